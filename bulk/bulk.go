@@ -0,0 +1,144 @@
+// Package bulk bulk.go
+// This module runs many API requests, like one per row of a CSV of users, in batches that keep to the OneLogin API's
+// rate limit. Each batch runs at once, and when the X-RateLimit-Remaining header of its responses says fewer requests
+// than a batch are left, the next batch waits the X-RateLimit-Reset seconds until the limit resets. RunWithStatus also
+// reports after each batch how much of the rate limit is left and when the jobs should be done.
+package bulk
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultBatchSize is how many requests are sent at once unless configured otherwise
+const DefaultBatchSize = 10
+
+// MaxPause caps the wait for the rate limit to reset, in case the header is wrong
+const MaxPause = time.Hour
+
+// Job sends one request, returning the headers of its response so the batches can keep to the rate limit. Headers may
+// be nil when nothing was sent
+type Job func() (http.Header, error)
+
+// RateLimitPeriod is how long the OneLogin API's rate limit lasts before it resets, for estimates spanning several
+const RateLimitPeriod = time.Hour
+
+// Status is how far the jobs are after a batch, and how much of the rate limit its responses said was left
+type Status struct {
+	Done, Total int
+	Elapsed     time.Duration // since the first batch started, waits for the rate limit included
+	Known       bool          // whether the responses had X-RateLimit headers, the fields below are empty when not
+	Limit       int           // requests allowed per period, 0 when not reported
+	Remaining   int           // requests left in the current period
+	Reset       time.Duration // until the current period ends
+}
+
+// Estimate is how long the jobs left should take, one request each: at the pace so far while the rate limit lasts,
+// then waiting for it to reset whenever it runs out
+func (s Status) Estimate() time.Duration {
+	if s.Done == 0 {
+		return 0
+	}
+	pace := s.Elapsed / time.Duration(s.Done)
+	left := s.Total - s.Done
+	if !s.Known || left <= s.Remaining {
+		return pace * time.Duration(left)
+	}
+	estimate := maxDuration(s.Reset, pace*time.Duration(s.Remaining))
+	left -= s.Remaining
+	for s.Limit > 0 && left > s.Limit {
+		estimate += maxDuration(RateLimitPeriod, pace*time.Duration(s.Limit))
+		left -= s.Limit
+	}
+	return estimate + pace*time.Duration(left)
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Run runs the jobs batchSize at a time, calling wait between batches when the rate limit runs low. The errors are
+// in the order of the jobs, nil for the jobs that succeeded
+func Run(jobs []Job, batchSize int, wait func(time.Duration)) []error {
+	return RunWithStatus(jobs, batchSize, wait, nil)
+}
+
+// RunWithStatus runs the jobs like Run, calling status after each batch but the last when given
+func RunWithStatus(jobs []Job, batchSize int, wait func(time.Duration), status func(Status)) []error {
+	started := time.Now()
+	if batchSize < 1 {
+		batchSize = DefaultBatchSize
+	}
+	errs := make([]error, len(jobs))
+	for start := 0; start < len(jobs); start += batchSize {
+		end := start + batchSize
+		if end > len(jobs) {
+			end = len(jobs)
+		}
+		headers := make([]http.Header, end-start)
+		var group sync.WaitGroup
+		for i := start; i < end; i++ {
+			group.Add(1)
+			go func(i int) {
+				defer group.Done()
+				headers[i-start], errs[i] = jobs[i]()
+			}(i)
+		}
+		group.Wait()
+		if end == len(jobs) {
+			break
+		}
+		var pause time.Duration
+		for _, header := range headers {
+			if p := Pause(header, batchSize); p > pause {
+				pause = p
+			}
+		}
+		if status != nil {
+			status(batchStatus(headers, end, len(jobs), time.Since(started)))
+		}
+		if pause > 0 {
+			wait(pause)
+		}
+	}
+	return errs
+}
+
+// batchStatus is the status after a batch, with the least of the rate limit its responses said was left
+func batchStatus(headers []http.Header, done int, total int, elapsed time.Duration) Status {
+	status := Status{Done: done, Total: total, Elapsed: elapsed}
+	for _, header := range headers {
+		remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+		if err != nil || (status.Known && remaining >= status.Remaining) {
+			continue
+		}
+		status.Known, status.Remaining = true, remaining
+		status.Limit, _ = strconv.Atoi(header.Get("X-RateLimit-Limit"))
+		reset, _ := strconv.Atoi(header.Get("X-RateLimit-Reset"))
+		status.Reset = time.Duration(reset) * time.Second
+	}
+	return status
+}
+
+// Pause is how long to wait before another batch given a response's headers: until the rate limit resets when fewer
+// requests than a batch remain, and not at all otherwise
+func Pause(header http.Header, batchSize int) time.Duration {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil || remaining >= batchSize {
+		return 0
+	}
+	reset, err := strconv.Atoi(header.Get("X-RateLimit-Reset"))
+	if err != nil || reset < 1 {
+		reset = 1 // the limit is about to reset, or the header doesn't say when
+	}
+	pause := time.Duration(reset) * time.Second
+	if pause > MaxPause {
+		pause = MaxPause
+	}
+	return pause
+}
@@ -0,0 +1,168 @@
+package bulk
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	tests := map[string]struct {
+		Jobs           int
+		BatchSize      int
+		Remaining      string
+		Fail           map[int]bool
+		ExpectedWaits  []time.Duration
+		ExpectedFailed []int
+	}{
+		"It runs every job in batches": {
+			Jobs:      7,
+			BatchSize: 3,
+			Remaining: "100",
+		},
+		"It waits for the rate limit when fewer requests than a batch remain": {
+			Jobs:          7,
+			BatchSize:     3,
+			Remaining:     "2",
+			ExpectedWaits: []time.Duration{30 * time.Second, 30 * time.Second},
+		},
+		"It returns errors in the order of the jobs": {
+			Jobs:           5,
+			BatchSize:      2,
+			Fail:           map[int]bool{1: true, 4: true},
+			ExpectedFailed: []int{1, 4},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			ran, inFlight, maxInFlight := 0, 0, 0
+			jobs := make([]Job, test.Jobs)
+			for i := range jobs {
+				i := i
+				jobs[i] = func() (http.Header, error) {
+					mu.Lock()
+					ran++
+					inFlight++
+					if inFlight > maxInFlight {
+						maxInFlight = inFlight
+					}
+					mu.Unlock()
+					time.Sleep(5 * time.Millisecond)
+					mu.Lock()
+					inFlight--
+					mu.Unlock()
+					if test.Fail[i] {
+						return nil, errors.New("failed")
+					}
+					header := http.Header{}
+					if test.Remaining != "" {
+						header.Set("X-RateLimit-Remaining", test.Remaining)
+						header.Set("X-RateLimit-Reset", "30")
+					}
+					return header, nil
+				}
+			}
+			var waits []time.Duration
+			errs := Run(jobs, test.BatchSize, func(d time.Duration) { waits = append(waits, d) })
+			failed := []int{}
+			for i, err := range errs {
+				if err != nil {
+					failed = append(failed, i)
+				}
+			}
+			assert.Equal(t, test.Jobs, ran)
+			assert.True(t, maxInFlight <= test.BatchSize)
+			assert.Equal(t, test.ExpectedWaits, waits)
+			if test.ExpectedFailed == nil {
+				test.ExpectedFailed = []int{}
+			}
+			assert.Equal(t, test.ExpectedFailed, failed)
+		})
+	}
+}
+
+func TestPause(t *testing.T) {
+	tests := map[string]struct {
+		Header   http.Header
+		Expected time.Duration
+	}{
+		"It doesn't wait while enough requests remain": {
+			Header:   http.Header{"X-Ratelimit-Remaining": {"10"}, "X-Ratelimit-Reset": {"60"}},
+			Expected: 0,
+		},
+		"It waits for the reset when too few remain": {
+			Header:   http.Header{"X-Ratelimit-Remaining": {"9"}, "X-Ratelimit-Reset": {"60"}},
+			Expected: time.Minute,
+		},
+		"It waits a second when the reset isn't given": {
+			Header:   http.Header{"X-Ratelimit-Remaining": {"0"}},
+			Expected: time.Second,
+		},
+		"It doesn't wait without rate limit headers": {
+			Header:   nil,
+			Expected: 0,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Pause(test.Header, 10))
+		})
+	}
+}
+
+func TestRunWithStatus(t *testing.T) {
+	jobs := make([]Job, 5)
+	for i := range jobs {
+		remaining := strconv.Itoa(100 - i)
+		jobs[i] = func() (http.Header, error) {
+			return http.Header{"X-Ratelimit-Limit": {"5000"}, "X-Ratelimit-Remaining": {remaining}, "X-Ratelimit-Reset": {"60"}}, nil
+		}
+	}
+	statuses := []Status{}
+	RunWithStatus(jobs, 2, nil, func(status Status) {
+		status.Elapsed = 0
+		statuses = append(statuses, status)
+	})
+	assert.Equal(t, []Status{
+		{Done: 2, Total: 5, Known: true, Limit: 5000, Remaining: 99, Reset: time.Minute},
+		{Done: 4, Total: 5, Known: true, Limit: 5000, Remaining: 97, Reset: time.Minute},
+	}, statuses)
+}
+
+func TestEstimate(t *testing.T) {
+	tests := map[string]struct {
+		Status   Status
+		Expected time.Duration
+	}{
+		"It keeps the pace while the rate limit lasts": {
+			Status:   Status{Done: 10, Total: 30, Elapsed: 10 * time.Second, Known: true, Limit: 100, Remaining: 50, Reset: time.Hour},
+			Expected: 20 * time.Second,
+		},
+		"It keeps the pace without rate limit headers": {
+			Status:   Status{Done: 10, Total: 30, Elapsed: 10 * time.Second},
+			Expected: 20 * time.Second,
+		},
+		"It waits for the reset when the rate limit runs out": {
+			Status:   Status{Done: 10, Total: 30, Elapsed: 10 * time.Second, Known: true, Limit: 100, Remaining: 5, Reset: time.Minute},
+			Expected: time.Minute + 15*time.Second,
+		},
+		"It waits a period for each limit's worth of jobs": {
+			Status:   Status{Done: 10, Total: 260, Elapsed: 10 * time.Second, Known: true, Limit: 100, Remaining: 0, Reset: time.Minute},
+			Expected: time.Minute + 2*time.Hour + 50*time.Second,
+		},
+		"It can't tell before any job is done": {
+			Status:   Status{Total: 30},
+			Expected: 0,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Status.Estimate())
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package clients
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/onelogin/onelogin-go-sdk/pkg/client"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/olhttp"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+)
+
+// OneLoginAPI sends requests to the OneLogin API through the OneLogin client's transport with its access token. It
+// covers calls the SDK doesn't, and resources whose SDK types would lose fields or send zero values back
+type OneLoginAPI struct {
+	client  *client.APIClient
+	configs ClientConfigs
+	mu      sync.Mutex // guards the access token shared with the SDK
+}
+
+// APIError is a response the OneLogin API refused a request with
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s was rejected with %s: %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+// matches the API and the token endpoint rejecting the credentials, even after the error was wrapped in others' text
+var authErrorPattern = regexp.MustCompile(`rejected with 40[13] `)
+
+// IsAuthError reports whether the error came from the credentials being rejected, as invalid or without the scope
+func IsAuthError(err error) bool {
+	return err != nil && authErrorPattern.MatchString(err.Error())
+}
+
+// matches the API answering that a resource doesn't exist, as reported by OneLoginAPI or by the OneLogin SDK, which
+// passes on the body of the response: {"statusCode":404,...} for version 2 and {"status":{"code":404,...}} for version 1
+var notFoundPattern = regexp.MustCompile(`rejected with 404 |"statusCode":\s*404\b|"code":\s*404\b`)
+
+// IsNotFound reports whether the error came from the API answering that the resource doesn't exist
+func IsNotFound(err error) bool {
+	return err != nil && notFoundPattern.MatchString(err.Error())
+}
+
+func init() {
+	Register(OneLoginAPIService, newOneLoginAPI)
+}
+
+// OneLoginAPI creates and returns an instance of OneLoginAPI sharing the OneLogin client if one does not exist
+// Memoizes the OneLoginAPI and returns that instance on every subsequent call
+func (c *Clients) OneLoginAPI() (*OneLoginAPI, error) {
+	api, err := c.Get(OneLoginAPIService)
+	if err != nil {
+		return nil, err
+	}
+	return api.(*OneLoginAPI), nil
+}
+
+func newOneLoginAPI(c *Clients) (interface{}, error) {
+	oneloginClient, err := c.OneLoginClient()
+	if err != nil {
+		return nil, err
+	}
+	return &OneLoginAPI{client: oneloginClient, configs: c.ClientConfigs}, nil
+}
+
+// HTTPClient is the client OneLogin API requests are sent with, for requests to other OneLogin endpoints like an
+// OIDC app's that should go through the same proxy, CA bundle and retries but not carry the API's access token
+func (a *OneLoginAPI) HTTPClient() services.HTTPClient {
+	return a.client.Services.HTTPService.Config.Client
+}
+
+// Do sends the request to the path e.g. /api/2/users/12 with a JSON body when given, returning the body and
+// headers of a successful response. A rejected access token is replaced once. Refusals are returned as *APIError
+func (a *OneLoginAPI) Do(method string, path string, query url.Values, body []byte) ([]byte, http.Header, error) {
+	requestURL := a.configs.OneLoginURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	for attempt := 1; ; attempt++ {
+		token, err := a.token(attempt > 1)
+		if err != nil {
+			return nil, nil, err
+		}
+		var requestBody io.Reader
+		if body != nil {
+			requestBody = bytes.NewReader(body)
+		}
+		request, err := http.NewRequest(method, requestURL, requestBody)
+		if err != nil {
+			return nil, nil, err
+		}
+		if body != nil {
+			request.GetBody = func() (io.ReadCloser, error) { return ioutil.NopCloser(bytes.NewReader(body)), nil }
+		}
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := a.client.Services.HTTPService.Config.Client.Do(request)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		switch {
+		case response.StatusCode == http.StatusUnauthorized && attempt == 1:
+			continue
+		case response.StatusCode < 200 || response.StatusCode > 299:
+			return nil, nil, &APIError{Method: method, Path: path, StatusCode: response.StatusCode, Status: response.Status, Body: string(data)}
+		}
+		return data, response.Header, nil
+	}
+}
+
+// token is the access token the OneLogin client uses, requesting one when it has none or when renew is set
+func (a *OneLoginAPI) token(renew bool) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	service := a.client.Services.HTTPService
+	if !renew && service.ClientCredential.AccessToken != nil {
+		return *service.ClientCredential.AccessToken, nil
+	}
+	token, err := RequestToken(a.configs)
+	if err != nil {
+		return "", fmt.Errorf("there was a problem getting a OneLogin access token: %s", err)
+	}
+	service.ClientCredential = olhttp.ClientCredential{AccessToken: &token.AccessToken}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,142 @@
+package clients
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOneLoginAPIDo(t *testing.T) {
+	tests := map[string]struct {
+		Method           string
+		Path             string
+		Body             string
+		RejectFirstToken bool
+		ExpectedBody     string
+		ExpectedError    error
+	}{
+		"It returns the body of the response": {
+			Method:       http.MethodGet,
+			Path:         "/api/2/users/7",
+			ExpectedBody: `{"id":7}`,
+		},
+		"It sends the body": {
+			Method:       http.MethodPut,
+			Path:         "/api/2/users/7",
+			Body:         `{"firstname":"Ada"}`,
+			ExpectedBody: `{"firstname":"Ada"}`,
+		},
+		"It renews a rejected access token": {
+			Method:           http.MethodGet,
+			Path:             "/api/2/users/7",
+			RejectFirstToken: true,
+			ExpectedBody:     `{"id":7}`,
+		},
+		"It returns refusals as API errors": {
+			Method:        http.MethodDelete,
+			Path:          "/api/2/users/404",
+			ExpectedError: &APIError{Method: http.MethodDelete, Path: "/api/2/users/404", StatusCode: 404, Status: "404 Not Found", Body: "missing"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokens := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.URL.Path == "/auth/oauth2/v2/token":
+					tokens++
+					w.Write([]byte(`{"access_token":"token-` + string(rune('0'+tokens)) + `","expires_in":36000}`))
+				case test.RejectFirstToken && r.Header.Get("Authorization") == "Bearer token-1":
+					w.WriteHeader(http.StatusUnauthorized)
+				case r.URL.Path == "/api/2/users/404":
+					w.WriteHeader(http.StatusNotFound)
+					w.Write([]byte("missing"))
+				case r.Body != nil && r.Method != http.MethodGet:
+					body, _ := ioutil.ReadAll(r.Body)
+					w.Write(body)
+				default:
+					w.Write([]byte(`{"id":7}`))
+				}
+			}))
+			defer server.Close()
+			c := New(ClientConfigs{
+				OneLoginClientID:     "id",
+				OneLoginClientSecret: "secret",
+				OneLoginURL:          server.URL,
+				Retry:                RetryPolicy{MaxAttempts: 1},
+			})
+			api, err := c.OneLoginAPI()
+			assert.Nil(t, err)
+			var body []byte
+			if test.Body != "" {
+				body = []byte(test.Body)
+			}
+			response, _, err := api.Do(test.Method, test.Path, nil, body)
+			assert.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.ExpectedBody, string(response))
+		})
+	}
+}
+
+func TestIsAuthError(t *testing.T) {
+	tests := map[string]struct {
+		Err      error
+		Expected bool
+	}{
+		"It recognizes rejected tokens": {
+			Err:      errors.New("there was a problem getting a OneLogin access token: token request was rejected with 401 Unauthorized"),
+			Expected: true,
+		},
+		"It recognizes missing scopes": {
+			Err:      &APIError{Method: "GET", Path: "/api/2/users", StatusCode: 403, Status: "403 Forbidden", Body: "{}"},
+			Expected: true,
+		},
+		"It ignores other refusals": {
+			Err: &APIError{Method: "GET", Path: "/api/2/users/1", StatusCode: 404, Status: "404 Not Found", Body: "{}"},
+		},
+		"It ignores unreachable APIs": {
+			Err: errors.New("there was a problem getting a OneLogin access token: unable to reach https://api.us.onelogin.com: timeout"),
+		},
+		"It ignores no error": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, IsAuthError(test.Err))
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	tests := map[string]struct {
+		Err      error
+		Expected bool
+	}{
+		"It recognizes OneLoginAPI responses": {
+			Err:      &APIError{Method: "GET", Path: "/api/2/users/1", StatusCode: 404, Status: "404 Not Found", Body: "{}"},
+			Expected: true,
+		},
+		"It recognizes version 2 bodies passed on by the SDK": {
+			Err:      errors.New(`unable to locate resource with id 1: {"statusCode":404,"name":"NotFoundError","message":"Not Found"}`),
+			Expected: true,
+		},
+		"It recognizes version 1 bodies passed on by the SDK": {
+			Err:      errors.New(`unable to locate resource with id 1: {"status":{"error":true,"code":404,"type":"not found"}}`),
+			Expected: true,
+		},
+		"It ignores ids holding 404": {
+			Err: errors.New("unable to locate resource with id 4040: unable to connect"),
+		},
+		"It ignores other refusals": {
+			Err: &APIError{Method: "GET", Path: "/api/2/users/404", StatusCode: 403, Status: "403 Forbidden", Body: "{}"},
+		},
+		"It ignores no error": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, IsNotFound(test.Err))
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package clients
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// refresh SSO role credentials this long before they expire
+const ssoExpiryWindow = time.Minute
+
+// awsSSOProfile is the SSO settings of a profile in the AWS shared config file, either set on the profile itself
+// or through an sso-session section
+type awsSSOProfile struct {
+	Session   string
+	StartURL  string
+	Region    string
+	AccountID string
+	RoleName  string
+}
+
+// cacheKey names the token cache file written by aws sso login, the session name when the profile uses one
+func (p awsSSOProfile) cacheKey() string {
+	if p.Session != "" {
+		return p.Session
+	}
+	return p.StartURL
+}
+
+// readAWSConfig parses the sections of an AWS shared config file into maps of their keys
+func readAWSConfig(path string) (map[string]map[string]string, error) {
+	sections := map[string]map[string]string{}
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return sections, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var section map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";"):
+			continue
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			name := strings.Join(strings.Fields(strings.Trim(line, "[]")), " ")
+			section = map[string]string{}
+			sections[name] = section
+		case section != nil && strings.Contains(line, "="):
+			parts := strings.SplitN(line, "=", 2)
+			section[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+	return sections, scanner.Err()
+}
+
+// loadAWSSSOProfile reads the SSO settings of the profile from the shared config file. ok is false for profiles without SSO
+func loadAWSSSOProfile(configPath string, profile string) (awsSSOProfile, bool, error) {
+	sections, err := readAWSConfig(configPath)
+	if err != nil {
+		return awsSSOProfile{}, false, err
+	}
+	name := "profile " + profile
+	if profile == "default" && sections[name] == nil {
+		name = "default"
+	}
+	settings := sections[name]
+	if settings["sso_account_id"] == "" || settings["sso_role_name"] == "" {
+		return awsSSOProfile{}, false, nil
+	}
+	out := awsSSOProfile{
+		Session:   settings["sso_session"],
+		StartURL:  settings["sso_start_url"],
+		Region:    settings["sso_region"],
+		AccountID: settings["sso_account_id"],
+		RoleName:  settings["sso_role_name"],
+	}
+	if out.Session != "" {
+		session := sections["sso-session "+out.Session]
+		if session == nil {
+			return awsSSOProfile{}, false, fmt.Errorf("sso-session %s of profile %s does not exist", out.Session, profile)
+		}
+		out.StartURL, out.Region = session["sso_start_url"], session["sso_region"]
+	}
+	if out.StartURL == "" || out.Region == "" {
+		return awsSSOProfile{}, false, fmt.Errorf("profile %s is missing sso_start_url or sso_region", profile)
+	}
+	return out, true, nil
+}
+
+// awsSSOProvider exchanges the token cached by aws sso login for credentials of the profile's role
+type awsSSOProvider struct {
+	credentials.Expiry
+	profile  awsSSOProfile
+	cacheDir string // ~/.aws/sso/cache
+	endpoint string // the SSO portal, defaults to the one of the profile's region
+	client   *http.Client
+}
+
+func newAWSSSOProvider(profile awsSSOProfile, cacheDir string, transport http.RoundTripper) *awsSSOProvider {
+	return &awsSSOProvider{
+		profile:  profile,
+		cacheDir: cacheDir,
+		endpoint: fmt.Sprintf("https://portal.sso.%s.amazonaws.com", profile.Region),
+		client:   &http.Client{Timeout: tokenTimeout, Transport: transport},
+	}
+}
+
+// Retrieve satisfies credentials.Provider
+func (p *awsSSOProvider) Retrieve() (credentials.Value, error) {
+	accessToken, err := p.accessToken()
+	if err != nil {
+		return credentials.Value{}, err
+	}
+	query := url.Values{"account_id": {p.profile.AccountID}, "role_name": {p.profile.RoleName}}
+	request, err := http.NewRequest(http.MethodGet, p.endpoint+"/federation/credentials?"+query.Encode(), nil)
+	if err != nil {
+		return credentials.Value{}, err
+	}
+	request.Header.Set("x-amz-sso_bearer_token", accessToken)
+	response, err := p.client.Do(request)
+	if err != nil {
+		return credentials.Value{}, fmt.Errorf("unable to reach AWS SSO: %s", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return credentials.Value{}, fmt.Errorf("AWS SSO rejected the request for role %s with %s. Run aws sso login", p.profile.RoleName, response.Status)
+	}
+	var body struct {
+		RoleCredentials struct {
+			AccessKeyID     string `json:"accessKeyId"`
+			SecretAccessKey string `json:"secretAccessKey"`
+			SessionToken    string `json:"sessionToken"`
+			Expiration      int64  `json:"expiration"` // milliseconds since the epoch
+		} `json:"roleCredentials"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return credentials.Value{}, fmt.Errorf("unable to read AWS SSO credentials: %s", err)
+	}
+	p.SetExpiration(time.Unix(0, body.RoleCredentials.Expiration*int64(time.Millisecond)), ssoExpiryWindow)
+	return credentials.Value{
+		AccessKeyID:     body.RoleCredentials.AccessKeyID,
+		SecretAccessKey: body.RoleCredentials.SecretAccessKey,
+		SessionToken:    body.RoleCredentials.SessionToken,
+		ProviderName:    "SSOProvider",
+	}, nil
+}
+
+// accessToken reads the token aws sso login cached for the profile's session or start url
+func (p *awsSSOProvider) accessToken() (string, error) {
+	sum := sha1.Sum([]byte(p.profile.cacheKey()))
+	data, err := ioutil.ReadFile(filepath.Join(p.cacheDir, hex.EncodeToString(sum[:])+".json"))
+	if err != nil {
+		return "", fmt.Errorf("no AWS SSO session found for %s. Run aws sso login: %s", p.profile.cacheKey(), err)
+	}
+	var token struct {
+		AccessToken string    `json:"accessToken"`
+		ExpiresAt   time.Time `json:"expiresAt"`
+	}
+	if err := json.Unmarshal(data, &token); err != nil {
+		return "", fmt.Errorf("unable to read the AWS SSO session: %s", err)
+	}
+	if token.AccessToken == "" || time.Now().After(token.ExpiresAt) {
+		return "", fmt.Errorf("the AWS SSO session for %s expired. Run aws sso login", p.profile.cacheKey())
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,145 @@
+package clients
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const testAWSConfig = `
+[default]
+region = us-east-1
+
+[profile legacy]
+sso_start_url = https://legacy.awsapps.com/start
+sso_region = us-west-2
+sso_account_id = 111111111111
+sso_role_name = ReadOnly
+
+[profile session]
+sso_session = corp
+sso_account_id = 222222222222
+sso_role_name = Admin
+
+[profile keys]
+aws_access_key_id = abc
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = eu-west-1
+
+[profile broken]
+sso_session = missing
+sso_account_id = 333333333333
+sso_role_name = Admin
+`
+
+func TestLoadAWSSSOProfile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "aws")
+	defer os.RemoveAll(dir)
+	configPath := filepath.Join(dir, "config")
+	ioutil.WriteFile(configPath, []byte(testAWSConfig), 0600)
+
+	tests := map[string]struct {
+		Profile       string
+		Expected      awsSSOProfile
+		ExpectedOK    bool
+		ExpectedError string
+	}{
+		"It reads SSO settings from the profile": {
+			Profile:    "legacy",
+			Expected:   awsSSOProfile{StartURL: "https://legacy.awsapps.com/start", Region: "us-west-2", AccountID: "111111111111", RoleName: "ReadOnly"},
+			ExpectedOK: true,
+		},
+		"It reads SSO settings from the sso-session": {
+			Profile:    "session",
+			Expected:   awsSSOProfile{Session: "corp", StartURL: "https://corp.awsapps.com/start", Region: "eu-west-1", AccountID: "222222222222", RoleName: "Admin"},
+			ExpectedOK: true,
+		},
+		"It skips profiles without SSO": {
+			Profile: "keys",
+		},
+		"It skips the default profile": {
+			Profile: "default",
+		},
+		"It skips unknown profiles": {
+			Profile: "unknown",
+		},
+		"It rejects missing sso-sessions": {
+			Profile:       "broken",
+			ExpectedError: "sso-session missing of profile broken does not exist",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			profile, ok, err := loadAWSSSOProfile(configPath, test.Profile)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOK, ok)
+			assert.Equal(t, test.Expected, profile)
+		})
+	}
+}
+
+func TestAWSSSOProviderRetrieve(t *testing.T) {
+	expiration := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-amz-sso_bearer_token") != "sso-token" || r.URL.Query().Get("account_id") != "222222222222" || r.URL.Query().Get("role_name") != "Admin" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"roleCredentials":{"accessKeyId":"key","secretAccessKey":"secret","sessionToken":"session","expiration":` +
+			strconv.FormatInt(expiration.UnixNano()/int64(time.Millisecond), 10) + `}}`))
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		CachedToken   string
+		ExpectedError string
+	}{
+		"It exchanges the cached token for role credentials": {
+			CachedToken: `{"accessToken":"sso-token","expiresAt":"` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`,
+		},
+		"It asks to log in when the session expired": {
+			CachedToken:   `{"accessToken":"sso-token","expiresAt":"` + time.Now().Add(-time.Hour).UTC().Format(time.RFC3339) + `"}`,
+			ExpectedError: "the AWS SSO session for corp expired. Run aws sso login",
+		},
+		"It reports rejected tokens": {
+			CachedToken:   `{"accessToken":"wrong","expiresAt":"` + time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`,
+			ExpectedError: "AWS SSO rejected the request for role Admin with 401 Unauthorized. Run aws sso login",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, _ := ioutil.TempDir("", "sso")
+			defer os.RemoveAll(dir)
+			sum := sha1.Sum([]byte("corp"))
+			ioutil.WriteFile(filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), []byte(test.CachedToken), 0600)
+
+			provider := newAWSSSOProvider(awsSSOProfile{Session: "corp", Region: "eu-west-1", AccountID: "222222222222", RoleName: "Admin"}, dir, nil)
+			provider.endpoint = server.URL
+			value, err := provider.Retrieve()
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, "key", value.AccessKeyID)
+			assert.Equal(t, "secret", value.SecretAccessKey)
+			assert.Equal(t, "session", value.SessionToken)
+			assert.False(t, provider.IsExpired())
+			assert.True(t, provider.ExpiresAt().Equal(expiration.Add(-ssoExpiryWindow)))
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package clients
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/onelogin/onelogin/logger"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResponseCache keeps successful GET responses so collections listed more than once, like apps listed for each app
+// type, are only fetched once per run. With a TTL, responses are also kept on disk and reused by runs within it
+type ResponseCache struct {
+	Dir string        // where responses are kept between runs, one directory per account
+	TTL time.Duration // how long responses on disk are reused. 0 keeps them in memory only
+
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+	now     func() time.Time
+}
+
+// NewResponseCache creates a cache keeping responses in memory, and in dir for the ttl when it is above 0
+func NewResponseCache(dir string, ttl time.Duration) *ResponseCache {
+	return &ResponseCache{Dir: dir, TTL: ttl, entries: map[string]cachedResponse{}, now: time.Now}
+}
+
+type cachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+func (r cachedResponse) response(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}
+}
+
+// onDisk reports whether responses are kept between runs
+func (c *ResponseCache) onDisk() bool {
+	return c.Dir != "" && c.TTL > 0
+}
+
+func (c *ResponseCache) get(scope string, url string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := scope + " " + url
+	if entry, ok := c.entries[key]; ok {
+		return entry, true
+	}
+	if !c.onDisk() {
+		return cachedResponse{}, false
+	}
+	data, err := ioutil.ReadFile(c.path(scope, url))
+	if err != nil {
+		return cachedResponse{}, false
+	}
+	var entry cachedResponse
+	if json.Unmarshal(data, &entry) != nil || c.now().Sub(entry.StoredAt) > c.TTL {
+		return cachedResponse{}, false
+	}
+	c.entries[key] = entry
+	return entry, true
+}
+
+func (c *ResponseCache) put(scope string, url string, entry cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry.StoredAt = c.now()
+	c.entries[scope+" "+url] = entry
+	if !c.onDisk() {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(c.path(scope, url)), 0700)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(c.path(scope, url), data, 0600)
+	}
+	if err != nil {
+		logger.Warn("Unable to cache API response", "error", err)
+	}
+}
+
+// invalidate drops every response of the scope, as a change made through the API may show in any of them
+func (c *ResponseCache) invalidate(scope string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if strings.HasPrefix(key, scope+" ") {
+			delete(c.entries, key)
+		}
+	}
+	if c.onDisk() {
+		os.RemoveAll(filepath.Join(c.Dir, hash(scope)))
+	}
+}
+
+// path is where the response for the url is kept on disk, in the scope's directory so it can be invalidated at once
+func (c *ResponseCache) path(scope string, url string) string {
+	return filepath.Join(c.Dir, hash(scope), hash(url)+".json")
+}
+
+func hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheTransport answers GET requests from the cache, storing successful responses, and invalidates the cache on
+// any other request. The scope keeps the responses of different accounts apart
+type cacheTransport struct {
+	cache *ResponseCache
+	scope string
+	next  http.RoundTripper
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t cacheTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Method != http.MethodGet {
+		// token requests change nothing the responses show
+		if !strings.HasPrefix(request.URL.Path, "/auth/oauth2/") {
+			t.cache.invalidate(t.scope)
+		}
+		return t.next.RoundTrip(request)
+	}
+	url := request.URL.String()
+	if entry, ok := t.cache.get(t.scope, url); ok {
+		logger.Debug("API response served from cache", "url", url, "stored_at", entry.StoredAt)
+		return entry.response(request), nil
+	}
+	response, err := t.next.RoundTrip(request)
+	if err != nil || response.StatusCode != http.StatusOK {
+		return response, err
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(body))
+	t.cache.put(t.scope, url, cachedResponse{StatusCode: response.StatusCode, Header: response.Header, Body: body})
+	return response, nil
+}
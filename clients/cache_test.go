@@ -0,0 +1,111 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	tests := map[string]struct {
+		TTL              time.Duration
+		Age              time.Duration // how long after the first run the second one starts
+		FirstRun         []string      // requests as "METHOD /path"
+		SecondRun        []string
+		ExpectedRequests []string // requests that reached the API
+	}{
+		"It requests a collection once per run": {
+			FirstRun:         []string{"GET /apps", "GET /apps", "GET /apps?cursor=2"},
+			ExpectedRequests: []string{"GET /apps", "GET /apps?cursor=2"},
+		},
+		"It requests it again after a change": {
+			FirstRun:         []string{"GET /apps", "PUT /apps/1", "GET /apps"},
+			ExpectedRequests: []string{"GET /apps", "PUT /apps/1", "GET /apps"},
+		},
+		"It keeps responses across token requests": {
+			TTL:              5 * time.Minute,
+			Age:              time.Minute,
+			FirstRun:         []string{"GET /apps", "POST /auth/oauth2/v2/token"},
+			SecondRun:        []string{"GET /apps"},
+			ExpectedRequests: []string{"GET /apps", "POST /auth/oauth2/v2/token"},
+		},
+		"It does not keep responses between runs without a ttl": {
+			FirstRun:         []string{"GET /apps"},
+			SecondRun:        []string{"GET /apps"},
+			ExpectedRequests: []string{"GET /apps", "GET /apps"},
+		},
+		"It reuses responses of runs within the ttl": {
+			TTL:              5 * time.Minute,
+			Age:              time.Minute,
+			FirstRun:         []string{"GET /apps"},
+			SecondRun:        []string{"GET /apps"},
+			ExpectedRequests: []string{"GET /apps"},
+		},
+		"It requests responses older than the ttl again": {
+			TTL:              5 * time.Minute,
+			Age:              10 * time.Minute,
+			FirstRun:         []string{"GET /apps"},
+			SecondRun:        []string{"GET /apps"},
+			ExpectedRequests: []string{"GET /apps", "GET /apps"},
+		},
+		"It drops responses on disk after a change": {
+			TTL:              5 * time.Minute,
+			Age:              time.Minute,
+			FirstRun:         []string{"GET /apps", "DELETE /apps/1"},
+			SecondRun:        []string{"GET /apps"},
+			ExpectedRequests: []string{"GET /apps", "DELETE /apps/1", "GET /apps"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests = append(requests, r.Method+" "+r.URL.RequestURI())
+				w.Write([]byte(`{"data":[{"id":1}]}`))
+			}))
+			defer server.Close()
+			dir, _ := ioutil.TempDir("", "cache")
+			defer os.RemoveAll(dir)
+			start := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+
+			run := func(requestLines []string, now time.Time) {
+				cache := NewResponseCache(dir, test.TTL)
+				cache.now = func() time.Time { return now }
+				client := http.Client{Transport: cacheTransport{cache: cache, scope: server.URL + " client-id", next: http.DefaultTransport}}
+				for _, line := range requestLines {
+					parts := strings.SplitN(line, " ", 2)
+					request, _ := http.NewRequest(parts[0], server.URL+parts[1], nil)
+					response, err := client.Do(request)
+					assert.Nil(t, err)
+					body, _ := ioutil.ReadAll(response.Body)
+					response.Body.Close()
+					assert.Equal(t, `{"data":[{"id":1}]}`, string(body))
+				}
+			}
+			run(test.FirstRun, start)
+			run(test.SecondRun, start.Add(test.Age))
+			assert.Equal(t, test.ExpectedRequests, requests)
+		})
+	}
+}
+
+func TestCacheTransportScope(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+	cache := NewResponseCache("", 0)
+	for _, scope := range []string{"tenant-a", "tenant-b", "tenant-a"} {
+		client := http.Client{Transport: cacheTransport{cache: cache, scope: scope, next: http.DefaultTransport}}
+		response, err := client.Get(server.URL + "/apps")
+		assert.Nil(t, err)
+		response.Body.Close()
+	}
+	assert.Equal(t, 2, requests)
+}
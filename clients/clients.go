@@ -1,72 +1,231 @@
 // Package clients clients.go
 // This module creates a list of client instances for any cloud provider so each client only gets
-// instantiated once and can be shared among other callers.
+// instantiated once and can be shared among other callers. Clients are only created when first asked for, so
+// credentials for a service that isn't used, like AWS during a OneLogin import, are never needed.
 //
 // Adding Clients
-// To add new clients, add to the Clients struct a field that represents how the client should be handled
-// Then add a method to Clients that either creates + memoizes, or returns the memoized client per the
-// client initialization procedure. These should be pulbic facing methods as they should be used to retrieve clients
+// To add new clients, register a constructor for the service under its name with Register from an init function.
+// Get creates the client on first use and memoizes it. Then add a method to Clients that returns the client from Get
+// with its type, as the public facing way to retrieve it
 package clients
 
 import (
+	"context"
+	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/onelogin/onelogin-go-sdk/pkg/client"
-
-	"log"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/olhttp"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
 )
 
 // Clients is a list of memoized instantiated clients
 type Clients struct {
-	OneLogin *client.APIClient
-	AwsIam   *iam.IAM
+	instances map[string]interface{} // clients created so far by service name
 	ClientConfigs
 }
 
 type ClientConfigs struct {
 	AwsRegion                                           string
+	AwsProfile                                          string // shared config profile, including SSO profiles. Defaults to AWS_PROFILE
+	AwsRoleARN, AwsExternalID                           string // role to assume with the profile's credentials
 	OneLoginClientID, OneLoginClientSecret, OneLoginURL string
+	TokenCache                                          *TokenCache     // when given, OneLogin access tokens are reused across invocations
+	Retry                                               RetryPolicy     // how rate limited and failed API requests are retried
+	Context                                             context.Context // aborts API requests in flight when done e.g. on Ctrl-C
+	CABundle                                            string          // PEM file of certificates to trust besides the system's e.g. of a TLS inspecting proxy
+	InsecureSkipVerify                                  bool            // don't verify the certificates of the APIs at all
+	Debug                                               int             // how much of every API request to log, DebugOff by default
+	Cache                                               *ResponseCache  // when given, OneLogin GET responses are reused instead of requested again
+	FetchConcurrency                                    int             // pages of a collection requested at once, DefaultFetchConcurrency by default
+	MockDir                                             string          // when given, API responses are replayed from the fixtures in it instead of requested
+	RecordDir                                           string          // when given, API responses are recorded to it as fixtures
+	Requests                                            *RequestCounter // when given, counts the API requests sent
+	Limiter                                             *RateLimiter    // when given, holds OneLogin API requests back to its rate
+}
+
+// how long to wait for the headers of each OneLogin API response
+const oneLoginResponseTimeout = 5 * time.Second
+
+// names of the services registered by this package
+const (
+	OneLoginService      = "onelogin"
+	OneLoginAPIService   = "onelogin_api"
+	OneLoginPagesService = "onelogin_pages"
+	AwsIamService        = "aws_iam"
+)
+
+func init() {
+	Register(OneLoginService, newOneLoginClient)
+	Register(AwsIamService, newAwsIamClient)
 }
 
 func New(clientConfigs ClientConfigs) *Clients {
-	return &Clients{ClientConfigs: clientConfigs}
+	return &Clients{ClientConfigs: clientConfigs, instances: map[string]interface{}{}}
 }
 
 // OneLoginClient creates and returns an instance of the OneLogin API client if one does not exist
 // Memoizes the OneLogin API client and returns that instance on every subsequent call
-func (c *Clients) OneLoginClient() *client.APIClient {
-	if c.OneLogin == nil {
-		clientConfig := &client.APIClientConfig{
-			Timeout:      5,
-			ClientID:     c.ClientConfigs.OneLoginClientID,
-			ClientSecret: c.ClientConfigs.OneLoginClientSecret,
-			Url:          c.ClientConfigs.OneLoginURL,
-		}
-		oneloginClient, err := client.NewClient(clientConfig)
+func (c *Clients) OneLoginClient() (*client.APIClient, error) {
+	oneloginClient, err := c.Get(OneLoginService)
+	if err != nil {
+		return nil, err
+	}
+	return oneloginClient.(*client.APIClient), nil
+}
+
+func newOneLoginClient(c *Clients) (interface{}, error) {
+	clientConfig := &client.APIClientConfig{
+		Timeout:      5,
+		ClientID:     c.ClientConfigs.OneLoginClientID,
+		ClientSecret: c.ClientConfigs.OneLoginClientSecret,
+		Url:          c.ClientConfigs.OneLoginURL,
+	}
+	if c.ClientConfigs.MockDir != "" {
+		mockCredentials(clientConfig)
+	}
+	oneloginClient, err := client.NewClient(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem configuring the OneLogin client. Ensure your OneLogin credentials are exported to your environment: %s", err)
+	}
+	// every service of the client sends requests through the same http service, so this covers them all. The
+	// timeout moves to the transport so it bounds each attempt rather than all of them
+	transport, err := c.ClientConfigs.transport()
+	if err != nil {
+		return nil, err
+	}
+	transport.ResponseHeaderTimeout = oneLoginResponseTimeout
+	var next http.RoundTripper = NewRetryTransport(c.ClientConfigs.limit(c.ClientConfigs.debug(c.ClientConfigs.count(c.ClientConfigs.fixtures(transport)))), c.ClientConfigs.Retry)
+	if c.ClientConfigs.Cache != nil {
+		next = cacheTransport{cache: c.ClientConfigs.Cache, scope: c.ClientConfigs.OneLoginURL + " " + c.ClientConfigs.OneLoginClientID, next: next}
+	}
+	oneloginClient.Services.HTTPService.Config.Client = &http.Client{Transport: contextTransport{ctx: c.context(), next: next}}
+	if c.ClientConfigs.TokenCache != nil {
+		token, err := c.ClientConfigs.TokenCache.Token(c.ClientConfigs)
 		if err != nil {
-			log.Fatalln("There was a problem configuring the OneLogin client. Ensure your OneLogin credentials are exported to your environment", err)
-		} else {
-			c.OneLogin = oneloginClient
+			return nil, fmt.Errorf("there was a problem getting a OneLogin access token: %s", err)
 		}
+		// the client only requests a token of its own when this one is rejected
+		oneloginClient.Services.HTTPService.ClientCredential = olhttp.ClientCredential{AccessToken: &token.AccessToken}
+	}
+	return oneloginClient, nil
+}
+
+// mockCredentials fills in the credentials left blank so the client can be created while replaying fixtures
+func mockCredentials(config *client.APIClientConfig) {
+	if config.ClientID == "" {
+		config.ClientID = "mock"
+	}
+	if config.ClientSecret == "" {
+		config.ClientSecret = "mock"
+	}
+	if config.Url == "" {
+		config.Url = fmt.Sprintf(client.BaseURLTemplate, client.USRegion)
 	}
-	return c.OneLogin
 }
 
 // AwsIamClient creates and returns an instance of the AWS API client if one does not exist
 // Memoizes the AWS API client and returns that instance on every subsequent call
-func (c *Clients) AwsIamClient() *iam.IAM {
-	if c.AwsIam == nil {
-		sess, err := session.NewSession(
-			&aws.Config{
-				Region: aws.String(c.ClientConfigs.AwsRegion),
-			},
-		)
+func (c *Clients) AwsIamClient() (*iam.IAM, error) {
+	iamClient, err := c.Get(AwsIamService)
+	if err != nil {
+		return nil, err
+	}
+	return iamClient.(*iam.IAM), nil
+}
+
+func newAwsIamClient(c *Clients) (interface{}, error) {
+	sess, err := c.awsSession()
+	if err != nil {
+		return nil, fmt.Errorf("there was a problem configuring the AWS client. Ensure your AWS credentials are exported to your environment: %s", err)
+	}
+	return iam.New(sess), nil
+}
+
+// awsSession resolves credentials like the AWS CLI: the shared config profile, SSO through the token cached by
+// aws sso login, then the role to assume with them
+func (c *Clients) awsSession() (*session.Session, error) {
+	options := session.Options{
+		Profile:           c.ClientConfigs.AwsProfile,
+		SharedConfigState: session.SharedConfigEnable,
+	}
+	transport, err := c.ClientConfigs.transport()
+	if err != nil {
+		return nil, err
+	}
+	options.Config.HTTPClient = &http.Client{Transport: c.ClientConfigs.debug(c.ClientConfigs.count(c.ClientConfigs.fixtures(transport)))}
+	if c.ClientConfigs.Retry.MaxAttempts > 0 {
+		options.Config.MaxRetries = aws.Int(c.ClientConfigs.Retry.MaxAttempts - 1)
+	}
+	if c.ClientConfigs.AwsRegion != "" {
+		options.Config.Region = aws.String(c.ClientConfigs.AwsRegion)
+	}
+	if c.ClientConfigs.MockDir != "" {
+		// requests are signed before they are replayed, any credentials will do
+		options.Config.Credentials = credentials.NewStaticCredentials("mock", "mock", "")
+		if c.ClientConfigs.AwsRegion == "" {
+			options.Config.Region = aws.String("us-east-1")
+		}
+		// the session loads AWS_CA_BUNDLE into the transport of its http client, which replaying has no use for
+		replay := options.Config.HTTPClient
+		options.Config.HTTPClient = nil
+		sess, err := session.NewSessionWithOptions(options)
 		if err != nil {
-			log.Fatalln("There was a problem configuring the AWS client. Ensure your AWS credentials are exported to your environment", err)
-		} else {
-			c.AwsIam = iam.New(sess)
+			return nil, err
 		}
+		sess.Config.HTTPClient = replay
+		return sess, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	ssoProfile, ok, err := loadAWSSSOProfile(awsConfigFile(home), awsProfileName(c.ClientConfigs.AwsProfile))
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		options.Config.Credentials = credentials.NewCredentials(newAWSSSOProvider(ssoProfile, filepath.Join(home, ".aws", "sso", "cache"), c.ClientConfigs.debug(c.ClientConfigs.count(c.ClientConfigs.fixtures(transport)))))
+	}
+	sess, err := session.NewSessionWithOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	ctx := c.context()
+	sess.Handlers.Build.PushFront(func(r *request.Request) {
+		r.SetContext(ctx)
+	})
+	if c.ClientConfigs.AwsRoleARN != "" {
+		sess.Config.Credentials = stscreds.NewCredentials(sess, c.ClientConfigs.AwsRoleARN, func(p *stscreds.AssumeRoleProvider) {
+			if c.ClientConfigs.AwsExternalID != "" {
+				p.ExternalID = aws.String(c.ClientConfigs.AwsExternalID)
+			}
+		})
+	}
+	return sess, nil
+}
+
+// awsProfileName is the shared config profile in use, as the AWS CLI picks it
+func awsProfileName(profile string) string {
+	if profile != "" {
+		return profile
+	}
+	if profile = os.Getenv("AWS_PROFILE"); profile != "" {
+		return profile
+	}
+	return "default"
+}
+
+func awsConfigFile(home string) string {
+	if path := os.Getenv("AWS_CONFIG_FILE"); path != "" {
+		return path
 	}
-	return c.AwsIam
+	return filepath.Join(home, ".aws", "config")
 }
@@ -21,9 +21,10 @@ func TestOneLoginClient(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			clnts := New(test.Configs)
-			clnts.OneLoginClient()                // instantiate and store address of aws client
-			clnt := clnts.OneLoginClient()        // retrieves that address
-			assert.Equal(t, clnt, clnts.OneLogin) // retrieved address should be the memoized address
+			clnts.OneLoginClient()              // instantiate and store address of aws client
+			clnt, err := clnts.OneLoginClient() // retrieves that address
+			assert.Nil(t, err)
+			assert.Equal(t, clnt, clnts.instances[OneLoginService]) // retrieved address should be the memoized address
 
 		})
 	}
@@ -45,9 +46,10 @@ func TestAwsIamClient(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			clnts := New(test.Configs)
-			clnts.AwsIamClient()                // instantiate and store address of aws client
-			clnt := clnts.AwsIamClient()        // retrieves that address
-			assert.Equal(t, clnt, clnts.AwsIam) // retrieved address should be the memoized address
+			clnts.AwsIamClient()              // instantiate and store address of aws client
+			clnt, err := clnts.AwsIamClient() // retrieves that address
+			assert.Nil(t, err)
+			assert.Equal(t, clnt, clnts.instances[AwsIamService]) // retrieved address should be the memoized address
 		})
 	}
 }
@@ -0,0 +1,26 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+)
+
+// contextTransport sends every request with the context so cancelling it aborts the requests in flight, including
+// those the OneLogin SDK makes without a way to pass one
+type contextTransport struct {
+	ctx  context.Context
+	next http.RoundTripper
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t contextTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	return t.next.RoundTrip(request.WithContext(t.ctx))
+}
+
+// context is the context requests are made with, from the configs or one that is never done
+func (c *Clients) context() context.Context {
+	if c.ClientConfigs.Context != nil {
+		return c.ClientConfigs.Context
+	}
+	return context.Background()
+}
@@ -0,0 +1,36 @@
+package clients
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// RequestCounter counts the API requests sent, retries included, e.g. for the summary of a run
+type RequestCounter struct {
+	count int64
+}
+
+// Count is how many requests were sent so far
+func (c *RequestCounter) Count() int64 {
+	return atomic.LoadInt64(&c.count)
+}
+
+// countTransport counts the requests sent through next
+type countTransport struct {
+	next    http.RoundTripper
+	counter *RequestCounter
+}
+
+// count counts the requests sent through next when a counter is configured, or returns next as it is
+func (c ClientConfigs) count(next http.RoundTripper) http.RoundTripper {
+	if c.Requests == nil {
+		return next
+	}
+	return countTransport{next: next, counter: c.Requests}
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t countTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	atomic.AddInt64(&t.counter.count, 1)
+	return t.next.RoundTrip(request)
+}
@@ -0,0 +1,39 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		Counter  *RequestCounter
+		Expected int64
+	}{
+		"It counts every request sent": {
+			Counter:  &RequestCounter{},
+			Expected: 3,
+		},
+		"It counts nothing without a counter": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &http.Client{Transport: ClientConfigs{Requests: test.Counter}.count(http.DefaultTransport)}
+			for i := 0; i < 3; i++ {
+				response, err := client.Get(server.URL)
+				assert.Nil(t, err)
+				response.Body.Close()
+			}
+			if test.Counter != nil {
+				assert.Equal(t, test.Expected, test.Counter.Count())
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package clients
+
+import (
+	"bytes"
+	"github.com/onelogin/onelogin/logger"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// how much detail of API requests is logged
+const (
+	DebugOff      = iota
+	DebugRequests // method, url, status and latency of every request
+	DebugBodies   // the request and response bodies too, with secrets redacted
+)
+
+// longest body logged, the rest is cut off
+const maxLoggedBody = 4096
+
+// secrets in the json, form and xml bodies of the OneLogin and AWS APIs, redacted before bodies are logged
+var (
+	secretJSONPattern = regexp.MustCompile(`(?i)("[a-z_]*(token|secret|password|passwd|credential|key)[a-z_]*"\s*:\s*)"(\\.|[^"\\])*"`)
+	secretFormPattern = regexp.MustCompile(`(?i)(^|&)([a-z_.]*(token|secret|password|key)[a-z_.]*=)[^&]*`)
+	secretXMLPattern  = regexp.MustCompile(`(?i)<((SecretAccessKey|SessionToken)[^>]*)>[^<]*<`)
+)
+
+// redact replaces the values of fields that look like secrets in a body
+func redact(body []byte) []byte {
+	body = secretJSONPattern.ReplaceAll(body, []byte(`$1"REDACTED"`))
+	body = secretFormPattern.ReplaceAll(body, []byte(`${1}${2}REDACTED`))
+	return secretXMLPattern.ReplaceAll(body, []byte(`<$1>REDACTED<`))
+}
+
+// debugTransport logs the requests sent through next
+type debugTransport struct {
+	next  http.RoundTripper
+	level int
+	log   func(msg string, keyvals ...interface{})
+}
+
+// debug logs the requests sent through next at the configured level, or returns next as it is when debugging is off
+func (c ClientConfigs) debug(next http.RoundTripper) http.RoundTripper {
+	if c.Debug <= DebugOff {
+		return next
+	}
+	return debugTransport{next: next, level: c.Debug, log: logger.Debug}
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t debugTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if t.level >= DebugBodies && request.GetBody != nil {
+		if body, err := request.GetBody(); err == nil {
+			data, _ := ioutil.ReadAll(body)
+			body.Close()
+			t.logBody("API request body", data)
+		}
+	}
+	started := time.Now()
+	response, err := t.next.RoundTrip(request)
+	latency := time.Since(started).Round(time.Millisecond)
+	if err != nil {
+		t.log("API request failed", "method", request.Method, "url", request.URL, "latency", latency, "error", err)
+		return response, err
+	}
+	t.log("API request", "method", request.Method, "url", request.URL, "status", response.StatusCode, "latency", latency)
+	if t.level >= DebugBodies {
+		data, readErr := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		response.Body = ioutil.NopCloser(bytes.NewReader(data))
+		if readErr != nil {
+			t.log("Unable to read API response body", "error", readErr)
+		}
+		t.logBody("API response body", data)
+	}
+	return response, nil
+}
+
+func (t debugTransport) logBody(msg string, data []byte) {
+	if len(data) == 0 {
+		return
+	}
+	data = redact(data)
+	if len(data) > maxLoggedBody {
+		data = append(data[:maxLoggedBody:maxLoggedBody], "..."...)
+	}
+	t.log(msg, "body", string(data))
+}
@@ -0,0 +1,83 @@
+package clients
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := map[string]struct {
+		Body     string
+		Expected string
+	}{
+		"It redacts json secrets": {
+			Body:     `{"access_token":"abc","token_type":"bearer","client_secret":"s\"x","name":"Wiki"}`,
+			Expected: `{"access_token":"REDACTED","token_type":"REDACTED","client_secret":"REDACTED","name":"Wiki"}`,
+		},
+		"It redacts form secrets": {
+			Body:     `Action=AssumeRole&ExternalId=x&Password=hunter2&Version=2011-06-15`,
+			Expected: `Action=AssumeRole&ExternalId=x&Password=REDACTED&Version=2011-06-15`,
+		},
+		"It redacts xml secrets": {
+			Body:     `<Credentials><AccessKeyId>AKIA</AccessKeyId><SecretAccessKey>abc</SecretAccessKey><SessionToken>def</SessionToken></Credentials>`,
+			Expected: `<Credentials><AccessKeyId>AKIA</AccessKeyId><SecretAccessKey>REDACTED</SecretAccessKey><SessionToken>REDACTED</SessionToken></Credentials>`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, string(redact([]byte(test.Body))))
+		})
+	}
+}
+
+func TestDebugTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"abc"}`))
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		Level    int
+		Expected []string
+	}{
+		"It logs requests": {
+			Level:    DebugRequests,
+			Expected: []string{"API request method=POST url=" + server.URL + "/auth status=200 latency="},
+		},
+		"It logs redacted bodies": {
+			Level: DebugBodies,
+			Expected: []string{
+				`API request body body={"client_secret":"REDACTED"}`,
+				"API request method=POST url=" + server.URL + "/auth status=200 latency=",
+				`API response body body={"access_token":"REDACTED"}`,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			logged := []string{}
+			transport := debugTransport{next: http.DefaultTransport, level: test.Level, log: func(msg string, keyvals ...interface{}) {
+				for i := 0; i < len(keyvals); i += 2 {
+					msg += fmt.Sprintf(" %s=%v", keyvals[i], keyvals[i+1])
+				}
+				logged = append(logged, msg)
+			}}
+			request, _ := http.NewRequest(http.MethodPost, server.URL+"/auth", strings.NewReader(`{"client_secret":"s"}`))
+			response, err := transport.RoundTrip(request)
+			assert.Nil(t, err)
+			body, _ := ioutil.ReadAll(response.Body)
+			assert.Equal(t, `{"access_token":"abc"}`, string(body), "the body is still readable after logging")
+			assert.Equal(t, len(test.Expected), len(logged))
+			for i, expected := range test.Expected {
+				if i < len(logged) {
+					assert.True(t, strings.HasPrefix(logged[i], expected), logged[i])
+				}
+			}
+		})
+	}
+}
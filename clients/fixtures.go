@@ -0,0 +1,177 @@
+package clients
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/logger"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Fixture is a recorded API response, kept as <method>/<path>[_<query>][_<body hash>].json in a fixture directory
+type Fixture struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   json.RawMessage   `json:"body"`
+}
+
+// recordedHeaders are the response headers worth replaying, the ones paging depends on
+var recordedHeaders = []string{"Content-Type", "Total-Pages", "Current-Page", "Total-Count", "After-Cursor", "Before-Cursor"}
+
+// fixtures replays the responses recorded in MockDir instead of sending requests, or records the responses of the
+// requests sent to RecordDir. Requests go through unchanged when neither is set
+func (c ClientConfigs) fixtures(next http.RoundTripper) http.RoundTripper {
+	switch {
+	case c.MockDir != "":
+		return replayTransport{dir: c.MockDir}
+	case c.RecordDir != "":
+		return recordTransport{dir: c.RecordDir, next: next}
+	}
+	return next
+}
+
+var unsafeFixtureChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// FixturePath is where the response to the request is recorded in dir. The host is left out so fixtures recorded
+// against one tenant replay against any, and requests with a body are told apart by its hash
+func FixturePath(dir string, request *http.Request, body []byte) string {
+	name := strings.Trim(request.URL.Path, "/")
+	if name == "" {
+		name = "index"
+	}
+	if query := request.URL.Query().Encode(); query != "" {
+		name += "_" + strings.Trim(unsafeFixtureChars.ReplaceAllString(query, "_"), "_")
+	}
+	if len(body) > 0 {
+		sum := sha256.Sum256(body)
+		name += "_" + hex.EncodeToString(sum[:])[:12]
+	}
+	return filepath.Join(dir, strings.ToLower(request.Method), filepath.FromSlash(name)+".json")
+}
+
+// isCredentialRequest reports whether the request is for a OneLogin access token, or AWS credentials from STS or
+// SSO, whose responses are never recorded
+func isCredentialRequest(request *http.Request) bool {
+	return strings.HasPrefix(request.URL.Path, "/auth/oauth2/") || strings.HasPrefix(request.URL.Host, "sts.") || strings.HasPrefix(request.URL.Host, "portal.sso.")
+}
+
+// readBody reads the request body and puts it back so it can still be sent
+func readBody(request *http.Request) ([]byte, error) {
+	if request.Body == nil {
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(request.Body)
+	request.Body.Close()
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// replayTransport answers requests with the fixtures in dir without touching the network
+type replayTransport struct {
+	dir string
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t replayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readBody(request)
+	if err != nil {
+		return nil, err
+	}
+	path := FixturePath(t.dir, request, body)
+	var fixture Fixture
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("unable to read the fixture %s: %s", path, err)
+		}
+	case os.IsNotExist(err) && isCredentialRequest(request):
+		// credentials aren't checked while replaying, any token will do
+		fixture = Fixture{Status: http.StatusOK, Body: json.RawMessage(`{"access_token":"mock","token_type":"bearer","expires_in":36000}`)}
+	case os.IsNotExist(err):
+		// not found rather than an error, so the request isn't retried
+		logger.Warn("No recorded response for the API request", "method", request.Method, "url", request.URL, "fixture", path)
+		fixture.Status = http.StatusNotFound
+		fixture.Body, _ = json.Marshal(map[string]string{"message": "no recorded response at " + path})
+	default:
+		return nil, err
+	}
+	if fixture.Status == 0 {
+		fixture.Status = http.StatusOK // fixtures written by hand may only have a body
+	}
+	var text string
+	var compact bytes.Buffer
+	if json.Unmarshal(fixture.Body, &text) == nil {
+		fixture.Body = json.RawMessage(text) // a body that wasn't JSON when recorded
+	} else if json.Compact(&compact, fixture.Body) == nil {
+		fixture.Body = compact.Bytes() // as sent rather than as indented in the fixture
+	}
+	response := &http.Response{
+		Status:        fmt.Sprintf("%d %s", fixture.Status, http.StatusText(fixture.Status)),
+		StatusCode:    fixture.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(fixture.Body)),
+		ContentLength: int64(len(fixture.Body)),
+		Request:       request,
+	}
+	for key, value := range fixture.Header {
+		response.Header.Set(key, value)
+	}
+	return response, nil
+}
+
+// recordTransport sends requests through next and writes their responses to dir as fixtures
+type recordTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t recordTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	body, err := readBody(request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := t.next.RoundTrip(request)
+	if err != nil || isCredentialRequest(request) {
+		return response, err
+	}
+	data, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	fixture := Fixture{Status: response.StatusCode, Header: map[string]string{}, Body: data}
+	for _, key := range recordedHeaders {
+		if value := response.Header.Get(key); value != "" {
+			fixture.Header[key] = value
+		}
+	}
+	if !json.Valid(data) {
+		fixture.Body, _ = json.Marshal(string(data)) // e.g. the XML of AWS, replayed as a JSON string
+	}
+	path := FixturePath(t.dir, request, body)
+	out, err := json.MarshalIndent(fixture, "", "  ")
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(path, out, 0600)
+	}
+	if err != nil {
+		logger.Warn("Unable to record API response", "fixture", path, "error", err)
+	}
+	return response, nil
+}
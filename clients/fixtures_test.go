@@ -0,0 +1,131 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFixturePath(t *testing.T) {
+	tests := map[string]struct {
+		Method   string
+		URL      string
+		Body     string
+		Expected string
+	}{
+		"It names fixtures after the path": {
+			Method:   http.MethodGet,
+			URL:      "https://acme.onelogin.com/api/2/apps/12",
+			Expected: filepath.Join("fixtures", "get", "api", "2", "apps", "12.json"),
+		},
+		"It adds the query": {
+			Method:   http.MethodGet,
+			URL:      "https://api.us.onelogin.com/api/2/users?page=2&limit=1000",
+			Expected: filepath.Join("fixtures", "get", "api", "2", "users_limit_1000_page_2.json"),
+		},
+		"It adds a hash of the body": {
+			Method:   http.MethodPost,
+			URL:      "https://iam.amazonaws.com/",
+			Body:     "Action=ListUsers&Version=2010-05-08",
+			Expected: filepath.Join("fixtures", "post", "index_b6359072c78d.json"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			request, _ := http.NewRequest(test.Method, test.URL, nil)
+			assert.Equal(t, test.Expected, FixturePath("fixtures", request, []byte(test.Body)))
+		})
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "fixtures")
+	defer os.RemoveAll(dir)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/oauth2/v2/token":
+			w.Write([]byte(`{"access_token":"secret-token","expires_in":36000}`))
+		case "/api/2/users":
+			w.Header().Set("Total-Pages", "3")
+			w.Header().Set("Set-Cookie", "session=secret")
+			w.Write([]byte(`[{"id":1}]`))
+		default:
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(`<ListUsersResponse/>`))
+		}
+	}))
+	defer server.Close()
+
+	recording := http.Client{Transport: ClientConfigs{RecordDir: dir}.fixtures(http.DefaultTransport)}
+	for _, request := range []*http.Request{
+		mustRequest(http.MethodPost, server.URL+"/auth/oauth2/v2/token", `{"grant_type":"client_credentials"}`),
+		mustRequest(http.MethodGet, server.URL+"/api/2/users?page=1", ""),
+		mustRequest(http.MethodPost, server.URL+"/", "Action=ListUsers"),
+	} {
+		response, err := recording.Do(request)
+		assert.Nil(t, err)
+		response.Body.Close()
+	}
+	tokenFixtures, _ := filepath.Glob(filepath.Join(dir, "post", "auth", "*"))
+	assert.Empty(t, tokenFixtures, "tokens are never recorded")
+
+	replaying := http.Client{Transport: ClientConfigs{MockDir: dir}.fixtures(nil)}
+	tests := map[string]struct {
+		Request        *http.Request
+		ExpectedStatus int
+		ExpectedHeader http.Header
+		ExpectedBody   string
+	}{
+		"It replays responses with the headers paging needs": {
+			Request:        mustRequest(http.MethodGet, "https://api.eu.onelogin.com/api/2/users?page=1", ""),
+			ExpectedStatus: http.StatusOK,
+			ExpectedHeader: http.Header{"Content-Type": {"text/plain; charset=utf-8"}, "Total-Pages": {"3"}},
+			ExpectedBody:   `[{"id":1}]`,
+		},
+		"It replays bodies that weren't JSON": {
+			Request:        mustRequest(http.MethodPost, "https://iam.amazonaws.com/", "Action=ListUsers"),
+			ExpectedStatus: http.StatusOK,
+			ExpectedHeader: http.Header{"Content-Type": {"text/xml"}},
+			ExpectedBody:   `<ListUsersResponse/>`,
+		},
+		"It makes up tokens": {
+			Request:        mustRequest(http.MethodPost, "https://api.us.onelogin.com/auth/oauth2/v2/token", `{"grant_type":"client_credentials"}`),
+			ExpectedStatus: http.StatusOK,
+			ExpectedHeader: http.Header{"Content-Type": {"application/json"}},
+			ExpectedBody:   `{"access_token":"mock","token_type":"bearer","expires_in":36000}`,
+		},
+		"It answers requests that weren't recorded with not found": {
+			Request:        mustRequest(http.MethodGet, "https://api.us.onelogin.com/api/2/apps", ""),
+			ExpectedStatus: http.StatusNotFound,
+			ExpectedHeader: http.Header{"Content-Type": {"application/json"}},
+			ExpectedBody:   `{"message":"no recorded response at ` + strings.Replace(filepath.Join(dir, "get", "api", "2", "apps.json"), `\`, `\\`, -1) + `"}`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			response, err := replaying.Do(test.Request)
+			assert.Nil(t, err)
+			body, _ := ioutil.ReadAll(response.Body)
+			response.Body.Close()
+			assert.Equal(t, test.ExpectedStatus, response.StatusCode)
+			assert.Equal(t, test.ExpectedHeader, response.Header)
+			assert.Equal(t, test.ExpectedBody, string(body))
+		})
+	}
+}
+
+func mustRequest(method string, url string, body string) *http.Request {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		panic(err)
+	}
+	if body == "" {
+		request.Body = nil
+	}
+	return request
+}
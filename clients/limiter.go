@@ -0,0 +1,100 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket shared by every client of a run, so requests sent at once by different importables
+// or pages fetched concurrently keep to one rate together rather than each to its own
+type RateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // between tokens
+	burst    float64       // tokens the bucket holds
+	tokens   float64       // tokens in the bucket, negative while requests wait for tokens reserved ahead of time
+	last     time.Time     // when tokens was last topped up
+	now      func() time.Time
+}
+
+// NewRateLimiter lets through rps requests a second, up to a second's worth at once after a pause. It returns nil,
+// which lets every request through, when rps isn't positive
+func NewRateLimiter(rps float64) *RateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	burst := float64(int(rps))
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{interval: time.Duration(float64(time.Second) / rps), burst: burst, tokens: burst, now: time.Now}
+}
+
+// reserve takes a token, returning how long to wait until it is due
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// unreserve puts back a token taken by reserve for a request that won't be sent
+func (l *RateLimiter) unreserve() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// Wait blocks until the request may be sent, or the context is done, in which case the token is put back for the
+// requests still waiting
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	delay := l.reserve()
+	if delay <= 0 {
+		return nil
+	}
+	select {
+	case <-after(delay):
+		return nil
+	case <-ctx.Done():
+		l.unreserve()
+		return ctx.Err()
+	}
+}
+
+// limitTransport holds the requests sent through next back to the limiter's rate
+type limitTransport struct {
+	next    http.RoundTripper
+	limiter *RateLimiter
+}
+
+// limit holds the requests sent through next back to the configured rate, or returns next as it is when there is
+// no limiter or the responses are replayed
+func (c ClientConfigs) limit(next http.RoundTripper) http.RoundTripper {
+	if c.Limiter == nil || c.MockDir != "" {
+		return next
+	}
+	return limitTransport{next: next, limiter: c.Limiter}
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t limitTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(request.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(request)
+}
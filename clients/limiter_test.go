@@ -0,0 +1,115 @@
+package clients
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	tests := map[string]struct {
+		RPS      float64
+		At       []time.Duration // when each request is sent, from the first
+		Expected []time.Duration
+	}{
+		"It lets a second's worth through at once": {
+			RPS:      2,
+			At:       []time.Duration{0, 0, 0, 0},
+			Expected: []time.Duration{0, 0, 500 * time.Millisecond, time.Second},
+		},
+		"It lets requests at the rate through without waiting": {
+			RPS:      1,
+			At:       []time.Duration{0, time.Second, 2 * time.Second},
+			Expected: []time.Duration{0, 0, 0},
+		},
+		"It doesn't save up more than a second's worth": {
+			RPS:      2,
+			At:       []time.Duration{0, 10 * time.Second, 10 * time.Second, 10 * time.Second},
+			Expected: []time.Duration{0, 0, 0, 500 * time.Millisecond},
+		},
+		"It lets one through at once under a request a second": {
+			RPS:      0.5,
+			At:       []time.Duration{0, 0, time.Second},
+			Expected: []time.Duration{0, 2 * time.Second, 3 * time.Second},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			start := time.Now()
+			var now time.Time
+			limiter := NewRateLimiter(test.RPS)
+			limiter.now = func() time.Time { return now }
+			actual := []time.Duration{}
+			for _, at := range test.At {
+				now = start.Add(at)
+				actual = append(actual, limiter.reserve())
+			}
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestRateLimiterShared(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(5)
+	limiter.now = func() time.Time { return now }
+	var mu sync.Mutex
+	var group sync.WaitGroup
+	delays := []time.Duration{}
+	for i := 0; i < 10; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			delay := limiter.reserve()
+			mu.Lock()
+			delays = append(delays, delay)
+			mu.Unlock()
+		}()
+	}
+	group.Wait()
+	sort.Slice(delays, func(i, j int) bool { return delays[i] < delays[j] })
+	assert.Equal(t, []time.Duration{0, 0, 0, 0, 0, 200 * time.Millisecond, 400 * time.Millisecond, 600 * time.Millisecond, 800 * time.Millisecond, time.Second}, delays)
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	assert.Nil(t, NewRateLimiter(0), "no rate is no limiter")
+	limiter := NewRateLimiter(1)
+	assert.Nil(t, limiter.Wait(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, limiter.Wait(ctx))
+
+	now := time.Now()
+	limiter = NewRateLimiter(1)
+	limiter.now = func() time.Time { return now }
+	assert.Nil(t, limiter.Wait(context.Background()))
+	assert.Equal(t, context.Canceled, limiter.Wait(ctx))
+	assert.Equal(t, time.Second, limiter.reserve(), "a canceled wait gives its token back")
+}
+
+func TestLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	waits := []time.Duration{}
+	after = func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		return time.After(0)
+	}
+	defer func() { after = time.After }()
+	client := &http.Client{Transport: ClientConfigs{Limiter: NewRateLimiter(1)}.limit(http.DefaultTransport)}
+	for i := 0; i < 3; i++ {
+		response, err := client.Get(server.URL)
+		assert.Nil(t, err)
+		response.Body.Close()
+	}
+	assert.Len(t, waits, 2, "the requests after the first wait for their turn")
+	assert.Equal(t, http.DefaultTransport, ClientConfigs{Limiter: NewRateLimiter(1), MockDir: "fixtures"}.limit(http.DefaultTransport))
+}
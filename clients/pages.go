@@ -0,0 +1,164 @@
+package clients
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// DefaultFetchConcurrency is how many pages of a collection are requested at once unless configured otherwise
+const DefaultFetchConcurrency = 4
+
+// pageSize is the most items the OneLogin API returns per page
+const pageSize = 1000
+
+// PageReader reads OneLogin collections by page number, so the pages after the first can be requested at once rather
+// than one after another by cursor like the SDK does
+type PageReader struct {
+	api         *OneLoginAPI
+	concurrency int
+}
+
+func init() {
+	Register(OneLoginPagesService, newOneLoginPages)
+}
+
+// OneLoginPages creates and returns a PageReader sharing the OneLogin client and its access token if one does not exist
+// Memoizes the PageReader and returns that instance on every subsequent call
+func (c *Clients) OneLoginPages() (*PageReader, error) {
+	pages, err := c.Get(OneLoginPagesService)
+	if err != nil {
+		return nil, err
+	}
+	return pages.(*PageReader), nil
+}
+
+func newOneLoginPages(c *Clients) (interface{}, error) {
+	api, err := c.OneLoginAPI()
+	if err != nil {
+		return nil, err
+	}
+	concurrency := c.ClientConfigs.FetchConcurrency
+	if concurrency < 1 {
+		concurrency = DefaultFetchConcurrency
+	}
+	return &PageReader{api: api, concurrency: concurrency}, nil
+}
+
+// ReadPages reads every page of the collection at path e.g. /api/2/users, returning their bodies in page order
+func (r *PageReader) ReadPages(path string, query url.Values) ([][]byte, error) {
+	pages := [][]byte{}
+	err := r.StreamPages(path, query, func(page []byte) error {
+		pages = append(pages, page)
+		return nil
+	})
+	return pages, err
+}
+
+// StreamPages hands every page of the collection at path to each, in page order, as soon as the pages before it have
+// been. The first page tells how many there are in its Total-Pages header, then the rest are fetched by a bounded pool
+// of workers. At most as many pages as requested at once are held waiting for an earlier one, so memory stays flat
+// however large the collection. An error from each stops the fetching and is returned
+func (r *PageReader) StreamPages(path string, query url.Values, each func(page []byte) error) error {
+	first, total, err := r.readPage(path, query, 1)
+	if err != nil {
+		return err
+	}
+	if err := each(first); err != nil || total == 1 {
+		return err
+	}
+
+	type result struct {
+		number int
+		page   []byte
+		err    error
+	}
+	numbers := make(chan int)
+	results := make(chan result)
+	slots := make(chan struct{}, r.concurrency) // taken by a page when requested, given back when handed to each
+	stop := make(chan struct{})
+	go func() {
+		defer close(numbers)
+		for number := 2; number <= total; number++ {
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+			select {
+			case numbers <- number:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	for w := 0; w < r.concurrency && w < total-1; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for number := range numbers {
+				page, _, err := r.readPage(path, query, number)
+				select {
+				case results <- result{number: number, page: page, err: err}:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	waiting := map[int][]byte{}
+	next := 2
+	var failure error
+	for result := range results {
+		if failure != nil {
+			continue // wait for the workers to notice
+		}
+		if result.err != nil {
+			failure = result.err
+			close(stop)
+			continue
+		}
+		waiting[result.number] = result.page
+		for page, ok := waiting[next]; ok; page, ok = waiting[next] {
+			delete(waiting, next)
+			<-slots
+			next++
+			if err := each(page); err != nil {
+				failure = err
+				close(stop)
+				break
+			}
+		}
+	}
+	return failure
+}
+
+// readPage requests a page of the collection, returning it with the page count reported in Total-Pages
+func (r *PageReader) readPage(path string, query url.Values, number int) ([]byte, int, error) {
+	params := url.Values{}
+	for key, values := range query {
+		params[key] = values
+	}
+	params.Set("page", strconv.Itoa(number))
+	params.Set("limit", strconv.Itoa(pageSize))
+	body, header, err := r.api.Do(http.MethodGet, path, params, nil)
+	if apiErr, ok := err.(*APIError); ok {
+		return nil, 0, fmt.Errorf("page %d of %s was rejected with %s: %s", number, path, apiErr.Status, apiErr.Body)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("unable to get page %d of %s: %s", number, path, err)
+	}
+	total, err := strconv.Atoi(header.Get("Total-Pages"))
+	if err != nil || total < 1 {
+		total = 1 // collections that fit a page may not say how many there are
+	}
+	return body, total, nil
+}
@@ -0,0 +1,138 @@
+package clients
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadPages(t *testing.T) {
+	tests := map[string]struct {
+		TotalPages       int
+		Concurrency      int
+		FailPage         int
+		RejectFirstToken bool
+		Expected         []string
+		ExpectedError    string
+	}{
+		"It reads a single page": {
+			TotalPages:  1,
+			Concurrency: 4,
+			Expected:    []string{`[{"page":1}]`},
+		},
+		"It reassembles pages fetched at once in order": {
+			TotalPages:  6,
+			Concurrency: 3,
+			Expected:    []string{`[{"page":1}]`, `[{"page":2}]`, `[{"page":3}]`, `[{"page":4}]`, `[{"page":5}]`, `[{"page":6}]`},
+		},
+		"It reports a page that failed": {
+			TotalPages:    4,
+			Concurrency:   2,
+			FailPage:      3,
+			ExpectedError: "page 3 of /api/2/users was rejected with 400 Bad Request: bad page",
+		},
+		"It requests a new token when the one in use is rejected": {
+			TotalPages:       2,
+			Concurrency:      1,
+			RejectFirstToken: true,
+			Expected:         []string{`[{"page":1}]`, `[{"page":2}]`},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var mu sync.Mutex
+			tokens, inFlight, maxInFlight := 0, 0, 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/auth/oauth2/v2/token" {
+					mu.Lock()
+					tokens++
+					fmt.Fprintf(w, `{"access_token":"token-%d","expires_in":36000}`, tokens)
+					mu.Unlock()
+					return
+				}
+				mu.Lock()
+				inFlight++
+				if inFlight > maxInFlight {
+					maxInFlight = inFlight
+				}
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				defer func() {
+					mu.Lock()
+					inFlight--
+					mu.Unlock()
+				}()
+				if test.RejectFirstToken && r.Header.Get("Authorization") == "Bearer token-1" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				if page == test.FailPage {
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte("bad page"))
+					return
+				}
+				assert.Equal(t, "1000", r.URL.Query().Get("limit"))
+				w.Header().Set("Total-Pages", strconv.Itoa(test.TotalPages))
+				fmt.Fprintf(w, `[{"page":%d}]`, page)
+			}))
+			defer server.Close()
+			c := New(ClientConfigs{
+				OneLoginClientID:     "id",
+				OneLoginClientSecret: "secret",
+				OneLoginURL:          server.URL,
+				Retry:                RetryPolicy{MaxAttempts: 1},
+				FetchConcurrency:     test.Concurrency,
+			})
+			reader, err := c.OneLoginPages()
+			assert.Nil(t, err)
+			pages, err := reader.ReadPages("/api/2/users", nil)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			actual := make([]string, len(pages))
+			for i, page := range pages {
+				actual[i] = string(page)
+			}
+			assert.Equal(t, test.Expected, actual)
+			assert.LessOrEqual(t, maxInFlight, test.Concurrency)
+		})
+	}
+}
+
+func TestStreamPagesStops(t *testing.T) {
+	var mu sync.Mutex
+	requested := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/auth/oauth2/v2/token" {
+			w.Write([]byte(`{"access_token":"token","expires_in":36000}`))
+			return
+		}
+		mu.Lock()
+		requested++
+		mu.Unlock()
+		w.Header().Set("Total-Pages", "100")
+		fmt.Fprintf(w, `[{"page":%s}]`, r.URL.Query().Get("page"))
+	}))
+	defer server.Close()
+	reader, err := New(ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL, FetchConcurrency: 2}).OneLoginPages()
+	assert.Nil(t, err)
+	handed := []string{}
+	err = reader.StreamPages("/api/2/users", nil, func(page []byte) error {
+		handed = append(handed, string(page))
+		if len(handed) == 3 {
+			return fmt.Errorf("enough")
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "enough")
+	assert.Equal(t, []string{`[{"page":1}]`, `[{"page":2}]`, `[{"page":3}]`}, handed)
+	assert.LessOrEqual(t, requested, 6) // the pages handed over and those in flight, not all 100
+}
@@ -0,0 +1,139 @@
+package clients
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// writeProbes are updated to find out whether the credentials may write, one for each scope that may: users for
+// manage_users and roles, which only manage_all may write. No user or role has id 0, so credentials that may write
+// are told it doesn't exist and nothing changes, while others are refused before it's looked up
+var writeProbes = []string{"/api/2/users/0", "/api/2/roles/0"}
+
+// ScopeProbes are the resources read to find out what the credentials' scope allows, by the path they're listed at
+var ScopeProbes = []struct{ Resource, Path string }{
+	{"users", "/api/2/users"},
+	{"apps", "/api/2/apps"},
+	{"roles", "/api/2/roles"},
+	{"mappings", "/api/2/mappings"},
+}
+
+// Access is whether the credentials may read a resource
+type Access struct {
+	Resource string `json:"resource"`
+	Readable bool   `json:"readable"`
+	Detail   string `json:"detail,omitempty"` // why it can't be read
+}
+
+// PingReport is what Ping found out about the credentials and the API they're for
+type PingReport struct {
+	URL          string    `json:"url"`
+	Region       string    `json:"region"`        // us or eu, or custom for other API URLs
+	TokenLatency string    `json:"token_latency"` // how long the token request took
+	ExpiresAt    time.Time `json:"expires_at"`    // when the access token expires
+	MinLatency   string    `json:"min_latency"`
+	AvgLatency   string    `json:"avg_latency"`
+	MaxLatency   string    `json:"max_latency"`
+	RateLimit    RateLimit `json:"rate_limit"`
+	Access       []Access  `json:"access"`
+}
+
+// Ping checks the OneLogin credentials in the configs by requesting an access token, times count requests to the API
+// to measure its latency, reads the rate limit left and tries reading each of the ScopeProbes. Only invalid
+// credentials or an unreachable API are errors
+func Ping(configs ClientConfigs, count int) (PingReport, error) {
+	report := PingReport{URL: configs.OneLoginURL, Region: Region(configs.OneLoginURL)}
+	started := time.Now()
+	token, err := RequestToken(configs)
+	if err != nil {
+		return report, err
+	}
+	report.TokenLatency = roundLatency(time.Since(started))
+	report.ExpiresAt = token.ExpiresAt
+
+	configs.TokenCache = nil // the token just requested proves the credentials, not one cached earlier
+	configs.Cache = nil      // every request is timed, not answered from responses kept earlier
+	api, err := New(configs).OneLoginAPI()
+	if err != nil {
+		return report, err
+	}
+	if count < 1 {
+		count = 1
+	}
+	var min, max, total time.Duration
+	for i := 0; i < count; i++ {
+		started := time.Now()
+		if report.RateLimit, err = api.RateLimit(); err != nil {
+			return report, err
+		}
+		latency := time.Since(started)
+		if i == 0 || latency < min {
+			min = latency
+		}
+		if latency > max {
+			max = latency
+		}
+		total += latency
+	}
+	report.MinLatency, report.AvgLatency, report.MaxLatency = roundLatency(min), roundLatency(total/time.Duration(count)), roundLatency(max)
+
+	for _, probe := range ScopeProbes {
+		access := Access{Resource: probe.Resource, Readable: true}
+		if _, _, err := api.Do(http.MethodGet, probe.Path, url.Values{"limit": {"1"}}, nil); err != nil {
+			access.Readable = false
+			access.Detail = err.Error()
+			if apiError, ok := err.(*APIError); ok {
+				access.Detail = apiError.Status
+			}
+		}
+		report.Access = append(report.Access, access)
+	}
+	return report, nil
+}
+
+// CanWrite finds out whether the OneLogin credentials in the configs may write, whatever scope their profile claims, by
+// updating a user and a role that don't exist. They're read only when every update is refused. Responses saying
+// neither are errors
+func CanWrite(configs ClientConfigs) (bool, error) {
+	configs.Cache = nil
+	api, err := New(configs).OneLoginAPI()
+	if err != nil {
+		return false, err
+	}
+	for _, path := range writeProbes {
+		_, _, err = api.Do(http.MethodPut, path, nil, []byte(`{}`))
+		apiError, ok := err.(*APIError)
+		switch {
+		case err == nil:
+			return true, nil
+		case !ok:
+			return false, err
+		case apiError.StatusCode == http.StatusUnauthorized || apiError.StatusCode == http.StatusForbidden:
+			continue
+		case apiError.StatusCode == http.StatusNotFound || apiError.StatusCode == http.StatusBadRequest || apiError.StatusCode == http.StatusUnprocessableEntity:
+			return true, nil
+		default:
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// Region is the region of the OneLogin API URL e.g. us for https://api.us.onelogin.com, or custom for other URLs
+func Region(apiURL string) string {
+	parsed, err := url.Parse(apiURL)
+	if err != nil {
+		return "custom"
+	}
+	parts := strings.Split(parsed.Hostname(), ".")
+	if len(parts) == 4 && parts[0] == "api" && parts[2] == "onelogin" && parts[3] == "com" {
+		return parts[1]
+	}
+	return "custom"
+}
+
+func roundLatency(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
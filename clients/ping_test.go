@@ -0,0 +1,104 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	rateLimitRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/oauth2/v2/token":
+			if id, _, _ := r.BasicAuth(); id != "id" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"access_token":"token","expires_in":36000}`))
+		case "/auth/rate_limit":
+			rateLimitRequests++
+			w.Write([]byte(`{"status":{"error":false,"code":200},"data":{"X-RateLimit-Limit":5000,"X-RateLimit-Remaining":4990,"X-RateLimit-Reset":1200}}`))
+		case "/api/2/roles":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"Forbidden"}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	report, err := Ping(ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL, Retry: RetryPolicy{MaxAttempts: 1}, Cache: NewResponseCache("", 0)}, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, rateLimitRequests)
+	assert.Equal(t, "custom", report.Region)
+	assert.Equal(t, RateLimit{Limit: 5000, Remaining: 4990, Reset: 1200}, report.RateLimit)
+	assert.NotEmpty(t, report.MinLatency)
+	assert.Equal(t, []Access{
+		{Resource: "users", Readable: true},
+		{Resource: "apps", Readable: true},
+		{Resource: "roles", Readable: false, Detail: "403 Forbidden"},
+		{Resource: "mappings", Readable: true},
+	}, report.Access)
+
+	_, err = Ping(ClientConfigs{OneLoginClientID: "wrong", OneLoginClientSecret: "secret", OneLoginURL: server.URL}, 1)
+	assert.EqualError(t, err, "token request was rejected with 401 Unauthorized")
+	assert.True(t, IsAuthError(err))
+}
+
+func TestCanWrite(t *testing.T) {
+	tests := map[string]struct {
+		users    int
+		roles    int
+		expected bool
+		err      bool
+	}{
+		"read only":                  {users: http.StatusUnauthorized, roles: http.StatusUnauthorized, expected: false},
+		"forbidden":                  {users: http.StatusForbidden, roles: http.StatusForbidden, expected: false},
+		"may write everything":       {users: http.StatusNotFound, roles: http.StatusNotFound, expected: true},
+		"may write users only":       {users: http.StatusNotFound, roles: http.StatusForbidden, expected: true},
+		"may write roles only":       {users: http.StatusForbidden, roles: http.StatusNotFound, expected: true},
+		"invalid body":               {users: http.StatusUnprocessableEntity, roles: http.StatusForbidden, expected: true},
+		"unknown answer":             {users: http.StatusInternalServerError, roles: http.StatusForbidden, err: true},
+		"unknown answer after users": {users: http.StatusForbidden, roles: http.StatusInternalServerError, err: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/auth/oauth2/v2/token":
+					w.Write([]byte(`{"access_token":"token","expires_in":36000}`))
+				case "/api/2/users/0":
+					assert.Equal(t, http.MethodPut, r.Method)
+					w.WriteHeader(test.users)
+				case "/api/2/roles/0":
+					assert.Equal(t, http.MethodPut, r.Method)
+					w.WriteHeader(test.roles)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			canWrite, err := CanWrite(ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL, Retry: RetryPolicy{MaxAttempts: 1}})
+			assert.Equal(t, test.err, err != nil)
+			assert.Equal(t, test.expected, canWrite)
+		})
+	}
+}
+
+func TestRegion(t *testing.T) {
+	tests := map[string]string{
+		"https://api.us.onelogin.com":      "us",
+		"https://api.eu.onelogin.com/":     "eu",
+		"https://onelogin.example.com":     "custom",
+		"http://127.0.0.1:8765":            "custom",
+		"https://api.us.onelogin.com.evil": "custom",
+	}
+	for apiURL, expected := range tests {
+		t.Run(apiURL, func(t *testing.T) {
+			assert.Equal(t, expected, Region(apiURL))
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// RateLimit is how many requests the credentials may send to the OneLogin API, as GET /auth/rate_limit or the
+// X-RateLimit headers of a response report it
+type RateLimit struct {
+	Limit     int `json:"limit"`     // requests allowed per period
+	Remaining int `json:"remaining"` // requests left in the current period
+	Reset     int `json:"reset"`     // seconds until the period ends and Remaining is back to Limit
+}
+
+// RateLimit asks the API how many requests the credentials have left. Asking isn't counted against them
+func (a *OneLoginAPI) RateLimit() (RateLimit, error) {
+	data, _, err := a.Do(http.MethodGet, "/auth/rate_limit", nil, nil)
+	if err != nil {
+		return RateLimit{}, err
+	}
+	var body struct {
+		Data struct {
+			Limit     int `json:"X-RateLimit-Limit"`
+			Remaining int `json:"X-RateLimit-Remaining"`
+			Reset     int `json:"X-RateLimit-Reset"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return RateLimit{}, fmt.Errorf("unable to read the rate limit: %s", err)
+	}
+	return RateLimit{Limit: body.Data.Limit, Remaining: body.Data.Remaining, Reset: body.Data.Reset}, nil
+}
+
+// RateLimitFromHeader reads the X-RateLimit headers of a response, reporting false when it has none
+func RateLimitFromHeader(header http.Header) (RateLimit, bool) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	limit, _ := strconv.Atoi(header.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.Atoi(header.Get("X-RateLimit-Reset"))
+	return RateLimit{Limit: limit, Remaining: remaining, Reset: reset}, true
+}
+
+// RateClassProbes are cheap requests to each class of endpoints, whose responses report the class' rate limit
+var RateClassProbes = []struct{ Class, Path string }{
+	{"api/1", "/api/1/events/types"},
+	{"api/2", "/api/2/users"},
+}
+
+// AccountRateClass is the class of the account wide rate limit GET /auth/rate_limit reports
+const AccountRateClass = "account"
+
+// RateStatus is the rate limit of a class of endpoints
+type RateStatus struct {
+	Class    string `json:"class"`
+	Reported bool   `json:"reported"` // whether the API reported the limit, the other fields are empty when it didn't
+	RateLimit
+}
+
+// RateLimits reads the account's rate limit, then sends the RateClassProbes and reads the headers of their responses
+func (a *OneLoginAPI) RateLimits() ([]RateStatus, error) {
+	account, err := a.RateLimit()
+	if err != nil {
+		return nil, err
+	}
+	out := []RateStatus{{Class: AccountRateClass, Reported: true, RateLimit: account}}
+	for _, probe := range RateClassProbes {
+		_, header, err := a.Do(http.MethodGet, probe.Path, url.Values{"limit": {"1"}}, nil)
+		if err != nil {
+			return nil, err
+		}
+		status := RateStatus{Class: probe.Class}
+		status.RateLimit, status.Reported = RateLimitFromHeader(header)
+		out = append(out, status)
+	}
+	return out, nil
+}
@@ -0,0 +1,67 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRateLimitFromHeader(t *testing.T) {
+	tests := map[string]struct {
+		Header           http.Header
+		Expected         RateLimit
+		ExpectedReported bool
+	}{
+		"It reads the headers": {
+			Header:           http.Header{"X-Ratelimit-Limit": {"5000"}, "X-Ratelimit-Remaining": {"42"}, "X-Ratelimit-Reset": {"120"}},
+			Expected:         RateLimit{Limit: 5000, Remaining: 42, Reset: 120},
+			ExpectedReported: true,
+		},
+		"It reads what's there": {
+			Header:           http.Header{"X-Ratelimit-Remaining": {"42"}},
+			Expected:         RateLimit{Remaining: 42},
+			ExpectedReported: true,
+		},
+		"It reports nothing without the remaining requests": {
+			Header: http.Header{"X-Ratelimit-Limit": {"5000"}},
+		},
+		"It reports nothing without headers": {},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, reported := RateLimitFromHeader(test.Header)
+			assert.Equal(t, test.Expected, actual)
+			assert.Equal(t, test.ExpectedReported, reported)
+		})
+	}
+}
+
+func TestRateLimits(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/oauth2/v2/token":
+			w.Write([]byte(`{"access_token":"token","expires_in":36000}`))
+		case "/auth/rate_limit":
+			w.Write([]byte(`{"data":{"X-RateLimit-Limit":5000,"X-RateLimit-Remaining":4990,"X-RateLimit-Reset":1200}}`))
+		case "/api/2/users":
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", "4989")
+			w.Header().Set("X-RateLimit-Reset", "1199")
+			w.Write([]byte(`[]`))
+		default:
+			w.Write([]byte(`{"data":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	api, err := New(ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL, Retry: RetryPolicy{MaxAttempts: 1}}).OneLoginAPI()
+	assert.Nil(t, err)
+	actual, err := api.RateLimits()
+	assert.Nil(t, err)
+	assert.Equal(t, []RateStatus{
+		{Class: "account", Reported: true, RateLimit: RateLimit{Limit: 5000, Remaining: 4990, Reset: 1200}},
+		{Class: "api/1"},
+		{Class: "api/2", Reported: true, RateLimit: RateLimit{Limit: 5000, Remaining: 4989, Reset: 1199}},
+	}, actual)
+}
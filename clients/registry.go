@@ -0,0 +1,52 @@
+package clients
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Constructor creates the client of a service from the clients' configs. It may get the clients it builds on from c
+type Constructor func(c *Clients) (interface{}, error)
+
+// constructors holds the constructor of each registered service by name
+var constructors = map[string]Constructor{}
+
+// Register adds the constructor of the named service's client. Services register from an init function, and a name
+// registered twice is a programming error
+func Register(name string, constructor Constructor) {
+	if _, ok := constructors[name]; ok {
+		panic(fmt.Sprintf("clients: %s is registered twice", name))
+	}
+	constructors[name] = constructor
+}
+
+// Registered lists the names of the services clients can be created for
+func Registered() []string {
+	names := make([]string, 0, len(constructors))
+	for name := range constructors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get creates the named service's client the first time it is asked for, and returns that instance on every
+// subsequent call. A client that couldn't be created is tried again on the next call
+func (c *Clients) Get(name string) (interface{}, error) {
+	if instance, ok := c.instances[name]; ok {
+		return instance, nil
+	}
+	constructor, ok := constructors[name]
+	if !ok {
+		return nil, fmt.Errorf("no client is registered for %s", name)
+	}
+	instance, err := constructor(c)
+	if err != nil {
+		return nil, err
+	}
+	if c.instances == nil {
+		c.instances = map[string]interface{}{}
+	}
+	c.instances[name] = instance
+	return instance, nil
+}
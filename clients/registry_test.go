@@ -0,0 +1,67 @@
+package clients
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testClient struct {
+	region string
+}
+
+func TestGet(t *testing.T) {
+	created := 0
+	Register("test_service", func(c *Clients) (interface{}, error) {
+		created++
+		if c.ClientConfigs.AwsRegion == "" {
+			return nil, errors.New("a region is required")
+		}
+		return &testClient{region: c.ClientConfigs.AwsRegion}, nil
+	})
+	defer delete(constructors, "test_service")
+
+	tests := map[string]struct {
+		Configs         ClientConfigs
+		Name            string
+		Expected        interface{}
+		ExpectedCreated int
+		ExpectedError   string
+	}{
+		"It creates the client once and memoizes it": {
+			Configs:         ClientConfigs{AwsRegion: "us-test-2"},
+			Name:            "test_service",
+			Expected:        &testClient{region: "us-test-2"},
+			ExpectedCreated: 1,
+		},
+		"It tries again after the client couldn't be created": {
+			Name:            "test_service",
+			ExpectedCreated: 2,
+			ExpectedError:   "a region is required",
+		},
+		"It reports services that aren't registered": {
+			Name:          "gcp_iam",
+			ExpectedError: "no client is registered for gcp_iam",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			created = 0
+			clnts := New(test.Configs)
+			clnts.Get(test.Name)
+			actual, err := clnts.Get(test.Name)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.Expected, actual)
+			assert.Equal(t, test.ExpectedCreated, created)
+		})
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	assert.Equal(t, []string{AwsIamService, OneLoginService, OneLoginAPIService, OneLoginPagesService}, Registered())
+	assert.Panics(t, func() { Register(OneLoginService, newOneLoginClient) })
+}
@@ -0,0 +1,146 @@
+package clients
+
+import (
+	"github.com/onelogin/onelogin/logger"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how API requests that were rate limited or hit a server error are retried
+type RetryPolicy struct {
+	MaxAttempts int           // attempts per request including the first, 1 disables retries
+	BaseDelay   time.Duration // backoff after the first failed attempt, doubled after every other
+	MaxDelay    time.Duration // cap on the backoff and on waits asked for with Retry-After
+}
+
+// DefaultRetryPolicy fills in the fields of a policy left zero
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second}
+
+// after is swapped out in tests so retries don't slow the suite down
+var after = time.After
+
+// jitter picks the random part of a backoff, swapped out in tests so delays are predictable
+var jitter = func(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.BaseDelay == 0 {
+		p.BaseDelay = DefaultRetryPolicy.BaseDelay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = DefaultRetryPolicy.MaxDelay
+	}
+	return p
+}
+
+// backoff is the wait after the failed attempt, exponential with the upper half jittered so clients that were
+// throttled together don't retry together
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay/2 + jitter(delay/2)
+}
+
+// NewRetryTransport retries the requests sent through next according to the policy. Rate limited requests are
+// retried whatever their method, as the API refused them before doing anything. Server and network errors are only
+// retried for methods safe to repeat
+func NewRetryTransport(next http.RoundTripper, policy RetryPolicy) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return retryTransport{next: next, policy: policy.withDefaults()}
+}
+
+type retryTransport struct {
+	next   http.RoundTripper
+	policy RetryPolicy
+}
+
+// RoundTrip satisfies http.RoundTripper
+func (t retryTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	attemptRequest := request
+	for attempt := 1; ; attempt++ {
+		response, err := t.next.RoundTrip(attemptRequest)
+		if attempt >= t.policy.MaxAttempts || !shouldRetry(request.Method, response, err) {
+			return response, err
+		}
+		if request.Body != nil && request.GetBody == nil {
+			return response, err // the body was consumed and can't be sent again
+		}
+		delay := t.policy.backoff(attempt)
+		reason := "error"
+		if response != nil {
+			reason = response.Status
+			if wait, ok := retryAfter(response.Header.Get("Retry-After")); ok {
+				delay = wait
+				if delay > t.policy.MaxDelay {
+					delay = t.policy.MaxDelay
+				}
+			}
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		} else {
+			reason = err.Error()
+		}
+		logger.Warn("API request failed, retrying", "method", request.Method, "path", request.URL.Path, "reason", reason, "delay", delay, "retry", attempt, "max_retries", t.policy.MaxAttempts-1)
+		select {
+		case <-request.Context().Done():
+			return nil, request.Context().Err()
+		case <-after(delay):
+		}
+		attemptRequest = request.Clone(request.Context())
+		if request.GetBody != nil {
+			if attemptRequest.Body, err = request.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// shouldRetry reports whether the attempt failed in a way a later attempt may not
+func shouldRetry(method string, response *http.Response, err error) bool {
+	idempotent := method == http.MethodGet || method == http.MethodHead || method == http.MethodPut || method == http.MethodDelete || method == http.MethodOptions
+	if err != nil {
+		return idempotent
+	}
+	switch {
+	case response.StatusCode == http.StatusTooManyRequests:
+		return true
+	case response.StatusCode >= 500 && response.StatusCode != http.StatusNotImplemented:
+		return idempotent
+	}
+	return false
+}
+
+// retryAfter reads a Retry-After header given in seconds or as a date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,128 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryTransport(t *testing.T) {
+	tests := map[string]struct {
+		Method           string
+		Statuses         []int
+		RetryAfter       string
+		Policy           RetryPolicy
+		ExpectedAttempts int
+		ExpectedStatus   int
+		ExpectedDelays   []time.Duration
+	}{
+		"It retries rate limited requests with backoff": {
+			Method:           http.MethodGet,
+			Statuses:         []int{429, 429, 200},
+			Policy:           RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			ExpectedAttempts: 3,
+			ExpectedStatus:   200,
+			ExpectedDelays:   []time.Duration{time.Second, 2 * time.Second},
+		},
+		"It waits as long as Retry-After asks": {
+			Method:           http.MethodPost,
+			Statuses:         []int{429, 201},
+			RetryAfter:       "3",
+			Policy:           RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			ExpectedAttempts: 2,
+			ExpectedStatus:   201,
+			ExpectedDelays:   []time.Duration{3 * time.Second},
+		},
+		"It caps Retry-After and backoff at the max delay": {
+			Method:           http.MethodGet,
+			Statuses:         []int{503, 503, 503, 200},
+			RetryAfter:       "120",
+			Policy:           RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			ExpectedAttempts: 4,
+			ExpectedStatus:   200,
+			ExpectedDelays:   []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		"It gives up after max attempts": {
+			Method:           http.MethodGet,
+			Statuses:         []int{500, 500, 500},
+			Policy:           RetryPolicy{MaxAttempts: 2, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			ExpectedAttempts: 2,
+			ExpectedStatus:   500,
+			ExpectedDelays:   []time.Duration{time.Second},
+		},
+		"It does not retry server errors of requests unsafe to repeat": {
+			Method:           http.MethodPost,
+			Statuses:         []int{500, 201},
+			Policy:           RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			ExpectedAttempts: 1,
+			ExpectedStatus:   500,
+		},
+		"It does not retry client errors": {
+			Method:           http.MethodGet,
+			Statuses:         []int{404, 200},
+			Policy:           RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: 10 * time.Second},
+			ExpectedAttempts: 1,
+			ExpectedStatus:   404,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var delays []time.Duration
+			after = func(d time.Duration) <-chan time.Time {
+				delays = append(delays, d)
+				ch := make(chan time.Time, 1)
+				ch <- time.Now()
+				return ch
+			}
+			defer func() { after = time.After }()
+			randomJitter := jitter
+			jitter = func(max time.Duration) time.Duration { return max }
+			defer func() { jitter = randomJitter }()
+
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := ioutil.ReadAll(r.Body)
+				assert.Equal(t, "payload", string(body))
+				if test.RetryAfter != "" {
+					w.Header().Set("Retry-After", test.RetryAfter)
+				}
+				w.WriteHeader(test.Statuses[attempts])
+				attempts++
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: NewRetryTransport(nil, test.Policy)}
+			request, _ := http.NewRequest(test.Method, server.URL, strings.NewReader("payload"))
+			response, err := client.Do(request)
+			assert.Nil(t, err)
+			response.Body.Close()
+			assert.Equal(t, test.ExpectedStatus, response.StatusCode)
+			assert.Equal(t, test.ExpectedAttempts, attempts)
+			assert.Equal(t, test.ExpectedDelays, delays)
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := map[string]struct {
+		Value      string
+		Expected   time.Duration
+		ExpectedOK bool
+	}{
+		"It reads seconds":              {Value: "2", Expected: 2 * time.Second, ExpectedOK: true},
+		"It reads dates in the past":    {Value: "Wed, 21 Oct 2015 07:28:00 GMT", Expected: 0, ExpectedOK: true},
+		"It ignores missing headers":    {Value: ""},
+		"It ignores unreadable headers": {Value: "soon"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wait, ok := retryAfter(test.Value)
+			assert.Equal(t, test.ExpectedOK, ok)
+			assert.Equal(t, test.Expected, wait)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// how long to wait for the token endpoint
+const tokenTimeout = 10 * time.Second
+
+// Token is an OAuth access token for the OneLogin API
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the token expires within the margin, so it isn't used for requests that outlive it
+func (t Token) Expired(margin time.Duration) bool {
+	return t.AccessToken == "" || time.Now().Add(margin).After(t.ExpiresAt)
+}
+
+// RequestToken requests a new access token with the OneLogin credentials in the configs
+func RequestToken(configs ClientConfigs) (Token, error) {
+	request, err := http.NewRequest(http.MethodPost, configs.OneLoginURL+"/auth/oauth2/v2/token", bytes.NewBufferString(`{"grant_type":"client_credentials"}`))
+	if err != nil {
+		return Token{}, err
+	}
+	if configs.Context != nil {
+		request = request.WithContext(configs.Context)
+	}
+	request.SetBasicAuth(configs.OneLoginClientID, configs.OneLoginClientSecret)
+	request.Header.Set("Content-Type", "application/json")
+	transport, err := configs.transport()
+	if err != nil {
+		return Token{}, err
+	}
+	requestedAt := time.Now()
+	response, err := (&http.Client{Timeout: tokenTimeout, Transport: configs.limit(configs.debug(configs.count(configs.fixtures(transport))))}).Do(request)
+	if err != nil {
+		return Token{}, fmt.Errorf("unable to reach %s: %s", configs.OneLoginURL, err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return Token{}, fmt.Errorf("token request was rejected with %s", response.Status)
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return Token{}, fmt.Errorf("unable to read token response: %s", err)
+	}
+	return Token{AccessToken: body.AccessToken, ExpiresAt: requestedAt.Add(time.Duration(body.ExpiresIn) * time.Second)}, nil
+}
+
+// VerifyOneLogin requests an access token with the OneLogin credentials in the configs to check they are valid
+// before they are used or saved. Nothing is kept from the response
+func VerifyOneLogin(configs ClientConfigs) error {
+	_, err := RequestToken(configs)
+	return err
+}
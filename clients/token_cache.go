@@ -0,0 +1,104 @@
+package clients
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// tokens expiring sooner than this are requested again rather than reused
+const tokenExpiryMargin = time.Minute
+
+// TokenCache keeps access tokens between CLI invocations in a JSON file only the user can read,
+// keyed by the API URL and client ID they were issued for
+type TokenCache struct {
+	Path string
+}
+
+// TokenCacheKey is the key the token for the credentials in the configs is cached under
+func TokenCacheKey(configs ClientConfigs) string {
+	return configs.OneLoginURL + "|" + configs.OneLoginClientID
+}
+
+// Token returns the cached token for the configs, requesting and caching a new one when there is none or it expired
+func (c TokenCache) Token(configs ClientConfigs) (Token, error) {
+	tokens, err := c.read()
+	if err != nil {
+		return Token{}, err
+	}
+	key := TokenCacheKey(configs)
+	if token, ok := tokens[key]; ok && !token.Expired(tokenExpiryMargin) {
+		return token, nil
+	}
+	token, err := RequestToken(configs)
+	if err != nil {
+		return Token{}, err
+	}
+	return token, c.Put(key, token)
+}
+
+// Get returns the token cached under the key, expired or not
+func (c TokenCache) Get(key string) (Token, bool, error) {
+	tokens, err := c.read()
+	if err != nil {
+		return Token{}, false, err
+	}
+	token, ok := tokens[key]
+	return token, ok, nil
+}
+
+// Put caches the token under the key, replacing any token already there
+func (c TokenCache) Put(key string, token Token) error {
+	tokens, err := c.read()
+	if err != nil {
+		return err
+	}
+	tokens[key] = token
+	return c.write(tokens)
+}
+
+// Delete removes the token cached under the key
+func (c TokenCache) Delete(key string) error {
+	tokens, err := c.read()
+	if err != nil {
+		return err
+	}
+	delete(tokens, key)
+	return c.write(tokens)
+}
+
+// Clear removes every cached token
+func (c TokenCache) Clear() error {
+	err := os.Remove(c.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (c TokenCache) read() (map[string]Token, error) {
+	tokens := map[string]Token{}
+	data, err := ioutil.ReadFile(c.Path)
+	if os.IsNotExist(err) || len(data) == 0 {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return map[string]Token{}, nil // a corrupt cache is discarded and rebuilt
+	}
+	return tokens, nil
+}
+
+func (c TokenCache) write(tokens map[string]Token) error {
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.Path, data, 0600); err != nil {
+		return err
+	}
+	return os.Chmod(c.Path, 0600) // WriteFile only sets the mode of new files
+}
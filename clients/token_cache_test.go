@@ -0,0 +1,82 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTokenCacheToken(t *testing.T) {
+	tests := map[string]struct {
+		Cached           *Token
+		ExpectedToken    string
+		ExpectedRequests int
+	}{
+		"It requests and caches a token when none is cached": {
+			ExpectedToken:    "new",
+			ExpectedRequests: 1,
+		},
+		"It reuses a cached token": {
+			Cached:        &Token{AccessToken: "cached", ExpiresAt: time.Now().Add(time.Hour)},
+			ExpectedToken: "cached",
+		},
+		"It requests a new token when the cached one is about to expire": {
+			Cached:           &Token{AccessToken: "cached", ExpiresAt: time.Now().Add(time.Second)},
+			ExpectedToken:    "new",
+			ExpectedRequests: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Write([]byte(`{"access_token":"new","expires_in":36000}`))
+			}))
+			defer server.Close()
+			dir, _ := ioutil.TempDir("", "tokens")
+			defer os.RemoveAll(dir)
+
+			configs := ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL}
+			cache := TokenCache{Path: filepath.Join(dir, "tokens.json")}
+			if test.Cached != nil {
+				cache.Put(TokenCacheKey(configs), *test.Cached)
+			}
+			token, err := cache.Token(configs)
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedToken, token.AccessToken)
+			assert.Equal(t, test.ExpectedRequests, requests)
+
+			cached, ok, err := cache.Get(TokenCacheKey(configs))
+			assert.Nil(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, test.ExpectedToken, cached.AccessToken)
+			info, _ := os.Stat(cache.Path)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		})
+	}
+}
+
+func TestTokenCacheDelete(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "tokens")
+	defer os.RemoveAll(dir)
+	cache := TokenCache{Path: filepath.Join(dir, "tokens.json")}
+	cache.Put("a", Token{AccessToken: "a"})
+	cache.Put("b", Token{AccessToken: "b"})
+
+	assert.Nil(t, cache.Delete("a"))
+	_, ok, _ := cache.Get("a")
+	assert.False(t, ok)
+	_, ok, _ = cache.Get("b")
+	assert.True(t, ok)
+
+	assert.Nil(t, cache.Clear())
+	_, ok, _ = cache.Get("b")
+	assert.False(t, ok)
+	assert.Nil(t, cache.Clear())
+}
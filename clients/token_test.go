@@ -0,0 +1,59 @@
+package clients
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestVerifyOneLogin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if r.URL.Path != "/auth/oauth2/v2/token" || !ok || id != "id" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"access_token":"token","expires_in":36000}`))
+	}))
+	defer server.Close()
+
+	tests := map[string]struct {
+		Configs       ClientConfigs
+		ExpectedError string
+	}{
+		"It accepts valid credentials": {
+			Configs: ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL},
+		},
+		"It rejects invalid credentials": {
+			Configs:       ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "wrong", OneLoginURL: server.URL},
+			ExpectedError: "token request was rejected with 401 Unauthorized",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := VerifyOneLogin(test.Configs)
+			if test.ExpectedError == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestRequestToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"token","expires_in":36000}`))
+	}))
+	defer server.Close()
+
+	before := time.Now()
+	token, err := RequestToken(ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL})
+	assert.Nil(t, err)
+	assert.Equal(t, "token", token.AccessToken)
+	assert.WithinDuration(t, before.Add(10*time.Hour), token.ExpiresAt, time.Minute)
+	assert.False(t, token.Expired(time.Minute))
+	assert.True(t, token.Expired(11*time.Hour))
+}
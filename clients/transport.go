@@ -0,0 +1,36 @@
+package clients
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// transport is the base of every API request: proxied per HTTPS_PROXY, HTTP_PROXY and NO_PROXY, and trusting the
+// certificates in the CA bundle on top of the system's
+func (c ClientConfigs) transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if c.CABundle == "" && !c.InsecureSkipVerify {
+		return transport, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify} // #nosec G402 only when asked for with --insecure-skip-verify
+	if c.CABundle != "" {
+		pem, err := ioutil.ReadFile(c.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the CA bundle: %s", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in the CA bundle %s", c.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
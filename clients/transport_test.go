@@ -0,0 +1,64 @@
+package clients
+
+import (
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	dir, _ := ioutil.TempDir("", "ca")
+	defer os.RemoveAll(dir)
+	bundle := filepath.Join(dir, "ca.pem")
+	ioutil.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0600)
+	empty := filepath.Join(dir, "empty.pem")
+	ioutil.WriteFile(empty, []byte("not a certificate"), 0600)
+
+	tests := map[string]struct {
+		Configs             ClientConfigs
+		ExpectedError       string
+		ExpectedRequestFail bool
+	}{
+		"It rejects unknown certificates by default": {
+			ExpectedRequestFail: true,
+		},
+		"It trusts the certificates in the CA bundle": {
+			Configs: ClientConfigs{CABundle: bundle},
+		},
+		"It skips verification when asked to": {
+			Configs: ClientConfigs{InsecureSkipVerify: true},
+		},
+		"It reports missing CA bundles": {
+			Configs:       ClientConfigs{CABundle: filepath.Join(dir, "missing.pem")},
+			ExpectedError: "unable to read the CA bundle: open " + filepath.Join(dir, "missing.pem") + ": no such file or directory",
+		},
+		"It reports CA bundles without certificates": {
+			Configs:       ClientConfigs{CABundle: empty},
+			ExpectedError: "no PEM certificates found in the CA bundle " + empty,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			transport, err := test.Configs.transport()
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			response, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if test.ExpectedRequestFail {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			response.Body.Close()
+		})
+	}
+}
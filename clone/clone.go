@@ -0,0 +1,152 @@
+// Package clone clone.go
+// This module turns an app read from one OneLogin account into the requests creating an equivalent app in another,
+// e.g. to promote an app configured in a sandbox to production. Ids only meaningful in the account the app was read
+// from are dropped, except for role ids which are remapped to the roles with the same name in the other account.
+//
+// Role references
+// Roles are remapped in the app's role_ids, in rule conditions on has_role, and in the values of rule actions
+// setting roles. Roles with no namesake in the other account are reported rather than silently dropped.
+package clone
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"sort"
+	"strings"
+)
+
+// app fields the API sets itself, or that refer to resources of the account the app was read from
+var droppedAppFields = []string{"id", "created_at", "updated_at", "icon_url", "sso", "role_ids", "policy_id", "brand_id", "tab_id"}
+
+// RoleMap is the id of every role of the other account by the id of its namesake in the account the app is read from
+type RoleMap map[string]string
+
+// MapRoles matches the roles of the two accounts by name, returning the names of the source roles the target lacks
+// by their id
+func MapRoles(source []records.Record, target []records.Record) (RoleMap, map[string]string) {
+	byName := map[string]string{}
+	for _, role := range target {
+		byName[role.Text("name")] = role.Text("id")
+	}
+	roles, missing := RoleMap{}, map[string]string{}
+	for _, role := range source {
+		if id, ok := byName[role.Text("name")]; ok {
+			roles[role.Text("id")] = id
+		} else {
+			missing[role.Text("id")] = role.Text("name")
+		}
+	}
+	return roles, missing
+}
+
+// Cloner remaps the role references of apps and their rules. Unmapped role ids are collected in Missing and left out
+type Cloner struct {
+	Roles   RoleMap
+	Missing map[string]bool
+}
+
+// App is the body creating the app, under the new name when given
+func (c *Cloner) App(app records.Record, name string) records.Record {
+	out := records.Merge(app)
+	for _, field := range droppedAppFields {
+		delete(out, field)
+	}
+	if name != "" {
+		out["name"] = name
+	}
+	if roleIDs := c.remapList(app["role_ids"]); len(roleIDs) > 0 {
+		out["role_ids"] = roleIDs
+	}
+	return out
+}
+
+// Rule is the body creating the rule for the cloned app
+func (c *Cloner) Rule(rule records.Record) records.Record {
+	out := records.Merge(rule)
+	delete(out, "id")
+	if conditions, ok := rule["conditions"].([]interface{}); ok {
+		out["conditions"] = c.remapEach(conditions, func(item map[string]interface{}) bool {
+			return item["source"] == "has_role"
+		})
+	}
+	if actions, ok := rule["actions"].([]interface{}); ok {
+		out["actions"] = c.remapEach(actions, func(item map[string]interface{}) bool {
+			name, _ := item["action"].(string)
+			return strings.Contains(name, "role")
+		})
+	}
+	return out
+}
+
+// remapEach copies the conditions or actions, remapping the values of those referring to roles
+func (c *Cloner) remapEach(list []interface{}, refersToRoles func(map[string]interface{}) bool) []interface{} {
+	out := make([]interface{}, len(list))
+	for i, item := range list {
+		out[i] = item
+		if object, ok := item.(map[string]interface{}); ok && refersToRoles(object) {
+			out[i] = with(object, "value", c.remapValue(object["value"]))
+		}
+	}
+	return out
+}
+
+// MissingRoles lists the role ids that had no namesake, sorted
+func (c *Cloner) MissingRoles() []string {
+	out := []string{}
+	for id := range c.Missing {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// remapValue remaps a role id, or a list of them, keeping values that aren't role ids as they are
+func (c *Cloner) remapValue(value interface{}) interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return c.remapList(list)
+	}
+	if value == nil {
+		return nil
+	}
+	id := fmt.Sprint(value)
+	if mapped, ok := c.Roles[id]; ok {
+		return mapped
+	}
+	c.missing(id)
+	return value
+}
+
+// remapList remaps a list of role ids, leaving out those without a namesake
+func (c *Cloner) remapList(value interface{}) []interface{} {
+	list, _ := value.([]interface{})
+	out := []interface{}{}
+	for _, item := range list {
+		id := fmt.Sprint(item)
+		if mapped, ok := c.Roles[id]; !ok {
+			c.missing(id)
+		} else if _, isString := item.(string); isString {
+			out = append(out, mapped) // rules give ids as strings, keep them that way
+		} else {
+			out = append(out, json.Number(mapped))
+		}
+	}
+	return out
+}
+
+func (c *Cloner) missing(id string) {
+	if c.Missing == nil {
+		c.Missing = map[string]bool{}
+	}
+	c.Missing[id] = true
+}
+
+// with copies the object with the field set
+func with(object map[string]interface{}, field string, value interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for key, item := range object {
+		out[key] = item
+	}
+	out[field] = value
+	return out
+}
@@ -0,0 +1,63 @@
+package clone
+
+import (
+	"encoding/json"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMapRoles(t *testing.T) {
+	source := mustRecords(`[{"id":1,"name":"Engineering"},{"id":2,"name":"Sales"},{"id":3,"name":"Interns"}]`)
+	target := mustRecords(`[{"id":10,"name":"Sales"},{"id":20,"name":"Engineering"}]`)
+	roles, missing := MapRoles(source, target)
+	assert.Equal(t, RoleMap{"1": "20", "2": "10"}, roles)
+	assert.Equal(t, map[string]string{"3": "Interns"}, missing)
+}
+
+func TestApp(t *testing.T) {
+	tests := map[string]struct {
+		App             string
+		Name            string
+		Expected        string
+		ExpectedMissing []string
+	}{
+		"It drops fields of the source account and remaps roles": {
+			App:             `{"id":5,"name":"Salesforce","connector_id":110016,"policy_id":9,"role_ids":[1,3],"sso":{"metadata_url":"x"},"configuration":{"consumer_url":"https://sf.example.com/acs"}}`,
+			Expected:        `{"configuration":{"consumer_url":"https://sf.example.com/acs"},"connector_id":110016,"name":"Salesforce","role_ids":[20]}`,
+			ExpectedMissing: []string{"3"},
+		},
+		"It renames the app": {
+			App:             `{"id":5,"name":"Salesforce","connector_id":110016}`,
+			Name:            "Salesforce (staging)",
+			Expected:        `{"connector_id":110016,"name":"Salesforce (staging)"}`,
+			ExpectedMissing: []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cloner := &Cloner{Roles: RoleMap{"1": "20", "2": "10"}}
+			actual, _ := json.Marshal(cloner.App(mustRecords(test.App)[0], test.Name))
+			assert.JSONEq(t, test.Expected, string(actual))
+			assert.Equal(t, test.ExpectedMissing, cloner.MissingRoles())
+		})
+	}
+}
+
+func TestRule(t *testing.T) {
+	rule := mustRecords(`{"id":8,"name":"Admins","conditions":[{"source":"has_role","operator":"ri","value":"1"},{"source":"member_of","operator":"=","value":"1"}],"actions":[{"action":"set_role","value":["2","3"]},{"action":"set_nameid","value":["email"]}]}`)[0]
+	cloner := &Cloner{Roles: RoleMap{"1": "20", "2": "10"}}
+	actual, _ := json.Marshal(cloner.Rule(rule))
+	assert.JSONEq(t, `{"name":"Admins","conditions":[{"source":"has_role","operator":"ri","value":"20"},{"source":"member_of","operator":"=","value":"1"}],"actions":[{"action":"set_role","value":["10"]},{"action":"set_nameid","value":["email"]}]}`, string(actual))
+	assert.Equal(t, []string{"3"}, cloner.MissingRoles())
+	original, _ := json.Marshal(rule)
+	assert.Contains(t, string(original), `"value":"1"`, "the rule read is left as it was")
+}
+
+func mustRecords(data string) []records.Record {
+	list, err := records.FromJSON([]byte(data))
+	if err != nil {
+		panic(err)
+	}
+	return list
+}
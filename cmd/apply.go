@@ -0,0 +1,315 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/desired"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var (
+		path   string
+		dryRun bool
+		yes    bool
+	)
+	var applyCommand = &cobra.Command{
+		Use:   "apply",
+		Short: `Reconcile roles, mappings and hook env vars with YAML definitions.`,
+		Long: `Reads the desired roles, mappings and hook env vars from the YAML files of the -f directory, or from the
+		one file given, then previews and after confirmation makes the changes reconciling the account with them.
+		Only the types the files name are managed, and the account's resources of those types the files don't
+		declare are deleted. Roles list their members by email, mappings refer to roles by name, and hook env vars
+		take their values from the environment variables of the same name.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := apply(clientConfigs, path, dryRun, yes); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	applyCommand.Flags().StringVarP(&path, "file", "f", "", "Directory of YAML files, or a YAML file, declaring the desired state")
+	applyCommand.Flags().BoolVar(&dryRun, "dry-run", false, "Print the plan without making the changes")
+	applyCommand.Flags().BoolVarP(&yes, "yes", "y", false, "Make the changes without asking for confirmation")
+	applyCommand.MarkFlagRequired("file")
+	rootCmd.AddCommand(applyCommand)
+}
+
+// applyLive is what the account holds of the managed types
+type applyLive struct {
+	roles    []records.Record
+	members  map[string][]string // emails of the users of each role, by role id
+	userIDs  map[string]int      // by lowercased email
+	mappings []records.Record
+	envs     []records.Record
+}
+
+// apply previews the changes reconciling the account with the desired state in the path, then makes them
+func apply(clientConfigs clients.ClientConfigs, path string, dryRun bool, yes bool) error {
+	state, err := desired.Load(path)
+	if err != nil {
+		return err
+	}
+	values := map[string]string{}
+	missing := []string{}
+	for _, name := range state.HookEnvVars {
+		if value, ok := os.LookupEnv(name); ok {
+			values[name] = value
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("set the environment variables %s for the hook env vars", strings.Join(missing, ", "))
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	live, err := fetchApplyLive(clientConfigs, api, state)
+	if err != nil {
+		return err
+	}
+
+	roleChanges := []desired.Change{}
+	if state.Managed[desired.RolesType] {
+		roleChanges = desired.PlanRoles(state.Roles, live.roles, live.members)
+	}
+	unknown := map[string]bool{}
+	for _, change := range roleChanges {
+		for _, email := range change.Add {
+			if _, ok := live.userIDs[strings.ToLower(email)]; !ok {
+				unknown[email] = true
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		emails := []string{}
+		for email := range unknown {
+			emails = append(emails, email)
+		}
+		sort.Strings(emails)
+		return fmt.Errorf("no users have the emails %s", strings.Join(emails, ", "))
+	}
+	roleIDs := applyRoleIDs(live.roles, roleChanges)
+	mappingChanges, err := planApplyMappings(state, live, roleIDs)
+	if err != nil {
+		return err
+	}
+	envChanges := []desired.Change{}
+	if state.Managed[desired.HookEnvVarsType] {
+		envChanges = desired.PlanHookEnvVars(state.HookEnvVars, live.envs)
+	}
+	changes := append(append(append([]desired.Change{}, roleChanges...), mappingChanges...), envChanges...)
+	desired.WritePlan(os.Stdout, changes)
+	if len(changes) == 0 || dryRun {
+		return nil
+	}
+	if proceed, err := confirm(yes, "Do you want to make these changes?"); !proceed {
+		return err
+	}
+
+	for _, change := range roleChanges {
+		id, err := applyRoleChange(api, change, live.userIDs)
+		if err != nil {
+			return err
+		}
+		if change.Action == desired.Create {
+			roleIDs[change.Name] = id
+		}
+	}
+	// planned again so mappings can refer to the roles just created
+	if mappingChanges, err = planApplyMappings(state, live, roleIDs); err != nil {
+		return err
+	}
+	for _, change := range mappingChanges {
+		if err := applyMappingChange(api, change); err != nil {
+			return err
+		}
+	}
+	for _, change := range envChanges {
+		if change.Action == desired.Delete {
+			if _, _, err := api.Do(http.MethodDelete, "/api/2/hooks/envs/"+change.ID, nil, nil); err != nil {
+				return fmt.Errorf("unable to delete hook env var %s: %s", change.Name, err)
+			}
+			logger.Info("Deleted hook env var", "name", change.Name)
+		} else if err := setHookEnv(api, live.envs, change.Name, values[change.Name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchApplyLive fetches what the account holds of the managed types, and the roles mappings refer to
+func fetchApplyLive(clientConfigs clients.ClientConfigs, api *clients.OneLoginAPI, state desired.State) (applyLive, error) {
+	live := applyLive{members: map[string][]string{}, userIDs: map[string]int{}}
+	var err error
+	if state.Managed[desired.RolesType] || state.Managed[desired.MappingsType] {
+		if live.roles, err = fetchAll(clientConfigs, "/api/2/roles", nil); err != nil {
+			return live, err
+		}
+	}
+	listsMembers := map[string]bool{}
+	for _, role := range state.Roles {
+		if role.Users != nil {
+			listsMembers[role.Name] = true
+		}
+	}
+	if state.Managed[desired.RolesType] && len(listsMembers) > 0 {
+		users, err := fetchUsers(clientConfigs, nil)
+		if err != nil {
+			return live, err
+		}
+		emails := map[string]string{}
+		for _, user := range users {
+			id, _ := strconv.Atoi(user.Text("id"))
+			email := strings.ToLower(user.Text("email"))
+			emails[user.Text("id")], live.userIDs[email] = email, id
+		}
+		for _, role := range live.roles {
+			if !listsMembers[role.Text("name")] {
+				continue
+			}
+			members, err := fetchAll(clientConfigs, "/api/2/roles/"+role.Text("id")+"/users", nil)
+			if err != nil {
+				return live, err
+			}
+			for _, member := range members {
+				live.members[role.Text("id")] = append(live.members[role.Text("id")], emails[member.Text("id")])
+			}
+		}
+	}
+	if state.Managed[desired.MappingsType] {
+		enabled, err := fetchMappings(api, true)
+		if err != nil {
+			return live, err
+		}
+		disabled, err := fetchMappings(api, false)
+		if err != nil {
+			return live, err
+		}
+		live.mappings = append(enabled, disabled...)
+	}
+	if state.Managed[desired.HookEnvVarsType] {
+		if live.envs, err = fetchHookEnvs(api); err != nil {
+			return live, err
+		}
+	}
+	return live, nil
+}
+
+// applyRoleIDs are the ids of the roles by name once the role changes are made, with those yet to be created
+// standing in as desired.NewRoleID
+func applyRoleIDs(roles []records.Record, changes []desired.Change) map[string]string {
+	out := map[string]string{}
+	for _, role := range roles {
+		out[role.Text("name")] = role.Text("id")
+	}
+	for _, change := range changes {
+		switch change.Action {
+		case desired.Create:
+			out[change.Name] = desired.NewRoleID
+		case desired.Delete:
+			delete(out, change.Name)
+		}
+	}
+	return out
+}
+
+// planApplyMappings plans the mapping changes when mappings are managed
+func planApplyMappings(state desired.State, live applyLive, roleIDs map[string]string) ([]desired.Change, error) {
+	if !state.Managed[desired.MappingsType] {
+		return nil, nil
+	}
+	return desired.PlanMappings(state.Mappings, live.mappings, roleIDs)
+}
+
+// applyRoleChange creates, updates the members of, or deletes the role, returning its id
+func applyRoleChange(api *clients.OneLoginAPI, change desired.Change, userIDs map[string]int) (string, error) {
+	switch change.Action {
+	case desired.Delete:
+		if _, _, err := api.Do(http.MethodDelete, "/api/2/roles/"+change.ID, nil, nil); err != nil {
+			return "", fmt.Errorf("unable to delete role %s: %s", change.Name, err)
+		}
+		logger.Info("Deleted role", "id", change.ID, "name", change.Name)
+		return change.ID, nil
+	case desired.Create:
+		body, _ := json.Marshal(map[string]string{"name": change.Name})
+		data, _, err := api.Do(http.MethodPost, "/api/2/roles", nil, body)
+		if err != nil {
+			return "", fmt.Errorf("unable to create role %s: %s", change.Name, err)
+		}
+		created, err := records.FromJSON(data)
+		if err != nil || len(created) == 0 {
+			return "", fmt.Errorf("unable to read created role: %s", data)
+		}
+		change.ID = created[0].Text("id")
+		logger.Info("Created role", "id", change.ID, "name", change.Name)
+	}
+	roleID, _ := strconv.Atoi(change.ID)
+	idsOf := func(emails []string) []int {
+		out := make([]int, len(emails))
+		for i, email := range emails {
+			out[i] = userIDs[strings.ToLower(email)]
+		}
+		return out
+	}
+	if len(change.Add) > 0 {
+		if err := sendRoleUsers(api, http.MethodPost, roleID, idsOf(change.Add)); err != nil {
+			return "", err
+		}
+	}
+	if len(change.Remove) > 0 {
+		if err := sendRoleUsers(api, http.MethodDelete, roleID, idsOf(change.Remove)); err != nil {
+			return "", err
+		}
+	}
+	if len(change.Add) > 0 || len(change.Remove) > 0 {
+		logger.Info("Synced role", "role", change.Name, "added", len(change.Add), "removed", len(change.Remove))
+	}
+	return change.ID, nil
+}
+
+// applyMappingChange creates, updates or deletes the mapping
+func applyMappingChange(api *clients.OneLoginAPI, change desired.Change) error {
+	if change.Action == desired.Delete {
+		if _, _, err := api.Do(http.MethodDelete, "/api/2/mappings/"+change.ID, nil, nil); err != nil {
+			return fmt.Errorf("unable to delete mapping %s: %s", change.Name, err)
+		}
+		logger.Info("Deleted mapping", "id", change.ID, "name", change.Name)
+		return nil
+	}
+	body, err := json.Marshal(change.Body)
+	if err != nil {
+		return err
+	}
+	if change.Action == desired.Update {
+		if _, _, err := api.Do(http.MethodPut, "/api/2/mappings/"+change.ID, nil, body); err != nil {
+			return fmt.Errorf("unable to update mapping %s: %s", change.Name, err)
+		}
+		logger.Info("Updated mapping", "id", change.ID, "name", change.Name)
+		return nil
+	}
+	data, _, err := api.Do(http.MethodPost, "/api/2/mappings", nil, body)
+	if err != nil {
+		return fmt.Errorf("unable to create mapping %s: %s", change.Name, err)
+	}
+	created, err := records.FromJSON(data)
+	if err != nil || len(created) == 0 {
+		return fmt.Errorf("unable to read created mapping: %s", data)
+	}
+	logger.Info("Created mapping", "id", created[0].Text("id"), "name", change.Name)
+	return nil
+}
@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/clone"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/sso"
+	"github.com/spf13/cobra"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auth_method of the apps --connector selects by name
+var appConnectors = map[string]string{
+	"password": "0",
+	"openid":   "1",
+	"saml":     "2",
+	"api":      "3",
+	"google":   "4",
+	"forms":    "6",
+	"wsfed":    "7",
+	"oidc":     "8",
+}
+
+// app fields listed by default
+var appListFields = []string{"id", "name", "connector_id", "auth_method", "visible"}
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var appsCommand = &cobra.Command{
+		Use:   "apps",
+		Short: `Look up and copy OneLogin apps.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var (
+		connector  string
+		filters    []string
+		listOutput outputFlags
+	)
+	var appsListCommand = &cobra.Command{
+		Use:   "list",
+		Short: `List apps, optionally filtered.`,
+		Long: `Lists the account's apps with the fields given by --fields, which can include sign in details like
+		acs_url and certificate_expires_at. --connector keeps the apps signing in with saml, oidc, openid, wsfed,
+		password, forms, api or google, or built from the connector with the id given.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listApps(clientConfigs, connector, filters, listOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	appsListCommand.Flags().StringVar(&connector, "connector", "", "Keep apps signing in with saml, oidc, openid, wsfed, password, forms, api or google, or from the connector with this id")
+	appsListCommand.Flags().StringArrayVar(&filters, "filter", nil, "Keep apps whose field matches e.g. name~salesforce or visible=true. Repeat to require several")
+	addOutputFlags(appsListCommand, &listOutput, appListFields, records.TableFormat)
+
+	var (
+		searchConnector string
+		searchOutput    outputFlags
+	)
+	var appsSearchCommand = &cobra.Command{
+		Use:    "search <text>",
+		Short:  `List the apps whose name contains the text, ignoring case.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listApps(clientConfigs, searchConnector, []string{"name~" + args[0]}, searchOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	appsSearchCommand.Flags().StringVar(&searchConnector, "connector", "", "Keep apps signing in with saml, oidc, openid, wsfed, password, forms, api or google, or from the connector with this id")
+	addOutputFlags(appsSearchCommand, &searchOutput, appListFields, records.TableFormat)
+
+	var getOutput outputFlags
+	var appsGetCommand = &cobra.Command{
+		Use:   "get <id>",
+		Short: `Print an app with its sign in details.`,
+		Long: `Prints every field of the app, with its sign in details summarized: acs_url, metadata_url, issuer,
+		sls_url and client_id, and the signing certificate's certificate_name, certificate_expires_at and
+		certificate_days_left.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeList(lookupIDs("/api/2/apps"))),
+		PreRun:            loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := getApp(clientConfigs, args[0], getOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(appsGetCommand, &getOutput, nil, records.JSONFormat)
+
+	var cloneFlags appCloneFlags
+	var appsCloneCommand = &cobra.Command{
+		Use:   "clone <id>",
+		Short: `Copy an app to another account.`,
+		Long: `Reads the app with its parameters, configuration and rules from the account in use, and creates an
+		equivalent app in the account of --to-profile. Role references are remapped to the roles of the same name
+		there, and the command stops if a role has no namesake unless --ignore-missing-roles is given. Policies,
+		brands, tabs and signing certificates belong to an account and are left for the new app to default.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeList(lookupIDs("/api/2/apps"))),
+		PreRun:            loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := cloneApp(clientConfigs, args[0], cloneFlags); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	appsCloneCommand.Flags().StringVar(&cloneFlags.toProfile, "to-profile", "", "Profile of the account to create the app in")
+	appsCloneCommand.Flags().StringVar(&cloneFlags.name, "name", "", "Name of the new app (defaults to the name of the app cloned)")
+	appsCloneCommand.Flags().BoolVar(&cloneFlags.ignoreMissingRoles, "ignore-missing-roles", false, "Leave out references to roles the other account has no namesake for instead of stopping")
+	appsCloneCommand.Flags().BoolVar(&cloneFlags.dryRun, "dry-run", false, "Print the app and rules that would be created without creating them")
+	appsCloneCommand.MarkFlagRequired("to-profile")
+	appsCloneCommand.RegisterFlagCompletionFunc("to-profile", completeList(profileNames))
+
+	appsCommand.AddCommand(appsListCommand, appsSearchCommand, appsGetCommand, appsCloneCommand)
+	rootCmd.AddCommand(appsCommand)
+}
+
+// listApps prints the apps of the connector matching every filter
+func listApps(clientConfigs clients.ClientConfigs, connector string, filterFlags []string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	filters, err := records.ParseFilters(filterFlags)
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	if connector != "" {
+		if _, err := strconv.Atoi(connector); err == nil {
+			query.Set("connector_id", connector)
+		} else if authMethod, ok := appConnectors[strings.ToLower(connector)]; ok {
+			query.Set("auth_method", authMethod)
+		} else {
+			return fmt.Errorf("unknown connector %s, expected a connector id or one of %s", connector, strings.Join(appConnectorNames(), ", "))
+		}
+	}
+	pages, err := clients.New(clientConfigs).OneLoginPages()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	apps := []records.Record{}
+	err = pages.StreamPages("/api/2/apps", query, func(page []byte) error {
+		pageApps, err := records.FromJSON(page)
+		if err != nil {
+			return fmt.Errorf("unable to read apps: %s", err)
+		}
+		for _, app := range pageApps {
+			app = records.Merge(app, sso.Summary(app, now))
+			if records.MatchAll(app, filters) {
+				apps = append(apps, app)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list apps: %s", err)
+	}
+	return output.write(apps)
+}
+
+// getApp prints the app with the id and its sign in details
+func getApp(clientConfigs clients.ClientConfigs, id string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	appID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid app id %s", id)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	data, _, err := api.Do(http.MethodGet, fmt.Sprintf("/api/2/apps/%d", appID), nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get app %d: %s", appID, err)
+	}
+	list, err := records.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("unable to read app %d: %s", appID, err)
+	}
+	return output.writeOne(records.Merge(list[0], sso.Summary(list[0], time.Now())))
+}
+
+func appConnectorNames() []string {
+	names := []string{}
+	for name := range appConnectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// appCloneFlags are the flags of apps clone
+type appCloneFlags struct {
+	toProfile          string
+	name               string
+	ignoreMissingRoles bool
+	dryRun             bool
+}
+
+// cloneApp creates the app, and its rules, in the account of the profile with role references remapped by name
+func cloneApp(clientConfigs clients.ClientConfigs, id string, flags appCloneFlags) error {
+	appID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid app id %s", id)
+	}
+	targetConfigs := profileClientConfigs(loadProfiles(flags.toProfile)[0])
+	source, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	data, _, err := source.Do(http.MethodGet, fmt.Sprintf("/api/2/apps/%d", appID), nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get app %d: %s", appID, err)
+	}
+	app, err := records.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("unable to read app %d: %s", appID, err)
+	}
+	if data, _, err = source.Do(http.MethodGet, fmt.Sprintf("/api/2/apps/%d/rules", appID), nil, nil); err != nil {
+		return fmt.Errorf("unable to get the rules of app %d: %s", appID, err)
+	}
+	rules, err := records.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("unable to read the rules of app %d: %s", appID, err)
+	}
+	sourceRoles, err := fetchAll(clientConfigs, "/api/2/roles", nil)
+	if err != nil {
+		return err
+	}
+	targetRoles, err := fetchAll(targetConfigs, "/api/2/roles", nil)
+	if err != nil {
+		return err
+	}
+
+	roles, unmatched := clone.MapRoles(sourceRoles, targetRoles)
+	cloner := &clone.Cloner{Roles: roles}
+	newApp := cloner.App(app[0], flags.name)
+	newRules := make([]records.Record, len(rules))
+	for i, rule := range rules {
+		newRules[i] = cloner.Rule(rule)
+	}
+	if missing := cloner.MissingRoles(); len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, id := range missing {
+			names[i] = fmt.Sprintf("%s (%s)", unmatched[id], id)
+		}
+		if !flags.ignoreMissingRoles {
+			return fmt.Errorf("roles %s have no namesake in profile %s, create them or give --ignore-missing-roles", strings.Join(names, ", "), flags.toProfile)
+		}
+		logger.Warn("Leaving out references to roles with no namesake", "roles", strings.Join(names, ", "), "profile", flags.toProfile)
+	}
+
+	if flags.dryRun {
+		logger.Info("Dry run, nothing was created", "profile", flags.toProfile, "rules", len(newRules))
+		out, err := json.MarshalIndent(map[string]interface{}{"app": newApp, "rules": newRules}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	target, err := clients.New(targetConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(newApp)
+	if err != nil {
+		return err
+	}
+	if data, _, err = target.Do(http.MethodPost, "/api/2/apps", nil, body); err != nil {
+		return fmt.Errorf("unable to create app in profile %s: %s", flags.toProfile, err)
+	}
+	created, err := records.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("unable to read created app: %s", err)
+	}
+	newID := created[0].Text("id")
+	for i, rule := range newRules {
+		if body, err = json.Marshal(rule); err != nil {
+			return err
+		}
+		if _, _, err = target.Do(http.MethodPost, "/api/2/apps/"+newID+"/rules", nil, body); err != nil {
+			return fmt.Errorf("created app %s but not rule %d (%s): %s", newID, i+1, rule.Text("name"), err)
+		}
+	}
+	logger.Info("Cloned app", "id", appID, "new_id", newID, "profile", flags.toProfile, "rules", len(newRules))
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+func init() {
+	var authCommand = &cobra.Command{
+		Use:   "auth",
+		Short: `Manage the cached OneLogin access tokens.`,
+		Long: `Access tokens are cached per set of credentials in tokens.json next to the profiles file, readable only by you,
+		and reused by every command until they expire, when a new one is requested automatically.
+		Use --profile or ONELOGIN_PROFILE to manage the token of a profile other than the active one.`,
+	}
+
+	var clientConfigs clients.ClientConfigs
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	authCommand.AddCommand(&cobra.Command{
+		Use:    "login",
+		Short:  `Request a new access token and cache it.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			token, err := clients.RequestToken(clientConfigs)
+			if err != nil {
+				logger.FatalCode(exitCode(err), "Unable to log in", "error", err)
+			}
+			if err := clientConfigs.TokenCache.Put(clients.TokenCacheKey(clientConfigs), token); err != nil {
+				logger.Fatal("Unable to cache token", "error", err)
+			}
+			fmt.Println("Logged in to", clientConfigs.OneLoginURL, "until", token.ExpiresAt.Local().Format(time.RFC1123))
+		},
+	})
+
+	authCommand.AddCommand(&cobra.Command{
+		Use:    "status",
+		Short:  `Show whether a valid access token is cached.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			token, ok, err := clientConfigs.TokenCache.Get(clients.TokenCacheKey(clientConfigs))
+			if err != nil {
+				logger.Fatal("Unable to read token cache", "error", err)
+			}
+			switch {
+			case !ok:
+				fmt.Println("Not logged in to", clientConfigs.OneLoginURL)
+			case token.Expired(0):
+				fmt.Println("Token for", clientConfigs.OneLoginURL, "expired at", token.ExpiresAt.Local().Format(time.RFC1123))
+			default:
+				fmt.Println("Logged in to", clientConfigs.OneLoginURL, "until", token.ExpiresAt.Local().Format(time.RFC1123))
+			}
+		},
+	})
+
+	var all *bool
+	var logoutCommand = &cobra.Command{
+		Use:    "logout",
+		Short:  `Remove the cached access token.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			var err error
+			if *all {
+				err = clientConfigs.TokenCache.Clear()
+			} else {
+				err = clientConfigs.TokenCache.Delete(clients.TokenCacheKey(clientConfigs))
+			}
+			if err != nil {
+				logger.Fatal("Unable to remove cached token", "error", err)
+			}
+			fmt.Println("Logged out")
+		},
+	}
+	all = logoutCommand.Flags().Bool("all", false, "Remove the cached tokens of every profile")
+	authCommand.AddCommand(logoutCommand)
+
+	rootCmd.AddCommand(authCommand)
+}
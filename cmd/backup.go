@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/snapshot"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var (
+		dir       string
+		include   []string
+		exclude   []string
+		batchSize int
+	)
+	var backupCommand = &cobra.Command{
+		Use:   "backup",
+		Short: `Save a JSON snapshot of the account's resources.`,
+		Long: `Writes the account's resources to the --out directory as they come from the API, a directory per type
+		and a JSON file per resource named by its id, with a manifest.json recording when and of which account the
+		snapshot was taken. Types are ` + strings.Join(snapshot.Names(), ", ") + `, all of them unless --include
+		narrows them, less any --exclude. Roles hold the ids of their users, apps and admins, and privileges the
+		ids of the roles and users assigned them. Types the account's API doesn't offer are skipped with a warning.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := backup(clientConfigs, dir, include, exclude, batchSize); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	backupCommand.Flags().StringVar(&dir, "out", "", "Directory to write the snapshot to, created when missing")
+	backupCommand.Flags().StringSliceVar(&include, "include", nil, "Comma separated types to save, instead of all of them")
+	backupCommand.Flags().StringSliceVar(&exclude, "exclude", nil, "Comma separated types to leave out")
+	backupCommand.Flags().IntVar(&batchSize, "batch-size", bulk.DefaultBatchSize, "Requests sent at once for types fetched a resource at a time")
+	backupCommand.MarkFlagRequired("out")
+	rootCmd.AddCommand(backupCommand)
+}
+
+// backup writes the resources of the selected types to the directory as a snapshot
+func backup(clientConfigs clients.ClientConfigs, dir string, include []string, exclude []string, batchSize int) error {
+	types, err := snapshot.Select(include, exclude)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	manifest := snapshot.Manifest{CreatedAt: time.Now().UTC().Format(time.RFC3339), URL: clientConfigs.OneLoginURL, Counts: map[string]int{}}
+	for _, resourceType := range types {
+		resources, err := fetchSnapshotType(clientConfigs, resourceType, batchSize)
+		if err != nil {
+			return fmt.Errorf("unable to back up %s: %s", resourceType.Name, err)
+		}
+		if resources == nil {
+			continue
+		}
+		if err := snapshot.WriteType(dir, resourceType.Name, resources); err != nil {
+			return fmt.Errorf("unable to write %s: %s", resourceType.Name, err)
+		}
+		manifest.Counts[resourceType.Name] = len(resources)
+		logger.Info("Backed up", "type", resourceType.Name, "count", len(resources))
+	}
+	if err := snapshot.WriteManifest(dir, manifest); err != nil {
+		return err
+	}
+	logger.Info("Saved snapshot", "dir", dir)
+	return nil
+}
+
+// fetchSnapshotType fetches every resource of the type, or nil when the account's API doesn't offer the type
+func fetchSnapshotType(clientConfigs clients.ClientConfigs, resourceType snapshot.Type, batchSize int) ([]records.Record, error) {
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return nil, err
+	}
+	switch resourceType.Name {
+	case "mappings":
+		enabled, err := fetchMappings(api, true)
+		if err != nil {
+			return nil, err
+		}
+		disabled, err := fetchMappings(api, false)
+		return append(enabled, disabled...), err
+	case "privileges":
+		list, err := fetchAll(clientConfigs, resourceType.Path, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, privilege := range list {
+			roleIDs, userIDs, err := privilegeAssignees(api, privilege.Text("id"))
+			if err != nil {
+				return nil, err
+			}
+			privilege["role_ids"], privilege["user_ids"] = roleIDs, userIDs
+		}
+		return list, nil
+	}
+	if resourceType.Optional {
+		_, _, err := api.Do(http.MethodGet, resourceType.Path, nil, nil)
+		if apiErr, ok := err.(*clients.APIError); ok && apiErr.StatusCode == http.StatusNotFound {
+			logger.Warn("Skipping a type the API doesn't offer", "type", resourceType.Name)
+			return nil, nil
+		}
+	}
+	if resourceType.FetchOne {
+		return fetchEach(clientConfigs, resourceType.Path, nil, batchSize)
+	}
+	return fetchAll(clientConfigs, resourceType.Path, nil)
+}
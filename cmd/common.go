@@ -0,0 +1,428 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/profiles"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/terraform/exec"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// addRenderFlags adds the flags that change how resources are written as HCL to a command
+func addRenderFlags(cmd *cobra.Command, options *stateparser.Options) {
+	cmd.Flags().BoolVar(&options.KeepEmpties, "keep-empties", false, "Write empty strings, lists, and maps to main.tf instead of omitting them")
+	cmd.Flags().StringSliceVar(&options.KeepEmptyAttributes, "keep-empty", []string{}, "Attributes to write even when empty, as resource_type.attribute e.g. onelogin_apps.notes")
+	cmd.Flags().BoolVar(&options.RawIDs, "raw-ids", false, "Write ids of other managed resources as literals instead of references e.g. onelogin_roles.engineering.id")
+	cmd.Flags().StringSliceVar(&options.IgnoreChanges, "ignore-changes", []string{}, "Attributes to ignore changes to with a lifecycle block, as resource_type.attribute e.g. onelogin_users.comment")
+	cmd.Flags().BoolVar(&options.NoDefaultIgnoreChanges, "no-default-ignore-changes", false, "Don't ignore changes to attributes known to drift on their own like updated_at")
+	cmd.Flags().BoolVar(&options.IncludeSecrets, "include-secrets", false, "Write secrets like client secrets and certificates to generated files instead of redacting them")
+}
+
+// profileRepository stores profiles in the profiles file, with client secrets in the OS keyring once moved there
+func profileRepository(configFile *os.File) profiles.Repository {
+	return profiles.NewKeyringRepository(profiles.FileRepository{StorageMedia: configFile}, profiles.SystemKeyring{})
+}
+
+// tokenCache keeps OneLogin access tokens next to the profiles file
+func tokenCache() *clients.TokenCache {
+	if mockDir != "" {
+		return nil // the made up tokens of replayed runs aren't worth keeping
+	}
+	return &clients.TokenCache{Path: filepath.Join(filepath.Dir(viper.ConfigFileUsed()), "tokens.json")}
+}
+
+// the commands listing the same collections over and over, like apps for each app type, whose responses are cached.
+// Other commands, and those polling for changes above all, always ask the API
+var cachedCommands = map[*cobra.Command]bool{}
+
+// cacheResponses has the commands reuse the OneLogin responses of the run
+func cacheResponses(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cachedCommands[cmd] = true
+	}
+}
+
+// whether the command running reuses OneLogin responses, set before it runs
+var cacheEnabled bool
+
+// runCache is shared by every client of the run, so it is created once the flags are parsed
+var runCache *clients.ResponseCache
+
+// responseCache keeps OneLogin GET responses for the run of commands caching them unless --no-cache is given, and on
+// disk for --cache-ttl
+func responseCache() *clients.ResponseCache {
+	if noCache || !cacheEnabled {
+		return nil
+	}
+	if runCache == nil {
+		ttl := cacheTTL
+		if mockDir != "" {
+			ttl = 0 // replayed runs only see the fixtures
+		}
+		runCache = clients.NewResponseCache(filepath.Join(filepath.Dir(viper.ConfigFileUsed()), "cache"), ttl)
+	}
+	return runCache
+}
+
+// runLimiter is shared by every client of the run, so all their requests keep to --rps together
+var runLimiter *clients.RateLimiter
+
+// rateLimiter holds OneLogin API requests back to --rps, or lets them through as fast as they come without it
+func rateLimiter() *clients.RateLimiter {
+	if runLimiter == nil {
+		runLimiter = clients.NewRateLimiter(rps)
+	}
+	return runLimiter
+}
+
+// loadClientConfigs reads the credentials of the profile given with --profile or ONELOGIN_PROFILE, or the active profile,
+// falling back to environment variables
+func loadClientConfigs() clients.ClientConfigs {
+	return profileClientConfigs(loadProfiles(profileName)[0])
+}
+
+// loadProfiles finds the named profiles, with the settings they inherit filled in. A blank name is the active profile,
+// which is nil when there is none
+func loadProfiles(names ...string) []*profiles.Profile {
+	configFile, err := os.OpenFile(viper.ConfigFileUsed(), os.O_RDWR, 0600)
+	if err != nil {
+		logger.Warn("Unable to open profiles file. Falling back to Environment Variables", "error", err)
+	}
+	defer configFile.Close()
+	profileService := profiles.ProfileService{
+		Repository: profileRepository(configFile),
+	}
+	out := make([]*profiles.Profile, len(names))
+	for i, name := range names {
+		out[i] = profileService.Select(name)
+	}
+	return out
+}
+
+// API requests sent by the run, for summaries like terraform-import's report
+var apiRequests = &clients.RequestCounter{}
+
+// profileClientConfigs are the credentials of the profile, or from environment variables when profile is nil
+func profileClientConfigs(profile *profiles.Profile) clients.ClientConfigs {
+	clientConfigs := clients.ClientConfigs{
+		AwsRegion:          os.Getenv("AWS_REGION"),
+		AwsProfile:         awsProfile,
+		AwsRoleARN:         awsRoleARN,
+		AwsExternalID:      awsExternalID,
+		TokenCache:         tokenCache(),
+		Retry:              clients.RetryPolicy{MaxAttempts: apiMaxAttempts},
+		Context:            runContext,
+		CABundle:           caBundle,
+		InsecureSkipVerify: insecureSkipVerify,
+		Debug:              debugLevel(),
+		Cache:              responseCache(),
+		FetchConcurrency:   fetchConcurrency,
+		MockDir:            mockDir,
+		RecordDir:          recordDir,
+		Requests:           apiRequests,
+		Limiter:            rateLimiter(),
+	}
+	if profile == nil {
+		logger.Info("No active profile detected. Authenticating with environment variables")
+		clientConfigs.OneLoginClientID = os.Getenv("ONELOGIN_CLIENT_ID")
+		clientConfigs.OneLoginClientSecret = os.Getenv("ONELOGIN_CLIENT_SECRET")
+		clientConfigs.OneLoginURL = os.Getenv("ONELOGIN_OAPI_URL")
+	} else {
+		logger.Info("Using profile", "profile", (*profile).Name)
+		clientConfigs.OneLoginClientID = (*profile).ClientID
+		clientConfigs.OneLoginClientSecret = (*profile).ClientSecret
+		clientConfigs.OneLoginURL = (*profile).APIURL()
+	}
+	if readOnly {
+		checkReadOnly(profile, clientConfigs)
+	}
+	return clientConfigs
+}
+
+// checkReadOnly stops the run unless the profile's scope is read only and the API refuses its credentials a write, as
+// the scope is only what was typed in when the profile was added
+func checkReadOnly(profile *profiles.Profile, clientConfigs clients.ClientConfigs) {
+	if profile == nil || !profile.ReadOnly() {
+		logger.FatalCode(exitAuth, "--read-only given but the credentials in use are not known to be read only. Use a profile with a read only scope e.g. read_all")
+	}
+	if mockDir != "" {
+		return // replayed runs send nothing
+	}
+	canWrite, err := clients.CanWrite(clientConfigs)
+	if err != nil {
+		logger.FatalCode(exitAuth, "--read-only given but unable to check the credentials in use are read only", "error", err)
+	}
+	if canWrite {
+		logger.FatalCode(exitAuth, "--read-only given but the credentials of the profile may write, though its scope says otherwise. Fix the scope with onelogin profiles edit", "profile", profile.Name, "scope", profile.Scope)
+	}
+}
+
+// fetchAll reads every page of a OneLogin collection like /api/2/roles as records
+func fetchAll(clientConfigs clients.ClientConfigs, path string, query url.Values) ([]records.Record, error) {
+	pages, err := clients.New(clientConfigs).OneLoginPages()
+	if err != nil {
+		return nil, err
+	}
+	out := []records.Record{}
+	err = pages.StreamPages(path, query, func(page []byte) error {
+		list, err := records.FromJSON(page)
+		out = append(out, list...)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list %s: %s", path, err)
+	}
+	return out, nil
+}
+
+// fetchEach lists the collection, then gets each item in batches for the fields only returned one at a time
+func fetchEach(clientConfigs clients.ClientConfigs, path string, query url.Values, batchSize int) ([]records.Record, error) {
+	list, err := fetchAll(clientConfigs, path, query)
+	if err != nil {
+		return nil, err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]records.Record, len(list))
+	jobs := make([]bulk.Job, len(list))
+	for i, item := range list {
+		i, itemPath := i, path+"/"+item.Text("id")
+		jobs[i] = func() (http.Header, error) {
+			data, header, err := api.Do(http.MethodGet, itemPath, nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get %s: %s", itemPath, err)
+			}
+			got, err := records.FromJSON(data)
+			if err != nil || len(got) == 0 {
+				return nil, fmt.Errorf("unable to read %s: %s", itemPath, data)
+			}
+			out[i] = got[0]
+			return header, nil
+		}
+	}
+	if err := firstError(runBatches(jobs, batchSize)); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// runBatches runs the jobs in batches keeping to the rate limit, logging every --rate-status-interval how much of it
+// is left and when the jobs should be done
+func runBatches(jobs []bulk.Job, batchSize int) []error {
+	logged := time.Now()
+	return bulk.RunWithStatus(jobs, batchSize, waitForRateLimit, func(status bulk.Status) {
+		if rateStatusInterval <= 0 || time.Since(logged) < rateStatusInterval {
+			return
+		}
+		logged = time.Now()
+		fields := []interface{}{"done", status.Done, "total", status.Total, "estimate", status.Estimate().Round(time.Second)}
+		if status.Known {
+			fields = append(fields, "remaining", status.Remaining, "reset", status.Reset)
+		}
+		if status.Limit > 0 {
+			fields = append(fields, "limit", status.Limit)
+		}
+		logger.Info("Rate budget", fields...)
+	})
+}
+
+// waitForRateLimit waits for the rate limit to reset between batches
+func waitForRateLimit(pause time.Duration) {
+	logger.Info("Waiting for the rate limit to reset", "delay", pause)
+	time.Sleep(pause)
+}
+
+// firstError is the first of the errors that isn't nil
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchAllV1 reads every page of a version 1 collection, which wraps its items in data and pages by cursor. Also
+// returns the headers of the last response, for callers keeping to the rate limit
+func fetchAllV1(api *clients.OneLoginAPI, path string, query url.Values) ([]records.Record, http.Header, error) {
+	out := []records.Record{}
+	header, err := streamV1(api, path, query, func(items []records.Record, cursor string) error {
+		out = append(out, items...)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, header, nil
+}
+
+// streamV1 hands each page of a version 1 collection to each as it is read, with the cursor of the page after it or
+// nothing for the last page. An after_cursor in the query starts from that page. An error from each stops the reading
+func streamV1(api *clients.OneLoginAPI, path string, query url.Values, each func(items []records.Record, cursor string) error) (http.Header, error) {
+	params := url.Values{}
+	for key, values := range query {
+		params[key] = values
+	}
+	for {
+		data, header, err := api.Do(http.MethodGet, path, params, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Data       json.RawMessage `json:"data"`
+			Pagination struct {
+				AfterCursor *string `json:"after_cursor"`
+			} `json:"pagination"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+		items := []records.Record{}
+		if len(page.Data) > 0 {
+			if items, err = records.FromJSON(page.Data); err != nil {
+				return nil, err
+			}
+		}
+		cursor := ""
+		if page.Pagination.AfterCursor != nil {
+			cursor = *page.Pagination.AfterCursor
+		}
+		if err := each(items, cursor); err != nil {
+			return nil, err
+		}
+		if cursor == "" {
+			return header, nil
+		}
+		params.Set("after_cursor", cursor)
+	}
+}
+
+// stdin is shared by the prompts, so answers piped in together aren't lost to another prompt's buffer
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm reports whether to go on: when yes is given, or the question asked on stdout is answered y or yes. With
+// --no-input it fails rather than ask
+func confirm(yes bool, question string) (bool, error) {
+	if yes {
+		return true, nil
+	}
+	text, err := prompt(fmt.Sprintf("%s (y/n)", question))
+	if err != nil {
+		return false, err
+	}
+	if text = strings.ToLower(text); text != "y" && text != "yes" {
+		logger.Info("User aborted operation!")
+		return false, nil
+	}
+	return true, nil
+}
+
+// prompt asks the question on stdout and returns the line answering it. With --no-input it fails rather than ask
+func prompt(question string) (string, error) {
+	if noInput {
+		return "", fmt.Errorf("unable to ask %q as --no-input is set", question)
+	}
+	fmt.Printf("%s: ", question)
+	line, _ := stdin.ReadString('\n')
+	return strings.TrimSpace(line), nil
+}
+
+// profileInput is what profile prompts read: stdin, or nothing with --no-input so they keep the values given before
+// and fail when there are none
+func profileInput() io.Reader {
+	if noInput {
+		return strings.NewReader("")
+	}
+	return os.Stdin
+}
+
+// debugLevel is how much of API requests to log per -v and --debug
+func debugLevel() int {
+	if debug && verbosity < clients.DebugRequests {
+		return clients.DebugRequests
+	}
+	return verbosity
+}
+
+// workspaceFlags locate the terraform workspace, or tfstate file, a command reads state from
+type workspaceFlags struct {
+	runner     string
+	binary     string
+	workingDir string
+	stateFile  string
+}
+
+func addWorkspaceFlags(cmd *cobra.Command, flags *workspaceFlags) {
+	cmd.Flags().StringVar(&flags.runner, "runner", "terraform", "Tool driving the workspace (terraform or terragrunt)")
+	cmd.Flags().StringVar(&flags.binary, "terraform-binary", "", "Path to the terraform compatible binary e.g. /usr/local/bin/tofu (defaults to terraform, or tofu if terraform is not on PATH)")
+	cmd.Flags().StringVar(&flags.binary, "binary", "", "Alias for --terraform-binary")
+	cmd.Flags().StringVar(&flags.workingDir, "working-dir", "", "Directory of the workspace where terraform commands are run (defaults to the current directory)")
+	cmd.Flags().StringVar(&flags.stateFile, "state", "", "Read state from this tfstate file instead of running 'state pull'")
+}
+
+// readState reads state from the file when given, otherwise pulls it from the workspace
+func (f workspaceFlags) readState() (stateparser.State, error) {
+	r, err := f.openState()
+	if err != nil {
+		return stateparser.State{}, err
+	}
+	defer r.Close()
+	state, err := stateparser.Parse(r)
+	if err != nil {
+		return state, fmt.Errorf("unable to parse tfstate: %s", err)
+	}
+	return state, nil
+}
+
+// stateSource is tfstate to read from the start again as needed
+type stateSource interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// pulledState is state pulled from the workspace, held in memory
+type pulledState struct {
+	*bytes.Reader
+}
+
+func (pulledState) Close() error {
+	return nil
+}
+
+// openState opens the state file when given, read as it is parsed rather than all at once, otherwise pulls the
+// state from the workspace
+func (f workspaceFlags) openState() (stateSource, error) {
+	if f.stateFile != "" {
+		file, err := os.Open(filepath.Clean(f.stateFile))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read tfstate: %s", err)
+		}
+		return file, nil
+	}
+	runner, err := tfexec.New(f.runner, f.binary, f.workingDir)
+	if err != nil {
+		return nil, err
+	}
+	data, err := runner.StatePull()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tfstate: %s", err)
+	}
+	return pulledState{bytes.NewReader(data)}, nil
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/profiles"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// completionCacheTTL is how long completions reuse the apps and roles they listed unless --cache-ttl is given, so
+// pressing tab again answers from disk instead of the API
+const completionCacheTTL = 5 * time.Minute
+
+// completion returns a cobra completion function
+type completion func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+func init() {
+	var completionCommand = &cobra.Command{
+		Use:   "completion <bash|zsh|fish|powershell>",
+		Short: `Print a shell completion script.`,
+		Long: `Prints the script completing commands and flags for the shell. Bash and fish also complete the
+		arguments looked up as they are typed: importable resource types, profile names and app and role ids.
+		For bash, add source <(onelogin completion bash) to ~/.bashrc. For fish, run
+		onelogin completion fish > ~/.config/fish/completions/onelogin.fish.`,
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: []string{"bash", "zsh", "fish", "powershell"},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := writeCompletion(args[0]); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	rootCmd.AddCommand(completionCommand)
+}
+
+// writeCompletion prints the completion script for the shell
+func writeCompletion(shell string) error {
+	switch shell {
+	case "bash":
+		return rootCmd.GenBashCompletion(os.Stdout)
+	case "zsh":
+		return rootCmd.GenZshCompletion(os.Stdout)
+	case "fish":
+		return rootCmd.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		return rootCmd.GenPowerShellCompletion(os.Stdout)
+	}
+	return fmt.Errorf("unsupported shell %s, expected bash, zsh, fish or powershell", shell)
+}
+
+// firstArg completes the first argument of a command with the completion, and nothing after it
+func firstArg(complete completion) completion {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return complete(cmd, args, toComplete)
+	}
+}
+
+// completeList completes with the choices listed that start with what was typed
+func completeList(list func() ([]string, error)) completion {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		choices, err := list()
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+			return nil, cobra.ShellCompDirectiveError
+		}
+		return matchingChoices(choices, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeEach completes the last of the comma separated values of flags like --profiles prod,emea
+func completeEach(list func() ([]string, error)) completion {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		choices, err := list()
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+			return nil, cobra.ShellCompDirectiveError
+		}
+		typed := toComplete[strings.LastIndex(toComplete, ",")+1:]
+		before := toComplete[:len(toComplete)-len(typed)]
+		out := []string{}
+		for _, choice := range matchingChoices(choices, typed) {
+			out = append(out, before+choice)
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
+}
+
+// matchingChoices keeps the choices starting with what was typed. Choices can have a description after a tab
+func matchingChoices(choices []string, toComplete string) []string {
+	out := []string{}
+	for _, choice := range choices {
+		if strings.HasPrefix(choice, toComplete) {
+			out = append(out, choice)
+		}
+	}
+	return out
+}
+
+// importableNames lists the resource types terraform-import can import
+func importableNames() ([]string, error) {
+	return tfimportables.Names(), nil
+}
+
+// profileNames lists the names of the profiles in the profiles file
+func profileNames() ([]string, error) {
+	configFile, err := os.OpenFile(viper.ConfigFileUsed(), os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open profiles file: %s", err)
+	}
+	defer configFile.Close()
+	names := []string{}
+	for name := range (profiles.ProfileService{Repository: profileRepository(configFile)}).Index() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// lookupIDs lists the ids of the OneLogin collection at the path, like /api/2/roles, described by their names.
+// Responses are kept on disk for completionCacheTTL unless --cache-ttl is given
+func lookupIDs(path string) func() ([]string, error) {
+	return func() ([]string, error) {
+		cacheEnabled = true
+		if cacheTTL == 0 {
+			cacheTTL = completionCacheTTL
+		}
+		items, err := fetchAll(loadClientConfigs(), path, nil)
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]string, len(items))
+		for i, item := range items {
+			ids[i] = item.Text("id") + "\t" + item.Text("name")
+		}
+		return ids, nil
+	}
+}
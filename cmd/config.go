@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"strings"
+)
+
+// defaults for command flags, read from ~/.onelogin/config.yaml and ONELOGIN_ environment variables
+var commandDefaults = viper.New()
+
+// initCommandDefaults reads the config file from the directory, if there is one. A value under the command's name
+// applies to that command only, a top level value to every command with a flag of that name e.g.
+//
+//	runner: terragrunt
+//	terraform-import:
+//	  auto_approve: true
+//	  working-dir: ./infra
+func initCommandDefaults(dir string) error {
+	commandDefaults.AddConfigPath(dir)
+	commandDefaults.SetConfigName("config")
+	commandDefaults.SetConfigType("yaml")
+	commandDefaults.SetEnvPrefix("onelogin")
+	commandDefaults.SetEnvKeyReplacer(strings.NewReplacer("-", "_", ".", "_"))
+	commandDefaults.AutomaticEnv()
+	if err := commandDefaults.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return fmt.Errorf("unable to read %s: %s", commandDefaults.ConfigFileUsed(), err)
+		}
+	}
+	return nil
+}
+
+// applyCommandDefaults sets the flags of the command that weren't given on the command line. Environment variables
+// win over the config file, so the precedence is flag > env > config
+func applyCommandDefaults(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "config" || flag.Name == "help" {
+			return
+		}
+		key := cmd.Name() + "." + flag.Name
+		if !commandDefaults.IsSet(key) {
+			key = flag.Name
+		}
+		if !commandDefaults.IsSet(key) {
+			return
+		}
+		value := commandDefaults.GetString(key)
+		if strings.HasSuffix(flag.Value.Type(), "Slice") {
+			value = strings.Join(commandDefaults.GetStringSlice(key), ",")
+		}
+		if setErr := cmd.Flags().Set(flag.Name, value); setErr != nil {
+			err = fmt.Errorf("invalid default for --%s: %s", flag.Name, setErr)
+		}
+	})
+	return err
+}
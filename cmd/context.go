@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"github.com/onelogin/onelogin/logger"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// runContext is done when the command is interrupted or runs past --timeout. API requests in flight are aborted with it
+var runContext = context.Background()
+
+// commandContext is cancelled by the first Ctrl-C, or when the timeout is up if one is given. Signals are only
+// caught once so a second Ctrl-C kills the process if something doesn't stop in time
+func commandContext(timeout time.Duration) context.Context {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), timeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			logger.Warn("Interrupted, stopping. Press Ctrl-C again to exit immediately")
+		case <-ctx.Done():
+		}
+		signal.Stop(signals)
+		cancel()
+	}()
+	return ctx
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/diffs"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/snapshot"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+// exit code of diff when the accounts differ, like drift
+const diffExitCode = 2
+
+func init() {
+	var (
+		fromProfile string
+		toProfile   string
+		types       []string
+		asJSON      bool
+		batchSize   int
+	)
+	var diffCommand = &cobra.Command{
+		Use:   "diff",
+		Short: `Compare the resources of two accounts.`,
+		Long: `Fetches the --types given, out of ` + strings.Join(snapshot.Names(), ", ") + `, from the accounts of
+		--from-profile and --to-profile and prints the resources only in one of them and the fields of those that
+		differ, e.g. to audit what a promotion from staging to production would change. Users are matched by email,
+		hooks by type and the rest by name, and references between resources, like the roles of apps, are compared
+		by the names they refer to rather than by id. Exits with 2 when the accounts differ so CI can fail on it.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			format, err := reportFormat(asJSON)
+			if err != nil {
+				fatal(err)
+			}
+			differences, err := diffAccounts(fromProfile, toProfile, types, batchSize)
+			if err != nil {
+				fatal(err)
+			}
+			if format == records.TableFormat {
+				snapshot.WriteDiff(diffs.Printer{W: os.Stdout, Color: diffs.ColorEnabled(os.Stdout)}, differences, fromProfile, toProfile)
+			} else {
+				err = records.WriteValue(os.Stdout, format, differences)
+			}
+			if err != nil {
+				fatal(err)
+			}
+			if len(differences) > 0 {
+				os.Exit(diffExitCode)
+			}
+		},
+	}
+	diffCommand.Flags().StringVar(&fromProfile, "from-profile", "", "Profile of the account to compare from")
+	diffCommand.Flags().StringVar(&toProfile, "to-profile", "", "Profile of the account to compare to")
+	diffCommand.RegisterFlagCompletionFunc("to-profile", completeList(profileNames))
+	diffCommand.Flags().StringSliceVar(&types, "types", nil, "Comma separated types to compare, instead of all of them")
+	diffCommand.Flags().BoolVar(&asJSON, "json", false, "Print the differences as JSON")
+	diffCommand.Flags().MarkDeprecated("json", "use --output json")
+	diffCommand.Flags().IntVar(&batchSize, "batch-size", bulk.DefaultBatchSize, "Requests sent at once for types fetched a resource at a time")
+	diffCommand.MarkFlagRequired("from-profile")
+	diffCommand.MarkFlagRequired("to-profile")
+	acceptOutput(diffCommand)
+	rootCmd.AddCommand(diffCommand)
+}
+
+// diffAccounts compares the resources of the types in the accounts of the profiles
+func diffAccounts(fromProfile string, toProfile string, types []string, batchSize int) ([]snapshot.Difference, error) {
+	selected, err := snapshot.Select(types, nil)
+	if err != nil {
+		return nil, err
+	}
+	found := loadProfiles(fromProfile, toProfile)
+	fromConfigs, toConfigs := profileClientConfigs(found[0]), profileClientConfigs(found[1])
+
+	// the types the selected ones refer to are fetched too, so references can be compared by name
+	wanted := map[string]bool{}
+	for _, resourceType := range selected {
+		wanted[resourceType.Name] = true
+		for _, name := range snapshot.Needs(resourceType.Name) {
+			wanted[name] = true
+		}
+	}
+	differ := &snapshot.Differ{}
+	from, to := map[string][]records.Record{}, map[string][]records.Record{}
+	for _, resourceType := range snapshot.Types {
+		if !wanted[resourceType.Name] {
+			continue
+		}
+		if from[resourceType.Name], err = fetchSnapshotType(fromConfigs, resourceType, batchSize); err != nil {
+			return nil, fmt.Errorf("unable to fetch %s from %s: %s", resourceType.Name, fromProfile, err)
+		}
+		if to[resourceType.Name], err = fetchSnapshotType(toConfigs, resourceType, batchSize); err != nil {
+			return nil, fmt.Errorf("unable to fetch %s from %s: %s", resourceType.Name, toProfile, err)
+		}
+		differ.Match(resourceType.Name, from[resourceType.Name], to[resourceType.Name])
+	}
+	differences := []snapshot.Difference{}
+	for _, resourceType := range selected {
+		if from[resourceType.Name] == nil || to[resourceType.Name] == nil {
+			continue // skipped, as the API of one of the accounts doesn't offer it
+		}
+		differences = append(differences, differ.Diff(resourceType.Name, from[resourceType.Name], to[resourceType.Name])...)
+	}
+	return differences, nil
+}
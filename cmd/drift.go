@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/diffs"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/terraform/drift"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// exit code of drift when a resource changed on the remote, like terraform plan -detailed-exitcode
+const driftExitCode = 2
+
+func init() {
+	var (
+		workspace     workspaceFlags
+		asJSON        *bool
+		clientConfigs clients.ClientConfigs
+	)
+	var driftCommand = &cobra.Command{
+		Use:   "drift",
+		Short: `Report resources in Terraform state that changed on the remote.`,
+		Long: `Reads the workspace's tfstate, fetches the same resources from the OneLogin API, and prints the
+		attributes that differ, catching changes made outside Terraform without running a plan.
+		Exits with 2 when anything drifted so CI can fail on it.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			format, err := reportFormat(*asJSON)
+			if err != nil {
+				fatal(err)
+			}
+			state, err := workspace.readState()
+			if err != nil {
+				fatal(err)
+			}
+			report, err := tfdrift.Detect(runContext, state, tfimportables.New(clients.New(clientConfigs)))
+			if err != nil {
+				fatal(err)
+			}
+			if format == records.TableFormat {
+				writeDriftText(diffs.Printer{W: os.Stdout, Color: diffs.ColorEnabled(os.Stdout)}, report)
+			} else {
+				err = records.WriteValue(os.Stdout, format, report)
+			}
+			if err != nil {
+				fatal(err)
+			}
+			if report.Drifted() {
+				os.Exit(driftExitCode)
+			}
+		},
+	}
+	addWorkspaceFlags(driftCommand, &workspace)
+	asJSON = driftCommand.Flags().Bool("json", false, "Print the report as JSON")
+	driftCommand.Flags().MarkDeprecated("json", "use --output json")
+	acceptOutput(driftCommand)
+	rootCmd.AddCommand(driftCommand)
+}
+
+// prints the resources that drifted with state => remote for every value that differs
+func writeDriftText(p diffs.Printer, report tfdrift.Report) {
+	drifted := 0
+	for _, resource := range report.Resources {
+		switch {
+		case resource.Error != "":
+			p.Printf(diffs.Removed, "! %s (%s): unable to fetch from remote: %s", resource.Address, resource.ID, resource.Error)
+		case resource.Missing:
+			p.Printf(diffs.Removed, "- %s (%s): deleted from remote", resource.Address, resource.ID)
+		case len(resource.Changes) > 0:
+			p.Printf(diffs.Changed, "~ %s (%s)", resource.Address, resource.ID)
+			for _, change := range resource.Changes {
+				p.Lines("    ", diffs.Compare(change.Attribute, change.State, change.Remote))
+			}
+		default:
+			continue
+		}
+		drifted++
+	}
+	if drifted == 0 {
+		fmt.Fprintf(p.W, "No drift in %d resources\n", len(report.Resources))
+		return
+	}
+	fmt.Fprintf(p.W, "%d of %d resources drifted\n", drifted, len(report.Resources))
+}
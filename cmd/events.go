@@ -0,0 +1,380 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/events"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// event fields printed by default, with event_type named from event_type_id
+var eventFields = []string{"created_at", "id", "event_type", "user_name", "app_name", "ipaddr", "notes"}
+
+// requests left in the rate limit below which events tail slows down until the limit resets
+const eventsTailReserve = 5
+
+// eventQueryFlags narrow the events listed or tailed
+type eventQueryFlags struct {
+	since     string
+	until     string
+	eventType string
+	user      string
+}
+
+func addEventQueryFlags(cmd *cobra.Command, flags *eventQueryFlags, defaultSince string) {
+	cmd.Flags().StringVar(&flags.since, "since", defaultSince, "Start of the events, as a duration ago like 1h or 7d, a date, or an RFC 3339 timestamp")
+	cmd.Flags().StringVar(&flags.eventType, "type", "", "Keep events of this type, by id e.g. 5 or by name e.g. USER_LOGGED_INTO_ONELOGIN")
+	cmd.Flags().StringVar(&flags.user, "user", "", "Keep events about the user with this id")
+}
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var eventsCommand = &cobra.Command{
+		Use:   "events",
+		Short: `Look up and watch OneLogin events like logins and provisioning.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var (
+		listQuery  eventQueryFlags
+		filters    []string
+		listOutput outputFlags
+	)
+	var eventsListCommand = &cobra.Command{
+		Use:    "list",
+		Short:  `List events, oldest first.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listEvents(clientConfigs, listQuery, filters, listOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addEventQueryFlags(eventsListCommand, &listQuery, "1h")
+	eventsListCommand.Flags().StringVar(&listQuery.until, "until", "", "End of the events, given like --since (defaults to now)")
+	eventsListCommand.Flags().StringArrayVar(&filters, "filter", nil, "Keep events whose field matches e.g. app_name~salesforce. Repeat to require several")
+	addOutputFlags(eventsListCommand, &listOutput, eventFields, records.TableFormat)
+
+	var (
+		tailQuery  eventQueryFlags
+		tailFields []string
+		interval   time.Duration
+	)
+	var eventsTailCommand = &cobra.Command{
+		Use:   "tail",
+		Short: `Print new events as they happen.`,
+		Long: `Polls for events every --interval, printing each new one on a line, or as a JSON object per line with
+		--output json, until interrupted. Polling slows down until the rate limit resets when it runs low.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := tailEvents(os.Stdout, clientConfigs, tailQuery, tailFields, formatOr(records.TableFormat), interval); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addEventQueryFlags(eventsTailCommand, &tailQuery, "0s")
+	eventsTailCommand.Flags().StringSliceVar(&tailFields, "fields", eventFields, "Comma separated fields to print, as the API names them")
+	eventsTailCommand.Flags().DurationVar(&interval, "interval", 10*time.Second, "How often to check for new events")
+
+	var (
+		exportQuery  eventQueryFlags
+		exportFormat string
+		exportFile   string
+		resume       string
+	)
+	var eventsExportCommand = &cobra.Command{
+		Use:   "export",
+		Short: `Write events to a file for a SIEM or an audit.`,
+		Long: `Writes every event in the range as a JSON object per line, or as a line per event in ArcSight's Common
+		Event Format with --format cef. The range ends when the export starts unless --until is given, so periodic
+		exports can each start with --since where the last one ended, as logged when it finishes.
+		An export that stops part way logs a token to carry on from the page after the last one written with
+		--resume, appending to --out.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := exportEvents(clientConfigs, exportQuery, exportFormat, exportFile, resume); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addEventQueryFlags(eventsExportCommand, &exportQuery, "24h")
+	eventsExportCommand.Flags().StringVar(&exportQuery.until, "until", "", "End of the events, given like --since (defaults to now)")
+	eventsExportCommand.Flags().StringVar(&exportFormat, "format", events.NDJSONFormat, "Format of the events: ndjson or cef")
+	eventsExportCommand.Flags().StringVar(&exportFile, "out", "", "File to write the events to, instead of stdout")
+	eventsExportCommand.Flags().StringVar(&resume, "resume", "", "Token logged by an export that stopped part way, to carry on from where it got to")
+
+	acceptOutput(eventsTailCommand)
+	eventsCommand.AddCommand(eventsListCommand, eventsTailCommand, eventsExportCommand)
+	rootCmd.AddCommand(eventsCommand)
+}
+
+// listEvents prints the events matching the query and every filter
+func listEvents(clientConfigs clients.ClientConfigs, query eventQueryFlags, filterFlags []string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	filters, err := records.ParseFilters(filterFlags)
+	if err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	reader, err := newEventReader(api, query)
+	if err != nil {
+		return err
+	}
+	list, _, err := reader.read(reader.since)
+	if err != nil {
+		return err
+	}
+	matching := []records.Record{}
+	for _, event := range list {
+		if records.MatchAll(event, filters) {
+			matching = append(matching, event)
+		}
+	}
+	return output.write(matching)
+}
+
+// tailEvents prints the events after since to out as they happen, until interrupted
+func tailEvents(out io.Writer, clientConfigs clients.ClientConfigs, query eventQueryFlags, fields []string, format string, interval time.Duration) error {
+	if format != records.TableFormat && format != records.JSONFormat {
+		return fmt.Errorf("unsupported output %s, expected table or json", format)
+	}
+	clientConfigs.Cache = nil // polls ask for the same URL until an event shows up, so none is answered from earlier responses
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	reader, err := newEventReader(api, query)
+	if err != nil {
+		return err
+	}
+	since := reader.since
+	last, seen := "", map[string]bool{} // events at the newest time printed, as the next poll includes them again
+	for {
+		list, header, err := reader.read(since)
+		if err != nil {
+			return err
+		}
+		for _, event := range list {
+			at := event.Text("created_at")
+			if at < last || (at == last && seen[event.Text("id")]) {
+				continue
+			}
+			if at > last {
+				last, seen = at, map[string]bool{}
+			}
+			seen[event.Text("id")] = true
+			if err := writeEventLine(out, event, fields, format); err != nil {
+				return err
+			}
+		}
+		if parsed, err := time.Parse(time.RFC3339, last); err == nil {
+			since = parsed
+		}
+		wait := interval
+		if pause := bulk.Pause(header, eventsTailReserve); pause > wait {
+			logger.Warn("Rate limit is running low, waiting for it to reset", "delay", pause)
+			wait = pause
+		}
+		select {
+		case <-runContext.Done():
+			return nil
+		case <-time.After(wait):
+		}
+	}
+}
+
+// exportEvents writes the events in the range to the file, or stdout, a page at a time
+func exportEvents(clientConfigs clients.ClientConfigs, query eventQueryFlags, format string, file string, resume string) error {
+	var token events.Token
+	if resume != "" {
+		var err error
+		if token, err = events.DecodeToken(resume); err != nil {
+			return err
+		}
+		query = eventQueryFlags{since: token.Since, until: token.Until, eventType: token.EventType, user: token.User}
+	} else if query.until == "" {
+		query.until = events.Format(time.Now())
+	}
+	out := os.Stdout
+	if file != "" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if resume != "" {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		var err error
+		if out, err = os.OpenFile(file, flags, 0600); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	buffered := bufio.NewWriter(out)
+	writer, err := events.NewWriter(buffered, format)
+	if err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	reader, err := newEventReader(api, query)
+	if err != nil {
+		return err
+	}
+	params := url.Values{"since": {events.Format(reader.since)}}
+	for key, values := range reader.query {
+		params[key] = values
+	}
+	token = events.Token{Since: params.Get("since"), Until: params.Get("until"), EventType: params.Get("event_type_id"), User: params.Get("user_id"), Cursor: token.Cursor}
+	if token.Cursor != "" {
+		params.Set("after_cursor", token.Cursor)
+	}
+	count := 0
+	_, err = streamV1(api, "/api/1/events", params, func(items []records.Record, cursor string) error {
+		for _, event := range reader.name(items) {
+			if err := writer.Write(event); err != nil {
+				return err
+			}
+		}
+		if err := buffered.Flush(); err != nil {
+			return err
+		}
+		count += len(items)
+		token.Cursor = cursor
+		if cursor != "" && runContext.Err() != nil {
+			return errors.New("interrupted")
+		}
+		return nil
+	})
+	if err != nil {
+		if token.Cursor != "" {
+			logger.Error("Export stopped part way, carry on from where it got to with --resume", "events", count, "resume", token.Encode())
+		}
+		return fmt.Errorf("unable to export events: %s", err)
+	}
+	logger.Info("Exported events, start the next export from where this one ended with --since", "events", count, "since", token.Until)
+	return nil
+}
+
+// writeEventLine prints an event on a line, its fields separated by two spaces or as a JSON object
+func writeEventLine(out io.Writer, event records.Record, fields []string, format string) error {
+	if format == records.JSONFormat {
+		selected := records.Record{}
+		for _, field := range fields {
+			selected[field] = event[field]
+		}
+		line, err := json.Marshal(selected)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(out, string(line))
+		return err
+	}
+	texts := make([]string, len(fields))
+	for i, field := range fields {
+		texts[i] = strings.Replace(event.Text(field), "\n", " ", -1)
+	}
+	_, err := fmt.Fprintln(out, strings.Join(texts, "  "))
+	return err
+}
+
+// eventReader reads the events matching a query, naming their types
+type eventReader struct {
+	api   *clients.OneLoginAPI
+	query url.Values
+	since time.Time
+	types map[string]string // event type names by id
+}
+
+func newEventReader(api *clients.OneLoginAPI, flags eventQueryFlags) (*eventReader, error) {
+	now := time.Now()
+	since, err := events.ParseSince(flags.since, now)
+	if err != nil {
+		return nil, err
+	}
+	reader := &eventReader{api: api, query: url.Values{}, since: since, types: map[string]string{}}
+	if flags.until != "" {
+		until, err := events.ParseSince(flags.until, now)
+		if err != nil {
+			return nil, err
+		}
+		reader.query.Set("until", events.Format(until))
+	}
+	if flags.user != "" {
+		if _, err := strconv.Atoi(flags.user); err != nil {
+			return nil, fmt.Errorf("invalid user id %s", flags.user)
+		}
+		reader.query.Set("user_id", flags.user)
+	}
+	types, _, err := fetchAllV1(api, "/api/1/events/types", nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list event types: %s", err)
+	}
+	for _, eventType := range types {
+		reader.types[eventType.Text("id")] = eventType.Text("name")
+		if flags.eventType != "" && strings.EqualFold(eventType.Text("name"), flags.eventType) {
+			reader.query.Set("event_type_id", eventType.Text("id"))
+		}
+	}
+	if flags.eventType != "" && reader.query.Get("event_type_id") == "" {
+		if _, err := strconv.Atoi(flags.eventType); err != nil {
+			return nil, fmt.Errorf("unknown event type %s", flags.eventType)
+		}
+		reader.query.Set("event_type_id", flags.eventType)
+	}
+	return reader, nil
+}
+
+// read lists the events from since, oldest first, returning the headers of the last response
+func (r *eventReader) read(since time.Time) ([]records.Record, http.Header, error) {
+	query := url.Values{}
+	for key, values := range r.query {
+		query[key] = values
+	}
+	query.Set("since", events.Format(since))
+	list, header, err := fetchAllV1(r.api, "/api/1/events", query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to list events: %s", err)
+	}
+	r.name(list)
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Text("created_at") != list[j].Text("created_at") {
+			return list[i].Text("created_at") < list[j].Text("created_at")
+		}
+		a, _ := strconv.Atoi(list[i].Text("id"))
+		b, _ := strconv.Atoi(list[j].Text("id"))
+		return a < b
+	})
+	return list, header, nil
+}
+
+// name sets the event_type field of the events to the name of their event_type_id
+func (r *eventReader) name(list []records.Record) []records.Record {
+	for _, event := range list {
+		event["event_type"] = r.types[event.Text("event_type_id")]
+	}
+	return list
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cancelingWriter cancels the run once it has been written lines times
+type cancelingWriter struct {
+	bytes.Buffer
+	lines  int
+	cancel context.CancelFunc
+}
+
+func (w *cancelingWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	if strings.Count(w.String(), "\n") >= w.lines {
+		w.cancel()
+	}
+	return n, err
+}
+
+func TestTailEvents(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/oauth2/v2/token":
+			w.Write([]byte(`{"access_token":"token","expires_in":36000}`))
+		case "/api/1/events":
+			polls++
+			if polls == 1 {
+				w.Write([]byte(`{"data":[]}`))
+				return
+			}
+			w.Write([]byte(`{"data":[{"id":7,"created_at":"2020-01-01T00:00:00Z","event_type_id":5}]}`))
+		default:
+			w.Write([]byte(`{"data":[{"id":5,"name":"USER_LOGGED_INTO_ONELOGIN"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	previous := runContext
+	runContext = ctx
+	defer func() { runContext = previous }()
+
+	out := &cancelingWriter{lines: 1, cancel: cancel}
+	configs := clients.ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL, Retry: clients.RetryPolicy{MaxAttempts: 1}, Cache: clients.NewResponseCache("", 0)}
+	err := tailEvents(out, configs, eventQueryFlags{since: "0s"}, []string{"id", "event_type"}, "table", time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, "7  USER_LOGGED_INTO_ONELOGIN\n", out.String())
+	assert.True(t, polls >= 2)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/terraform/exec"
+)
+
+// exit codes of failed commands, documented in the README so wrappers can branch on them instead of reading output
+const (
+	exitFailed      = 1 // the command failed
+	exitPartial     = 2 // part of the work was done before the rest failed, like some rows of a bulk import
+	exitAuth        = 3 // the API rejected the credentials
+	exitRateLimited = 4 // the API kept rate limiting requests after they were retried
+)
+
+// partialError is a failure after part of the work was done
+type partialError struct {
+	error
+}
+
+// partial is the error of failed of total parts of the work failing, a partialError when some of them succeeded
+func partial(err error, failed int, total int) error {
+	if failed < total {
+		return partialError{err}
+	}
+	return err
+}
+
+// exitCode is the exit code for the error. Rate limits come first, as running the command again later may finish
+// the work, then rejected credentials, then failures after part of the work was done
+func exitCode(err error) int {
+	_, isPartial := err.(partialError)
+	switch {
+	case tfexec.IsRateLimited(err):
+		return exitRateLimited
+	case clients.IsAuthError(err):
+		return exitAuth
+	case isPartial:
+		return exitPartial
+	}
+	return exitFailed
+}
+
+// fatal logs the error the command can't go on after, records the run with --metrics and exits with its exit code
+func fatal(err error) {
+	code := exitCode(err)
+	recordRun(code)
+	logger.FatalCode(code, err.Error())
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var groupsCommand = &cobra.Command{
+		Use:   "groups",
+		Short: `Look up OneLogin groups and their members.`,
+		Long: `Looks up the account's legacy groups, which mapping conditions often still depend on. The OneLogin API
+		can read groups but not create or delete them, so that is still done in the admin console.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var (
+		filters    []string
+		listOutput outputFlags
+	)
+	var groupsListCommand = &cobra.Command{
+		Use:    "list",
+		Short:  `List groups, optionally filtered.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listGroups(clientConfigs, filters, listOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	groupsListCommand.Flags().StringArrayVar(&filters, "filter", nil, "Keep groups whose field matches e.g. name~contractors. Repeat to require several")
+	addOutputFlags(groupsListCommand, &listOutput, []string{"id", "name", "reference"}, records.TableFormat)
+
+	var getOutput outputFlags
+	var groupsGetCommand = &cobra.Command{
+		Use:    "get <id>",
+		Short:  `Print a group.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := getGroup(clientConfigs, args[0], getOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(groupsGetCommand, &getOutput, nil, records.JSONFormat)
+
+	var membersOutput outputFlags
+	var groupsMembersCommand = &cobra.Command{
+		Use:    "members <id>",
+		Short:  `List the users in a group.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listGroupMembers(clientConfigs, args[0], membersOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(groupsMembersCommand, &membersOutput, []string{"id", "email", "username", "firstname", "lastname", "status"}, records.TableFormat)
+
+	groupsCommand.AddCommand(groupsListCommand, groupsGetCommand, groupsMembersCommand)
+	rootCmd.AddCommand(groupsCommand)
+}
+
+// listGroups prints the groups matching every filter
+func listGroups(clientConfigs clients.ClientConfigs, filterFlags []string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	filters, err := records.ParseFilters(filterFlags)
+	if err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	groups, _, err := fetchAllV1(api, "/api/1/groups", nil)
+	if err != nil {
+		return fmt.Errorf("unable to list groups: %s", err)
+	}
+	matching := []records.Record{}
+	for _, group := range groups {
+		if records.MatchAll(group, filters) {
+			matching = append(matching, group)
+		}
+	}
+	return output.write(matching)
+}
+
+// getGroup prints the group with the id
+func getGroup(clientConfigs clients.ClientConfigs, id string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	groupID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid group id %s", id)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	groups, _, err := fetchAllV1(api, fmt.Sprintf("/api/1/groups/%d", groupID), nil)
+	if err != nil {
+		return fmt.Errorf("unable to get group %d: %s", groupID, err)
+	}
+	if len(groups) == 0 {
+		return fmt.Errorf("group %d doesn't exist", groupID)
+	}
+	return output.writeOne(groups[0])
+}
+
+// listGroupMembers prints the users whose group is the one with the id
+func listGroupMembers(clientConfigs clients.ClientConfigs, id string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	if _, err := strconv.Atoi(id); err != nil {
+		return fmt.Errorf("invalid group id %s", id)
+	}
+	members, err := fetchUsers(clientConfigs, []records.Filter{{Field: "group_id", Operator: "=", Value: id}})
+	if err != nil {
+		return err
+	}
+	return output.write(members)
+}
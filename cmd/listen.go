@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/webhooks"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// commands queued while one runs before deliveries wait for them
+const listenQueueSize = 100
+
+// how long deliveries may take to send their headers and all of their at most webhooks.MaxDelivery body, which
+// allows for slow links, and how long a connection may sit idle between deliveries. Responses have no bound as
+// deliveries wait while the queue is full
+const (
+	listenHeaderTimeout = 10 * time.Second
+	listenReadTimeout   = time.Minute
+	listenIdleTimeout   = 2 * time.Minute
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var (
+		port     int
+		rules    []string
+		secret   string
+		insecure bool
+	)
+	var listenCommand = &cobra.Command{
+		Use:   "listen",
+		Short: `Run commands when OneLogin delivers events to a webhook.`,
+		Long: `Serves an endpoint for OneLogin's event webhooks on --port, and for every event delivered runs the
+		commands of the --on rules matching it, one at a time in the order they arrive. Rules are written
+		<event>=<command>, with the event named by type like app.updated or user_created, by type id, or * for all,
+		and the command's words rendered as Go templates with the event's fields, e.g. {{.app_id}}. Commands
+		starting with a command of this CLI run it, e.g. terraform-import, and others run the program named.
+		--secret, or ONELOGIN_WEBHOOK_SECRET, is required: it is the bearer token the webhook sends in its
+		Authorization header, turning away deliveries from anyone else. To try rules out without one, --insecure
+		only listens on 127.0.0.1.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listen(clientConfigs, port, rules, secret, insecure); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	listenCommand.Flags().IntVar(&port, "port", 8080, "Port to listen on")
+	listenCommand.Flags().StringArrayVar(&rules, "on", nil, "Run a command for events e.g. app.updated='terraform-import onelogin_apps --id {{.app_id}} --auto_approve'. Repeat for several")
+	listenCommand.Flags().StringVar(&secret, "secret", os.Getenv("ONELOGIN_WEBHOOK_SECRET"), "Bearer token deliveries must give")
+	listenCommand.Flags().BoolVar(&insecure, "insecure", false, "Accept deliveries without --secret, listening on 127.0.0.1 only so only this machine can deliver")
+	listenCommand.MarkFlagRequired("on")
+	rootCmd.AddCommand(listenCommand)
+}
+
+// listen serves the webhook endpoint until interrupted, running the commands of the rules for the events delivered.
+// Deliveries run commands with the profile's credentials, so without a secret only this machine may deliver them
+func listen(clientConfigs clients.ClientConfigs, port int, ruleFlags []string, secret string, insecure bool) error {
+	rules := make([]webhooks.Rule, len(ruleFlags))
+	for i, flag := range ruleFlags {
+		rule, err := webhooks.ParseRule(flag)
+		if err != nil {
+			return err
+		}
+		rules[i] = rule
+	}
+	host := ""
+	if secret == "" {
+		if !insecure {
+			return fmt.Errorf("--secret or ONELOGIN_WEBHOOK_SECRET is required so deliveries from anyone else are turned away, or --insecure to listen on 127.0.0.1 only")
+		}
+		host = "127.0.0.1"
+		logger.Warn("No --secret given, deliveries from anyone on this machine are accepted")
+	}
+	names := map[string]string{}
+	if api, err := clients.New(clientConfigs).OneLoginAPI(); err == nil {
+		types, _, err := fetchAllV1(api, "/api/1/events/types", nil)
+		if err != nil {
+			logger.Warn("Unable to list event types, rules can only name events by id", "error", err)
+		}
+		for _, eventType := range types {
+			names[eventType.Text("id")] = eventType.Text("name")
+		}
+	}
+
+	queue := make(chan []string, listenQueueSize)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for command := range queue {
+			runListenCommand(command)
+		}
+	}()
+	server := &http.Server{
+		Addr: fmt.Sprintf("%s:%d", host, port),
+		Handler: webhooks.Receiver{
+			Rules:  rules,
+			Secret: secret,
+			Name: func(event records.Record) {
+				if name, ok := names[event.Text("event_type_id")]; ok {
+					event["event_type"] = name
+				}
+			},
+			Queue: queue,
+		},
+		ReadHeaderTimeout: listenHeaderTimeout,
+		ReadTimeout:       listenReadTimeout,
+		IdleTimeout:       listenIdleTimeout,
+	}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-runContext.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+	logger.Info("Listening for events", "address", server.Addr, "rules", len(rules))
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	<-stopped // deliveries in flight are done queuing
+	close(queue)
+	logger.Info("Stopped listening, finishing the commands queued", "queued", len(queue))
+	<-done
+	return nil
+}
+
+// runListenCommand runs the command, with this CLI when it starts with one of its commands, logging how it went
+func runListenCommand(command []string) {
+	name, args, env := command[0], command[1:], os.Environ()
+	if found, _, err := rootCmd.Find(command); err == nil && found != rootCmd {
+		self, err := os.Executable()
+		if err != nil {
+			logger.Error("Unable to find the onelogin executable", "error", err)
+			return
+		}
+		name, args = self, command
+		if profileName != "" {
+			env = append(env, "ONELOGIN_PROFILE="+profileName) // so it uses the account this was started with
+		}
+	}
+	logger.Info("Running command", "command", command)
+	started := time.Now()
+	run := exec.Command(name, args...)
+	run.Stdout, run.Stderr, run.Env = os.Stdout, os.Stderr, env
+	if err := run.Run(); err != nil {
+		logger.Error("Command failed", "command", command, "error", err, "duration", time.Since(started).Round(time.Millisecond))
+		return
+	}
+	logger.Info("Command finished", "command", command, "duration", time.Since(started).Round(time.Millisecond))
+}
@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/mappings"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var mappingsCommand = &cobra.Command{
+		Use:   "mappings",
+		Short: `Look up, reorder and try out OneLogin mappings.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var (
+		disabled   bool
+		filters    []string
+		listOutput outputFlags
+	)
+	var mappingsListCommand = &cobra.Command{
+		Use:    "list",
+		Short:  `List mappings in the order they run.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listMappings(clientConfigs, disabled, filters, listOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	mappingsListCommand.Flags().BoolVar(&disabled, "disabled", false, "List the disabled mappings instead of the enabled ones")
+	mappingsListCommand.Flags().StringArrayVar(&filters, "filter", nil, "Keep mappings whose field matches e.g. name~contractors. Repeat to require several")
+	addOutputFlags(mappingsListCommand, &listOutput, []string{"position", "id", "name", "match", "enabled"}, records.TableFormat)
+
+	var (
+		positionsFile string
+		sortDryRun    bool
+	)
+	var mappingsSortCommand = &cobra.Command{
+		Use:   "sort",
+		Short: `Reorder the enabled mappings.`,
+		Long: `Reorders the enabled mappings as listed in --positions, a file naming a mapping by id or name per line
+		in the order they should run. Mappings the file leaves out keep their order after those it lists. The new
+		order is printed, and --dry-run stops there.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := sortMappings(clientConfigs, positionsFile, sortDryRun); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	mappingsSortCommand.Flags().StringVar(&positionsFile, "positions", "", "File naming a mapping by id or name per line, in the order they should run")
+	mappingsSortCommand.Flags().BoolVar(&sortDryRun, "dry-run", false, "Print the new order without changing it")
+	mappingsSortCommand.MarkFlagRequired("positions")
+
+	var (
+		testUser   string
+		testOutput outputFlags
+	)
+	var mappingsTestCommand = &cobra.Command{
+		Use:   "test",
+		Short: `Show which mappings would match a user.`,
+		Long: `Runs every enabled mapping for the user with the API's dry run, which changes nothing, and prints in
+		the order they run whether each would match and the actions it would take.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := testMappings(clientConfigs, testUser, testOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	mappingsTestCommand.Flags().StringVar(&testUser, "user", "", "Id of the user to try the mappings for")
+	mappingsTestCommand.MarkFlagRequired("user")
+	addOutputFlags(mappingsTestCommand, &testOutput, []string{"position", "id", "name", "mapped", "actions"}, records.TableFormat)
+
+	mappingsCommand.AddCommand(mappingsListCommand, mappingsSortCommand, mappingsTestCommand)
+	rootCmd.AddCommand(mappingsCommand)
+}
+
+// listMappings prints the enabled, or disabled, mappings matching every filter
+func listMappings(clientConfigs clients.ClientConfigs, disabled bool, filterFlags []string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	filters, err := records.ParseFilters(filterFlags)
+	if err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	list, err := fetchMappings(api, !disabled)
+	if err != nil {
+		return err
+	}
+	matching := []records.Record{}
+	for _, mapping := range list {
+		if records.MatchAll(mapping, filters) {
+			matching = append(matching, mapping)
+		}
+	}
+	return output.write(matching)
+}
+
+// sortMappings reorders the enabled mappings as the positions file lists them
+func sortMappings(clientConfigs clients.ClientConfigs, positionsFile string, dryRun bool) error {
+	in, err := os.Open(positionsFile)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %s", positionsFile, err)
+	}
+	defer in.Close()
+	positions, err := mappings.ReadPositions(in)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %s", positionsFile, err)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	enabled, err := fetchMappings(api, true)
+	if err != nil {
+		return err
+	}
+	order, left, err := mappings.Order(enabled, positions)
+	if err != nil {
+		return err
+	}
+	if len(left) > 0 {
+		logger.Warn("Mappings not in the positions file keep their order after those listed", "count", len(left))
+	}
+	names := map[string]string{}
+	for _, mapping := range enabled {
+		names[mapping.Text("id")] = mapping.Text("name")
+	}
+	preview := make([]records.Record, len(order))
+	for i, id := range order {
+		preview[i] = records.Record{"position": i + 1, "id": id, "name": names[strconv.Itoa(id)]}
+	}
+	if err := records.Write(os.Stdout, records.TableFormat, preview, []string{"position", "id", "name"}); err != nil {
+		return err
+	}
+	if dryRun {
+		logger.Info("Dry run, the mappings were not reordered")
+		return nil
+	}
+	body, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+	if _, _, err := api.Do(http.MethodPut, "/api/2/mappings/sort", nil, body); err != nil {
+		return fmt.Errorf("unable to reorder mappings: %s", err)
+	}
+	logger.Info("Reordered mappings", "count", len(order))
+	return nil
+}
+
+// testMappings prints whether each enabled mapping would match the user, and what it would do
+func testMappings(clientConfigs clients.ClientConfigs, user string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	userID, err := strconv.Atoi(user)
+	if err != nil {
+		return fmt.Errorf("invalid user id %s", user)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	enabled, err := fetchMappings(api, true)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal([]int{userID})
+	if err != nil {
+		return err
+	}
+	results := []records.Record{}
+	for _, mapping := range enabled {
+		data, _, err := api.Do(http.MethodPost, "/api/2/mappings/"+mapping.Text("id")+"/dryrun", nil, body)
+		if err != nil {
+			return fmt.Errorf("unable to dry run mapping %s: %s", mapping.Text("id"), err)
+		}
+		outcomes, err := records.FromJSON(data)
+		if err != nil {
+			return fmt.Errorf("unable to read the dry run of mapping %s: %s", mapping.Text("id"), err)
+		}
+		result := records.Record{"position": mapping["position"], "id": mapping["id"], "name": mapping["name"], "mapped": false}
+		if len(outcomes) > 0 {
+			result["mapped"] = outcomes[0]["mapped"]
+			result["actions"] = outcomes[0]["actions"]
+		}
+		results = append(results, result)
+	}
+	return output.write(results)
+}
+
+// fetchMappings lists the enabled, or disabled, mappings in the order they run
+func fetchMappings(api *clients.OneLoginAPI, enabled bool) ([]records.Record, error) {
+	data, _, err := api.Do(http.MethodGet, "/api/2/mappings", url.Values{"enabled": {strconv.FormatBool(enabled)}}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list mappings: %s", err)
+	}
+	list, err := records.FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read mappings: %s", err)
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		a, _ := strconv.Atoi(list[i].Text("position"))
+		b, _ := strconv.Atoi(list[j].Text("position"))
+		return a < b
+	})
+	return list, nil
+}
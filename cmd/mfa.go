@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/deprovision"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// device fields printed by default
+var mfaDeviceFields = []string{"device_id", "auth_factor_name", "type_display_name", "user_display_name", "default"}
+
+// longest a temporary MFA token lasts, as the API allows
+const mfaTokenMaxLifetime = 72 * time.Hour
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var mfaCommand = &cobra.Command{
+		Use:   "mfa",
+		Short: `Look up and reset the MFA factors users have enrolled.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var devicesOutput outputFlags
+	var mfaDevicesCommand = &cobra.Command{
+		Use:    "devices <user id|email>",
+		Short:  `List the MFA factors a user has enrolled.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listMFADevices(clientConfigs, args[0], devicesOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(mfaDevicesCommand, &devicesOutput, mfaDeviceFields, records.TableFormat)
+
+	var (
+		factor      string
+		resetDryRun bool
+		resetYes    bool
+	)
+	var mfaResetCommand = &cobra.Command{
+		Use:   "reset <user id|email>",
+		Short: `Remove an MFA factor from a user, so they can enroll it again.`,
+		Long: `Removes the enrolled factor with the --factor device id, listed by mfa devices, after asking for
+		confirmation unless --yes is given.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := resetMFADevice(clientConfigs, args[0], factor, resetDryRun, resetYes); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	mfaResetCommand.Flags().StringVar(&factor, "factor", "", "Device id of the factor to remove")
+	mfaResetCommand.Flags().BoolVar(&resetDryRun, "dry-run", false, "Log the factor that would be removed without removing it")
+	mfaResetCommand.Flags().BoolVarP(&resetYes, "yes", "y", false, "Remove the factor without asking for confirmation")
+	mfaResetCommand.MarkFlagRequired("factor")
+
+	var (
+		expiresIn  time.Duration
+		reusable   bool
+		linkOutput outputFlags
+	)
+	var mfaEnrollLinkCommand = &cobra.Command{
+		Use:   "enroll-link <user id|email>",
+		Short: `Create a temporary MFA token a user can sign in with to enroll a new factor.`,
+		Long: `Creates a temporary MFA token for a user who has lost their factor. They enter it instead of an OTP when
+		signing in, then enroll a new factor from their profile. Tokens last --expires-in, up to 72h, and work once
+		unless --reusable is given.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := createMFAToken(clientConfigs, args[0], expiresIn, reusable, linkOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	mfaEnrollLinkCommand.Flags().DurationVar(&expiresIn, "expires-in", 24*time.Hour, "How long the token lasts, up to 72h")
+	mfaEnrollLinkCommand.Flags().BoolVar(&reusable, "reusable", false, "Let the token be used more than once until it expires")
+	addOutputFlags(mfaEnrollLinkCommand, &linkOutput, []string{"mfa_token", "expires_at", "reusable"}, records.TableFormat)
+
+	mfaCommand.AddCommand(mfaDevicesCommand, mfaResetCommand, mfaEnrollLinkCommand)
+	rootCmd.AddCommand(mfaCommand)
+}
+
+// listMFADevices prints the factors the user has enrolled
+func listMFADevices(clientConfigs clients.ClientConfigs, reference string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	user, err := deprovision.FindUser(api, reference)
+	if err != nil {
+		return err
+	}
+	devices, err := fetchMFADevices(api, user.Text("id"))
+	if err != nil {
+		return err
+	}
+	return output.write(devices)
+}
+
+// fetchMFADevices lists the factors the user with the id has enrolled
+func fetchMFADevices(api *clients.OneLoginAPI, userID string) ([]records.Record, error) {
+	data, _, err := api.Do(http.MethodGet, mfaDevicesPath(userID), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the MFA devices of user %s: %s", userID, err)
+	}
+	devices, err := records.FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the MFA devices of user %s: %s", userID, err)
+	}
+	return devices, nil
+}
+
+// resetMFADevice removes the factor with the device id from the user once confirmed, naming the factor and user so the
+// wrong one is caught before it is too late
+func resetMFADevice(clientConfigs clients.ClientConfigs, reference string, deviceID string, dryRun bool, yes bool) error {
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	user, err := deprovision.FindUser(api, reference)
+	if err != nil {
+		return err
+	}
+	devices, err := fetchMFADevices(api, user.Text("id"))
+	if err != nil {
+		return err
+	}
+	var device records.Record
+	for _, candidate := range devices {
+		if candidate.Text("device_id") == deviceID {
+			device = candidate
+		}
+	}
+	if device == nil {
+		return fmt.Errorf("user %s has no MFA device %s", user.Text("email"), deviceID)
+	}
+	name := device.Text("type_display_name")
+	if name == "" {
+		name = device.Text("auth_factor_name")
+	}
+	if dryRun {
+		logger.Info("Dry run, would remove MFA device", "user", user.Text("email"), "device", deviceID, "factor", name)
+		return nil
+	}
+	if proceed, err := confirm(yes, fmt.Sprintf("This will remove %s (device %s) from %s. Do you want to continue?", name, deviceID, user.Text("email"))); !proceed {
+		return err
+	}
+	if _, _, err := api.Do(http.MethodDelete, mfaDevicesPath(user.Text("id"))+"/"+url.PathEscape(deviceID), nil, nil); err != nil {
+		return fmt.Errorf("unable to remove MFA device %s: %s", deviceID, err)
+	}
+	logger.Info("Removed MFA device", "user", user.Text("email"), "device", deviceID, "factor", name)
+	return nil
+}
+
+// createMFAToken creates a temporary MFA token for the user and prints it
+func createMFAToken(clientConfigs clients.ClientConfigs, reference string, expiresIn time.Duration, reusable bool, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	if expiresIn < time.Second || expiresIn > mfaTokenMaxLifetime {
+		return fmt.Errorf("invalid --expires-in %s, expected between 1s and %s", expiresIn, mfaTokenMaxLifetime)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	user, err := deprovision.FindUser(api, reference)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]interface{}{"expires_in": int(expiresIn.Seconds()), "reusable": reusable})
+	if err != nil {
+		return err
+	}
+	data, _, err := api.Do(http.MethodPost, "/api/1/users/"+user.Text("id")+"/mfa_token", nil, body)
+	if err != nil {
+		return fmt.Errorf("unable to create an MFA token for %s: %s", user.Text("email"), err)
+	}
+	token, err := records.FromJSON(data)
+	if err != nil || len(token) == 0 {
+		return fmt.Errorf("unable to read the MFA token: %s", data)
+	}
+	logger.Info("Created temporary MFA token, sign in with it instead of an OTP to enroll a new factor", "user", user.Text("email"))
+	return output.writeOne(token[0])
+}
+
+// mfaDevicesPath is the path of the factors the user with the id has enrolled
+func mfaDevicesPath(userID string) string {
+	return "/api/2/mfa/users/" + url.PathEscape(userID) + "/devices"
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// the global --output, empty to print in each command's default format
+var outputFormat string
+
+// outputFlags are --fields of the commands printing resources, and the format they print in
+type outputFlags struct {
+	fields []string
+	format string
+}
+
+// the output flags of the commands printing resources, given the global --output before they run
+var commandOutputs = map[*cobra.Command]*outputFlags{}
+
+// addOutputFlags adds --fields to the command with the defaults it prints. No default fields prints every field of
+// the resources
+func addOutputFlags(cmd *cobra.Command, flags *outputFlags, defaultFields []string, defaultFormat string) {
+	cmd.Flags().StringSliceVar(&flags.fields, "fields", defaultFields, "Comma separated fields to print, as the API names them e.g. id,email,status")
+	flags.format = defaultFormat
+	commandOutputs[cmd] = flags
+}
+
+// the commands printing reports or lines in the format of the global --output, besides those with output flags
+var reportCommands = map[*cobra.Command]bool{}
+
+// acceptOutput lets the commands print in the format of the global --output
+func acceptOutput(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		reportCommands[cmd] = true
+	}
+}
+
+// applyOutputFormat checks the global --output, when set, and gives it to the command running. Commands that don't
+// print in an output format refuse it when given on the command line, so -o meant for a file, like terraform-export
+// -o main.tf, isn't ignored. A default for every command in the config file only applies to those that do
+func applyOutputFormat(cmd *cobra.Command, given bool) error {
+	if outputFormat == "" {
+		return nil
+	}
+	flags, ok := commandOutputs[cmd]
+	if !ok && !reportCommands[cmd] {
+		if given {
+			return fmt.Errorf("%s doesn't take --output (-o) %s", cmd.CommandPath(), outputFormat)
+		}
+		return nil
+	}
+	if err := records.CheckFormat(outputFormat); err != nil {
+		return err
+	}
+	if ok {
+		flags.format = outputFormat
+	}
+	return nil
+}
+
+// formatOr is the global --output, or the command's default when it isn't set
+func formatOr(defaultFormat string) string {
+	if outputFormat != "" {
+		return outputFormat
+	}
+	return defaultFormat
+}
+
+// reportFormat is the format of the commands printing a report, table for its text or json or yaml. Their --json is
+// the same as --output json
+func reportFormat(asJSON bool) (string, error) {
+	format := formatOr(records.TableFormat)
+	if asJSON {
+		format = records.JSONFormat
+	}
+	if format != records.TableFormat && format != records.JSONFormat && format != records.YAMLFormat {
+		return "", fmt.Errorf("unsupported output %s, expected table, json or yaml", format)
+	}
+	return format, nil
+}
+
+// write prints the records to stdout in the format asked for
+func (f outputFlags) write(list []records.Record) error {
+	fields := f.fields
+	if len(fields) == 0 {
+		fields = records.Fields(list)
+	}
+	return records.Write(os.Stdout, f.format, list, fields)
+}
+
+// writeOne prints a single resource to stdout in the format asked for
+func (f outputFlags) writeOne(record records.Record) error {
+	fields := f.fields
+	if len(fields) == 0 {
+		fields = records.Fields([]records.Record{record})
+	}
+	return records.WriteOne(os.Stdout, f.format, record, fields)
+}
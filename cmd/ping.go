@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+	"time"
+)
+
+// lowHeadroom is the share of the rate limit left under which ping warns that long running commands may be throttled
+const lowHeadroom = 0.1
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var count int
+	var pingCommand = &cobra.Command{
+		Use:   "ping",
+		Short: `Check the credentials of the active profile against the OneLogin API.`,
+		Long: `Requests an access token with the credentials of the active profile, then reports the API's region, how
+		long its requests take, how much of the rate limit is left and which resources the credentials may read. Only
+		reads, so it is safe against any account. Fails when the credentials are rejected or the API can't be reached.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runPing(clientConfigs, count); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	pingCommand.Flags().IntVar(&count, "count", 3, "Number of requests timed to measure latency")
+	acceptOutput(pingCommand)
+	rootCmd.AddCommand(pingCommand)
+}
+
+// runPing pings the API with the credentials and prints the report, warning about what would get in the way of
+// importing
+func runPing(clientConfigs clients.ClientConfigs, count int) error {
+	format, err := reportFormat(false)
+	if err != nil {
+		return err
+	}
+	report, err := clients.Ping(clientConfigs, count)
+	if err != nil {
+		return err
+	}
+	if format == records.TableFormat {
+		denied := []string{}
+		readable := []string{}
+		for _, access := range report.Access {
+			if access.Readable {
+				readable = append(readable, access.Resource)
+			} else {
+				denied = append(denied, fmt.Sprintf("%s (%s)", access.Resource, access.Detail))
+			}
+		}
+		rows := []map[string]string{
+			{"check": "api", "result": fmt.Sprintf("%s (region %s)", report.URL, report.Region)},
+			{"check": "token", "result": fmt.Sprintf("valid, took %s, expires %s", report.TokenLatency, report.ExpiresAt.Format(time.RFC3339))},
+			{"check": "latency", "result": fmt.Sprintf("min %s, avg %s, max %s", report.MinLatency, report.AvgLatency, report.MaxLatency)},
+			{"check": "rate limit", "result": fmt.Sprintf("%d of %d left, resets in %ds", report.RateLimit.Remaining, report.RateLimit.Limit, report.RateLimit.Reset)},
+			{"check": "readable", "result": strings.Join(readable, ", ")},
+			{"check": "denied", "result": strings.Join(denied, ", ")},
+		}
+		list, err := records.From(rows)
+		if err != nil {
+			return err
+		}
+		err = records.Write(os.Stdout, format, list, []string{"check", "result"})
+	} else {
+		err = records.WriteValue(os.Stdout, format, report)
+	}
+	if err != nil {
+		return err
+	}
+	for _, access := range report.Access {
+		if !access.Readable {
+			logger.Warn("The credentials can't read a resource, importing it will fail", "resource", access.Resource, "error", access.Detail)
+		}
+	}
+	if limit := report.RateLimit; limit.Limit > 0 && float64(limit.Remaining) < lowHeadroom*float64(limit.Limit) {
+		logger.Warn("Little of the rate limit is left, long running commands will be throttled", "remaining", limit.Remaining, "limit", limit.Limit, "reset", fmt.Sprintf("%ds", limit.Reset))
+	}
+	return nil
+}
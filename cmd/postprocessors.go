@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// postProcessorConfig is how the post-processor of a resource type is configured under post-processors in
+// config.yaml: an inline template, a template file, or a command, with paths relative to the config file e.g.
+//
+//	post-processors:
+//	  onelogin_apps:
+//	    template: |
+//	      # owner: platform
+//	      {{.HCL}}
+//	  onelogin_roles:
+//	    command: scripts/roles.sh --strict
+type postProcessorConfig struct {
+	Template     string `mapstructure:"template"`
+	TemplateFile string `mapstructure:"template-file"`
+	Command      string `mapstructure:"command"`
+}
+
+// loadPostProcessors reads the post-processors configured in config.yaml by resource type
+func loadPostProcessors() (map[string]stateparser.PostProcessor, error) {
+	configs := map[string]postProcessorConfig{}
+	if err := commandDefaults.UnmarshalKey("post-processors", &configs); err != nil {
+		return nil, fmt.Errorf("unable to read post-processors: %s", err)
+	}
+	dir := filepath.Dir(commandDefaults.ConfigFileUsed())
+	processors := map[string]stateparser.PostProcessor{}
+	for resourceType, config := range configs {
+		var err error
+		config.Command = strings.TrimSpace(config.Command)
+		switch {
+		case config.Template != "" && config.TemplateFile == "" && config.Command == "":
+			processors[resourceType], err = stateparser.NewTemplate(resourceType, config.Template)
+		case config.TemplateFile != "" && config.Template == "" && config.Command == "":
+			var text []byte
+			if text, err = ioutil.ReadFile(configPath(dir, config.TemplateFile)); err == nil {
+				processors[resourceType], err = stateparser.NewTemplate(resourceType, string(text))
+			}
+		case config.Command != "" && config.Template == "" && config.TemplateFile == "":
+			args := strings.Fields(config.Command)
+			if strings.ContainsRune(args[0], '/') || strings.ContainsRune(args[0], filepath.Separator) {
+				args[0] = configPath(dir, args[0])
+			}
+			processors[resourceType] = stateparser.Command{Args: args}
+		default:
+			err = fmt.Errorf("give one of template, template-file or command")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid post-processor for %s: %s", resourceType, err)
+		}
+	}
+	return processors, nil
+}
+
+// configPath resolves a path given in the config file relative to the file's directory
+func configPath(dir string, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,363 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/privileges"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// privilege fields printed by default, with actions listing what the privilege's statements allow
+var privilegeFields = []string{"id", "name", "description", "actions"}
+
+// fields printed by who-can, a row per role or user a privilege allowing the action is assigned to
+var whoCanFields = []string{"privilege_id", "privilege", "scopes", "assignee_type", "assignee_id", "assignee"}
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var privilegesCommand = &cobra.Command{
+		Use:   "privileges",
+		Short: `Look up and assign the privileges of delegated administrators.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var (
+		filters    []string
+		listOutput outputFlags
+	)
+	var privilegesListCommand = &cobra.Command{
+		Use:    "list",
+		Short:  `List privileges and the actions they allow, optionally filtered.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listPrivileges(clientConfigs, filters, listOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	privilegesListCommand.Flags().StringArrayVar(&filters, "filter", nil, "Keep privileges whose field matches e.g. actions~users:. Repeat to require several")
+	addOutputFlags(privilegesListCommand, &listOutput, privilegeFields, records.TableFormat)
+
+	var getOutput outputFlags
+	var privilegesGetCommand = &cobra.Command{
+		Use:    "get <id>",
+		Short:  `Print a privilege with its statements and the roles and users assigned it.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := getPrivilege(clientConfigs, args[0], getOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(privilegesGetCommand, &getOutput, nil, records.JSONFormat)
+
+	var (
+		assignRoles  []string
+		assignEmails []string
+		assignDryRun bool
+		revokeRoles  []string
+		revokeEmails []string
+		revokeDryRun bool
+	)
+	var privilegesAssignCommand = &cobra.Command{
+		Use:    "assign <id>",
+		Short:  `Assign a privilege to roles, or to users by email.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := assignPrivilege(clientConfigs, args[0], assignRoles, assignEmails, assignDryRun); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	privilegesAssignCommand.Flags().StringSliceVar(&assignRoles, "roles", nil, "Comma separated ids of the roles to assign the privilege to")
+	privilegesAssignCommand.Flags().StringSliceVar(&assignEmails, "emails", nil, "Comma separated emails of the users to assign the privilege to")
+	privilegesAssignCommand.Flags().BoolVar(&assignDryRun, "dry-run", false, "Log the assignments without making them")
+	var privilegesRevokeCommand = &cobra.Command{
+		Use:    "revoke <id>",
+		Short:  `Revoke a privilege from roles, or from users by email.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := revokePrivilege(clientConfigs, args[0], revokeRoles, revokeEmails, revokeDryRun); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	privilegesRevokeCommand.Flags().StringSliceVar(&revokeRoles, "roles", nil, "Comma separated ids of the roles to revoke the privilege from")
+	privilegesRevokeCommand.Flags().StringSliceVar(&revokeEmails, "emails", nil, "Comma separated emails of the users to revoke the privilege from")
+	privilegesRevokeCommand.Flags().BoolVar(&revokeDryRun, "dry-run", false, "Log the revocations without making them")
+
+	var (
+		action       string
+		whoCanOutput outputFlags
+	)
+	var privilegesWhoCanCommand = &cobra.Command{
+		Use:   "who-can",
+		Short: `List the roles and users allowed an action, and the privileges allowing it.`,
+		Long: `Finds the privileges whose statements allow the action, including through wildcards like users:*, and lists
+		a row for each role and user they are assigned to, with the scopes the action is allowed over.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := whoCan(clientConfigs, action, whoCanOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	privilegesWhoCanCommand.Flags().StringVar(&action, "action", "", "Action to look up e.g. users:Update")
+	privilegesWhoCanCommand.MarkFlagRequired("action")
+	addOutputFlags(privilegesWhoCanCommand, &whoCanOutput, whoCanFields, records.TableFormat)
+
+	privilegesCommand.AddCommand(privilegesListCommand, privilegesGetCommand, privilegesAssignCommand,
+		privilegesRevokeCommand, privilegesWhoCanCommand)
+	rootCmd.AddCommand(privilegesCommand)
+}
+
+// listPrivileges prints the privileges matching every filter
+func listPrivileges(clientConfigs clients.ClientConfigs, filterFlags []string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	filters, err := records.ParseFilters(filterFlags)
+	if err != nil {
+		return err
+	}
+	list, err := fetchPrivileges(clientConfigs)
+	if err != nil {
+		return err
+	}
+	matching := []records.Record{}
+	for _, privilege := range list {
+		if records.MatchAll(privilege, filters) {
+			matching = append(matching, privilege)
+		}
+	}
+	return output.write(matching)
+}
+
+// fetchPrivileges lists every privilege, setting actions to what its statements allow
+func fetchPrivileges(clientConfigs clients.ClientConfigs) ([]records.Record, error) {
+	list, err := fetchAll(clientConfigs, "/api/2/privileges", nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, privilege := range list {
+		statements, err := privileges.Statements(privilege)
+		if err != nil {
+			return nil, err
+		}
+		privilege["actions"] = strings.Join(privileges.Actions(statements), ",")
+	}
+	return list, nil
+}
+
+// getPrivilege prints the privilege with the id, with role_ids and user_ids listing who is assigned it
+func getPrivilege(clientConfigs clients.ClientConfigs, id string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	data, _, err := api.Do(http.MethodGet, privilegePath(id), nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get privilege %s: %s", id, err)
+	}
+	privilege, err := records.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("unable to read privilege %s: %s", id, err)
+	}
+	roleIDs, userIDs, err := privilegeAssignees(api, id)
+	if err != nil {
+		return err
+	}
+	privilege[0]["role_ids"], privilege[0]["user_ids"] = roleIDs, userIDs
+	return output.writeOne(privilege[0])
+}
+
+// privilegeAssignees lists the ids of the roles and users the privilege is assigned to
+func privilegeAssignees(api *clients.OneLoginAPI, id string) ([]int, []int, error) {
+	var roles struct {
+		Roles []int `json:"roles"`
+	}
+	var users struct {
+		Users []int `json:"users"`
+	}
+	for kind, out := range map[string]interface{}{"roles": &roles, "users": &users} {
+		data, _, err := api.Do(http.MethodGet, privilegePath(id)+"/"+kind, nil, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to list the %s assigned privilege %s: %s", kind, id, err)
+		}
+		if err := json.Unmarshal(data, out); err != nil {
+			return nil, nil, fmt.Errorf("unable to read the %s assigned privilege %s: %s", kind, id, err)
+		}
+	}
+	if roles.Roles == nil {
+		roles.Roles = []int{}
+	}
+	if users.Users == nil {
+		users.Users = []int{}
+	}
+	return roles.Roles, users.Users, nil
+}
+
+// assignPrivilege assigns the privilege to the roles, and the users with the emails
+func assignPrivilege(clientConfigs clients.ClientConfigs, id string, roles []string, emails []string, dryRun bool) error {
+	roleIDs, err := privilegeRoleIDs(roles, emails)
+	if err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	userIDs, err := userIDsByEmail(api, emails)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		logger.Info("Assigned privilege", "privilege", id, "roles", strings.Join(roles, ","), "users", strings.Join(emails, ","), "dry_run", true)
+		return nil
+	}
+	if len(roleIDs) > 0 {
+		body, _ := json.Marshal(map[string][]int{"roles": roleIDs})
+		if _, _, err := api.Do(http.MethodPost, privilegePath(id)+"/roles", nil, body); err != nil {
+			return fmt.Errorf("unable to assign privilege %s to roles: %s", id, err)
+		}
+	}
+	if len(userIDs) > 0 {
+		body, _ := json.Marshal(map[string][]int{"users": userIDs})
+		if _, _, err := api.Do(http.MethodPost, privilegePath(id)+"/users", nil, body); err != nil {
+			return fmt.Errorf("unable to assign privilege %s to users: %s", id, err)
+		}
+	}
+	logger.Info("Assigned privilege", "privilege", id, "roles", strings.Join(roles, ","), "users", strings.Join(emails, ","))
+	return nil
+}
+
+// revokePrivilege revokes the privilege from the roles, and the users with the emails, one at a time as the API takes
+func revokePrivilege(clientConfigs clients.ClientConfigs, id string, roles []string, emails []string, dryRun bool) error {
+	roleIDs, err := privilegeRoleIDs(roles, emails)
+	if err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	userIDs, err := userIDsByEmail(api, emails)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		logger.Info("Revoked privilege", "privilege", id, "roles", strings.Join(roles, ","), "users", strings.Join(emails, ","), "dry_run", true)
+		return nil
+	}
+	for _, roleID := range roleIDs {
+		if _, _, err := api.Do(http.MethodDelete, fmt.Sprintf("%s/roles/%d", privilegePath(id), roleID), nil, nil); err != nil {
+			return fmt.Errorf("unable to revoke privilege %s from role %d: %s", id, roleID, err)
+		}
+	}
+	for i, userID := range userIDs {
+		if _, _, err := api.Do(http.MethodDelete, fmt.Sprintf("%s/users/%d", privilegePath(id), userID), nil, nil); err != nil {
+			return fmt.Errorf("unable to revoke privilege %s from user %s: %s", id, emails[i], err)
+		}
+	}
+	logger.Info("Revoked privilege", "privilege", id, "roles", strings.Join(roles, ","), "users", strings.Join(emails, ","))
+	return nil
+}
+
+// privilegeRoleIDs reads the role ids given to assign or revoke, requiring roles or users
+func privilegeRoleIDs(roles []string, emails []string) ([]int, error) {
+	if len(roles) == 0 && len(emails) == 0 {
+		return nil, fmt.Errorf("no roles or users given, expected --roles or --emails")
+	}
+	out := []int{}
+	for _, role := range roles {
+		id, err := strconv.Atoi(strings.TrimSpace(role))
+		if err != nil {
+			return nil, fmt.Errorf("invalid role id %s", role)
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// whoCan prints the roles and users assigned a privilege allowing the action
+func whoCan(clientConfigs clients.ClientConfigs, action string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	list, err := fetchPrivileges(clientConfigs)
+	if err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	roleNames := map[int]string{}
+	userEmails := map[int]string{}
+	rows := []records.Record{}
+	for _, privilege := range list {
+		statements, _ := privileges.Statements(privilege)
+		scopes, allowed := privileges.Allows(statements, action)
+		if !allowed {
+			continue
+		}
+		roleIDs, userIDs, err := privilegeAssignees(api, privilege.Text("id"))
+		if err != nil {
+			return err
+		}
+		if len(roleIDs) > 0 && len(roleNames) == 0 {
+			roles, err := fetchAll(clientConfigs, "/api/2/roles", nil)
+			if err != nil {
+				return err
+			}
+			for _, role := range roles {
+				id, _ := strconv.Atoi(role.Text("id"))
+				roleNames[id] = role.Text("name")
+			}
+		}
+		row := func(kind string, id int, name string) records.Record {
+			return records.Record{"privilege_id": privilege["id"], "privilege": privilege["name"], "scopes": strings.Join(scopes, ","),
+				"assignee_type": kind, "assignee_id": id, "assignee": name}
+		}
+		for _, roleID := range roleIDs {
+			rows = append(rows, row("role", roleID, roleNames[roleID]))
+		}
+		for _, userID := range userIDs {
+			if _, ok := userEmails[userID]; !ok {
+				data, _, err := api.Do(http.MethodGet, fmt.Sprintf("/api/2/users/%d", userID), nil, nil)
+				if err != nil {
+					return fmt.Errorf("unable to get user %d: %s", userID, err)
+				}
+				user, err := records.FromJSON(data)
+				if err != nil {
+					return fmt.Errorf("unable to read user %d: %s", userID, err)
+				}
+				userEmails[userID] = user[0].Text("email")
+			}
+			rows = append(rows, row("user", userID, userEmails[userID]))
+		}
+	}
+	return output.write(rows)
+}
+
+// privilegePath is the path of the privilege with the id, which is a UUID rather than a number
+func privilegePath(id string) string {
+	return "/api/2/privileges/" + url.PathEscape(id)
+}
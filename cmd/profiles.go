@@ -3,28 +3,43 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
 	"github.com/onelogin/onelogin/profiles"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"log"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
+// parent of the scoped profile created by add, given with --parent
+var parentProfile string
+
+// settings of export and import, given with --format and --redact-secrets
+var (
+	shareFormat   string
+	redactSecrets bool
+)
+
 func init() {
 	legalActions := map[string]interface{}{
-		"add":     add,
-		"create":  add,
-		"list":    list,
-		"ls":      list,
-		"show":    show,
-		"use":     use,
-		"edit":    edit,
-		"update":  edit,
-		"remove":  remove,
-		"delete":  remove,
-		"which":   current,
-		"current": current,
+		"add":         add,
+		"create":      add,
+		"list":        list,
+		"ls":          list,
+		"show":        show,
+		"use":         use,
+		"edit":        edit,
+		"update":      edit,
+		"remove":      remove,
+		"delete":      remove,
+		"which":       current,
+		"current":     current,
+		"use-keyring": useKeyring,
+		"export":      export,
+		"import":      importProfiles,
 	}
 	rootCmd.AddCommand(&cobra.Command{
 		Use:   "init",
@@ -34,15 +49,15 @@ func init() {
 			configFile, err := os.OpenFile(viper.ConfigFileUsed(), os.O_RDWR, 0600)
 			if err != nil {
 				configFile.Close()
-				log.Fatalln("Unable to open profiles file", err)
+				logger.Fatal("Unable to open profiles file", "error", err)
 			}
 			profileService := profiles.ProfileService{
-				Repository:  profiles.FileRepository{StorageMedia: configFile},
-				InputReader: os.Stdin,
+				Repository:  profileRepository(configFile),
+				InputReader: profileInput(),
 			}
 			if len(profileService.Index()) > 0 {
 				configFile.Close()
-				log.Fatalln("Profiles already set up!")
+				logger.Fatal("Profiles already set up!")
 			}
 			configFile.Close()
 			return nil
@@ -51,17 +66,18 @@ func init() {
 			configFile, err := os.OpenFile(viper.ConfigFileUsed(), os.O_RDWR, 0600)
 			if err != nil {
 				configFile.Close()
-				log.Fatalln("Unable to open profiles file", err)
+				logger.Fatal("Unable to open profiles file", "error", err)
 			}
 			profileService := profiles.ProfileService{
-				Repository:  profiles.FileRepository{StorageMedia: configFile},
-				InputReader: os.Stdin,
+				Repository:  profileRepository(configFile),
+				InputReader: profileInput(),
 			}
 			profileService.Create("default")
 			configFile.Close()
 		},
 	})
-	rootCmd.AddCommand(&cobra.Command{
+	var testCredentials *bool
+	var profilesCommand = &cobra.Command{
 		Use:   "profiles",
 		Short: "Manage account settings for the CLI",
 		Long: `Maintains a listing of accounts used by the CLI in a home/.onelogin/profiles file
@@ -72,21 +88,25 @@ func init() {
 			show            [name - required] => shows information about the profile
 			edit   (update) [name - required] => edits selected profile information
 			remove (delete) [name - required] => removes selected profile
-			add    (create) [name - required] => adds profile to manage
+			add    (create) [name - required] => adds profile to manage. with --parent, adds a profile with its own credentials for the parent's tenant
 			list   (ls)     [name - optional] => lists managed profile that can be used. if name given, lists information about that profile
-			which  (current)                  => returns current active profile`,
+			which  (current)                  => returns current active profile
+			use-keyring                       => moves the client secrets of all profiles to the OS keyring
+			export          [file - optional] => writes all profiles as yaml or json to the file, or to stdout. pass --redact-secrets to leave out client secrets
+			import          [file - required] => adds the profiles in an exported file, prompting for any client id or secret left out
+		Pass --test with add, edit or import to request a token with the given credentials before the profile is saved.`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				log.Fatalf("Must specify action to perform!")
+				logger.Fatal("Must specify action to perform!")
 			}
 			action := strings.ToLower(args[0])
 			if legalActions[action] == nil {
-				log.Fatalf("Illegal Action!")
+				logger.Fatal("Illegal Action!")
 			}
 			switch action {
-			case "show", "add", "use", "edit", "update", "remove", "delete":
+			case "show", "add", "use", "edit", "update", "remove", "delete", "import":
 				if len(args) < 2 {
-					log.Fatalf("Profile Name is required for this action!")
+					logger.Fatal("Profile Name is required for this action!")
 				}
 			}
 			return nil
@@ -96,27 +116,80 @@ func init() {
 			configFile, err := os.OpenFile(viper.ConfigFileUsed(), os.O_RDWR, 0600)
 			if err != nil {
 				configFile.Close()
-				log.Fatalln("Unable to open profiles file", err)
+				logger.Fatal("Unable to open profiles file", "error", err)
 			}
 			profileService := profiles.ProfileService{
-				Repository:  profiles.FileRepository{StorageMedia: configFile},
-				InputReader: os.Stdin,
+				Repository:  profileRepository(configFile),
+				InputReader: profileInput(),
+			}
+			if *testCredentials {
+				profileService.Verify = verifyProfile
 			}
 			if f, ok := legalActions[action].(func(s string, pr profiles.ProfileService)); ok {
-				profileName := args[1]
-				f(profileName, profileService)
+				var arg string
+				if len(args) > 1 {
+					arg = args[1]
+				}
+				f(arg, profileService)
 			} else if f, ok := legalActions[action].(func(pr profiles.ProfileService)); ok {
 				f(profileService)
 			} else {
-				log.Fatalf("Unexpected Error!")
+				logger.Fatal("Unexpected Error!")
 			}
 			configFile.Close()
 		},
+	}
+	testCredentials = profilesCommand.Flags().Bool("test", false, "Verify the credentials with a token request before saving the profile")
+	profilesCommand.Flags().StringVar(&parentProfile, "parent", "", "With add, create a scoped profile with its own credentials that uses the tenant of this profile")
+	profilesCommand.Flags().StringVar(&shareFormat, "format", "", "Format of export and import, yaml or json. Defaults to the file's extension, or yaml")
+	profilesCommand.Flags().BoolVar(&redactSecrets, "redact-secrets", false, "With export, leave out client secrets so the file can be shared")
+	profilesCommand.ValidArgsFunction = completeProfileArgs(legalActions)
+	profilesCommand.RegisterFlagCompletionFunc("parent", completeList(profileNames))
+	rootCmd.AddCommand(profilesCommand)
+}
+
+// completeProfileArgs completes the action of profiles, then the name of the profile it acts on
+func completeProfileArgs(legalActions map[string]interface{}) completion {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch {
+		case len(args) == 0:
+			actions := []string{}
+			for action := range legalActions {
+				actions = append(actions, action)
+			}
+			sort.Strings(actions)
+			return matchingChoices(actions, toComplete), cobra.ShellCompDirectiveNoFileComp
+		case len(args) == 1 && profileActions[strings.ToLower(args[0])]:
+			return completeList(profileNames)(cmd, args, toComplete)
+		case len(args) == 1 && (args[0] == "export" || args[0] == "import"):
+			return nil, cobra.ShellCompDirectiveDefault // files
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// actions of profiles completed with the names of existing profiles
+var profileActions = map[string]bool{"show": true, "use": true, "edit": true, "update": true, "remove": true, "delete": true, "list": true, "ls": true}
+
+// verifyProfile requests a token from the profile's API with its credentials
+func verifyProfile(p profiles.Profile) error {
+	return clients.VerifyOneLogin(clients.ClientConfigs{
+		OneLoginClientID:     p.ClientID,
+		OneLoginClientSecret: p.ClientSecret,
+		OneLoginURL:          p.APIURL(),
+		Context:              runContext,
+		CABundle:             caBundle,
+		InsecureSkipVerify:   insecureSkipVerify,
+		Debug:                debugLevel(),
 	})
 }
 
 func add(name string, pr profiles.ProfileService) {
-	pr.Create(name)
+	if parentProfile != "" {
+		pr.CreateScoped(name, parentProfile)
+	} else {
+		pr.Create(name)
+	}
 	fmt.Println("Successfully created:", name)
 }
 
@@ -134,20 +207,17 @@ func list(pr profiles.ProfileService) {
 
 func show(name string, pr profiles.ProfileService) {
 	out := pr.Find(name)
-	if out != nil {
-		printout, _ := json.MarshalIndent(out, "", " ")
-		fmt.Println(string(printout))
+	if out == nil {
+		logger.Fatal("Profile does not exist!")
 	}
+	printout, _ := json.MarshalIndent(out, "", " ")
+	fmt.Println(string(printout))
 }
 
 func current(pr profiles.ProfileService) {
-	profiles := pr.Index()
 	var active string
-	for name, p := range profiles {
-		if (*p).Active == true {
-			active = name
-			break
-		}
+	if p := pr.Select(profileName); p != nil {
+		active = (*p).Name
 	}
 	fmt.Println("Current Profile:", active)
 }
@@ -166,3 +236,50 @@ func remove(name string, pr profiles.ProfileService) {
 	pr.Remove(name)
 	fmt.Println("Successfully removed:", name)
 }
+
+func useKeyring(pr profiles.ProfileService) {
+	pr.UseKeyring()
+	fmt.Println("Client secrets are now stored in the OS keyring")
+}
+
+// sharedFormat is the format given with --format, or the one the file's extension implies
+func sharedFormat(path string) string {
+	if shareFormat != "" {
+		return strings.ToLower(shareFormat)
+	}
+	if strings.ToLower(filepath.Ext(path)) == ".json" {
+		return profiles.JSONFormat
+	}
+	return profiles.YAMLFormat
+}
+
+func export(path string, pr profiles.ProfileService) {
+	out := os.Stdout
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			logger.Fatal("Unable to create file", "path", path, "error", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := pr.Export(out, sharedFormat(path), redactSecrets); err != nil {
+		logger.Fatal("Unable to export profiles", "error", err)
+	}
+	if path != "" {
+		fmt.Println("Exported profiles to", path)
+	}
+}
+
+func importProfiles(path string, pr profiles.ProfileService) {
+	file, err := os.Open(path)
+	if err != nil {
+		logger.Fatal("Unable to open file", "path", path, "error", err)
+	}
+	defer file.Close()
+	added, err := pr.Import(file, sharedFormat(path))
+	if err != nil {
+		logger.Fatal("Unable to import profiles", "error", err)
+	}
+	fmt.Println("Successfully imported:", strings.Join(added, ", "))
+}
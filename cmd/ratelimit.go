@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var rateLimitCommand = &cobra.Command{
+		Use:   "ratelimit",
+		Short: `Show how much of the API's rate limit is left.`,
+		Long: `Prints the account's rate limit, then sends a cheap request to each class of endpoints (api/1 and api/2)
+		and prints the limit, the requests remaining and the seconds until it resets from the X-RateLimit headers of its
+		response. Classes whose responses have no such headers are shown as not reported.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := showRateLimits(clientConfigs); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	acceptOutput(rateLimitCommand)
+	rootCmd.AddCommand(rateLimitCommand)
+}
+
+// showRateLimits prints the rate limit of each class of endpoints
+func showRateLimits(clientConfigs clients.ClientConfigs) error {
+	format, err := reportFormat(false)
+	if err != nil {
+		return err
+	}
+	clientConfigs.Cache = nil // responses kept by earlier runs would show the rate limit as it was then
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	limits, err := api.RateLimits()
+	if err != nil {
+		return err
+	}
+	if format != records.TableFormat {
+		return records.WriteValue(os.Stdout, format, limits)
+	}
+	list, err := records.From(limits)
+	if err != nil {
+		return err
+	}
+	return records.Write(os.Stdout, format, list, []string{"class", "reported", "limit", "remaining", "reset"})
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/privileges"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/reports"
+	"github.com/spf13/cobra"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fields each report prints unless --fields is given. The role membership matrix prints a column per role
+var reportFields = map[string][]string{
+	reports.InactiveUsers:     {"id", "email", "status", "last_login", "days_inactive"},
+	reports.AppsWithoutOwners: {"id", "name", "connector_id", "visible"},
+	reports.UsersWithoutMFA:   {"id", "email", "username", "last_login"},
+	reports.ExpiringSAMLCerts: {"id", "name", "certificate_name", "certificate_expires_at", "certificate_days_left"},
+}
+
+// days the reports looking back or ahead cover unless --days is given
+var reportDefaultDays = map[string]int{
+	reports.InactiveUsers:     90,
+	reports.ExpiringSAMLCerts: 30,
+}
+
+// auth method of SAML apps
+const samlAuthMethod = "2"
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var (
+		days      int
+		batchSize int
+		output    outputFlags
+	)
+	var reportCommand = &cobra.Command{
+		Use:   "report <name>",
+		Short: `Print a built-in report for security reviews.`,
+		Long: `Prints one of the built-in reports:
+		inactive-users          users who haven't signed in for --days (90 by default), or ever
+		apps-without-owners     apps no privilege over the app by id (apps/123 scopes) is assigned to anyone
+		users-without-mfa       active users with no MFA factor enrolled
+		expiring-saml-certs     SAML apps whose certificate expires within --days (30 by default), or has expired
+		role-membership-matrix  a row per user in a role and a column per role, marked x for the roles they are in`,
+		Args:      cobra.ExactValidArgs(1),
+		ValidArgs: reports.Names,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if !cmd.Flags().Changed("days") {
+				days = reportDefaultDays[args[0]]
+			}
+			if !cmd.Flags().Changed("fields") {
+				output.fields = reportFields[args[0]]
+			}
+			if err := runReport(clientConfigs, args[0], days, batchSize, output); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	reportCommand.Flags().IntVar(&days, "days", 0, "Days inactive-users looks back and expiring-saml-certs looks ahead")
+	reportCommand.Flags().IntVar(&batchSize, "batch-size", bulk.DefaultBatchSize, "Requests sent at once by reports making one per user or app")
+	addOutputFlags(reportCommand, &output, nil, records.TableFormat)
+	rootCmd.AddCommand(reportCommand)
+}
+
+// runReport works out the report with the name and prints it
+func runReport(clientConfigs clients.ClientConfigs, name string, days int, batchSize int, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	if days < 0 {
+		return fmt.Errorf("invalid --days %d, expected 0 or more", days)
+	}
+	now := time.Now()
+	var list []records.Record
+	var err error
+	switch name {
+	case reports.InactiveUsers:
+		var users []records.Record
+		if users, err = fetchUsers(clientConfigs, nil); err == nil {
+			list = reports.Inactive(users, now, days)
+		}
+	case reports.AppsWithoutOwners:
+		list, err = appsWithoutOwners(clientConfigs)
+	case reports.UsersWithoutMFA:
+		list, err = usersWithoutMFA(clientConfigs, batchSize)
+	case reports.ExpiringSAMLCerts:
+		var apps []records.Record
+		if apps, err = fetchEach(clientConfigs, "/api/2/apps", url.Values{"auth_method": {samlAuthMethod}}, batchSize); err == nil {
+			list = reports.ExpiringCertificates(apps, now, days)
+		}
+	case reports.RoleMembershipMatrix:
+		var fields []string
+		if list, fields, err = roleMembershipMatrix(clientConfigs); err == nil && len(output.fields) == 0 {
+			output.fields = fields
+		}
+	default:
+		return fmt.Errorf("unknown report %s, expected one of %s", name, strings.Join(reports.Names, ", "))
+	}
+	if err != nil {
+		return err
+	}
+	return output.write(list)
+}
+
+// appsWithoutOwners lists the apps no privilege scoped to them by id is assigned to a role or user
+func appsWithoutOwners(clientConfigs clients.ClientConfigs) ([]records.Record, error) {
+	apps, err := fetchAll(clientConfigs, "/api/2/apps", nil)
+	if err != nil {
+		return nil, err
+	}
+	list, err := fetchPrivileges(clientConfigs)
+	if err != nil {
+		return nil, err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return nil, err
+	}
+	assigned := []privileges.Statement{}
+	for _, privilege := range list {
+		statements, _ := privileges.Statements(privilege)
+		if len(reports.OwnedApps(statements)) == 0 {
+			continue
+		}
+		roleIDs, userIDs, err := privilegeAssignees(api, privilege.Text("id"))
+		if err != nil {
+			return nil, err
+		}
+		if len(roleIDs)+len(userIDs) > 0 {
+			assigned = append(assigned, statements...)
+		}
+	}
+	return reports.WithoutOwners(apps, reports.OwnedApps(assigned)), nil
+}
+
+// usersWithoutMFA lists the active users without factors, looking up the factors of each in batches
+func usersWithoutMFA(clientConfigs clients.ClientConfigs, batchSize int) ([]records.Record, error) {
+	users, err := fetchUsers(clientConfigs, []records.Filter{{Field: "status", Operator: "=", Value: "1"}})
+	if err != nil {
+		return nil, err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	factors := map[string]int{}
+	jobs := make([]bulk.Job, len(users))
+	for i, user := range users {
+		id := user.Text("id")
+		jobs[i] = func() (http.Header, error) {
+			data, header, err := api.Do(http.MethodGet, mfaDevicesPath(id), nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("unable to list the MFA devices of user %s: %s", id, err)
+			}
+			devices, err := records.FromJSON(data)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read the MFA devices of user %s: %s", id, err)
+			}
+			mu.Lock()
+			factors[id] = len(devices)
+			mu.Unlock()
+			return header, nil
+		}
+	}
+	if err := firstError(runBatches(jobs, batchSize)); err != nil {
+		return nil, err
+	}
+	return reports.WithoutMFA(users, factors), nil
+}
+
+// roleMembershipMatrix lists a row per user in a role with a column per role, and the fields in order
+func roleMembershipMatrix(clientConfigs clients.ClientConfigs) ([]records.Record, []string, error) {
+	roles, err := fetchAll(clientConfigs, "/api/2/roles", nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	users, err := fetchUsers(clientConfigs, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	members := map[string][]string{}
+	for _, role := range roles {
+		list, err := fetchAll(clientConfigs, "/api/2/roles/"+role.Text("id")+"/users", nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, member := range list {
+			members[role.Text("id")] = append(members[role.Text("id")], member.Text("id"))
+		}
+	}
+	rows, fields := reports.MembershipMatrix(roles, members, users)
+	return rows, fields, nil
+}
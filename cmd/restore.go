@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/snapshot"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"strings"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var (
+		types     []string
+		dryRun    bool
+		yes       bool
+		batchSize int
+	)
+	var restoreCommand = &cobra.Command{
+		Use:   "restore <dir>",
+		Short: `Create and update resources so the account holds those of a backup snapshot.`,
+		Long: `Compares the snapshot written by backup with the account, matching users by email, hooks by type and
+		other resources by name, then previews and after confirmation makes the changes: resources missing from the
+		account are created, and those differing from the snapshot updated. Only the --types given are changed,
+		out of ` + strings.Join(snapshot.Names(), ", ") + `. Resources only in the account are never deleted, nor
+		assignments removed. Ids the resources refer to, like the roles of apps, are remapped to the account's
+		namesakes, and references with none are left out and listed in the preview.`,
+		Args: cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := restore(clientConfigs, args[0], types, dryRun, yes, batchSize); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	restoreCommand.Flags().StringSliceVar(&types, "types", nil, "Comma separated types to create and update")
+	restoreCommand.Flags().BoolVar(&dryRun, "dry-run", false, "Print the changes without making them")
+	restoreCommand.Flags().BoolVarP(&yes, "yes", "y", false, "Make the changes without asking for confirmation")
+	restoreCommand.Flags().IntVar(&batchSize, "batch-size", bulk.DefaultBatchSize, "Requests sent at once for types fetched a resource at a time")
+	restoreCommand.MarkFlagRequired("types")
+	rootCmd.AddCommand(restoreCommand)
+}
+
+// restore previews the changes making the account hold the snapshot's resources of the types, then makes them
+func restore(clientConfigs clients.ClientConfigs, dir string, types []string, dryRun bool, yes bool, batchSize int) error {
+	selected, err := snapshot.Select(types, nil)
+	if err != nil {
+		return err
+	}
+	manifest, err := snapshot.ReadManifest(dir)
+	if err != nil {
+		return err
+	}
+	logger.Info("Restoring snapshot", "dir", dir, "taken", manifest.CreatedAt, "of", manifest.URL)
+
+	// every type the snapshot holds is matched, so the selected ones can refer to the rest
+	restorer := &snapshot.Restorer{}
+	saved, live := map[string][]records.Record{}, map[string][]records.Record{}
+	for _, resourceType := range snapshot.Types {
+		if _, ok := manifest.Counts[resourceType.Name]; !ok {
+			continue
+		}
+		if saved[resourceType.Name], err = snapshot.ReadType(dir, resourceType.Name); err != nil {
+			return err
+		}
+		if live[resourceType.Name], err = fetchSnapshotType(clientConfigs, resourceType, batchSize); err != nil {
+			return fmt.Errorf("unable to fetch %s: %s", resourceType.Name, err)
+		}
+		restorer.Match(resourceType.Name, saved[resourceType.Name], live[resourceType.Name])
+	}
+	changes := []snapshot.Change{}
+	for _, resourceType := range selected {
+		if _, ok := saved[resourceType.Name]; !ok {
+			logger.Warn("The snapshot holds none of the type", "type", resourceType.Name)
+			continue
+		}
+		changes = append(changes, restorer.Plan(resourceType.Name, saved[resourceType.Name], live[resourceType.Name])...)
+	}
+	snapshot.WritePreview(os.Stdout, changes)
+	if len(changes) == 0 || dryRun {
+		return nil
+	}
+	if proceed, err := confirm(yes, "Do you want to make these changes?"); !proceed {
+		return err
+	}
+
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	for _, resourceType := range selected {
+		if _, ok := saved[resourceType.Name]; !ok {
+			continue
+		}
+		// planned again so references to resources created by earlier types get their ids
+		for _, change := range restorer.Plan(resourceType.Name, saved[resourceType.Name], live[resourceType.Name]) {
+			id, err := applyRestoreChange(api, resourceType, change)
+			if err != nil {
+				return err
+			}
+			restorer.IDs[change.Type][change.Source] = id
+			if err := assignRestored(api, change, id); err != nil {
+				return err
+			}
+			if change.Create {
+				logger.Info("Created", "type", change.Type, "key", change.Key, "id", id)
+			} else {
+				logger.Info("Updated", "type", change.Type, "key", change.Key, "id", id)
+			}
+		}
+	}
+	return nil
+}
+
+// applyRestoreChange creates or updates the resource, returning its id in the account
+func applyRestoreChange(api *clients.OneLoginAPI, resourceType snapshot.Type, change snapshot.Change) (string, error) {
+	if !change.Create && len(change.Fields) == 0 {
+		return change.ID, nil
+	}
+	body, err := change.Body.JSON()
+	if err != nil {
+		return "", err
+	}
+	if !change.Create {
+		if _, _, err := api.Do(http.MethodPut, resourceType.Path+"/"+change.ID, nil, body); err != nil {
+			return "", fmt.Errorf("unable to update %s %s: %s", change.Type, change.Key, err)
+		}
+		return change.ID, nil
+	}
+	data, _, err := api.Do(http.MethodPost, resourceType.Path, nil, body)
+	if err != nil {
+		return "", fmt.Errorf("unable to create %s %s: %s", change.Type, change.Key, err)
+	}
+	created, err := records.FromJSON(data)
+	if err != nil || len(created) == 0 || created[0].Text("id") == "" {
+		return "", fmt.Errorf("unable to read the id of the created %s %s: %s", change.Type, change.Key, data)
+	}
+	return created[0].Text("id"), nil
+}
+
+// assignRestored adds the resource to the roles or users of its assignments
+func assignRestored(api *clients.OneLoginAPI, change snapshot.Change, id string) error {
+	for field, ids := range change.Assign {
+		numbers := []json.Number{}
+		for _, assigned := range ids {
+			if assigned != snapshot.NewID {
+				numbers = append(numbers, json.Number(assigned))
+			}
+		}
+		if len(numbers) == 0 {
+			continue
+		}
+		var path string
+		var body interface{} = numbers
+		switch change.Type + "." + field {
+		case "roles.users", "roles.admins":
+			path = fmt.Sprintf("/api/2/roles/%s/%s", id, field)
+		case "privileges.role_ids":
+			path, body = privilegePath(id)+"/roles", map[string]interface{}{"roles": numbers}
+		case "privileges.user_ids":
+			path, body = privilegePath(id)+"/users", map[string]interface{}{"users": numbers}
+		default:
+			continue
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		if _, _, err := api.Do(http.MethodPost, path, nil, data); err != nil {
+			return fmt.Errorf("unable to assign %s %s to its %s: %s", change.Type, change.Key, field, err)
+		}
+	}
+	return nil
+}
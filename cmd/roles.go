@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/membership"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var rolesCommand = &cobra.Command{
+		Use:   "roles",
+		Short: `Look up and manage OneLogin roles and who is in them.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var (
+		filters    []string
+		listOutput outputFlags
+	)
+	var rolesListCommand = &cobra.Command{
+		Use:    "list",
+		Short:  `List roles, optionally filtered.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listRoles(clientConfigs, filters, listOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	rolesListCommand.Flags().StringArrayVar(&filters, "filter", nil, "Keep roles whose field matches e.g. name~admin. Repeat to require several")
+	addOutputFlags(rolesListCommand, &listOutput, []string{"id", "name"}, records.TableFormat)
+
+	var getOutput outputFlags
+	var rolesGetCommand = &cobra.Command{
+		Use:               "get <id>",
+		Short:             `Print a role.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeList(lookupIDs("/api/2/roles"))),
+		PreRun:            loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := getRole(clientConfigs, args[0], getOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(rolesGetCommand, &getOutput, nil, records.JSONFormat)
+
+	var createName string
+	var rolesCreateCommand = &cobra.Command{
+		Use:    "create",
+		Short:  `Create a role.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := createRole(clientConfigs, createName); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	rolesCreateCommand.Flags().StringVar(&createName, "name", "", "Name of the role")
+	rolesCreateCommand.MarkFlagRequired("name")
+
+	var deleteYes bool
+	var rolesDeleteCommand = &cobra.Command{
+		Use:               "delete <id>",
+		Short:             `Delete a role.`,
+		Long:              `Deletes the role after asking for confirmation, unless --yes is given.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: firstArg(completeList(lookupIDs("/api/2/roles"))),
+		PreRun:            loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := deleteRole(clientConfigs, args[0], deleteYes); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	rolesDeleteCommand.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Delete without asking for confirmation")
+
+	var (
+		addRole      string
+		addEmails    []string
+		addDryRun    bool
+		removeRole   string
+		removeEmails []string
+		removeDryRun bool
+	)
+	var rolesAddUsersCommand = &cobra.Command{
+		Use:    "add-users",
+		Short:  `Add users to a role by email.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := changeRoleUsers(clientConfigs, http.MethodPost, addRole, addEmails, addDryRun); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	rolesAddUsersCommand.Flags().StringVar(&addRole, "role", "", "Id of the role")
+	rolesAddUsersCommand.RegisterFlagCompletionFunc("role", completeList(lookupIDs("/api/2/roles")))
+	rolesAddUsersCommand.Flags().StringSliceVar(&addEmails, "emails", nil, "Comma separated emails of the users to add")
+	rolesAddUsersCommand.Flags().BoolVar(&addDryRun, "dry-run", false, "Log the users that would be added without adding them")
+	rolesAddUsersCommand.MarkFlagRequired("role")
+	rolesAddUsersCommand.MarkFlagRequired("emails")
+	var rolesRemoveUsersCommand = &cobra.Command{
+		Use:    "remove-users",
+		Short:  `Remove users from a role by email.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := changeRoleUsers(clientConfigs, http.MethodDelete, removeRole, removeEmails, removeDryRun); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	rolesRemoveUsersCommand.Flags().StringVar(&removeRole, "role", "", "Id of the role")
+	rolesRemoveUsersCommand.RegisterFlagCompletionFunc("role", completeList(lookupIDs("/api/2/roles")))
+	rolesRemoveUsersCommand.Flags().StringSliceVar(&removeEmails, "emails", nil, "Comma separated emails of the users to remove")
+	rolesRemoveUsersCommand.Flags().BoolVar(&removeDryRun, "dry-run", false, "Log the users that would be removed without removing them")
+	rolesRemoveUsersCommand.MarkFlagRequired("role")
+	rolesRemoveUsersCommand.MarkFlagRequired("emails")
+
+	var (
+		syncFile   string
+		syncDryRun bool
+		syncYes    bool
+	)
+	var rolesSyncCommand = &cobra.Command{
+		Use:   "sync",
+		Short: `Make the members of roles those listed in a CSV file.`,
+		Long: `Reads a CSV file with role and email columns, a row per member, and adds and removes users so the roles
+		listed have exactly those members. Roles are named or given by id, and roles not in the file are left alone.
+		The changes are previewed like a diff and applied once confirmed, unless --dry-run or --yes is given.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncRoles(clientConfigs, syncFile, syncDryRun, syncYes); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	rolesSyncCommand.Flags().StringVar(&syncFile, "from-csv", "", "CSV file with role and email columns")
+	rolesSyncCommand.Flags().BoolVar(&syncDryRun, "dry-run", false, "Preview the changes without making them")
+	rolesSyncCommand.Flags().BoolVarP(&syncYes, "yes", "y", false, "Make the changes without asking for confirmation")
+	rolesSyncCommand.MarkFlagRequired("from-csv")
+
+	rolesCommand.AddCommand(rolesListCommand, rolesGetCommand, rolesCreateCommand, rolesDeleteCommand,
+		rolesAddUsersCommand, rolesRemoveUsersCommand, rolesSyncCommand)
+	rootCmd.AddCommand(rolesCommand)
+}
+
+// listRoles prints the roles matching every filter
+func listRoles(clientConfigs clients.ClientConfigs, filterFlags []string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	filters, err := records.ParseFilters(filterFlags)
+	if err != nil {
+		return err
+	}
+	roles, err := fetchAll(clientConfigs, "/api/2/roles", nil)
+	if err != nil {
+		return err
+	}
+	matching := []records.Record{}
+	for _, role := range roles {
+		if records.MatchAll(role, filters) {
+			matching = append(matching, role)
+		}
+	}
+	return output.write(matching)
+}
+
+// getRole prints the role with the id
+func getRole(clientConfigs clients.ClientConfigs, id string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	roleID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid role id %s", id)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	data, _, err := api.Do(http.MethodGet, fmt.Sprintf("/api/2/roles/%d", roleID), nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get role %d: %s", roleID, err)
+	}
+	role, err := records.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("unable to read role %d: %s", roleID, err)
+	}
+	return output.writeOne(role[0])
+}
+
+// createRole creates a role with the name
+func createRole(clientConfigs clients.ClientConfigs, name string) error {
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"name": name})
+	if err != nil {
+		return err
+	}
+	data, _, err := api.Do(http.MethodPost, "/api/2/roles", nil, body)
+	if err != nil {
+		return fmt.Errorf("unable to create role %s: %s", name, err)
+	}
+	created, err := records.FromJSON(data)
+	if err != nil || len(created) == 0 {
+		return fmt.Errorf("unable to read created role: %s", data)
+	}
+	logger.Info("Created role", "id", created[0].Text("id"), "name", name)
+	return nil
+}
+
+// deleteRole deletes the role once confirmed, naming it so the wrong id is caught before it is too late
+func deleteRole(clientConfigs clients.ClientConfigs, id string, yes bool) error {
+	roleID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid role id %s", id)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/2/roles/%d", roleID)
+	data, _, err := api.Do(http.MethodGet, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get role %d: %s", roleID, err)
+	}
+	role, err := records.FromJSON(data)
+	if err != nil {
+		return fmt.Errorf("unable to read role %d: %s", roleID, err)
+	}
+	if proceed, err := confirm(yes, fmt.Sprintf("This will delete role %d (%s). Do you want to continue?", roleID, role[0].Text("name"))); !proceed {
+		return err
+	}
+	if _, _, err := api.Do(http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("unable to delete role %d: %s", roleID, err)
+	}
+	logger.Info("Deleted role", "id", roleID, "name", role[0].Text("name"))
+	return nil
+}
+
+// changeRoleUsers adds the users with the emails to the role with POST, or removes them with DELETE
+func changeRoleUsers(clientConfigs clients.ClientConfigs, method string, id string, emails []string, dryRun bool) error {
+	roleID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid role id %s", id)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	userIDs, err := userIDsByEmail(api, emails)
+	if err != nil {
+		return err
+	}
+	change := "Added users to role"
+	if method == http.MethodDelete {
+		change = "Removed users from role"
+	}
+	if dryRun {
+		logger.Info(change, "role", roleID, "users", strings.Join(emails, ","), "dry_run", true)
+		return nil
+	}
+	if err := sendRoleUsers(api, method, roleID, userIDs); err != nil {
+		return err
+	}
+	logger.Info(change, "role", roleID, "users", strings.Join(emails, ","))
+	return nil
+}
+
+// sendRoleUsers adds users to the role with POST, or removes them with DELETE
+func sendRoleUsers(api *clients.OneLoginAPI, method string, roleID int, userIDs []int) error {
+	body, err := json.Marshal(userIDs)
+	if err != nil {
+		return err
+	}
+	if _, _, err := api.Do(method, fmt.Sprintf("/api/2/roles/%d/users", roleID), nil, body); err != nil {
+		return fmt.Errorf("unable to change the users of role %d: %s", roleID, err)
+	}
+	return nil
+}
+
+// syncRoles adds and removes users so the roles in the CSV file have exactly the members it lists
+func syncRoles(clientConfigs clients.ClientConfigs, file string, dryRun bool, yes bool) error {
+	in, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %s", file, err)
+	}
+	defer in.Close()
+	rows, err := records.ReadCSV(in, nil)
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %s", file, err)
+	}
+	roles, err := fetchAll(clientConfigs, "/api/2/roles", nil)
+	if err != nil {
+		return err
+	}
+	roleIDs := map[string]int{} // by name and by id, as the file may give either
+	for _, role := range roles {
+		id, _ := strconv.Atoi(role.Text("id"))
+		roleIDs[role.Text("name")] = id
+		roleIDs[role.Text("id")] = id
+	}
+	desired := map[string][]string{}
+	for i, row := range rows {
+		role, email := row.Text("role"), row.Text("email")
+		if role == "" || email == "" {
+			return fmt.Errorf("line %d of %s needs a role and an email", i+2, file)
+		}
+		if _, ok := roleIDs[role]; !ok {
+			return fmt.Errorf("line %d of %s names role %s, which doesn't exist", i+2, file, role)
+		}
+		desired[role] = append(desired[role], email)
+	}
+
+	users, err := fetchUsers(clientConfigs, nil)
+	if err != nil {
+		return err
+	}
+	emails, ids := map[string]string{}, map[string]int{}
+	for _, user := range users {
+		email := strings.ToLower(user.Text("email"))
+		id, _ := strconv.Atoi(user.Text("id"))
+		emails[user.Text("id")], ids[email] = email, id
+	}
+	current := map[string][]string{}
+	for role := range desired {
+		members, err := fetchAll(clientConfigs, fmt.Sprintf("/api/2/roles/%d/users", roleIDs[role]), nil)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			current[role] = append(current[role], emails[member.Text("id")])
+		}
+	}
+	unknown := []string{}
+	for _, members := range desired {
+		for _, email := range members {
+			if _, ok := ids[strings.ToLower(email)]; !ok {
+				unknown = append(unknown, email)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("no users have the emails %s", strings.Join(unknown, ", "))
+	}
+
+	changes := membership.Diff(desired, current)
+	membership.WritePreview(os.Stdout, changes)
+	if len(changes) == 0 || dryRun {
+		return nil
+	}
+	if proceed, err := confirm(yes, "Do you want to make these changes?"); !proceed {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	idsOf := func(emails []string) []int {
+		out := make([]int, len(emails))
+		for i, email := range emails {
+			out[i] = ids[email]
+		}
+		return out
+	}
+	for _, change := range changes {
+		if len(change.Add) > 0 {
+			if err := sendRoleUsers(api, http.MethodPost, roleIDs[change.Role], idsOf(change.Add)); err != nil {
+				return err
+			}
+		}
+		if len(change.Remove) > 0 {
+			if err := sendRoleUsers(api, http.MethodDelete, roleIDs[change.Role], idsOf(change.Remove)); err != nil {
+				return err
+			}
+		}
+		logger.Info("Synced role", "role", change.Role, "added", len(change.Add), "removed", len(change.Remove))
+	}
+	return nil
+}
@@ -5,7 +5,7 @@ Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
 You may obtain a copy of the License at
 
-    http://www.apache.org/licenses/LICENSE-2.0
+	http://www.apache.org/licenses/LICENSE-2.0
 
 Unless required by applicable law or agreed to in writing, software
 distributed under the License is distributed on an "AS IS" BASIS,
@@ -17,10 +17,13 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
 	"github.com/spf13/cobra"
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	homedir "github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
@@ -28,12 +31,95 @@ import (
 
 var cfgFile string
 
+// profile selected for this invocation, overriding the active profile without changing it
+var profileName string
+
+// refuse credentials whose scope allows writes
+var readOnly bool
+
+// AWS shared config profile and role to assume for AWS importables
+var awsProfile, awsRoleARN, awsExternalID string
+
+// attempts per API request before rate limits and server errors are reported
+var apiMaxAttempts int
+
+// bound on how long the command runs, 0 for no bound
+var timeout time.Duration
+
+// certificates to trust for API requests, or not to verify them at all
+var caBundle string
+var insecureSkipVerify bool
+
+// how much of every API request to log, -v for requests and -vv for their bodies. --debug is the same as -v
+var verbosity int
+var debug bool
+
+// API responses are reused within the run of commands caching them unless caching is off, and across runs for the TTL
+var noCache bool
+var cacheTTL time.Duration
+
+// pages of a collection requested at once
+var fetchConcurrency int
+
+// OneLogin API requests sent a second at most by the whole run, 0 for no limit
+var rps float64
+
+// how often long running commands log how much of the rate limit is left, 0 to never
+var rateStatusInterval time.Duration
+
+// directories API responses are replayed from instead of requested, or recorded to
+var mockDir, recordDir string
+
+// text, or json for CI systems and log aggregators
+var logFormat string
+
+// log each step of long runs instead of drawing progress bars on a terminal
+var noProgress bool
+
+// log only warnings and errors, for scripts reading the output and exit code
+var quiet bool
+
+// fail rather than prompt, for cron jobs and CI
+var noInput bool
+
+// Version of the CLI, set for releases with -ldflags "-X github.com/onelogin/onelogin/cmd.Version=v1.4.0"
+var Version = "dev"
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
-	Use:   "onelogin",
-	Short: "A CLI for managing IAM and Authentication resources",
-	Long:  `The OneLogin CLI provides a convenient interface for managing OneLogin resources from the command line such as Apps and User Mappings. `,
-	Run:   func(cmd *cobra.Command, args []string) { fmt.Println("Welcome to OneLogin") },
+	Use:     "onelogin",
+	Short:   "A CLI for managing IAM and Authentication resources",
+	Long:    `The OneLogin CLI provides a convenient interface for managing OneLogin resources from the command line such as Apps and User Mappings. `,
+	Version: Version,
+	Run:     func(cmd *cobra.Command, args []string) { fmt.Println("Welcome to OneLogin") },
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		outputGiven := cmd.Flags().Changed("output")
+		if err := applyCommandDefaults(cmd); err != nil {
+			return err
+		}
+		if err := applyOutputFormat(cmd, outputGiven); err != nil {
+			return err
+		}
+		cacheEnabled = cachedCommands[cmd]
+		if err := configureLogger(); err != nil {
+			return err
+		}
+		runContext = commandContext(timeout)
+		startRun(cmd)
+		if mockDir != "" && recordDir != "" {
+			return fmt.Errorf("--mock-dir and --record-dir can't be used together")
+		}
+		if mockDir != "" {
+			logger.Warn("--mock-dir is set. API responses are replayed from fixtures and nothing is sent to the APIs", "dir", mockDir)
+		}
+		if insecureSkipVerify {
+			logger.Warn("--insecure-skip-verify is set. TLS certificates of the OneLogin and AWS APIs are not verified and credentials can be intercepted")
+		}
+		return nil
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		recordRun(0)
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -53,18 +139,65 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.onelogin.json)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", os.Getenv("ONELOGIN_PROFILE"), "profile to use instead of the active one (env ONELOGIN_PROFILE)")
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, "refuse to use credentials unless the profile's scope is read only")
+	rootCmd.PersistentFlags().StringVar(&awsProfile, "aws-profile", "", "AWS shared config profile, including SSO profiles (defaults to AWS_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&awsRoleARN, "aws-role-arn", "", "AWS role to assume with the AWS credentials")
+	rootCmd.PersistentFlags().StringVar(&awsExternalID, "aws-external-id", "", "external ID required to assume the AWS role")
+	rootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0, "stop the command if it runs longer than this e.g. 10m, aborting API requests in flight (no limit by default)")
+	rootCmd.PersistentFlags().StringVar(&caBundle, "ca-bundle", "", "PEM file of CA certificates to trust for API requests besides the system's, e.g. of a TLS inspecting proxy")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "don't verify TLS certificates of the APIs. Unsafe, for debugging only")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "log API requests with their status and latency, -vv to log their bodies with secrets redacted")
+	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "same as -v")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", logger.TextFormat, "format of log messages on stderr, text or json")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "log only warnings and errors, without progress bars")
+	rootCmd.PersistentFlags().BoolVar(&noInput, "no-input", false, "never prompt, as in cron or CI: commands needing confirmation fail unless given --yes or --auto-approve, and values otherwise asked for must be given with flags or variables")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "log each resource of long imports instead of drawing a progress bar, as when stdout isn't a terminal")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "output format of commands printing resources, reports and differences: table, json, yaml or csv (defaults to each command's)")
+	rootCmd.PersistentFlags().BoolVar(&recordMetrics, "metrics", false, "record how long each command takes and the API requests it makes in ~/.onelogin/metrics.jsonl, for onelogin stats. Nothing is sent anywhere")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", 0, "reuse OneLogin API responses kept on disk by runs of terraform-import and terraform-export within this long e.g. 5m (only within the run by default)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "request every OneLogin API response in terraform-import and terraform-export, even ones already fetched in the run")
+	rootCmd.PersistentFlags().IntVar(&fetchConcurrency, "fetch-concurrency", clients.DefaultFetchConcurrency, "pages of users and apps requested at once when listing them")
+	rootCmd.PersistentFlags().Float64Var(&rps, "rps", 0, "OneLogin API requests sent a second at most, shared by every request of the run however many are sent at once e.g. 2.5 (no limit by default)")
+	rootCmd.PersistentFlags().DurationVar(&rateStatusInterval, "rate-status-interval", time.Minute, "how often commands sending a request per resource log how much of the rate limit is left and when they should be done (0 never)")
+	rootCmd.PersistentFlags().StringVar(&mockDir, "mock-dir", "", "replay the API responses recorded in this directory instead of calling the APIs, e.g. for demos and tests")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record-dir", "", "record the API responses to this directory as fixtures for --mock-dir")
+	rootCmd.PersistentFlags().IntVar(&apiMaxAttempts, "api-max-attempts", clients.DefaultRetryPolicy.MaxAttempts, "attempts per API request, retrying with backoff on rate limits and server errors (1 disables retries)")
+	rootCmd.RegisterFlagCompletionFunc("profile", completeList(profileNames))
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
+// configureLogger writes log messages in the format given with --log-format, including debug messages when API
+// requests are logged
+func configureLogger() error {
+	l, err := logger.New(os.Stderr, logFormat)
+	if err != nil {
+		return err
+	}
+	if debugLevel() > clients.DebugOff {
+		if quiet {
+			return fmt.Errorf("--quiet can't be used with --verbose or --debug")
+		}
+		l.SetLevel(logger.DebugLevel)
+	}
+	if quiet {
+		l.SetLevel(logger.WarnLevel)
+	}
+	logger.SetDefault(l)
+	// libraries like the OneLogin SDK log with the log package, mostly about failed requests
+	log.SetFlags(0)
+	log.SetOutput(l.Writer(logger.WarnLevel))
+	return nil
+}
+
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
 	home, err := homedir.Dir()
 	if err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fatal(err)
 	}
 	if cfgFile != "" {
 		// Use config file from the flag.
@@ -77,6 +210,10 @@ func initConfig() {
 
 	viper.AutomaticEnv() // read in environment variables that match
 
+	if err := initCommandDefaults(filepath.Join(home, ".onelogin")); err != nil {
+		fatal(err)
+	}
+
 	// If a config file is found, read it in.
 	if err := viper.ReadInConfig(); err != nil {
 		p := filepath.Join(home, ".onelogin")
@@ -84,7 +221,7 @@ func initConfig() {
 		p = filepath.Join(p, "profiles.json")
 		_, err := os.Create(p)
 		if err != nil {
-			log.Fatalln("Unable to create config file!")
+			logger.Fatal("Unable to create config file!")
 		}
 		viper.ReadInConfig()
 	}
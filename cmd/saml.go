@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/saml"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// samlAssertFlags say whose assertion for which app to generate
+type samlAssertFlags struct {
+	app       int
+	user      string
+	subdomain string
+	device    string
+	otp       string
+	raw       bool
+	format    string
+}
+
+// samlAssertionResponse is what the SAML Assertion API returns: the encoded SAML response in data, or a state token
+// and the user's devices when a factor has to be verified first
+type samlAssertionResponse struct {
+	Data       string `json:"data"`
+	Message    string `json:"message"`
+	StateToken string `json:"state_token"`
+	Devices    []struct {
+		DeviceID   json.Number `json:"device_id"`
+		DeviceType string      `json:"device_type"`
+	} `json:"devices"`
+}
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var samlCommand = &cobra.Command{
+		Use:   "saml",
+		Short: `Check what SAML apps receive about users.`,
+	}
+
+	var flags samlAssertFlags
+	var samlAssertCommand = &cobra.Command{
+		Use:   "assert",
+		Short: `Generate a SAML assertion for a user and app, and print its attributes.`,
+		Long: `Signs the user in to the app through the SAML Assertion API and prints the subject and attributes of the
+		assertion the app would receive, so attribute mappings can be checked without a browser. The password is read
+		from ONELOGIN_SAML_PASSWORD, for test accounts, or else prompted for. When the user has to verify a factor,
+		the OTP is taken from --otp or prompted for, and the factor from --device or chosen when there are several.
+		The subdomain defaults to the active profile's.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+			if profile := loadProfiles(profileName)[0]; flags.subdomain == "" && profile != nil {
+				flags.subdomain = profile.Subdomain
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			flags.format = formatOr(records.TableFormat)
+			if err := assertSAML(clientConfigs, flags); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	samlAssertCommand.Flags().IntVar(&flags.app, "app", 0, "Id of the SAML app")
+	samlAssertCommand.RegisterFlagCompletionFunc("app", completeList(lookupIDs("/api/2/apps")))
+	samlAssertCommand.Flags().StringVar(&flags.user, "user", "", "Username or email of the user")
+	samlAssertCommand.Flags().StringVar(&flags.subdomain, "subdomain", "", "Subdomain of the OneLogin account e.g. acme for acme.onelogin.com")
+	samlAssertCommand.Flags().StringVar(&flags.device, "device", "", "Id of the device to verify the factor of, when the user has several")
+	samlAssertCommand.Flags().StringVar(&flags.otp, "otp", "", "One time password of the factor, when the user has to verify one")
+	samlAssertCommand.Flags().BoolVar(&flags.raw, "raw", false, "Print the SAML response XML instead of its attributes")
+	samlAssertCommand.MarkFlagRequired("app")
+	samlAssertCommand.MarkFlagRequired("user")
+
+	acceptOutput(samlAssertCommand)
+	samlCommand.AddCommand(samlAssertCommand)
+	rootCmd.AddCommand(samlCommand)
+}
+
+// assertSAML generates an assertion for the user and app, verifying a factor when asked to, and prints it
+func assertSAML(clientConfigs clients.ClientConfigs, flags samlAssertFlags) error {
+	if flags.format != records.TableFormat && flags.format != records.JSONFormat && flags.format != records.YAMLFormat {
+		return fmt.Errorf("unsupported output %s, expected table, json or yaml", flags.format)
+	}
+	if flags.subdomain == "" {
+		return fmt.Errorf("no subdomain, give --subdomain or add one to the profile")
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	password := os.Getenv("ONELOGIN_SAML_PASSWORD")
+	if password == "" {
+		if password, err = prompt(fmt.Sprintf("Password of %s", flags.user)); err != nil {
+			return err
+		}
+	}
+	response, err := requestSAML(api, "/api/2/saml_assertion", map[string]interface{}{
+		"username_or_email": flags.user, "password": password, "app_id": flags.app, "subdomain": flags.subdomain,
+	})
+	if err != nil {
+		return err
+	}
+	if response.Data == "" && response.StateToken != "" {
+		if response, err = verifySAMLFactor(api, flags, response); err != nil {
+			return err
+		}
+	}
+	if response.Data == "" {
+		return fmt.Errorf("no SAML response was generated: %s", response.Message)
+	}
+	if flags.raw {
+		data, err := base64.StdEncoding.DecodeString(response.Data)
+		if err != nil {
+			return fmt.Errorf("unable to decode the SAML response: %s", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	assertion, err := saml.Parse(response.Data)
+	if err != nil {
+		return err
+	}
+	return writeAssertion(assertion, flags.format)
+}
+
+// verifySAMLFactor verifies the factor of the device given, or the only or chosen one, with the OTP
+func verifySAMLFactor(api *clients.OneLoginAPI, flags samlAssertFlags, pending samlAssertionResponse) (samlAssertionResponse, error) {
+	device := flags.device
+	if device == "" && len(pending.Devices) == 1 {
+		device = pending.Devices[0].DeviceID.String()
+	}
+	if device == "" {
+		fmt.Println(pending.Message)
+		for _, candidate := range pending.Devices {
+			fmt.Printf("  %s  %s\n", candidate.DeviceID, candidate.DeviceType)
+		}
+		var err error
+		if device, err = prompt("Id of the device to verify"); err != nil {
+			return samlAssertionResponse{}, err
+		}
+	}
+	otp := flags.otp
+	if otp == "" {
+		var err error
+		if otp, err = prompt("One time password"); err != nil {
+			return samlAssertionResponse{}, err
+		}
+	}
+	return requestSAML(api, "/api/2/saml_assertion/verify_factor", map[string]interface{}{
+		"app_id": flags.app, "device_id": device, "state_token": pending.StateToken, "otp_token": otp,
+	})
+}
+
+// requestSAML sends the request to the SAML Assertion API
+func requestSAML(api *clients.OneLoginAPI, path string, request map[string]interface{}) (samlAssertionResponse, error) {
+	var response samlAssertionResponse
+	body, err := json.Marshal(request)
+	if err != nil {
+		return response, err
+	}
+	data, _, err := api.Do(http.MethodPost, path, nil, body)
+	if err != nil {
+		return response, fmt.Errorf("unable to generate a SAML assertion: %s", err)
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return response, fmt.Errorf("unable to read the SAML assertion: %s", err)
+	}
+	return response, nil
+}
+
+// writeAssertion prints the assertion's subject and conditions, then a row per attribute, or all of it as JSON
+func writeAssertion(assertion saml.Assertion, format string) error {
+	summary := records.Record{
+		"name_id":         assertion.NameID,
+		"name_id_format":  assertion.NameIDFormat,
+		"issuer":          assertion.Issuer,
+		"audience":        assertion.Audience,
+		"not_before":      assertion.NotBefore,
+		"not_on_or_after": assertion.NotOnOrAfter,
+	}
+	fields := []string{"name_id", "name_id_format", "issuer", "audience", "not_before", "not_on_or_after"}
+	if format != records.TableFormat {
+		attributes := map[string][]string{}
+		for _, attribute := range assertion.Attributes {
+			attributes[attribute.Name] = attribute.Values
+		}
+		summary["attributes"] = attributes
+		return records.WriteOne(os.Stdout, format, summary, append(fields, "attributes"))
+	}
+	if err := records.WriteOne(os.Stdout, format, summary, fields); err != nil {
+		return err
+	}
+	fmt.Println()
+	rows := []records.Record{}
+	for _, attribute := range assertion.Attributes {
+		rows = append(rows, records.Record{"attribute": attribute.Name, "values": strings.Join(attribute.Values, ", ")})
+	}
+	return records.Write(os.Stdout, format, rows, []string{"attribute", "values"})
+}
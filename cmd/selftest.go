@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/selftest"
+	"github.com/onelogin/onelogin/terraform/exec"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var (
+		yes          bool
+		keep         bool
+		connector    int
+		skipProvider bool
+		runnerName   string
+		binary       string
+	)
+	var selftestCommand = &cobra.Command{
+		Use:   "selftest",
+		Short: `Check importing end to end against a sandbox account.`,
+		Long: `Creates a throwaway role, user and app named onelogin-cli-selftest-<time> in the account, finds them the
+		way terraform-import does, plans their imports and writes their configuration, then runs terraform init and
+		validate on it in a scratch directory and checks their data against the provider's schema. The resources are
+		deleted at the end, even when a step fails. Prints how each step went and fails when any did, catching
+		changes to the API or the provider that break importing. Only run it against a sandbox account.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			var runner *tfexec.Runner
+			if !skipProvider {
+				created, err := tfexec.New(runnerName, binary, "")
+				if err != nil {
+					fatal(err)
+				}
+				runner = &created
+			}
+			if err := runSelftest(clientConfigs, runner, connector, keep, yes); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	selftestCommand.Flags().BoolVarP(&yes, "yes", "y", false, "Create and delete the resources without asking for confirmation")
+	selftestCommand.Flags().BoolVar(&keep, "keep", false, "Leave the resources in the account instead of deleting them, to look into a failure")
+	selftestCommand.Flags().IntVar(&connector, "connector", selftest.DefaultConnector, "Connector of the app created")
+	selftestCommand.Flags().BoolVar(&skipProvider, "skip-provider", false, "Only parse the configuration written, without running terraform")
+	selftestCommand.Flags().StringVar(&runnerName, "runner", "terraform", "Tool driving the scratch workspace (terraform or terragrunt)")
+	selftestCommand.Flags().StringVar(&binary, "terraform-binary", "", "Path to the terraform compatible binary e.g. /usr/local/bin/tofu (defaults to terraform, or tofu if terraform is not on PATH)")
+	acceptOutput(selftestCommand)
+	rootCmd.AddCommand(selftestCommand)
+}
+
+// runSelftest runs the selftest suite against the account once confirmed and prints its report. Without a runner the
+// configuration is only parsed
+func runSelftest(clientConfigs clients.ClientConfigs, runner *tfexec.Runner, connector int, keep bool, yes bool) error {
+	format, err := reportFormat(false)
+	if err != nil {
+		return err
+	}
+	if proceed, err := confirm(yes, fmt.Sprintf("This will create and then delete a role, a user and an app in the account at %s. Only run it against a sandbox account. Do you want to continue?", clientConfigs.OneLoginURL)); !proceed {
+		return err
+	}
+	clientList := clients.New(clientConfigs)
+	api, err := clientList.OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	suite := selftest.Suite{
+		API:         api,
+		Importables: tfimportables.New(clientList),
+		Prefix:      fmt.Sprintf("onelogin-cli-selftest-%d", time.Now().Unix()),
+		Connector:   connector,
+		Keep:        keep,
+	}
+	if runner != nil {
+		suite.Verify = func(config []byte) (*tfschema.ProviderSchemas, error) {
+			return verifyWithProvider(*runner, config)
+		}
+	}
+	report := suite.Run()
+	if format == records.TableFormat {
+		list, err := records.From(report.Results)
+		if err != nil {
+			return err
+		}
+		err = records.Write(os.Stdout, format, list, []string{"step", "passed", "duration", "detail"})
+	} else {
+		err = records.WriteValue(os.Stdout, format, report)
+	}
+	if err != nil {
+		return err
+	}
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%d of %d selftest steps failed", failed, len(report.Results))
+	}
+	logger.Info("Selftest passed", "steps", len(report.Results))
+	return nil
+}
+
+// verifyWithProvider runs init and validate on the configuration in a scratch directory, returning the provider
+// schemas found there
+func verifyWithProvider(runner tfexec.Runner, config []byte) (*tfschema.ProviderSchemas, error) {
+	dir, err := ioutil.TempDir("", "onelogin-selftest")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.tf"), config, 0600); err != nil {
+		return nil, err
+	}
+	runner.WorkingDir = dir
+	logger.Info("Initializing Terraform...", "command", runner.Binary+" init", "dir", dir)
+	if err := runner.Init(); err != nil {
+		return nil, err
+	}
+	if err := runner.Validate(); err != nil {
+		return nil, err
+	}
+	return loadSchemas(runner)
+}
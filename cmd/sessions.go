@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/deprovision"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/spf13/cobra"
+	"net/http"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var sessionsCommand = &cobra.Command{
+		Use:   "sessions",
+		Short: `End the OneLogin sessions of users.`,
+	}
+
+	var (
+		users  []string
+		dryRun bool
+	)
+	var sessionsRevokeCommand = &cobra.Command{
+		Use:   "revoke",
+		Short: `Sign users out of OneLogin, ending every session they have.`,
+		Long: `Ends every OneLogin session of the users, so they have to sign in again before reaching any app through
+		OneLogin. Users are given by id or email, and every one is tried even when some fail.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := revokeSessions(clientConfigs, users, dryRun); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	sessionsRevokeCommand.Flags().StringSliceVar(&users, "user", nil, "Id or email of the user to sign out. Repeat or separate with commas for several")
+	sessionsRevokeCommand.Flags().BoolVar(&dryRun, "dry-run", false, "Log the users that would be signed out without signing them out")
+	sessionsRevokeCommand.MarkFlagRequired("user")
+
+	sessionsCommand.AddCommand(sessionsRevokeCommand)
+	rootCmd.AddCommand(sessionsCommand)
+}
+
+// revokeSessions ends the sessions of each user, reporting how many couldn't be signed out
+func revokeSessions(clientConfigs clients.ClientConfigs, references []string, dryRun bool) error {
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, reference := range references {
+		user, err := deprovision.FindUser(api, reference)
+		if err != nil {
+			logger.Error("Unable to revoke sessions", "user", reference, "error", err)
+			failed++
+			continue
+		}
+		if dryRun {
+			logger.Info("Revoked sessions", "user", user.Text("email"), "id", user.Text("id"), "dry_run", true)
+			continue
+		}
+		if _, _, err := api.Do(http.MethodPut, "/api/1/users/"+user.Text("id")+"/logout", nil, nil); err != nil {
+			logger.Error("Unable to revoke sessions", "user", user.Text("email"), "error", err)
+			failed++
+			continue
+		}
+		logger.Info("Revoked sessions", "user", user.Text("email"), "id", user.Text("id"))
+	}
+	if failed > 0 {
+		return partial(fmt.Errorf("unable to revoke the sessions of %d of %d users", failed, len(references)), failed, len(references))
+	}
+	return nil
+}
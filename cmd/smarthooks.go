@@ -0,0 +1,476 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/smarthooks"
+	"github.com/spf13/cobra"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var smarthooksCommand = &cobra.Command{
+		Use:   "smarthooks",
+		Short: `Deploy, watch and try out OneLogin Smart Hooks.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var deployDryRun bool
+	var smarthooksDeployCommand = &cobra.Command{
+		Use:   "deploy <dir>",
+		Short: `Create or update a hook from a directory.`,
+		Long: `Deploys the hook described by hook.yaml in the directory, with the code of its function file. Its
+		env_vars are set from the environment variables of the same name first. The hook with the id in hook.yaml is
+		updated, or else the hook of the same type, and a hook is created when there is neither.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := deployHook(clientConfigs, args[0], deployDryRun); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	smarthooksDeployCommand.Flags().BoolVar(&deployDryRun, "dry-run", false, "Print the hook that would be deployed without deploying it")
+
+	var (
+		follow   bool
+		interval time.Duration
+	)
+	var smarthooksLogsCommand = &cobra.Command{
+		Use:    "logs <id>",
+		Short:  `Print the logs of a hook's runs.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := hookLogs(os.Stdout, clientConfigs, args[0], follow, interval); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	smarthooksLogsCommand.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing the logs of new runs until interrupted")
+	smarthooksLogsCommand.Flags().DurationVar(&interval, "interval", 5*time.Second, "How often to check for new runs with --follow")
+
+	var (
+		payloadFile string
+		hookEnv     []string
+		node        string
+	)
+	var smarthooksInvokeCommand = &cobra.Command{
+		Use:   "invoke <id|dir>",
+		Short: `Run a hook locally with node.`,
+		Long: `Runs the code of the deployed hook with the id, or of the hook in the directory, with node on this
+		machine. The hook's handler is called with the context read from --payload and what it returns is printed.
+		Env var values can't be read back from OneLogin, so give the hook's env vars with --env or in the environment.`,
+		Args: cobra.ExactArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			if info, err := os.Stat(args[0]); err != nil || !info.IsDir() {
+				loadConfigs(cmd, args) // only deployed hooks need credentials
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := invokeHook(clientConfigs, args[0], payloadFile, hookEnv, node); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	smarthooksInvokeCommand.Flags().StringVar(&payloadFile, "payload", "", "JSON file with the context the hook is called with, or - for stdin")
+	smarthooksInvokeCommand.Flags().StringArrayVar(&hookEnv, "env", nil, "Set an env var for the run e.g. API_KEY=secret. Repeat to set several")
+	smarthooksInvokeCommand.Flags().StringVar(&node, "node", "node", "node binary to run the hook with")
+	smarthooksInvokeCommand.MarkFlagRequired("payload")
+
+	var smarthooksEnvCommand = &cobra.Command{
+		Use:   "env",
+		Short: `Manage the env vars hooks read their secrets from.`,
+		Long: `Manages the account's hook env vars, which hooks name in their env_vars to read as process.env. OneLogin
+		never returns their values, so get and list only show which exist and when they changed.`,
+	}
+	var envListOutput outputFlags
+	var smarthooksEnvListCommand = &cobra.Command{
+		Use:    "list",
+		Short:  `List hook env vars.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listHookEnvs(clientConfigs, envListOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(smarthooksEnvListCommand, &envListOutput, []string{"id", "name", "created_at", "updated_at"}, records.TableFormat)
+	var envGetOutput outputFlags
+	var smarthooksEnvGetCommand = &cobra.Command{
+		Use:    "get <NAME>",
+		Short:  `Print a hook env var, without its value.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := getHookEnv(clientConfigs, args[0], envGetOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(smarthooksEnvGetCommand, &envGetOutput, nil, records.JSONFormat)
+	var fromEnv bool
+	var smarthooksEnvSetCommand = &cobra.Command{
+		Use:   "set <NAME[=VALUE]>",
+		Short: `Create a hook env var or change its value.`,
+		Long: `Sets the env var to the value after =, to the environment variable of the same name with --from-env, or
+		else to what is read from stdin, which keeps secrets out of shell history and CI logs.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := setHookEnvCommand(clientConfigs, args[0], fromEnv); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	smarthooksEnvSetCommand.Flags().BoolVar(&fromEnv, "from-env", false, "Read the value from the environment variable of the same name")
+	var smarthooksEnvUnsetCommand = &cobra.Command{
+		Use:    "unset <NAME>",
+		Short:  `Delete a hook env var.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := unsetHookEnv(clientConfigs, args[0]); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	smarthooksEnvCommand.AddCommand(smarthooksEnvListCommand, smarthooksEnvGetCommand, smarthooksEnvSetCommand, smarthooksEnvUnsetCommand)
+
+	smarthooksCommand.AddCommand(smarthooksDeployCommand, smarthooksLogsCommand, smarthooksInvokeCommand, smarthooksEnvCommand)
+	rootCmd.AddCommand(smarthooksCommand)
+}
+
+// deployHook sets the env vars of the hook in the directory, then updates the hook or creates it
+func deployHook(clientConfigs clients.ClientConfigs, dir string, dryRun bool) error {
+	hook, err := smarthooks.Load(dir)
+	if err != nil {
+		return err
+	}
+	values, err := hook.EnvValues()
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(hook)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		var pretty map[string]interface{}
+		json.Unmarshal(body, &pretty)
+		pretty["function"] = fmt.Sprintf("<%d bytes of base64 from %s>", len(hook.Code), hook.Function)
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(pretty); err != nil {
+			return err
+		}
+		logger.Info("Dry run, nothing was deployed", "env_vars", len(values))
+		return nil
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	envs, err := fetchHookEnvs(api)
+	if err != nil {
+		return err
+	}
+	for _, name := range hook.EnvVars {
+		if err := setHookEnv(api, envs, name, values[name]); err != nil {
+			return err
+		}
+	}
+
+	id := ""
+	if hook.ID != 0 {
+		id = strconv.Itoa(hook.ID)
+	} else {
+		data, _, err := api.Do(http.MethodGet, "/api/2/hooks", nil, nil)
+		if err != nil {
+			return fmt.Errorf("unable to list hooks: %s", err)
+		}
+		existing, err := records.FromJSON(data)
+		if err != nil {
+			return fmt.Errorf("unable to read hooks: %s", err)
+		}
+		for _, candidate := range existing {
+			if candidate.Text("type") == hook.Type {
+				id = candidate.Text("id")
+			}
+		}
+	}
+	if id == "" {
+		data, _, err := api.Do(http.MethodPost, "/api/2/hooks", nil, body)
+		if err != nil {
+			return fmt.Errorf("unable to create hook: %s", err)
+		}
+		created, err := records.FromJSON(data)
+		if err != nil || len(created) == 0 {
+			return fmt.Errorf("unable to read created hook: %s", data)
+		}
+		logger.Info("Created hook", "id", created[0].Text("id"), "type", hook.Type)
+		return nil
+	}
+	if _, _, err := api.Do(http.MethodPut, "/api/2/hooks/"+id, nil, body); err != nil {
+		return fmt.Errorf("unable to update hook %s: %s", id, err)
+	}
+	logger.Info("Updated hook", "id", id, "type", hook.Type)
+	return nil
+}
+
+// hookLogs prints the logs of the hook's runs to out, oldest first, and with follow keeps printing those of new runs
+func hookLogs(out io.Writer, clientConfigs clients.ClientConfigs, id string, follow bool, interval time.Duration) error {
+	if _, err := strconv.Atoi(id); err != nil {
+		return fmt.Errorf("invalid hook id %s", id)
+	}
+	if follow {
+		clientConfigs.Cache = nil // polls ask for the same URL, so none is answered from earlier responses
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for {
+		runs, err := fetchHookLogs(api, id)
+		if err != nil {
+			return err
+		}
+		for i := len(runs) - 1; i >= 0; i-- { // the API lists the newest runs first
+			run := runs[i]
+			key := run.Text("request_id") + " " + run.Text("created_at")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			lines, _ := run["logs"].([]interface{})
+			for _, line := range lines {
+				fmt.Fprintf(out, "%s %s %v\n", run.Text("created_at"), run.Text("request_id"), line)
+			}
+		}
+		if !follow {
+			return nil
+		}
+		select {
+		case <-runContext.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+// fetchHookLogs reads the hook's runs with their logs, following the cursor of every page
+func fetchHookLogs(api *clients.OneLoginAPI, id string) ([]records.Record, error) {
+	out := []records.Record{}
+	query := url.Values{}
+	for {
+		data, header, err := api.Do(http.MethodGet, "/api/2/hooks/"+id+"/logs", query, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the logs of hook %s: %s", id, err)
+		}
+		runs, err := records.FromJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the logs of hook %s: %s", id, err)
+		}
+		out = append(out, runs...)
+		cursor := header.Get("After-Cursor")
+		if cursor == "" || len(runs) == 0 {
+			return out, nil
+		}
+		query.Set("cursor", cursor)
+	}
+}
+
+// invokeHook runs the code of the hook in the directory, or of the deployed hook, with node
+func invokeHook(clientConfigs clients.ClientConfigs, reference string, payloadFile string, env []string, node string) error {
+	var code []byte
+	if info, err := os.Stat(reference); err == nil && info.IsDir() {
+		hook, err := smarthooks.Load(reference)
+		if err != nil {
+			return err
+		}
+		if code, err = smarthooks.Function(hook.Code); err != nil {
+			return err
+		}
+	} else {
+		if _, err := strconv.Atoi(reference); err != nil {
+			return fmt.Errorf("%s is neither a hook id nor a directory", reference)
+		}
+		api, err := clients.New(clientConfigs).OneLoginAPI()
+		if err != nil {
+			return err
+		}
+		data, _, err := api.Do(http.MethodGet, "/api/2/hooks/"+reference, nil, nil)
+		if err != nil {
+			return fmt.Errorf("unable to get hook %s: %s", reference, err)
+		}
+		hook, err := records.FromJSON(data)
+		if err != nil {
+			return fmt.Errorf("unable to read hook %s: %s", reference, err)
+		}
+		if code, err = smarthooks.Function(hook[0].Text("function")); err != nil {
+			return err
+		}
+	}
+	var payload []byte
+	var err error
+	if payloadFile == "-" {
+		payload, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		payload, err = ioutil.ReadFile(payloadFile)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to read %s: %s", payloadFile, err)
+	}
+	if !json.Valid(payload) {
+		return fmt.Errorf("%s isn't JSON", payloadFile)
+	}
+	for _, variable := range env {
+		if !strings.Contains(variable, "=") {
+			return fmt.Errorf("invalid --env %s, expected NAME=VALUE", variable)
+		}
+	}
+	return smarthooks.Invoke(node, code, payload, env, os.Stdout, os.Stderr)
+}
+
+// fetchHookEnvs lists the account's hook env vars, which hold their names but never their values
+func fetchHookEnvs(api *clients.OneLoginAPI) ([]records.Record, error) {
+	data, _, err := api.Do(http.MethodGet, "/api/2/hooks/envs", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list hook env vars: %s", err)
+	}
+	envs, err := records.FromJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read hook env vars: %s", err)
+	}
+	return envs, nil
+}
+
+// setHookEnv updates the value of the env var with the name, or creates it
+func setHookEnv(api *clients.OneLoginAPI, envs []records.Record, name string, value string) error {
+	for _, env := range envs {
+		if env.Text("name") == name {
+			body, _ := json.Marshal(map[string]string{"value": value})
+			if _, _, err := api.Do(http.MethodPut, "/api/2/hooks/envs/"+env.Text("id"), nil, body); err != nil {
+				return fmt.Errorf("unable to set hook env var %s: %s", name, err)
+			}
+			logger.Info("Updated hook env var", "name", name)
+			return nil
+		}
+	}
+	body, _ := json.Marshal(map[string]string{"name": name, "value": value})
+	if _, _, err := api.Do(http.MethodPost, "/api/2/hooks/envs", nil, body); err != nil {
+		return fmt.Errorf("unable to create hook env var %s: %s", name, err)
+	}
+	logger.Info("Created hook env var", "name", name)
+	return nil
+}
+
+// listHookEnvs prints the account's hook env vars
+func listHookEnvs(clientConfigs clients.ClientConfigs, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	envs, err := fetchHookEnvs(api)
+	if err != nil {
+		return err
+	}
+	return output.write(envs)
+}
+
+// getHookEnv prints the hook env var with the name
+func getHookEnv(clientConfigs clients.ClientConfigs, name string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	env, err := findHookEnv(api, name)
+	if err != nil {
+		return err
+	}
+	return output.writeOne(env)
+}
+
+// setHookEnvCommand sets the env var given as NAME=VALUE, or NAME with the value from the environment or stdin
+func setHookEnvCommand(clientConfigs clients.ClientConfigs, assignment string, fromEnv bool) error {
+	name, value := assignment, ""
+	if index := strings.Index(assignment, "="); index >= 0 {
+		name, value = assignment[:index], assignment[index+1:]
+	} else if fromEnv {
+		var ok bool
+		if value, ok = os.LookupEnv(name); !ok {
+			return fmt.Errorf("the environment variable %s isn't set", name)
+		}
+	} else {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("unable to read the value of %s from stdin: %s", name, err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	}
+	if name == "" {
+		return fmt.Errorf("invalid env var %s, expected NAME=VALUE or NAME", assignment)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	envs, err := fetchHookEnvs(api)
+	if err != nil {
+		return err
+	}
+	return setHookEnv(api, envs, name, value)
+}
+
+// unsetHookEnv deletes the hook env var with the name
+func unsetHookEnv(clientConfigs clients.ClientConfigs, name string) error {
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	env, err := findHookEnv(api, name)
+	if err != nil {
+		return err
+	}
+	if _, _, err := api.Do(http.MethodDelete, "/api/2/hooks/envs/"+env.Text("id"), nil, nil); err != nil {
+		return fmt.Errorf("unable to delete hook env var %s: %s", name, err)
+	}
+	logger.Info("Deleted hook env var", "name", name)
+	return nil
+}
+
+// findHookEnv finds the hook env var with the name
+func findHookEnv(api *clients.OneLoginAPI, name string) (records.Record, error) {
+	envs, err := fetchHookEnvs(api)
+	if err != nil {
+		return nil, err
+	}
+	for _, env := range envs {
+		if env.Text("name") == name {
+			return env, nil
+		}
+	}
+	return nil, fmt.Errorf("no hook env var is named %s", name)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"context"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHookLogsFollow(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/auth/oauth2/v2/token":
+			w.Write([]byte(`{"access_token":"token","expires_in":36000}`))
+		case "/api/2/hooks/12/logs":
+			polls++
+			if polls == 1 {
+				w.Write([]byte(`[{"request_id":"a","created_at":"2020-01-01T00:00:00Z","logs":["first"]}]`))
+				return
+			}
+			w.Write([]byte(`[{"request_id":"b","created_at":"2020-01-01T00:01:00Z","logs":["second"]},{"request_id":"a","created_at":"2020-01-01T00:00:00Z","logs":["first"]}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	previous := runContext
+	runContext = ctx
+	defer func() { runContext = previous }()
+
+	out := &cancelingWriter{lines: 2, cancel: cancel}
+	configs := clients.ClientConfigs{OneLoginClientID: "id", OneLoginClientSecret: "secret", OneLoginURL: server.URL, Retry: clients.RetryPolicy{MaxAttempts: 1}, Cache: clients.NewResponseCache("", 0)}
+	err := hookLogs(out, configs, "12", true, time.Millisecond)
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-01-01T00:00:00Z a first\n2020-01-01T00:01:00Z b second\n", out.String())
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"text/tabwriter"
+)
+
+func init() {
+	var stateCommand = &cobra.Command{
+		Use:   "state",
+		Short: `Inspect Terraform state.`,
+	}
+
+	var (
+		workspace workspaceFlags
+		asJSON    *bool
+	)
+	var stateListCommand = &cobra.Command{
+		Use:   "list",
+		Short: `List the OneLogin resources managed in Terraform state.`,
+		Long: `Reads the workspace's tfstate and prints every managed OneLogin resource with its remote id and name,
+		along with the serial of the state. Useful for audits and for listing what's already under management.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			format, err := reportFormat(*asJSON)
+			if err != nil {
+				fatal(err)
+			}
+			listing, err := listState(workspace)
+			if err != nil {
+				fatal(err)
+			}
+			if format == records.TableFormat {
+				err = listing.writeTable(os.Stdout)
+			} else {
+				err = records.WriteValue(os.Stdout, format, listing)
+			}
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addWorkspaceFlags(stateListCommand, &workspace)
+	asJSON = stateListCommand.Flags().Bool("json", false, "Print the resources as JSON")
+	stateListCommand.Flags().MarkDeprecated("json", "use --output json")
+	acceptOutput(stateListCommand)
+	stateCommand.AddCommand(stateListCommand)
+
+	var (
+		renderWorkspace workspaceFlags
+		out             string
+		options         stateparser.Options
+	)
+	var stateRenderCommand = &cobra.Command{
+		Use:   "render",
+		Short: `Write the OneLogin resources in Terraform state as configuration.`,
+		Long: `Reads the workspace's tfstate and writes the configuration of every managed OneLogin resource in it, as
+		terraform-import fills in main.tf, e.g. to recover configuration that was lost or never written. A state file
+		given with --state is read, rendered and dropped a resource at a time, so states of hundreds of megabytes
+		take little memory.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := renderState(renderWorkspace, out, options); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addWorkspaceFlags(stateRenderCommand, &renderWorkspace)
+	stateRenderCommand.Flags().StringVar(&out, "out", "", "File to write the configuration to (defaults to stdout)")
+	addRenderFlags(stateRenderCommand, &options)
+	stateCommand.AddCommand(stateRenderCommand)
+	rootCmd.AddCommand(stateCommand)
+}
+
+// listState summarizes the OneLogin resources in the workspace's state as they are read
+func listState(workspace workspaceFlags) (stateListing, error) {
+	r, err := workspace.openState()
+	if err != nil {
+		return stateListing{}, err
+	}
+	defer r.Close()
+	listing := stateListing{Resources: []stateparser.ResourceSummary{}}
+	state, err := stateparser.ParseEach(r, func(resource stateparser.StateResource) error {
+		listing.Resources = append(listing.Resources, stateparser.Summarize(stateparser.State{Resources: []stateparser.StateResource{resource}}, "onelogin")...)
+		return nil
+	})
+	if err != nil {
+		return listing, fmt.Errorf("unable to parse tfstate: %s", err)
+	}
+	listing.Serial = state.Serial
+	return listing, nil
+}
+
+// renderState writes the configuration of the resources in the workspace's state to out, or stdout when empty
+func renderState(workspace workspaceFlags, out string, options stateparser.Options) error {
+	var err error
+	if options.PostProcessors, err = loadPostProcessors(); err != nil {
+		return err
+	}
+	r, err := workspace.openState()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	var renderError *stateparser.RenderError
+	err = streamOutput(out, func(w io.Writer) error {
+		err := stateparser.RenderStateTo(w, r, options)
+		if failed, partlyRendered := err.(*stateparser.RenderError); partlyRendered {
+			renderError, err = failed, nil
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to render tfstate: %s", err)
+	}
+	if renderError != nil {
+		return renderError
+	}
+	return nil
+}
+
+// stateListing is the output of state list
+type stateListing struct {
+	Serial    uint64                        `json:"serial"`
+	Resources []stateparser.ResourceSummary `json:"resources"`
+}
+
+func (l stateListing) writeTable(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ADDRESS\tID\tNAME")
+	for _, resource := range l.Resources {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", resource.Address, resource.ID, resource.Name)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\n%d resources at serial %d\n", len(l.Resources), l.Serial)
+	return err
+}
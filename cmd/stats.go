@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/metrics"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// record each command's duration and API requests in the metrics file, for onelogin stats
+var recordMetrics bool
+
+// the command running and when it started, for the metrics file. Commands can add what they work on, like the
+// resource type terraform-import imports
+var (
+	runCommand string
+	runStarted time.Time
+)
+
+func init() {
+	var (
+		command     string
+		since       time.Duration
+		statsOutput outputFlags
+	)
+	var statsCommand = &cobra.Command{
+		Use:   "stats",
+		Short: `Summarize the commands run with --metrics.`,
+		Long: `Summarizes the runs recorded in ~/.onelogin/metrics.jsonl by command: how many there were and how many
+		failed, how long they took on average, at most and the last time, and the API requests they made. Runs are only
+		recorded with --metrics, or metrics: true in ~/.onelogin/config.yaml, and never leave the machine. --command
+		lists each run of a command instead, e.g. --command "terraform-import onelogin_apps".`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := printStats(command, since, statsOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	statsCommand.Flags().StringVar(&command, "command", "", "List each run of this command instead of summarizing")
+	statsCommand.Flags().DurationVar(&since, "since", 0, "Only count runs started within this long e.g. 168h (all of them by default)")
+	addOutputFlags(statsCommand, &statsOutput, nil, records.TableFormat)
+	rootCmd.AddCommand(statsCommand)
+}
+
+// metricsFile is where runs are recorded, next to the profiles
+func metricsFile() string {
+	return filepath.Join(filepath.Dir(viper.ConfigFileUsed()), "metrics.jsonl")
+}
+
+// startRun notes the command starting, for recordRun
+func startRun(cmd *cobra.Command) {
+	runCommand = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	runStarted = time.Now()
+}
+
+// recordRun appends the run of the command to the metrics file with --metrics. Completions and stats itself aren't
+// recorded. A run that can't be recorded is logged rather than failing the command
+func recordRun(exitCode int) {
+	if !recordMetrics || runStarted.IsZero() || runCommand == "stats" || strings.HasPrefix(runCommand, cobra.ShellCompRequestCmd) {
+		return
+	}
+	run := metrics.NewRun(runCommand, runStarted, apiRequests.Count(), exitCode)
+	if err := metrics.Append(metricsFile(), run); err != nil {
+		logger.Warn("Unable to record the run", "file", metricsFile(), "error", err)
+	}
+}
+
+// printStats prints the runs recorded since the duration, summarized by command or those of the command
+func printStats(command string, since time.Duration, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	runs, err := metrics.Read(metricsFile())
+	if err != nil {
+		return err
+	}
+	kept := []metrics.Run{}
+	for _, run := range runs {
+		if since > 0 && time.Since(run.Started) > since {
+			continue
+		}
+		if command == "" || run.Command == command {
+			kept = append(kept, run)
+		}
+	}
+	if len(runs) == 0 && !recordMetrics {
+		logger.Info("No runs recorded. Pass --metrics, or set metrics: true in config.yaml, to record them")
+	}
+	var list []records.Record
+	if command != "" {
+		list, err = records.From(kept)
+		if len(output.fields) == 0 {
+			output.fields = []string{"started", "seconds", "api_requests", "exit_code"}
+		}
+	} else {
+		list, err = records.From(metrics.Summarize(kept))
+		if len(output.fields) == 0 {
+			output.fields = []string{"command", "runs", "failed", "average_seconds", "max_seconds", "last_seconds", "average_api_requests", "last_api_requests", "last_run"}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return output.write(list)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/usersync"
+	"github.com/spf13/cobra"
+	"net/http"
+	"os"
+)
+
+// fields of the results report of sync users, a row per change
+var syncReportFields = []string{"line", "key", "action", "id", "result", "error"}
+
+// syncUsersFlags are the flags of sync users
+type syncUsersFlags struct {
+	source            string
+	columns           []string
+	key               string
+	updateFields      []string
+	deactivateMissing bool
+	batchSize         int
+	report            string
+	dryRun            bool
+	yes               bool
+}
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var syncCommand = &cobra.Command{
+		Use:   "sync",
+		Short: `Reconcile the account with an outside source of truth.`,
+	}
+
+	var usersFlags syncUsersFlags
+	var syncUsersCommand = &cobra.Command{
+		Use:   "users",
+		Short: `Reconcile users with a CSV export from HR or a directory.`,
+		Long: `Matches the rows of the --source CSV to the account's users by the --key field, ignoring case, and
+		previews the changes reconciling them: users without a row are created, and users whose --update-fields
+		differ from their row are updated. --deactivate-missing also suspends the active users with no row. Columns
+		are user fields as the API names them, renamed with --map like users bulk-import. After confirmation changes
+		are sent --batch-size at a time, and --report writes the outcome of each to a CSV file.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := syncUsers(clientConfigs, usersFlags); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	syncUsersCommand.Flags().StringVar(&usersFlags.source, "source", "", "CSV file of the users there should be, with a header naming their fields")
+	syncUsersCommand.Flags().StringArrayVar(&usersFlags.columns, "map", nil, "Rename a column to a user field e.g. \"E-mail=email\", or drop it with \"Notes=\". Repeat for several")
+	syncUsersCommand.Flags().StringVar(&usersFlags.key, "key", "email", "Field rows and users are matched by e.g. email, username or external_id")
+	syncUsersCommand.Flags().StringSliceVar(&usersFlags.updateFields, "update-fields", nil, "Comma separated fields to update, instead of every column")
+	syncUsersCommand.Flags().BoolVar(&usersFlags.deactivateMissing, "deactivate-missing", false, "Suspend the active users with no row")
+	syncUsersCommand.Flags().IntVar(&usersFlags.batchSize, "batch-size", bulk.DefaultBatchSize, "Changes sent at once")
+	syncUsersCommand.Flags().StringVar(&usersFlags.report, "report", "", "Write the outcome of every change to this CSV file")
+	syncUsersCommand.Flags().BoolVar(&usersFlags.dryRun, "dry-run", false, "Print the changes without making them")
+	syncUsersCommand.Flags().BoolVarP(&usersFlags.yes, "yes", "y", false, "Make the changes without asking for confirmation")
+	syncUsersCommand.MarkFlagRequired("source")
+
+	syncCommand.AddCommand(syncUsersCommand)
+	rootCmd.AddCommand(syncCommand)
+}
+
+// syncUsers previews the changes reconciling the account's users with the rows of the source, then makes them
+func syncUsers(clientConfigs clients.ClientConfigs, flags syncUsersFlags) error {
+	rows, err := readUserRows(flags.source, flags.columns)
+	if err != nil {
+		return err
+	}
+	users, err := fetchUsers(clientConfigs, nil)
+	if err != nil {
+		return err
+	}
+	changes, err := usersync.Plan(rows, users, usersync.Options{
+		Key:               flags.key,
+		UpdateFields:      flags.updateFields,
+		DeactivateMissing: flags.deactivateMissing,
+	})
+	if err != nil {
+		return err
+	}
+	usersync.WritePreview(os.Stdout, changes)
+	if len(changes) == 0 || flags.dryRun {
+		return nil
+	}
+	if proceed, err := confirm(flags.yes, "Do you want to make these changes?"); !proceed {
+		return err
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, len(changes))
+	jobs := make([]bulk.Job, len(changes))
+	for i, change := range changes {
+		i, change := i, change
+		jobs[i] = func() (http.Header, error) {
+			user, err := importedUser(change.Body)
+			if err != nil {
+				return nil, err
+			}
+			body, err := json.Marshal(user)
+			if err != nil {
+				return nil, err
+			}
+			if change.Action != usersync.Create {
+				ids[i] = change.ID
+				_, header, err := api.Do(http.MethodPut, "/api/2/users/"+change.ID, nil, body)
+				return header, err
+			}
+			data, header, err := api.Do(http.MethodPost, "/api/2/users", nil, body)
+			if err != nil {
+				return header, err
+			}
+			if created, err := records.FromJSON(data); err == nil && len(created) > 0 {
+				ids[i] = created[0].Text("id")
+			}
+			return header, nil
+		}
+	}
+	errs := runBatches(jobs, flags.batchSize)
+
+	results := make([]records.Record, len(changes))
+	counts, failed := map[string]int{}, 0
+	for i, change := range changes {
+		results[i] = records.Record{"key": change.Key, "action": change.Action, "id": ids[i], "result": "ok"}
+		if change.Line > 0 {
+			results[i]["line"] = change.Line
+		}
+		if errs[i] != nil {
+			failed++
+			results[i]["result"], results[i]["error"] = "failed", errs[i].Error()
+			logger.Error("Unable to "+change.Action+" user", "user", change.Key, "error", errs[i])
+			continue
+		}
+		counts[change.Action]++
+	}
+	logger.Info("Synced users", "created", counts[usersync.Create], "updated", counts[usersync.Update],
+		"deactivated", counts[usersync.Deactivate], "failed", failed)
+	if flags.report != "" {
+		out, err := os.Create(flags.report)
+		if err != nil {
+			return fmt.Errorf("unable to create %s: %s", flags.report, err)
+		}
+		defer out.Close()
+		if err := records.Write(out, records.CSVFormat, results, syncReportFields); err != nil {
+			return fmt.Errorf("unable to write %s: %s", flags.report, err)
+		}
+		logger.Info("Wrote results", "file", flags.report)
+	}
+	if failed > 0 {
+		return partial(fmt.Errorf("%d of %d changes failed", failed, len(changes)), failed, len(changes))
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/terraform/cdktf"
+	"github.com/onelogin/onelogin/terraform/import"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	var (
+		searchID      *string
+		idsFile       *string
+		noAnnotations *bool
+		out           *string
+		format        *string
+		clientConfigs clients.ClientConfigs
+		options       stateparser.Options
+	)
+	var tfExportCommand = &cobra.Command{
+		Use:   "terraform-export <resource> [resource...]",
+		Short: `Write remote resources as Terraform configuration without importing them.`,
+		Long: `Collects resources from the remote and writes them as HCL, the same way terraform-import
+		fills in main.tf, without running terraform or touching any .tfstate. Useful to review or diff the
+		configuration of an account. Takes the same resource types as terraform-import.`,
+		Args: cobra.MinimumNArgs(1),
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			ids, err := searchIDs(*searchID, *idsFile)
+			if err != nil {
+				fatal(err)
+			}
+			if options.PostProcessors, err = loadPostProcessors(); err != nil {
+				fatal(err)
+			}
+			if err := tfExport(args, clientConfigs, ids, *out, *format, !*noAnnotations, options); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	searchID = tfExportCommand.Flags().String("id", "", "Export one resource by id")
+	idsFile = tfExportCommand.Flags().String("ids-file", "", "Export the resources with the ids in this file, one per line or as a JSON array, or - for stdin")
+	out = tfExportCommand.Flags().String("out", "", "File to write the configuration to (defaults to stdout)")
+	noAnnotations = tfExportCommand.Flags().Bool("no-annotations", false, "Don't write a comment above each resource saying when and where from it was exported")
+	format = tfExportCommand.Flags().String("format", "hcl", "Output format: hcl, tf-json for terraform's JSON syntax (.tf.json), cdktf-ts for a CDK for Terraform TypeScript stack, cdktf-python for a Python one, or pulumi for a pulumi import file")
+	addRenderFlags(tfExportCommand, &options)
+	cacheResponses(tfExportCommand)
+	rootCmd.AddCommand(tfExportCommand)
+}
+
+// cdktf languages by the --format naming them
+var cdktfFormats = map[string]cdktf.Language{
+	"cdktf-ts":     cdktf.TypeScript,
+	"cdktf-python": cdktf.Python,
+}
+
+// tfExport writes the resources of the types named in args to out, or stdout when out is empty, in the format. Annotated
+// resources are preceded by a comment saying when and where from they were exported
+func tfExport(args []string, clientConfigs clients.ClientConfigs, searchIDs []string, out string, format string, annotated bool, options stateparser.Options) error {
+	language, isCDKTF := cdktfFormats[format]
+	if format != "hcl" && format != "tf-json" && format != "pulumi" && !isCDKTF {
+		return fmt.Errorf("unknown format %s, expected hcl, tf-json, cdktf-ts, cdktf-python, or pulumi", format)
+	}
+	importables := tfimportables.New(clients.New(clientConfigs))
+	resourceDefinitions := []tfimportables.ResourceDefinition{}
+	for _, arg := range args {
+		importable, err := importables.GetImportable(strings.ToLower(arg))
+		if err != nil {
+			return err
+		}
+		err = streamIDs(importable, searchIDs, func(definition tfimportables.ResourceDefinition) error {
+			resourceDefinitions = append(resourceDefinitions, definition)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if format == "pulumi" {
+		var manifest bytes.Buffer
+		if err := tfimport.WritePulumiManifest(resourceDefinitions, &manifest); err != nil {
+			return fmt.Errorf("unable to write pulumi import file: %s", err)
+		}
+		if err := writeOutput(out, manifest.Bytes()); err != nil {
+			return fmt.Errorf("problem writing pulumi import file: %s", err)
+		}
+		logger.Info("Exported resources for pulumi import --file", "count", len(resourceDefinitions))
+		return nil
+	}
+	for i := range resourceDefinitions {
+		resourceDefinitions[i].Name = tfimport.ImportName(resourceDefinitions[i], i)
+	}
+	if annotated {
+		addresses := make([]string, len(resourceDefinitions))
+		for i, resourceDefinition := range resourceDefinitions {
+			addresses[i] = resourceDefinition.Type + "." + resourceDefinition.Name
+		}
+		options.Annotations = annotate("exported", resourceDefinitions, addresses, map[string]string{"": clientConfigs.OneLoginURL})
+	}
+
+	state, err := stateparser.FromRemote(resourceDefinitions)
+	if err != nil {
+		return err
+	}
+	var renderError *stateparser.RenderError
+	if format == "hcl" {
+		// written a block at a time, as large accounts make for more configuration than is worth holding at once
+		err = streamOutput(out, func(w io.Writer) error {
+			err := stateparser.RenderTo(w, state, options)
+			if failed, partlyRendered := err.(*stateparser.RenderError); partlyRendered {
+				renderError, err = failed, nil
+			}
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("problem writing configuration: %s", err)
+		}
+	} else {
+		content, err := stateparser.Render(state, options)
+		if failed, partlyRendered := err.(*stateparser.RenderError); partlyRendered {
+			renderError, err = failed, nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to render configuration: %s", err)
+		}
+		if format == "tf-json" {
+			if content, err = stateparser.HCLToJSON(content, "main.tf"); err != nil {
+				return fmt.Errorf("unable to convert configuration to JSON: %s", err)
+			}
+		} else if content, err = cdktf.Convert(content, "main.tf", language); err != nil {
+			return fmt.Errorf("unable to convert configuration to cdktf: %s", err)
+		}
+		if err := writeOutput(out, content); err != nil {
+			return fmt.Errorf("problem writing configuration: %s", err)
+		}
+	}
+	if renderError != nil {
+		return partial(renderError, len(renderError.Resources), len(resourceDefinitions))
+	}
+	logger.Info("Exported resources", "count", len(resourceDefinitions))
+	return nil
+}
+
+// writes content to the file, replacing what was there, or to stdout when filename is empty
+func writeOutput(filename string, content []byte) error {
+	return streamOutput(filename, func(w io.Writer) error {
+		_, err := w.Write(content)
+		return err
+	})
+}
+
+// streamOutput hands write the file to write to, replacing what was there, or stdout when filename is empty
+func streamOutput(filename string, write func(w io.Writer) error) error {
+	if filename == "" {
+		return write(os.Stdout)
+	}
+	file, err := os.OpenFile(filepath.Clean(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
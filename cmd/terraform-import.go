@@ -1,29 +1,39 @@
 package cmd
 
 import (
-	"bufio"
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"github.com/onelogin/onelogin/clients"
-	"github.com/onelogin/onelogin/profiles"
+	"github.com/onelogin/onelogin/diffs"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/progress"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/terraform/exec"
 	"github.com/onelogin/onelogin/terraform/import"
 	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
 	"github.com/onelogin/onelogin/terraform/state_parser"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"io"
 	"io/ioutil"
-	"log"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 func init() {
 	var (
 		autoApprove   *bool
 		searchID      *string
+		idsFile       *string
+		runnerName    *string
+		binary        *string
+		workingDir    *string
+		tenantNames   *[]string
+		reportFile    *string
 		clientConfigs clients.ClientConfigs
+		options       tfImportOptions
 	)
 	var tfImportCommand = &cobra.Command{
 		Use:   "terraform-import",
@@ -37,124 +47,905 @@ func init() {
 			onelogin_user_mappings => onelogin user mappings
 			onelogin_users         => onelogin users
 			aws_iam_user           => aws users`,
-		Args: cobra.MinimumNArgs(1),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: firstArg(completeList(importableNames)),
 		PreRun: func(cmd *cobra.Command, args []string) {
-			configFile, err := os.OpenFile(viper.ConfigFileUsed(), os.O_RDWR, 0600)
+			if len(*tenantNames) > 0 {
+				options.Tenants = loadTenants(*tenantNames)
+				return
+			}
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			runner, err := tfexec.New(*runnerName, *binary, *workingDir)
 			if err != nil {
-				configFile.Close()
-				log.Println("Unable to open profiles file. Falling back to Environment Variables", err)
+				fatal(err)
+			}
+			if version, err := runner.Version(); err == nil {
+				logger.Info("Using terraform", "version", version)
 			}
-			profileService := profiles.ProfileService{
-				Repository: profiles.FileRepository{
-					StorageMedia: configFile,
-				},
+			runCommand += " " + strings.ToLower(args[0])
+			if options.Render.PostProcessors, err = loadPostProcessors(); err != nil {
+				fatal(err)
 			}
-			profile := profileService.GetActive()
-			clientConfigs = clients.ClientConfigs{
-				AwsRegion: os.Getenv("AWS_REGION"),
+			options.AutoApprove = *autoApprove
+			if options.SearchIDs, err = searchIDs(*searchID, *idsFile); err != nil {
+				fatal(err)
 			}
-			if profile == nil {
-				fmt.Println("No active profile detected. Authenticating with environment variables")
-				clientConfigs.OneLoginClientID = os.Getenv("ONELOGIN_CLIENT_ID")
-				clientConfigs.OneLoginClientSecret = os.Getenv("ONELOGIN_CLIENT_SECRET")
-				clientConfigs.OneLoginURL = os.Getenv("ONELOGIN_OAPI_URL")
+			started, summary := time.Now(), tfimport.NewSummary(strings.ToLower(args[0]), nil, nil, nil)
+			if options.RefreshOnly {
+				err = tfRefresh(args, clientConfigs, runner, options, &summary)
 			} else {
-				fmt.Println("Using profile", (*profile).Name)
-				clientConfigs.OneLoginClientID = (*profile).ClientID
-				clientConfigs.OneLoginClientSecret = (*profile).ClientSecret
-				clientConfigs.OneLoginURL = fmt.Sprintf("https://api.%s.onelogin.com", (*profile).Region)
+				err = tfImport(args, clientConfigs, runner, options, &summary)
+			}
+			if *reportFile != "" {
+				if reportErr := writeImportReport(*reportFile, summary, time.Since(started), err); reportErr != nil {
+					logger.Error("Unable to write the import report", "file", *reportFile, "error", reportErr)
+				}
+			}
+			if err != nil {
+				fatal(err)
 			}
-		},
-		Run: func(cmd *cobra.Command, args []string) {
-			tfImport(args, clientConfigs, *autoApprove, searchID)
 		},
 	}
 	autoApprove = tfImportCommand.Flags().Bool("auto_approve", false, "Skip confirmation of resource import")
 	searchID = tfImportCommand.Flags().String("id", "", "Import one resource by id")
+	idsFile = tfImportCommand.Flags().String("ids-file", "", "Import the resources with the ids in this file, one per line or as a JSON array, or - for stdin")
+	runnerName = tfImportCommand.Flags().String("runner", "terraform", "Tool driving the workspace (terraform or terragrunt)")
+	binary = tfImportCommand.Flags().String("terraform-binary", "", "Path to the terraform compatible binary e.g. /usr/local/bin/tofu (defaults to terraform, or tofu if terraform is not on PATH)")
+	tfImportCommand.Flags().StringVar(binary, "binary", "", "Alias for --terraform-binary")
+	workingDir = tfImportCommand.Flags().String("working-dir", "", "Directory holding main.tf where terraform commands are run (defaults to the current directory)")
+	tfImportCommand.Flags().BoolVar(&options.DirectState, "direct-state", false, "EXPERIMENTAL: write state from the remote data with 'state push' instead of running terraform import per resource")
+	tfImportCommand.Flags().StringVar(&options.ImportScript, "emit-import-script", "", "Write the terraform import commands to this file instead of running them (.json for a JSON plan, .ps1 for a PowerShell script, otherwise a shell script)")
+	tfImportCommand.Flags().DurationVar(&options.RetryPolicy.Interval, "import-interval", 0, "Pause between terraform import calls e.g. 500ms. Also the base of the backoff when rate limited")
+	tfImportCommand.Flags().IntVar(&options.RetryPolicy.MaxRetries, "max-retries", 3, "Times to retry an import that failed because of rate limiting")
+	tfImportCommand.Flags().BoolVar(&options.Plan, "plan", false, "Run a plan after writing main.tf and report whether it is drift free")
+	tfImportCommand.Flags().BoolVar(&options.Verify, "verify", false, "Like --plan but fail when the plan is not empty")
+	addRenderFlags(tfImportCommand, &options.Render)
+	tfImportCommand.Flags().StringSliceVar(&options.DataSources, "as-data-sources", []string{}, "Resource types to declare as data sources looking them up by id instead of importing them e.g. onelogin_roles")
+	tenantNames = tfImportCommand.Flags().StringSlice("profiles", []string{}, "Import from the OneLogin account of each of these profiles e.g. prod,emea, through a provider configuration aliased by the profile name")
+	tfImportCommand.RegisterFlagCompletionFunc("profiles", completeEach(profileNames))
+	reportFile = tfImportCommand.Flags().String("report-file", "", "Write a summary of the import to this file, as JSON when it ends in .json and Markdown otherwise e.g. import.md to attach to a pull request")
+	tfImportCommand.Flags().BoolVar(&options.SkipInvalid, "skip-invalid", false, "Leave out resources that don't fit the provider schema or the values the API accepts, which would fail to import or plan, instead of only warning about them")
+	tfImportCommand.Flags().IntVar(&options.ChunkSize, "chunk-size", 0, "Import this many resources at a time, writing main.tf and a checkpoint after each batch so an interrupted import resumes from the last batch when run again")
+	tfImportCommand.Flags().BoolVar(&options.RefreshOnly, "refresh-only", false, "Rewrite the configuration of the resources already managed in main.tf from the remote, without importing anything or touching state")
+	tfImportCommand.Flags().BoolVar(&options.NoAnnotations, "no-annotations", false, "Don't write a comment above each imported resource saying when and where from it was imported")
+	tfImportCommand.Flags().StringVar(&options.OnDuplicate, "on-duplicate", tfimport.DuplicatesIndex, "How to handle remote resources of a type sharing a name: index to tell them apart by the index in their addresses, suffix-id to add their ids to their names, skip to import none of them, or rename to ask for names")
+	tfImportCommand.RegisterFlagCompletionFunc("on-duplicate", completeList(func() ([]string, error) { return tfimport.DuplicateStrategies, nil }))
+	tfImportCommand.Flags().BoolVar(&options.Variablize, "variablize", false, "Move secrets and environment specific values into variables.tf and terraform.tfvars.example, referenced as var. in main.tf")
+	cacheResponses(tfImportCommand)
 	rootCmd.AddCommand(tfImportCommand)
 }
 
-func tfImport(args []string, clientConfigs clients.ClientConfigs, autoApprove bool, searchID *string) {
-	planFile, err := os.OpenFile(filepath.Join("main.tf"), os.O_RDWR|os.O_CREATE, 0600)
-	if err != nil {
-		log.Fatalln("Unable to open main.tf ", err)
+// tfImportOptions are the knobs of terraform-import that change how resources get into state
+type tfImportOptions struct {
+	AutoApprove   bool
+	SearchIDs     []string // nil imports every resource of the type
+	DirectState   bool
+	ImportScript  string
+	RetryPolicy   tfexec.RetryPolicy
+	Plan          bool
+	Verify        bool
+	Variablize    bool
+	SkipInvalid   bool
+	ChunkSize     int // 0 imports every resource in one go
+	RefreshOnly   bool
+	NoAnnotations bool
+	OnDuplicate   string // how remote resources of a type sharing a name are handled, one of tfimport.DuplicateStrategies
+	DataSources   []string
+	Render        stateparser.Options
+	Tenants       []tenantImport // when given, resources are imported from each tenant instead of the configured account
+}
+
+// tenantImport is a OneLogin account imported with --profiles and the credentials to reach it
+type tenantImport struct {
+	tfimport.Tenant
+	clientConfigs clients.ClientConfigs
+}
+
+// loadTenants reads the profiles named with --profiles as tenants aliased by the profile names
+func loadTenants(names []string) []tenantImport {
+	tenants := make([]tenantImport, len(names))
+	for i, profile := range loadProfiles(names...) {
+		tenant, err := tfimport.NewTenant(profile.Name, profile.APIURL(), profile.ClientID, profile.ClientSecret)
+		if err != nil {
+			fatal(err)
+		}
+		tenants[i] = tenantImport{Tenant: tenant, clientConfigs: profileClientConfigs(profile)}
 	}
+	return tenants
+}
 
-	clientList := clients.New(clientConfigs)
-	importables := tfimportables.New(clientList)
-	importable := importables.GetImportable(strings.ToLower(args[0]))
+// searchIDs combines --id and --ids-file into the ids to fetch, nil for every resource
+func searchIDs(id string, idsFile string) ([]string, error) {
+	if idsFile == "" {
+		if id == "" {
+			return nil, nil
+		}
+		return []string{id}, nil
+	}
+	in := io.Reader(os.Stdin)
+	if idsFile != "-" {
+		file, err := os.Open(filepath.Clean(idsFile))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %s", idsFile, err)
+		}
+		defer file.Close()
+		in = file
+	}
+	ids, err := tfimport.ReadIDs(in)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %s", idsFile, err)
+	}
+	if id != "" {
+		listed := false
+		for _, fileID := range ids {
+			listed = listed || fileID == id
+		}
+		if !listed {
+			ids = append([]string{id}, ids...)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%s holds no ids", idsFile)
+	}
+	return ids, nil
+}
 
-	resourceDefinitionsFromRemote := importable.ImportFromRemote(searchID)
-	newResourceDefinitions, newProviderDefinitions := tfimport.FilterExistingDefinitions(planFile, resourceDefinitionsFromRemote)
-	if len(newResourceDefinitions) == 0 {
-		fmt.Println("No new resources to import from remote")
-		planFile.Close()
-		os.Exit(0)
+// streamIDs streams the resources with the ids from the importable, or all of them when ids is nil
+func streamIDs(importable tfimportables.Importable, ids []string, handle func(tfimportables.ResourceDefinition) error) error {
+	if ids == nil {
+		return tfimportables.StreamFromRemoteContext(runContext, importable, nil, handle)
+	}
+	for i := range ids {
+		if err := tfimportables.StreamFromRemoteContext(runContext, importable, &ids[i], handle); err != nil {
+			return fmt.Errorf("unable to fetch %s: %s", ids[i], err)
+		}
 	}
+	return nil
+}
 
-	if autoApprove == false {
-		fmt.Printf("This will import %d resources. Do you want to continue? (y/n): ", len(newResourceDefinitions))
-		input := bufio.NewScanner(os.Stdin)
-		input.Scan()
-		text := strings.ToLower(input.Text())
-		if text != "y" && text != "yes" {
-			fmt.Printf("User aborted operation!")
-			if err := planFile.Close(); err != nil {
-				fmt.Println("Problem writing file", err)
-			}
-			os.Exit(0)
+// fetchRemote collects the resources of the type from the configured account, or from every tenant with each
+// resource assigned to its tenant's provider configuration. Importables that stream hand their resources over page
+// by page, so only the decoded resources are held rather than every page of the response too
+func fetchRemote(resourceType string, clientConfigs clients.ClientConfigs, options tfImportOptions) ([]tfimportables.ResourceDefinition, error) {
+	out := []tfimportables.ResourceDefinition{}
+	if len(options.Tenants) == 0 {
+		importable, err := tfimportables.New(clients.New(clientConfigs)).GetImportable(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		err = streamIDs(importable, options.SearchIDs, func(definition tfimportables.ResourceDefinition) error {
+			out = append(out, definition)
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
+		return out, nil
 	}
+	if !strings.HasPrefix(resourceType, "onelogin_") {
+		return nil, fmt.Errorf("--profiles only applies to onelogin resources, not %s", resourceType)
+	}
+	for _, tenant := range options.Tenants {
+		importable, err := tfimportables.New(clients.New(tenant.clientConfigs)).GetImportable(resourceType)
+		if err != nil {
+			return nil, err
+		}
+		err = streamIDs(importable, options.SearchIDs, func(definition tfimportables.ResourceDefinition) error {
+			out = append(out, tenant.Tag([]tfimportables.ResourceDefinition{definition})...)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to import from %s: %s", tenant.Alias, err)
+		}
+	}
+	return out, nil
+}
+
+// tfImport imports the resources of the type named in args and writes their configuration to main.tf.
+// Errors are returned to the command, which decides how to exit
+func tfImport(args []string, clientConfigs clients.ClientConfigs, runner tfexec.Runner, options tfImportOptions, summary *tfimport.Summary) error {
+	resourceType := strings.ToLower(args[0])
+	planFile, err := os.OpenFile(filepath.Join(runner.WorkingDir, "main.tf"), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open main.tf: %s", err)
+	}
+	defer planFile.Close()
 
-	if err := tfimport.WriteHCLDefinitionHeaders(newResourceDefinitions, newProviderDefinitions, planFile); err != nil {
-		planFile.Close()
-		log.Fatal("Problem creating import file", err)
+	checkpoint, err := resumeCheckpoint(runner.WorkingDir, resourceType, planFile, options)
+	if err != nil {
+		return err
+	}
+	if checkpoint != nil && options.ChunkSize == 0 {
+		options.ChunkSize = checkpoint.ChunkSize
 	}
 
-	log.Println("Initializing Terraform with 'terraform init'...")
-	// #nosec G204
-	if err := exec.Command("terraform", "init").Run(); err != nil {
+	resourceDefinitionsFromRemote, err := fetchRemote(resourceType, clientConfigs, options)
+	if err != nil {
+		return err
+	}
+	resourceDefinitionsFromRemote, duplicatesSkipped, err := resolveDuplicates(resourceDefinitionsFromRemote, options.OnDuplicate)
+	if err != nil {
+		return err
+	}
+	existingDefinitions, err := tfimport.ReadDefinitionHeaders(runner.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("unable to read existing configuration: %s", err)
+	}
+	resourceDefinitionsFromRemote, dataSourceDefinitions := tfimport.SplitDataSources(existingDefinitions, resourceDefinitionsFromRemote, options.DataSources)
+	newResourceDefinitions, newProviderDefinitions := tfimport.FilterExistingDefinitions(existingDefinitions, resourceDefinitionsFromRemote)
+	if checkpoint != nil {
+		newResourceDefinitions = checkpoint.Remaining(newResourceDefinitions)
+	}
+	addressMap, err := tfimport.ReadAddressMap(runner.WorkingDir)
+	if err != nil {
+		return fmt.Errorf("unable to read the address map: %s", err)
+	}
+	newResourceDefinitions, mapped := addressMap.FilterMapped(existingDefinitions, newResourceDefinitions)
+	if len(mapped) > 0 {
+		logger.Info("Skipping resources imported before, even if renamed since", "count", len(mapped), "map", tfimport.AddressMapFile)
+	}
+	*summary = tfimport.NewSummary(resourceType, resourceDefinitionsFromRemote, newResourceDefinitions, dataSourceDefinitions)
+	for _, resourceDefinition := range duplicatesSkipped {
+		summary.Fail(resourceDefinition.Type+"."+resourceDefinition.Name, fmt.Errorf("skipped as other resources share its name, id %s", resourceDefinition.ImportID))
+	}
+	if len(newResourceDefinitions) == 0 && len(dataSourceDefinitions) == 0 {
+		logger.Info("No new resources to import from remote")
+		return removeCheckpoint(runner.WorkingDir, checkpoint)
+	}
+
+	question := fmt.Sprintf("This will import %d resources.", len(newResourceDefinitions))
+	if len(dataSourceDefinitions) > 0 {
+		question = fmt.Sprintf("This will import %d resources and declare %d data sources.", len(newResourceDefinitions), len(dataSourceDefinitions))
+	}
+	if proceed, err := confirm(options.AutoApprove, question+" Do you want to continue?"); !proceed {
+		return err
+	}
+
+	planFile.Seek(0, io.SeekEnd)
+	tenants := make([]tfimport.Tenant, len(options.Tenants))
+	for i, tenant := range options.Tenants {
+		tenants[i] = tenant.Tenant
+		runner.Env = append(runner.Env, tenant.Env()...) // credentials reach the aliased providers as variables
+	}
+	if err := tfimport.WriteTenantProviders(existingDefinitions, tenants, planFile); err != nil {
+		return fmt.Errorf("problem writing tenant providers: %s", err)
+	}
+	if err := tfimport.WriteHCLDefinitionHeaders(nil, newProviderDefinitions, planFile); err != nil {
+		return fmt.Errorf("problem creating import file: %s", err)
+	}
+
+	if options.ImportScript != "" {
+		if err := tfimport.WriteHCLDefinitionHeaders(newResourceDefinitions, nil, planFile); err != nil {
+			return fmt.Errorf("problem creating import file: %s", err)
+		}
 		if err := planFile.Close(); err != nil {
-			log.Fatal("Problem writing to main.tf", err)
+			return fmt.Errorf("problem writing to main.tf: %s", err)
 		}
-		log.Fatal("Problem executing terraform init", err)
+		if err := emitImportScript(options.ImportScript, runner, newResourceDefinitions); err != nil {
+			return fmt.Errorf("problem writing import script: %s", err)
+		}
+		logger.Info("Wrote import commands", "count", len(newResourceDefinitions), "path", options.ImportScript)
+		for _, tenant := range tenants {
+			logger.Info("Set the tenant's credential variables before running it", "client_id", "TF_VAR_"+tenant.ClientIDVariable(), "client_secret", "TF_VAR_"+tenant.ClientSecretVariable())
+		}
+		if len(dataSourceDefinitions) > 0 {
+			logger.Info("Skipped data sources. They are written to main.tf when importing", "count", len(dataSourceDefinitions))
+		}
+		return nil
+	}
+
+	logger.Info("Initializing Terraform...", "command", runner.Binary+" init")
+	if err := runner.Init(); err != nil {
+		return fmt.Errorf("problem executing terraform init: %s", err)
+	}
+	schemas, schemaErr := loadSchemas(runner)
+	if schemaErr != nil && options.DirectState {
+		return fmt.Errorf("problem writing state directly: %s", schemaErr)
+	} else if schemaErr != nil {
+		logger.Warn("Unable to read provider schemas, falling back to built in resource shapes", "error", schemaErr)
+	}
+
+	if newResourceDefinitions, err = validateRemote(newResourceDefinitions, schemas, options.SkipInvalid, summary); err != nil {
+		return err
+	}
+	if len(newResourceDefinitions) == 0 && len(dataSourceDefinitions) == 0 {
+		logger.Info("No valid resources left to import")
+		return removeCheckpoint(runner.WorkingDir, checkpoint)
+	}
+
+	first := 0
+	if checkpoint == nil && options.ChunkSize > 0 {
+		checkpoint = &tfimport.Checkpoint{Resource: resourceType, ChunkSize: options.ChunkSize}
+	} else if checkpoint != nil {
+		first = checkpoint.Next
+		if err := forgetPending(runner, checkpoint); err != nil {
+			return fmt.Errorf("unable to remove the resources of the interrupted chunk from state: %s", err)
+		}
+	}
+	chunks := tfimport.Chunks(newResourceDefinitions, options.ChunkSize, first)
+	if len(chunks) == 0 {
+		logger.Info("Nothing to import, declaring data sources only")
+		chunks = []tfimport.Chunk{{First: first}}
+	}
+
+	baseURLs := map[string]string{"": clientConfigs.OneLoginURL}
+	for _, tenant := range tenants {
+		baseURLs[tenant.Alias] = tenant.URL
+	}
+	renderOptions := options.Render
+	renderOptions.Schemas = schemas
+	failed := &stateparser.RenderError{}
+	for i, chunk := range chunks {
+		if len(chunks) > 1 {
+			logger.Info("Importing chunk", "chunk", i+1, "chunks", len(chunks), "resources", len(chunk.Resources))
+		}
+		chunkOptions := renderOptions
+		if i == 0 {
+			chunkOptions.DataSources = dataSourceDefinitions
+		}
+		if !options.NoAnnotations {
+			chunkOptions.Annotations = annotate("imported", chunk.Resources, chunk.Addresses(), baseURLs)
+		}
+		renderError, err := importChunk(runner, planFile, chunk, chunkOptions, options, checkpoint, addressMap, summary)
+		if err != nil {
+			return err
+		}
+		if renderError != nil {
+			failed.Resources = append(failed.Resources, renderError.Resources...)
+		}
+	}
+	if err := planFile.Close(); err != nil {
+		return fmt.Errorf("problem writing main.tf: %s", err)
+	}
+	if err := removeCheckpoint(runner.WorkingDir, checkpoint); err != nil {
+		return err
 	}
 
-	for i, resourceDefinition := range newResourceDefinitions {
-		resourceName := fmt.Sprintf("%s._%s_%d", resourceDefinition.Type, resourceDefinition.Name, i+1)
-		id := resourceDefinition.ImportID
-		// #nosec G204
-		cmd := exec.Command("terraform", "import", resourceName, id)
-		log.Printf("Importing resource %d", i+1)
-		if err := cmd.Run(); err != nil {
-			log.Fatal("Problem executing terraform import", cmd.Args, err)
+	if len(failed.Resources) > 0 {
+		for _, resource := range failed.Resources {
+			summary.Fail(resource.Address, resource.Err)
+		}
+		return partial(failed, len(failed.Resources), len(newResourceDefinitions))
+	}
+
+	if options.Plan || options.Verify {
+		return verifyPlan(runner, options.Verify)
+	}
+	return nil
+}
+
+// tfRefresh rewrites the blocks of the resources of the type named in args that state already manages from their data
+// on the remote, e.g. to fill in attributes added to the provider schema since they were imported. State isn't touched,
+// and resources not managed yet are left for an import
+func tfRefresh(args []string, clientConfigs clients.ClientConfigs, runner tfexec.Runner, options tfImportOptions, summary *tfimport.Summary) error {
+	if options.DirectState || options.ImportScript != "" || options.ChunkSize > 0 {
+		return fmt.Errorf("--refresh-only doesn't import, so it can't be combined with --direct-state, --emit-import-script or --chunk-size")
+	}
+	resourceType := strings.ToLower(args[0])
+	path := filepath.Join(runner.WorkingDir, "main.tf")
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to read main.tf: %s", err)
+	}
+	data, err := runner.StatePull()
+	if err != nil {
+		return fmt.Errorf("unable to read tfstate: %s", err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		logger.Info("No managed resources to refresh, state is empty")
+		return nil
+	}
+	state, err := stateparser.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("unable to translate tfstate in memory: %s", err)
+	}
+
+	resourceDefinitions, err := fetchRemote(resourceType, clientConfigs, options)
+	if err != nil {
+		return err
+	}
+	refreshed, missing, err := stateparser.Refreshed(state, resourceDefinitions)
+	if err != nil {
+		return err
+	}
+	if options.SearchIDs == nil {
+		for _, address := range missing {
+			logger.Warn("Managed resource is no longer on the remote, leaving its configuration as it is", "resource", address)
+		}
+	}
+	headers, err := tfimport.ParseDefinitionHeaders(path, src)
+	if err != nil {
+		return fmt.Errorf("unable to read main.tf: %s", err)
+	}
+	addresses := []string{}
+	for _, resource := range refreshed.Resources {
+		address := resource.Type + "." + resource.Name
+		if headers.Resources[address] == 0 {
+			logger.Warn("Managed resource is configured outside main.tf, leaving it as it is", "resource", address)
+			continue
+		}
+		addresses = append(addresses, address)
+	}
+	if len(addresses) == 0 {
+		logger.Info("No managed resources to refresh in main.tf")
+		return nil
+	}
+	if proceed, err := confirm(options.AutoApprove, fmt.Sprintf("This will rewrite the configuration of %d resources in main.tf. Do you want to continue?", len(addresses))); !proceed {
+		return err
+	}
+
+	schemas, err := loadSchemas(runner)
+	if err != nil {
+		logger.Warn("Unable to read provider schemas, falling back to built in resource shapes", "error", err)
+	}
+	renderOptions := options.Render
+	renderOptions.Schemas = schemas
+	if options.Variablize {
+		renderOptions.Variables = &stateparser.Variables{}
+	}
+	buffer, err := stateparser.UpdateHCL(src, path, refreshed, renderOptions, addresses)
+	renderError, partlyRendered := err.(*stateparser.RenderError)
+	if err != nil && !partlyRendered {
+		return fmt.Errorf("unable to update main.tf: %s", err)
+	}
+	if err := ioutil.WriteFile(path, buffer, 0600); err != nil {
+		return fmt.Errorf("problem writing main.tf: %s", err)
+	}
+	count := len(addresses)
+	if partlyRendered {
+		count -= len(renderError.Resources)
+	}
+	logger.Info("Refreshed the configuration of managed resources", "count", count)
+	if renderOptions.Variables != nil {
+		if err := writeVariables(runner.WorkingDir, renderOptions.Variables); err != nil {
+			return fmt.Errorf("unable to write variables: %s", err)
+		}
+	}
+	if partlyRendered {
+		for _, failed := range renderError.Resources {
+			summary.Fail(failed.Address, failed.Err)
+		}
+		return partial(renderError, len(renderError.Resources), len(addresses))
+	}
+	if options.Plan || options.Verify {
+		return verifyPlan(runner, options.Verify)
+	}
+	return nil
+}
+
+// annotate traces the resources to the remote objects they were fetched from, by the address they're written at.
+// baseURLs are the API URLs of the OneLogin accounts by provider alias, "" for the account the command runs against
+func annotate(action string, resources []tfimportables.ResourceDefinition, addresses []string, baseURLs map[string]string) map[string]stateparser.Annotation {
+	generated := time.Now().UTC()
+	annotations := make(map[string]stateparser.Annotation, len(resources))
+	for i, resource := range resources {
+		annotations[addresses[i]] = stateparser.Annotation{
+			Action: action,
+			Time:   generated,
+			Source: tfimportables.Source(resource, baseURLs[resource.ProviderAlias]),
+			Tool:   "onelogin-cli " + Version,
+		}
+	}
+	return annotations
+}
+
+// importChunk imports the chunk's resources and fills in their configuration in main.tf, leaving the rest of it as it
+// was, then records where they were imported in the address map. With a checkpoint, the chunk is recorded as started
+// before importing and as done once main.tf and the address map are written
+func importChunk(runner tfexec.Runner, planFile *os.File, chunk tfimport.Chunk, renderOptions stateparser.Options, options tfImportOptions, checkpoint *tfimport.Checkpoint, addressMap tfimport.AddressMap, summary *tfimport.Summary) (*stateparser.RenderError, error) {
+	configSize, err := planFile.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read main.tf: %s", err)
+	}
+	if checkpoint != nil {
+		checkpoint.Start(chunk, configSize)
+		if err := checkpoint.Write(runner.WorkingDir); err != nil {
+			return nil, fmt.Errorf("unable to write the import checkpoint: %s", err)
+		}
+	}
+	if err := chunk.WriteHeaders(planFile); err != nil {
+		return nil, fmt.Errorf("problem creating import file: %s", err)
+	}
+
+	if len(chunk.Resources) > 0 && options.DirectState {
+		if err := pushDirectState(runner, chunk, renderOptions.Schemas); err != nil {
+			return nil, fmt.Errorf("problem writing state directly: %s", err)
+		}
+		for _, resourceDefinition := range chunk.Resources {
+			summary.Imported(resourceDefinition.Type)
+		}
+	} else if len(chunk.Resources) > 0 {
+		if err := importResources(runner, chunk, options.RetryPolicy, summary); err != nil {
+			return nil, err
 		}
 	}
 
 	// grab the state from tfstate
-	state := stateparser.State{}
-	log.Println("Collecting State from tfstate File")
-	data, err := ioutil.ReadFile(filepath.Join("terraform.tfstate"))
+	logger.Info("Collecting State with 'state pull'")
+	data, err := runner.StatePull()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read tfstate: %s", err)
+	}
+	state, err := stateparser.Parse(bytes.NewReader(data))
 	if err != nil {
-		planFile.Close()
-		log.Fatalln("Unable to Read tfstate", err)
+		return nil, fmt.Errorf("unable to translate tfstate in memory: %s", err)
 	}
-	if err := json.Unmarshal(data, &state); err != nil {
-		planFile.Close()
-		log.Fatalln("Unable to Translate tfstate in Memory", err)
+
+	if options.Variablize {
+		renderOptions.Variables = &stateparser.Variables{}
+	}
+	// fill in the placeholders written for this chunk, leaving the rest of main.tf as it was
+	planFile.Seek(0, io.SeekStart)
+	src, err := ioutil.ReadAll(planFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read main.tf: %s", err)
+	}
+	buffer, err := stateparser.UpdateHCL(src, planFile.Name(), state, renderOptions, chunk.Addresses())
+	renderError, partlyRendered := err.(*stateparser.RenderError)
+	if err != nil && !partlyRendered {
+		return nil, fmt.Errorf("unable to update main.tf: %s", err)
 	}
 
-	buffer := stateparser.ConvertTFStateToHCL(state, importables)
+	planFile.Truncate(0)
+	planFile.Seek(0, io.SeekStart)
+	if _, err := planFile.Write(buffer); err != nil {
+		return nil, fmt.Errorf("problem writing final main.tf: %s", err)
+	}
+	if err := planFile.Sync(); err != nil {
+		return nil, fmt.Errorf("problem writing main.tf: %s", err)
+	}
+
+	if renderOptions.Variables != nil {
+		if err := writeVariables(runner.WorkingDir, renderOptions.Variables); err != nil {
+			return nil, fmt.Errorf("unable to write variables: %s", err)
+		}
+	}
+	if len(chunk.Resources) > 0 {
+		addressMap.Add(chunk)
+		if err := addressMap.Write(runner.WorkingDir); err != nil {
+			return nil, fmt.Errorf("unable to write the address map: %s", err)
+		}
+	}
+	if checkpoint != nil {
+		checkpoint.Done(chunk, int64(len(buffer)))
+		if err := checkpoint.Write(runner.WorkingDir); err != nil {
+			return nil, fmt.Errorf("unable to write the import checkpoint: %s", err)
+		}
+	}
+	if partlyRendered {
+		return renderError, nil
+	}
+	return nil, nil
+}
 
-	// go to the start of main.tf and overwrite whole file
-	planFile.Seek(0, 0)
-	_, err = planFile.Write(buffer)
+// resumeCheckpoint reads the checkpoint of an interrupted chunked import of the resource type in dir, nil when there's
+// none. main.tf is cut back to where the last chunk written ended, dropping the placeholders of the chunk interrupted
+func resumeCheckpoint(dir string, resourceType string, planFile *os.File, options tfImportOptions) (*tfimport.Checkpoint, error) {
+	checkpoint, err := tfimport.ReadCheckpoint(dir)
+	if err != nil || checkpoint == nil {
+		return nil, err
+	}
+	if checkpoint.Resource != resourceType {
+		return nil, fmt.Errorf("an import of %s was interrupted here, run it again to finish it or delete %s", checkpoint.Resource, tfimport.CheckpointFile)
+	}
+	if options.ImportScript != "" {
+		return nil, fmt.Errorf("an import of %s was interrupted here, finish it before emitting an import script or delete %s", checkpoint.Resource, tfimport.CheckpointFile)
+	}
+	logger.Info("Resuming the interrupted import", "resource", resourceType, "imported", len(checkpoint.Imported))
+	if err := planFile.Truncate(checkpoint.ConfigSize); err != nil {
+		return nil, fmt.Errorf("unable to restore main.tf: %s", err)
+	}
+	return checkpoint, nil
+}
+
+// forgetPending removes the resources of the chunk the interrupted import was on from state, as main.tf no longer
+// has their placeholders and they are imported again
+func forgetPending(runner tfexec.Runner, checkpoint *tfimport.Checkpoint) error {
+	if len(checkpoint.Pending) == 0 {
+		return nil
+	}
+	data, err := runner.StatePull()
+	if err != nil || len(bytes.TrimSpace(data)) == 0 {
+		return err
+	}
+	state, err := stateparser.Parse(bytes.NewReader(data))
 	if err != nil {
-		planFile.Close()
-		fmt.Println("ERROR Writing Final main.tf", err)
+		return err
+	}
+	inState := map[string]bool{}
+	for _, resource := range state.Resources {
+		if resource.Mode != "data" {
+			inState[resource.Type+"."+resource.Name] = true
+		}
+	}
+	addresses := []string{}
+	for _, address := range checkpoint.Pending {
+		if inState[address] {
+			addresses = append(addresses, address)
+		}
+	}
+	if len(addresses) == 0 {
+		return nil
 	}
+	logger.Info("Removing the resources of the interrupted chunk from state to import them again", "count", len(addresses))
+	return runner.StateRm(addresses...)
+}
 
-	if err := planFile.Close(); err != nil {
-		fmt.Println("Problem writing file", err)
+// removeCheckpoint deletes the checkpoint once the import it tracks is done
+func removeCheckpoint(dir string, checkpoint *tfimport.Checkpoint) error {
+	if checkpoint == nil {
+		return nil
+	}
+	if err := checkpoint.Remove(dir); err != nil {
+		return fmt.Errorf("unable to remove the import checkpoint: %s", err)
+	}
+	return nil
+}
+
+// resolveDuplicates warns about remote resources of a type sharing a name, listing them in a table, and handles them
+// the way given with --on-duplicate. Renaming asks for a name for each of them
+func resolveDuplicates(resourceDefinitions []tfimportables.ResourceDefinition, strategy string) ([]tfimportables.ResourceDefinition, []tfimportables.ResourceDefinition, error) {
+	known := false
+	for _, choice := range tfimport.DuplicateStrategies {
+		known = known || choice == strategy
+	}
+	if !known {
+		return nil, nil, fmt.Errorf("unknown --on-duplicate %s, expected one of %s", strategy, strings.Join(tfimport.DuplicateStrategies, ", "))
+	}
+	duplicates := tfimport.FindDuplicates(resourceDefinitions)
+	if len(duplicates) == 0 {
+		return resourceDefinitions, nil, nil
+	}
+	logger.Warn("Remote resources share names", "count", len(duplicates), "on-duplicate", strategy)
+	if logFormat == logger.TextFormat {
+		list, err := records.From(duplicates)
+		if err != nil {
+			return nil, nil, err
+		}
+		records.Write(os.Stderr, records.TableFormat, list, []string{"type", "name", "ids"})
+	} else {
+		for _, duplicate := range duplicates {
+			logger.Warn("Duplicate name", "type", duplicate.Type, "name", duplicate.Name, "ids", strings.Join(duplicate.IDs, ","))
+		}
+	}
+	return tfimport.ResolveDuplicates(resourceDefinitions, strategy, func(resourceDefinition tfimportables.ResourceDefinition) (string, error) {
+		return prompt(fmt.Sprintf("Name for %s %s with id %s (empty to keep it)", resourceDefinition.Type, resourceDefinition.Name, resourceDefinition.ImportID))
+	})
+}
+
+// validateRemote checks the resources fetched from the remote before importing them, warning about those that would
+// fail to import or plan. With skipInvalid those are left out of the resources returned, and recorded as failures in
+// the summary
+func validateRemote(resourceDefinitions []tfimportables.ResourceDefinition, schemas *tfschema.ProviderSchemas, skipInvalid bool, summary *tfimport.Summary) ([]tfimportables.ResourceDefinition, error) {
+	invalid, err := stateparser.Validate(resourceDefinitions, schemas)
+	if err != nil {
+		return nil, err
+	}
+	skipped := map[string]bool{}
+	for _, resource := range invalid {
+		definition := resource.Resource
+		address, problems := definition.Type+"."+definition.Name, strings.Join(resource.Problems, "; ")
+		if !skipInvalid {
+			logger.Warn("Resource will fail to import or plan, pass --skip-invalid to leave it out", "resource", address, "id", definition.ImportID, "problems", problems)
+			continue
+		}
+		logger.Warn("Skipping invalid resource", "resource", address, "id", definition.ImportID, "problems", problems)
+		summary.Fail(address, fmt.Errorf("skipped as invalid: %s", problems))
+		skipped[definition.ProviderAlias+"/"+definition.Type+"/"+definition.ImportID] = true
+	}
+	valid := []tfimportables.ResourceDefinition{}
+	for _, definition := range resourceDefinitions {
+		if !skipped[definition.ProviderAlias+"/"+definition.Type+"/"+definition.ImportID] {
+			valid = append(valid, definition)
+		}
+	}
+	return valid, nil
+}
+
+// importResources runs terraform import for each resource, drawing a progress bar on a terminal or logging each
+// resource elsewhere, then logs how long the imports of each type took
+func importResources(runner tfexec.Runner, chunk tfimport.Chunk, policy tfexec.RetryPolicy, summary *tfimport.Summary) error {
+	resourceDefinitions := chunk.Resources
+	started, timings := time.Now(), progress.Timings{}
+	var err error
+	if showProgress() {
+		bar := progress.New(os.Stderr, len(resourceDefinitions))
+		previous := logger.Default().SetOutput(bar) // warnings like retries are printed above the bar
+		err = runImports(runner, chunk, policy, bar, timings, summary)
+		bar.Finish()
+		logger.Default().SetOutput(previous)
+	} else {
+		err = runImports(runner, chunk, policy, nil, timings, summary)
+	}
+	if err != nil {
+		return err
+	}
+	for _, timing := range timings.Summary() {
+		logger.Info("Imported resources", "type", timing.Group, "count", timing.Count,
+			"duration", timing.Duration.Round(time.Millisecond), "average", timing.Average().Round(time.Millisecond))
+	}
+	logger.Info("Imported all resources", "count", len(resourceDefinitions), "duration", time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// runImports imports the resources one at a time, showing each on the bar or in a log line when there's no bar, and
+// counting them in the timings and the summary
+func runImports(runner tfexec.Runner, chunk tfimport.Chunk, policy tfexec.RetryPolicy, bar *progress.Bar, timings progress.Timings, summary *tfimport.Summary) error {
+	resourceDefinitions := chunk.Resources
+	for i, resourceDefinition := range resourceDefinitions {
+		if err := runContext.Err(); err != nil {
+			return partial(fmt.Errorf("stopped after importing %d of %d resources: %s", i, len(resourceDefinitions), err), len(resourceDefinitions)-i, len(resourceDefinitions))
+		}
+		address := chunk.Address(i)
+		if bar != nil {
+			bar.Start(address)
+		} else {
+			logger.Info("Importing resource", "index", i+1, "total", len(resourceDefinitions), "address", address)
+		}
+		started := time.Now()
+		if err := runner.ImportWithRetry(address, resourceDefinition.ImportID, policy); err != nil {
+			summary.Fail(address, err)
+			err = fmt.Errorf("problem executing terraform import after importing %d of %d resources: %s", i, len(resourceDefinitions), err)
+			return partial(err, len(resourceDefinitions)-i, len(resourceDefinitions))
+		}
+		timings.Add(resourceDefinition.Type, time.Since(started))
+		summary.Imported(resourceDefinition.Type)
+		if bar != nil {
+			bar.Increment()
+		}
+	}
+	return nil
+}
+
+// writeImportReport writes the summary of the import that ended with err to the file, as JSON or Markdown by its
+// extension
+func writeImportReport(file string, summary tfimport.Summary, elapsed time.Duration, err error) error {
+	summary.Elapsed, summary.APIRequests, summary.Result = elapsed, apiRequests.Count(), tfimport.Succeeded
+	if err != nil {
+		summary.Result = tfimport.Failed
+		if _, isPartial := err.(partialError); isPartial {
+			summary.Result = tfimport.Partial
+		}
+		if len(summary.Failures) == 0 {
+			summary.Fail("", err)
+		}
+	}
+	out, createErr := os.Create(file)
+	if createErr != nil {
+		return createErr
+	}
+	defer out.Close()
+	write := summary.WriteMarkdown
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		write = summary.WriteJSON
+	}
+	if err := write(out); err != nil {
+		return err
+	}
+	logger.Info("Wrote import report", "file", file)
+	return out.Close()
+}
+
+// showProgress reports whether progress bars can be drawn: on a terminal, with text logs, unless --no-progress or
+// --quiet is set
+func showProgress() bool {
+	return !noProgress && !quiet && logFormat == logger.TextFormat && progress.IsTerminal(os.Stdout) && progress.IsTerminal(os.Stderr)
+}
+
+// declares the values extracted from main.tf in variables.tf and writes an example terraform.tfvars with
+// the values from state, leaving secrets blank. Both are appended to so earlier content is kept
+func writeVariables(dir string, variables *stateparser.Variables) error {
+	if len(variables.List()) == 0 {
+		return nil
+	}
+	logger.Info("Writing variables to variables.tf and terraform.tfvars.example", "count", len(variables.List()))
+	if err := appendToFile(filepath.Join(dir, "variables.tf"), variables.HCL()); err != nil {
+		return err
+	}
+	return appendToFile(filepath.Join(dir, "terraform.tfvars.example"), variables.TFVarsExample())
+}
+
+// appends content to the file, separated from what's already there by a blank line
+func appendToFile(filename string, content []byte) error {
+	existing, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(existing) > 0 {
+		content = append([]byte("\n"), content...)
+		if !bytes.HasSuffix(existing, []byte("\n")) {
+			content = append([]byte("\n"), content...)
+		}
+	}
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// runs a plan against the freshly written main.tf and reports anything that isn't drift free.
+// when strict, a non-empty plan is an error
+func verifyPlan(runner tfexec.Runner, strict bool) error {
+	logger.Info("Verifying main.tf with 'plan -detailed-exitcode'...")
+	result, err := runner.Plan()
+	if err != nil {
+		return err
+	}
+	if result.Empty() {
+		logger.Info("Plan is empty. The generated configuration matches state")
+		return nil
+	}
+	logger.Warn("Plan has changes", "count", len(result.Changes))
+	printer := diffs.Printer{W: os.Stderr, Color: diffs.ColorEnabled(os.Stderr)}
+	for _, change := range result.Changes {
+		logger.Warn("Planned change", "address", change.Address, "actions", strings.Join(change.Actions, ","), "attributes", strings.Join(change.Attributes, ","))
+		if logFormat != logger.TextFormat {
+			continue // lines of text would break the JSON logs
+		}
+		for _, attribute := range change.Attributes {
+			printer.Lines("    ", diffs.Compare(attribute, change.Before[attribute], change.After[attribute]))
+		}
+	}
+	if strict {
+		return fmt.Errorf("verification failed: plan is not empty")
+	}
+	return nil
+}
+
+// reads the schemas of the providers in the workspace. terraform init must have run
+func loadSchemas(runner tfexec.Runner) (*tfschema.ProviderSchemas, error) {
+	logger.Info("Reading provider schemas...")
+	schemaData, err := runner.ProvidersSchema()
+	if err != nil {
+		return nil, err
+	}
+	return tfschema.Parse(schemaData)
+}
+
+// builds state entries for the new resources from the data already collected from the remote
+// and pushes them in one go, skipping the provider refresh terraform import does per resource
+func pushDirectState(runner tfexec.Runner, chunk tfimport.Chunk, schemas *tfschema.ProviderSchemas) error {
+	existingState, err := runner.StatePull()
+	if err != nil {
+		return err
+	}
+	named := make([]tfimportables.ResourceDefinition, len(chunk.Resources))
+	for i, resourceDefinition := range chunk.Resources {
+		resourceDefinition.Name = tfimport.ImportName(resourceDefinition, chunk.First+i)
+		named[i] = resourceDefinition
+	}
+	state, err := stateparser.BuildState(existingState, named, schemas)
+	if err != nil {
+		return err
+	}
+	logger.Info("Pushing resources to state", "count", len(named))
+	return runner.StatePush(state)
+}
+
+// writes the import commands to a file as a shell script, as a PowerShell script if the file ends in .ps1 or as a JSON
+// plan if it ends in .json
+func emitImportScript(path string, runner tfexec.Runner, resourceDefinitions []tfimportables.ResourceDefinition) error {
+	format := "sh"
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = "json"
+	case ".ps1":
+		format = "ps1"
+	}
+	scriptFile, err := os.OpenFile(filepath.Clean(path), os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := tfimport.WriteImportScript(resourceDefinitions, runner.Binary, format, scriptFile); err != nil {
+		scriptFile.Close()
+		return err
 	}
+	return scriptFile.Close()
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/oidc"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+)
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var tokensCommand = &cobra.Command{
+		Use:   "tokens",
+		Short: `Revoke tokens OneLogin issued through OIDC apps.`,
+	}
+
+	var (
+		app       oidc.App
+		tokens    []string
+		tokenFile string
+		tokenType string
+		dryRun    bool
+	)
+	var tokensRevokeCommand = &cobra.Command{
+		Use:   "revoke",
+		Short: `Revoke access and refresh tokens issued through an OIDC app.`,
+		Long: `Revokes tokens at the revocation endpoint of the OIDC app's issuer, authenticating as the app with its
+		client id and secret. The secret is read from ONELOGIN_OIDC_CLIENT_SECRET unless --client-secret is given, and
+		apps without one, like those using PKCE, need only the client id. Tokens are given with --token, or a line per
+		token with --from-file, and every one is tried even when some fail. Revoking a refresh token also ends the
+		access tokens issued with it.`,
+		Args: cobra.NoArgs,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			clientConfigs = loadClientConfigs()
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := revokeTokens(clientConfigs, app, tokens, tokenFile, tokenType, dryRun); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	tokensRevokeCommand.Flags().StringVar(&app.Issuer, "issuer", "", "Issuer of the OIDC app e.g. https://acme.onelogin.com/oidc/2")
+	tokensRevokeCommand.Flags().StringVar(&app.ClientID, "client-id", "", "Client id of the OIDC app")
+	tokensRevokeCommand.Flags().StringVar(&app.ClientSecret, "client-secret", os.Getenv("ONELOGIN_OIDC_CLIENT_SECRET"), "Client secret of the OIDC app (env ONELOGIN_OIDC_CLIENT_SECRET)")
+	tokensRevokeCommand.Flags().StringArrayVar(&tokens, "token", nil, "Token to revoke. Repeat for several")
+	tokensRevokeCommand.Flags().StringVar(&tokenFile, "from-file", "", "File with a token to revoke per line, or - for stdin")
+	tokensRevokeCommand.Flags().StringVar(&tokenType, "type", "", "Kind of the tokens, access_token or refresh_token, to help the issuer find them")
+	tokensRevokeCommand.Flags().BoolVar(&dryRun, "dry-run", false, "Log how many tokens would be revoked without revoking them")
+	tokensRevokeCommand.MarkFlagRequired("issuer")
+	tokensRevokeCommand.MarkFlagRequired("client-id")
+
+	tokensCommand.AddCommand(tokensRevokeCommand)
+	rootCmd.AddCommand(tokensCommand)
+}
+
+// revokeTokens revokes the tokens given and those in the file, reporting how many couldn't be revoked. Tokens are
+// logged by position rather than value, so logs don't leak them
+func revokeTokens(clientConfigs clients.ClientConfigs, app oidc.App, tokens []string, file string, tokenType string, dryRun bool) error {
+	if tokenType != "" && tokenType != oidc.AccessToken && tokenType != oidc.RefreshToken {
+		return fmt.Errorf("invalid --type %s, expected %s or %s", tokenType, oidc.AccessToken, oidc.RefreshToken)
+	}
+	if file != "" {
+		var in io.Reader = os.Stdin
+		if file != "-" {
+			opened, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer opened.Close()
+			in = opened
+		}
+		lines := bufio.NewScanner(in)
+		for lines.Scan() {
+			if token := strings.TrimSpace(lines.Text()); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+		if err := lines.Err(); err != nil {
+			return fmt.Errorf("unable to read tokens: %s", err)
+		}
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("no tokens given, expected --token or --from-file")
+	}
+	if dryRun {
+		logger.Info("Revoked tokens", "issuer", app.Issuer, "client_id", app.ClientID, "tokens", len(tokens), "dry_run", true)
+		return nil
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for i, token := range tokens {
+		if err := app.Revoke(api.HTTPClient(), token, tokenType); err != nil {
+			logger.Error("Unable to revoke token", "token", i+1, "error", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return partial(fmt.Errorf("unable to revoke %d of %d tokens", failed, len(tokens)), failed, len(tokens))
+	}
+	logger.Info("Revoked tokens", "issuer", app.Issuer, "client_id", app.ClientID, "tokens", len(tokens))
+	return nil
+}
@@ -0,0 +1,538 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/deprovision"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/passwords"
+	"github.com/onelogin/onelogin/records"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// user fields the API filters on itself, so filtering by them with = doesn't page through every user
+var userQueryFields = map[string]bool{
+	"email":          true,
+	"username":       true,
+	"firstname":      true,
+	"lastname":       true,
+	"samaccountname": true,
+	"directory_id":   true,
+	"external_id":    true,
+}
+
+func init() {
+	var clientConfigs clients.ClientConfigs
+	var usersCommand = &cobra.Command{
+		Use:   "users",
+		Short: `Look up and manage OneLogin users.`,
+	}
+	loadConfigs := func(cmd *cobra.Command, args []string) {
+		clientConfigs = loadClientConfigs()
+	}
+
+	var (
+		filters    []string
+		listOutput outputFlags
+	)
+	var usersListCommand = &cobra.Command{
+		Use:   "list",
+		Short: `List users, optionally filtered.`,
+		Long: `Lists the account's users with the fields given by --fields. Every --filter must match for a user
+		to be listed: email~@example.com keeps users whose email contains the value ignoring case, status=1 those
+		whose field equals it, and status!=1 those whose field doesn't.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := listUsers(clientConfigs, filters, listOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	usersListCommand.Flags().StringArrayVar(&filters, "filter", nil, "Keep users whose field matches e.g. email~@example.com, status=1 or status!=1. Repeat to require several")
+	addOutputFlags(usersListCommand, &listOutput, []string{"id", "email", "username", "firstname", "lastname", "status"}, records.TableFormat)
+
+	var getOutput outputFlags
+	var usersGetCommand = &cobra.Command{
+		Use:    "get <id>",
+		Short:  `Print a user.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := getUser(clientConfigs, args[0], getOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addOutputFlags(usersGetCommand, &getOutput, nil, records.JSONFormat)
+
+	var (
+		createFields userFieldFlags
+		createOutput outputFlags
+	)
+	var usersCreateCommand = &cobra.Command{
+		Use:   "create",
+		Short: `Create a user.`,
+		Long: `Creates a user with the fields of --from-file, a JSON object or - for stdin, overridden by --set and
+		flags like --email. --dry-run prints the user that would be created instead.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := createUser(clientConfigs, cmd, createFields, createOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addUserFieldFlags(usersCreateCommand, &createFields, true)
+	addOutputFlags(usersCreateCommand, &createOutput, nil, records.JSONFormat)
+
+	var (
+		updateFields userFieldFlags
+		updateOutput outputFlags
+	)
+	var usersUpdateCommand = &cobra.Command{
+		Use:   "update <id>",
+		Short: `Update fields of a user.`,
+		Long: `Updates only the fields given by --from-file, --set and flags like --email, leaving the others as they
+		are. --dry-run prints the changes that would be sent instead.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := updateUser(clientConfigs, cmd, args[0], updateFields, updateOutput); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	addUserFieldFlags(usersUpdateCommand, &updateFields, false)
+	addOutputFlags(usersUpdateCommand, &updateOutput, nil, records.JSONFormat)
+
+	var deleteDryRun, deleteYes bool
+	var usersDeleteCommand = &cobra.Command{
+		Use:    "delete <id>",
+		Short:  `Delete a user.`,
+		Long:   `Deletes the user after asking for confirmation, unless --yes is given.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := deleteUser(clientConfigs, args[0], deleteDryRun, deleteYes); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	usersDeleteCommand.Flags().BoolVar(&deleteDryRun, "dry-run", false, "Print the user that would be deleted without deleting it")
+	usersDeleteCommand.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Delete without asking for confirmation")
+
+	var (
+		exportFilters []string
+		exportOutput  outputFlags
+		exportFile    string
+	)
+	var usersExportCommand = &cobra.Command{
+		Use:   "export",
+		Short: `Export users to CSV or JSON.`,
+		Long: `Writes the users matching every --filter to --out, or stdout, as CSV ready to edit and load back with
+		bulk-import, or as JSON with --format json.`,
+		Args:   cobra.NoArgs,
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := exportUsers(clientConfigs, exportFilters, exportOutput, exportFile); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	usersExportCommand.Flags().StringArrayVar(&exportFilters, "filter", nil, "Keep users whose field matches e.g. email~@example.com, status=1 or status!=1. Repeat to require several")
+	usersExportCommand.Flags().StringSliceVar(&exportOutput.fields, "fields", userExportFields, "Comma separated fields to export, as the API names them")
+	usersExportCommand.Flags().StringVar(&exportOutput.format, "format", records.CSVFormat, "Export format: csv or json")
+	usersExportCommand.Flags().StringVar(&exportFile, "out", "", "File to write the users to instead of stdout")
+
+	var importFlags bulkImportFlags
+	var usersBulkImportCommand = &cobra.Command{
+		Use:   "bulk-import <file.csv>",
+		Short: `Create or update users from a CSV file.`,
+		Long: `Creates a user for every row of the CSV file, whose header names the user fields e.g. email,firstname.
+		--map renames columns, and --upsert updates the users whose email already exists instead of creating them.
+		Rows are sent --batch-size at a time, waiting for the rate limit to reset when it runs low. Rows that fail are
+		reported and written to --errors-file with the reason, ready to fix and import again.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := bulkImportUsers(clientConfigs, args[0], importFlags); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	usersBulkImportCommand.Flags().StringArrayVar(&importFlags.columns, "map", nil, "Rename a column to a user field e.g. \"E-mail=email\", or drop it with \"Notes=\". Repeat for several")
+	usersBulkImportCommand.Flags().BoolVar(&importFlags.upsert, "upsert", false, "Update users whose email already exists instead of creating them, so the import can be run again")
+	usersBulkImportCommand.Flags().IntVar(&importFlags.batchSize, "batch-size", bulk.DefaultBatchSize, "Rows sent at once")
+	usersBulkImportCommand.Flags().StringVar(&importFlags.errorsFile, "errors-file", "", "Write the rows that failed, with an error column, to this CSV file")
+	usersBulkImportCommand.Flags().BoolVar(&importFlags.dryRun, "dry-run", false, "Report whether each row would create or update a user without sending anything")
+
+	var (
+		workflowFile      string
+		deprovisionDryRun bool
+		deprovisionYes    bool
+	)
+	var usersDeprovisionCommand = &cobra.Command{
+		Use:   "deprovision <id|email>",
+		Short: `Offboard a user with a workflow.`,
+		Long: `Runs the steps of the --workflow YAML file for the user, e.g. removing them from their roles, revoking
+		their sessions and suspending them, which is also the workflow run when none is given. Steps can be
+		remove_roles, remove_apps, revoke_sessions, set_status and delete, which with after_days only deletes users
+		suspended at least that long ago.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := deprovisionUser(clientConfigs, args[0], workflowFile, deprovisionDryRun, deprovisionYes); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	usersDeprovisionCommand.Flags().StringVar(&workflowFile, "workflow", "", "YAML file with the steps to take (defaults to remove_roles, revoke_sessions and set_status suspended)")
+	usersDeprovisionCommand.Flags().BoolVar(&deprovisionDryRun, "dry-run", false, "Log the steps that would be taken without changing the user")
+	usersDeprovisionCommand.Flags().BoolVarP(&deprovisionYes, "yes", "y", false, "Deprovision without asking for confirmation")
+
+	var (
+		personalEmail string
+		printLink     bool
+	)
+	var usersInviteCommand = &cobra.Command{
+		Use:   "invite <email>",
+		Short: `Invite a user to set their password and sign in.`,
+		Long: `Sends the user with the email an invite to set their password, to their personal email instead when
+		--personal-email is given. --print-link prints the invite link without sending it, to pass on another way.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := inviteUser(clientConfigs, args[0], personalEmail, printLink); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	usersInviteCommand.Flags().StringVar(&personalEmail, "personal-email", "", "Send the invite to this address instead of the user's email")
+	usersInviteCommand.Flags().BoolVar(&printLink, "print-link", false, "Print the invite link instead of sending it")
+
+	var resetFlags resetPasswordFlags
+	var usersResetPasswordCommand = &cobra.Command{
+		Use:   "reset-password <id|email>",
+		Short: `Set a temporary password for a user.`,
+		Long: `Sets a generated password for the user, or the one read from stdin with --password-stdin. It is only
+		printed with --show-password, so without it the user has to reset it themselves. --require-change expires
+		the password so the user has to change it when they next sign in.`,
+		Args:   cobra.ExactArgs(1),
+		PreRun: loadConfigs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := resetPassword(clientConfigs, args[0], resetFlags); err != nil {
+				fatal(err)
+			}
+		},
+	}
+	usersResetPasswordCommand.Flags().BoolVar(&resetFlags.requireChange, "require-change", false, "Make the user change the password when they next sign in")
+	usersResetPasswordCommand.Flags().BoolVar(&resetFlags.showPassword, "show-password", false, "Print the temporary password on stdout")
+	usersResetPasswordCommand.Flags().BoolVar(&resetFlags.passwordStdin, "password-stdin", false, "Read the password from stdin instead of generating one")
+	usersResetPasswordCommand.Flags().IntVar(&resetFlags.length, "length", passwords.DefaultLength, "Length of the generated password")
+	usersResetPasswordCommand.Flags().BoolVar(&resetFlags.skipPolicy, "skip-policy", false, "Set the password even if the user's password policy rejects it")
+	usersResetPasswordCommand.Flags().BoolVar(&resetFlags.dryRun, "dry-run", false, "Log the user whose password would be reset without resetting it")
+
+	usersCommand.AddCommand(usersListCommand, usersGetCommand, usersCreateCommand, usersUpdateCommand, usersDeleteCommand,
+		usersExportCommand, usersBulkImportCommand, usersDeprovisionCommand, usersInviteCommand, usersResetPasswordCommand)
+	rootCmd.AddCommand(usersCommand)
+}
+
+// listUsers prints the users matching every filter
+func listUsers(clientConfigs clients.ClientConfigs, filterFlags []string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	filters, err := records.ParseFilters(filterFlags)
+	if err != nil {
+		return err
+	}
+	users, err := fetchUsers(clientConfigs, filters)
+	if err != nil {
+		return err
+	}
+	return output.write(users)
+}
+
+// fetchUsers lists the users matching every filter. Filters the API supports narrow the pages fetched, and all of
+// them are checked on the users returned
+func fetchUsers(clientConfigs clients.ClientConfigs, filters []records.Filter) ([]records.Record, error) {
+	pages, err := clients.New(clientConfigs).OneLoginPages()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	for _, filter := range filters {
+		if filter.Operator == "=" && userQueryFields[filter.Field] {
+			query.Set(filter.Field, filter.Value)
+		}
+	}
+	users := []records.Record{}
+	err = pages.StreamPages("/api/2/users", query, func(page []byte) error {
+		pageUsers, err := records.FromJSON(page)
+		if err != nil {
+			return fmt.Errorf("unable to read users: %s", err)
+		}
+		for _, user := range pageUsers {
+			if records.MatchAll(user, filters) {
+				users = append(users, user)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list users: %s", err)
+	}
+	return users, nil
+}
+
+// getUser prints the user with the id
+func getUser(clientConfigs clients.ClientConfigs, id string, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid user id %s", id)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	user, _, err := api.Do(http.MethodGet, fmt.Sprintf("/api/2/users/%d", userID), nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get user %d: %s", userID, err)
+	}
+	list, err := records.FromJSON(user)
+	if err != nil {
+		return fmt.Errorf("unable to read user %d: %s", userID, err)
+	}
+	return output.writeOne(list[0])
+}
+
+// userFieldFlags are the flags setting the fields of a user to create or update
+type userFieldFlags struct {
+	fromFile  string
+	set       []string
+	email     string
+	username  string
+	firstname string
+	lastname  string
+	dryRun    bool
+}
+
+func addUserFieldFlags(cmd *cobra.Command, flags *userFieldFlags, create bool) {
+	cmd.Flags().StringVar(&flags.fromFile, "from-file", "", "Read fields from a JSON object in this file, or - for stdin")
+	cmd.Flags().StringArrayVar(&flags.set, "set", nil, "Set a field e.g. status=1 or firstname=Ada. Repeat to set several")
+	cmd.Flags().StringVar(&flags.email, "email", "", "Email of the user")
+	cmd.Flags().StringVar(&flags.username, "username", "", "Username of the user")
+	cmd.Flags().StringVar(&flags.firstname, "firstname", "", "First name of the user")
+	cmd.Flags().StringVar(&flags.lastname, "lastname", "", "Last name of the user")
+	if create {
+		cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Print the user that would be created without creating it")
+	} else {
+		cmd.Flags().BoolVar(&flags.dryRun, "dry-run", false, "Print the changes that would be sent without sending them")
+	}
+}
+
+// fields are the user's fields from --from-file, then --set, then the flags naming fields, later ones winning
+func (f userFieldFlags) fields(cmd *cobra.Command) (records.Record, error) {
+	fromFile := records.Record{}
+	if f.fromFile != "" {
+		var data []byte
+		var err error
+		if f.fromFile == "-" {
+			data, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			data, err = ioutil.ReadFile(f.fromFile)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %s", f.fromFile, err)
+		}
+		if data = bytes.TrimSpace(data); len(data) == 0 || data[0] != '{' {
+			return nil, fmt.Errorf("%s must hold a JSON object of user fields", f.fromFile)
+		}
+		list, err := records.FromJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s: %s", f.fromFile, err)
+		}
+		fromFile = list[0]
+	}
+	set, err := records.ParseSet(f.set)
+	if err != nil {
+		return nil, err
+	}
+	named := records.Record{}
+	for field, value := range map[string]string{"email": f.email, "username": f.username, "firstname": f.firstname, "lastname": f.lastname} {
+		if cmd.Flags().Changed(field) {
+			named[field] = value
+		}
+	}
+	return records.Merge(fromFile, set, named), nil
+}
+
+// createUser creates the user, or prints it with --dry-run
+func createUser(clientConfigs clients.ClientConfigs, cmd *cobra.Command, flags userFieldFlags, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	user, err := flags.fields(cmd)
+	if err != nil {
+		return err
+	}
+	if user.Text("email") == "" && user.Text("username") == "" {
+		return fmt.Errorf("a user needs an email or a username, give one with --email or --username")
+	}
+	return sendUser(clientConfigs, http.MethodPost, "/api/2/users", user, flags.dryRun, output)
+}
+
+// updateUser sends the fields given for the user, or prints them with --dry-run
+func updateUser(clientConfigs clients.ClientConfigs, cmd *cobra.Command, id string, flags userFieldFlags, output outputFlags) error {
+	if err := records.CheckFormat(output.format); err != nil {
+		return err
+	}
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid user id %s", id)
+	}
+	changes, err := flags.fields(cmd)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return fmt.Errorf("nothing to update, give fields with --set, --from-file or flags like --email")
+	}
+	return sendUser(clientConfigs, http.MethodPut, fmt.Sprintf("/api/2/users/%d", userID), changes, flags.dryRun, output)
+}
+
+// sendUser sends the user's fields and prints the user the API returns
+func sendUser(clientConfigs clients.ClientConfigs, method string, path string, user records.Record, dryRun bool, output outputFlags) error {
+	body, err := user.JSON()
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		logger.Info("Dry run, nothing was sent", "method", method, "path", path)
+		fmt.Println(string(body))
+		return nil
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	response, _, err := api.Do(method, path, nil, body)
+	if err != nil {
+		return fmt.Errorf("unable to save user: %s", err)
+	}
+	list, err := records.FromJSON(response)
+	if err != nil {
+		return fmt.Errorf("unable to read saved user: %s", err)
+	}
+	return output.writeOne(list[0])
+}
+
+// deleteUser deletes the user once confirmed, naming it by email so the wrong id is caught before it is too late
+func deleteUser(clientConfigs clients.ClientConfigs, id string, dryRun bool, yes bool) error {
+	userID, err := strconv.Atoi(id)
+	if err != nil {
+		return fmt.Errorf("invalid user id %s", id)
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/api/2/users/%d", userID)
+	response, _, err := api.Do(http.MethodGet, path, nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get user %d: %s", userID, err)
+	}
+	list, err := records.FromJSON(response)
+	if err != nil {
+		return fmt.Errorf("unable to read user %d: %s", userID, err)
+	}
+	name := list[0].Text("email")
+	if name == "" {
+		name = list[0].Text("username")
+	}
+	if dryRun {
+		logger.Info("Dry run, user not deleted", "id", userID, "user", name)
+		return nil
+	}
+	if proceed, err := confirm(yes, fmt.Sprintf("This will delete user %d (%s). Do you want to continue?", userID, name)); !proceed {
+		return err
+	}
+	if _, _, err := api.Do(http.MethodDelete, path, nil, nil); err != nil {
+		return fmt.Errorf("unable to delete user %d: %s", userID, err)
+	}
+	logger.Info("Deleted user", "id", userID, "user", name)
+	return nil
+}
+
+// deprovisionUser runs the workflow, or the default one, for the user given by id or email once confirmed
+func deprovisionUser(clientConfigs clients.ClientConfigs, reference string, workflowFile string, dryRun bool, yes bool) error {
+	workflow := deprovision.Default()
+	if workflowFile != "" {
+		data, err := ioutil.ReadFile(workflowFile)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %s", workflowFile, err)
+		}
+		if workflow, err = deprovision.Parse(data); err != nil {
+			return err
+		}
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	user, err := deprovision.FindUser(api, reference)
+	if err != nil {
+		return err
+	}
+	actions := make([]string, len(workflow.Steps))
+	for i, step := range workflow.Steps {
+		actions[i] = step.Action
+	}
+	question := fmt.Sprintf("This will %s for user %s (%s).", strings.Join(actions, ", "), user.Text("id"), user.Text("email"))
+	if proceed, err := confirm(dryRun || yes, question+" Do you want to continue?"); !proceed {
+		return err
+	}
+	runner := deprovision.Runner{API: api, DryRun: dryRun}
+	if err := runner.Run(workflow, user); err != nil {
+		return fmt.Errorf("unable to deprovision user %s: %s", user.Text("id"), err)
+	}
+	if dryRun {
+		logger.Info("Dry run, the user was not changed", "id", user.Text("id"), "user", user.Text("email"))
+		return nil
+	}
+	logger.Info("Deprovisioned user", "id", user.Text("id"), "user", user.Text("email"))
+	return nil
+}
+
+// userIDsByEmail looks up the ids of the users with the emails, reporting the emails no user has
+func userIDsByEmail(api *clients.OneLoginAPI, emails []string) ([]int, error) {
+	out := []int{}
+	for _, email := range emails {
+		data, _, err := api.Do(http.MethodGet, "/api/2/users", url.Values{"email": {email}}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find user %s: %s", email, err)
+		}
+		found, err := records.FromJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read user %s: %s", email, err)
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no user has the email %s", email)
+		}
+		id, _ := strconv.Atoi(found[0].Text("id"))
+		out = append(out, id)
+	}
+	return out, nil
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/bulk"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// user fields exported by default, the ones bulk-import can set
+var userExportFields = []string{
+	"id", "email", "username", "firstname", "lastname", "title", "department", "company", "phone", "samaccountname",
+	"userprincipalname", "external_id", "directory_id", "manager_user_id", "status", "state",
+}
+
+// user fields the API sends numbers for, converted from the strings CSV holds
+var userNumberFields = map[string]bool{
+	"directory_id":    true,
+	"group_id":        true,
+	"manager_user_id": true,
+	"state":           true,
+	"status":          true,
+	"trusted_idp_id":  true,
+}
+
+// user fields the API sets itself, left out of imports so exported files load back as they are
+var userReadOnlyFields = map[string]bool{
+	"id":                     true,
+	"activated_at":           true,
+	"created_at":             true,
+	"updated_at":             true,
+	"last_login":             true,
+	"locked_until":           true,
+	"invitation_sent_at":     true,
+	"invalid_login_attempts": true,
+	"password_changed_at":    true,
+}
+
+// bulkImportFlags are the flags of users bulk-import
+type bulkImportFlags struct {
+	columns    []string
+	upsert     bool
+	batchSize  int
+	errorsFile string
+	dryRun     bool
+}
+
+// exportUsers writes the users matching every filter to the file, or stdout
+func exportUsers(clientConfigs clients.ClientConfigs, filterFlags []string, output outputFlags, file string) error {
+	if output.format != records.CSVFormat && output.format != records.JSONFormat {
+		return fmt.Errorf("unsupported format %s, expected csv or json", output.format)
+	}
+	filters, err := records.ParseFilters(filterFlags)
+	if err != nil {
+		return err
+	}
+	users, err := fetchUsers(clientConfigs, filters)
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if file != "" {
+		if out, err = os.Create(file); err != nil {
+			return fmt.Errorf("unable to create %s: %s", file, err)
+		}
+		defer out.Close()
+	}
+	if err := records.Write(out, output.format, users, output.fields); err != nil {
+		return fmt.Errorf("unable to write users: %s", err)
+	}
+	logger.Info("Exported users", "count", len(users))
+	return nil
+}
+
+// bulkImportUsers creates, or with --upsert updates, a user per row of the CSV file, reporting the rows that fail
+func bulkImportUsers(clientConfigs clients.ClientConfigs, file string, flags bulkImportFlags) error {
+	rows, err := readUserRows(file, flags.columns)
+	if err != nil {
+		return err
+	}
+
+	existing := map[string]string{} // ids of the account's users by lowercase email, for --upsert
+	if flags.upsert {
+		users, err := fetchUsers(clientConfigs, nil)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			if email := strings.ToLower(user.Text("email")); email != "" {
+				existing[email] = user.Text("id")
+			}
+		}
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	created, updated := 0, 0
+	jobs := make([]bulk.Job, len(rows))
+	for i, row := range rows {
+		row := row
+		jobs[i] = func() (http.Header, error) {
+			user, err := importedUser(row)
+			if err != nil {
+				return nil, err
+			}
+			email := strings.ToLower(user.Text("email"))
+			if flags.upsert && email == "" {
+				return nil, fmt.Errorf("rows need an email to be upserted")
+			}
+			method, path, action := http.MethodPost, "/api/2/users", "create"
+			if id, ok := existing[email]; ok && flags.upsert {
+				method, path, action = http.MethodPut, "/api/2/users/"+id, "update"
+			}
+			var header http.Header
+			if flags.dryRun {
+				logger.Info("Dry run, would "+action+" user", "user", email)
+			} else {
+				body, err := json.Marshal(user)
+				if err != nil {
+					return nil, err
+				}
+				if _, header, err = api.Do(method, path, nil, body); err != nil {
+					return header, err
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if action == "create" {
+				created++
+			} else {
+				updated++
+			}
+			return header, nil
+		}
+	}
+	errs := runBatches(jobs, flags.batchSize)
+
+	failed := []records.Record{}
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		line := i + 2 // the header is line 1
+		logger.Error("Unable to import row", "line", line, "user", rows[i].Text("email"), "error", err)
+		failure := records.Merge(rows[i], records.Record{"line": line, "error": err.Error()})
+		failed = append(failed, failure)
+	}
+	if flags.dryRun {
+		logger.Info("Dry run, no users were changed", "create", created, "update", updated, "failed", len(failed))
+	} else {
+		logger.Info("Imported users", "created", created, "updated", updated, "failed", len(failed))
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if flags.errorsFile != "" {
+		if err := writeFailedRows(flags.errorsFile, failed); err != nil {
+			return err
+		}
+	}
+	return partial(fmt.Errorf("%d of %d rows failed", len(failed), len(rows)), len(failed), len(rows))
+}
+
+// readUserRows reads the rows of the CSV file with its columns renamed by the --map flags
+func readUserRows(file string, mapFlags []string) ([]records.Record, error) {
+	columns := map[string]string{}
+	for _, column := range mapFlags {
+		index := strings.Index(column, "=")
+		if index < 1 {
+			return nil, fmt.Errorf("invalid --map %s, expected <column>=<field>", column)
+		}
+		columns[column[:index]] = strings.TrimSpace(column[index+1:])
+	}
+	in, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open %s: %s", file, err)
+	}
+	defer in.Close()
+	rows, err := records.ReadCSV(in, columns)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %s", file, err)
+	}
+	return rows, nil
+}
+
+// importedUser is the user a row sets, with read only fields dropped and numbers converted
+func importedUser(row records.Record) (records.Record, error) {
+	user := records.Record{}
+	for field, value := range row {
+		if userReadOnlyFields[field] {
+			continue
+		}
+		if userNumberFields[field] {
+			number := json.Number(row.Text(field))
+			if _, err := number.Int64(); err != nil {
+				return nil, fmt.Errorf("%s must be a number, got %s", field, number)
+			}
+			value = number
+		}
+		user[field] = value
+	}
+	return user, nil
+}
+
+// writeFailedRows writes the rows that failed as CSV with their line and error first, so they can be fixed and
+// imported again with --map line= --map error=
+func writeFailedRows(file string, failed []records.Record) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %s", file, err)
+	}
+	defer out.Close()
+	fields := []string{"line", "error"}
+	for _, field := range records.Fields(failed) {
+		if field != "line" && field != "error" {
+			fields = append(fields, field)
+		}
+	}
+	if err := records.Write(out, records.CSVFormat, failed, fields); err != nil {
+		return fmt.Errorf("unable to write %s: %s", file, err)
+	}
+	logger.Info("Wrote failed rows", "file", file, "count", len(failed))
+	return nil
+}
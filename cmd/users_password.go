@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/deprovision"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/passwords"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// status of users who have to change their password when they next sign in
+const passwordExpiredStatus = 4
+
+// resetPasswordFlags say how to reset a user's password
+type resetPasswordFlags struct {
+	requireChange bool
+	showPassword  bool
+	passwordStdin bool
+	length        int
+	skipPolicy    bool
+	dryRun        bool
+}
+
+// inviteUser sends the user with the email an invite to set their password, or prints the invite link instead
+func inviteUser(clientConfigs clients.ClientConfigs, email string, personalEmail string, printLink bool) error {
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	if printLink {
+		body, _ := json.Marshal(map[string]string{"email": email})
+		data, _, err := api.Do(http.MethodPost, "/api/1/invites/get_invite_link", nil, body)
+		if err != nil {
+			return fmt.Errorf("unable to generate an invite link for %s: %s", email, err)
+		}
+		var response struct {
+			Data []string `json:"data"`
+		}
+		if err := json.Unmarshal(data, &response); err != nil || len(response.Data) == 0 {
+			return fmt.Errorf("unable to read the invite link: %s", data)
+		}
+		fmt.Println(response.Data[0])
+		return nil
+	}
+	request := map[string]string{"email": email}
+	if personalEmail != "" {
+		request["personal_email"] = personalEmail
+	}
+	body, _ := json.Marshal(request)
+	if _, _, err := api.Do(http.MethodPost, "/api/1/invites/send_invite_link", nil, body); err != nil {
+		return fmt.Errorf("unable to send an invite to %s: %s", email, err)
+	}
+	to := email
+	if personalEmail != "" {
+		to = personalEmail
+	}
+	logger.Info("Sent invite", "user", email, "to", to)
+	return nil
+}
+
+// resetPassword sets a temporary password for the user given by id or email, read from stdin or generated, and
+// expires it when the user has to change it
+func resetPassword(clientConfigs clients.ClientConfigs, reference string, flags resetPasswordFlags) error {
+	password := ""
+	if flags.passwordStdin {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("unable to read the password: %s", err)
+		}
+		if password = strings.TrimRight(string(data), "\r\n"); password == "" {
+			return fmt.Errorf("no password given on stdin")
+		}
+	} else {
+		var err error
+		if password, err = passwords.Generate(flags.length); err != nil {
+			return err
+		}
+	}
+	api, err := clients.New(clientConfigs).OneLoginAPI()
+	if err != nil {
+		return err
+	}
+	user, err := deprovision.FindUser(api, reference)
+	if err != nil {
+		return err
+	}
+	id, email := user.Text("id"), user.Text("email")
+	if flags.dryRun {
+		logger.Info("Dry run, password not reset", "id", id, "user", email, "require_change", flags.requireChange)
+		return nil
+	}
+	body, _ := json.Marshal(map[string]interface{}{
+		"password": password, "password_confirmation": password, "validate_policy": !flags.skipPolicy,
+	})
+	if _, _, err := api.Do(http.MethodPut, "/api/1/users/"+id+"/set_password_clear_text", nil, body); err != nil {
+		return fmt.Errorf("unable to reset the password of %s: %s", email, err)
+	}
+	if flags.requireChange {
+		body, _ := json.Marshal(map[string]int{"status": passwordExpiredStatus})
+		if _, _, err := api.Do(http.MethodPut, "/api/2/users/"+id, nil, body); err != nil {
+			return fmt.Errorf("the password of %s was reset, but unable to require changing it: %s", email, err)
+		}
+	}
+	logger.Info("Reset password", "id", id, "user", email, "require_change", flags.requireChange)
+	if flags.showPassword {
+		fmt.Println(password)
+	}
+	return nil
+}
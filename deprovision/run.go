@@ -0,0 +1,176 @@
+package deprovision
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// API sends requests to the OneLogin API, like clients.OneLoginAPI
+type API interface {
+	Do(method string, path string, query url.Values, body []byte) ([]byte, http.Header, error)
+}
+
+// Runner runs workflows through the API, only reading from it when DryRun is set
+type Runner struct {
+	API    API
+	DryRun bool
+	Now    func() time.Time
+}
+
+// FindUser looks the user up by id, or by email when the reference isn't a number
+func FindUser(api API, reference string) (records.Record, error) {
+	path, query := "/api/2/users/"+reference, url.Values(nil)
+	if _, err := strconv.Atoi(reference); err != nil {
+		path, query = "/api/2/users", url.Values{"email": {reference}}
+	}
+	found, err := get(api, path, query)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find user %s: %s", reference, err)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("no user has the email %s", reference)
+	}
+	return found[0], nil
+}
+
+// Run takes the workflow's steps in order for the user, stopping at the first one that fails
+func (r Runner) Run(workflow Workflow, user records.Record) error {
+	id := user.Text("id")
+	roles := map[int]bool{} // roles the user is still in as steps remove them
+	for _, role := range ids(user["role_ids"]) {
+		roles[role] = true
+	}
+	for i, step := range workflow.Steps {
+		var err error
+		switch step.Action {
+		case RemoveRoles:
+			err = r.removeRoles(id, roles, sortedIDs(roles), step.Except)
+		case RemoveApps:
+			err = r.removeApps(id, roles, step.Except)
+		case RevokeSessions:
+			err = r.change("Revoking sessions", http.MethodPut, "/api/1/users/"+id+"/logout", nil, "user", id)
+		case SetStatus:
+			err = r.change("Setting status", http.MethodPut, "/api/2/users/"+id, map[string]int{"status": statuses[step.Status]}, "user", id, "status", step.Status)
+		case Delete:
+			err = r.delete(id, user, step.AfterDays)
+		}
+		if err != nil {
+			return fmt.Errorf("step %d (%s) failed: %s", i+1, step.Action, err)
+		}
+	}
+	return nil
+}
+
+// removeRoles removes the user from the candidate roles they are in, except those to keep
+func (r Runner) removeRoles(id string, roles map[int]bool, candidates []int, except []int) error {
+	keep := map[int]bool{}
+	for _, role := range except {
+		keep[role] = true
+	}
+	userID, _ := strconv.Atoi(id)
+	for _, role := range candidates {
+		if !roles[role] || keep[role] {
+			continue
+		}
+		if err := r.change("Removing user from role", http.MethodDelete, fmt.Sprintf("/api/2/roles/%d/users", role), []int{userID}, "user", id, "role", role); err != nil {
+			return err
+		}
+		delete(roles, role)
+	}
+	return nil
+}
+
+// removeApps removes the user from the roles granting the apps they can access
+func (r Runner) removeApps(id string, roles map[int]bool, except []int) error {
+	apps, err := get(r.API, "/api/2/users/"+id+"/apps", nil)
+	if err != nil {
+		return fmt.Errorf("unable to list the user's apps: %s", err)
+	}
+	granting := map[int]bool{}
+	for _, app := range apps {
+		found, err := get(r.API, "/api/2/apps/"+app.Text("id"), nil)
+		if err != nil {
+			return fmt.Errorf("unable to get app %s: %s", app.Text("id"), err)
+		}
+		for _, role := range ids(found[0]["role_ids"]) {
+			granting[role] = true
+		}
+	}
+	return r.removeRoles(id, roles, sortedIDs(granting), except)
+}
+
+// delete deletes the user, or when afterDays is set, only a user suspended and left alone for that long
+func (r Runner) delete(id string, user records.Record, afterDays int) error {
+	if afterDays > 0 {
+		status, _ := strconv.Atoi(user.Text("status"))
+		updated, err := time.Parse(time.RFC3339, user.Text("updated_at"))
+		due := updated.AddDate(0, 0, afterDays)
+		if status != Suspended || err != nil || r.now().Before(due) {
+			logger.Info("Not deleting the user yet, run the workflow again once suspended for the days given", "user", id, "after_days", afterDays, "due", due.Format("2006-01-02"))
+			return nil
+		}
+	}
+	return r.change("Deleting user", http.MethodDelete, "/api/2/users/"+id, nil, "user", id)
+}
+
+// change sends a request changing the user, or only logs it for dry runs
+func (r Runner) change(message string, method string, path string, body interface{}, keyvals ...interface{}) error {
+	if r.DryRun {
+		logger.Info(message, append(keyvals, "dry_run", true)...)
+		return nil
+	}
+	logger.Info(message, keyvals...)
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	_, _, err := r.API.Do(method, path, nil, data)
+	return err
+}
+
+func (r Runner) now() time.Time {
+	if r.Now == nil {
+		return time.Now()
+	}
+	return r.Now()
+}
+
+// get reads a resource, or a collection, as records
+func get(api API, path string, query url.Values) ([]records.Record, error) {
+	data, _, err := api.Do(http.MethodGet, path, query, nil)
+	if err != nil {
+		return nil, err
+	}
+	return records.FromJSON(data)
+}
+
+// ids reads a field holding a list of ids like role_ids
+func ids(value interface{}) []int {
+	list, _ := value.([]interface{})
+	out := []int{}
+	for _, item := range list {
+		if id, err := strconv.Atoi(fmt.Sprint(item)); err == nil {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func sortedIDs(set map[int]bool) []int {
+	out := []int{}
+	for id := range set {
+		out = append(out, id)
+	}
+	sort.Ints(out)
+	return out
+}
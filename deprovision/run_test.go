@@ -0,0 +1,101 @@
+package deprovision
+
+import (
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// MockAPI answers GETs from Responses by path and records every request
+type MockAPI struct {
+	Responses map[string]string
+	Requests  []string
+}
+
+func (m *MockAPI) Do(method string, path string, query url.Values, body []byte) ([]byte, http.Header, error) {
+	request := method + " " + path
+	if len(query) > 0 {
+		request += "?" + query.Encode()
+	}
+	if body != nil {
+		request += " " + string(body)
+	}
+	m.Requests = append(m.Requests, request)
+	if method == http.MethodGet {
+		return []byte(m.Responses[path]), nil, nil
+	}
+	return nil, nil, nil
+}
+
+func TestRun(t *testing.T) {
+	now := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		Workflow         Workflow
+		User             string
+		DryRun           bool
+		ExpectedRequests []string
+	}{
+		"It runs the default workflow": {
+			Workflow: Default(),
+			User:     `{"id":7,"role_ids":[3,1],"status":1}`,
+			ExpectedRequests: []string{
+				`DELETE /api/2/roles/1/users [7]`,
+				`DELETE /api/2/roles/3/users [7]`,
+				`PUT /api/1/users/7/logout`,
+				`PUT /api/2/users/7 {"status":2}`,
+			},
+		},
+		"It removes the roles granting apps, keeping those excepted by the step": {
+			Workflow: Workflow{Steps: []Step{{Action: RemoveApps, Except: []int{4}}, {Action: RemoveRoles}}},
+			User:     `{"id":7,"role_ids":[1,3,4],"status":1}`,
+			ExpectedRequests: []string{
+				`GET /api/2/users/7/apps`,
+				`GET /api/2/apps/20`,
+				`DELETE /api/2/roles/3/users [7]`,
+				`DELETE /api/2/roles/1/users [7]`,
+				`DELETE /api/2/roles/4/users [7]`,
+			},
+		},
+		"It deletes users suspended for long enough": {
+			Workflow:         Workflow{Steps: []Step{{Action: Delete, AfterDays: 30}}},
+			User:             `{"id":7,"status":2,"updated_at":"2020-01-15T10:00:00.000Z"}`,
+			ExpectedRequests: []string{`DELETE /api/2/users/7`},
+		},
+		"It waits to delete users suspended recently": {
+			Workflow:         Workflow{Steps: []Step{{Action: SetStatus, Status: "suspended"}, {Action: Delete, AfterDays: 30}}},
+			User:             `{"id":7,"status":1,"updated_at":"2020-01-15T10:00:00.000Z"}`,
+			ExpectedRequests: []string{`PUT /api/2/users/7 {"status":2}`},
+		},
+		"It only reads with dry runs": {
+			Workflow:         Workflow{Steps: []Step{{Action: RemoveApps}, {Action: Delete}}},
+			User:             `{"id":7,"role_ids":[3]}`,
+			DryRun:           true,
+			ExpectedRequests: []string{`GET /api/2/users/7/apps`, `GET /api/2/apps/20`},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &MockAPI{Responses: map[string]string{
+				"/api/2/users/7/apps": `[{"id":20}]`,
+				"/api/2/apps/20":      `{"id":20,"role_ids":[3,4]}`,
+			}}
+			user, _ := records.FromJSON([]byte(test.User))
+			runner := Runner{API: api, DryRun: test.DryRun, Now: func() time.Time { return now }}
+			assert.Nil(t, runner.Run(test.Workflow, user[0]))
+			assert.Equal(t, test.ExpectedRequests, api.Requests)
+		})
+	}
+}
+
+func TestFindUser(t *testing.T) {
+	api := &MockAPI{Responses: map[string]string{"/api/2/users": `[]`, "/api/2/users/7": `{"id":7}`}}
+	user, err := FindUser(api, "7")
+	assert.Nil(t, err)
+	assert.Equal(t, "7", user.Text("id"))
+	_, err = FindUser(api, "gone@acme.com")
+	assert.EqualError(t, err, "no user has the email gone@acme.com")
+	assert.Equal(t, []string{"GET /api/2/users/7", "GET /api/2/users?email=gone%40acme.com"}, api.Requests)
+}
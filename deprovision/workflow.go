@@ -0,0 +1,116 @@
+// Package deprovision workflow.go
+// This module offboards a OneLogin user by running the steps of a workflow in order, like removing the user from their
+// roles, revoking their sessions and suspending them. Workflows are YAML documents so a team can review and share how
+// leavers are handled:
+//
+//	steps:
+//	  - action: remove_apps
+//	    except: [123]        # role ids to keep
+//	  - revoke_sessions
+//	  - action: set_status
+//	    status: suspended
+//	  - action: delete
+//	    after_days: 30
+//
+// Deleting after N days
+// The delete step only deletes users that were already suspended and haven't been changed for after_days, so running
+// the workflow again later, e.g. from cron, finishes what an earlier run started.
+package deprovision
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"sort"
+	"strings"
+)
+
+// actions a step can take
+const (
+	RemoveRoles    = "remove_roles"    // remove the user from every role
+	RemoveApps     = "remove_apps"     // remove the user from the roles granting the apps they can access
+	RevokeSessions = "revoke_sessions" // log the user out of every session
+	SetStatus      = "set_status"      // set the user's status e.g. suspended
+	Delete         = "delete"          // delete the user, optionally once suspended for after_days
+)
+
+// statuses set_status accepts, by the user status they stand for
+var statuses = map[string]int{
+	"unactivated": 0,
+	"active":      1,
+	"suspended":   2,
+	"locked":      3,
+}
+
+// Suspended is the status of suspended users
+const Suspended = 2
+
+// Workflow is the steps taken to deprovision a user, in order
+type Workflow struct {
+	Steps []Step `yaml:"steps"`
+}
+
+// Step is one action of a workflow, with the settings of that action
+type Step struct {
+	Action    string `yaml:"action"`
+	Except    []int  `yaml:"except,omitempty"`     // remove_roles and remove_apps: ids of roles to keep
+	Status    string `yaml:"status,omitempty"`     // set_status: unactivated, active, suspended or locked
+	AfterDays int    `yaml:"after_days,omitempty"` // delete: days the user must have been suspended for
+}
+
+// UnmarshalYAML reads steps given as just their action e.g. - revoke_sessions, as well as full steps
+func (s *Step) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var action string
+	if err := unmarshal(&action); err == nil {
+		*s = Step{Action: action}
+		return nil
+	}
+	type step Step // without this method, so the mapping is decoded as usual
+	return unmarshal((*step)(s))
+}
+
+// Default is the workflow run when none is given: the user loses their roles and sessions and is suspended
+func Default() Workflow {
+	return Workflow{Steps: []Step{{Action: RemoveRoles}, {Action: RevokeSessions}, {Action: SetStatus, Status: "suspended"}}}
+}
+
+// Parse reads a workflow written as YAML, reporting unknown actions and settings before anything is run
+func Parse(data []byte) (Workflow, error) {
+	var workflow Workflow
+	if err := yaml.UnmarshalStrict(data, &workflow); err != nil {
+		return workflow, fmt.Errorf("unable to read workflow: %s", err)
+	}
+	if len(workflow.Steps) == 0 {
+		return workflow, fmt.Errorf("the workflow has no steps")
+	}
+	for i, step := range workflow.Steps {
+		if err := step.validate(); err != nil {
+			return workflow, fmt.Errorf("step %d: %s", i+1, err)
+		}
+	}
+	return workflow, nil
+}
+
+func (s Step) validate() error {
+	switch s.Action {
+	case RemoveRoles, RemoveApps, RevokeSessions, Delete:
+	case SetStatus:
+		if _, ok := statuses[s.Status]; !ok {
+			return fmt.Errorf("unknown status %q, expected %s", s.Status, strings.Join(statusNames(), ", "))
+		}
+	default:
+		return fmt.Errorf("unknown action %q, expected %s", s.Action, strings.Join([]string{RemoveRoles, RemoveApps, RevokeSessions, SetStatus, Delete}, ", "))
+	}
+	if s.AfterDays < 0 {
+		return fmt.Errorf("after_days can't be negative")
+	}
+	return nil
+}
+
+func statusNames() []string {
+	names := []string{}
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
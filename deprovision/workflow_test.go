@@ -0,0 +1,51 @@
+package deprovision
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		Input         string
+		Expected      Workflow
+		ExpectedError string
+	}{
+		"It reads steps given by action or in full": {
+			Input: "steps:\n  - revoke_sessions\n  - action: remove_roles\n    except: [3]\n  - action: set_status\n    status: suspended\n  - action: delete\n    after_days: 30\n",
+			Expected: Workflow{Steps: []Step{
+				{Action: RevokeSessions},
+				{Action: RemoveRoles, Except: []int{3}},
+				{Action: SetStatus, Status: "suspended"},
+				{Action: Delete, AfterDays: 30},
+			}},
+		},
+		"It reports unknown actions": {
+			Input:         "steps:\n  - disable\n",
+			ExpectedError: `step 1: unknown action "disable", expected remove_roles, remove_apps, revoke_sessions, set_status, delete`,
+		},
+		"It reports unknown statuses": {
+			Input:         "steps:\n  - action: set_status\n    status: gone\n",
+			ExpectedError: `step 1: unknown status "gone", expected active, locked, suspended, unactivated`,
+		},
+		"It reports unknown settings": {
+			Input:         "steps:\n  - action: delete\n    after: 30\n",
+			ExpectedError: "unable to read workflow: yaml: unmarshal errors:\n  line 3: field after not found in type deprovision.step",
+		},
+		"It reports workflows without steps": {
+			Input:         "steps: []\n",
+			ExpectedError: "the workflow has no steps",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Parse([]byte(test.Input))
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
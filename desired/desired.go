@@ -0,0 +1,158 @@
+// Package desired desired.go
+// This module reads the desired state of a few kinds of resources from YAML files, and plans the changes reconciling
+// an account with it, for GitOps without Terraform. Every .yaml or .yml file of a directory is read, e.g.
+//
+//	roles:
+//	  - name: Engineering
+//	    users: [ann@acme.com, bob@acme.com]   # exact membership, left alone when not given
+//	mappings:
+//	  - name: Contractors
+//	    match: all
+//	    conditions:
+//	      - {source: member_of, operator: "~", value: contractors}
+//	    actions:
+//	      - {action: set_role, value: [Engineering]}   # roles by name
+//	hook_env_vars: [API_KEY]                           # values from the environment variables of the same name
+//
+// Managed types
+// Only the types the files name are reconciled, and for those the account's resources the files don't declare are
+// deleted. Name a type with an empty list, e.g. roles: [], to delete every resource of it.
+package desired
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// types of resources the files declare, in the order they are applied
+const (
+	RolesType       = "roles"
+	MappingsType    = "mappings"
+	HookEnvVarsType = "hook_env_vars"
+)
+
+// Role is a role by name, with its members by email when Users is given
+type Role struct {
+	Name  string    `yaml:"name"`
+	Users *[]string `yaml:"users,omitempty"`
+}
+
+// Condition is a condition of a mapping, as the API takes it
+type Condition struct {
+	Source   string `yaml:"source" json:"source"`
+	Operator string `yaml:"operator" json:"operator"`
+	Value    string `yaml:"value" json:"value"`
+}
+
+// Action is an action of a mapping, as the API takes it except that actions on roles give them by name
+type Action struct {
+	Action string   `yaml:"action" json:"action"`
+	Value  []string `yaml:"value" json:"value"`
+}
+
+// Mapping is a mapping by name
+type Mapping struct {
+	Name       string      `yaml:"name" json:"name"`
+	Match      string      `yaml:"match" json:"match"`
+	Enabled    *bool       `yaml:"enabled,omitempty" json:"enabled"` // true when not given
+	Conditions []Condition `yaml:"conditions" json:"conditions"`
+	Actions    []Action    `yaml:"actions" json:"actions"`
+}
+
+// State is the desired state read from the files. Managed holds the types they name
+type State struct {
+	Roles       []Role
+	Mappings    []Mapping
+	HookEnvVars []string
+	Managed     map[string]bool
+}
+
+// file is a file of the desired state. Types left out of it are nil
+type file struct {
+	Roles       []Role    `yaml:"roles"`
+	Mappings    []Mapping `yaml:"mappings"`
+	HookEnvVars []string  `yaml:"hook_env_vars"`
+}
+
+// Load reads the .yaml and .yml files of the directory, or the file when given one, reporting resources declared
+// twice and mappings with no name or match
+func Load(path string) (State, error) {
+	state := State{Managed: map[string]bool{}}
+	info, err := os.Stat(path)
+	if err != nil {
+		return state, err
+	}
+	files := []string{path}
+	if info.IsDir() {
+		files = []string{}
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, _ := filepath.Glob(filepath.Join(path, pattern))
+			files = append(files, matches...)
+		}
+		sort.Strings(files)
+		if len(files) == 0 {
+			return state, fmt.Errorf("no .yaml or .yml files in %s", path)
+		}
+	}
+	for _, name := range files {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return state, err
+		}
+		var read file
+		if err := yaml.UnmarshalStrict(data, &read); err != nil {
+			return state, fmt.Errorf("unable to read %s: %s", name, err)
+		}
+		if read.Roles != nil {
+			state.Managed[RolesType] = true
+			state.Roles = append(state.Roles, read.Roles...)
+		}
+		if read.Mappings != nil {
+			state.Managed[MappingsType] = true
+			state.Mappings = append(state.Mappings, read.Mappings...)
+		}
+		if read.HookEnvVars != nil {
+			state.Managed[HookEnvVarsType] = true
+			state.HookEnvVars = append(state.HookEnvVars, read.HookEnvVars...)
+		}
+	}
+	return state, state.validate()
+}
+
+// validate reports resources declared twice, or without what they need
+func (s State) validate() error {
+	problems := []string{}
+	check := func(typeName string, names []string) {
+		seen := map[string]bool{}
+		for _, name := range names {
+			if name == "" {
+				problems = append(problems, typeName+" without a name")
+			} else if seen[name] {
+				problems = append(problems, fmt.Sprintf("%s %s declared twice", typeName, name))
+			}
+			seen[name] = true
+		}
+	}
+	roles, mappings := []string{}, []string{}
+	for _, role := range s.Roles {
+		roles = append(roles, role.Name)
+	}
+	for _, mapping := range s.Mappings {
+		mappings = append(mappings, mapping.Name)
+		if mapping.Match == "" {
+			problems = append(problems, fmt.Sprintf("mappings %s without a match", mapping.Name))
+		}
+	}
+	check(RolesType, roles)
+	check(MappingsType, mappings)
+	check(HookEnvVarsType, s.HookEnvVars)
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid desired state: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package desired
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		Files         map[string]string
+		Expected      State
+		ExpectedError string
+	}{
+		"It merges the files and manages the types they name": {
+			Files: map[string]string{
+				"roles.yaml": "roles:\n  - name: Eng\n    users: [ann@acme.com]\n  - name: Sales\n",
+				"hooks.yml":  "hook_env_vars: [API_KEY]\n",
+				"notes.txt":  "not read",
+			},
+			Expected: State{
+				Roles:       []Role{{Name: "Eng", Users: &[]string{"ann@acme.com"}}, {Name: "Sales"}},
+				HookEnvVars: []string{"API_KEY"},
+				Managed:     map[string]bool{RolesType: true, HookEnvVarsType: true},
+			},
+		},
+		"It manages types named with an empty list": {
+			Files:    map[string]string{"mappings.yaml": "mappings: []\n"},
+			Expected: State{Managed: map[string]bool{MappingsType: true}},
+		},
+		"It reports resources declared twice": {
+			Files: map[string]string{
+				"a.yaml": "roles:\n  - name: Eng\n",
+				"b.yaml": "roles:\n  - name: Eng\n",
+			},
+			ExpectedError: "invalid desired state: roles Eng declared twice",
+		},
+		"It reports mappings without a match": {
+			Files:         map[string]string{"a.yaml": "mappings:\n  - name: Contractors\n"},
+			ExpectedError: "invalid desired state: mappings Contractors without a match",
+		},
+		"It reports unknown fields": {
+			Files:         map[string]string{"a.yaml": "groups: []\n"},
+			ExpectedError: "field groups not found",
+		},
+		"It reports directories without files": {
+			ExpectedError: "no .yaml or .yml files",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "desired")
+			assert.Nil(t, err)
+			defer os.RemoveAll(dir)
+			for file, content := range test.Files {
+				assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0600))
+			}
+			actual, err := Load(dir)
+			if test.ExpectedError != "" {
+				assert.Contains(t, err.Error(), test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package desired
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/membership"
+	"github.com/onelogin/onelogin/records"
+	"io"
+	"sort"
+	"strings"
+)
+
+// actions of a Change
+const (
+	Create = "create"
+	Update = "update"
+	Delete = "delete"
+)
+
+// NewRoleID stands in for the id of a role yet to be created, which mappings can't refer to until it is
+const NewRoleID = "(new)"
+
+// Change creates, updates or deletes a resource
+type Change struct {
+	Type   string
+	Name   string
+	Action string
+	ID     string   // of the resource updated or deleted
+	Fields []string // fields updated, sorted
+	Add    []string // emails of the users added to a role
+	Remove []string // emails of the users removed from a role
+	Body   *Mapping // the mapping created or updated, with roles by id
+}
+
+// PlanRoles lists the changes giving the account the desired roles, and the desired members of those listing them.
+// Members are the emails of the users of each role, by role id
+func PlanRoles(desired []Role, live []records.Record, members map[string][]string) []Change {
+	byName := map[string]records.Record{}
+	for _, role := range live {
+		byName[role.Text("name")] = role
+	}
+	declared := map[string]bool{}
+	changes := []Change{}
+	for _, role := range desired {
+		declared[role.Name] = true
+		current, exists := byName[role.Name]
+		want, have := []string{}, []string{}
+		if role.Users != nil {
+			want = *role.Users
+		}
+		if exists {
+			if role.Users == nil {
+				continue
+			}
+			have = members[current.Text("id")]
+		}
+		change := Change{Type: RolesType, Name: role.Name, Action: Create, Add: []string{}, Remove: []string{}}
+		if diff := membership.Diff(map[string][]string{role.Name: want}, map[string][]string{role.Name: have}); len(diff) > 0 {
+			change.Add, change.Remove = diff[0].Add, diff[0].Remove
+		}
+		if exists {
+			if len(change.Add) == 0 && len(change.Remove) == 0 {
+				continue
+			}
+			change.Action, change.ID = Update, current.Text("id")
+		}
+		changes = append(changes, change)
+	}
+	return append(changes, deletions(RolesType, live, declared)...)
+}
+
+// PlanMappings lists the changes giving the account the desired mappings, with roles given by name remapped by their
+// id in roleIDs. Live holds the enabled and disabled mappings
+func PlanMappings(desired []Mapping, live []records.Record, roleIDs map[string]string) ([]Change, error) {
+	byName := map[string]records.Record{}
+	for _, mapping := range live {
+		byName[mapping.Text("name")] = mapping
+	}
+	declared := map[string]bool{}
+	changes := []Change{}
+	for _, mapping := range desired {
+		declared[mapping.Name] = true
+		body, err := mapping.resolve(roleIDs)
+		if err != nil {
+			return nil, err
+		}
+		current, exists := byName[mapping.Name]
+		if !exists {
+			changes = append(changes, Change{Type: MappingsType, Name: mapping.Name, Action: Create, Body: &body})
+			continue
+		}
+		have := liveMapping(current)
+		fields := []string{}
+		for field, same := range map[string]bool{
+			"match":      body.Match == have.Match,
+			"enabled":    *body.Enabled == *have.Enabled,
+			"conditions": sameJSON(body.Conditions, have.Conditions),
+			"actions":    sameJSON(body.Actions, have.Actions),
+		} {
+			if !same {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) > 0 {
+			sort.Strings(fields)
+			changes = append(changes, Change{Type: MappingsType, Name: mapping.Name, Action: Update, ID: current.Text("id"), Fields: fields, Body: &body})
+		}
+	}
+	return append(changes, deletions(MappingsType, live, declared)...), nil
+}
+
+// PlanHookEnvVars lists the changes giving the account the desired hook env vars. Their values can't be read back,
+// so those the account already has are always updated
+func PlanHookEnvVars(desired []string, live []records.Record) []Change {
+	byName := map[string]records.Record{}
+	for _, env := range live {
+		byName[env.Text("name")] = env
+	}
+	declared := map[string]bool{}
+	changes := []Change{}
+	for _, name := range desired {
+		declared[name] = true
+		if current, exists := byName[name]; exists {
+			changes = append(changes, Change{Type: HookEnvVarsType, Name: name, Action: Update, ID: current.Text("id"), Fields: []string{"value"}})
+		} else {
+			changes = append(changes, Change{Type: HookEnvVarsType, Name: name, Action: Create})
+		}
+	}
+	return append(changes, deletions(HookEnvVarsType, live, declared)...)
+}
+
+// WritePlan prints the changes a line each, + for resources created, ~ for those updated and - for those deleted,
+// then their count
+func WritePlan(w io.Writer, changes []Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "The account is up to date.")
+		return
+	}
+	counts := map[string]int{}
+	for _, change := range changes {
+		counts[change.Action]++
+		details := []string{}
+		if len(change.Fields) > 0 {
+			details = append(details, "fields "+strings.Join(change.Fields, ","))
+		}
+		if len(change.Add) > 0 {
+			details = append(details, "add "+strings.Join(change.Add, ","))
+		}
+		if len(change.Remove) > 0 {
+			details = append(details, "remove "+strings.Join(change.Remove, ","))
+		}
+		mark := map[string]string{Create: "+", Update: "~", Delete: "-"}[change.Action]
+		line := fmt.Sprintf("%s %s %s", mark, change.Type, change.Name)
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, "; ") + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "%d to create, %d to update and %d to delete.\n", counts[Create], counts[Update], counts[Delete])
+}
+
+// resolve copies the mapping with the roles its conditions and actions name replaced by their ids, and enabled
+// unless it says otherwise
+func (m Mapping) resolve(roleIDs map[string]string) (Mapping, error) {
+	enabled := m.Enabled == nil || *m.Enabled
+	out := Mapping{Name: m.Name, Match: m.Match, Enabled: &enabled, Conditions: []Condition{}, Actions: []Action{}}
+	missing := []string{}
+	roleID := func(name string) string {
+		id, ok := roleIDs[name]
+		if !ok {
+			missing = append(missing, name)
+		}
+		return id
+	}
+	for _, condition := range m.Conditions {
+		if condition.Source == "has_role" {
+			condition.Value = roleID(condition.Value)
+		}
+		out.Conditions = append(out.Conditions, condition)
+	}
+	for _, action := range m.Actions {
+		if action.Value == nil {
+			action.Value = []string{}
+		}
+		if strings.Contains(action.Action, "role") {
+			ids := make([]string, len(action.Value))
+			for i, name := range action.Value {
+				ids[i] = roleID(name)
+			}
+			action.Value = ids
+		}
+		out.Actions = append(out.Actions, action)
+	}
+	if len(missing) > 0 {
+		return out, fmt.Errorf("mapping %s refers to roles %s, which don't exist", m.Name, strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
+// liveMapping reads a mapping as the API returns it, with values as text whatever their JSON type
+func liveMapping(mapping records.Record) Mapping {
+	enabled := mapping.Text("enabled") == "true"
+	out := Mapping{Name: mapping.Text("name"), Match: mapping.Text("match"), Enabled: &enabled, Conditions: []Condition{}, Actions: []Action{}}
+	conditions, _ := mapping["conditions"].([]interface{})
+	for _, item := range conditions {
+		if object, ok := item.(map[string]interface{}); ok {
+			condition := records.Record(object)
+			out.Conditions = append(out.Conditions, Condition{Source: condition.Text("source"), Operator: condition.Text("operator"), Value: condition.Text("value")})
+		}
+	}
+	actions, _ := mapping["actions"].([]interface{})
+	for _, item := range actions {
+		if object, ok := item.(map[string]interface{}); ok {
+			action := Action{Action: records.Record(object).Text("action"), Value: []string{}}
+			values, _ := object["value"].([]interface{})
+			for _, value := range values {
+				action.Value = append(action.Value, fmt.Sprint(value))
+			}
+			out.Actions = append(out.Actions, action)
+		}
+	}
+	return out
+}
+
+// deletions deletes the resources the desired state doesn't declare, by name
+func deletions(typeName string, live []records.Record, declared map[string]bool) []Change {
+	changes := []Change{}
+	for _, resource := range live {
+		if name := resource.Text("name"); !declared[name] {
+			changes = append(changes, Change{Type: typeName, Name: name, Action: Delete, ID: resource.Text("id")})
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes
+}
+
+func sameJSON(a interface{}, b interface{}) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return string(left) == string(right)
+}
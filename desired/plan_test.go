@@ -0,0 +1,112 @@
+package desired
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlanRoles(t *testing.T) {
+	live := []records.Record{
+		{"id": json.Number("1"), "name": "Eng"},
+		{"id": json.Number("2"), "name": "Sales"},
+		{"id": json.Number("3"), "name": "Ops"},
+	}
+	members := map[string][]string{"1": {"ann@acme.com", "bob@acme.com"}, "2": {"cy@acme.com"}}
+	desired := []Role{
+		{Name: "Eng", Users: &[]string{"Ann@acme.com", "dee@acme.com"}},
+		{Name: "Sales"},
+		{Name: "Support", Users: &[]string{"eve@acme.com"}},
+	}
+	assert.Equal(t, []Change{
+		{Type: RolesType, Name: "Eng", Action: Update, ID: "1", Add: []string{"dee@acme.com"}, Remove: []string{"bob@acme.com"}},
+		{Type: RolesType, Name: "Support", Action: Create, Add: []string{"eve@acme.com"}, Remove: []string{}},
+		{Type: RolesType, Name: "Ops", Action: Delete, ID: "3"},
+	}, PlanRoles(desired, live, members))
+}
+
+func TestPlanMappings(t *testing.T) {
+	live := []records.Record{
+		{"id": json.Number("8"), "name": "Admins", "match": "all", "enabled": true,
+			"conditions": []interface{}{map[string]interface{}{"source": "has_role", "operator": "ri", "value": json.Number("1")}},
+			"actions":    []interface{}{map[string]interface{}{"action": "set_role", "value": []interface{}{"1"}}}},
+		{"id": json.Number("9"), "name": "Contractors", "match": "all", "enabled": false,
+			"conditions": []interface{}{}, "actions": []interface{}{}},
+		{"id": json.Number("10"), "name": "Old", "match": "any", "enabled": true},
+	}
+	disabled := false
+	tests := map[string]struct {
+		Desired       []Mapping
+		RoleIDs       map[string]string
+		Expected      []Change
+		ExpectedError string
+	}{
+		"It plans the changes by name, giving roles by id": {
+			Desired: []Mapping{
+				{Name: "Admins", Match: "all",
+					Conditions: []Condition{{Source: "has_role", Operator: "ri", Value: "Eng"}},
+					Actions:    []Action{{Action: "set_role", Value: []string{"Eng"}}}},
+				{Name: "Contractors", Match: "all", Actions: []Action{{Action: "set_status", Value: []string{"1"}}}},
+				{Name: "New", Match: "any", Enabled: &disabled},
+			},
+			RoleIDs: map[string]string{"Eng": "1"},
+			Expected: []Change{
+				{Type: MappingsType, Name: "Contractors", Action: Update, ID: "9", Fields: []string{"actions", "enabled"}, Body: &Mapping{
+					Name: "Contractors", Match: "all", Enabled: boolPointer(true), Conditions: []Condition{}, Actions: []Action{{Action: "set_status", Value: []string{"1"}}},
+				}},
+				{Type: MappingsType, Name: "New", Action: Create, Body: &Mapping{
+					Name: "New", Match: "any", Enabled: &disabled, Conditions: []Condition{}, Actions: []Action{},
+				}},
+				{Type: MappingsType, Name: "Old", Action: Delete, ID: "10"},
+			},
+		},
+		"It reports roles that don't exist": {
+			Desired:       []Mapping{{Name: "Admins", Match: "all", Actions: []Action{{Action: "add_role", Value: []string{"Eng", "Sales"}}}}},
+			RoleIDs:       map[string]string{"Eng": "1"},
+			ExpectedError: "mapping Admins refers to roles Sales, which don't exist",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := PlanMappings(test.Desired, live, test.RoleIDs)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestPlanHookEnvVars(t *testing.T) {
+	live := []records.Record{{"id": "a", "name": "API_KEY"}, {"id": "b", "name": "OLD"}}
+	assert.Equal(t, []Change{
+		{Type: HookEnvVarsType, Name: "API_KEY", Action: Update, ID: "a", Fields: []string{"value"}},
+		{Type: HookEnvVarsType, Name: "TOKEN", Action: Create},
+		{Type: HookEnvVarsType, Name: "OLD", Action: Delete, ID: "b"},
+	}, PlanHookEnvVars([]string{"API_KEY", "TOKEN"}, live))
+}
+
+func TestWritePlan(t *testing.T) {
+	var out bytes.Buffer
+	WritePlan(&out, []Change{
+		{Type: RolesType, Name: "Eng", Action: Update, Add: []string{"dee@acme.com"}, Remove: []string{"bob@acme.com"}},
+		{Type: MappingsType, Name: "New", Action: Create},
+		{Type: HookEnvVarsType, Name: "OLD", Action: Delete},
+	})
+	assert.Equal(t, "~ roles Eng (add dee@acme.com; remove bob@acme.com)\n"+
+		"+ mappings New\n"+
+		"- hook_env_vars OLD\n"+
+		"1 to create, 1 to update and 1 to delete.\n", out.String())
+
+	out.Reset()
+	WritePlan(&out, nil)
+	assert.Equal(t, "The account is up to date.\n", out.String())
+}
+
+func boolPointer(b bool) *bool {
+	return &b
+}
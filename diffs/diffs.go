@@ -0,0 +1,168 @@
+// Package diffs diffs.go
+// This module prints differences between values for people, as drift, diff and verify find them. Objects and lists
+// are compared down to the values that differ, each printed on a line with its path, e.g. configuration.redirect_uri
+// or role_ids[2], rather than as whole JSON documents.
+//
+// Color
+// Lines are marked + for values added, - for values removed and ~ for values changed, and colored green, red and
+// yellow by their mark on a terminal. NO_COLOR turns color off, as described at https://no-color.org.
+package diffs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/progress"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// marks of lines
+const (
+	Added   = "+"
+	Removed = "-"
+	Changed = "~"
+)
+
+// terminal colors of the marks
+var colors = map[string]string{
+	Added:   "\033[32m",
+	Removed: "\033[31m",
+	Changed: "\033[33m",
+}
+
+const reset = "\033[0m"
+
+// ColorEnabled reports whether lines written to the file are colored: on a terminal, unless NO_COLOR is set
+func ColorEnabled(f *os.File) bool {
+	return os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && progress.IsTerminal(f)
+}
+
+// Line is a value that differs at a path
+type Line struct {
+	Mark string
+	Path string
+	From interface{}
+	To   interface{}
+}
+
+// Compare lists the values that differ between from and to, which are at the path. Objects are compared by key and
+// lists by index, down to the values in them that differ
+func Compare(path string, from interface{}, to interface{}) []Line {
+	from, to = plain(from), plain(to)
+	fromObject, fromIsObject := from.(map[string]interface{})
+	toObject, toIsObject := to.(map[string]interface{})
+	if fromIsObject && toIsObject {
+		keys := []string{}
+		for key := range fromObject {
+			keys = append(keys, key)
+		}
+		for key := range toObject {
+			if _, ok := fromObject[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		lines := []Line{}
+		for _, key := range keys {
+			lines = append(lines, Compare(join(path, key), fromObject[key], toObject[key])...)
+		}
+		return lines
+	}
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList {
+		lines := []Line{}
+		for i := 0; i < len(fromList) || i < len(toList); i++ {
+			var fromValue, toValue interface{}
+			if i < len(fromList) {
+				fromValue = fromList[i]
+			}
+			if i < len(toList) {
+				toValue = toList[i]
+			}
+			lines = append(lines, Compare(path+"["+strconv.Itoa(i)+"]", fromValue, toValue)...)
+		}
+		return lines
+	}
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+	mark := Changed
+	switch {
+	case from == nil:
+		mark = Added
+	case to == nil:
+		mark = Removed
+	}
+	return []Line{{Mark: mark, Path: path, From: from, To: to}}
+}
+
+// plain is the value as JSON reads it, so records and structs compare as objects and numbers compare as written
+func plain(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var out interface{}
+	if err := decoder.Decode(&out); err != nil {
+		return value
+	}
+	return out
+}
+
+func join(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Value is the value as printed, JSON or (none)
+func Value(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// Printer prints lines, colored by their mark when Color is set
+type Printer struct {
+	W     io.Writer
+	Color bool
+}
+
+// Printf prints a line colored by the mark
+func (p Printer) Printf(mark string, format string, args ...interface{}) {
+	text := fmt.Sprintf(format, args...)
+	if color, ok := colors[mark]; ok && p.Color {
+		text = color + text + reset
+	}
+	fmt.Fprintln(p.W, text)
+}
+
+// Lines prints the lines indented, with the value they had and the value they have when changed
+func (p Printer) Lines(indent string, lines []Line) {
+	for _, line := range lines {
+		switch line.Mark {
+		case Added:
+			p.Printf(line.Mark, "%s%s %s: %s", indent, line.Mark, line.Path, Value(line.To))
+		case Removed:
+			p.Printf(line.Mark, "%s%s %s: %s", indent, line.Mark, line.Path, Value(line.From))
+		default:
+			p.Printf(line.Mark, "%s%s %s: %s => %s", indent, line.Mark, line.Path, Value(line.From), Value(line.To))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package diffs
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := map[string]struct {
+		From     interface{}
+		To       interface{}
+		Expected []Line
+	}{
+		"It compares values": {
+			From:     "Old",
+			To:       "New",
+			Expected: []Line{{Mark: Changed, Path: "name", From: "Old", To: "New"}},
+		},
+		"It finds nothing in equal values": {
+			From: map[string]interface{}{"a": []interface{}{1}},
+			To:   map[string]interface{}{"a": []interface{}{1}},
+		},
+		"It compares objects down to the values that differ": {
+			From: map[string]interface{}{"redirect_uri": "https://a", "login_url": "https://b", "scopes": []interface{}{"openid"}},
+			To:   map[string]interface{}{"redirect_uri": "https://c", "access_token_ttl": 60, "scopes": []interface{}{"openid"}},
+			Expected: []Line{
+				{Mark: Added, Path: "name.access_token_ttl", To: json.Number("60")},
+				{Mark: Removed, Path: "name.login_url", From: "https://b"},
+				{Mark: Changed, Path: "name.redirect_uri", From: "https://a", To: "https://c"},
+			},
+		},
+		"It compares lists by index": {
+			From: []interface{}{1, 2},
+			To:   []interface{}{1, 3, 4},
+			Expected: []Line{
+				{Mark: Changed, Path: "name[1]", From: json.Number("2"), To: json.Number("3")},
+				{Mark: Added, Path: "name[2]", To: json.Number("4")},
+			},
+		},
+		"It compares records and maps alike": {
+			From:     map[string]string{"value": "a"},
+			To:       map[string]interface{}{"value": "a"},
+			Expected: nil,
+		},
+		"It reports objects replaced by other values whole": {
+			From:     map[string]interface{}{"a": 1},
+			To:       "a",
+			Expected: []Line{{Mark: Changed, Path: "name", From: map[string]interface{}{"a": json.Number("1")}, To: "a"}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lines := Compare("name", test.From, test.To)
+			if len(test.Expected) == 0 {
+				assert.Empty(t, lines)
+				return
+			}
+			assert.Equal(t, test.Expected, lines)
+		})
+	}
+}
+
+func TestPrinter(t *testing.T) {
+	lines := []Line{
+		{Mark: Added, Path: "notes", To: "a"},
+		{Mark: Removed, Path: "visible", From: true},
+		{Mark: Changed, Path: "name", From: "Old", To: "New"},
+	}
+	tests := map[string]struct {
+		Color    bool
+		Expected string
+	}{
+		"It prints the lines with their values": {
+			Expected: "  + notes: \"a\"\n  - visible: true\n  ~ name: \"Old\" => \"New\"\n",
+		},
+		"It colors the lines by their mark": {
+			Color:    true,
+			Expected: "\033[32m  + notes: \"a\"\033[0m\n\033[31m  - visible: true\033[0m\n\033[33m  ~ name: \"Old\" => \"New\"\033[0m\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			Printer{W: &out, Color: test.Color}.Lines("  ", lines)
+			assert.Equal(t, test.Expected, out.String())
+		})
+	}
+}
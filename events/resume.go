@@ -0,0 +1,36 @@
+package events
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Token records how far an export got, so one that stopped part way can carry on from the next page rather than
+// starting over. The range is kept fixed so the pages after the cursor are the ones the export would have read
+type Token struct {
+	Since     string `json:"since"`
+	Until     string `json:"until"`
+	EventType string `json:"event_type_id,omitempty"`
+	User      string `json:"user_id,omitempty"`
+	Cursor    string `json:"after_cursor"`
+}
+
+// Encode writes the token as a single word to pass back with --resume
+func (t Token) Encode() string {
+	data, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeToken reads a token written by Encode
+func DecodeToken(s string) (Token, error) {
+	var token Token
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(data, &token)
+	}
+	if err != nil || token.Since == "" || token.Until == "" || token.Cursor == "" {
+		return Token{}, fmt.Errorf("invalid resume token %s", s)
+	}
+	return token, nil
+}
@@ -0,0 +1,37 @@
+// Package events since.go
+// This module reads the time ranges the events commands take, and writes events for tools that collect them like
+// SIEMs. Ranges are given as how long ago they start, e.g. 1h or 7d, or as a date or RFC 3339 timestamp.
+package events
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseSince reads when a range starts: a duration before now like 90m, 1h or 7d, a date like 2020-01-31, or an
+// RFC 3339 timestamp like 2020-01-31T09:00:00Z
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+	if at, err := time.Parse("2006-01-02", value); err == nil {
+		return at, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %s, expected a duration like 1h or 7d, a date like 2020-01-31, or an RFC 3339 timestamp", value)
+}
+
+// Format writes a time as the events API takes it
+func Format(at time.Time) string {
+	return at.UTC().Format("2006-01-02T15:04:05.000Z")
+}
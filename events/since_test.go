@@ -0,0 +1,49 @@
+package events
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2020, 3, 10, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		Input         string
+		Expected      time.Time
+		ExpectedError string
+	}{
+		"It reads durations": {
+			Input:    "90m",
+			Expected: time.Date(2020, 3, 10, 10, 30, 0, 0, time.UTC),
+		},
+		"It reads days": {
+			Input:    "7d",
+			Expected: time.Date(2020, 3, 3, 12, 0, 0, 0, time.UTC),
+		},
+		"It reads dates": {
+			Input:    "2020-01-31",
+			Expected: time.Date(2020, 1, 31, 0, 0, 0, 0, time.UTC),
+		},
+		"It reads timestamps": {
+			Input:    "2020-01-31T09:00:00Z",
+			Expected: time.Date(2020, 1, 31, 9, 0, 0, 0, time.UTC),
+		},
+		"It reports anything else": {
+			Input:         "yesterday",
+			ExpectedError: "invalid time yesterday, expected a duration like 1h or 7d, a date like 2020-01-31, or an RFC 3339 timestamp",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseSince(test.Input, now)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.True(t, test.Expected.Equal(actual), "%s is not %s", actual, test.Expected)
+		})
+	}
+	assert.Equal(t, "2020-03-10T12:00:00.000Z", Format(now))
+}
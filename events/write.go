@@ -0,0 +1,122 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// formats events can be exported in
+const (
+	NDJSONFormat = "ndjson"
+	CEFFormat    = "cef"
+)
+
+// defaultSeverity is the CEF severity of events OneLogin gives no risk score
+const defaultSeverity = 3
+
+// cefExtensions are the CEF extension keys events' fields are written as, in order. Custom keys like cs1 are labelled
+// with the field's name
+var cefExtensions = []struct {
+	key, field string
+	custom     bool
+}{
+	{"externalId", "id", false},
+	{"suser", "actor_user_name", false},
+	{"suid", "actor_user_id", false},
+	{"duser", "user_name", false},
+	{"duid", "user_id", false},
+	{"src", "ipaddr", false},
+	{"cs1", "app_name", true},
+	{"msg", "notes", false},
+}
+
+// Writer writes events one after another
+type Writer interface {
+	Write(event records.Record) error
+}
+
+// NewWriter returns a Writer of events in the format: ndjson for a JSON object per line, or cef for a line per event
+// in ArcSight's Common Event Format
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case NDJSONFormat:
+		return ndjsonWriter{w}, nil
+	case CEFFormat:
+		return cefWriter{w}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %s, expected ndjson or cef", format)
+}
+
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (n ndjsonWriter) Write(event records.Record) error {
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(event); err != nil {
+		return err
+	}
+	_, err := n.w.Write(line.Bytes())
+	return err
+}
+
+type cefWriter struct {
+	w io.Writer
+}
+
+func (c cefWriter) Write(event records.Record) error {
+	name := event.Text("event_type")
+	if name == "" {
+		name = event.Text("event_type_id")
+	}
+	extensions := []string{}
+	if at, err := time.Parse(time.RFC3339, event.Text("created_at")); err == nil {
+		extensions = append(extensions, "rt="+strconv.FormatInt(at.UnixNano()/int64(time.Millisecond), 10))
+	}
+	for _, extension := range cefExtensions {
+		value := event.Text(extension.field)
+		if value == "" {
+			continue
+		}
+		if extension.custom {
+			extensions = append(extensions, extension.key+"Label="+extension.field)
+		}
+		extensions = append(extensions, extension.key+"="+escapeExtension(value))
+	}
+	_, err := fmt.Fprintf(c.w, "CEF:0|OneLogin|OneLogin|1|%s|%s|%d|%s\n", escapeHeader(event.Text("event_type_id")),
+		escapeHeader(name), severity(event), strings.Join(extensions, " "))
+	return err
+}
+
+// severity is the CEF severity from 0 to 10 of the event's risk score from 0 to 100
+func severity(event records.Record) int {
+	score, err := strconv.ParseFloat(event.Text("risk_score"), 64)
+	if err != nil {
+		return defaultSeverity
+	}
+	if score < 0 {
+		return 0
+	}
+	if score > 100 {
+		return 10
+	}
+	return int(score / 10)
+}
+
+// escapeHeader escapes the characters CEF reserves in header fields
+func escapeHeader(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r", " ", "\n", " ").Replace(s)
+}
+
+// escapeExtension escapes the characters CEF reserves in extension values
+func escapeExtension(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "=", `\=`, "\r", `\r`, "\n", `\n`).Replace(s)
+}
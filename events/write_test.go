@@ -0,0 +1,102 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	login := records.Record{
+		"id":              json.Number("100"),
+		"created_at":      "2020-01-31T09:00:00.000Z",
+		"event_type_id":   json.Number("5"),
+		"event_type":      "USER_LOGGED_INTO_ONELOGIN",
+		"actor_user_name": "Ann",
+		"user_name":       "Ann",
+		"user_id":         json.Number("7"),
+		"ipaddr":          "1.2.3.4",
+		"app_name":        nil,
+		"notes":           "a=b\nc",
+	}
+	tests := map[string]struct {
+		Format        string
+		Events        []records.Record
+		Expected      string
+		ExpectedError string
+	}{
+		"It writes a JSON object per line": {
+			Format:   NDJSONFormat,
+			Events:   []records.Record{{"id": json.Number("1"), "notes": "<b>"}, {"id": json.Number("2")}},
+			Expected: "{\"id\":1,\"notes\":\"<b>\"}\n{\"id\":2}\n",
+		},
+		"It writes CEF with the fields events have": {
+			Format:   CEFFormat,
+			Events:   []records.Record{login},
+			Expected: "CEF:0|OneLogin|OneLogin|1|5|USER_LOGGED_INTO_ONELOGIN|3|rt=1580461200000 externalId=100 suser=Ann duser=Ann duid=7 src=1.2.3.4 msg=a\\=b\\nc\n",
+		},
+		"It labels custom CEF fields and scales risk scores to severities": {
+			Format:   CEFFormat,
+			Events:   []records.Record{{"event_type_id": json.Number("8"), "app_name": "Sales|Force", "risk_score": json.Number("85")}},
+			Expected: "CEF:0|OneLogin|OneLogin|1|8|8|8|cs1Label=app_name cs1=Sales|Force\n",
+		},
+		"It escapes CEF headers": {
+			Format:   CEFFormat,
+			Events:   []records.Record{{"event_type_id": json.Number("9"), "event_type": `A|B\C`}},
+			Expected: "CEF:0|OneLogin|OneLogin|1|9|A\\|B\\\\C|3|\n",
+		},
+		"It reports other formats": {
+			Format:        "xml",
+			ExpectedError: "unsupported format xml, expected ndjson or cef",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			writer, err := NewWriter(&out, test.Format)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			for _, event := range test.Events {
+				assert.Nil(t, writer.Write(event))
+			}
+			assert.Equal(t, test.Expected, out.String())
+		})
+	}
+}
+
+func TestToken(t *testing.T) {
+	tests := map[string]struct {
+		Input         string
+		Expected      Token
+		ExpectedError string
+	}{
+		"It reads encoded tokens": {
+			Input:    Token{Since: "2020-01-30T09:00:00.000Z", Until: "2020-01-31T09:00:00.000Z", EventType: "5", Cursor: "abc"}.Encode(),
+			Expected: Token{Since: "2020-01-30T09:00:00.000Z", Until: "2020-01-31T09:00:00.000Z", EventType: "5", Cursor: "abc"},
+		},
+		"It reports tokens that aren't encoded": {
+			Input:         "abc!",
+			ExpectedError: "invalid resume token abc!",
+		},
+		"It reports tokens without a cursor": {
+			Input:         Token{Since: "2020-01-30T09:00:00.000Z", Until: "2020-01-31T09:00:00.000Z"}.Encode(),
+			ExpectedError: "invalid resume token eyJzaW5jZSI6IjIwMjAtMDEtMzBUMDk6MDA6MDAuMDAwWiIsInVudGlsIjoiMjAyMC0wMS0zMVQwOTowMDowMC4wMDBaIiwiYWZ0ZXJfY3Vyc29yIjoiIn0",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := DecodeToken(test.Input)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
@@ -4,10 +4,16 @@ go 1.13
 
 require (
 	github.com/aws/aws-sdk-go v1.34.0
+	github.com/godbus/dbus/v5 v5.1.0 // indirect
+	github.com/hashicorp/hcl/v2 v2.8.2
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/okta/okta-sdk-golang/v2 v2.0.0 // indirect
 	github.com/onelogin/onelogin-go-sdk v1.0.11
 	github.com/spf13/cobra v1.0.0
+	github.com/spf13/pflag v1.0.3
 	github.com/spf13/viper v1.4.0
 	github.com/stretchr/testify v1.5.1
+	github.com/zalando/go-keyring v0.2.1
+	github.com/zclconf/go-cty v1.2.0
+	gopkg.in/yaml.v2 v2.4.0
 )
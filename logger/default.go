@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"os"
+)
+
+// std is the logger of the package level functions, writing text to stderr until the CLI configures it
+var std, _ = New(os.Stderr, TextFormat)
+
+// Default returns the logger the package level functions write to
+func Default() *Logger {
+	return std
+}
+
+// SetDefault replaces the logger the package level functions write to
+func SetDefault(l *Logger) {
+	std = l
+}
+
+// Debug logs to the default logger
+func Debug(msg string, keyvals ...interface{}) {
+	std.log(DebugLevel, msg, keyvals)
+}
+
+// Info logs to the default logger
+func Info(msg string, keyvals ...interface{}) {
+	std.log(InfoLevel, msg, keyvals)
+}
+
+// Warn logs to the default logger
+func Warn(msg string, keyvals ...interface{}) {
+	std.log(WarnLevel, msg, keyvals)
+}
+
+// Error logs to the default logger
+func Error(msg string, keyvals ...interface{}) {
+	std.log(ErrorLevel, msg, keyvals)
+}
+
+// Fatal logs to the default logger and exits with status 1
+func Fatal(msg string, keyvals ...interface{}) {
+	std.Fatal(msg, keyvals...)
+}
+
+// FatalCode logs to the default logger and exits with the code
+func FatalCode(code int, msg string, keyvals ...interface{}) {
+	std.FatalCode(code, msg, keyvals...)
+}
@@ -0,0 +1,204 @@
+// Package logger logger.go
+// This module is the leveled, structured logger the CLI reports progress and errors with. Messages carry key value
+// pairs instead of formatted text so they can be written for people, or as one JSON object per line for CI systems
+// and log aggregators.
+//
+// Logging
+// Call the package level functions with a message and alternating keys and values e.g.
+// logger.Info("Importing resource", "address", address, "index", i). Command results that are the output of a
+// command, like a generated main.tf or a JSON report, are not logged; they go to stdout.
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level orders messages by severity. Messages below the logger's level are dropped
+type Level int
+
+// levels from least to most severe
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+)
+
+func (l Level) String() string {
+	return [...]string{"debug", "info", "warn", "error", "fatal"}[l]
+}
+
+// formats messages can be written in
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
+// Logger writes leveled messages with their fields to out
+type Logger struct {
+	mu     sync.Mutex
+	out    io.Writer
+	format string
+	level  Level
+	now    func() time.Time
+	exit   func(code int)
+}
+
+// New creates a logger writing messages of InfoLevel and above in the format
+func New(out io.Writer, format string) (*Logger, error) {
+	if format != TextFormat && format != JSONFormat {
+		return nil, fmt.Errorf("unsupported log format %s, expected text or json", format)
+	}
+	return &Logger{out: out, format: format, level: InfoLevel, now: time.Now, exit: os.Exit}, nil
+}
+
+// SetLevel drops messages below the level
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// SetOutput writes messages to out from now on, returning where they were written so far
+func (l *Logger) SetOutput(out io.Writer) io.Writer {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	previous := l.out
+	l.out = out
+	return previous
+}
+
+// Debug logs details only wanted when diagnosing a problem
+func (l *Logger) Debug(msg string, keyvals ...interface{}) {
+	l.log(DebugLevel, msg, keyvals)
+}
+
+// Info logs progress
+func (l *Logger) Info(msg string, keyvals ...interface{}) {
+	l.log(InfoLevel, msg, keyvals)
+}
+
+// Warn logs problems the command works around
+func (l *Logger) Warn(msg string, keyvals ...interface{}) {
+	l.log(WarnLevel, msg, keyvals)
+}
+
+// Error logs problems that fail part of the command
+func (l *Logger) Error(msg string, keyvals ...interface{}) {
+	l.log(ErrorLevel, msg, keyvals)
+}
+
+// Fatal logs a problem the command can't go on after and exits with status 1
+func (l *Logger) Fatal(msg string, keyvals ...interface{}) {
+	l.log(FatalLevel, msg, keyvals)
+	l.exit(1)
+}
+
+// FatalCode is Fatal exiting with the code instead, for callers that tell failures apart by exit code
+func (l *Logger) FatalCode(code int, msg string, keyvals ...interface{}) {
+	l.log(FatalLevel, msg, keyvals)
+	l.exit(code)
+}
+
+// Writer adapts the logger for the standard library's log package, and anything else writing lines of text. Every
+// line is logged as a message at the level
+func (l *Logger) Writer(level Level) io.Writer {
+	return lineWriter{logger: l, level: level}
+}
+
+type lineWriter struct {
+	logger *Logger
+	level  Level
+}
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		w.logger.log(w.level, line, nil)
+	}
+	return len(p), nil
+}
+
+func (l *Logger) log(level Level, msg string, keyvals []interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < l.level {
+		return
+	}
+	var line bytes.Buffer
+	if l.format == JSONFormat {
+		writeJSON(&line, l.now(), level, msg, keyvals)
+	} else {
+		writeText(&line, l.now(), level, msg, keyvals)
+	}
+	l.out.Write(line.Bytes())
+}
+
+// writeText writes the message like the standard library's log package, with the level when it isn't info and the
+// fields as key=value
+func writeText(w *bytes.Buffer, now time.Time, level Level, msg string, keyvals []interface{}) {
+	w.WriteString(now.Format("2006/01/02 15:04:05 "))
+	if level != InfoLevel {
+		w.WriteString(strings.ToUpper(level.String()) + " ")
+	}
+	w.WriteString(msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, value := field(keyvals, i)
+		text := fmt.Sprint(value)
+		if text == "" || strings.ContainsAny(text, " \t\n\"=") {
+			text = fmt.Sprintf("%q", text)
+		}
+		fmt.Fprintf(w, " %s=%s", key, text)
+	}
+	w.WriteByte('\n')
+}
+
+// writeJSON writes the message as one JSON object with time, level, msg, and the fields
+func writeJSON(w *bytes.Buffer, now time.Time, level Level, msg string, keyvals []interface{}) {
+	w.WriteString(`{"time":`)
+	writeJSONValue(w, now.Format(time.RFC3339))
+	w.WriteString(`,"level":`)
+	writeJSONValue(w, level.String())
+	w.WriteString(`,"msg":`)
+	writeJSONValue(w, msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, value := field(keyvals, i)
+		w.WriteByte(',')
+		writeJSONValue(w, key)
+		w.WriteByte(':')
+		writeJSONValue(w, value)
+	}
+	w.WriteString("}\n")
+}
+
+func writeJSONValue(w *bytes.Buffer, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(value))
+	}
+	w.Write(data)
+}
+
+// field reads the key and value at i, naming a value without a key !BADKEY like log/slog. Errors and Stringers are
+// logged as their text, as their fields are rarely useful
+func field(keyvals []interface{}, i int) (string, interface{}) {
+	key, ok := keyvals[i].(string)
+	if !ok || i+1 == len(keyvals) {
+		return "!BADKEY", keyvals[i]
+	}
+	switch value := keyvals[i+1].(type) {
+	case error:
+		return key, value.Error()
+	case fmt.Stringer:
+		return key, value.String()
+	default:
+		return key, value
+	}
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"log"
+	"testing"
+	"time"
+)
+
+func TestLogger(t *testing.T) {
+	tests := map[string]struct {
+		Format   string
+		Level    Level
+		Log      func(l *Logger)
+		Expected string
+	}{
+		"It writes text like the log package": {
+			Format:   TextFormat,
+			Log:      func(l *Logger) { l.Info("Importing resource", "index", 1, "address", "onelogin_apps.wiki") },
+			Expected: "2020/03/04 05:06:07 Importing resource index=1 address=onelogin_apps.wiki\n",
+		},
+		"It names levels other than info and quotes values with spaces": {
+			Format: TextFormat,
+			Log: func(l *Logger) {
+				l.Warn("Unable to read provider schemas", "error", errors.New("exit status 1"), "empty", "")
+			},
+			Expected: "2020/03/04 05:06:07 WARN Unable to read provider schemas error=\"exit status 1\" empty=\"\"\n",
+		},
+		"It writes json": {
+			Format: JSONFormat,
+			Log: func(l *Logger) {
+				l.Error("problem executing terraform init", "error", errors.New("exit status 1"), "count", 2, "wait", time.Second)
+			},
+			Expected: `{"time":"2020-03-04T05:06:07Z","level":"error","msg":"problem executing terraform init","error":"exit status 1","count":2,"wait":"1s"}` + "\n",
+		},
+		"It flags values without keys": {
+			Format:   JSONFormat,
+			Log:      func(l *Logger) { l.Info("Using profile", "prod") },
+			Expected: `{"time":"2020-03-04T05:06:07Z","level":"info","msg":"Using profile","!BADKEY":"prod"}` + "\n",
+		},
+		"It drops messages below the level": {
+			Format: TextFormat,
+			Level:  WarnLevel,
+			Log: func(l *Logger) {
+				l.Debug("request", "status", 200)
+				l.Info("Importing resource")
+				l.Error("failed")
+			},
+			Expected: "2020/03/04 05:06:07 ERROR failed\n",
+		},
+		"It logs lines written by the log package": {
+			Format: JSONFormat,
+			Log: func(l *Logger) {
+				std := log.New(l.Writer(WarnLevel), "", 0)
+				std.Println("HTTP Transport Error")
+			},
+			Expected: `{"time":"2020-03-04T05:06:07Z","level":"warn","msg":"HTTP Transport Error"}` + "\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			l, err := New(&out, test.Format)
+			assert.Nil(t, err)
+			l.now = func() time.Time { return time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC) }
+			l.SetLevel(test.Level)
+			test.Log(l)
+			assert.Equal(t, test.Expected, out.String())
+		})
+	}
+}
+
+func TestFatal(t *testing.T) {
+	var out bytes.Buffer
+	l, _ := New(&out, TextFormat)
+	l.now = func() time.Time { return time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC) }
+	code := -1
+	l.exit = func(c int) { code = c }
+	l.Fatal("Profile does not exist!", "name", "prod")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "2020/03/04 05:06:07 FATAL Profile does not exist! name=prod\n", out.String())
+}
+
+func TestFatalCode(t *testing.T) {
+	var out bytes.Buffer
+	l, _ := New(&out, TextFormat)
+	l.now = func() time.Time { return time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC) }
+	code := -1
+	l.exit = func(c int) { code = c }
+	l.FatalCode(3, "token request was rejected with 401 Unauthorized")
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "2020/03/04 05:06:07 FATAL token request was rejected with 401 Unauthorized\n", out.String())
+}
+
+func TestSetOutput(t *testing.T) {
+	var first, second bytes.Buffer
+	l, _ := New(&first, TextFormat)
+	l.now = func() time.Time { return time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC) }
+	previous := l.SetOutput(&second)
+	l.Info("Importing resource")
+	assert.Equal(t, &first, previous)
+	assert.Empty(t, first.String())
+	assert.Equal(t, "2020/03/04 05:06:07 Importing resource\n", second.String())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(&bytes.Buffer{}, "xml")
+	assert.EqualError(t, err, "unsupported log format xml, expected text or json")
+}
@@ -0,0 +1,68 @@
+// Package mappings order.go
+// This module works out the new order of the account's enabled mappings from a positions file listing them, by id
+// or by name, one per line in the order they should run. Blank lines and lines starting with # are skipped. Mappings
+// the file leaves out keep their order after the ones it lists, so a file can move a few mappings to the top.
+package mappings
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ReadPositions reads the lines of a positions file naming mappings, in order
+func ReadPositions(r io.Reader) ([]string, error) {
+	out := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			out = append(out, line)
+		}
+	}
+	return out, scanner.Err()
+}
+
+// Order is the ids of the mappings, listed in the order they currently run, with those named by the positions first.
+// Also returns the mappings the positions left out
+func Order(mappings []records.Record, positions []string) ([]int, []records.Record, error) {
+	byRef := map[string]int{}
+	for i, mapping := range mappings {
+		if previous, ok := byRef[mapping.Text("name")]; ok && previous != i {
+			byRef[mapping.Text("name")] = -1 // names given twice can't be told apart
+		} else {
+			byRef[mapping.Text("name")] = i
+		}
+	}
+	for i, mapping := range mappings {
+		byRef[mapping.Text("id")] = i
+	}
+	placed := map[int]bool{}
+	order := []int{}
+	for _, position := range positions {
+		index, ok := byRef[position]
+		switch {
+		case !ok:
+			return nil, nil, fmt.Errorf("no enabled mapping is named or has the id %s", position)
+		case index == -1:
+			return nil, nil, fmt.Errorf("several mappings are named %s, give its id instead", position)
+		case placed[index]:
+			return nil, nil, fmt.Errorf("mapping %s is listed more than once", position)
+		}
+		placed[index] = true
+		id, _ := strconv.Atoi(mappings[index].Text("id"))
+		order = append(order, id)
+	}
+	left := []records.Record{}
+	for i, mapping := range mappings {
+		if !placed[i] {
+			id, _ := strconv.Atoi(mapping.Text("id"))
+			order = append(order, id)
+			left = append(left, mapping)
+		}
+	}
+	return order, left, nil
+}
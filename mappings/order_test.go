@@ -0,0 +1,54 @@
+package mappings
+
+import (
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestReadPositions(t *testing.T) {
+	positions, err := ReadPositions(strings.NewReader("# most specific first\n12\n\n  Contractors \n"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"12", "Contractors"}, positions)
+}
+
+func TestOrder(t *testing.T) {
+	mappings, _ := records.FromJSON([]byte(`[{"id":10,"name":"Everyone"},{"id":11,"name":"Contractors"},{"id":12,"name":"Admins"},{"id":13,"name":"Admins"}]`))
+	tests := map[string]struct {
+		Positions     []string
+		Expected      []int
+		ExpectedLeft  int
+		ExpectedError string
+	}{
+		"It puts the mappings listed first, by id or name": {
+			Positions:    []string{"12", "Contractors"},
+			Expected:     []int{12, 11, 10, 13},
+			ExpectedLeft: 2,
+		},
+		"It reports mappings that don't exist": {
+			Positions:     []string{"Nobody"},
+			ExpectedError: "no enabled mapping is named or has the id Nobody",
+		},
+		"It reports names given to several mappings": {
+			Positions:     []string{"Admins"},
+			ExpectedError: "several mappings are named Admins, give its id instead",
+		},
+		"It reports mappings listed twice": {
+			Positions:     []string{"11", "Contractors"},
+			ExpectedError: "mapping Contractors is listed more than once",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			order, left, err := Order(mappings, test.Positions)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, order)
+			assert.Len(t, left, test.ExpectedLeft)
+		})
+	}
+}
@@ -0,0 +1,83 @@
+// Package membership membership.go
+// This module reconciles who belongs to roles with a desired membership, e.g. one read from a CSV of role,email rows,
+// by working out who to add to and remove from each role. Roles missing from the desired membership are left alone,
+// so a file can manage some roles without touching the others.
+package membership
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Change is who to add to and remove from a role, by email
+type Change struct {
+	Role   string
+	Add    []string
+	Remove []string
+}
+
+// Diff works out the changes bringing the current members of each role in desired to the desired ones. Emails are
+// compared ignoring case, and roles whose membership is already right are left out
+func Diff(desired map[string][]string, current map[string][]string) []Change {
+	roles := []string{}
+	for role := range desired {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	changes := []Change{}
+	for _, role := range roles {
+		want, have := set(desired[role]), set(current[role])
+		change := Change{Role: role, Add: []string{}, Remove: []string{}}
+		for email := range want {
+			if !have[email] {
+				change.Add = append(change.Add, email)
+			}
+		}
+		for email := range have {
+			if !want[email] {
+				change.Remove = append(change.Remove, email)
+			}
+		}
+		if len(change.Add) > 0 || len(change.Remove) > 0 {
+			sort.Strings(change.Add)
+			sort.Strings(change.Remove)
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// WritePreview writes the changes like a diff: the role, then + for members added and - for members removed
+func WritePreview(w io.Writer, changes []Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "Membership is up to date.")
+		return
+	}
+	for _, change := range changes {
+		fmt.Fprintf(w, "%s\n", change.Role)
+		for _, email := range change.Add {
+			fmt.Fprintf(w, "  + %s\n", email)
+		}
+		for _, email := range change.Remove {
+			fmt.Fprintf(w, "  - %s\n", email)
+		}
+	}
+	added, removed := 0, 0
+	for _, change := range changes {
+		added += len(change.Add)
+		removed += len(change.Remove)
+	}
+	fmt.Fprintf(w, "%d to add and %d to remove across %d roles.\n", added, removed, len(changes))
+}
+
+func set(emails []string) map[string]bool {
+	out := map[string]bool{}
+	for _, email := range emails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			out[email] = true
+		}
+	}
+	return out
+}
@@ -0,0 +1,40 @@
+package membership
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	tests := map[string]struct {
+		Desired  map[string][]string
+		Current  map[string][]string
+		Expected []Change
+	}{
+		"It adds and removes members": {
+			Desired:  map[string][]string{"Engineering": {"ann@acme.com", "Bo@acme.com"}},
+			Current:  map[string][]string{"Engineering": {"bo@acme.com", "cy@acme.com"}},
+			Expected: []Change{{Role: "Engineering", Add: []string{"ann@acme.com"}, Remove: []string{"cy@acme.com"}}},
+		},
+		"It leaves out roles that are up to date or not desired": {
+			Desired:  map[string][]string{"Sales": {"ann@acme.com"}, "Support": {}},
+			Current:  map[string][]string{"Sales": {"ann@acme.com"}, "Engineering": {"bo@acme.com"}, "Support": {"cy@acme.com"}},
+			Expected: []Change{{Role: "Support", Add: []string{}, Remove: []string{"cy@acme.com"}}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Diff(test.Desired, test.Current))
+		})
+	}
+}
+
+func TestWritePreview(t *testing.T) {
+	var out bytes.Buffer
+	WritePreview(&out, []Change{{Role: "Engineering", Add: []string{"ann@acme.com"}, Remove: []string{"cy@acme.com"}}})
+	assert.Equal(t, "Engineering\n  + ann@acme.com\n  - cy@acme.com\n1 to add and 1 to remove across 1 roles.\n", out.String())
+	out.Reset()
+	WritePreview(&out, nil)
+	assert.Equal(t, "Membership is up to date.\n", out.String())
+}
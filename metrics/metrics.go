@@ -0,0 +1,128 @@
+// Package metrics metrics.go
+// This module keeps a local record of the commands run, how long they took and how many API requests they made, so
+// users can see why a nightly import slowed down. It is opt-in with --metrics and nothing leaves the machine: runs are
+// appended as JSON lines to a file next to the profiles, and summarized by command with onelogin stats.
+package metrics
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// Run is a command run
+type Run struct {
+	Command     string    `json:"command"` // the command path e.g. terraform-import onelogin_apps
+	Started     time.Time `json:"started"`
+	Seconds     float64   `json:"seconds"`
+	APIRequests int64     `json:"api_requests"`
+	ExitCode    int       `json:"exit_code"`
+}
+
+// NewRun is the run of the command started at the time, taking until now
+func NewRun(command string, started time.Time, apiRequests int64, exitCode int) Run {
+	return Run{
+		Command:     command,
+		Started:     started.UTC().Truncate(time.Second),
+		Seconds:     round(time.Since(started).Seconds(), 3),
+		APIRequests: apiRequests,
+		ExitCode:    exitCode,
+	}
+}
+
+// Append adds the run to the end of the file, creating it readable by the user only
+func Append(path string, run Run) error {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read reads the runs in the file, none when there is no file yet
+func Read(path string) ([]Run, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []Run{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	runs := []Run{}
+	lines := bufio.NewScanner(file)
+	for line := 1; lines.Scan(); line++ {
+		if len(lines.Bytes()) == 0 {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(lines.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("unable to read line %d of %s: %s", line, path, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, lines.Err()
+}
+
+// Stats summarize the runs of a command. The last run's figures are next to the averages, to spot one slower than usual
+type Stats struct {
+	Command            string    `json:"command"`
+	Runs               int       `json:"runs"`
+	Failed             int       `json:"failed"`
+	AverageSeconds     float64   `json:"average_seconds"`
+	MaxSeconds         float64   `json:"max_seconds"`
+	LastSeconds        float64   `json:"last_seconds"`
+	AverageAPIRequests float64   `json:"average_api_requests"`
+	LastAPIRequests    int64     `json:"last_api_requests"`
+	LastRun            time.Time `json:"last_run"`
+}
+
+// Summarize summarizes the runs by command, sorted by command
+func Summarize(runs []Run) []Stats {
+	byCommand := map[string]*Stats{}
+	totals := map[string]struct{ seconds, apiRequests float64 }{}
+	for _, run := range runs {
+		stats, ok := byCommand[run.Command]
+		if !ok {
+			stats = &Stats{Command: run.Command}
+			byCommand[run.Command] = stats
+		}
+		stats.Runs++
+		if run.ExitCode != 0 {
+			stats.Failed++
+		}
+		stats.MaxSeconds = math.Max(stats.MaxSeconds, run.Seconds)
+		if !run.Started.Before(stats.LastRun) {
+			stats.LastRun, stats.LastSeconds, stats.LastAPIRequests = run.Started, run.Seconds, run.APIRequests
+		}
+		total := totals[run.Command]
+		total.seconds += run.Seconds
+		total.apiRequests += float64(run.APIRequests)
+		totals[run.Command] = total
+	}
+	out := []Stats{}
+	for command, stats := range byCommand {
+		stats.AverageSeconds = round(totals[command].seconds/float64(stats.Runs), 3)
+		stats.AverageAPIRequests = round(totals[command].apiRequests/float64(stats.Runs), 1)
+		out = append(out, *stats)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Command < out[j].Command })
+	return out
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "metrics.jsonl")
+
+	runs, err := Read(path)
+	assert.Nil(t, err)
+	assert.Empty(t, runs, "there are no runs before the file is written")
+
+	started := time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)
+	assert.Nil(t, Append(path, Run{Command: "terraform-import onelogin_apps", Started: started, Seconds: 61.5, APIRequests: 40}))
+	assert.Nil(t, Append(path, Run{Command: "users list", Started: started.Add(time.Hour), Seconds: 2, APIRequests: 3, ExitCode: 3}))
+
+	runs, err = Read(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []Run{
+		{Command: "terraform-import onelogin_apps", Started: started, Seconds: 61.5, APIRequests: 40},
+		{Command: "users list", Started: started.Add(time.Hour), Seconds: 2, APIRequests: 3, ExitCode: 3},
+	}, runs)
+
+	info, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("{\"command\":\"users list\"}\nnot json\n"), 0600))
+	_, err = Read(path)
+	assert.Contains(t, err.Error(), "unable to read line 2 of")
+}
+
+func TestSummarize(t *testing.T) {
+	night := time.Date(2024, 6, 1, 2, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		Runs     []Run
+		Expected []Stats
+	}{
+		"It summarizes nothing": {
+			Runs:     []Run{},
+			Expected: []Stats{},
+		},
+		"It summarizes runs by command": {
+			Runs: []Run{
+				{Command: "terraform-import onelogin_apps", Started: night, Seconds: 60, APIRequests: 40},
+				{Command: "terraform-import onelogin_apps", Started: night.Add(48 * time.Hour), Seconds: 240, APIRequests: 120, ExitCode: 4},
+				{Command: "terraform-import onelogin_apps", Started: night.Add(24 * time.Hour), Seconds: 90, APIRequests: 41},
+				{Command: "apps list", Started: night, Seconds: 1.25, APIRequests: 2},
+			},
+			Expected: []Stats{
+				{Command: "apps list", Runs: 1, AverageSeconds: 1.25, MaxSeconds: 1.25, LastSeconds: 1.25, AverageAPIRequests: 2, LastAPIRequests: 2, LastRun: night},
+				{Command: "terraform-import onelogin_apps", Runs: 3, Failed: 1, AverageSeconds: 130, MaxSeconds: 240, LastSeconds: 240, AverageAPIRequests: 67, LastAPIRequests: 120, LastRun: night.Add(48 * time.Hour)},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Summarize(test.Runs))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+// Package oidc revoke.go
+// This module revokes the access and refresh tokens OneLogin issued through an OIDC app, at the revocation endpoint
+// of the app's issuer as RFC 7009 describes. Apps with a client secret authenticate with it, and apps without one,
+// like those using PKCE, send just their client id.
+package oidc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// hints telling the revocation endpoint what kind of token it is given
+const (
+	AccessToken  = "access_token"
+	RefreshToken = "refresh_token"
+)
+
+// HTTPClient sends the revocation requests, like an *http.Client
+type HTTPClient interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+// App is an OIDC app tokens were issued through
+type App struct {
+	Issuer       string // e.g. https://acme.onelogin.com/oidc/2
+	ClientID     string
+	ClientSecret string // empty for apps without one
+}
+
+// Revoke revokes the token, with the hint when given. Tokens that are already revoked or expired revoke without error
+func (a App) Revoke(client HTTPClient, token string, hint string) error {
+	form := url.Values{"token": {token}}
+	if hint != "" {
+		form.Set("token_type_hint", hint)
+	}
+	if a.ClientSecret == "" {
+		form.Set("client_id", a.ClientID)
+	}
+	request, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(a.Issuer, "/")+"/token/revocation", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if a.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("revocation was rejected with %s: %s", response.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package oidc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRevoke(t *testing.T) {
+	tests := map[string]struct {
+		App           App
+		Hint          string
+		Status        int
+		ExpectedForm  map[string]string
+		ExpectedUser  string
+		ExpectedError string
+	}{
+		"It authenticates with the client secret": {
+			App:          App{ClientID: "client", ClientSecret: "s3cr&t"},
+			Hint:         RefreshToken,
+			Status:       http.StatusOK,
+			ExpectedForm: map[string]string{"token": "abc", "token_type_hint": "refresh_token", "client_id": ""},
+			ExpectedUser: "client",
+		},
+		"It sends the client id of apps without a secret": {
+			App:          App{ClientID: "client"},
+			Status:       http.StatusOK,
+			ExpectedForm: map[string]string{"token": "abc", "token_type_hint": "", "client_id": "client"},
+		},
+		"It reports refusals": {
+			App:           App{ClientID: "client", ClientSecret: "wrong"},
+			Status:        http.StatusUnauthorized,
+			ExpectedUser:  "client",
+			ExpectedError: "revocation was rejected with 401 Unauthorized: invalid_client\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/oidc/2/token/revocation", r.URL.Path)
+				assert.Nil(t, r.ParseForm())
+				for key, value := range test.ExpectedForm {
+					assert.Equal(t, value, r.PostForm.Get(key))
+				}
+				user, _, _ := r.BasicAuth()
+				assert.Equal(t, test.ExpectedUser, user)
+				if test.Status != http.StatusOK {
+					http.Error(w, "invalid_client", test.Status)
+				}
+			}))
+			defer server.Close()
+			test.App.Issuer = server.URL + "/oidc/2/"
+			err := test.App.Revoke(server.Client(), "abc", test.Hint)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
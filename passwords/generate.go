@@ -0,0 +1,62 @@
+// Package passwords generate.go
+// This module generates temporary passwords for users whose password an administrator resets. Passwords are drawn
+// from crypto/rand and always have an upper case letter, a lower case letter, a digit and a symbol, so they pass the
+// usual OneLogin password policies.
+package passwords
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// DefaultLength is how long generated passwords are unless asked otherwise
+const DefaultLength = 16
+
+// character classes a password takes at least one character from, leaving out ones easily misread like O and 0
+var classes = []string{
+	"ABCDEFGHJKLMNPQRSTUVWXYZ",
+	"abcdefghijkmnopqrstuvwxyz",
+	"23456789",
+	"!#$%&*+-=?@_",
+}
+
+// Generate returns a random password of the length, which must fit a character of every class
+func Generate(length int) (string, error) {
+	if length < len(classes) {
+		return "", fmt.Errorf("invalid password length %d, expected at least %d", length, len(classes))
+	}
+	all := ""
+	for _, class := range classes {
+		all += class
+	}
+	password := make([]byte, length)
+	for i := range password {
+		set := all
+		if i < len(classes) {
+			set = classes[i]
+		}
+		c, err := pick(set)
+		if err != nil {
+			return "", err
+		}
+		password[i] = c
+	}
+	for i := len(password) - 1; i > 0; i-- { // shuffle so the classes aren't always first
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j.Int64()] = password[j.Int64()], password[i]
+	}
+	return string(password), nil
+}
+
+// pick returns a random character of the set
+func pick(set string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(set))))
+	if err != nil {
+		return 0, err
+	}
+	return set[n.Int64()], nil
+}
@@ -0,0 +1,39 @@
+package passwords
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := map[string]struct {
+		Length        int
+		ExpectedError string
+	}{
+		"It generates passwords of the default length": {
+			Length: DefaultLength,
+		},
+		"It generates the shortest passwords with every class": {
+			Length: 4,
+		},
+		"It reports lengths too short for every class": {
+			Length:        3,
+			ExpectedError: "invalid password length 3, expected at least 4",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			password, err := Generate(test.Length)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Len(t, password, test.Length)
+			for _, class := range classes {
+				assert.True(t, strings.ContainsAny(password, class), "%s has no character of %s", password, class)
+			}
+		})
+	}
+}
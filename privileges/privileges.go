@@ -0,0 +1,75 @@
+// Package privileges privileges.go
+// This module reads the statements of OneLogin privileges, which grant delegated administrators actions like
+// users:Update over a scope of resources, so the privileges granting an action can be found. Actions may end in a
+// wildcard, e.g. users:* grants every action on users and * grants everything.
+package privileges
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"strings"
+)
+
+// Statement allows or denies actions over a scope, like * for every resource or apps/123 for one
+type Statement struct {
+	Effect string   `json:"Effect"`
+	Action []string `json:"Action"`
+	Scope  []string `json:"Scope"`
+}
+
+// Statements reads the statements in the privilege field of a privilege as the API returns it
+func Statements(privilege records.Record) ([]Statement, error) {
+	data, err := json.Marshal(privilege["privilege"])
+	if err != nil {
+		return nil, err
+	}
+	var document struct {
+		Statement []Statement `json:"Statement"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("unable to read the statements of privilege %s: %s", privilege.Text("id"), err)
+	}
+	return document.Statement, nil
+}
+
+// Actions lists the actions the statements allow, in order
+func Actions(statements []Statement) []string {
+	out := []string{}
+	for _, statement := range statements {
+		if !strings.EqualFold(statement.Effect, "Deny") {
+			out = append(out, statement.Action...)
+		}
+	}
+	return out
+}
+
+// Allows reports whether the statements allow the action, returning the scopes they allow it over. A statement
+// denying the action overrides any allowing it
+func Allows(statements []Statement, action string) ([]string, bool) {
+	scopes := []string{}
+	allowed := false
+	for _, statement := range statements {
+		if !matchesAny(statement.Action, action) {
+			continue
+		}
+		if strings.EqualFold(statement.Effect, "Deny") {
+			return nil, false
+		}
+		allowed = true
+		scopes = append(scopes, statement.Scope...)
+	}
+	return scopes, allowed
+}
+
+// matchesAny reports whether any of the patterns, which may end in a wildcard, matches the action ignoring case
+func matchesAny(patterns []string, action string) bool {
+	action = strings.ToLower(action)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if pattern == action || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(action, strings.TrimSuffix(pattern, "*"))) {
+			return true
+		}
+	}
+	return false
+}
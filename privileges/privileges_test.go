@@ -0,0 +1,89 @@
+package privileges
+
+import (
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStatements(t *testing.T) {
+	tests := map[string]struct {
+		Privilege     records.Record
+		Expected      []Statement
+		ExpectedError string
+	}{
+		"It reads the statements of a privilege": {
+			Privilege: records.Record{"id": "p1", "privilege": map[string]interface{}{
+				"Version":   "2018-05-18",
+				"Statement": []interface{}{map[string]interface{}{"Effect": "Allow", "Action": []interface{}{"users:List"}, "Scope": []interface{}{"*"}}},
+			}},
+			Expected: []Statement{{Effect: "Allow", Action: []string{"users:List"}, Scope: []string{"*"}}},
+		},
+		"It reads privileges without statements": {
+			Privilege: records.Record{"id": "p1"},
+			Expected:  nil,
+		},
+		"It reports statements it can't read": {
+			Privilege:     records.Record{"id": "p1", "privilege": map[string]interface{}{"Statement": "all"}},
+			ExpectedError: "unable to read the statements of privilege p1: json: cannot unmarshal string into Go struct field .Statement of type []privileges.Statement",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Statements(test.Privilege)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	statements := []Statement{
+		{Effect: "Allow", Action: []string{"users:List", "users:Update"}, Scope: []string{"users/1", "users/2"}},
+		{Effect: "Allow", Action: []string{"apps:*"}, Scope: []string{"*"}},
+		{Effect: "Deny", Action: []string{"apps:Delete"}, Scope: []string{"*"}},
+	}
+	tests := map[string]struct {
+		Action         string
+		ExpectedScopes []string
+		Expected       bool
+	}{
+		"It allows actions named in statements, ignoring case": {
+			Action:         "USERS:update",
+			ExpectedScopes: []string{"users/1", "users/2"},
+			Expected:       true,
+		},
+		"It allows actions matching wildcards": {
+			Action:         "apps:Update",
+			ExpectedScopes: []string{"*"},
+			Expected:       true,
+		},
+		"It doesn't allow denied actions": {
+			Action: "apps:Delete",
+		},
+		"It doesn't allow other actions": {
+			Action:         "roles:List",
+			ExpectedScopes: []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			scopes, allowed := Allows(statements, test.Action)
+			assert.Equal(t, test.Expected, allowed)
+			assert.Equal(t, test.ExpectedScopes, scopes)
+		})
+	}
+}
+
+func TestActions(t *testing.T) {
+	statements := []Statement{
+		{Effect: "Allow", Action: []string{"users:List", "users:Update"}, Scope: []string{"*"}},
+		{Effect: "Deny", Action: []string{"users:Delete"}, Scope: []string{"*"}},
+		{Effect: "Allow", Action: []string{"apps:*"}, Scope: []string{"*"}},
+	}
+	assert.Equal(t, []string{"users:List", "users:Update", "apps:*"}, Actions(statements))
+}
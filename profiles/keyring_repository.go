@@ -0,0 +1,103 @@
+package profiles
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/zalando/go-keyring"
+)
+
+// KeyringSecretStore marks a profile whose client secret is kept in the OS keyring instead of the profiles file
+const KeyringSecretStore = "keyring"
+
+// the service client secrets are filed under in the keyring, with the profile name as the user
+const keyringService = "onelogin-cli"
+
+// Keyring stores secrets in the OS credential store
+type Keyring interface {
+	Get(service string, user string) (string, error)
+	Set(service string, user string, secret string) error
+	Delete(service string, user string) error
+}
+
+// SystemKeyring is the Keychain on macOS, the Credential Manager on Windows, and the Secret Service on Linux
+type SystemKeyring struct{}
+
+func (SystemKeyring) Get(service string, user string) (string, error) {
+	return keyring.Get(service, user)
+}
+
+func (SystemKeyring) Set(service string, user string, secret string) error {
+	return keyring.Set(service, user, secret)
+}
+
+func (SystemKeyring) Delete(service string, user string) error {
+	return keyring.Delete(service, user)
+}
+
+// KeyringRepository keeps the client secrets of profiles marked with KeyringSecretStore in the keyring and
+// everything else in the wrapped Repository. Profiles that aren't marked are passed through as they are,
+// so existing profiles files keep working until they are moved to the keyring with ProfileService.UseKeyring
+type KeyringRepository struct {
+	Repository Repository
+	Keyring    Keyring
+	known      map[string]bool // profiles read, so secrets of removed profiles can be deleted
+}
+
+func NewKeyringRepository(repository Repository, keyring Keyring) KeyringRepository {
+	return KeyringRepository{Repository: repository, Keyring: keyring, known: map[string]bool{}}
+}
+
+// readAll reads the profiles without their client secrets, so a locked or broken keyring entry only stops commands
+// using that profile. Secrets are read one profile at a time with loadSecret
+func (r KeyringRepository) readAll() ([]byte, error) {
+	data, err := r.Repository.readAll()
+	if err != nil || len(data) == 0 || data[0] == 0 {
+		return data, err
+	}
+	profiles := map[string]*Profile{}
+	if err := json.Unmarshal(bytes.Trim(data, "\x00"), &profiles); err != nil {
+		return nil, err
+	}
+	for name := range profiles {
+		r.known[name] = true
+	}
+	return data, nil
+}
+
+// loadSecret reads the client secret of the profile from the keyring, when it is kept there and not read yet
+func (r KeyringRepository) loadSecret(profile *Profile) error {
+	if profile.SecretStore != KeyringSecretStore || profile.ClientSecret != "" {
+		return nil
+	}
+	secret, err := r.Keyring.Get(keyringService, profile.Name)
+	if err != nil {
+		return fmt.Errorf("unable to read the client secret of %s from the keyring: %s", profile.Name, err)
+	}
+	profile.ClientSecret = secret
+	return nil
+}
+
+func (r KeyringRepository) persist(profiles map[string]*Profile) {
+	stored := map[string]*Profile{}
+	for name, profile := range profiles {
+		p := *profile
+		if p.SecretStore == KeyringSecretStore {
+			// secrets not read from the keyring, or entered, are already there
+			if p.ClientSecret != "" {
+				if err := r.Keyring.Set(keyringService, name, p.ClientSecret); err != nil {
+					logger.Fatal("Unable to store client secret in keyring", "error", err)
+				}
+			}
+			p.ClientSecret = ""
+		}
+		stored[name] = &p
+	}
+	for name := range r.known {
+		if profiles[name] == nil {
+			r.Keyring.Delete(keyringService, name) // not every removed profile had its secret in the keyring
+		}
+	}
+	r.Repository.persist(stored)
+}
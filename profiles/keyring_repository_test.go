@@ -0,0 +1,95 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type MockKeyring struct {
+	Secrets map[string]string
+}
+
+func (k MockKeyring) Get(service string, user string) (string, error) {
+	secret, ok := k.Secrets[service+"/"+user]
+	if !ok {
+		return "", errors.New("secret not found in keyring")
+	}
+	return secret, nil
+}
+
+func (k MockKeyring) Set(service string, user string, secret string) error {
+	k.Secrets[service+"/"+user] = secret
+	return nil
+}
+
+func (k MockKeyring) Delete(service string, user string) error {
+	delete(k.Secrets, service+"/"+user)
+	return nil
+}
+
+func storedProfiles(t *testing.T, file *MockFile) map[string]Profile {
+	profiles := map[string]Profile{}
+	assert.Nil(t, json.Unmarshal(file.Content, &profiles))
+	return profiles
+}
+
+func TestKeyringRepositoryReadsSecretsFromKeyring(t *testing.T) {
+	file := &MockFile{Content: []byte(`{"t":{"name":"t","active":true,"region":"us","client_id":"ti","client_secret":"","secret_store":"keyring"},"s":{"name":"s","region":"us","client_id":"si","client_secret":"ss"}}`)}
+	keyring := MockKeyring{Secrets: map[string]string{"onelogin-cli/t": "ts"}}
+	profilesSvc := ProfileService{Repository: NewKeyringRepository(MockRepository{StorageMedia: file}, keyring)}
+
+	assert.Equal(t, &Profile{Name: "t", Active: true, Region: "us", ClientID: "ti", ClientSecret: "ts", SecretStore: KeyringSecretStore}, profilesSvc.Find("t"))
+	assert.Equal(t, &Profile{Name: "s", Region: "us", ClientID: "si", ClientSecret: "ss"}, profilesSvc.Find("s"))
+}
+
+func TestUseKeyring(t *testing.T) {
+	file := &MockFile{Content: []byte(`{"t":{"name":"t","active":true,"region":"us","client_id":"ti","client_secret":"ts"}}`)}
+	keyring := MockKeyring{Secrets: map[string]string{}}
+	profilesSvc := ProfileService{Repository: NewKeyringRepository(MockRepository{StorageMedia: file}, keyring)}
+
+	profilesSvc.UseKeyring()
+	assert.Equal(t, map[string]Profile{
+		"t": Profile{Name: "t", Active: true, Region: "us", ClientID: "ti", SecretStore: KeyringSecretStore},
+	}, storedProfiles(t, file))
+	assert.Equal(t, map[string]string{"onelogin-cli/t": "ts"}, keyring.Secrets)
+}
+
+func TestKeyringRepositoryRemovesSecrets(t *testing.T) {
+	file := &MockFile{Content: []byte(`{"t":{"name":"t","region":"us","client_id":"ti","client_secret":"","secret_store":"keyring"},"s":{"name":"s","region":"us","client_id":"si","client_secret":"","secret_store":"keyring"}}`)}
+	keyring := MockKeyring{Secrets: map[string]string{"onelogin-cli/t": "ts", "onelogin-cli/s": "ss"}}
+	profilesSvc := ProfileService{Repository: NewKeyringRepository(MockRepository{StorageMedia: file}, keyring)}
+
+	profilesSvc.Remove("t")
+	assert.Equal(t, map[string]Profile{
+		"s": Profile{Name: "s", Region: "us", ClientID: "si", SecretStore: KeyringSecretStore},
+	}, storedProfiles(t, file))
+	assert.Equal(t, map[string]string{"onelogin-cli/s": "ss"}, keyring.Secrets)
+}
+
+func TestKeyringRepositoryReadsSecretsOfSelectedProfileOnly(t *testing.T) {
+	file := &MockFile{Content: []byte(`{"t":{"name":"t","active":true,"region":"us","client_id":"ti","client_secret":"","secret_store":"keyring"},"locked":{"name":"locked","region":"us","client_id":"li","client_secret":"","secret_store":"keyring"}}`)}
+	keyring := &CountingKeyring{MockKeyring: MockKeyring{Secrets: map[string]string{"onelogin-cli/t": "ts"}}} // locked's entry can't be read
+	profilesSvc := ProfileService{Repository: NewKeyringRepository(MockRepository{StorageMedia: file}, keyring)}
+
+	assert.Len(t, profilesSvc.Index(), 2)
+	assert.Equal(t, 0, keyring.gets)
+	assert.Equal(t, "ts", profilesSvc.Select("").ClientSecret)
+	assert.Equal(t, 1, keyring.gets)
+
+	profilesSvc.Activate("locked")
+	assert.Equal(t, map[string]string{"onelogin-cli/t": "ts"}, keyring.Secrets)
+	assert.Equal(t, "", storedProfiles(t, file)["t"].ClientSecret)
+}
+
+// CountingKeyring counts the secrets read from it
+type CountingKeyring struct {
+	MockKeyring
+	gets int
+}
+
+func (k *CountingKeyring) Get(service string, user string) (string, error) {
+	k.gets++
+	return k.MockKeyring.Get(service, user)
+}
@@ -2,8 +2,9 @@ package profiles
 
 import (
 	"encoding/json"
+	"github.com/onelogin/onelogin/logger"
+	"io"
 	"io/ioutil"
-	"log"
 	"os"
 )
 
@@ -16,7 +17,11 @@ type FileRepository struct {
 	StorageMedia *os.File
 }
 
+// readAll reads the whole file, from its start so the profiles can be read more than once
 func (p FileRepository) readAll() ([]byte, error) {
+	if _, err := p.StorageMedia.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
 	return ioutil.ReadAll(p.StorageMedia)
 }
 
@@ -29,11 +34,11 @@ func (p FileRepository) persist(profiles map[string]*Profile) {
 	p.StorageMedia.Truncate(0)
 	if _, err := p.StorageMedia.WriteAt(updatedProfiles, 0); err != nil {
 		if err = p.StorageMedia.Close(); err != nil {
-			log.Fatalln("Unable write profile", err)
+			logger.Fatal("Unable write profile", "error", err)
 		}
-		log.Fatalln("Unable to persist", err)
+		logger.Fatal("Unable to persist", "error", err)
 	}
 	if err := p.StorageMedia.Close(); err != nil {
-		log.Fatalln("Unable write profile", err)
+		logger.Fatal("Unable write profile", "error", err)
 	}
 }
@@ -5,14 +5,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/onelogin/onelogin/logger"
 	"io"
-	"log"
+	"net/url"
+	"regexp"
 	"strings"
 )
 
 type ProfileService struct {
 	Repository  Repository
 	InputReader io.Reader
+	Verify      func(profile Profile) error // when given, checks the credentials entered for a profile before it is saved
 }
 
 type Profile struct {
@@ -21,6 +24,48 @@ type Profile struct {
 	Region       string `json:"region"`
 	ClientID     string `json:"client_id"`
 	ClientSecret string `json:"client_secret"`
+	SecretStore  string `json:"secret_store,omitempty"` // where the client secret is kept when not in the profiles file e.g. keyring
+	Subdomain    string `json:"subdomain,omitempty"`    // tenant subdomain, for API-domain-customized tenants
+	URL          string `json:"url,omitempty"`          // API base URL e.g. for sandbox shards. Overrides region and subdomain
+	Parent       string `json:"parent,omitempty"`       // profile the region, subdomain, and URL are inherited from
+	Scope        string `json:"scope,omitempty"`        // scope of the API credential pair e.g. read_all
+}
+
+// API credential scopes as named in the OneLogin admin portal
+const (
+	ScopeAuthenticationOnly = "authentication_only"
+	ScopeReadUsers          = "read_users"
+	ScopeManageUsers        = "manage_users"
+	ScopeReadAll            = "read_all"
+	ScopeManageAll          = "manage_all"
+)
+
+// scopes maps each known scope to whether it allows writes
+var scopes = map[string]bool{
+	ScopeAuthenticationOnly: false,
+	ScopeReadUsers:          false,
+	ScopeManageUsers:        true,
+	ScopeReadAll:            false,
+	ScopeManageAll:          true,
+}
+
+// ReadOnly reports whether the profile's credentials are known to be unable to write. Profiles without a scope may write
+func (p Profile) ReadOnly() bool {
+	writes, known := scopes[p.Scope]
+	return known && !writes
+}
+
+var subdomainPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// APIURL is the base URL of the OneLogin API for the profile. The URL is used when given, then the subdomain, then the region
+func (p Profile) APIURL() string {
+	if p.URL != "" {
+		return strings.TrimRight(p.URL, "/")
+	}
+	if p.Subdomain != "" {
+		return fmt.Sprintf("https://%s.onelogin.com", p.Subdomain)
+	}
+	return fmt.Sprintf("https://api.%s.onelogin.com", p.Region)
 }
 
 func (p ProfileService) GetActive() *Profile {
@@ -33,8 +78,69 @@ func (p ProfileService) GetActive() *Profile {
 	return nil
 }
 
+// Select finds the named profile, or the active profile when no name is given, with the settings it inherits
+// from its parent filled in. Exits when the named profile or a parent doesn't exist
+func (p ProfileService) Select(name string) *Profile {
+	profiles := p.Index()
+	var profile *Profile
+	if name == "" {
+		for _, prof := range profiles {
+			if (*prof).Active == true {
+				profile = prof
+			}
+		}
+		if profile == nil {
+			return nil
+		}
+	} else if profile = profiles[name]; profile == nil {
+		logger.Fatal("Profile does not exist!", "name", name)
+	}
+	resolved, err := inherit(profile, profiles)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	p.loadSecret(resolved)
+	return resolved
+}
+
+// secretLoader is a Repository keeping client secrets apart from the profiles, read only for the profiles using them
+type secretLoader interface {
+	loadSecret(profile *Profile) error
+}
+
+// loadSecret fills in the client secret of the profile when the Repository keeps secrets apart
+func (p ProfileService) loadSecret(profile *Profile) {
+	if loader, ok := p.Repository.(secretLoader); ok {
+		if err := loader.loadSecret(profile); err != nil {
+			logger.Fatal(err.Error())
+		}
+	}
+}
+
+// inherit returns a copy of the profile with the region, subdomain, and URL of its parents
+func inherit(profile *Profile, profiles map[string]*Profile) (*Profile, error) {
+	out := *profile
+	seen := map[string]bool{profile.Name: true}
+	for parentName := profile.Parent; parentName != ""; {
+		parent := profiles[parentName]
+		if parent == nil {
+			return nil, fmt.Errorf("parent profile %s of %s does not exist", parentName, profile.Name)
+		}
+		if seen[parentName] {
+			return nil, fmt.Errorf("parent profiles of %s form a cycle at %s", profile.Name, parentName)
+		}
+		seen[parentName] = true
+		out.Region, out.Subdomain, out.URL = parent.Region, parent.Subdomain, parent.URL
+		parentName = parent.Parent
+	}
+	return &out, nil
+}
+
 func (p ProfileService) Activate(name string) {
 	profiles := p.Index()
+	if profiles[name] == nil {
+		logger.Fatal("Profile does not exist!")
+	}
 	for n, prof := range profiles {
 		if n == name {
 			(*prof).Active = true
@@ -45,9 +151,11 @@ func (p ProfileService) Activate(name string) {
 	p.Repository.persist(profiles)
 }
 
+// Find finds the named profile with its client secret, or nil when it doesn't exist
 func (p ProfileService) Find(name string) *Profile {
 	profiles := p.Index()
 	if profiles[name] != nil {
+		p.loadSecret(profiles[name])
 		return profiles[name]
 	}
 	return nil
@@ -57,29 +165,45 @@ func (p ProfileService) Index() map[string]*Profile {
 	existingProfiles := map[string]*Profile{}
 	fileData, err := p.Repository.readAll()
 	if err != nil {
-		log.Fatalln("Unable to read profiles", err)
+		logger.Fatal("Unable to read profiles", "error", err)
 	}
 	if len(fileData) == 0 || fileData[0] == 0 { // no data in file
 		return existingProfiles
 	}
 	err = json.Unmarshal(bytes.Trim(fileData, "\x00"), &existingProfiles)
 	if err != nil {
-		log.Fatalln("Unable to parse profiles file!", err)
+		logger.Fatal("Unable to parse profiles file!", "error", err)
 	}
 	return existingProfiles
 }
 
 func (p ProfileService) Create(name string) {
+	p.create(&Profile{Name: name})
+}
+
+// CreateScoped adds a profile with its own, usually restricted, API credentials that uses the tenant of the parent profile
+func (p ProfileService) CreateScoped(name string, parent string) {
+	if p.Index()[parent] == nil {
+		logger.Fatal("Parent profile does not exist!")
+	}
+	p.create(&Profile{Name: name, Parent: parent})
+}
+
+func (p ProfileService) create(profile *Profile) {
 	existingProfiles := p.Index()
-	profile := existingProfiles[name]
-	if profile != nil {
-		log.Fatalln("Profile with this name already exists!")
+	if existingProfiles[profile.Name] != nil {
+		logger.Fatal("Profile with this name already exists!")
 	}
-	profile = &Profile{Name: name}
 	if len(existingProfiles) == 0 {
 		profile.Active = true
 	}
+	for _, existing := range existingProfiles {
+		if existing.SecretStore == KeyringSecretStore {
+			profile.SecretStore = KeyringSecretStore // once secrets are in the keyring, new ones go there too
+		}
+	}
 	collectProfileInput(profile, p.InputReader)
+	p.verify(profile, existingProfiles)
 	existingProfiles[(*profile).Name] = profile
 	p.Repository.persist(existingProfiles)
 }
@@ -88,59 +212,152 @@ func (p ProfileService) Update(name string) {
 	existingProfiles := p.Index()
 	profile := existingProfiles[name]
 	if profile == nil {
-		log.Fatalln("Profile does not exist!")
+		logger.Fatal("Profile does not exist!")
 	}
+	p.loadSecret(profile)
 	collectProfileInput(profile, p.InputReader)
+	p.verify(profile, existingProfiles)
 	existingProfiles[(*profile).Name] = profile
 	p.Repository.persist(existingProfiles)
 }
 
+// verify checks the credentials of the profile with Verify, if given, so bad ones are never saved
+func (p ProfileService) verify(profile *Profile, profiles map[string]*Profile) {
+	if p.Verify == nil {
+		return
+	}
+	resolved, err := inherit(profile, profiles)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
+	logger.Info("Verifying credentials...")
+	if err := p.Verify(*resolved); err != nil {
+		logger.Fatal("Unable to verify credentials, profile not saved!", "error", err)
+	}
+	logger.Info("Credentials verified")
+}
+
+// UseKeyring moves the client secrets of every profile out of the profiles file into the OS keyring.
+// The Repository must be a KeyringRepository
+func (p ProfileService) UseKeyring() {
+	if _, ok := p.Repository.(KeyringRepository); !ok {
+		logger.Fatal("Profiles are not backed by a keyring!")
+	}
+	existingProfiles := p.Index()
+	for _, profile := range existingProfiles {
+		profile.SecretStore = KeyringSecretStore
+	}
+	p.Repository.persist(existingProfiles)
+}
+
 func (p ProfileService) Remove(name string) {
 	existingProfiles := p.Index()
+	if existingProfiles[name] == nil {
+		logger.Fatal("Profile does not exist!")
+	}
 	delete(existingProfiles, name)
 	p.Repository.persist(existingProfiles)
 }
 
 func collectProfileInput(p *Profile, rdr io.Reader) {
-	var userInput string
 	reader := bufio.NewReader(rdr)
+	if p.Parent == "" { // scoped profiles use the tenant of their parent
+		p.Region = readRegion(reader, p.Region)
+	}
+
+	fmt.Printf("Add the profile's CLIENT_ID [Enter to accept %s]: \n", p.ClientID)
+	p.ClientID = readRequired(reader, p.ClientID)
+
+	fmt.Printf("Add the profile's CLIENT_SECRET [Enter to accept %s]: \n", maskSecret(p.ClientSecret))
+	p.ClientSecret = readRequired(reader, p.ClientSecret)
+
+	if p.Parent == "" {
+		fmt.Printf("Add the profile's SUBDOMAIN, only needed for API-domain-customized tenants (optional) [Enter to accept %s, - to clear]: \n", p.Subdomain)
+		p.Subdomain = readOptional(reader, p.Subdomain, validSubdomain)
+
+		fmt.Printf("Add the profile's API URL e.g. for sandbox tenants, overrides region and subdomain (optional) [Enter to accept %s, - to clear]: \n", p.URL)
+		p.URL = readOptional(reader, p.URL, validURL)
+	}
+
+	fmt.Printf("Add the SCOPE of the credentials (authentication_only, read_users, manage_users, read_all, or manage_all) (optional) [Enter to accept %s, - to clear]: \n", p.Scope)
+	p.Scope = readOptional(reader, p.Scope, validScope)
+}
+
+// reads a line of input, keeping current when the line is blank and clearing it with -. Asks again while invalid
+func readOptional(reader *bufio.Reader, current string, valid func(string) bool) string {
 	for {
-		fmt.Printf("Add the profile's REGION (us or eu) [Enter to accept %s]: \n", p.Region)
-		userInput, _ = reader.ReadString('\n')
-		userInput = strings.ToLower(strings.TrimSuffix(userInput, "\n"))
-		if userInput == "us" || userInput == "eu" || (len(userInput) == 0 && p.Region != "") {
-			if len(userInput) == 0 && p.Region != "" {
-				userInput = p.Region
-			}
-			break
+		userInput, err := reader.ReadString('\n')
+		userInput = strings.Trim(userInput, " \t\r\n\x00")
+		switch {
+		case userInput == "":
+			return current
+		case userInput == "-":
+			return ""
+		case valid(userInput):
+			return userInput
 		}
-		fmt.Println("Invalid region given!")
+		if err != nil {
+			logger.Fatal("Invalid value given!", "value", userInput)
+		}
+		fmt.Println("Invalid value given!")
 	}
-	p.Region = userInput
+}
 
-	fmt.Printf("Add the profile's CLIENT_ID [Enter to accept %s]: \n", p.ClientID)
+func validScope(scope string) bool {
+	_, ok := scopes[scope]
+	return ok
+}
+
+func validSubdomain(subdomain string) bool {
+	return subdomainPattern.MatchString(subdomain)
+}
+
+func validURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
+
+// reads a region, keeping current when the line is blank. Asks again until a valid region is given
+func readRegion(reader *bufio.Reader, current string) string {
 	for {
-		userInput, _ = reader.ReadString('\n')
-		if userInput == "\n" && p.ClientID != "" {
-			break
+		fmt.Printf("Add the profile's REGION (us or eu) [Enter to accept %s]: \n", current)
+		userInput, err := reader.ReadString('\n')
+		userInput = strings.ToLower(strings.TrimSpace(userInput))
+		if userInput == "us" || userInput == "eu" {
+			return userInput
 		}
-		if userInput != "\n" {
-			p.ClientID = strings.TrimSuffix(userInput, "\n")
-			break
+		if len(userInput) == 0 && current != "" {
+			return current
 		}
-		fmt.Println("Value cannot be blank!")
+		if err != nil {
+			logger.Fatal("Invalid region given!", "value", userInput)
+		}
+		fmt.Println("Invalid region given!")
 	}
+}
 
-	fmt.Printf("Add the profile's CLIENT_SECRET [Enter to accept %s]: \n", p.ClientSecret)
+// reads a line of input, keeping current when the line is blank. Asks again while both are blank
+func readRequired(reader *bufio.Reader, current string) string {
 	for {
-		userInput, _ = reader.ReadString('\n')
-		if userInput == "\n" && p.ClientSecret != "" {
-			break
+		userInput, err := reader.ReadString('\n')
+		userInput = strings.TrimSpace(userInput)
+		if userInput != "" {
+			return userInput
+		}
+		if current != "" {
+			return current
 		}
-		if userInput != "\n" {
-			p.ClientSecret = strings.TrimSuffix(userInput, "\n")
-			break
+		if err != nil {
+			logger.Fatal("Value cannot be blank!")
 		}
 		fmt.Println("Value cannot be blank!")
 	}
 }
+
+// maskSecret hides all but the last 4 characters of a secret shown in a prompt
+func maskSecret(secret string) string {
+	if len(secret) <= 4 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", len(secret)-4) + secret[len(secret)-4:]
+}
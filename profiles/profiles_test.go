@@ -145,6 +145,106 @@ func TestFind(t *testing.T) {
 	}
 }
 
+func TestSelect(t *testing.T) {
+	tests := map[string]struct {
+		ProfileName    string
+		ExpectedReturn *Profile
+	}{
+		"It selects the named profile over the active one": {
+			ProfileName:    "s",
+			ExpectedReturn: &Profile{Name: "s", Active: false, Region: "eu", ClientID: "si", ClientSecret: "ss"},
+		},
+		"It selects the active profile when no name is given": {
+			ExpectedReturn: &Profile{Name: "t", Active: true, Region: "us", ClientID: "ti", ClientSecret: "ts"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockStorage := &MockFile{Content: []byte(`{"t":{"name":"t","active":true,"region":"us","client_id":"ti","client_secret":"ts"}, "s":{"name":"s","active":false,"region":"eu","client_id":"si","client_secret":"ss"}}`)}
+			profilesSvc := ProfileService{
+				Repository: MockRepository{StorageMedia: mockStorage},
+			}
+			assert.Equal(t, test.ExpectedReturn, profilesSvc.Select(test.ProfileName))
+			assert.Equal(t, "t", profilesSvc.GetActive().Name)
+		})
+	}
+}
+
+func TestSelectInheritsFromParent(t *testing.T) {
+	tests := map[string]struct {
+		ProfileName    string
+		ExpectedReturn *Profile
+	}{
+		"It inherits the tenant of the parent": {
+			ProfileName:    "reader",
+			ExpectedReturn: &Profile{Name: "reader", Region: "eu", URL: "https://api.sandbox.onelogin.com", ClientID: "ri", ClientSecret: "rs", Parent: "admin", Scope: "read_all"},
+		},
+		"It inherits through every parent": {
+			ProfileName:    "nested",
+			ExpectedReturn: &Profile{Name: "nested", Region: "eu", URL: "https://api.sandbox.onelogin.com", ClientID: "ni", ClientSecret: "ns", Parent: "reader", Scope: "authentication_only"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockStorage := &MockFile{Content: []byte(`{
+				"admin":{"name":"admin","active":true,"region":"eu","url":"https://api.sandbox.onelogin.com","client_id":"ai","client_secret":"as"},
+				"reader":{"name":"reader","region":"us","client_id":"ri","client_secret":"rs","parent":"admin","scope":"read_all"},
+				"nested":{"name":"nested","client_id":"ni","client_secret":"ns","parent":"reader","scope":"authentication_only"}
+			}`)}
+			profilesSvc := ProfileService{
+				Repository: MockRepository{StorageMedia: mockStorage},
+			}
+			assert.Equal(t, test.ExpectedReturn, profilesSvc.Select(test.ProfileName))
+			assert.Equal(t, "", profilesSvc.Find("nested").Region) // the stored profile is left as it was
+		})
+	}
+}
+
+func TestInheritRejectsCycles(t *testing.T) {
+	profiles := map[string]*Profile{
+		"a": {Name: "a", Parent: "b"},
+		"b": {Name: "b", Parent: "a"},
+	}
+	_, err := inherit(profiles["a"], profiles)
+	assert.EqualError(t, err, "parent profiles of a form a cycle at a")
+	_, err = inherit(&Profile{Name: "c", Parent: "missing"}, profiles)
+	assert.EqualError(t, err, "parent profile missing of c does not exist")
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := map[string]struct {
+		Scope    string
+		Expected bool
+	}{
+		"It is read only with read_all":          {Scope: ScopeReadAll, Expected: true},
+		"It is read only with read_users":        {Scope: ScopeReadUsers, Expected: true},
+		"It is read only with authentication":    {Scope: ScopeAuthenticationOnly, Expected: true},
+		"It can write with manage_all":           {Scope: ScopeManageAll, Expected: false},
+		"It can write with manage_users":         {Scope: ScopeManageUsers, Expected: false},
+		"It may write when the scope is unknown": {Scope: "", Expected: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Profile{Scope: test.Scope}.ReadOnly())
+		})
+	}
+}
+
+func TestCreateScoped(t *testing.T) {
+	verified := []Profile{}
+	profilesSvc := ProfileService{
+		Repository:  MockRepository{StorageMedia: &MockFile{Content: []byte(`{"admin":{"name":"admin","active":true,"region":"eu","client_id":"ai","client_secret":"as"}}`)}},
+		InputReader: &MockCmdLineInput{Content: []byte("ri\nrs\nread_all\n")},
+		Verify: func(profile Profile) error {
+			verified = append(verified, profile)
+			return nil
+		},
+	}
+	profilesSvc.CreateScoped("reader", "admin")
+	assert.Equal(t, Profile{Name: "reader", ClientID: "ri", ClientSecret: "rs", Parent: "admin", Scope: "read_all"}, *profilesSvc.Find("reader"))
+	assert.Equal(t, []Profile{{Name: "reader", Region: "eu", ClientID: "ri", ClientSecret: "rs", Parent: "admin", Scope: "read_all"}}, verified)
+}
+
 func TestActivate(t *testing.T) {
 	tests := map[string]struct {
 		MockStorage  *MockFile
@@ -188,6 +288,13 @@ func TestCreate(t *testing.T) {
 			ExpectedProfile:      Profile{Name: "test", Region: "us", ClientID: "test", ClientSecret: "test"},
 			ExpectedProfileCount: 2,
 		},
+		"It creates a profile with a subdomain and API URL": {
+			CmdLineInput:         &MockCmdLineInput{Content: []byte("us\ntest\ntest\nBad Domain\nacme\nnot a url\nhttps://api.sandbox.onelogin.com/\n")},
+			ProfileName:          "test",
+			MockStorage:          &MockFile{Content: []byte(`{"pre-existing":{"name":"pre-existing","active":false,"region":"us","client_id":"test","client_secret":"test"}}`)},
+			ExpectedProfile:      Profile{Name: "test", Region: "us", ClientID: "test", ClientSecret: "test", Subdomain: "acme", URL: "https://api.sandbox.onelogin.com/"},
+			ExpectedProfileCount: 2,
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -219,6 +326,13 @@ func TestUpdate(t *testing.T) {
 			ExpectedProfile:      Profile{Name: "test", Region: "us", ClientID: "update", ClientSecret: "update"},
 			ExpectedProfileCount: 1,
 		},
+		"It keeps the subdomain and clears the API URL": {
+			CmdLineInput:         &MockCmdLineInput{Content: []byte("\n\n\n\n-\n")},
+			ProfileName:          "test",
+			MockStorage:          &MockFile{Content: []byte(`{"test":{"name":"test","active":false,"region":"eu","client_id":"test","client_secret":"test","subdomain":"acme","url":"https://api.sandbox.onelogin.com"}}`)},
+			ExpectedProfile:      Profile{Name: "test", Region: "eu", ClientID: "test", ClientSecret: "test", Subdomain: "acme"},
+			ExpectedProfileCount: 1,
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -259,3 +373,50 @@ func TestRemove(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateVerifiesCredentials(t *testing.T) {
+	verified := []Profile{}
+	profilesSvc := ProfileService{
+		Repository:  MockRepository{StorageMedia: &MockFile{Content: []byte(`{}`)}},
+		InputReader: &MockCmdLineInput{Content: []byte("eu\nid\nsecret\n")},
+		Verify: func(profile Profile) error {
+			verified = append(verified, profile)
+			return nil
+		},
+	}
+	profilesSvc.Create("test")
+	assert.Equal(t, []Profile{{Name: "test", Active: true, Region: "eu", ClientID: "id", ClientSecret: "secret"}}, verified)
+	assert.Equal(t, Profile{Name: "test", Active: true, Region: "eu", ClientID: "id", ClientSecret: "secret"}, *profilesSvc.Find("test"))
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := map[string]struct {
+		Secret   string
+		Expected string
+	}{
+		"It shows the last 4 characters": {Secret: "abcdefgh", Expected: "****efgh"},
+		"It hides short secrets":         {Secret: "abc", Expected: "***"},
+		"It leaves blanks blank":         {Secret: "", Expected: ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, maskSecret(test.Secret))
+		})
+	}
+}
+
+func TestAPIURL(t *testing.T) {
+	tests := map[string]struct {
+		Profile  Profile
+		Expected string
+	}{
+		"It derives the URL from the region":    {Profile: Profile{Region: "eu"}, Expected: "https://api.eu.onelogin.com"},
+		"It uses the subdomain over the region": {Profile: Profile{Region: "us", Subdomain: "acme"}, Expected: "https://acme.onelogin.com"},
+		"It uses the URL over everything else":  {Profile: Profile{Region: "us", Subdomain: "acme", URL: "https://api.sandbox.onelogin.com/"}, Expected: "https://api.sandbox.onelogin.com"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Profile.APIURL())
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package profiles
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/logger"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// formats profiles can be shared in
+const (
+	YAMLFormat = "yaml"
+	JSONFormat = "json"
+)
+
+// SharedProfile is a profile as shared with a team. Settings only meaningful on one machine, like which profile is
+// active or where the secret is kept, are left out
+type SharedProfile struct {
+	Name         string `json:"name" yaml:"name"`
+	Region       string `json:"region,omitempty" yaml:"region,omitempty"`
+	Subdomain    string `json:"subdomain,omitempty" yaml:"subdomain,omitempty"`
+	URL          string `json:"url,omitempty" yaml:"url,omitempty"`
+	Parent       string `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Scope        string `json:"scope,omitempty" yaml:"scope,omitempty"`
+	ClientID     string `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+}
+
+// SharedProfiles is the document profiles are exported to and imported from
+type SharedProfiles struct {
+	Profiles []SharedProfile `json:"profiles" yaml:"profiles"`
+}
+
+// Export writes every profile, sorted by name, in the format. Client secrets are left out when redactSecrets is set
+func (p ProfileService) Export(w io.Writer, format string, redactSecrets bool) error {
+	existingProfiles := p.Index()
+	shared := SharedProfiles{Profiles: []SharedProfile{}}
+	for _, profile := range existingProfiles {
+		if !redactSecrets {
+			p.loadSecret(profile)
+		}
+		out := SharedProfile{
+			Name:         profile.Name,
+			Region:       profile.Region,
+			Subdomain:    profile.Subdomain,
+			URL:          profile.URL,
+			Parent:       profile.Parent,
+			Scope:        profile.Scope,
+			ClientID:     profile.ClientID,
+			ClientSecret: profile.ClientSecret,
+		}
+		if redactSecrets {
+			out.ClientSecret = ""
+		}
+		shared.Profiles = append(shared.Profiles, out)
+	}
+	sort.Slice(shared.Profiles, func(i, j int) bool { return shared.Profiles[i].Name < shared.Profiles[j].Name })
+
+	var data []byte
+	var err error
+	switch format {
+	case YAMLFormat:
+		data, err = yaml.Marshal(shared)
+	case JSONFormat:
+		data, err = json.MarshalIndent(shared, "", "  ")
+		data = append(data, '\n')
+	default:
+		return fmt.Errorf("unsupported format %s, expected yaml or json", format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Import adds the profiles in the shared document, in yaml or json, prompting for any client id or secret left out.
+// Profiles that already exist are skipped. Returns the names of the profiles added
+func (p ProfileService) Import(r io.Reader, format string) ([]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	document := SharedProfiles{}
+	switch format {
+	case YAMLFormat:
+		err = yaml.UnmarshalStrict(data, &document)
+	case JSONFormat:
+		err = json.Unmarshal(data, &document)
+	default:
+		return nil, fmt.Errorf("unsupported format %s, expected yaml or json", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read profiles: %s", err)
+	}
+	for _, profile := range document.Profiles {
+		if err := validateShared(profile); err != nil {
+			return nil, err
+		}
+	}
+
+	existingProfiles := p.Index()
+	secretStore := ""
+	for _, existing := range existingProfiles {
+		if existing.SecretStore == KeyringSecretStore {
+			secretStore = KeyringSecretStore
+		}
+	}
+	reader := bufio.NewReader(p.InputReader)
+	added := []string{}
+	for _, shared := range document.Profiles {
+		if existingProfiles[shared.Name] != nil {
+			logger.Warn("Skipping profile as one with this name already exists", "name", shared.Name)
+			continue
+		}
+		profile := &Profile{
+			Name:         shared.Name,
+			Active:       len(existingProfiles) == 0 && len(added) == 0,
+			Region:       shared.Region,
+			Subdomain:    shared.Subdomain,
+			URL:          shared.URL,
+			Parent:       shared.Parent,
+			Scope:        shared.Scope,
+			ClientID:     shared.ClientID,
+			ClientSecret: shared.ClientSecret,
+			SecretStore:  secretStore,
+		}
+		if profile.ClientID == "" {
+			fmt.Printf("Add the CLIENT_ID of %s: \n", profile.Name)
+			profile.ClientID = readRequired(reader, "")
+		}
+		if profile.ClientSecret == "" {
+			fmt.Printf("Add the CLIENT_SECRET of %s: \n", profile.Name)
+			profile.ClientSecret = readRequired(reader, "")
+		}
+		existingProfiles[profile.Name] = profile
+		added = append(added, profile.Name)
+	}
+	for _, name := range added {
+		if parent := existingProfiles[name].Parent; parent != "" && existingProfiles[parent] == nil {
+			return nil, fmt.Errorf("parent profile %s of %s does not exist", parent, name)
+		}
+	}
+	for _, name := range added {
+		p.verify(existingProfiles[name], existingProfiles)
+	}
+	p.Repository.persist(existingProfiles)
+	return added, nil
+}
+
+// validateShared checks a shared profile the way the prompts check entered settings
+func validateShared(profile SharedProfile) error {
+	switch {
+	case profile.Name == "":
+		return fmt.Errorf("a profile has no name")
+	case profile.Parent == "" && profile.Region != "us" && profile.Region != "eu":
+		return fmt.Errorf("profile %s has invalid region %q, expected us or eu", profile.Name, profile.Region)
+	case profile.Subdomain != "" && !validSubdomain(profile.Subdomain):
+		return fmt.Errorf("profile %s has invalid subdomain %q", profile.Name, profile.Subdomain)
+	case profile.URL != "" && !validURL(profile.URL):
+		return fmt.Errorf("profile %s has invalid url %q", profile.Name, profile.URL)
+	case profile.Scope != "" && !validScope(profile.Scope):
+		return fmt.Errorf("profile %s has unknown scope %q", profile.Name, profile.Scope)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package profiles
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+const shareTestProfiles = `{"prod":{"name":"prod","active":true,"region":"us","client_id":"pi","client_secret":"ps","secret_store":"keyring"},"reader":{"name":"reader","region":"","client_id":"ri","client_secret":"rs","parent":"prod","scope":"read_all"}}`
+
+func TestExport(t *testing.T) {
+	tests := map[string]struct {
+		Format        string
+		RedactSecrets bool
+		Expected      string
+		ExpectedError string
+	}{
+		"It exports yaml without secrets": {
+			Format:        YAMLFormat,
+			RedactSecrets: true,
+			Expected: `profiles:
+- name: prod
+  region: us
+  client_id: pi
+- name: reader
+  parent: prod
+  scope: read_all
+  client_id: ri
+`,
+		},
+		"It exports json with secrets": {
+			Format: JSONFormat,
+			Expected: `{
+  "profiles": [
+    {
+      "name": "prod",
+      "region": "us",
+      "client_id": "pi",
+      "client_secret": "ps"
+    },
+    {
+      "name": "reader",
+      "parent": "prod",
+      "scope": "read_all",
+      "client_id": "ri",
+      "client_secret": "rs"
+    }
+  ]
+}
+`,
+		},
+		"It rejects unknown formats": {
+			Format:        "toml",
+			ExpectedError: "unsupported format toml, expected yaml or json",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			profilesSvc := ProfileService{
+				Repository: MockRepository{StorageMedia: &MockFile{Content: []byte(shareTestProfiles)}},
+			}
+			var out bytes.Buffer
+			err := profilesSvc.Export(&out, test.Format, test.RedactSecrets)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, out.String())
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	tests := map[string]struct {
+		Existing         string
+		Document         string
+		Format           string
+		CmdLineInput     string
+		ExpectedAdded    []string
+		ExpectedProfiles map[string]Profile
+		ExpectedError    string
+	}{
+		"It prompts for the secrets left out": {
+			Existing: `{}`,
+			Document: `profiles:
+- name: prod
+  region: us
+  client_id: pi
+- name: reader
+  parent: prod
+  scope: read_all
+`,
+			Format:        YAMLFormat,
+			CmdLineInput:  "ps\nri\nrs\n",
+			ExpectedAdded: []string{"prod", "reader"},
+			ExpectedProfiles: map[string]Profile{
+				"prod":   {Name: "prod", Active: true, Region: "us", ClientID: "pi", ClientSecret: "ps"},
+				"reader": {Name: "reader", Parent: "prod", Scope: "read_all", ClientID: "ri", ClientSecret: "rs"},
+			},
+		},
+		"It skips existing profiles and keeps secrets in the keyring": {
+			Existing:      `{"prod":{"name":"prod","active":true,"region":"eu","client_id":"old","client_secret":"old","secret_store":"keyring"}}`,
+			Document:      `{"profiles":[{"name":"prod","region":"us","client_id":"pi","client_secret":"ps"},{"name":"emea","region":"eu","subdomain":"acme","client_id":"ei","client_secret":"es"}]}`,
+			Format:        JSONFormat,
+			ExpectedAdded: []string{"emea"},
+			ExpectedProfiles: map[string]Profile{
+				"prod": {Name: "prod", Active: true, Region: "eu", ClientID: "old", ClientSecret: "old", SecretStore: KeyringSecretStore},
+				"emea": {Name: "emea", Region: "eu", Subdomain: "acme", ClientID: "ei", ClientSecret: "es", SecretStore: KeyringSecretStore},
+			},
+		},
+		"It rejects invalid settings": {
+			Existing:      `{}`,
+			Document:      `{"profiles":[{"name":"prod","region":"ap"}]}`,
+			Format:        JSONFormat,
+			ExpectedError: `profile prod has invalid region "ap", expected us or eu`,
+		},
+		"It rejects missing parents": {
+			Existing:      `{}`,
+			Document:      `{"profiles":[{"name":"reader","parent":"prod","client_id":"ri","client_secret":"rs"}]}`,
+			Format:        JSONFormat,
+			ExpectedError: "parent profile prod of reader does not exist",
+		},
+		"It rejects unknown fields": {
+			Existing:      `{}`,
+			Document:      "profiles:\n- name: prod\n  regoin: us\n",
+			Format:        YAMLFormat,
+			ExpectedError: "unable to read profiles: yaml: unmarshal errors:\n  line 3: field regoin not found in type profiles.SharedProfile",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			profilesSvc := ProfileService{
+				Repository:  MockRepository{StorageMedia: &MockFile{Content: []byte(test.Existing)}},
+				InputReader: &MockCmdLineInput{Content: []byte(test.CmdLineInput)},
+			}
+			added, err := profilesSvc.Import(strings.NewReader(test.Document), test.Format)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedAdded, added)
+			for name, expected := range test.ExpectedProfiles {
+				assert.Equal(t, expected, *profilesSvc.Find(name))
+			}
+			assert.Equal(t, len(test.ExpectedProfiles), len(profilesSvc.Index()))
+		})
+	}
+}
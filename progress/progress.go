@@ -0,0 +1,146 @@
+// Package progress progress.go
+// This module shows how far along a long run of steps is, like importing hundreds of resources one terraform import
+// at a time. On a terminal a bar is redrawn on one line with the count, the rate and the time left, and log messages
+// written through the bar are printed above it. Elsewhere, like in CI, the command logs each step instead.
+//
+// Timings
+// Timings add up how long the steps of each group took, e.g. each resource type, for a summary at the end.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// width of the bar, in characters
+const width = 30
+
+// IsTerminal reports whether the file is a terminal rather than a pipe or a file
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Bar draws the progress of total steps on the last line of out
+type Bar struct {
+	mu      sync.Mutex
+	out     io.Writer
+	total   int
+	done    int
+	label   string
+	started time.Time
+	now     func() time.Time
+}
+
+// New starts a bar for total steps
+func New(out io.Writer, total int) *Bar {
+	return &Bar{out: out, total: total, started: time.Now(), now: time.Now}
+}
+
+// Start shows the label of the step starting, like the address of the resource
+func (b *Bar) Start(label string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.label = label
+	b.draw()
+}
+
+// Increment counts a step as done
+func (b *Bar) Increment() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.done++
+	b.draw()
+}
+
+// Write prints p above the bar, so log messages don't break it
+func (b *Bar) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	io.WriteString(b.out, "\r\033[K")
+	n, err := b.out.Write(p)
+	b.draw()
+	return n, err
+}
+
+// Finish clears the bar, leaving the line for what's printed next
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	io.WriteString(b.out, "\r\033[K")
+}
+
+func (b *Bar) draw() {
+	io.WriteString(b.out, "\r\033[K"+b.line())
+}
+
+// line is the bar, e.g. [=====>    ] 3/10 30% 1.5/s ETA 5s onelogin_apps.app_1. The rate and time left are left out
+// until a step is done
+func (b *Bar) line() string {
+	filled := width
+	percent := 100
+	if b.total > 0 {
+		filled = width * b.done / b.total
+		percent = 100 * b.done / b.total
+	}
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+	line := fmt.Sprintf("[%s] %d/%d %d%%", bar, b.done, b.total, percent)
+	if elapsed := b.now().Sub(b.started); b.done > 0 && elapsed > 0 {
+		left := elapsed / time.Duration(b.done) * time.Duration(b.total-b.done)
+		line += fmt.Sprintf(" %.1f/s ETA %s", float64(b.done)/elapsed.Seconds(), left.Round(time.Second))
+	}
+	if b.label != "" {
+		line += " " + b.label
+	}
+	return line
+}
+
+// Timing is how long the steps of a group took
+type Timing struct {
+	Group    string
+	Count    int
+	Duration time.Duration
+}
+
+// Average is how long a step of the group took on average
+func (t Timing) Average() time.Duration {
+	if t.Count == 0 {
+		return 0
+	}
+	return t.Duration / time.Duration(t.Count)
+}
+
+// Timings add up how long the steps of each group took
+type Timings map[string]*Timing
+
+// Add counts a step of the group that took d
+func (t Timings) Add(group string, d time.Duration) {
+	if t[group] == nil {
+		t[group] = &Timing{Group: group}
+	}
+	t[group].Count++
+	t[group].Duration += d
+}
+
+// Summary lists the groups, the slowest first
+func (t Timings) Summary() []Timing {
+	out := []Timing{}
+	for _, timing := range t {
+		out = append(out, *timing)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Duration != out[j].Duration {
+			return out[i].Duration > out[j].Duration
+		}
+		return out[i].Group < out[j].Group
+	})
+	return out
+}
@@ -0,0 +1,71 @@
+package progress
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLine(t *testing.T) {
+	started := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		Total    int
+		Done     int
+		Label    string
+		Elapsed  time.Duration
+		Expected string
+	}{
+		"It leaves out the rate until a step is done": {
+			Total:    10,
+			Label:    "onelogin_apps.app_0",
+			Expected: "[>                             ] 0/10 0% onelogin_apps.app_0",
+		},
+		"It shows the rate and the time left": {
+			Total:    10,
+			Done:     4,
+			Elapsed:  8 * time.Second,
+			Label:    "onelogin_apps.app_4",
+			Expected: "[============>                 ] 4/10 40% 0.5/s ETA 12s onelogin_apps.app_4",
+		},
+		"It fills the bar when done": {
+			Total:    3,
+			Done:     3,
+			Elapsed:  3 * time.Second,
+			Expected: "[==============================] 3/3 100% 1.0/s ETA 0s",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bar := &Bar{total: test.Total, done: test.Done, label: test.Label, started: started,
+				now: func() time.Time { return started.Add(test.Elapsed) }}
+			assert.Equal(t, test.Expected, bar.line())
+		})
+	}
+}
+
+func TestBarWrite(t *testing.T) {
+	var out bytes.Buffer
+	bar := New(&out, 2)
+	bar.Write([]byte("Rate limited, retrying\n"))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, "\r\033[KRate limited, retrying", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "\r\033[K[>"), "the bar is drawn again below the message")
+}
+
+func TestTimings(t *testing.T) {
+	timings := Timings{}
+	timings.Add("onelogin_roles", time.Second)
+	timings.Add("onelogin_apps", 2*time.Second)
+	timings.Add("onelogin_apps", 4*time.Second)
+	timings.Add("onelogin_users", time.Second)
+
+	summary := timings.Summary()
+	assert.Equal(t, []Timing{
+		{Group: "onelogin_apps", Count: 2, Duration: 6 * time.Second},
+		{Group: "onelogin_roles", Count: 1, Duration: time.Second},
+		{Group: "onelogin_users", Count: 1, Duration: time.Second},
+	}, summary)
+	assert.Equal(t, 3*time.Second, summary[0].Average())
+}
@@ -0,0 +1,55 @@
+package records
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ReadCSV reads CSV with a header row into records, one per row, with fields named by the header unless renamed by
+// columns e.g. {"E-mail": "email"}. Columns renamed to "" are dropped and empty cells are left out, so values are
+// strings and only the fields given are set
+func ReadCSV(r io.Reader, columns map[string]string) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, len(header))
+	mapped := map[string]bool{}
+	for i, column := range header {
+		column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")) // spreadsheets often start files with a byte order mark
+		fields[i] = column
+		if field, ok := columns[column]; ok {
+			fields[i] = field
+			mapped[column] = true
+		}
+	}
+	for column := range columns {
+		if !mapped[column] {
+			return nil, fmt.Errorf("column %s isn't in the header", column)
+		}
+	}
+	out := []Record{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		record := Record{}
+		for i, cell := range row {
+			if i < len(fields) && fields[i] != "" && cell != "" {
+				record[fields[i]] = cell
+			}
+		}
+		out = append(out, record)
+	}
+}
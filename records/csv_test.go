@@ -0,0 +1,50 @@
+package records
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestReadCSV(t *testing.T) {
+	tests := map[string]struct {
+		Input         string
+		Columns       map[string]string
+		Expected      []Record
+		ExpectedError string
+	}{
+		"It names fields after the header": {
+			Input:    "email,firstname\nann@acme.com,Ann\nbo@acme.com,Bo\n",
+			Expected: []Record{{"email": "ann@acme.com", "firstname": "Ann"}, {"email": "bo@acme.com", "firstname": "Bo"}},
+		},
+		"It renames and drops columns": {
+			Input:    "\ufeffE-mail,First Name,Notes\nann@acme.com,Ann,call back\n",
+			Columns:  map[string]string{"E-mail": "email", "First Name": "firstname", "Notes": ""},
+			Expected: []Record{{"email": "ann@acme.com", "firstname": "Ann"}},
+		},
+		"It leaves out empty cells": {
+			Input:    "email,phone\nann@acme.com,\n",
+			Expected: []Record{{"email": "ann@acme.com"}},
+		},
+		"It reads files without rows": {
+			Input:    "",
+			Expected: []Record{},
+		},
+		"It reports mapped columns missing from the header": {
+			Input:         "email\nann@acme.com\n",
+			Columns:       map[string]string{"Mail": "email"},
+			ExpectedError: "column Mail isn't in the header",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ReadCSV(strings.NewReader(test.Input), test.Columns)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
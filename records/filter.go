@@ -0,0 +1,66 @@
+package records
+
+import (
+	"fmt"
+	"strings"
+)
+
+// operators a filter compares a field with, longest first so != isn't read as =
+var operators = []string{"!=", "=", "~"}
+
+// Filter keeps the records whose field compares to the value e.g. email~@example.com
+type Filter struct {
+	Field    string
+	Operator string // = for equal, != for not equal, ~ for containing the value ignoring case
+	Value    string
+}
+
+// ParseFilter reads a filter written as <field><operator><value> e.g. status=1 or email~@example.com
+func ParseFilter(s string) (Filter, error) {
+	index, operator := -1, ""
+	for _, candidate := range operators {
+		if i := strings.Index(s, candidate); i > 0 && (index == -1 || i < index) {
+			index, operator = i, candidate
+		}
+	}
+	if index == -1 {
+		return Filter{}, fmt.Errorf("invalid filter %s, expected <field>=<value>, <field>!=<value> or <field>~<value>", s)
+	}
+	return Filter{Field: strings.TrimSpace(s[:index]), Operator: operator, Value: s[index+len(operator):]}, nil
+}
+
+// ParseFilters reads every filter given with --filter
+func ParseFilters(filters []string) ([]Filter, error) {
+	out := make([]Filter, len(filters))
+	for i, filter := range filters {
+		parsed, err := ParseFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = parsed
+	}
+	return out, nil
+}
+
+// Match reports whether the record's field compares to the filter's value. Missing fields are empty
+func (f Filter) Match(record Record) bool {
+	text := record.Text(f.Field)
+	switch f.Operator {
+	case "!=":
+		return text != f.Value
+	case "~":
+		return strings.Contains(strings.ToLower(text), strings.ToLower(f.Value))
+	default:
+		return text == f.Value
+	}
+}
+
+// MatchAll reports whether the record matches every filter
+func MatchAll(record Record, filters []Filter) bool {
+	for _, filter := range filters {
+		if !filter.Match(record) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,65 @@
+package records
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := map[string]struct {
+		Input         string
+		Expected      Filter
+		ExpectedError string
+	}{
+		"It reads equal filters": {
+			Input:    "status=1",
+			Expected: Filter{Field: "status", Operator: "=", Value: "1"},
+		},
+		"It reads not equal filters": {
+			Input:    "status!=1",
+			Expected: Filter{Field: "status", Operator: "!=", Value: "1"},
+		},
+		"It reads contains filters whose value has an equal sign": {
+			Input:    "comment~a=b",
+			Expected: Filter{Field: "comment", Operator: "~", Value: "a=b"},
+		},
+		"It reports filters without a field": {
+			Input:         "=1",
+			ExpectedError: "invalid filter =1, expected <field>=<value>, <field>!=<value> or <field>~<value>",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseFilter(test.Input)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	record := Record{"email": "Ann@Example.com", "status": json.Number("1")}
+	tests := map[string]struct {
+		Filters  []string
+		Expected bool
+	}{
+		"It matches without filters":                {Expected: true},
+		"It matches contained values ignoring case": {Filters: []string{"email~@example.COM"}, Expected: true},
+		"It matches equal values":                   {Filters: []string{"status=1"}, Expected: true},
+		"It requires every filter to match":         {Filters: []string{"status=1", "email~@acme.com"}, Expected: false},
+		"It treats missing fields as empty":         {Filters: []string{"phone!="}, Expected: false},
+		"It matches values that aren't equal":       {Filters: []string{"status!=2"}, Expected: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			filters, err := ParseFilters(test.Filters)
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, MatchAll(record, filters))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package records records.go
+// This module turns API resources into flat records of fields, so the commands managing resources like users can
+// filter them with --filter, narrow them to --fields, and print them with --output as a table, JSON or CSV the same
+// way whatever the resource.
+//
+// Fields
+// A record's fields are the resource's JSON fields as the API names them e.g. id, email, status. Fields holding
+// objects or arrays are printed as JSON in tables and CSV.
+package records
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Record is a resource by its JSON fields
+type Record map[string]interface{}
+
+// FromJSON decodes a JSON array of objects, or a single object, into records. Numbers are kept as written so ids
+// don't lose precision
+func FromJSON(data []byte) ([]Record, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			return nil, err
+		}
+		return []Record{record}, nil
+	}
+	var out []Record
+	if err := decoder.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// From converts resources, like a slice of the SDK's users or a single one, into records by their JSON fields
+func From(resources interface{}) ([]Record, error) {
+	data, err := json.Marshal(resources)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(data)
+}
+
+// Fields lists the fields of the records, sorted, for printing every field when none were asked for
+func Fields(records []Record) []string {
+	seen := map[string]bool{}
+	fields := []string{}
+	for _, record := range records {
+		for field := range record {
+			if !seen[field] {
+				seen[field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Text is the field's value as printed in tables and CSV: strings and numbers as they are, objects and arrays as
+// JSON, and nothing for fields that are missing or null
+func (r Record) Text(field string) string {
+	switch value := r[field].(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return fmt.Sprint(value)
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprint(value)
+		}
+		return string(data)
+	}
+}
@@ -0,0 +1,58 @@
+package records
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFromJSON(t *testing.T) {
+	tests := map[string]struct {
+		Input         string
+		Expected      []Record
+		ExpectedError string
+	}{
+		"It decodes arrays keeping numbers as written": {
+			Input:    `[{"id":90071992547409931,"email":"ann@acme.com"}]`,
+			Expected: []Record{{"id": json.Number("90071992547409931"), "email": "ann@acme.com"}},
+		},
+		"It decodes single objects": {
+			Input:    ` {"id":1}`,
+			Expected: []Record{{"id": json.Number("1")}},
+		},
+		"It reports bodies that aren't resources": {
+			Input:         `"oops"`,
+			ExpectedError: "json: cannot unmarshal string into Go value of type []records.Record",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := FromJSON([]byte(test.Input))
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	records, _ := FromJSON([]byte(`{"id":12,"email":"ann@acme.com","locked":false,"role_ids":[1,2],"manager":null,"custom_attributes":{"team":"infra"}}`))
+	tests := map[string]string{
+		"id":                "12",
+		"email":             "ann@acme.com",
+		"locked":            "false",
+		"role_ids":          "[1,2]",
+		"manager":           "",
+		"missing":           "",
+		"custom_attributes": `{"team":"infra"}`,
+	}
+	for field, expected := range tests {
+		t.Run(field, func(t *testing.T) {
+			assert.Equal(t, expected, records[0].Text(field))
+		})
+	}
+	assert.Equal(t, []string{"custom_attributes", "email", "id", "locked", "manager", "role_ids"}, Fields(records))
+}
@@ -0,0 +1,53 @@
+package records
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseSet reads fields given as <field>=<value> e.g. status=1 or firstname=Ada into a record. Values that are JSON,
+// like 1, true or [1,2], are set as such and anything else as a string; quote a value to keep it a string e.g. phone="0123"
+func ParseSet(assignments []string) (Record, error) {
+	out := Record{}
+	for _, assignment := range assignments {
+		index := strings.Index(assignment, "=")
+		if index < 1 {
+			return nil, fmt.Errorf("invalid field %s, expected <field>=<value>", assignment)
+		}
+		out[strings.TrimSpace(assignment[:index])] = parseValue(assignment[index+1:])
+	}
+	return out, nil
+}
+
+// parseValue decodes the value when it is JSON, keeping numbers as written like FromJSON
+func parseValue(value string) interface{} {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil || decoder.More() || strings.TrimSpace(value) == "" {
+		return value
+	}
+	return decoded
+}
+
+// Merge returns a record with the fields of every record, later records overriding earlier ones
+func Merge(records ...Record) Record {
+	out := Record{}
+	for _, record := range records {
+		for field, value := range record {
+			out[field] = value
+		}
+	}
+	return out
+}
+
+// JSON encodes the record as an indented JSON object with its fields sorted
+func (r Record) JSON() ([]byte, error) {
+	var out bytes.Buffer
+	if err := writeObject(&out, r, Fields([]Record{r}), ""); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
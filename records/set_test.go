@@ -0,0 +1,51 @@
+package records
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseSet(t *testing.T) {
+	tests := map[string]struct {
+		Input         []string
+		Expected      Record
+		ExpectedError string
+	}{
+		"It sets JSON values as such": {
+			Input:    []string{"status=1", "role_ids=[1,2]", "locked=false"},
+			Expected: Record{"status": json.Number("1"), "role_ids": []interface{}{json.Number("1"), json.Number("2")}, "locked": false},
+		},
+		"It sets anything else as a string": {
+			Input:    []string{"firstname=Ada", "comment=a=b", "phone=0123", "title="},
+			Expected: Record{"firstname": "Ada", "comment": "a=b", "phone": "0123", "title": ""},
+		},
+		"It keeps quoted values strings": {
+			Input:    []string{`username="1234"`},
+			Expected: Record{"username": "1234"},
+		},
+		"It reports fields without a value": {
+			Input:         []string{"status"},
+			ExpectedError: "invalid field status, expected <field>=<value>",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseSet(test.Input)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	merged := Merge(Record{"email": "ann@acme.com", "status": json.Number("0")}, Record{"status": json.Number("1")})
+	assert.Equal(t, Record{"email": "ann@acme.com", "status": json.Number("1")}, merged)
+	data, err := merged.JSON()
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"email\": \"ann@acme.com\",\n  \"status\": 1\n}", string(data))
+}
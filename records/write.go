@@ -0,0 +1,176 @@
+package records
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// formats records can be written in
+const (
+	TableFormat = "table"
+	JSONFormat  = "json"
+	CSVFormat   = "csv"
+	YAMLFormat  = "yaml"
+)
+
+// CheckFormat reports formats records can't be written in, so commands can refuse them before calling the API
+func CheckFormat(format string) error {
+	if format != TableFormat && format != JSONFormat && format != CSVFormat && format != YAMLFormat {
+		return fmt.Errorf("unsupported output %s, expected table, json, yaml or csv", format)
+	}
+	return nil
+}
+
+// Write writes the fields of the records in the format: a table with a header row, a JSON array of objects with
+// the fields in order, a YAML list of them, or CSV with a header row
+func Write(w io.Writer, format string, records []Record, fields []string) error {
+	switch format {
+	case TableFormat:
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		headers := make([]string, len(fields))
+		for i, field := range fields {
+			headers[i] = strings.ToUpper(field)
+		}
+		fmt.Fprintln(table, strings.Join(headers, "\t"))
+		for _, record := range records {
+			fmt.Fprintln(table, strings.Join(texts(record, fields, true), "\t"))
+		}
+		return table.Flush()
+	case JSONFormat:
+		var out bytes.Buffer
+		out.WriteString("[")
+		for i, record := range records {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			out.WriteString("\n  ")
+			if err := writeObject(&out, record, fields, "  "); err != nil {
+				return err
+			}
+		}
+		if len(records) > 0 {
+			out.WriteString("\n")
+		}
+		out.WriteString("]\n")
+		_, err := w.Write(out.Bytes())
+		return err
+	case CSVFormat:
+		writer := csv.NewWriter(w)
+		writer.Write(fields)
+		for _, record := range records {
+			writer.Write(texts(record, fields, false))
+		}
+		writer.Flush()
+		return writer.Error()
+	case YAMLFormat:
+		var out bytes.Buffer
+		if err := Write(&out, JSONFormat, records, fields); err != nil {
+			return err
+		}
+		return writeYAML(w, out.Bytes())
+	}
+	return CheckFormat(format)
+}
+
+// WriteOne writes the fields of a single record: a table of fields and values, a JSON or YAML object, or CSV with a
+// header row
+func WriteOne(w io.Writer, format string, record Record, fields []string) error {
+	switch format {
+	case TableFormat:
+		table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, field := range fields {
+			fmt.Fprintf(table, "%s\t%s\n", field, oneLine(record.Text(field)))
+		}
+		return table.Flush()
+	case JSONFormat:
+		var out bytes.Buffer
+		if err := writeObject(&out, record, fields, ""); err != nil {
+			return err
+		}
+		out.WriteString("\n")
+		_, err := w.Write(out.Bytes())
+		return err
+	case YAMLFormat:
+		var out bytes.Buffer
+		if err := writeObject(&out, record, fields, ""); err != nil {
+			return err
+		}
+		return writeYAML(w, out.Bytes())
+	}
+	return Write(w, format, []Record{record}, fields)
+}
+
+// WriteValue writes a value other than records, like a report, as indented JSON or as YAML with the keys JSON has
+func WriteValue(w io.Writer, format string, value interface{}) error {
+	out, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case JSONFormat:
+		_, err = w.Write(append(out, '\n'))
+		return err
+	case YAMLFormat:
+		return writeYAML(w, out)
+	}
+	return fmt.Errorf("unsupported output %s, expected json or yaml", format)
+}
+
+// writeYAML writes JSON as YAML, which JSON is a subset of. It's read wrapped in an object, as objects read into an
+// ordered mapping read the objects in them the same way, keeping the order of the keys
+func writeYAML(w io.Writer, data []byte) error {
+	var wrapped yaml.MapSlice
+	if err := yaml.Unmarshal(append(append([]byte(`{"value": `), data...), '}'), &wrapped); err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(wrapped[0].Value)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// texts are the record's fields as printed, kept on one line for tables
+func texts(record Record, fields []string, table bool) []string {
+	out := make([]string, len(fields))
+	for i, field := range fields {
+		out[i] = record.Text(field)
+		if table {
+			out[i] = oneLine(out[i])
+		}
+	}
+	return out
+}
+
+// oneLine keeps a value from breaking a table's rows and columns
+func oneLine(s string) string {
+	return strings.NewReplacer("\n", " ", "\t", " ").Replace(s)
+}
+
+// writeObject writes the fields of the record as an indented JSON object, in the order given rather than sorted
+func writeObject(out *bytes.Buffer, record Record, fields []string, indent string) error {
+	out.WriteString("{")
+	for i, field := range fields {
+		if i > 0 {
+			out.WriteString(",")
+		}
+		key, _ := json.Marshal(field)
+		value, err := json.MarshalIndent(record[field], indent+"  ", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "\n%s  %s: %s", indent, key, value)
+	}
+	if len(fields) > 0 {
+		out.WriteString("\n" + indent)
+	}
+	out.WriteString("}")
+	return nil
+}
@@ -0,0 +1,142 @@
+package records
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	records := []Record{
+		{"id": json.Number("1"), "email": "ann@acme.com", "role_ids": []interface{}{json.Number("3")}},
+		{"id": json.Number("2"), "email": "bob@acme.com", "comment": "likes, commas"},
+	}
+	tests := map[string]struct {
+		Format        string
+		Records       []Record
+		Fields        []string
+		Expected      string
+		ExpectedError string
+	}{
+		"It writes tables": {
+			Format:   TableFormat,
+			Records:  records,
+			Fields:   []string{"id", "email", "role_ids"},
+			Expected: "ID  EMAIL         ROLE_IDS\n1   ann@acme.com  [3]\n2   bob@acme.com  \n",
+		},
+		"It writes JSON with the fields in order": {
+			Format:   JSONFormat,
+			Records:  records,
+			Fields:   []string{"id", "email", "role_ids"},
+			Expected: "[\n  {\n    \"id\": 1,\n    \"email\": \"ann@acme.com\",\n    \"role_ids\": [\n      3\n    ]\n  },\n  {\n    \"id\": 2,\n    \"email\": \"bob@acme.com\",\n    \"role_ids\": null\n  }\n]\n",
+		},
+		"It writes empty JSON arrays": {
+			Format:   JSONFormat,
+			Fields:   []string{"id"},
+			Expected: "[]\n",
+		},
+		"It writes CSV": {
+			Format:   CSVFormat,
+			Records:  records,
+			Fields:   []string{"id", "comment"},
+			Expected: "id,comment\n1,\n2,\"likes, commas\"\n",
+		},
+		"It reports unsupported formats": {
+			Format:        "xml",
+			ExpectedError: "unsupported output xml, expected table, json, yaml or csv",
+		},
+		"It writes YAML with the fields in order": {
+			Format:   YAMLFormat,
+			Records:  records,
+			Fields:   []string{"id", "email", "role_ids"},
+			Expected: "- id: 1\n  email: ann@acme.com\n  role_ids:\n  - 3\n- id: 2\n  email: bob@acme.com\n  role_ids: null\n",
+		},
+		"It writes empty YAML lists": {
+			Format:   YAMLFormat,
+			Fields:   []string{"id"},
+			Expected: "[]\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := Write(&out, test.Format, test.Records, test.Fields)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.Expected, out.String())
+		})
+	}
+}
+
+func TestWriteOne(t *testing.T) {
+	record := Record{"id": json.Number("1"), "email": "ann@acme.com"}
+	tests := map[string]struct {
+		Format   string
+		Expected string
+	}{
+		"It writes a table of fields and values": {
+			Format:   TableFormat,
+			Expected: "email  ann@acme.com\nid     1\n",
+		},
+		"It writes a JSON object": {
+			Format:   JSONFormat,
+			Expected: "{\n  \"email\": \"ann@acme.com\",\n  \"id\": 1\n}\n",
+		},
+		"It writes CSV": {
+			Format:   CSVFormat,
+			Expected: "email,id\nann@acme.com,1\n",
+		},
+		"It writes a YAML object": {
+			Format:   YAMLFormat,
+			Expected: "email: ann@acme.com\nid: 1\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			assert.Nil(t, WriteOne(&out, test.Format, record, []string{"email", "id"}))
+			assert.Equal(t, test.Expected, out.String())
+		})
+	}
+}
+
+func TestWriteValue(t *testing.T) {
+	value := struct {
+		Serial    int               `json:"serial"`
+		Resources []map[string]bool `json:"resources"`
+	}{Serial: 3, Resources: []map[string]bool{{"b": true, "a": false}}}
+	tests := map[string]struct {
+		Format        string
+		Expected      string
+		ExpectedError string
+	}{
+		"It writes indented JSON": {
+			Format:   JSONFormat,
+			Expected: "{\n  \"serial\": 3,\n  \"resources\": [\n    {\n      \"a\": false,\n      \"b\": true\n    }\n  ]\n}\n",
+		},
+		"It writes YAML with the keys of the JSON": {
+			Format:   YAMLFormat,
+			Expected: "serial: 3\nresources:\n- a: false\n  b: true\n",
+		},
+		"It reports formats other than JSON and YAML": {
+			Format:        TableFormat,
+			ExpectedError: "unsupported output table, expected json or yaml",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := WriteValue(&out, test.Format, value)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Package reports reports.go
+// This module works out the built-in reports security reviews ask for, from resources already fetched from the
+// OneLogin API, so each report is a list of records printed like any other command's:
+//
+//	inactive-users          users who haven't signed in for a number of days, or ever
+//	apps-without-owners     apps no delegated administrator is assigned a privilege over
+//	users-without-mfa       active users with no MFA factor enrolled
+//	expiring-saml-certs     SAML apps whose signing certificate expires within a number of days
+//	role-membership-matrix  a row per user with a column per role marked x for the roles they are in
+package reports
+
+import (
+	"github.com/onelogin/onelogin/privileges"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/sso"
+	"sort"
+	"strings"
+	"time"
+)
+
+// names of the built-in reports
+const (
+	InactiveUsers        = "inactive-users"
+	AppsWithoutOwners    = "apps-without-owners"
+	UsersWithoutMFA      = "users-without-mfa"
+	ExpiringSAMLCerts    = "expiring-saml-certs"
+	RoleMembershipMatrix = "role-membership-matrix"
+)
+
+// Names lists the built-in reports
+var Names = []string{InactiveUsers, AppsWithoutOwners, UsersWithoutMFA, ExpiringSAMLCerts, RoleMembershipMatrix}
+
+// activeStatus is the status of users who can sign in
+const activeStatus = "1"
+
+// Inactive lists the users who last signed in more than days before now, or never did, with days_inactive set to
+// how long ago that was, left blank for users who never signed in. Longest inactive first
+func Inactive(users []records.Record, now time.Time, days int) []records.Record {
+	cutoff := now.AddDate(0, 0, -days)
+	out := []records.Record{}
+	for _, user := range users {
+		lastLogin, err := time.Parse(time.RFC3339, user.Text("last_login"))
+		if err != nil {
+			out = append(out, records.Merge(user, records.Record{"days_inactive": nil}))
+			continue
+		}
+		if lastLogin.Before(cutoff) {
+			out = append(out, records.Merge(user, records.Record{"days_inactive": int(now.Sub(lastLogin).Hours() / 24)}))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Text("last_login") < out[j].Text("last_login")
+	})
+	return out
+}
+
+// OwnedApps lists the ids of the apps the statements allow apps actions on by naming them in a scope like apps/123.
+// Statements scoped to every app don't make anyone an app's owner
+func OwnedApps(statements []privileges.Statement) map[string]bool {
+	owned := map[string]bool{}
+	for _, statement := range statements {
+		if strings.EqualFold(statement.Effect, "Deny") || !hasAppsAction(statement.Action) {
+			continue
+		}
+		for _, scope := range statement.Scope {
+			if id := strings.TrimPrefix(scope, "apps/"); id != scope && id != "*" {
+				owned[id] = true
+			}
+		}
+	}
+	return owned
+}
+
+// hasAppsAction reports whether any action is on apps
+func hasAppsAction(actions []string) bool {
+	for _, action := range actions {
+		if strings.HasPrefix(strings.ToLower(action), "apps:") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutOwners lists the apps that aren't owned
+func WithoutOwners(apps []records.Record, owned map[string]bool) []records.Record {
+	out := []records.Record{}
+	for _, app := range apps {
+		if !owned[app.Text("id")] {
+			out = append(out, app)
+		}
+	}
+	return out
+}
+
+// WithoutMFA lists the active users with no factors, given how many factors each user id has enrolled
+func WithoutMFA(users []records.Record, factors map[string]int) []records.Record {
+	out := []records.Record{}
+	for _, user := range users {
+		if user.Text("status") == activeStatus && factors[user.Text("id")] == 0 {
+			out = append(out, user)
+		}
+	}
+	return out
+}
+
+// ExpiringCertificates lists the apps whose signing certificate expires within days of now, or has expired, with the
+// certificate's name, expiry and days left from their sign in details. Soonest first
+func ExpiringCertificates(apps []records.Record, now time.Time, days int) []records.Record {
+	out := []records.Record{}
+	for _, app := range apps {
+		summary := sso.Summary(app, now)
+		if left, ok := summary["certificate_days_left"].(int); ok && left <= days {
+			out = append(out, records.Merge(app, summary))
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i]["certificate_days_left"].(int) < out[j]["certificate_days_left"].(int)
+	})
+	return out
+}
+
+// MembershipMatrix lists a row per user in any role, with their id and email and a column per role named like the
+// role, marked x when the user is in it. members lists the user ids in each role by role id. The fields are returned
+// in order, roles sorted by name
+func MembershipMatrix(roles []records.Record, members map[string][]string, users []records.Record) ([]records.Record, []string) {
+	sorted := append([]records.Record{}, roles...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Text("name")) < strings.ToLower(sorted[j].Text("name"))
+	})
+	fields := []string{"id", "email"}
+	rows := map[string]records.Record{}
+	for _, role := range sorted {
+		column := role.Text("name")
+		fields = append(fields, column)
+		for _, userID := range members[role.Text("id")] {
+			if rows[userID] == nil {
+				rows[userID] = records.Record{"id": userID}
+			}
+			rows[userID][column] = "x"
+		}
+	}
+	out := []records.Record{}
+	for _, user := range users {
+		if row := rows[user.Text("id")]; row != nil {
+			row["id"], row["email"] = user["id"], user["email"]
+			out = append(out, row)
+			delete(rows, user.Text("id"))
+		}
+	}
+	ids := []string{} // members that weren't among the users, kept rather than lost
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		out = append(out, rows[id])
+	}
+	return out, fields
+}
@@ -0,0 +1,116 @@
+package reports
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/onelogin/onelogin/privileges"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestInactive(t *testing.T) {
+	now := time.Date(2020, 3, 10, 12, 0, 0, 0, time.UTC)
+	users := []records.Record{
+		{"id": json.Number("1"), "last_login": "2020-03-01T09:00:00Z"},
+		{"id": json.Number("2"), "last_login": "2019-11-01T12:00:00Z"},
+		{"id": json.Number("3"), "last_login": nil},
+		{"id": json.Number("4"), "last_login": "2020-01-01T12:00:00Z"},
+	}
+	expected := []records.Record{
+		{"id": json.Number("3"), "last_login": nil, "days_inactive": nil},
+		{"id": json.Number("2"), "last_login": "2019-11-01T12:00:00Z", "days_inactive": 130},
+		{"id": json.Number("4"), "last_login": "2020-01-01T12:00:00Z", "days_inactive": 69},
+	}
+	assert.Equal(t, expected, Inactive(users, now, 30))
+}
+
+func TestOwnedApps(t *testing.T) {
+	tests := map[string]struct {
+		Statements []privileges.Statement
+		Expected   map[string]bool
+	}{
+		"It owns the apps scoped by id": {
+			Statements: []privileges.Statement{{Effect: "Allow", Action: []string{"apps:Update"}, Scope: []string{"apps/1", "apps/2"}}},
+			Expected:   map[string]bool{"1": true, "2": true},
+		},
+		"It doesn't own apps scoped with a wildcard": {
+			Statements: []privileges.Statement{{Effect: "Allow", Action: []string{"apps:*"}, Scope: []string{"*", "apps/*"}}},
+			Expected:   map[string]bool{},
+		},
+		"It only counts statements allowing apps actions": {
+			Statements: []privileges.Statement{
+				{Effect: "Allow", Action: []string{"users:List"}, Scope: []string{"apps/1"}},
+				{Effect: "Deny", Action: []string{"apps:Update"}, Scope: []string{"apps/2"}},
+			},
+			Expected: map[string]bool{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, OwnedApps(test.Statements))
+		})
+	}
+}
+
+func TestWithoutOwners(t *testing.T) {
+	apps := []records.Record{{"id": json.Number("1")}, {"id": json.Number("2")}}
+	assert.Equal(t, []records.Record{{"id": json.Number("2")}}, WithoutOwners(apps, map[string]bool{"1": true}))
+}
+
+func TestWithoutMFA(t *testing.T) {
+	users := []records.Record{
+		{"id": json.Number("1"), "status": json.Number("1")},
+		{"id": json.Number("2"), "status": json.Number("1")},
+		{"id": json.Number("3"), "status": json.Number("2")},
+	}
+	assert.Equal(t, []records.Record{{"id": json.Number("2"), "status": json.Number("1")}}, WithoutMFA(users, map[string]int{"1": 2}))
+}
+
+func TestExpiringCertificates(t *testing.T) {
+	now := time.Date(2020, 3, 10, 12, 0, 0, 0, time.UTC)
+	app := func(id string, expires time.Time) records.Record {
+		return records.Record{"id": id, "sso": map[string]interface{}{"certificate": map[string]interface{}{"name": "cert " + id, "value": testCertificate(t, expires)}}}
+	}
+	apps := []records.Record{
+		app("later", now.AddDate(0, 6, 0)),
+		app("soon", now.AddDate(0, 0, 20)),
+		app("expired", now.AddDate(0, 0, -3)),
+		{"id": "oidc"},
+	}
+	actual := ExpiringCertificates(apps, now, 30)
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "expired", actual[0]["id"])
+	assert.Equal(t, -3, actual[0]["certificate_days_left"])
+	assert.Equal(t, "soon", actual[1]["id"])
+	assert.Equal(t, "cert soon", actual[1]["certificate_name"])
+	assert.Equal(t, "2020-03-30T12:00:00Z", actual[1]["certificate_expires_at"])
+}
+
+func TestMembershipMatrix(t *testing.T) {
+	roles := []records.Record{{"id": json.Number("1"), "name": "Sales"}, {"id": json.Number("2"), "name": "Eng"}}
+	members := map[string][]string{"1": {"7", "9"}, "2": {"7"}}
+	users := []records.Record{{"id": json.Number("7"), "email": "ann@example.com"}, {"id": json.Number("8"), "email": "bob@example.com"}}
+	rows, fields := MembershipMatrix(roles, members, users)
+	assert.Equal(t, []string{"id", "email", "Eng", "Sales"}, fields)
+	assert.Equal(t, []records.Record{
+		{"id": json.Number("7"), "email": "ann@example.com", "Eng": "x", "Sales": "x"},
+		{"id": "9", "Sales": "x"},
+	}, rows)
+}
+
+func testCertificate(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test"}, NotBefore: notAfter.AddDate(-1, 0, 0), NotAfter: notAfter}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
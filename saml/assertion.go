@@ -0,0 +1,93 @@
+// Package saml assertion.go
+// This module reads the SAML responses OneLogin's SAML Assertion API generates for an app, so the attributes an app
+// would receive can be checked without signing in through a browser. Only what an app maps is read: the subject, the
+// issuer and audience, when the assertion is valid, and its attributes. Signatures aren't verified.
+package saml
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Assertion is what a SAML response asserts about the user
+type Assertion struct {
+	Issuer       string
+	NameID       string
+	NameIDFormat string
+	Audience     string
+	NotBefore    string
+	NotOnOrAfter string
+	Attributes   []Attribute // in the order the assertion lists them
+}
+
+// Attribute is an attribute of the user with its values
+type Attribute struct {
+	Name   string
+	Values []string
+}
+
+// response is the part of a SAML response read, matched by local names so any namespace prefixes do
+type response struct {
+	Encrypted *struct{} `xml:"EncryptedAssertion"`
+	Assertion *struct {
+		Issuer  string `xml:"Issuer"`
+		Subject struct {
+			NameID struct {
+				Format string `xml:"Format,attr"`
+				Value  string `xml:",chardata"`
+			} `xml:"NameID"`
+		} `xml:"Subject"`
+		Conditions struct {
+			NotBefore    string `xml:"NotBefore,attr"`
+			NotOnOrAfter string `xml:"NotOnOrAfter,attr"`
+			Audience     string `xml:"AudienceRestriction>Audience"`
+		} `xml:"Conditions"`
+		Attributes []struct {
+			Name   string   `xml:"Name,attr"`
+			Values []string `xml:"AttributeValue"`
+		} `xml:"AttributeStatement>Attribute"`
+	} `xml:"Assertion"`
+}
+
+// Parse reads the assertion in a base64 encoded SAML response, as the SAML Assertion API returns it
+func Parse(encoded string) (Assertion, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return Assertion{}, fmt.Errorf("unable to decode the SAML response: %s", err)
+	}
+	return ParseXML(data)
+}
+
+// ParseXML reads the assertion in a SAML response
+func ParseXML(data []byte) (Assertion, error) {
+	var parsed response
+	if err := xml.Unmarshal(data, &parsed); err != nil {
+		return Assertion{}, fmt.Errorf("unable to read the SAML response: %s", err)
+	}
+	if parsed.Assertion == nil {
+		if parsed.Encrypted != nil {
+			return Assertion{}, fmt.Errorf("the assertion is encrypted, turn off encryption for the app to read it")
+		}
+		return Assertion{}, fmt.Errorf("the SAML response has no assertion")
+	}
+	assertion := parsed.Assertion
+	out := Assertion{
+		Issuer:       strings.TrimSpace(assertion.Issuer),
+		NameID:       strings.TrimSpace(assertion.Subject.NameID.Value),
+		NameIDFormat: assertion.Subject.NameID.Format,
+		Audience:     strings.TrimSpace(assertion.Conditions.Audience),
+		NotBefore:    assertion.Conditions.NotBefore,
+		NotOnOrAfter: assertion.Conditions.NotOnOrAfter,
+		Attributes:   []Attribute{},
+	}
+	for _, attribute := range assertion.Attributes {
+		values := []string{}
+		for _, value := range attribute.Values {
+			values = append(values, strings.TrimSpace(value))
+		}
+		out.Attributes = append(out.Attributes, Attribute{Name: attribute.Name, Values: values})
+	}
+	return out, nil
+}
@@ -0,0 +1,76 @@
+package saml
+
+import (
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const samlResponse = `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion">
+  <saml:Issuer>https://app.onelogin.com/saml/metadata/123</saml:Issuer>
+  <saml:Assertion>
+    <saml:Issuer>https://app.onelogin.com/saml/metadata/123</saml:Issuer>
+    <saml:Subject>
+      <saml:NameID Format="urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress">ann@example.com</saml:NameID>
+    </saml:Subject>
+    <saml:Conditions NotBefore="2020-01-31T09:00:00Z" NotOnOrAfter="2020-01-31T09:03:00Z">
+      <saml:AudienceRestriction><saml:Audience>https://sp.example.com</saml:Audience></saml:AudienceRestriction>
+    </saml:Conditions>
+    <saml:AttributeStatement>
+      <saml:Attribute Name="email"><saml:AttributeValue>ann@example.com</saml:AttributeValue></saml:Attribute>
+      <saml:Attribute Name="groups">
+        <saml:AttributeValue>admins</saml:AttributeValue>
+        <saml:AttributeValue>sales</saml:AttributeValue>
+      </saml:Attribute>
+      <saml:Attribute Name="department"/>
+    </saml:AttributeStatement>
+  </saml:Assertion>
+</samlp:Response>`
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		Input         string
+		Expected      Assertion
+		ExpectedError string
+	}{
+		"It reads the subject, conditions and attributes": {
+			Input: base64.StdEncoding.EncodeToString([]byte(samlResponse)),
+			Expected: Assertion{
+				Issuer:       "https://app.onelogin.com/saml/metadata/123",
+				NameID:       "ann@example.com",
+				NameIDFormat: "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress",
+				Audience:     "https://sp.example.com",
+				NotBefore:    "2020-01-31T09:00:00Z",
+				NotOnOrAfter: "2020-01-31T09:03:00Z",
+				Attributes: []Attribute{
+					{Name: "email", Values: []string{"ann@example.com"}},
+					{Name: "groups", Values: []string{"admins", "sales"}},
+					{Name: "department", Values: []string{}},
+				},
+			},
+		},
+		"It reports encrypted assertions": {
+			Input:         base64.StdEncoding.EncodeToString([]byte(`<Response><EncryptedAssertion/></Response>`)),
+			ExpectedError: "the assertion is encrypted, turn off encryption for the app to read it",
+		},
+		"It reports responses without an assertion": {
+			Input:         base64.StdEncoding.EncodeToString([]byte(`<Response/>`)),
+			ExpectedError: "the SAML response has no assertion",
+		},
+		"It reports responses that aren't base64": {
+			Input:         "<Response/>",
+			ExpectedError: "unable to decode the SAML response: illegal base64 data at input byte 0",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Parse(test.Input)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
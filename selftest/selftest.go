@@ -0,0 +1,267 @@
+// Package selftest checks the CLI end to end against a sandbox account: it creates a throwaway role, user and app,
+// finds them the way terraform-import does, generates their import commands and configuration, verifies it, and
+// deletes them again. Changes to the API or the provider that break importing show up here before users hit them
+package selftest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"github.com/onelogin/onelogin/terraform/import"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// API sends requests to the OneLogin API, like clients.OneLoginAPI
+type API interface {
+	Do(method string, path string, query url.Values, body []byte) ([]byte, http.Header, error)
+}
+
+// Importables creates the importable of a resource type, like tfimportables.ImportableList
+type Importables interface {
+	GetImportable(importableType string) (tfimportables.Importable, error)
+}
+
+// DefaultConnector is the connector of the app created, OpenId Connect (OIDC)
+const DefaultConnector = 108419
+
+// Names of the steps, in the order they run
+const (
+	CreateStep   = "create"
+	DiscoverStep = "discover"
+	GenerateStep = "generate"
+	VerifyStep   = "verify"
+	CleanupStep  = "cleanup"
+)
+
+// Result is how a step went
+type Result struct {
+	Step     string `json:"step"`
+	Passed   bool   `json:"passed"`
+	Detail   string `json:"detail,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report lists the result of every step run
+type Report struct {
+	Results []Result `json:"results"`
+}
+
+// Failed counts the steps that failed
+func (r Report) Failed() int {
+	failed := 0
+	for _, result := range r.Results {
+		if !result.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Verifier checks generated configuration against the provider, e.g. by running terraform validate on it in a
+// scratch workspace, and returns the provider schemas to check the remote's data against
+type Verifier func(config []byte) (*tfschema.ProviderSchemas, error)
+
+// created is a throwaway resource, by the path it is deleted at and the type it is imported as
+type created struct {
+	importableType string
+	path           string
+	id             string
+}
+
+// Suite runs the steps against the account behind API
+type Suite struct {
+	API         API
+	Importables Importables
+	Prefix      string   // names the throwaway resources so they can be told apart, and cleaned up by hand if need be
+	Connector   int      // connector of the app, DefaultConnector when 0
+	Verify      Verifier // checks the configuration against the provider, only parsed when nil
+	Keep        bool     // leaves the throwaway resources in the account instead of deleting them
+
+	created   []created
+	resources []tfimportables.ResourceDefinition
+	plan      []tfimport.PlannedImport
+	config    []byte
+}
+
+// Run takes the steps in order, stopping at the first that fails. Whatever was created is deleted regardless
+func (s *Suite) Run() Report {
+	report := Report{}
+	steps := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{CreateStep, s.create},
+		{DiscoverStep, s.discover},
+		{GenerateStep, s.generate},
+		{VerifyStep, s.verify},
+	}
+	for _, step := range steps {
+		result := run(step.name, step.run)
+		report.Results = append(report.Results, result)
+		if !result.Passed {
+			break
+		}
+	}
+	if s.Keep {
+		logger.Info("Keeping the throwaway resources", "name", s.Prefix)
+		return report
+	}
+	report.Results = append(report.Results, run(CleanupStep, s.cleanup))
+	return report
+}
+
+// run times the step and logs how it went. A step that panics fails, so what was created is still cleaned up
+func run(name string, step func() (string, error)) Result {
+	logger.Info("Running selftest step", "step", name)
+	started := time.Now()
+	detail, err := func() (detail string, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				err = fmt.Errorf("panicked: %v", recovered)
+			}
+		}()
+		return step()
+	}()
+	result := Result{Step: name, Passed: err == nil, Detail: detail, Duration: time.Since(started).Round(time.Millisecond).String()}
+	if err != nil {
+		result.Detail = err.Error()
+		logger.Warn("Selftest step failed", "step", name, "error", err)
+	}
+	return result
+}
+
+// create makes a role, a user and an app named after the prefix
+func (s *Suite) create() (string, error) {
+	connector := s.Connector
+	if connector == 0 {
+		connector = DefaultConnector
+	}
+	resources := []struct {
+		importableType string
+		path           string
+		body           map[string]interface{}
+	}{
+		{"onelogin_roles", "/api/2/roles", map[string]interface{}{"name": s.Prefix}},
+		{"onelogin_users", "/api/2/users", map[string]interface{}{"username": s.Prefix, "email": s.Prefix + "@example.com", "firstname": "Selftest", "lastname": "OneLogin CLI"}},
+		{"onelogin_apps", "/api/2/apps", map[string]interface{}{"name": s.Prefix, "connector_id": connector}},
+	}
+	for _, resource := range resources {
+		body, err := json.Marshal(resource.body)
+		if err != nil {
+			return "", err
+		}
+		data, _, err := s.API.Do(http.MethodPost, resource.path, nil, body)
+		if err != nil {
+			return "", fmt.Errorf("unable to create %s: %s", resource.importableType, err)
+		}
+		found, err := records.FromJSON(data)
+		if err != nil || len(found) == 0 || found[0].Text("id") == "" {
+			return "", fmt.Errorf("unable to read the %s created: %s", resource.importableType, data)
+		}
+		id := found[0].Text("id")
+		s.created = append(s.created, created{importableType: resource.importableType, path: resource.path + "/" + id, id: id})
+		logger.Info("Created throwaway resource", "type", resource.importableType, "id", id, "name", s.Prefix)
+	}
+	return fmt.Sprintf("%d resources named %s", len(s.created), s.Prefix), nil
+}
+
+// discover finds each resource created through its importable, as terraform-import --id does
+func (s *Suite) discover() (string, error) {
+	for _, resource := range s.created {
+		importable, err := s.Importables.GetImportable(resource.importableType)
+		if err != nil {
+			return "", err
+		}
+		id := resource.id
+		found, err := importable.ImportFromRemote(&id)
+		if err != nil {
+			return "", fmt.Errorf("unable to find %s %s: %s", resource.importableType, id, err)
+		}
+		if len(found) != 1 || found[0].ImportID != id {
+			return "", fmt.Errorf("expected %s %s to be found once, found %d resources", resource.importableType, id, len(found))
+		}
+		s.resources = append(s.resources, found[0])
+	}
+	return fmt.Sprintf("%d resources", len(s.resources)), nil
+}
+
+// generate plans the imports of the resources found, as --emit-import-script does, and writes their configuration
+func (s *Suite) generate() (string, error) {
+	var plan bytes.Buffer
+	if err := tfimport.WriteImportScript(s.resources, "terraform", "json", &plan); err != nil {
+		return "", fmt.Errorf("unable to plan imports: %s", err)
+	}
+	if err := json.Unmarshal(plan.Bytes(), &s.plan); err != nil {
+		return "", fmt.Errorf("unable to read the import plan: %s", err)
+	}
+	named := make([]tfimportables.ResourceDefinition, len(s.resources))
+	for i, resource := range s.resources {
+		resource.Name = tfimport.ImportName(resource, i)
+		named[i] = resource
+	}
+	state, err := stateparser.FromRemote(named)
+	if err != nil {
+		return "", err
+	}
+	if s.config, err = stateparser.Render(state, stateparser.Options{}); err != nil {
+		return "", fmt.Errorf("unable to render configuration: %s", err)
+	}
+	return fmt.Sprintf("%d imports, %d bytes of configuration", len(s.plan), len(s.config)), nil
+}
+
+// verify parses the configuration back, then checks it and the remote's data against the provider
+func (s *Suite) verify() (string, error) {
+	headers, err := tfimport.ParseDefinitionHeaders("main.tf", s.config)
+	if err != nil {
+		return "", fmt.Errorf("generated configuration doesn't parse: %s", err)
+	}
+	for _, planned := range s.plan {
+		if headers.Resources[planned.Address] == 0 {
+			return "", fmt.Errorf("generated configuration has no block for the import of %s %s at %s", planned.Type, planned.ID, planned.Address)
+		}
+	}
+	if s.Verify == nil {
+		return "parsed, not checked against the provider", nil
+	}
+	schemas, err := s.Verify(s.config)
+	if err != nil {
+		return "", err
+	}
+	invalid, err := stateparser.Validate(s.resources, schemas)
+	if err != nil {
+		return "", err
+	}
+	if len(invalid) > 0 {
+		problems := []string{}
+		for _, resource := range invalid {
+			problems = append(problems, fmt.Sprintf("%s %s: %s", resource.Resource.Type, resource.Resource.ImportID, strings.Join(resource.Problems, ", ")))
+		}
+		return "", fmt.Errorf("the provider rejects data from the API: %s", strings.Join(problems, "; "))
+	}
+	return "checked against the provider", nil
+}
+
+// cleanup deletes what was created, newest first, carrying on past failures so as little as possible is left behind
+func (s *Suite) cleanup() (string, error) {
+	failed := []string{}
+	for i := len(s.created) - 1; i >= 0; i-- {
+		resource := s.created[i]
+		if _, _, err := s.API.Do(http.MethodDelete, resource.path, nil, nil); err != nil {
+			failed = append(failed, fmt.Sprintf("%s %s: %s", resource.importableType, resource.id, err))
+			continue
+		}
+		logger.Info("Deleted throwaway resource", "type", resource.importableType, "id", resource.id)
+	}
+	if len(failed) > 0 {
+		return "", fmt.Errorf("unable to delete %s, delete them by hand", strings.Join(failed, "; "))
+	}
+	return fmt.Sprintf("%d resources deleted", len(s.created)), nil
+}
@@ -0,0 +1,147 @@
+package selftest
+
+import (
+	"errors"
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// MockAPI creates resources with ids counting up from 10, failing the creation of the paths in Fail, and records
+// every request
+type MockAPI struct {
+	Fail     map[string]bool
+	Requests []string
+}
+
+func (m *MockAPI) Do(method string, path string, query url.Values, body []byte) ([]byte, http.Header, error) {
+	m.Requests = append(m.Requests, method+" "+path)
+	if m.Fail[path] {
+		return nil, nil, errors.New("403 Forbidden")
+	}
+	if method == http.MethodPost {
+		return []byte(fmt.Sprintf(`{"id": %d}`, 9+len(m.Requests))), nil, nil
+	}
+	return nil, nil, nil
+}
+
+// MockImportable finds the resource with any id, unless Missing. Panics when asked to
+type MockImportable struct {
+	Type    string
+	Missing bool
+	Panic   bool
+}
+
+func (i MockImportable) ImportFromRemote(searchID *string) ([]tfimportables.ResourceDefinition, error) {
+	if i.Panic {
+		panic("nil pointer dereference")
+	}
+	if i.Missing {
+		return []tfimportables.ResourceDefinition{}, nil
+	}
+	return []tfimportables.ResourceDefinition{{Provider: "onelogin", Type: i.Type, Name: "selftest", ImportID: *searchID, Remote: map[string]interface{}{"name": "selftest"}}}, nil
+}
+
+func (i MockImportable) HCLShape() interface{} {
+	return &map[string]interface{}{}
+}
+
+type MockImportables map[string]tfimportables.Importable
+
+func (m MockImportables) GetImportable(importableType string) (tfimportables.Importable, error) {
+	return m[importableType], nil
+}
+
+func TestRun(t *testing.T) {
+	importables := MockImportables{
+		"onelogin_roles": MockImportable{Type: "onelogin_roles"},
+		"onelogin_users": MockImportable{Type: "onelogin_users"},
+		"onelogin_apps":  MockImportable{Type: "onelogin_oidc_apps"},
+	}
+	tests := map[string]struct {
+		Fail             map[string]bool
+		Importables      MockImportables
+		Verify           Verifier
+		Keep             bool
+		ExpectedSteps    []string // step names, with a ! when it failed
+		ExpectedRequests []string
+	}{
+		"It creates, finds, generates, verifies and deletes the resources": {
+			ExpectedSteps:    []string{"create", "discover", "generate", "verify", "cleanup"},
+			ExpectedRequests: []string{"POST /api/2/roles", "POST /api/2/users", "POST /api/2/apps", "DELETE /api/2/apps/12", "DELETE /api/2/users/11", "DELETE /api/2/roles/10"},
+		},
+		"It deletes what was created when creating fails": {
+			Fail:             map[string]bool{"/api/2/apps": true},
+			ExpectedSteps:    []string{"!create", "cleanup"},
+			ExpectedRequests: []string{"POST /api/2/roles", "POST /api/2/users", "POST /api/2/apps", "DELETE /api/2/users/11", "DELETE /api/2/roles/10"},
+		},
+		"It stops when a resource isn't found, deleting them regardless": {
+			Importables:      MockImportables{"onelogin_roles": MockImportable{Type: "onelogin_roles", Missing: true}},
+			ExpectedSteps:    []string{"create", "!discover", "cleanup"},
+			ExpectedRequests: []string{"POST /api/2/roles", "POST /api/2/users", "POST /api/2/apps", "DELETE /api/2/apps/12", "DELETE /api/2/users/11", "DELETE /api/2/roles/10"},
+		},
+		"It fails a step that panics, deleting the resources regardless": {
+			Importables:      MockImportables{"onelogin_roles": MockImportable{Type: "onelogin_roles", Panic: true}},
+			ExpectedSteps:    []string{"create", "!discover", "cleanup"},
+			ExpectedRequests: []string{"POST /api/2/roles", "POST /api/2/users", "POST /api/2/apps", "DELETE /api/2/apps/12", "DELETE /api/2/users/11", "DELETE /api/2/roles/10"},
+		},
+		"It fails verification when the provider rejects the configuration": {
+			Verify: func(config []byte) (*tfschema.ProviderSchemas, error) {
+				return nil, errors.New("terraform validate: exit status 1")
+			},
+			ExpectedSteps:    []string{"create", "discover", "generate", "!verify", "cleanup"},
+			ExpectedRequests: []string{"POST /api/2/roles", "POST /api/2/users", "POST /api/2/apps", "DELETE /api/2/apps/12", "DELETE /api/2/users/11", "DELETE /api/2/roles/10"},
+		},
+		"It reports resources it couldn't delete": {
+			Fail:             map[string]bool{"/api/2/users/11": true},
+			ExpectedSteps:    []string{"create", "discover", "generate", "verify", "!cleanup"},
+			ExpectedRequests: []string{"POST /api/2/roles", "POST /api/2/users", "POST /api/2/apps", "DELETE /api/2/apps/12", "DELETE /api/2/users/11", "DELETE /api/2/roles/10"},
+		},
+		"It keeps the resources when asked to": {
+			Keep:             true,
+			ExpectedSteps:    []string{"create", "discover", "generate", "verify"},
+			ExpectedRequests: []string{"POST /api/2/roles", "POST /api/2/users", "POST /api/2/apps"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &MockAPI{Fail: test.Fail}
+			suite := Suite{API: api, Importables: importables, Prefix: "onelogin-cli-selftest-1", Verify: test.Verify, Keep: test.Keep}
+			if test.Importables != nil {
+				suite.Importables = test.Importables
+			}
+			report := suite.Run()
+			steps := make([]string, len(report.Results))
+			failed := 0
+			for i, result := range report.Results {
+				steps[i] = result.Step
+				if !result.Passed {
+					steps[i] = "!" + result.Step
+					failed++
+				}
+			}
+			assert.Equal(t, test.ExpectedSteps, steps)
+			assert.Equal(t, failed, report.Failed())
+			assert.Equal(t, test.ExpectedRequests, api.Requests)
+		})
+	}
+}
+
+func TestRunDetails(t *testing.T) {
+	api := &MockAPI{Fail: map[string]bool{"/api/2/users/11": true}}
+	suite := Suite{API: api, Importables: MockImportables{
+		"onelogin_roles": MockImportable{Type: "onelogin_roles"},
+		"onelogin_users": MockImportable{Type: "onelogin_users"},
+		"onelogin_apps":  MockImportable{Type: "onelogin_oidc_apps"},
+	}, Prefix: "onelogin-cli-selftest-1"}
+	report := suite.Run()
+	assert.Equal(t, "3 resources named onelogin-cli-selftest-1", report.Results[0].Detail)
+	assert.True(t, strings.HasPrefix(report.Results[2].Detail, "3 imports, "))
+	assert.Equal(t, "parsed, not checked against the provider", report.Results[3].Detail)
+	assert.Equal(t, "unable to delete onelogin_users 11: 403 Forbidden, delete them by hand", report.Results[4].Detail)
+}
@@ -0,0 +1,120 @@
+// Package smarthooks hook.go
+// This module turns a directory holding a Smart Hook into the hook the OneLogin API creates, so hooks can live in
+// version control and be deployed from CI. The directory holds the hook's code and a hook.yaml describing it:
+//
+//	type: pre-authentication
+//	runtime: nodejs18.x
+//	function: hook.js          # the default
+//	timeout: 1
+//	retries: 0
+//	env_vars: [API_KEY]        # set from the environment variables of the same name when deploying
+//	packages:
+//	  axios: 1.6.0
+//	options:
+//	  location_enabled: true
+//
+// Testing hooks
+// Hooks can be run with node before or after they are deployed, with a context read from a payload file, to see what
+// they return without signing in.
+package smarthooks
+
+import (
+	"encoding/base64"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestFile is the file describing the hook in its directory
+const ManifestFile = "hook.yaml"
+
+// defaults of hooks that don't say otherwise
+const (
+	DefaultRuntime  = "nodejs18.x"
+	DefaultFunction = "hook.js"
+	DefaultTimeout  = 1
+)
+
+// Manifest describes a hook, as written in hook.yaml
+type Manifest struct {
+	ID         int                      `yaml:"id,omitempty" json:"-"`
+	Type       string                   `yaml:"type" json:"type"`
+	Runtime    string                   `yaml:"runtime,omitempty" json:"runtime"`
+	Function   string                   `yaml:"function,omitempty" json:"-"`
+	Disabled   bool                     `yaml:"disabled,omitempty" json:"disabled"`
+	Timeout    int                      `yaml:"timeout,omitempty" json:"timeout"`
+	Retries    int                      `yaml:"retries,omitempty" json:"retries"`
+	EnvVars    []string                 `yaml:"env_vars,omitempty" json:"env_vars"`
+	Packages   map[string]string        `yaml:"packages,omitempty" json:"packages"`
+	Options    map[string]interface{}   `yaml:"options,omitempty" json:"options,omitempty"`
+	Conditions []map[string]interface{} `yaml:"conditions,omitempty" json:"conditions,omitempty"`
+}
+
+// Hook is a hook's description and code, ready to be sent to the API
+type Hook struct {
+	Manifest
+	Code string `json:"function"` // base64 encoded, as the API takes it
+}
+
+// Load reads the hook in the directory, filling in the defaults of what hook.yaml leaves out
+func Load(dir string) (Hook, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return Hook{}, fmt.Errorf("unable to read %s: %s", ManifestFile, err)
+	}
+	var manifest Manifest
+	if err := yaml.UnmarshalStrict(data, &manifest); err != nil {
+		return Hook{}, fmt.Errorf("unable to read %s: %s", ManifestFile, err)
+	}
+	if manifest.Type == "" {
+		return Hook{}, fmt.Errorf("%s needs the type of the hook e.g. pre-authentication", ManifestFile)
+	}
+	if manifest.Runtime == "" {
+		manifest.Runtime = DefaultRuntime
+	}
+	if manifest.Function == "" {
+		manifest.Function = DefaultFunction
+	}
+	if manifest.Timeout == 0 {
+		manifest.Timeout = DefaultTimeout
+	}
+	if manifest.EnvVars == nil {
+		manifest.EnvVars = []string{}
+	}
+	if manifest.Packages == nil {
+		manifest.Packages = map[string]string{}
+	}
+	code, err := ioutil.ReadFile(filepath.Join(dir, manifest.Function))
+	if err != nil {
+		return Hook{}, fmt.Errorf("unable to read the hook's function: %s", err)
+	}
+	return Hook{Manifest: manifest, Code: base64.StdEncoding.EncodeToString(code)}, nil
+}
+
+// Function decodes the code of a hook as the API returns it
+func Function(encoded string) ([]byte, error) {
+	code, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the hook's function: %s", err)
+	}
+	return code, nil
+}
+
+// EnvValues are the values of the hook's env vars from the environment, reporting those that aren't set
+func (h Hook) EnvValues() (map[string]string, error) {
+	out, missing := map[string]string{}, []string{}
+	for _, name := range h.EnvVars {
+		if value, ok := os.LookupEnv(name); ok {
+			out[name] = value
+		} else {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("set the environment variables %s for the hook's env vars", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
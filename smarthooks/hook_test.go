@@ -0,0 +1,68 @@
+package smarthooks
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tests := map[string]struct {
+		Files         map[string]string
+		Expected      string
+		ExpectedError string
+	}{
+		"It fills in defaults and encodes the function": {
+			Files: map[string]string{
+				"hook.yaml": "type: pre-authentication\nenv_vars: [API_KEY]\n",
+				"hook.js":   "exports.handler = async (context) => ({ user: context.user });\n",
+			},
+			Expected: `{"type":"pre-authentication","runtime":"nodejs18.x","disabled":false,"timeout":1,"retries":0,"env_vars":["API_KEY"],"packages":{},"function":"ZXhwb3J0cy5oYW5kbGVyID0gYXN5bmMgKGNvbnRleHQpID0+ICh7IHVzZXI6IGNvbnRleHQudXNlciB9KTsK"}`,
+		},
+		"It reads the function named in the manifest": {
+			Files: map[string]string{
+				"hook.yaml":  "type: user-migration\nfunction: migrate.js\ntimeout: 5\npackages:\n  axios: 1.6.0\n",
+				"migrate.js": "1",
+			},
+			Expected: `{"type":"user-migration","runtime":"nodejs18.x","disabled":false,"timeout":5,"retries":0,"env_vars":[],"packages":{"axios":"1.6.0"},"function":"MQ=="}`,
+		},
+		"It needs a type": {
+			Files:         map[string]string{"hook.yaml": "runtime: nodejs18.x\n"},
+			ExpectedError: "hook.yaml needs the type of the hook e.g. pre-authentication",
+		},
+		"It reports unknown settings": {
+			Files:         map[string]string{"hook.yaml": "type: pre-authentication\nenv: [A]\n"},
+			ExpectedError: "unable to read hook.yaml: yaml: unmarshal errors:\n  line 2: field env not found in type smarthooks.Manifest",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir, _ := ioutil.TempDir("", "hook")
+			defer os.RemoveAll(dir)
+			for file, content := range test.Files {
+				ioutil.WriteFile(filepath.Join(dir, file), []byte(content), 0600)
+			}
+			hook, err := Load(dir)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			body, _ := json.Marshal(hook)
+			assert.JSONEq(t, test.Expected, string(body))
+		})
+	}
+}
+
+func TestEnvValues(t *testing.T) {
+	os.Setenv("SMARTHOOKS_TEST_KEY", "secret")
+	defer os.Unsetenv("SMARTHOOKS_TEST_KEY")
+	values, err := Hook{Manifest: Manifest{EnvVars: []string{"SMARTHOOKS_TEST_KEY"}}}.EnvValues()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"SMARTHOOKS_TEST_KEY": "secret"}, values)
+	_, err = Hook{Manifest: Manifest{EnvVars: []string{"SMARTHOOKS_TEST_MISSING"}}}.EnvValues()
+	assert.EqualError(t, err, "set the environment variables SMARTHOOKS_TEST_MISSING for the hook's env vars")
+}
@@ -0,0 +1,44 @@
+package smarthooks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// runner loads the hook and calls its handler with the context read from stdin, printing what it returns as JSON
+const runner = `const hook = require('./hook.js');
+const context = JSON.parse(require('fs').readFileSync(0, 'utf8'));
+Promise.resolve(hook.handler(context))
+  .then((result) => console.log(JSON.stringify(result, null, 2)))
+  .catch((err) => { console.error((err && err.stack) || err); process.exit(1); });
+`
+
+// Invoke runs the hook's code with node, passing the payload as the context and env as extra environment
+// variables. What the handler returns is written to stdout and what it logs to stderr
+func Invoke(node string, code []byte, payload []byte, env []string, stdout io.Writer, stderr io.Writer) error {
+	dir, err := ioutil.TempDir("", "smarthook")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "hook.js"), code, 0600); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "run.js"), []byte(runner), 0600); err != nil {
+		return err
+	}
+	command := exec.Command(node, "run.js")
+	command.Dir = dir
+	command.Env = append(os.Environ(), env...)
+	command.Stdin = bytes.NewReader(payload)
+	command.Stdout, command.Stderr = stdout, stderr
+	if err := command.Run(); err != nil {
+		return fmt.Errorf("the hook failed: %s", err)
+	}
+	return nil
+}
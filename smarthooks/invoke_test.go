@@ -0,0 +1,24 @@
+package smarthooks
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"os/exec"
+	"testing"
+)
+
+func TestInvoke(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not installed")
+	}
+	code := []byte("exports.handler = async (context) => { console.error('checking', context.user.id); return { user: { policy_id: Number(process.env.POLICY) } }; };")
+	var stdout, stderr bytes.Buffer
+	err = Invoke(node, code, []byte(`{"user":{"id":7}}`), []string{"POLICY=42"}, &stdout, &stderr)
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"user\": {\n    \"policy_id\": 42\n  }\n}\n", stdout.String())
+	assert.Equal(t, "checking 7\n", stderr.String())
+
+	err = Invoke(node, []byte("exports.handler = async () => { throw new Error('boom'); };"), []byte(`{}`), nil, &stdout, &stderr)
+	assert.EqualError(t, err, "the hook failed: exit status 1")
+}
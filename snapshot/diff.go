@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/diffs"
+	"github.com/onelogin/onelogin/records"
+	"sort"
+)
+
+// kinds of Difference
+const (
+	Removed = "removed" // only in the account compared from
+	Added   = "added"   // only in the account compared to
+	Changed = "changed"
+)
+
+// FieldChange is a field whose value differs between the accounts
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// Difference is a resource that is only in one of the accounts, or that differs between them
+type Difference struct {
+	Type    string        `json:"type"`
+	Key     string        `json:"key"`
+	Kind    string        `json:"kind"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+	Missing []string      `json:"missing,omitempty"` // references of the from resource with no namesake, as type/id
+}
+
+// Differ compares the resources of two accounts, matched by Key. References between resources are compared by the
+// namesakes they refer to rather than by id, so the types referred to, see Needs, should be matched first
+type Differ struct {
+	from Restorer // remaps the from account's resources to the ids of the to account
+	to   Restorer // keeps the to account's ids, so its resources are shaped the same way
+}
+
+// Needs lists the types the type's resources refer to
+func Needs(typeName string) []string {
+	needs := map[string]bool{}
+	for _, refType := range references[typeName] {
+		needs[refType] = true
+	}
+	for _, refType := range assignments[typeName] {
+		needs[refType] = true
+	}
+	if typeName == "apps" || typeName == "mappings" {
+		needs["roles"] = true
+	}
+	out := []string{}
+	for _, t := range Types {
+		if needs[t.Name] {
+			out = append(out, t.Name)
+		}
+	}
+	return out
+}
+
+// Match records which resources of the type are namesakes across the accounts
+func (d *Differ) Match(typeName string, from []records.Record, to []records.Record) {
+	d.from.Match(typeName, from, to)
+	d.to.Match(typeName, to, to)
+}
+
+// Diff lists the resources of the type that are only in one account, then those that differ, by key
+func (d *Differ) Diff(typeName string, from []records.Record, to []records.Record) []Difference {
+	byKey := d.from.Match(typeName, from, to)
+	d.to.Match(typeName, to, to)
+	inFrom := map[string]bool{}
+	removed, changed := []Difference{}, []Difference{}
+	for _, resource := range from {
+		key := Key(typeName, resource)
+		inFrom[key] = true
+		fromBody, fromAssign, missing := d.from.body(typeName, resource)
+		current, exists := byKey[key]
+		if !exists {
+			removed = append(removed, Difference{Type: typeName, Key: key, Kind: Removed, Missing: missing})
+			continue
+		}
+		toBody, toAssign, _ := d.to.body(typeName, current)
+		fields := []FieldChange{}
+		for _, field := range records.Fields([]records.Record{fromBody, toBody}) {
+			if !sameJSON(fromBody[field], toBody[field]) {
+				fields = append(fields, FieldChange{Field: field, From: fromBody[field], To: toBody[field]})
+			}
+		}
+		for field := range assignments[typeName] {
+			fromIDs, toIDs := sortedIDs(fromAssign[field]), sortedIDs(toAssign[field])
+			if !sameJSON(fromIDs, toIDs) {
+				fields = append(fields, FieldChange{Field: field, From: fromIDs, To: toIDs})
+			}
+		}
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		if len(fields) > 0 || len(missing) > 0 {
+			changed = append(changed, Difference{Type: typeName, Key: key, Kind: Changed, Fields: fields, Missing: missing})
+		}
+	}
+	added := []Difference{}
+	for _, resource := range to {
+		if key := Key(typeName, resource); !inFrom[key] {
+			added = append(added, Difference{Type: typeName, Key: key, Kind: Added})
+		}
+	}
+	sortDifferences(removed)
+	sortDifferences(added)
+	sortDifferences(changed)
+	return append(append(removed, added...), changed...)
+}
+
+// WriteDiff prints the differences, - for resources only in the account compared from, + for those only in the one
+// compared to, and ~ for those that differ with a line per value that differs, then their count
+func WriteDiff(p diffs.Printer, differences []Difference, from string, to string) {
+	if len(differences) == 0 {
+		fmt.Fprintf(p.W, "No differences between %s and %s.\n", from, to)
+		return
+	}
+	counts := map[string]int{}
+	for _, difference := range differences {
+		counts[difference.Kind]++
+		mark := map[string]string{Removed: diffs.Removed, Added: diffs.Added, Changed: diffs.Changed}[difference.Kind]
+		p.Printf(mark, "%s %s %s", mark, difference.Type, difference.Key)
+		for _, field := range difference.Fields {
+			p.Lines("    ", diffs.Compare(field.Field, field.From, field.To))
+		}
+		for _, missing := range difference.Missing {
+			fmt.Fprintf(p.W, "    refers to %s, which has no namesake in %s\n", missing, to)
+		}
+	}
+	fmt.Fprintf(p.W, "%d only in %s, %d only in %s and %d differing.\n", counts[Removed], from, counts[Added], to, counts[Changed])
+}
+
+// sortedIDs copies the ids sorted, never nil so no assignments compare equal to an empty list
+func sortedIDs(ids []string) []string {
+	out := append([]string{}, ids...)
+	sort.Slice(out, func(i, j int) bool { return lessID(out[i], out[j]) })
+	return out
+}
+
+func sortDifferences(differences []Difference) {
+	sort.SliceStable(differences, func(i, j int) bool { return differences[i].Key < differences[j].Key })
+}
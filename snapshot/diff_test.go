@@ -0,0 +1,98 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/onelogin/onelogin/diffs"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNeeds(t *testing.T) {
+	assert.Equal(t, []string{"roles", "policies"}, Needs("apps"))
+	assert.Equal(t, []string{"users"}, Needs("roles"))
+	assert.Equal(t, []string{}, Needs("hooks"))
+}
+
+func TestDiff(t *testing.T) {
+	fromRoles := []records.Record{{"id": json.Number("1"), "name": "Eng"}, {"id": json.Number("2"), "name": "Sales"}}
+	toRoles := []records.Record{{"id": json.Number("11"), "name": "Eng"}, {"id": json.Number("13"), "name": "Ops"}}
+	tests := map[string]struct {
+		Type     string
+		From     []records.Record
+		To       []records.Record
+		Expected []Difference
+	}{
+		"It lists resources only in one account, then those that differ": {
+			Type: "roles",
+			From: fromRoles,
+			To:   toRoles,
+			Expected: []Difference{
+				{Type: "roles", Key: "Sales", Kind: Removed, Missing: []string{}},
+				{Type: "roles", Key: "Ops", Kind: Added},
+			},
+		},
+		"It compares references by the namesakes they refer to": {
+			Type: "apps",
+			From: []records.Record{
+				{"id": json.Number("5"), "name": "SF", "role_ids": []interface{}{json.Number("1")}},
+				{"id": json.Number("6"), "name": "Slack", "role_ids": []interface{}{json.Number("1")}, "visible": true},
+			},
+			To: []records.Record{
+				{"id": json.Number("15"), "name": "SF", "role_ids": []interface{}{json.Number("11")}},
+				{"id": json.Number("16"), "name": "Slack", "visible": false},
+			},
+			Expected: []Difference{{
+				Type: "apps", Key: "Slack", Kind: Changed,
+				Fields: []FieldChange{
+					{Field: "role_ids", From: []interface{}{json.Number("11")}, To: nil},
+					{Field: "visible", From: true, To: false},
+				},
+				Missing: []string{},
+			}},
+		},
+		"It compares assignments as sets": {
+			Type: "roles",
+			From: []records.Record{{"id": json.Number("1"), "name": "Eng", "users": []interface{}{json.Number("2"), json.Number("1")}}},
+			To:   []records.Record{{"id": json.Number("11"), "name": "Eng", "users": []interface{}{json.Number("22"), json.Number("21")}}},
+			Expected: []Difference{{
+				Type: "roles", Key: "Eng", Kind: Changed,
+				Fields:  []FieldChange{{Field: "users", From: []string{"21"}, To: []string{"21", "22"}}},
+				Missing: []string{"users/2"},
+			}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			differ := &Differ{}
+			differ.Match("roles", fromRoles, toRoles)
+			differ.Match("users", []records.Record{{"id": json.Number("1"), "email": "ann@acme.com"}}, []records.Record{
+				{"id": json.Number("21"), "email": "ann@acme.com"},
+				{"id": json.Number("22"), "email": "bob@acme.com"},
+			})
+			assert.Equal(t, test.Expected, differ.Diff(test.Type, test.From, test.To))
+		})
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	var out bytes.Buffer
+	WriteDiff(diffs.Printer{W: &out}, []Difference{
+		{Type: "roles", Key: "Sales", Kind: Removed},
+		{Type: "apps", Key: "Slack", Kind: Changed, Fields: []FieldChange{
+			{Field: "visible", From: true, To: nil},
+			{Field: "configuration", From: map[string]interface{}{"url": "a", "ttl": 1}, To: map[string]interface{}{"url": "b", "ttl": 1}},
+		}, Missing: []string{"roles/3"}},
+	}, "prod", "staging")
+	assert.Equal(t, "- roles Sales\n"+
+		"~ apps Slack\n"+
+		"    - visible: true\n"+
+		"    ~ configuration.url: \"a\" => \"b\"\n"+
+		"    refers to roles/3, which has no namesake in staging\n"+
+		"1 only in prod, 0 only in staging and 1 differing.\n", out.String())
+
+	out.Reset()
+	WriteDiff(diffs.Printer{W: &out}, nil, "prod", "staging")
+	assert.Equal(t, "No differences between prod and staging.\n", out.String())
+}
@@ -0,0 +1,255 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clone"
+	"github.com/onelogin/onelogin/records"
+	"io"
+	"sort"
+	"strings"
+)
+
+// NewID stands in for the id of a resource a restore has yet to create, so later types can still refer to it
+const NewID = "(new)"
+
+// fields the API sets itself or that only mean something in the account the snapshot was taken of, by type
+var droppedFields = map[string][]string{
+	"users": {"id", "created_at", "updated_at", "activated_at", "last_login", "locked_until", "invitation_sent_at",
+		"invalid_login_attempts", "password_changed_at", "role_ids", "group_id", "directory_id", "trusted_idp_id"},
+	"roles":      {"id", "apps"},
+	"mappings":   {"id", "position"},
+	"policies":   {"id", "created_at", "updated_at"},
+	"hooks":      {"id", "created_at", "updated_at", "status"},
+	"privileges": {"id"},
+}
+
+// fields referring to resources of another type by id, remapped to the ids of their namesakes, by type
+var references = map[string]map[string]string{
+	"users": {"manager_user_id": "users", "policy_id": "policies"},
+	"apps":  {"policy_id": "policies"},
+}
+
+// fields listing the ids of resources a resource is assigned to, which are added with requests of their own rather
+// than with the resource, by type
+var assignments = map[string]map[string]string{
+	"roles":      {"users": "users", "admins": "users"},
+	"privileges": {"role_ids": "roles", "user_ids": "users"},
+}
+
+// Key is what a resource is matched by across accounts: users by email, or username when they have none, hooks by
+// their type, and the rest by name
+func Key(typeName string, resource records.Record) string {
+	switch typeName {
+	case "users":
+		if email := resource.Text("email"); email != "" {
+			return strings.ToLower(email)
+		}
+		return resource.Text("username")
+	case "hooks":
+		return resource.Text("type")
+	}
+	return resource.Text("name")
+}
+
+// Change creates a resource missing from the account, or updates one that differs from the snapshot
+type Change struct {
+	Type    string
+	Key     string
+	Source  string // the resource's id in the snapshot
+	Create  bool
+	ID      string              // of the resource updated
+	Body    records.Record      // the resource as the snapshot has it, remapped to the account
+	Fields  []string            // fields of the body that differ, sorted
+	Assign  map[string][]string // ids to assign the resource to that it isn't yet, by field
+	Missing []string            // references with no namesake in the account, as type/id, left out
+}
+
+// Restorer plans the changes making an account hold the resources of a snapshot. IDs maps the ids of resources
+// in the snapshot to those of their namesakes in the account, by type, and grows as types are planned, so types
+// should be planned in the order of Types
+type Restorer struct {
+	IDs map[string]map[string]string
+}
+
+// Match records the id of the account's namesake of each of the snapshot's resources of the type, or NewID when it
+// has none, so resources of other types can refer to them
+func (r *Restorer) Match(typeName string, snapshot []records.Record, live []records.Record) map[string]records.Record {
+	if r.IDs == nil {
+		r.IDs = map[string]map[string]string{}
+	}
+	byKey := map[string]records.Record{}
+	for _, resource := range live {
+		byKey[Key(typeName, resource)] = resource
+	}
+	ids := r.IDs[typeName]
+	if ids == nil {
+		ids = map[string]string{}
+		r.IDs[typeName] = ids
+	}
+	for _, resource := range snapshot {
+		ids[resource.Text("id")] = NewID
+		if current, exists := byKey[Key(typeName, resource)]; exists {
+			ids[resource.Text("id")] = current.Text("id")
+		}
+	}
+	return byKey
+}
+
+// Plan lists the changes making the account's resources of the type match those of the snapshot. Resources only in
+// the account are left alone
+func (r *Restorer) Plan(typeName string, snapshot []records.Record, live []records.Record) []Change {
+	byKey := r.Match(typeName, snapshot, live)
+	changes := []Change{}
+	for _, resource := range snapshot {
+		key := Key(typeName, resource)
+		body, assign, missing := r.body(typeName, resource)
+		current, exists := byKey[key]
+		if !exists {
+			changes = append(changes, Change{Type: typeName, Key: key, Source: resource.Text("id"), Create: true, Body: body, Assign: assign, Missing: missing})
+			continue
+		}
+		fields := []string{}
+		for field, value := range body {
+			if !sameJSON(value, current[field]) {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		assign = unassigned(assign, current)
+		if len(fields) > 0 || len(assign) > 0 {
+			changes = append(changes, Change{Type: typeName, Key: key, Source: resource.Text("id"), ID: current.Text("id"), Body: body, Fields: fields, Assign: assign, Missing: missing})
+		}
+	}
+	return changes
+}
+
+// WritePreview prints the changes a line each, + for resources created and ~ for those updated, then their count
+func WritePreview(w io.Writer, changes []Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "The account already holds the snapshot's resources.")
+		return
+	}
+	created := 0
+	for _, change := range changes {
+		details := []string{}
+		if len(change.Fields) > 0 {
+			details = append(details, "fields "+strings.Join(change.Fields, ","))
+		}
+		fields := []string{}
+		for field := range change.Assign {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			details = append(details, fmt.Sprintf("%d %s", len(change.Assign[field]), field))
+		}
+		if len(change.Missing) > 0 {
+			details = append(details, "without "+strings.Join(change.Missing, ","))
+		}
+		mark := "~"
+		if change.Create {
+			mark = "+"
+			created++
+		}
+		line := fmt.Sprintf("%s %s %s", mark, change.Type, change.Key)
+		if len(details) > 0 {
+			line += " (" + strings.Join(details, "; ") + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "%d to create and %d to update.\n", created, len(changes)-created)
+}
+
+// body is the resource remapped to the account, with the ids to assign it to kept apart, and the references that
+// couldn't be remapped. References to resources yet to be created are left out without being reported
+func (r *Restorer) body(typeName string, resource records.Record) (records.Record, map[string][]string, []string) {
+	missing := []string{}
+	var body records.Record
+	switch typeName {
+	case "apps", "mappings":
+		roles := clone.RoleMap{}
+		for id, mapped := range r.IDs["roles"] {
+			if mapped != NewID {
+				roles[id] = mapped
+			}
+		}
+		cloner := &clone.Cloner{Roles: roles}
+		if typeName == "apps" {
+			body = cloner.App(resource, "")
+		} else {
+			body = cloner.Rule(resource)
+		}
+		for _, id := range cloner.MissingRoles() {
+			if _, pending := r.IDs["roles"][id]; !pending {
+				missing = append(missing, "roles/"+id)
+			}
+		}
+	default:
+		body = records.Merge(resource)
+	}
+	for _, field := range droppedFields[typeName] {
+		delete(body, field)
+	}
+	for field, refType := range references[typeName] {
+		if resource[field] == nil {
+			continue
+		}
+		delete(body, field)
+		mapped, ok := r.IDs[refType][resource.Text(field)]
+		if !ok {
+			missing = append(missing, refType+"/"+resource.Text(field))
+		} else if mapped != NewID {
+			body[field] = json.Number(mapped)
+		}
+	}
+	assign := map[string][]string{}
+	for field, refType := range assignments[typeName] {
+		delete(body, field)
+		for _, id := range idList(resource[field]) {
+			if mapped, ok := r.IDs[refType][id]; ok {
+				assign[field] = append(assign[field], mapped)
+			} else {
+				missing = append(missing, refType+"/"+id)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return body, assign, missing
+}
+
+// unassigned keeps the ids the resource isn't assigned to yet, dropping fields with none left
+func unassigned(assign map[string][]string, current records.Record) map[string][]string {
+	out := map[string][]string{}
+	for field, ids := range assign {
+		have := map[string]bool{}
+		for _, id := range idList(current[field]) {
+			have[id] = true
+		}
+		for _, id := range ids {
+			if !have[id] {
+				out[field] = append(out[field], id)
+			}
+		}
+	}
+	return out
+}
+
+// idList is a list of ids as strings, whether read from JSON or fetched as ints
+func idList(value interface{}) []string {
+	data, _ := json.Marshal(value)
+	var list []json.Number
+	json.Unmarshal(data, &list)
+	out := make([]string, len(list))
+	for i, id := range list {
+		out[i] = id.String()
+	}
+	return out
+}
+
+// sameJSON reports whether the values encode to the same JSON
+func sameJSON(a interface{}, b interface{}) bool {
+	left, _ := json.Marshal(a)
+	right, _ := json.Marshal(b)
+	return string(left) == string(right)
+}
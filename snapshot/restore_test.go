@@ -0,0 +1,145 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	tests := map[string]struct {
+		Type     string
+		Resource records.Record
+		Expected string
+	}{
+		"It matches users by email, ignoring case": {
+			Type:     "users",
+			Resource: records.Record{"email": "Ann@Acme.com", "username": "ann"},
+			Expected: "ann@acme.com",
+		},
+		"It matches users without an email by username": {
+			Type:     "users",
+			Resource: records.Record{"username": "ann"},
+			Expected: "ann",
+		},
+		"It matches hooks by type": {
+			Type:     "hooks",
+			Resource: records.Record{"type": "pre-authentication"},
+			Expected: "pre-authentication",
+		},
+		"It matches other resources by name": {
+			Type:     "roles",
+			Resource: records.Record{"name": "Eng"},
+			Expected: "Eng",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Key(test.Type, test.Resource))
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := map[string]struct {
+		IDs      map[string]map[string]string
+		Type     string
+		Snapshot []records.Record
+		Live     []records.Record
+		Expected []Change
+	}{
+		"It creates missing resources without the fields the API sets": {
+			Type:     "users",
+			Snapshot: []records.Record{{"id": json.Number("1"), "email": "ann@acme.com", "last_login": "2020-01-01", "role_ids": []interface{}{json.Number("3")}}},
+			Expected: []Change{{Type: "users", Key: "ann@acme.com", Source: "1", Create: true, Body: records.Record{"email": "ann@acme.com"}, Assign: map[string][]string{}, Missing: []string{}}},
+		},
+		"It updates the fields that differ and leaves resources only in the account alone": {
+			Type: "roles",
+			Snapshot: []records.Record{
+				{"id": json.Number("1"), "name": "Eng", "apps": []interface{}{json.Number("5")}},
+				{"id": json.Number("2"), "name": "Sales"},
+			},
+			Live: []records.Record{
+				{"id": json.Number("11"), "name": "Eng"},
+				{"id": json.Number("12"), "name": "Sales"},
+				{"id": json.Number("13"), "name": "Ops"},
+			},
+			Expected: []Change{},
+		},
+		"It remaps references and reports those without a namesake": {
+			IDs:      map[string]map[string]string{"users": {"1": "11"}},
+			Type:     "users",
+			Snapshot: []records.Record{{"id": json.Number("2"), "email": "bob@acme.com", "manager_user_id": json.Number("1"), "policy_id": json.Number("4")}},
+			Live:     []records.Record{{"id": json.Number("12"), "email": "bob@acme.com"}},
+			Expected: []Change{{
+				Type: "users", Key: "bob@acme.com", Source: "2", ID: "12",
+				Body:    records.Record{"email": "bob@acme.com", "manager_user_id": json.Number("11")},
+				Fields:  []string{"manager_user_id"},
+				Assign:  map[string][]string{},
+				Missing: []string{"policies/4"},
+			}},
+		},
+		"It adds the assignments the account lacks": {
+			IDs:      map[string]map[string]string{"users": {"1": "11", "2": "12"}},
+			Type:     "roles",
+			Snapshot: []records.Record{{"id": json.Number("1"), "name": "Eng", "users": []interface{}{json.Number("1"), json.Number("2")}}},
+			Live:     []records.Record{{"id": json.Number("21"), "name": "Eng", "users": []int{11}}},
+			Expected: []Change{{Type: "roles", Key: "Eng", Source: "1", ID: "21", Body: records.Record{"name": "Eng"}, Fields: []string{}, Assign: map[string][]string{"users": {"12"}}, Missing: []string{}}},
+		},
+		"It leaves out roles yet to be created without reporting them": {
+			IDs:      map[string]map[string]string{"roles": {"1": NewID, "2": "22"}},
+			Type:     "apps",
+			Snapshot: []records.Record{{"id": json.Number("5"), "name": "SF", "role_ids": []interface{}{json.Number("1"), json.Number("2"), json.Number("3")}}},
+			Expected: []Change{{
+				Type: "apps", Key: "SF", Source: "5", Create: true,
+				Body:    records.Record{"name": "SF", "role_ids": []interface{}{json.Number("22")}},
+				Assign:  map[string][]string{},
+				Missing: []string{"roles/3"},
+			}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			restorer := &Restorer{IDs: test.IDs}
+			assert.Equal(t, test.Expected, restorer.Plan(test.Type, test.Snapshot, test.Live))
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	restorer := &Restorer{}
+	restorer.Match("roles", []records.Record{
+		{"id": json.Number("1"), "name": "Eng"},
+		{"id": json.Number("2"), "name": "Sales"},
+	}, []records.Record{{"id": json.Number("11"), "name": "Eng"}})
+	assert.Equal(t, map[string]string{"1": "11", "2": NewID}, restorer.IDs["roles"])
+}
+
+func TestWritePreview(t *testing.T) {
+	tests := map[string]struct {
+		Changes  []Change
+		Expected string
+	}{
+		"It says when there is nothing to do": {
+			Expected: "The account already holds the snapshot's resources.\n",
+		},
+		"It prints a line per change and their count": {
+			Changes: []Change{
+				{Type: "roles", Key: "Eng", Create: true, Assign: map[string][]string{"users": {"11", "12"}}},
+				{Type: "users", Key: "bob@acme.com", ID: "12", Fields: []string{"firstname", "title"}, Missing: []string{"policies/4"}},
+			},
+			Expected: "+ roles Eng (2 users)\n" +
+				"~ users bob@acme.com (fields firstname,title; without policies/4)\n" +
+				"1 to create and 1 to update.\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			WritePreview(&out, test.Changes)
+			assert.Equal(t, test.Expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Package snapshot snapshot.go
+// This module keeps API-level snapshots of a OneLogin account on disk, independent of Terraform: a directory per
+// resource type holding a JSON file per resource, named by its id, as the API returns it. A manifest.json at the top
+// records when and of which account the snapshot was taken and how many of each type it holds, e.g.
+//
+//	backup-2020-01-31/
+//	  manifest.json
+//	  apps/123.json
+//	  roles/456.json
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ManifestFile names the manifest in a snapshot's directory
+const ManifestFile = "manifest.json"
+
+// Type is a type of resource a snapshot holds
+type Type struct {
+	Name     string // directory the resources are kept in
+	Path     string // the collection's path in the API
+	FetchOne bool   // whether each resource is requested on its own, for the fields the collection leaves out
+	Optional bool   // whether the type is skipped when the account's API doesn't offer it
+}
+
+// Types are the resource types snapshots can hold, in the order they are taken
+var Types = []Type{
+	{Name: "users", Path: "/api/2/users"},
+	{Name: "roles", Path: "/api/2/roles", FetchOne: true},
+	{Name: "apps", Path: "/api/2/apps", FetchOne: true},
+	{Name: "mappings", Path: "/api/2/mappings"},
+	{Name: "policies", Path: "/api/2/policies", Optional: true},
+	{Name: "hooks", Path: "/api/2/hooks", FetchOne: true},
+	{Name: "privileges", Path: "/api/2/privileges"},
+}
+
+// Manifest describes a snapshot
+type Manifest struct {
+	CreatedAt string         `json:"created_at"`
+	URL       string         `json:"url"` // of the account's API
+	Counts    map[string]int `json:"counts"`
+}
+
+// Select returns the types included, all of them when none are, less those excluded
+func Select(include []string, exclude []string) ([]Type, error) {
+	known := map[string]bool{}
+	for _, t := range Types {
+		known[t.Name] = true
+	}
+	for _, name := range append(append([]string{}, include...), exclude...) {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown type %s, expected one of %s", name, strings.Join(Names(), ", "))
+		}
+	}
+	out := []Type{}
+	for _, t := range Types {
+		if (len(include) == 0 || contains(include, t.Name)) && !contains(exclude, t.Name) {
+			out = append(out, t)
+		}
+	}
+	return out, nil
+}
+
+// Names lists the names of the types
+func Names() []string {
+	out := make([]string, len(Types))
+	for i, t := range Types {
+		out[i] = t.Name
+	}
+	return out
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// unsafeFileName matches characters kept out of file names
+var unsafeFileName = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// WriteType writes the resources of the type to its directory in the snapshot, replacing any there already
+func WriteType(dir string, name string, resources []records.Record) error {
+	typeDir := filepath.Join(dir, name)
+	if err := os.RemoveAll(typeDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(typeDir, 0700); err != nil {
+		return err
+	}
+	for _, resource := range resources {
+		id := resource.Text("id")
+		if id == "" {
+			return fmt.Errorf("a resource of %s has no id", name)
+		}
+		data, err := resource.JSON()
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(typeDir, unsafeFileName.ReplaceAllString(id, "_")+".json")
+		if err := ioutil.WriteFile(file, append(data, '\n'), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteManifest writes the manifest to the snapshot
+func WriteManifest(dir string, manifest Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ManifestFile), append(data, '\n'), 0600)
+}
+
+// ReadManifest reads the manifest of the snapshot
+func ReadManifest(dir string) (Manifest, error) {
+	var manifest Manifest
+	data, err := ioutil.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		return manifest, fmt.Errorf("%s isn't a snapshot: %s", dir, err)
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("unable to read the manifest of %s: %s", dir, err)
+	}
+	return manifest, nil
+}
+
+// ReadType reads the resources of the type in the snapshot, sorted by id, or none when it holds none of the type
+func ReadType(dir string, name string) ([]records.Record, error) {
+	files, err := filepath.Glob(filepath.Join(dir, name, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	out := []records.Record{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		resources, err := records.FromJSON(data)
+		if err != nil || len(resources) != 1 {
+			return nil, fmt.Errorf("unable to read %s: expected a JSON object", file)
+		}
+		out = append(out, resources[0])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return lessID(out[i].Text("id"), out[j].Text("id"))
+	})
+	return out, nil
+}
+
+// lessID orders ids numerically when they are numbers, shorter first, and as text otherwise
+func lessID(a string, b string) bool {
+	if len(a) != len(b) && isDigits(a) && isDigits(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func isDigits(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
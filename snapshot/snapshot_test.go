@@ -0,0 +1,94 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	tests := map[string]struct {
+		Include       []string
+		Exclude       []string
+		Expected      []string
+		ExpectedError string
+	}{
+		"It selects every type by default": {
+			Expected: []string{"users", "roles", "apps", "mappings", "policies", "hooks", "privileges"},
+		},
+		"It selects the types included in their usual order": {
+			Include:  []string{"mappings", "apps"},
+			Expected: []string{"apps", "mappings"},
+		},
+		"It leaves out the types excluded": {
+			Exclude:  []string{"users", "hooks"},
+			Expected: []string{"roles", "apps", "mappings", "policies", "privileges"},
+		},
+		"It reports unknown types": {
+			Include:       []string{"groups"},
+			ExpectedError: "unknown type groups, expected one of users, roles, apps, mappings, policies, hooks, privileges",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Select(test.Include, test.Exclude)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			names := []string{}
+			for _, selected := range actual {
+				names = append(names, selected.Name)
+			}
+			assert.Equal(t, test.Expected, names)
+		})
+	}
+}
+
+func TestWriteAndReadType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "roles"), 0700))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "roles", "stale.json"), []byte(`{"id":"stale"}`), 0600))
+	roles := []records.Record{
+		{"id": json.Number("10"), "name": "Sales", "users": []interface{}{json.Number("7")}},
+		{"id": json.Number("9"), "name": "Eng"},
+	}
+	assert.Nil(t, WriteType(dir, "roles", roles))
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "roles", "10.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, "{\n  \"id\": 10,\n  \"name\": \"Sales\",\n  \"users\": [\n    7\n  ]\n}\n", string(data))
+
+	actual, err := ReadType(dir, "roles")
+	assert.Nil(t, err)
+	assert.Equal(t, []records.Record{roles[1], roles[0]}, actual)
+
+	none, err := ReadType(dir, "apps")
+	assert.Nil(t, err)
+	assert.Equal(t, []records.Record{}, none)
+
+	assert.EqualError(t, WriteType(dir, "apps", []records.Record{{"name": "SF"}}), "a resource of apps has no id")
+}
+
+func TestManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = ReadManifest(dir)
+	assert.Contains(t, err.Error(), dir+" isn't a snapshot")
+
+	manifest := Manifest{CreatedAt: "2020-01-31T09:00:00Z", URL: "https://api.us.onelogin.com", Counts: map[string]int{"roles": 2}}
+	assert.Nil(t, WriteManifest(dir, manifest))
+	actual, err := ReadManifest(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, manifest, actual)
+}
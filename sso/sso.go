@@ -0,0 +1,70 @@
+// Package sso sso.go
+// This module summarizes how an app signs users in from the app as the OneLogin API returns it, so inventory queries
+// like "which SAML certificates expire this quarter" don't need the console. The summary's fields are added to the
+// app's own, flattened so tables and CSV can show them:
+//
+//	acs_url                 where the app receives SAML assertions, its ACS (Consumer) URL
+//	metadata_url            OneLogin's SAML metadata for the app
+//	issuer                  OneLogin's SAML issuer for the app
+//	sls_url                 OneLogin's single logout URL for the app
+//	client_id               the OpenID Connect client id
+//	certificate_name        the certificate signing the app's assertions
+//	certificate_expires_at  when it expires, in RFC 3339
+//	certificate_days_left   whole days until it expires, negative once expired
+package sso
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"time"
+)
+
+// Summary is the app's sign in details, leaving out those it doesn't have
+func Summary(app records.Record, now time.Time) records.Record {
+	out := records.Record{}
+	sso, _ := app["sso"].(map[string]interface{})
+	configuration, _ := app["configuration"].(map[string]interface{})
+	set(out, "acs_url", configuration["consumer_url"])
+	if out["acs_url"] == nil {
+		set(out, "acs_url", sso["acs_url"])
+	}
+	set(out, "metadata_url", sso["metadata_url"])
+	set(out, "issuer", sso["issuer"])
+	set(out, "sls_url", sso["sls_url"])
+	set(out, "client_id", sso["client_id"])
+	certificate, _ := sso["certificate"].(map[string]interface{})
+	set(out, "certificate_name", certificate["name"])
+	if value, ok := certificate["value"].(string); ok && value != "" {
+		if expires, err := CertificateExpiry(value); err == nil {
+			out["certificate_expires_at"] = expires.UTC().Format(time.RFC3339)
+			out["certificate_days_left"] = int(expires.Sub(now).Hours() / 24)
+		}
+	}
+	return out
+}
+
+// CertificateExpiry reads when the PEM encoded certificate expires. Certificates given without the PEM header, as
+// some connectors do, are read too
+func CertificateExpiry(certificate string) (time.Time, error) {
+	block, _ := pem.Decode([]byte(certificate))
+	if block == nil {
+		block, _ = pem.Decode([]byte("-----BEGIN CERTIFICATE-----\n" + certificate + "\n-----END CERTIFICATE-----\n"))
+	}
+	if block == nil {
+		return time.Time{}, fmt.Errorf("the certificate isn't PEM encoded")
+	}
+	parsed, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parsed.NotAfter, nil
+}
+
+// set sets the field when the value isn't blank
+func set(out records.Record, field string, value interface{}) {
+	if value != nil && value != "" {
+		out[field] = value
+	}
+}
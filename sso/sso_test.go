@@ -0,0 +1,79 @@
+package sso
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	certificate := testCertificate(t, now.AddDate(0, 0, 90))
+	tests := map[string]struct {
+		App      records.Record
+		Expected records.Record
+	}{
+		"It summarizes SAML apps": {
+			App: records.Record{
+				"configuration": map[string]interface{}{"consumer_url": "https://app.example.com/saml/acs"},
+				"sso": map[string]interface{}{
+					"metadata_url": "https://acme.onelogin.com/saml/metadata/1",
+					"acs_url":      "https://acme.onelogin.com/trust/saml2/http-post/sso/1",
+					"issuer":       "https://app.onelogin.com/saml/metadata/1",
+					"certificate":  map[string]interface{}{"name": "Standard", "value": certificate},
+				},
+			},
+			Expected: records.Record{
+				"acs_url":                "https://app.example.com/saml/acs",
+				"metadata_url":           "https://acme.onelogin.com/saml/metadata/1",
+				"issuer":                 "https://app.onelogin.com/saml/metadata/1",
+				"certificate_name":       "Standard",
+				"certificate_expires_at": "2020-03-31T00:00:00Z",
+				"certificate_days_left":  90,
+			},
+		},
+		"It reads certificates without the PEM header": {
+			App: records.Record{"sso": map[string]interface{}{"certificate": map[string]interface{}{"value": withoutHeader(certificate)}}},
+			Expected: records.Record{
+				"certificate_expires_at": "2020-03-31T00:00:00Z",
+				"certificate_days_left":  90,
+			},
+		},
+		"It summarizes OpenID Connect apps": {
+			App:      records.Record{"sso": map[string]interface{}{"client_id": "abc123", "client_secret": "secret"}},
+			Expected: records.Record{"client_id": "abc123"},
+		},
+		"It leaves out what apps don't have": {
+			App:      records.Record{"name": "Password app"},
+			Expected: records.Record{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Summary(test.App, now))
+		})
+	}
+}
+
+func testCertificate(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test"}, NotBefore: notAfter.AddDate(-1, 0, 0), NotAfter: notAfter}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func withoutHeader(certificate string) string {
+	certificate = strings.TrimPrefix(certificate, "-----BEGIN CERTIFICATE-----\n")
+	return strings.TrimSuffix(certificate, "-----END CERTIFICATE-----\n")
+}
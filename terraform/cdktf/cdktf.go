@@ -0,0 +1,555 @@
+// Package cdktf cdktf.go
+// This module converts the HCL written by the importer into CDK for Terraform code. Every provider, resource, and
+// data block becomes a construct in a single stack and references between resources become references between the
+// constructs. The provider bindings are expected where `cdktf get` puts them.
+package cdktf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"github.com/zclconf/go-cty/cty"
+	"sort"
+	"strings"
+)
+
+// Language is the language constructs are written in
+type Language string
+
+const (
+	TypeScript Language = "typescript"
+	Python     Language = "python"
+)
+
+// construct is a provider, resource, or data block of the configuration
+type construct struct {
+	kind         string // provider, resource or data
+	resourceType string // the provider name for providers
+	name         string
+	body         *hclsyntax.Body
+}
+
+// address is how other blocks refer to the construct, empty for providers
+func (c construct) address() string {
+	switch c.kind {
+	case "resource":
+		return c.resourceType + "." + c.name
+	case "data":
+		return "data." + c.resourceType + "." + c.name
+	}
+	return ""
+}
+
+// Convert writes the provider, resource, and data blocks in src as a cdktf stack in the language
+func Convert(src []byte, filename string, language Language) ([]byte, error) {
+	if language != TypeScript && language != Python {
+		return nil, fmt.Errorf("unsupported language %s", language)
+	}
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	constructs := []construct{}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		switch {
+		case block.Type == "provider" && len(block.Labels) == 1:
+			constructs = append(constructs, construct{kind: block.Type, resourceType: block.Labels[0], name: block.Labels[0], body: block.Body})
+		case (block.Type == "resource" || block.Type == "data") && len(block.Labels) == 2:
+			constructs = append(constructs, construct{kind: block.Type, resourceType: block.Labels[0], name: block.Labels[1], body: block.Body})
+		}
+	}
+	g := generator{language: language, constructs: map[string]construct{}, referenced: map[string]bool{}}
+	for _, c := range constructs {
+		if c.address() != "" {
+			g.constructs[c.address()] = c
+		}
+	}
+	for _, c := range constructs {
+		for _, dependency := range g.dependencies(c) {
+			g.referenced[dependency] = true
+		}
+	}
+	return g.stack(g.dependencyOrder(constructs))
+}
+
+// generator carries the language and the constructs in the stack while writing it
+type generator struct {
+	language   Language
+	constructs map[string]construct // keyed by address
+	referenced map[string]bool      // addresses other constructs refer to, which are kept in a variable
+}
+
+// dependencies are the addresses of the constructs c refers to
+func (g generator) dependencies(c construct) []string {
+	dependencies := []string{}
+	hclsyntax.VisitAll(c.body, func(node hclsyntax.Node) hcl.Diagnostics {
+		if expr, ok := node.(*hclsyntax.ScopeTraversalExpr); ok {
+			if address, _, ok := g.splitReference(expr.Traversal); ok {
+				dependencies = append(dependencies, address)
+			}
+		}
+		return nil
+	})
+	return dependencies
+}
+
+// dependencyOrder orders constructs so every construct comes after the ones it refers to, keeping the order
+// of the configuration otherwise
+func (g generator) dependencyOrder(constructs []construct) []construct {
+	ordered := []construct{}
+	visited := map[string]bool{}
+	var visit func(c construct)
+	visit = func(c construct) {
+		key := c.kind + "." + c.resourceType + "." + c.name
+		if visited[key] {
+			return
+		}
+		visited[key] = true // marked before visiting dependencies so cycles end
+		for _, dependency := range g.dependencies(c) {
+			visit(g.constructs[dependency])
+		}
+		ordered = append(ordered, c)
+	}
+	for _, c := range constructs {
+		visit(c)
+	}
+	return ordered
+}
+
+// splitReference splits a traversal like onelogin_apps.wiki.id into the address of the construct it
+// refers to and the attribute path on it
+func (g generator) splitReference(traversal hcl.Traversal) (string, []string, bool) {
+	names := []string{traversal.RootName()}
+	for _, step := range traversal[1:] {
+		attr, ok := step.(hcl.TraverseAttr)
+		if !ok {
+			return "", nil, false
+		}
+		names = append(names, attr.Name)
+	}
+	length := 2
+	if names[0] == "data" {
+		length = 3
+	}
+	if len(names) < length {
+		return "", nil, false
+	}
+	address := strings.Join(names[:length], ".")
+	if _, ok := g.constructs[address]; !ok {
+		return "", nil, false
+	}
+	return address, names[length:], true
+}
+
+// stack writes the program holding every construct in a single stack
+func (g generator) stack(constructs []construct) ([]byte, error) {
+	var body bytes.Buffer
+	imports := map[string]string{} // class name by module
+	for _, c := range constructs {
+		module, class := g.binding(c)
+		imports[module] = class
+		if err := g.construct(&body, c, class); err != nil {
+			return nil, err
+		}
+	}
+	modules := make([]string, 0, len(imports))
+	for module := range imports {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+
+	var out bytes.Buffer
+	if g.language == TypeScript {
+		out.WriteString("import { Construct } from \"constructs\";\n")
+		out.WriteString("import { App, TerraformStack } from \"cdktf\";\n")
+		for _, module := range modules {
+			fmt.Fprintf(&out, "import { %s } from \"%s\";\n", imports[module], module)
+		}
+		out.WriteString("\nclass OneloginStack extends TerraformStack {\n")
+		out.WriteString("  constructor(scope: Construct, id: string) {\n")
+		out.WriteString("    super(scope, id);\n")
+		out.Write(body.Bytes())
+		out.WriteString("  }\n}\n\n")
+		out.WriteString("const app = new App();\n")
+		out.WriteString("new OneloginStack(app, \"onelogin\");\n")
+		out.WriteString("app.synth();\n")
+	} else {
+		out.WriteString("from constructs import Construct\n")
+		out.WriteString("from cdktf import App, TerraformStack\n")
+		for _, module := range modules {
+			fmt.Fprintf(&out, "from %s import %s\n", module, imports[module])
+		}
+		out.WriteString("\n\nclass OneloginStack(TerraformStack):\n")
+		out.WriteString("    def __init__(self, scope: Construct, id: str):\n")
+		out.WriteString("        super().__init__(scope, id)\n")
+		out.Write(body.Bytes())
+		out.WriteString("\n\napp = App()\n")
+		out.WriteString("OneloginStack(app, \"onelogin\")\n")
+		out.WriteString("app.synth()\n")
+	}
+	return out.Bytes(), nil
+}
+
+// binding is the module and class `cdktf get` generates for the construct, e.g. ./.gen/providers/onelogin/apps
+// and Apps for onelogin_apps in TypeScript
+func (g generator) binding(c construct) (string, string) {
+	provider := strings.SplitN(c.resourceType, "_", 2)[0]
+	var module, class string
+	switch c.kind {
+	case "provider":
+		module, class = "provider", pascalCase(provider)+"Provider"
+	case "data":
+		module, class = "data_"+c.resourceType, "Data"+pascalCase(c.resourceType)
+	default:
+		module, class = strings.TrimPrefix(c.resourceType, provider+"_"), pascalCase(strings.TrimPrefix(c.resourceType, provider+"_"))
+	}
+	if g.language == TypeScript {
+		return fmt.Sprintf("./.gen/providers/%s/%s", provider, strings.Replace(module, "_", "-", -1)), class
+	}
+	return fmt.Sprintf("imports.%s.%s", provider, module), class
+}
+
+// construct writes the statement creating c. Constructs other constructs refer to are kept in a variable
+func (g generator) construct(w *bytes.Buffer, c construct, class string) error {
+	config, err := g.body(c.body, 2, true)
+	if err != nil {
+		return fmt.Errorf("unable to convert %s %s.%s: %s", c.kind, c.resourceType, c.name, err)
+	}
+	assignment := ""
+	if g.referenced[c.address()] {
+		assignment = g.variableName(c.address()) + " = "
+		if g.language == TypeScript {
+			assignment = "const " + assignment
+		}
+	}
+	if g.language == TypeScript {
+		fmt.Fprintf(w, "\n    %snew %s(this, %s, %s);\n", assignment, class, quote(c.name), config)
+		return nil
+	}
+	fmt.Fprintf(w, "\n        %s%s(self, %s%s)\n", assignment, class, quote(c.name), config)
+	return nil
+}
+
+// item is an attribute or nested block of a body, converted
+type item struct {
+	name  string
+	value string
+	start int
+}
+
+// body converts the attributes and nested blocks of a body to an object in the order they were written.
+// The top level body of a construct is its config, which is keyword arguments in Python
+func (g generator) body(body *hclsyntax.Body, depth int, config bool) (string, error) {
+	items := []item{}
+	for name, attribute := range body.Attributes {
+		value, err := g.expression(attribute.Expr, depth+1)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item{name: name, value: value, start: attribute.SrcRange.Start.Byte})
+	}
+	blocks := map[string][]*hclsyntax.Block{}
+	for _, block := range body.Blocks {
+		blocks[block.Type] = append(blocks[block.Type], block)
+	}
+	for name, nested := range blocks {
+		value, err := g.blocks(nested, depth+1)
+		if err != nil {
+			return "", err
+		}
+		items = append(items, item{name: name, value: value, start: nested[0].TypeRange.Start.Byte})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].start < items[j].start })
+
+	if len(items) == 0 {
+		if config && g.language == Python {
+			return "", nil
+		}
+		return "{}", nil
+	}
+	var out strings.Builder
+	inner := g.indent(depth + 1)
+	switch {
+	case g.language == TypeScript:
+		out.WriteString("{\n")
+		for _, item := range items {
+			fmt.Fprintf(&out, "%s%s: %s,\n", inner, camelCase(item.name), item.value)
+		}
+		out.WriteString(g.indent(depth) + "}")
+	case config:
+		out.WriteString(",\n")
+		for _, item := range items {
+			fmt.Fprintf(&out, "%s%s=%s,\n", inner, pythonName(item.name), item.value)
+		}
+		out.WriteString(g.indent(depth))
+	default:
+		out.WriteString("{\n")
+		for _, item := range items {
+			fmt.Fprintf(&out, "%s%s: %s,\n", inner, quote(item.name), item.value)
+		}
+		out.WriteString(g.indent(depth) + "}")
+	}
+	return out.String(), nil
+}
+
+// blocks converts nested blocks of the same type to an object, or a list of objects when repeated
+func (g generator) blocks(blocks []*hclsyntax.Block, depth int) (string, error) {
+	if len(blocks) == 1 {
+		return g.block(blocks[0], depth)
+	}
+	values := make([]string, len(blocks))
+	for i, block := range blocks {
+		value, err := g.block(block, depth+1)
+		if err != nil {
+			return "", err
+		}
+		values[i] = value
+	}
+	return g.list(values, depth), nil
+}
+
+// block converts a nested block. The attributes ignored in a lifecycle block are names rather than references
+func (g generator) block(block *hclsyntax.Block, depth int) (string, error) {
+	if block.Type != "lifecycle" {
+		return g.body(block.Body, depth, false)
+	}
+	ignoreChanges, ok := block.Body.Attributes["ignore_changes"]
+	if !ok {
+		return g.body(block.Body, depth, false)
+	}
+	names := []string{}
+	if tuple, ok := ignoreChanges.Expr.(*hclsyntax.TupleConsExpr); ok {
+		for _, expr := range tuple.Exprs {
+			traversal, diags := hcl.AbsTraversalForExpr(expr)
+			if diags.HasErrors() {
+				return "", diags
+			}
+			names = append(names, quote(traversalString(traversal)))
+		}
+	} else if traversal, diags := hcl.AbsTraversalForExpr(ignoreChanges.Expr); !diags.HasErrors() {
+		names = append(names, quote(traversalString(traversal))) // ignore_changes = all
+	}
+	inner := g.indent(depth + 1)
+	if g.language == TypeScript {
+		return fmt.Sprintf("{\n%signoreChanges: %s,\n%s}", inner, g.list(names, depth+1), g.indent(depth)), nil
+	}
+	return fmt.Sprintf("{\n%s\"ignore_changes\": %s,\n%s}", inner, g.list(names, depth+1), g.indent(depth)), nil
+}
+
+// expression converts the value of an attribute. References to other constructs become references to their variables
+func (g generator) expression(expr hclsyntax.Expression, depth int) (string, error) {
+	switch e := expr.(type) {
+	case *hclsyntax.ScopeTraversalExpr:
+		address, attributes, ok := g.splitReference(e.Traversal)
+		if !ok {
+			return "", fmt.Errorf("unsupported reference %s", traversalString(e.Traversal))
+		}
+		return g.reference(address, attributes), nil
+	case *hclsyntax.TupleConsExpr:
+		values := make([]string, len(e.Exprs))
+		for i, item := range e.Exprs {
+			value, err := g.expression(item, depth+1)
+			if err != nil {
+				return "", err
+			}
+			values[i] = value
+		}
+		return g.list(values, depth), nil
+	case *hclsyntax.ObjectConsExpr:
+		keys := make([]string, len(e.Items))
+		values := make([]string, len(e.Items))
+		for i, item := range e.Items {
+			key, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() {
+				return "", diags
+			}
+			if key.Type() != cty.String || key.IsNull() {
+				return "", fmt.Errorf("unsupported object key at %s", item.KeyExpr.Range())
+			}
+			value, err := g.expression(item.ValueExpr, depth+1)
+			if err != nil {
+				return "", err
+			}
+			keys[i], values[i] = key.AsString(), value
+		}
+		return g.object(keys, values, depth), nil
+	}
+	if len(expr.Variables()) > 0 {
+		return "", fmt.Errorf("unsupported expression at %s", expr.Range())
+	}
+	value, diags := stateparser.Literal(expr)
+	if diags.HasErrors() {
+		return "", diags
+	}
+	return g.literal(value, depth)
+}
+
+// literal writes a value without references
+func (g generator) literal(value cty.Value, depth int) (string, error) {
+	if value.IsNull() {
+		if g.language == TypeScript {
+			return "null", nil
+		}
+		return "None", nil
+	}
+	ty := value.Type()
+	switch {
+	case ty == cty.String:
+		return quote(value.AsString()), nil
+	case ty == cty.Number:
+		return value.AsBigFloat().Text('f', -1), nil
+	case ty == cty.Bool:
+		if g.language == TypeScript {
+			return fmt.Sprint(value.True()), nil
+		}
+		if value.True() {
+			return "True", nil
+		}
+		return "False", nil
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		values := []string{}
+		for it := value.ElementIterator(); it.Next(); {
+			_, element := it.Element()
+			v, err := g.literal(element, depth+1)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, v)
+		}
+		return g.list(values, depth), nil
+	case ty.IsMapType() || ty.IsObjectType():
+		keys, values := []string{}, []string{}
+		for it := value.ElementIterator(); it.Next(); {
+			key, element := it.Element()
+			v, err := g.literal(element, depth+1)
+			if err != nil {
+				return "", err
+			}
+			keys, values = append(keys, key.AsString()), append(values, v)
+		}
+		return g.object(keys, values, depth), nil
+	}
+	return "", fmt.Errorf("unsupported value of type %s", ty.FriendlyName())
+}
+
+// list writes values on one line when they fit, one per line otherwise
+func (g generator) list(values []string, depth int) string {
+	oneLine := "[" + strings.Join(values, ", ") + "]"
+	if !strings.Contains(oneLine, "\n") && len(oneLine) <= 80 {
+		return oneLine
+	}
+	var out strings.Builder
+	out.WriteString("[\n")
+	for _, value := range values {
+		fmt.Fprintf(&out, "%s%s,\n", g.indent(depth+1), value)
+	}
+	out.WriteString(g.indent(depth) + "]")
+	return out.String()
+}
+
+// object writes a map. Keys are data and are kept as they are
+func (g generator) object(keys []string, values []string, depth int) string {
+	if len(keys) == 0 {
+		return "{}"
+	}
+	var out strings.Builder
+	out.WriteString("{\n")
+	for i := range keys {
+		fmt.Fprintf(&out, "%s%s: %s,\n", g.indent(depth+1), quote(keys[i]), values[i])
+	}
+	out.WriteString(g.indent(depth) + "}")
+	return out.String()
+}
+
+// reference is the attribute of the variable holding the construct at address
+func (g generator) reference(address string, attributes []string) string {
+	parts := []string{g.variableName(address)}
+	for _, attribute := range attributes {
+		if g.language == TypeScript {
+			parts = append(parts, camelCase(attribute))
+		} else {
+			parts = append(parts, pythonName(attribute))
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// variableName is the variable holding the construct at address e.g. oneloginAppsWiki for onelogin_apps.wiki
+func (g generator) variableName(address string) string {
+	name := strings.Replace(address, ".", "_", -1)
+	if g.language == TypeScript {
+		return camelCase(name)
+	}
+	return strings.Join(words(name), "_")
+}
+
+func (g generator) indent(depth int) string {
+	if g.language == TypeScript {
+		return strings.Repeat("  ", depth)
+	}
+	return strings.Repeat("    ", depth)
+}
+
+// words splits a snake_case name, dropping empty words from leading or repeated underscores
+func words(name string) []string {
+	out := []string{}
+	for _, word := range strings.Split(name, "_") {
+		if word != "" {
+			out = append(out, word)
+		}
+	}
+	return out
+}
+
+func pascalCase(name string) string {
+	var out strings.Builder
+	for _, word := range words(name) {
+		out.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return out.String()
+}
+
+func camelCase(name string) string {
+	pascal := pascalCase(name)
+	if pascal == "" {
+		return pascal
+	}
+	return strings.ToLower(pascal[:1]) + pascal[1:]
+}
+
+// python keywords can't be used as argument names, the bindings add an underscore to them
+var pythonKeywords = map[string]bool{
+	"and": true, "as": true, "assert": true, "async": true, "await": true, "break": true, "class": true, "continue": true,
+	"def": true, "del": true, "elif": true, "else": true, "except": true, "finally": true, "for": true, "from": true,
+	"global": true, "if": true, "import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true,
+	"or": true, "pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+func pythonName(name string) string {
+	if pythonKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// quote writes a string literal valid in both TypeScript and Python
+func quote(s string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimRight(buffer.String(), "\n")
+}
+
+func traversalString(traversal hcl.Traversal) string {
+	parts := []string{traversal.RootName()}
+	for _, step := range traversal[1:] {
+		if attr, ok := step.(hcl.TraverseAttr); ok {
+			parts = append(parts, attr.Name)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,215 @@
+package cdktf
+
+import (
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testHCL = `provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_roles" "engineering" {
+  apps = [onelogin_apps.wiki.id, 40]
+  name = "Engineering"
+}
+
+resource "onelogin_apps" "wiki" {
+  connector_id = 22
+  name         = "Wiki"
+  parameters {
+    param_key_name = "email"
+  }
+  lifecycle {
+    ignore_changes = [updated_at]
+  }
+}
+`
+
+func TestConvert(t *testing.T) {
+	tests := map[string]struct {
+		Src         string
+		Language    Language
+		Expected    string
+		ExpectedErr string
+	}{
+		"It writes a TypeScript stack with resources after the ones they refer to": {
+			Src:      testHCL,
+			Language: TypeScript,
+			Expected: `import { Construct } from "constructs";
+import { App, TerraformStack } from "cdktf";
+import { Apps } from "./.gen/providers/onelogin/apps";
+import { OneloginProvider } from "./.gen/providers/onelogin/provider";
+import { Roles } from "./.gen/providers/onelogin/roles";
+
+class OneloginStack extends TerraformStack {
+  constructor(scope: Construct, id: string) {
+    super(scope, id);
+
+    new OneloginProvider(this, "onelogin", {
+      alias: "onelogin",
+    });
+
+    const oneloginAppsWiki = new Apps(this, "wiki", {
+      connectorId: 22,
+      name: "Wiki",
+      parameters: {
+        paramKeyName: "email",
+      },
+      lifecycle: {
+        ignoreChanges: ["updated_at"],
+      },
+    });
+
+    new Roles(this, "engineering", {
+      apps: [oneloginAppsWiki.id, 40],
+      name: "Engineering",
+    });
+  }
+}
+
+const app = new App();
+new OneloginStack(app, "onelogin");
+app.synth();
+`,
+		},
+		"It writes a Python stack": {
+			Src:      testHCL,
+			Language: Python,
+			Expected: `from constructs import Construct
+from cdktf import App, TerraformStack
+from imports.onelogin.apps import Apps
+from imports.onelogin.provider import OneloginProvider
+from imports.onelogin.roles import Roles
+
+
+class OneloginStack(TerraformStack):
+    def __init__(self, scope: Construct, id: str):
+        super().__init__(scope, id)
+
+        OneloginProvider(self, "onelogin",
+            alias="onelogin",
+        )
+
+        onelogin_apps_wiki = Apps(self, "wiki",
+            connector_id=22,
+            name="Wiki",
+            parameters={
+                "param_key_name": "email",
+            },
+            lifecycle={
+                "ignore_changes": ["updated_at"],
+            },
+        )
+
+        Roles(self, "engineering",
+            apps=[onelogin_apps_wiki.id, 40],
+            name="Engineering",
+        )
+
+
+app = App()
+OneloginStack(app, "onelogin")
+app.synth()
+`,
+		},
+		"It writes data sources as constructs other constructs refer to": {
+			Src: `data "onelogin_roles" "admins" {
+  id = "5"
+}
+
+resource "onelogin_users" "jane" {
+  roles = [data.onelogin_roles.admins.id]
+}
+`,
+			Language: TypeScript,
+			Expected: `import { Construct } from "constructs";
+import { App, TerraformStack } from "cdktf";
+import { DataOneloginRoles } from "./.gen/providers/onelogin/data-onelogin-roles";
+import { Users } from "./.gen/providers/onelogin/users";
+
+class OneloginStack extends TerraformStack {
+  constructor(scope: Construct, id: string) {
+    super(scope, id);
+
+    const dataOneloginRolesAdmins = new DataOneloginRoles(this, "admins", {
+      id: "5",
+    });
+
+    new Users(this, "jane", {
+      roles: [dataOneloginRolesAdmins.id],
+    });
+  }
+}
+
+const app = new App();
+new OneloginStack(app, "onelogin");
+app.synth();
+`,
+		},
+		"It rejects references it can't resolve": {
+			Src: `resource "onelogin_apps" "wiki" {
+  name = var.name
+}
+`,
+			Language:    TypeScript,
+			ExpectedErr: "unable to convert resource onelogin_apps.wiki: unsupported reference var.name",
+		},
+		"It rejects unknown languages": {
+			Src:         testHCL,
+			Language:    "java",
+			ExpectedErr: "unsupported language java",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Convert([]byte(test.Src), "main.tf", test.Language)
+			if test.ExpectedErr != "" {
+				assert.EqualError(t, err, test.ExpectedErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, string(actual))
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	tests := map[string]struct {
+		Name           string
+		ExpectedCamel  string
+		ExpectedPascal string
+		ExpectedPython string
+	}{
+		"It converts snake case":       {Name: "connector_id", ExpectedCamel: "connectorId", ExpectedPascal: "ConnectorId", ExpectedPython: "connector_id"},
+		"It drops leading underscores": {Name: "_wiki_1", ExpectedCamel: "wiki1", ExpectedPascal: "Wiki1", ExpectedPython: "_wiki_1"},
+		"It escapes python keywords":   {Name: "from", ExpectedCamel: "from", ExpectedPascal: "From", ExpectedPython: "from_"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.ExpectedCamel, camelCase(test.Name))
+			assert.Equal(t, test.ExpectedPascal, pascalCase(test.Name))
+			assert.Equal(t, test.ExpectedPython, pythonName(test.Name))
+		})
+	}
+}
+
+func TestConvertRendered(t *testing.T) {
+	state := stateparser.State{
+		Resources: []stateparser.StateResource{
+			stateparser.StateResource{
+				Name:      "wiki",
+				Type:      "onelogin_apps",
+				Instances: []stateparser.ResourceInstance{stateparser.ResourceInstance{Data: map[string]interface{}{"description": "line1\nline2"}}},
+			},
+		},
+	}
+	rendered, err := stateparser.Render(state, stateparser.Options{})
+	assert.Nil(t, err)
+	for _, language := range []Language{TypeScript, Python} {
+		actual, err := Convert(rendered, "main.tf", language)
+		assert.Nil(t, err)
+		assert.Contains(t, string(actual), `"line1\nline2"`, "a heredoc without a trailing newline is written as its string")
+	}
+}
@@ -0,0 +1,192 @@
+// Package tfdrift drift.go
+// This module compares the resources in terraform state with the same resources fetched from the remote so changes
+// made outside terraform, e.g. in the OneLogin admin console, can be found without running a plan.
+// Only the attributes in the importable's HCLShape are compared, the same ones written to main.tf.
+package tfdrift
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"reflect"
+	"sort"
+)
+
+// Change is an attribute whose value in state differs from the remote
+type Change struct {
+	Attribute string      `json:"attribute"`
+	State     interface{} `json:"state"`
+	Remote    interface{} `json:"remote"`
+}
+
+// ResourceDrift is what changed on the remote for one resource in state
+type ResourceDrift struct {
+	Address string   `json:"address"`
+	ID      string   `json:"id"`
+	Missing bool     `json:"missing,omitempty"` // the resource no longer exists on the remote
+	Error   string   `json:"error,omitempty"`   // the resource couldn't be fetched
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Drifted reports whether the resource differs from state in any way
+func (r ResourceDrift) Drifted() bool {
+	return r.Missing || r.Error != "" || len(r.Changes) > 0
+}
+
+// Report is the drift of every resource that was checked
+type Report struct {
+	Resources []ResourceDrift `json:"resources"`
+}
+
+// Drifted reports whether any resource drifted
+func (r Report) Drifted() bool {
+	for _, resource := range r.Resources {
+		if resource.Drifted() {
+			return true
+		}
+	}
+	return false
+}
+
+// Getter returns the importable for a resource type. Satisfied by tfimportables.ImportableList
+type Getter interface {
+	GetImportable(importableType string) (tfimportables.Importable, error)
+}
+
+// Detect fetches every managed resource in state that has an importable from the remote and compares them.
+// Data sources and resources of other providers are skipped. Detection stops when ctx is done
+func Detect(ctx context.Context, state stateparser.State, importables Getter) (Report, error) {
+	report := Report{Resources: []ResourceDrift{}}
+	for _, resource := range state.Resources {
+		if resource.Mode == "data" || !tfimportables.Registered(resource.Type) {
+			continue
+		}
+		importable, err := importables.GetImportable(resource.Type)
+		if err != nil {
+			return report, err
+		}
+		for _, instance := range resource.Instances {
+			attributes, _ := instance.Data.(map[string]interface{})
+			if attributes["id"] == nil {
+				continue
+			}
+			id := fmt.Sprint(attributes["id"])
+			drift := ResourceDrift{Address: resource.Type + "." + resource.Name, ID: id}
+			remote, err := tfimportables.ImportFromRemoteContext(ctx, importable, &id)
+			if ctx.Err() != nil {
+				return report, err
+			}
+			if clients.IsNotFound(err) {
+				drift.Missing = true
+				report.Resources = append(report.Resources, drift)
+				continue
+			}
+			if err != nil {
+				drift.Error = err.Error()
+				report.Resources = append(report.Resources, drift)
+				continue
+			}
+			found := false
+			for _, definition := range remote {
+				if definition.ImportID != id {
+					continue // some remotes, like aws, list every resource regardless of id
+				}
+				found = true
+				if drift.Changes, err = Compare(resource.Type, attributes, definition.Remote); err != nil {
+					return report, fmt.Errorf("unable to compare %s: %s", drift.Address, err)
+				}
+			}
+			drift.Missing = !found
+			report.Resources = append(report.Resources, drift)
+		}
+	}
+	return report, nil
+}
+
+// Compare lists the attributes of the resource type that differ between its state and its remote representation
+func Compare(resourceType string, stateAttributes map[string]interface{}, remote interface{}) ([]Change, error) {
+	stateValues, err := shaped(resourceType, stateAttributes)
+	if err != nil {
+		return nil, err
+	}
+	remoteValues, err := shaped(resourceType, remote)
+	if err != nil {
+		return nil, err
+	}
+	changes := []Change{}
+	diff("", stateValues, remoteValues, &changes)
+	return changes, nil
+}
+
+// shaped passes the value through the importable's HCLShape so state and remote are keyed and typed the same. The id
+// is left out, as it is how the resources were matched and is a string in state
+func shaped(resourceType string, value interface{}) (map[string]interface{}, error) {
+	shape, ok := tfimportables.HCLShape(resourceType)
+	if !ok {
+		return nil, fmt.Errorf("no importable for %s", resourceType)
+	}
+	fields, err := decoded(value)
+	if err != nil {
+		return nil, err
+	}
+	delete(fields, "id")
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	// fields the shape doesn't have are left out of the comparison
+	if err := json.Unmarshal(b, shape); err != nil {
+		return nil, err
+	}
+	return decoded(shape)
+}
+
+// decoded is the value as JSON decodes it, with numbers kept as written
+func decoded(value interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// diff appends the attributes that differ between the maps, descending into nested maps. Lists are compared whole
+func diff(path string, state map[string]interface{}, remote map[string]interface{}, changes *[]Change) {
+	keys := map[string]bool{}
+	for key := range state {
+		keys[key] = true
+	}
+	for key := range remote {
+		keys[key] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	for _, key := range sorted {
+		attribute := key
+		if path != "" {
+			attribute = path + "." + key
+		}
+		stateMap, stateIsMap := state[key].(map[string]interface{})
+		remoteMap, remoteIsMap := remote[key].(map[string]interface{})
+		if stateIsMap && remoteIsMap {
+			diff(attribute, stateMap, remoteMap, changes)
+			continue
+		}
+		if !reflect.DeepEqual(state[key], remote[key]) {
+			*changes = append(*changes, Change{Attribute: attribute, State: state[key], Remote: remote[key]})
+		}
+	}
+}
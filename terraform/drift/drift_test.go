@@ -0,0 +1,119 @@
+package tfdrift
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/apps"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type mockImportable struct {
+	remote map[string]apps.App
+}
+
+func (m mockImportable) ImportFromRemote(searchID *string) ([]tfimportables.ResourceDefinition, error) {
+	if *searchID == "500" {
+		return nil, errors.New("internal server error")
+	}
+	if *searchID == "404" {
+		return nil, errors.New(`unable to locate resource with id 404: {"statusCode":404,"name":"NotFoundError","message":"Not Found"}`)
+	}
+	app, ok := m.remote[*searchID]
+	if !ok {
+		return []tfimportables.ResourceDefinition{}, nil
+	}
+	return []tfimportables.ResourceDefinition{{Type: "onelogin_apps", ImportID: *searchID, Remote: app}}, nil
+}
+
+func (m mockImportable) HCLShape() interface{} {
+	return &tfimportables.AppData{}
+}
+
+type mockGetter struct {
+	importable tfimportables.Importable
+}
+
+func (m mockGetter) GetImportable(importableType string) (tfimportables.Importable, error) {
+	return m.importable, nil
+}
+
+func TestCompare(t *testing.T) {
+	tests := map[string]struct {
+		State    map[string]interface{}
+		Remote   interface{}
+		Expected []Change
+	}{
+		"It finds no changes when state matches the remote": {
+			State:    map[string]interface{}{"id": "12", "name": "Wiki", "connector_id": json.Number("22")},
+			Remote:   apps.App{ID: oltypes.Int32(12), Name: oltypes.String("Wiki"), ConnectorID: oltypes.Int32(22)},
+			Expected: []Change{},
+		},
+		"It lists changed attributes, including nested ones": {
+			State: map[string]interface{}{
+				"id":            "12",
+				"name":          "Wiki",
+				"visible":       true,
+				"configuration": map[string]interface{}{"redirect_uri": "https://wiki.example.com"},
+			},
+			Remote: apps.App{
+				ID:            oltypes.Int32(12),
+				Name:          oltypes.String("Team Wiki"),
+				Configuration: &apps.AppConfiguration{RedirectURI: oltypes.String("https://team.example.com")},
+			},
+			Expected: []Change{
+				{Attribute: "configuration.redirect_uri", State: "https://wiki.example.com", Remote: "https://team.example.com"},
+				{Attribute: "name", State: "Wiki", Remote: "Team Wiki"},
+				{Attribute: "visible", State: true, Remote: nil},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Compare("onelogin_apps", test.State, test.Remote)
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+
+	_, err := Compare("onelogin_apps", map[string]interface{}{"id": "12", "name": 12}, apps.App{ID: oltypes.Int32(12)})
+	assert.Error(t, err, "state that doesn't fit the shape can't be compared")
+}
+
+func TestDetect(t *testing.T) {
+	state, _ := stateparser.Parse(strings.NewReader(`{"version": 4, "resources": [
+		{"mode": "managed", "type": "onelogin_apps", "name": "wiki", "instances": [{"attributes": {"id": "12", "name": "Wiki"}}]},
+		{"mode": "managed", "type": "onelogin_apps", "name": "gone", "instances": [{"attributes": {"id": "13", "name": "Gone"}}]},
+		{"mode": "managed", "type": "onelogin_apps", "name": "flaky", "instances": [{"attributes": {"id": "500", "name": "Flaky"}}]},
+		{"mode": "managed", "type": "onelogin_apps", "name": "deleted", "instances": [{"attributes": {"id": "404", "name": "Deleted"}}]},
+		{"mode": "data", "type": "onelogin_apps", "name": "shared", "instances": [{"attributes": {"id": "14"}}]},
+		{"mode": "managed", "type": "random_pet", "name": "pet", "instances": [{"attributes": {"id": "rex"}}]}
+	]}`))
+	getter := mockGetter{importable: mockImportable{remote: map[string]apps.App{
+		"12": apps.App{ID: oltypes.Int32(12), Name: oltypes.String("Team Wiki")},
+	}}}
+
+	report, err := Detect(context.Background(), state, getter)
+	assert.Nil(t, err)
+	assert.True(t, report.Drifted())
+	assert.Equal(t, []ResourceDrift{
+		{Address: "onelogin_apps.wiki", ID: "12", Changes: []Change{{Attribute: "name", State: "Wiki", Remote: "Team Wiki"}}},
+		{Address: "onelogin_apps.gone", ID: "13", Missing: true},
+		{Address: "onelogin_apps.flaky", ID: "500", Error: "internal server error"},
+		{Address: "onelogin_apps.deleted", ID: "404", Missing: true},
+	}, report.Resources)
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Detect(cancelled, state, getter)
+	assert.EqualError(t, err, "stopped collecting resources: context canceled")
+}
+
+func TestReportDrifted(t *testing.T) {
+	assert.False(t, Report{Resources: []ResourceDrift{{Address: "onelogin_apps.wiki", ID: "12", Changes: []Change{}}}}.Drifted())
+}
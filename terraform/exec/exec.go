@@ -0,0 +1,206 @@
+// Package tfexec exec.go
+// This module wraps the terraform binary (or a compatible wrapper like terragrunt) so callers
+// don't need to know which executable is driving the workspace or where it lives.
+package tfexec
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+)
+
+// Runner names supported by the CLI
+const (
+	TerraformRunner  = "terraform"
+	TerragruntRunner = "terragrunt"
+)
+
+// Binaries that can drive a terraform workspace, in the order they are searched for on PATH
+const (
+	TerraformBinary = "terraform"
+	OpenTofuBinary  = "tofu"
+)
+
+// lookPath, goos and getenv are swapped out in tests so detection doesn't depend on the host
+var (
+	lookPath = exec.LookPath
+	goos     = runtime.GOOS
+	getenv   = os.Getenv
+)
+
+// windowsInstallDirs are where winget, Chocolatey and Scoop put the binaries they install, by the environment
+// variable each is under. Shells opened before the install don't have them on PATH yet
+var windowsInstallDirs = [][]string{
+	{"LOCALAPPDATA", "Microsoft", "WinGet", "Links"},
+	{"ProgramData", "chocolatey", "bin"},
+	{"USERPROFILE", "scoop", "shims"},
+}
+
+// matches the first line of `terraform version` and `tofu version` e.g. Terraform v1.5.7 or OpenTofu v1.6.0
+var versionLine = regexp.MustCompile(`^(Terraform|OpenTofu) v?(\S+)`)
+
+// Version is the flavor and version number reported by the binary
+type Version struct {
+	Product string // Terraform or OpenTofu
+	Number  string // e.g. 1.6.0
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%s v%s", v.Product, v.Number)
+}
+
+// Runner shells out to the terraform compatible binary in the given working directory
+type Runner struct {
+	Name       string   // terraform or terragrunt. Dictates which flags are passed to the binary
+	Binary     string   // path or name of the executable on PATH. Defaults to Name
+	WorkingDir string   // directory holding the configuration. Defaults to the current directory
+	Env        []string // added to the environment of every command as KEY=value e.g. TF_VAR_ credentials
+}
+
+// New creates a Runner for the given runner name, binary override, and working directory
+func New(name string, binary string, workingDir string) (Runner, error) {
+	if name == "" {
+		name = TerraformRunner
+	}
+	if name != TerraformRunner && name != TerragruntRunner {
+		return Runner{}, fmt.Errorf("unsupported runner %s", name)
+	}
+	if binary == "" {
+		binary = name
+		if name == TerraformRunner {
+			binary = DetectBinary()
+		}
+	}
+	return Runner{Name: name, Binary: binary, WorkingDir: workingDir}, nil
+}
+
+// DetectBinary returns terraform if it is on PATH, falling back to tofu for OpenTofu installs. On Windows, where
+// PATH is searched for terraform.exe, it then looks where package managers install them
+func DetectBinary() string {
+	for _, candidate := range []string{TerraformBinary, OpenTofuBinary} {
+		if _, err := lookPath(candidate); err == nil {
+			return candidate
+		}
+	}
+	if goos == "windows" {
+		for _, candidate := range []string{TerraformBinary, OpenTofuBinary} {
+			for _, dir := range windowsInstallDirs {
+				root := getenv(dir[0])
+				if root == "" {
+					continue
+				}
+				path := filepath.Join(append([]string{root}, dir[1:]...)...)
+				if _, err := lookPath(filepath.Join(path, candidate+".exe")); err == nil {
+					return filepath.Join(path, candidate+".exe")
+				}
+			}
+		}
+	}
+	return TerraformBinary
+}
+
+// ParseVersion reads the product and version number from the output of the version command
+func ParseVersion(output []byte) (Version, error) {
+	firstLine := bytes.SplitN(bytes.TrimSpace(output), []byte("\n"), 2)[0]
+	match := versionLine.FindSubmatch(firstLine)
+	if match == nil {
+		return Version{}, fmt.Errorf("unrecognized version output %q", firstLine)
+	}
+	return Version{Product: string(match[1]), Number: string(match[2])}, nil
+}
+
+// Command assembles the command to run against the workspace without running it. It runs in the working directory,
+// which terragrunt takes as its own, so a relative one isn't resolved twice with --terragrunt-working-dir
+func (r Runner) Command(args ...string) *exec.Cmd {
+	if r.Name == TerragruntRunner {
+		args = append(args, "--terragrunt-non-interactive")
+	}
+	// #nosec G204
+	cmd := exec.Command(r.Binary, args...)
+	cmd.Dir = r.WorkingDir
+	cmd.Env = append(os.Environ(), r.Env...)
+	return cmd
+}
+
+// Init runs init in the working directory
+func (r Runner) Init() error {
+	return r.run("init")
+}
+
+// Import runs import for the resource address with the given remote id
+func (r Runner) Import(address string, id string) error {
+	return r.run("import", address, id)
+}
+
+// Validate runs validate in the working directory, checking the configuration against the providers' schemas
+func (r Runner) Validate() error {
+	return r.run("validate")
+}
+
+// Version reports which product and version is behind the binary so OpenTofu can be told apart from Terraform
+func (r Runner) Version() (Version, error) {
+	var stdout bytes.Buffer
+	// #nosec G204
+	cmd := exec.Command(r.Binary, "version")
+	cmd.Dir = r.WorkingDir
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return Version{}, fmt.Errorf("%s version: %s", r.Binary, err)
+	}
+	return ParseVersion(stdout.Bytes())
+}
+
+// StatePull returns the current state of the workspace, wherever the backend keeps it
+func (r Runner) StatePull() ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := r.Command("state", "pull")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s state pull: %s %s", r.Binary, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// StatePush replaces the workspace's state with the given state
+func (r Runner) StatePush(state []byte) error {
+	var stderr bytes.Buffer
+	cmd := r.Command("state", "push", "-")
+	cmd.Stdin = bytes.NewReader(state)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s state push: %s %s", r.Binary, err, stderr.String())
+	}
+	return nil
+}
+
+// StateRm forgets the resources at the addresses, leaving the remote objects as they are
+func (r Runner) StateRm(addresses ...string) error {
+	return r.run(append([]string{"state", "rm"}, addresses...)...)
+}
+
+// ProvidersSchema returns the machine readable schemas of the providers used in the working directory
+func (r Runner) ProvidersSchema() ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := r.Command("providers", "schema", "-json")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s providers schema: %s %s", r.Binary, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+func (r Runner) run(args ...string) error {
+	var stderr bytes.Buffer
+	cmd := r.Command(args...)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s: %s %s", r.Binary, args[0], err, stderr.String())
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package tfexec
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func stubLookPath(onPath ...string) func(string) (string, error) {
+	return func(file string) (string, error) {
+		for _, p := range onPath {
+			if p == file {
+				return "/usr/bin/" + file, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := map[string]struct {
+		Name           string
+		Binary         string
+		ExpectedRunner Runner
+		ExpectError    bool
+	}{
+		"It defaults to terraform": {
+			ExpectedRunner: Runner{Name: "terraform", Binary: "terraform"},
+		},
+		"It uses the runner name as the binary": {
+			Name:           "terragrunt",
+			ExpectedRunner: Runner{Name: "terragrunt", Binary: "terragrunt"},
+		},
+		"It accepts a binary override": {
+			Binary:         "/usr/local/bin/tofu",
+			ExpectedRunner: Runner{Name: "terraform", Binary: "/usr/local/bin/tofu"},
+		},
+		"It rejects unknown runners": {
+			Name:        "pulumi",
+			ExpectError: true,
+		},
+	}
+	lookPath = stubLookPath("terraform")
+	defer func() { lookPath = exec.LookPath }()
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			runner, err := New(test.Name, test.Binary, "")
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedRunner, runner)
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	tests := map[string]struct {
+		Runner       Runner
+		Args         []string
+		ExpectedArgs []string
+		ExpectedDir  string
+	}{
+		"It passes args straight through to terraform": {
+			Runner:       Runner{Name: "terraform", Binary: "terraform", WorkingDir: "infra"},
+			Args:         []string{"import", "onelogin_apps.test", "1"},
+			ExpectedArgs: []string{"terraform", "import", "onelogin_apps.test", "1"},
+			ExpectedDir:  "infra",
+		},
+		"It runs terragrunt non-interactively in the working dir": {
+			Runner:       Runner{Name: "terragrunt", Binary: "terragrunt", WorkingDir: "infra"},
+			Args:         []string{"state", "pull"},
+			ExpectedArgs: []string{"terragrunt", "state", "pull", "--terragrunt-non-interactive"},
+			ExpectedDir:  "infra",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd := test.Runner.Command(test.Args...)
+			assert.Equal(t, test.ExpectedArgs, cmd.Args)
+			assert.Equal(t, test.ExpectedDir, cmd.Dir)
+		})
+	}
+}
+
+func TestDetectBinary(t *testing.T) {
+	scoop := filepath.Join("home", "scoop", "shims", "tofu.exe")
+	tests := map[string]struct {
+		OnPath   []string
+		GOOS     string
+		Expected string
+	}{
+		"It prefers terraform":                               {OnPath: []string{"terraform", "tofu"}, Expected: "terraform"},
+		"It falls back to tofu":                              {OnPath: []string{"tofu"}, Expected: "tofu"},
+		"It defaults to terraform when absent":               {Expected: "terraform"},
+		"It looks where package managers install on windows": {OnPath: []string{scoop}, GOOS: "windows", Expected: scoop},
+		"It only looks there on windows":                     {OnPath: []string{scoop}, GOOS: "linux", Expected: "terraform"},
+		"It prefers PATH on windows":                         {OnPath: []string{"terraform", scoop}, GOOS: "windows", Expected: "terraform"},
+	}
+	getenv = func(key string) string {
+		if key == "USERPROFILE" {
+			return "home"
+		}
+		return ""
+	}
+	defer func() { lookPath, goos, getenv = exec.LookPath, runtime.GOOS, os.Getenv }()
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lookPath = stubLookPath(test.OnPath...)
+			goos = test.GOOS
+			assert.Equal(t, test.Expected, DetectBinary())
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := map[string]struct {
+		Output      string
+		Expected    Version
+		ExpectError bool
+	}{
+		"It parses terraform": {
+			Output:   "Terraform v1.5.7\non linux_amd64\n+ provider registry.terraform.io/onelogin/onelogin v0.1.0\n",
+			Expected: Version{Product: "Terraform", Number: "1.5.7"},
+		},
+		"It parses opentofu": {
+			Output:   "OpenTofu v1.6.0\non darwin_arm64\n",
+			Expected: Version{Product: "OpenTofu", Number: "1.6.0"},
+		},
+		"It errors on unknown output": {
+			Output:      "command not found",
+			ExpectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseVersion([]byte(test.Output))
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
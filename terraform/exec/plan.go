@@ -0,0 +1,119 @@
+package tfexec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// name of the plan file written to the working directory while verifying. Removed once read
+const verifyPlanFile = ".onelogin-verify.tfplan"
+
+// ResourceChange is a resource the plan would change and the attributes that differ, with their values before and
+// after the change
+type ResourceChange struct {
+	Address    string                 `json:"address"`
+	Actions    []string               `json:"actions"`
+	Attributes []string               `json:"attributes"`
+	Before     map[string]interface{} `json:"-"`
+	After      map[string]interface{} `json:"-"`
+}
+
+// PlanResult summarizes a plan of the workspace
+type PlanResult struct {
+	Changes []ResourceChange
+}
+
+// Empty reports whether the plan would change nothing
+func (p PlanResult) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// showPlan is the subset of `terraform show -json <plan>` needed to list changes
+type showPlan struct {
+	ResourceChanges []struct {
+		Address string `json:"address"`
+		Change  struct {
+			Actions []string               `json:"actions"`
+			Before  map[string]interface{} `json:"before"`
+			After   map[string]interface{} `json:"after"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// Plan runs plan with -detailed-exitcode and, when there are changes, lists them per resource
+func (r Runner) Plan() (PlanResult, error) {
+	var stderr bytes.Buffer
+	planPath := filepath.Join(r.WorkingDir, verifyPlanFile)
+	defer os.Remove(planPath)
+
+	cmd := r.Command("plan", "-detailed-exitcode", "-input=false", "-out="+verifyPlanFile)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err == nil {
+		return PlanResult{}, nil
+	}
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+		return PlanResult{}, fmt.Errorf("%s plan: %s %s", r.Binary, err, stderr.String())
+	}
+
+	var stdout bytes.Buffer
+	stderr.Reset()
+	cmd = r.Command("show", "-json", verifyPlanFile)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return PlanResult{}, fmt.Errorf("%s show: %s %s", r.Binary, err, stderr.String())
+	}
+	changes, err := ParsePlanChanges(stdout.Bytes())
+	if err != nil {
+		return PlanResult{}, err
+	}
+	return PlanResult{Changes: changes}, nil
+}
+
+// ParsePlanChanges reads the output of `terraform show -json <plan>` and lists the resources
+// that would change along with the top level attributes that differ
+func ParsePlanChanges(data []byte) ([]ResourceChange, error) {
+	plan := showPlan{}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("unable to parse plan: %s", err)
+	}
+	changes := []ResourceChange{}
+	for _, rc := range plan.ResourceChanges {
+		if len(rc.Change.Actions) == 1 && (rc.Change.Actions[0] == "no-op" || rc.Change.Actions[0] == "read") {
+			continue
+		}
+		changes = append(changes, ResourceChange{
+			Address:    rc.Address,
+			Actions:    rc.Change.Actions,
+			Attributes: changedAttributes(rc.Change.Before, rc.Change.After),
+			Before:     rc.Change.Before,
+			After:      rc.Change.After,
+		})
+	}
+	return changes, nil
+}
+
+func changedAttributes(before map[string]interface{}, after map[string]interface{}) []string {
+	changed := []string{}
+	seen := map[string]bool{}
+	for _, m := range []map[string]interface{}{before, after} {
+		for k := range m {
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+			if !reflect.DeepEqual(before[k], after[k]) {
+				changed = append(changed, k)
+			}
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
@@ -0,0 +1,45 @@
+package tfexec
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParsePlanChanges(t *testing.T) {
+	tests := map[string]struct {
+		Plan        string
+		Expected    []ResourceChange
+		ExpectError bool
+	}{
+		"It lists changed resources and attributes": {
+			Plan: `{"resource_changes": [
+				{"address": "onelogin_apps._a_1", "change": {"actions": ["update"], "before": {"name": "a", "visible": true, "notes": null}, "after": {"name": "a", "visible": false, "notes": "hi"}}},
+				{"address": "onelogin_apps._b_2", "change": {"actions": ["no-op"], "before": {"name": "b"}, "after": {"name": "b"}}},
+				{"address": "onelogin_roles._c_3", "change": {"actions": ["delete", "create"], "before": {"name": "c"}, "after": {"name": "c", "apps": [1]}}}
+			]}`,
+			Expected: []ResourceChange{
+				ResourceChange{Address: "onelogin_apps._a_1", Actions: []string{"update"}, Attributes: []string{"notes", "visible"},
+					Before: map[string]interface{}{"name": "a", "visible": true, "notes": nil},
+					After:  map[string]interface{}{"name": "a", "visible": false, "notes": "hi"}},
+				ResourceChange{Address: "onelogin_roles._c_3", Actions: []string{"delete", "create"}, Attributes: []string{"apps"},
+					Before: map[string]interface{}{"name": "c"},
+					After:  map[string]interface{}{"name": "c", "apps": []interface{}{float64(1)}}},
+			},
+		},
+		"It errors on garbage": {
+			Plan:        "not json",
+			ExpectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParsePlanChanges([]byte(test.Plan))
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package tfexec
+
+import (
+	"github.com/onelogin/onelogin/logger"
+	"regexp"
+	"time"
+)
+
+// matches the ways the provider and api report being throttled: 429 Too Many Requests, or the 429 status code in a
+// response body like {"statusCode":429} or status: 429. A bare 429 could be any id or number in the output
+var rateLimitPattern = regexp.MustCompile(`(?i)(too many requests|rate limit|status(_?code)?"?\s*[:=]\s*429\b|"code"\s*:\s*429\b)`)
+
+// sleep is swapped out in tests so retries don't slow the suite down
+var sleep = time.Sleep
+
+// RetryPolicy controls the pacing between imports and how rate limited imports are retried
+type RetryPolicy struct {
+	Interval   time.Duration // pause after every import, and the base of the backoff after a rate limited one
+	MaxRetries int           // how many times a rate limited import is retried before giving up
+}
+
+// minimum backoff so retries still back off when no interval is configured
+const minBackoff = time.Second
+
+// IsRateLimited reports whether the error came from the remote throttling us
+func IsRateLimited(err error) bool {
+	return err != nil && rateLimitPattern.MatchString(err.Error())
+}
+
+// Retry runs op, retrying with exponential backoff while it fails because of rate limiting.
+// Any other error is returned immediately. The interval is observed after every attempt
+func (p RetryPolicy) Retry(op func() error) error {
+	backoff := p.Interval
+	if backoff < minBackoff {
+		backoff = minBackoff
+	}
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = op()
+		if p.Interval > 0 {
+			sleep(p.Interval)
+		}
+		if !IsRateLimited(err) || attempt >= p.MaxRetries {
+			return err
+		}
+		logger.Warn("Rate limited, retrying", "delay", backoff, "retry", attempt+1, "max_retries", p.MaxRetries)
+		sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// ImportWithRetry runs import for the resource address, pacing and retrying per the policy
+func (r Runner) ImportWithRetry(address string, id string, policy RetryPolicy) error {
+	return policy.Retry(func() error {
+		return r.Import(address, id)
+	})
+}
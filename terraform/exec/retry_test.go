@@ -0,0 +1,79 @@
+package tfexec
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	rateLimited := errors.New("terraform import: exit status 1 Error: 429 Too Many Requests")
+	otherError := errors.New("terraform import: exit status 1 Error: resource not found")
+	tests := map[string]struct {
+		Policy           RetryPolicy
+		Errors           []error
+		ExpectedAttempts int
+		ExpectedSleeps   []time.Duration
+		ExpectedError    error
+	}{
+		"It retries rate limited imports with backoff": {
+			Policy:           RetryPolicy{MaxRetries: 3},
+			Errors:           []error{rateLimited, rateLimited, nil},
+			ExpectedAttempts: 3,
+			ExpectedSleeps:   []time.Duration{time.Second, 2 * time.Second},
+		},
+		"It paces every attempt by the interval": {
+			Policy:           RetryPolicy{Interval: 2 * time.Second, MaxRetries: 1},
+			Errors:           []error{rateLimited, nil},
+			ExpectedAttempts: 2,
+			ExpectedSleeps:   []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second},
+		},
+		"It gives up after max retries": {
+			Policy:           RetryPolicy{MaxRetries: 1},
+			Errors:           []error{rateLimited, rateLimited, nil},
+			ExpectedAttempts: 2,
+			ExpectedSleeps:   []time.Duration{time.Second},
+			ExpectedError:    rateLimited,
+		},
+		"It does not retry other errors": {
+			Policy:           RetryPolicy{MaxRetries: 3},
+			Errors:           []error{otherError, nil},
+			ExpectedAttempts: 1,
+			ExpectedError:    otherError,
+		},
+	}
+	defer func() { sleep = time.Sleep }()
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sleeps []time.Duration
+			sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+			attempts := 0
+			err := test.Policy.Retry(func() error {
+				err := test.Errors[attempts]
+				attempts++
+				return err
+			})
+			assert.Equal(t, test.ExpectedError, err)
+			assert.Equal(t, test.ExpectedAttempts, attempts)
+			assert.Equal(t, test.ExpectedSleeps, sleeps)
+		})
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := map[string]bool{
+		"Error: 429 Too Many Requests":                                        true,
+		`Error: {"statusCode":429,"name":"TooManyRequests"}`:                  true,
+		`Error: {"status":{"error":true,"code":429,"type":"rate limit"}}`:     true,
+		"Error: unexpected status: 429":                                       true,
+		"Error: API rate limit exceeded":                                      true,
+		"Error: Cannot import non-existent remote object onelogin_apps 14290": false,
+		"Error: role 429 not found":                                           false,
+	}
+	for output, expected := range tests {
+		t.Run(output, func(t *testing.T) {
+			assert.Equal(t, expected, IsRateLimited(errors.New(output)))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package tfimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// AddressMapFile is where the addresses remote resources were imported at are kept, in the working directory
+const AddressMapFile = ".onelogin-address-map.json"
+
+// ResourceKey identifies a remote resource across runs, by type, id and the alias of the account it was imported from
+func ResourceKey(resourceDefinition tfimportables.ResourceDefinition) string {
+	return resourceDefinition.ProviderAlias + "/" + resourceDefinition.Type + "/" + resourceDefinition.ImportID
+}
+
+// MappedResource is an entry of the address map file
+type MappedResource struct {
+	Type          string `json:"type"`
+	ID            string `json:"id"`
+	ProviderAlias string `json:"provider_alias,omitempty"`
+	Address       string `json:"address"`
+}
+
+// AddressMap is the address each remote resource was imported at, by ResourceKey. Resources are looked up in it so
+// one renamed on the remote, which gets a new name, isn't imported a second time
+type AddressMap struct {
+	resources map[string]MappedResource
+}
+
+// ReadAddressMap reads the address map kept in dir, empty when there's none yet
+func ReadAddressMap(dir string) (AddressMap, error) {
+	addressMap := AddressMap{resources: map[string]MappedResource{}}
+	data, err := ioutil.ReadFile(filepath.Join(dir, AddressMapFile))
+	if os.IsNotExist(err) {
+		return addressMap, nil
+	}
+	if err != nil {
+		return addressMap, err
+	}
+	list := []MappedResource{}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return addressMap, fmt.Errorf("unable to read %s: %s", AddressMapFile, err)
+	}
+	for _, resource := range list {
+		addressMap.resources[ResourceKey(tfimportables.ResourceDefinition{Type: resource.Type, ImportID: resource.ID, ProviderAlias: resource.ProviderAlias})] = resource
+	}
+	return addressMap, nil
+}
+
+// Write saves the address map in dir, sorted by address so changes to it diff cleanly
+func (m AddressMap) Write(dir string) error {
+	list := make([]MappedResource, 0, len(m.resources))
+	for _, resource := range m.resources {
+		list = append(list, resource)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Address < list[j].Address })
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, AddressMapFile)
+	if err := ioutil.WriteFile(path+".tmp", append(data, '\n'), 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Add records the addresses the chunk's resources were imported at
+func (m AddressMap) Add(chunk Chunk) {
+	for i, resourceDefinition := range chunk.Resources {
+		m.resources[ResourceKey(resourceDefinition)] = MappedResource{
+			Type:          resourceDefinition.Type,
+			ID:            resourceDefinition.ImportID,
+			ProviderAlias: resourceDefinition.ProviderAlias,
+			Address:       chunk.Address(i),
+		}
+	}
+}
+
+// Address is where the resource was imported at, if it was
+func (m AddressMap) Address(resourceDefinition tfimportables.ResourceDefinition) (string, bool) {
+	resource, ok := m.resources[ResourceKey(resourceDefinition)]
+	return resource.Address, ok
+}
+
+// FilterMapped leaves out the resources already imported at an address configuration still declares, returning the
+// others along with the addresses of those left out. Resources whose block was removed are imported again
+func (m AddressMap) FilterMapped(headers DefinitionHeaders, resources []tfimportables.ResourceDefinition) ([]tfimportables.ResourceDefinition, []string) {
+	remaining, mapped := []tfimportables.ResourceDefinition{}, []string{}
+	for _, resourceDefinition := range resources {
+		if address, ok := m.Address(resourceDefinition); ok && headers.Resources[address] > 0 {
+			mapped = append(mapped, address)
+			continue
+		}
+		remaining = append(remaining, resourceDefinition)
+	}
+	return remaining, mapped
+}
@@ -0,0 +1,51 @@
+package tfimport
+
+import (
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddressMap(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "address-map")
+	defer os.RemoveAll(dir)
+
+	addressMap, err := ReadAddressMap(dir)
+	assert.Nil(t, err)
+	addressMap.Add(Chunk{Resources: []tfimportables.ResourceDefinition{
+		{Name: "wiki", Type: "onelogin_apps", ImportID: "12"},
+		{Name: "wiki", Type: "onelogin_apps", ImportID: "12", ProviderAlias: "emea"},
+	}})
+	assert.Nil(t, addressMap.Write(dir))
+	written, _ := ioutil.ReadFile(filepath.Join(dir, AddressMapFile))
+	assert.Equal(t, `[
+  {
+    "type": "onelogin_apps",
+    "id": "12",
+    "address": "onelogin_apps._wiki_1"
+  },
+  {
+    "type": "onelogin_apps",
+    "id": "12",
+    "provider_alias": "emea",
+    "address": "onelogin_apps._wiki_2"
+  }
+]
+`, string(written))
+
+	read, err := ReadAddressMap(dir)
+	assert.Nil(t, err)
+	headers := newDefinitionHeaders()
+	headers.Resources["onelogin_apps._wiki_1"] = 1
+	incoming := []tfimportables.ResourceDefinition{
+		{Name: "docs", Type: "onelogin_apps", ImportID: "12"},                        // renamed on the remote
+		{Name: "docs", Type: "onelogin_apps", ImportID: "12", ProviderAlias: "emea"}, // its block was removed
+		{Name: "new", Type: "onelogin_apps", ImportID: "13"},
+	}
+	remaining, mapped := read.FilterMapped(headers, incoming)
+	assert.Equal(t, incoming[1:], remaining)
+	assert.Equal(t, []string{"onelogin_apps._wiki_1"}, mapped)
+}
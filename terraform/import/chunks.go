@@ -0,0 +1,132 @@
+package tfimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// CheckpointFile is where a chunked import records its progress, in the working directory
+const CheckpointFile = ".onelogin-import-checkpoint.json"
+
+// Chunk is a batch of the resources to import. First is the index of its first resource among all of them, so
+// resources are named the same whatever the chunk size
+type Chunk struct {
+	First     int
+	Resources []tfimportables.ResourceDefinition
+}
+
+// Chunks splits the resources into batches of size, numbered from first. A size of 0 or less makes a single batch
+func Chunks(resourceDefinitions []tfimportables.ResourceDefinition, size int, first int) []Chunk {
+	if size <= 0 || size > len(resourceDefinitions) {
+		size = len(resourceDefinitions)
+	}
+	chunks := []Chunk{}
+	for start := 0; start < len(resourceDefinitions); start += size {
+		end := start + size
+		if end > len(resourceDefinitions) {
+			end = len(resourceDefinitions)
+		}
+		chunks = append(chunks, Chunk{First: first + start, Resources: resourceDefinitions[start:end]})
+	}
+	return chunks
+}
+
+// Address is the terraform address of the chunk's i-th resource
+func (c Chunk) Address(i int) string {
+	return ImportAddress(c.Resources[i], c.First+i)
+}
+
+// Addresses lists the terraform addresses of the chunk's resources
+func (c Chunk) Addresses() []string {
+	addresses := make([]string, len(c.Resources))
+	for i := range c.Resources {
+		addresses[i] = c.Address(i)
+	}
+	return addresses
+}
+
+// WriteHeaders appends the empty resource definitions of the chunk's resources for terraform import to pick up
+func (c Chunk) WriteHeaders(planFile io.Writer) error {
+	return writeHeaders(c.Resources, c.First, nil, planFile)
+}
+
+// Checkpoint is the progress of a chunked import, written after each chunk so an interrupted import resumes from
+// the last chunk written instead of starting over
+type Checkpoint struct {
+	Resource   string   `json:"resource"` // the resource type imported e.g. onelogin_apps
+	ChunkSize  int      `json:"chunk_size"`
+	Next       int      `json:"next"`        // index naming the first resource of the next chunk
+	ConfigSize int64    `json:"config_size"` // length of main.tf once the last chunk was written
+	Imported   []string `json:"imported"`    // keys of the resources imported, see ResourceKey
+	Pending    []string `json:"pending"`     // addresses of the chunk being imported, which may be partly in state
+}
+
+// ReadCheckpoint reads the checkpoint left in dir by an interrupted import, nil when there's none
+func ReadCheckpoint(dir string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, CheckpointFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := Checkpoint{}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %s", CheckpointFile, err)
+	}
+	return &checkpoint, nil
+}
+
+// Write saves the checkpoint in dir, replacing the previous one only once it's fully written
+func (c Checkpoint) Write(dir string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, CheckpointFile)
+	if err := ioutil.WriteFile(path+".tmp", data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Remove deletes the checkpoint in dir once the import is done
+func (c Checkpoint) Remove(dir string) error {
+	if err := os.Remove(filepath.Join(dir, CheckpointFile)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Start records the chunk as being imported into main.tf, which was configSize long before its resources were added
+func (c *Checkpoint) Start(chunk Chunk, configSize int64) {
+	c.ConfigSize, c.Pending = configSize, chunk.Addresses()
+}
+
+// Done records the chunk as imported and written to main.tf, which is now configSize long
+func (c *Checkpoint) Done(chunk Chunk, configSize int64) {
+	for _, resourceDefinition := range chunk.Resources {
+		c.Imported = append(c.Imported, ResourceKey(resourceDefinition))
+	}
+	c.Next, c.ConfigSize, c.Pending = chunk.First+len(chunk.Resources), configSize, nil
+}
+
+// Remaining leaves out the resources the checkpoint records as imported
+func (c Checkpoint) Remaining(resourceDefinitions []tfimportables.ResourceDefinition) []tfimportables.ResourceDefinition {
+	imported := map[string]bool{}
+	for _, key := range c.Imported {
+		imported[key] = true
+	}
+	remaining := []tfimportables.ResourceDefinition{}
+	for _, resourceDefinition := range resourceDefinitions {
+		if !imported[ResourceKey(resourceDefinition)] {
+			remaining = append(remaining, resourceDefinition)
+		}
+	}
+	return remaining
+}
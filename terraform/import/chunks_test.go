@@ -0,0 +1,91 @@
+package tfimport
+
+import (
+	"bytes"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestChunks(t *testing.T) {
+	resources := []tfimportables.ResourceDefinition{
+		{Name: "a", Type: "onelogin_apps", ImportID: "1"},
+		{Name: "b", Type: "onelogin_apps", ImportID: "2"},
+		{Name: "c", Type: "onelogin_apps", ImportID: "3"},
+	}
+	tests := map[string]struct {
+		Size              int
+		First             int
+		ExpectedAddresses [][]string
+	}{
+		"It batches the resources": {
+			Size:              2,
+			ExpectedAddresses: [][]string{{"onelogin_apps._a_1", "onelogin_apps._b_2"}, {"onelogin_apps._c_3"}},
+		},
+		"It makes one batch without a size": {
+			ExpectedAddresses: [][]string{{"onelogin_apps._a_1", "onelogin_apps._b_2", "onelogin_apps._c_3"}},
+		},
+		"It numbers resources on from first": {
+			Size:              5,
+			First:             4,
+			ExpectedAddresses: [][]string{{"onelogin_apps._a_5", "onelogin_apps._b_6", "onelogin_apps._c_7"}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			addresses := [][]string{}
+			for _, chunk := range Chunks(resources, test.Size, test.First) {
+				addresses = append(addresses, chunk.Addresses())
+			}
+			assert.Equal(t, test.ExpectedAddresses, addresses)
+		})
+	}
+	assert.Empty(t, Chunks(nil, 2, 0))
+}
+
+func TestChunkWriteHeaders(t *testing.T) {
+	chunk := Chunk{First: 2, Resources: []tfimportables.ResourceDefinition{{Name: "wiki", Type: "onelogin_apps", ImportID: "3"}}}
+	var out bytes.Buffer
+	assert.Nil(t, chunk.WriteHeaders(&out))
+	assert.Equal(t, "resource \"onelogin_apps\" \"_wiki_3\" {\n}\n", out.String())
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "checkpoint")
+	defer os.RemoveAll(dir)
+	resources := []tfimportables.ResourceDefinition{
+		{Name: "a", Type: "onelogin_apps", ImportID: "1"},
+		{Name: "b", Type: "onelogin_apps", ImportID: "2"},
+		{Name: "b", Type: "onelogin_apps", ImportID: "2", ProviderAlias: "emea"},
+	}
+	chunks := Chunks(resources, 2, 0)
+
+	none, err := ReadCheckpoint(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, none, "there's no checkpoint before an import")
+
+	checkpoint := Checkpoint{Resource: "onelogin_apps", ChunkSize: 2}
+	checkpoint.Start(chunks[0], 10)
+	checkpoint.Done(chunks[0], 120)
+	checkpoint.Start(chunks[1], 120)
+	assert.Nil(t, checkpoint.Write(dir))
+
+	read, err := ReadCheckpoint(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, &Checkpoint{
+		Resource:   "onelogin_apps",
+		ChunkSize:  2,
+		Next:       2,
+		ConfigSize: 120,
+		Imported:   []string{"/onelogin_apps/1", "/onelogin_apps/2"},
+		Pending:    []string{"onelogin_apps._b_3"},
+	}, read)
+	assert.Equal(t, resources[2:], read.Remaining(resources))
+
+	assert.Nil(t, read.Remove(dir))
+	none, err = ReadCheckpoint(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, none)
+}
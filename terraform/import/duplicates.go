@@ -0,0 +1,104 @@
+package tfimport
+
+import (
+	"fmt"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ways of telling apart remote resources of a type sharing a name
+const (
+	DuplicatesIndex    = "index"     // leave the names, the index in their addresses tells them apart e.g. _wiki_1 and _wiki_2
+	DuplicatesSuffixID = "suffix-id" // add the resource's id to its name e.g. wiki_12
+	DuplicatesSkip     = "skip"      // import none of them
+	DuplicatesRename   = "rename"    // ask for a name for each
+)
+
+// DuplicateStrategies lists the ways of handling duplicates, for flag help and completion
+var DuplicateStrategies = []string{DuplicatesIndex, DuplicatesSuffixID, DuplicatesSkip, DuplicatesRename}
+
+// characters that can't be in a name, replaced when adding ids to names
+var invalidNameCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// Duplicate is a name shared by several remote resources of a type
+type Duplicate struct {
+	Type string   `json:"type"`
+	Name string   `json:"name"`
+	IDs  []string `json:"ids"`
+}
+
+// FindDuplicates lists the names shared by several resources of a type, sorted by type and name
+func FindDuplicates(resources []tfimportables.ResourceDefinition) []Duplicate {
+	ids := map[string][]string{}
+	for _, resource := range resources {
+		key := resource.Type + "." + resource.Name
+		ids[key] = append(ids[key], resource.ImportID)
+	}
+	duplicates := []Duplicate{}
+	for key, shared := range ids {
+		if len(shared) > 1 {
+			parts := strings.SplitN(key, ".", 2)
+			duplicates = append(duplicates, Duplicate{Type: parts[0], Name: parts[1], IDs: shared})
+		}
+	}
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Type != duplicates[j].Type {
+			return duplicates[i].Type < duplicates[j].Type
+		}
+		return duplicates[i].Name < duplicates[j].Name
+	})
+	return duplicates
+}
+
+// ResolveDuplicates applies the strategy to the resources sharing a name with others of their type, returning the
+// resources to import and those skipped. rename is asked for the new name of each duplicate with DuplicatesRename,
+// an empty name leaving it as it is
+func ResolveDuplicates(resources []tfimportables.ResourceDefinition, strategy string, rename func(tfimportables.ResourceDefinition) (string, error)) ([]tfimportables.ResourceDefinition, []tfimportables.ResourceDefinition, error) {
+	duplicated := map[string]bool{}
+	for _, duplicate := range FindDuplicates(resources) {
+		duplicated[duplicate.Type+"."+duplicate.Name] = true
+	}
+	if len(duplicated) == 0 || strategy == DuplicatesIndex {
+		return resources, nil, nil
+	}
+	taken := map[string]bool{}
+	for _, resource := range resources {
+		taken[resource.Type+"."+resource.Name] = true
+	}
+	kept, skipped := []tfimportables.ResourceDefinition{}, []tfimportables.ResourceDefinition{}
+	for _, resource := range resources {
+		if !duplicated[resource.Type+"."+resource.Name] {
+			kept = append(kept, resource)
+			continue
+		}
+		switch strategy {
+		case DuplicatesSkip:
+			skipped = append(skipped, resource)
+			continue
+		case DuplicatesSuffixID:
+			resource.Name = resource.Name + "_" + invalidNameCharacters.ReplaceAllString(resource.ImportID, "_")
+		case DuplicatesRename:
+			name, err := rename(resource)
+			if err != nil {
+				return nil, nil, err
+			}
+			if name != "" {
+				if !hclsyntax.ValidIdentifier(name) {
+					return nil, nil, fmt.Errorf("%s can't be used as a name, use letters, digits, underscores and dashes", name)
+				}
+				if taken[resource.Type+"."+name] {
+					return nil, nil, fmt.Errorf("another %s is already named %s", resource.Type, name)
+				}
+				resource.Name = name
+				taken[resource.Type+"."+name] = true
+			}
+		default:
+			return nil, nil, fmt.Errorf("unknown way of handling duplicates %s, expected one of %s", strategy, strings.Join(DuplicateStrategies, ", "))
+		}
+		kept = append(kept, resource)
+	}
+	return kept, skipped, nil
+}
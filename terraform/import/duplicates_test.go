@@ -0,0 +1,100 @@
+package tfimport
+
+import (
+	"errors"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	resources := []tfimportables.ResourceDefinition{
+		{Type: "onelogin_users", Name: "ann", ImportID: "7"},
+		{Type: "onelogin_apps", Name: "wiki", ImportID: "12"},
+		{Type: "onelogin_apps", Name: "wiki", ImportID: "40"},
+		{Type: "onelogin_apps", Name: "emea_wiki", ImportID: "12", ProviderAlias: "emea"},
+		{Type: "onelogin_users", Name: "ann", ImportID: "8"},
+	}
+	assert.Equal(t, []Duplicate{
+		{Type: "onelogin_apps", Name: "wiki", IDs: []string{"12", "40"}},
+		{Type: "onelogin_users", Name: "ann", IDs: []string{"7", "8"}},
+	}, FindDuplicates(resources))
+}
+
+func TestResolveDuplicates(t *testing.T) {
+	resources := []tfimportables.ResourceDefinition{
+		{Type: "onelogin_apps", Name: "wiki", ImportID: "12"},
+		{Type: "onelogin_apps", Name: "wiki", ImportID: "40"},
+		{Type: "onelogin_apps", Name: "docs", ImportID: "41"},
+	}
+	names := map[string]string{"12": "wiki_us", "40": ""}
+	tests := map[string]struct {
+		Strategy        string
+		Rename          func(tfimportables.ResourceDefinition) (string, error)
+		ExpectedNames   []string
+		ExpectedSkipped []string
+		ExpectedError   string
+	}{
+		"It leaves the names with index": {
+			Strategy:      DuplicatesIndex,
+			ExpectedNames: []string{"wiki", "wiki", "docs"},
+		},
+		"It adds ids to the names": {
+			Strategy:      DuplicatesSuffixID,
+			ExpectedNames: []string{"wiki_12", "wiki_40", "docs"},
+		},
+		"It skips duplicates": {
+			Strategy:        DuplicatesSkip,
+			ExpectedNames:   []string{"docs"},
+			ExpectedSkipped: []string{"12", "40"},
+		},
+		"It renames duplicates as asked": {
+			Strategy:      DuplicatesRename,
+			Rename:        func(resource tfimportables.ResourceDefinition) (string, error) { return names[resource.ImportID], nil },
+			ExpectedNames: []string{"wiki_us", "wiki", "docs"},
+		},
+		"It rejects names taken": {
+			Strategy:      DuplicatesRename,
+			Rename:        func(resource tfimportables.ResourceDefinition) (string, error) { return "docs", nil },
+			ExpectedError: "another onelogin_apps is already named docs",
+		},
+		"It rejects invalid names": {
+			Strategy:      DuplicatesRename,
+			Rename:        func(resource tfimportables.ResourceDefinition) (string, error) { return "my wiki", nil },
+			ExpectedError: "my wiki can't be used as a name, use letters, digits, underscores and dashes",
+		},
+		"It stops when renaming fails": {
+			Strategy:      DuplicatesRename,
+			Rename:        func(resource tfimportables.ResourceDefinition) (string, error) { return "", errors.New("no input") },
+			ExpectedError: "no input",
+		},
+		"It rejects unknown strategies": {
+			Strategy:      "merge",
+			ExpectedError: "unknown way of handling duplicates merge, expected one of index, suffix-id, skip, rename",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			kept, skipped, err := ResolveDuplicates(resources, test.Strategy, test.Rename)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			names := []string{}
+			for _, resource := range kept {
+				names = append(names, resource.Name)
+			}
+			skippedIDs := []string{}
+			for _, resource := range skipped {
+				skippedIDs = append(skippedIDs, resource.ImportID)
+			}
+			assert.Equal(t, test.ExpectedNames, names)
+			if test.ExpectedSkipped == nil {
+				test.ExpectedSkipped = []string{}
+			}
+			assert.Equal(t, test.ExpectedSkipped, skippedIDs)
+		})
+	}
+	assert.Equal(t, "wiki", resources[0].Name, "the input is left as it was")
+}
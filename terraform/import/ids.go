@@ -0,0 +1,55 @@
+package tfimport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// ReadIDs reads the ids of resources to import, one per line or as a JSON array of strings or numbers. Blank lines
+// and lines starting with # are skipped, and repeated ids are kept once
+func ReadIDs(r io.Reader) ([]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+		var list []interface{}
+		if err := decoder.Decode(&list); err != nil {
+			return nil, fmt.Errorf("unable to read ids as a JSON array: %s", err)
+		}
+		for i, item := range list {
+			switch id := item.(type) {
+			case string:
+				ids = append(ids, strings.TrimSpace(id))
+			case json.Number:
+				ids = append(ids, id.String())
+			default:
+				return nil, fmt.Errorf("id %d is %v, expected a string or number", i+1, item)
+			}
+		}
+	} else {
+		lines := bufio.NewScanner(bytes.NewReader(data))
+		for lines.Scan() {
+			if line := strings.TrimSpace(lines.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				ids = append(ids, line)
+			}
+		}
+	}
+	seen := map[string]bool{}
+	out := []string{}
+	for _, id := range ids {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,43 @@
+package tfimport
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestReadIDs(t *testing.T) {
+	tests := map[string]struct {
+		Input         string
+		Expected      []string
+		ExpectedError string
+	}{
+		"It reads an id per line": {
+			Input:    "# failed on 2024-06-01\n12\n\n 34 \n12\n",
+			Expected: []string{"12", "34"},
+		},
+		"It reads a JSON array of numbers and strings": {
+			Input:    ` [12, "34", 9007199254740993]`,
+			Expected: []string{"12", "34", "9007199254740993"},
+		},
+		"It reads nothing from an empty file": {
+			Input:    "",
+			Expected: []string{},
+		},
+		"It rejects other JSON": {
+			Input:         `[{"id": 12}]`,
+			ExpectedError: "id 1 is map[id:12], expected a string or number",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ids, err := ReadIDs(strings.NewReader(test.Input))
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, ids)
+		})
+	}
+}
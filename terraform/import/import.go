@@ -1,56 +1,154 @@
 package tfimport
 
 import (
-	"bufio"
+	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"github.com/zclconf/go-cty/cty"
 	"io"
-	"regexp"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 )
 
-// compares incoming resources from remote to what is already defined in the main.tf
-// file to prevent duplicate definitions which breaks terraform import
-func FilterExistingDefinitions(f io.Reader, resources []tfimportables.ResourceDefinition) ([]tfimportables.ResourceDefinition, []string) {
-	resourceDefinitionsToImport := []tfimportables.ResourceDefinition{} // resource definitions not in HCL file that were included in incoming resources
-	unspecifiedProviders := []string{}                                  // providers not already in HCL file from which to import new resources
+// block headers that identify providers and resources already declared in configuration
+var definitionSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "resource", LabelNames: []string{"type", "name"}},
+		{Type: "data", LabelNames: []string{"type", "name"}},
+	},
+}
+
+// DefinitionHeaders is a running tab of provider, resource, and data source definitions in configuration
+// keyed by provider name and resource address respectively. Data sources are keyed by type and id and
+// aliased provider configurations by name and alias e.g. onelogin.emea
+type DefinitionHeaders struct {
+	Providers       map[string]int
+	Resources       map[string]int
+	DataSources     map[string]int
+	ProviderAliases map[string]int
+}
+
+func newDefinitionHeaders() DefinitionHeaders {
+	return DefinitionHeaders{Providers: map[string]int{}, Resources: map[string]int{}, DataSources: map[string]int{}, ProviderAliases: map[string]int{}}
+}
+
+// ReadDefinitionHeaders parses every .tf and .tf.json file in dir and tallies the providers and resources they declare
+func ReadDefinitionHeaders(dir string) (DefinitionHeaders, error) {
+	headers := newDefinitionHeaders()
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return headers, err
+	}
+	parser := hclparse.NewParser()
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		filename := filepath.Join(dir, entry.Name())
+		var (
+			file  *hcl.File
+			diags hcl.Diagnostics
+		)
+		switch {
+		case strings.HasSuffix(entry.Name(), ".tf.json"):
+			file, diags = parser.ParseJSONFile(filename)
+		case strings.HasSuffix(entry.Name(), ".tf"):
+			file, diags = parser.ParseHCLFile(filename)
+		default:
+			continue
+		}
+		if diags.HasErrors() {
+			return headers, diags
+		}
+		if err := headers.add(file); err != nil {
+			return headers, err
+		}
+	}
+	return headers, nil
+}
 
-	// resource definition headers in HCL file like resource onelogin_apps cool_app {}
-	searchCriteria := map[string]*regexp.Regexp{
-		"provider": regexp.MustCompile(`(\w*provider\w*)\s(([a-zA-Z\_]*))\s\{`),
-		"resource": regexp.MustCompile(`(\w*resource\w*)\s([a-zA-Z\_\-]*)\s([a-zA-Z\_\-]*[0-9]*)\s?\{`),
-	}
-
-	// running tab of provider and resource definitions in HCL file
-	definitionHeaderCounter := map[string]map[string]int{
-		"provider": map[string]int{},
-		"resource": map[string]int{},
-	}
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		t := scanner.Text()
-		for regexName, r := range searchCriteria {
-			definitionHeaderLine := r.FindStringSubmatch(t)
-			if len(definitionHeaderLine) > 0 {
-				var definitionKey string
-				if regexName == "provider" {
-					definitionKey = fmt.Sprintf("%s", definitionHeaderLine[len(definitionHeaderLine)-1])
-				}
-				if regexName == "resource" {
-					definitionKey = fmt.Sprintf("%s.%s", definitionHeaderLine[len(definitionHeaderLine)-2], definitionHeaderLine[len(definitionHeaderLine)-1])
-				}
-				definitionHeaderCounter[regexName][definitionKey]++
+// ParseDefinitionHeaders tallies the providers and resources declared in a single configuration file.
+// Files ending in .json are read as JSON configuration, anything else as native HCL
+func ParseDefinitionHeaders(filename string, src []byte) (DefinitionHeaders, error) {
+	headers := newDefinitionHeaders()
+	parser := hclparse.NewParser()
+	var (
+		file  *hcl.File
+		diags hcl.Diagnostics
+	)
+	if strings.HasSuffix(filename, ".json") {
+		file, diags = parser.ParseJSON(src, filename)
+	} else {
+		file, diags = parser.ParseHCL(src, filename)
+	}
+	if diags.HasErrors() {
+		return headers, diags
+	}
+	return headers, headers.add(file)
+}
+
+func (h DefinitionHeaders) add(file *hcl.File) error {
+	content, _, diags := file.Body.PartialContent(definitionSchema)
+	if diags.HasErrors() {
+		return diags
+	}
+	for _, block := range content.Blocks {
+		switch block.Type {
+		case "provider":
+			h.Providers[block.Labels[0]]++
+			if alias, ok := literalAttribute(block, "alias"); ok {
+				h.ProviderAliases[block.Labels[0]+"."+alias]++
+			}
+		case "resource":
+			h.Resources[fmt.Sprintf("%s.%s", block.Labels[0], block.Labels[1])]++
+		case "data":
+			if id, ok := literalAttribute(block, "id"); ok {
+				h.DataSources[fmt.Sprintf("%s.%s", block.Labels[0], id)]++
 			}
 		}
 	}
+	return nil
+}
+
+// literalAttribute reads a literal string attribute of a block, like the id a data block looks its resource up by
+func literalAttribute(block *hcl.Block, name string) (string, bool) {
+	attributes, _ := block.Body.JustAttributes()
+	attribute, ok := attributes[name]
+	if !ok {
+		return "", false
+	}
+	value, diags := attribute.Expr.Value(nil)
+	if diags.HasErrors() || value.IsNull() || !value.Type().Equals(cty.String) {
+		return "", false
+	}
+	return value.AsString(), true
+}
+
+// FilterExistingDefinitions compares incoming resources from remote to what is already defined in configuration
+// to prevent duplicate definitions which breaks terraform import
+func FilterExistingDefinitions(headers DefinitionHeaders, resources []tfimportables.ResourceDefinition) ([]tfimportables.ResourceDefinition, []string) {
+	resourceDefinitionsToImport := []tfimportables.ResourceDefinition{} // resource definitions not in HCL file that were included in incoming resources
+	unspecifiedProviders := []string{}                                  // providers not already in HCL file from which to import new resources
 
+	providers := map[string]int{}
+	for name, count := range headers.Providers {
+		providers[name] = count
+	}
 	for _, resourceDefinition := range resources {
-		if definitionHeaderCounter["provider"][resourceDefinition.Provider] == 0 {
-			definitionHeaderCounter["provider"][resourceDefinition.Provider]++
+		if providers[resourceDefinition.Provider] == 0 {
+			providers[resourceDefinition.Provider]++
 			unspecifiedProviders = append(unspecifiedProviders, resourceDefinition.Provider)
 		}
-		if definitionHeaderCounter["resource"][fmt.Sprintf("%s.%s", resourceDefinition.Type, resourceDefinition.Name)] == 0 {
+		if headers.Resources[fmt.Sprintf("%s.%s", resourceDefinition.Type, resourceDefinition.Name)] == 0 {
 			resourceDefinitionsToImport = append(resourceDefinitionsToImport, resourceDefinition)
 		}
 	}
@@ -58,19 +156,118 @@ func FilterExistingDefinitions(f io.Reader, resources []tfimportables.ResourceDe
 	return resourceDefinitionsToImport, unspecifiedProviders
 }
 
+// SplitDataSources separates the resources of the types to be written as data sources rather than imported, leaving out
+// data sources already declared in configuration
+func SplitDataSources(headers DefinitionHeaders, resources []tfimportables.ResourceDefinition, dataSourceTypes []string) ([]tfimportables.ResourceDefinition, []tfimportables.ResourceDefinition) {
+	isDataSource := map[string]bool{}
+	for _, dataSourceType := range dataSourceTypes {
+		isDataSource[dataSourceType] = true
+	}
+	toImport := []tfimportables.ResourceDefinition{}
+	dataSources := []tfimportables.ResourceDefinition{}
+	for _, resourceDefinition := range resources {
+		switch {
+		case !isDataSource[resourceDefinition.Type]:
+			toImport = append(toImport, resourceDefinition)
+		case headers.DataSources[fmt.Sprintf("%s.%s", resourceDefinition.Type, resourceDefinition.ImportID)] == 0:
+			dataSources = append(dataSources, resourceDefinition)
+		}
+	}
+	return toImport, dataSources
+}
+
 // WriteHCLDefinitionHeaders appends empty resource definitions to the existing main.tf file so terraform import will pick them up
 func WriteHCLDefinitionHeaders(resourceDefinitions []tfimportables.ResourceDefinition, providerDefinitions []string, planFile io.Writer) error {
-	var builder strings.Builder
+	return writeHeaders(resourceDefinitions, 0, providerDefinitions, planFile)
+}
+
+// writeHeaders writes the providers and the empty resource definitions, named as if the first resource was the
+// first-th imported
+func writeHeaders(resourceDefinitions []tfimportables.ResourceDefinition, first int, providerDefinitions []string, planFile io.Writer) error {
+	file := hclwrite.NewEmptyFile()
 	for _, newProvider := range providerDefinitions {
-		builder.WriteString(fmt.Sprintf("terraform {\n\trequired_providers {\n\t\t%s = {\n\t\t\tsource = \"%s/%s\"\n\t\t\t}\n\t\t}\n\t}\n\n", newProvider, newProvider, newProvider))
-		builder.WriteString(fmt.Sprintf("provider %s {\n\talias = \"%s\"\n}\n\n", newProvider, newProvider))
+		stateparser.AppendProviderBlocks(file.Body(), newProvider)
 	}
 	for i, resourceDefinition := range resourceDefinitions {
-		resourceDefinition.Name = fmt.Sprintf("_%s_%d", resourceDefinition.Name, i+1)
-		builder.WriteString(fmt.Sprintf("resource %s \"%s\" {}\n", resourceDefinition.Type, resourceDefinition.Name))
+		block := file.Body().AppendNewBlock("resource", []string{resourceDefinition.Type, ImportName(resourceDefinition, first+i)})
+		if resourceDefinition.ProviderAlias != "" {
+			block.Body().SetAttributeTraversal("provider", hcl.Traversal{
+				hcl.TraverseRoot{Name: resourceDefinition.Provider},
+				hcl.TraverseAttr{Name: resourceDefinition.ProviderAlias},
+			})
+		}
 	}
-	if _, err := planFile.Write([]byte(builder.String())); err != nil {
+	if _, err := planFile.Write(file.Bytes()); err != nil {
 		return err
 	}
 	return nil
 }
+
+// ImportName is the name given to the i-th imported resource in main.tf
+func ImportName(resourceDefinition tfimportables.ResourceDefinition, i int) string {
+	return fmt.Sprintf("_%s_%d", resourceDefinition.Name, i+1)
+}
+
+// ImportAddress is the terraform address of the i-th imported resource e.g. onelogin_apps._my_app_1
+func ImportAddress(resourceDefinition tfimportables.ResourceDefinition, i int) string {
+	return fmt.Sprintf("%s.%s", resourceDefinition.Type, ImportName(resourceDefinition, i))
+}
+
+// PlannedImport is one entry of the JSON import plan
+type PlannedImport struct {
+	Address  string `json:"address"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+}
+
+// WriteImportScript writes the terraform import commands for the resources instead of running them so they
+// can be reviewed and run elsewhere. Format is "sh" for a shell script, "ps1" for a PowerShell script or "json" for
+// a JSON plan
+func WriteImportScript(resourceDefinitions []tfimportables.ResourceDefinition, binary string, format string, w io.Writer) error {
+	switch format {
+	case "json":
+		plan := make([]PlannedImport, len(resourceDefinitions))
+		for i, resourceDefinition := range resourceDefinitions {
+			plan[i] = PlannedImport{
+				Address:  ImportAddress(resourceDefinition, i),
+				ID:       resourceDefinition.ImportID,
+				Type:     resourceDefinition.Type,
+				Provider: resourceDefinition.Provider,
+			}
+		}
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(out, '\n'))
+		return err
+	case "sh":
+		var builder strings.Builder
+		builder.WriteString("#!/usr/bin/env sh\nset -e\n\n")
+		for i, resourceDefinition := range resourceDefinitions {
+			builder.WriteString(fmt.Sprintf("%s import %s %s\n", binary, shellQuote(ImportAddress(resourceDefinition, i)), shellQuote(resourceDefinition.ImportID)))
+		}
+		_, err := w.Write([]byte(builder.String()))
+		return err
+	case "ps1":
+		var builder strings.Builder
+		builder.WriteString("$ErrorActionPreference = 'Stop'\r\n\r\n")
+		for i, resourceDefinition := range resourceDefinitions {
+			// a failing native command doesn't stop the script, its exit code has to be checked
+			builder.WriteString(fmt.Sprintf("& %s import %s %s\r\nif ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }\r\n", powerShellQuote(binary), powerShellQuote(ImportAddress(resourceDefinition, i)), powerShellQuote(resourceDefinition.ImportID)))
+		}
+		_, err := w.Write([]byte(builder.String()))
+		return err
+	default:
+		return fmt.Errorf("unsupported import script format %s", format)
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
+
+func powerShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -1,10 +1,13 @@
 package tfimport
 
 import (
+	"bytes"
 	"github.com/onelogin/onelogin/terraform/importables"
 	"github.com/stretchr/testify/assert"
 	"io"
-	"strings"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -24,28 +27,126 @@ func (m *MockFile) Read(p []byte) (int, error) {
 	return len(p), io.EOF
 }
 
+func TestParseDefinitionHeaders(t *testing.T) {
+	tests := map[string]struct {
+		Filename          string
+		Config            string
+		ExpectedProviders map[string]int
+		ExpectedResources map[string]int
+		ExpectedAliases   map[string]int
+		ExpectError       bool
+	}{
+		"it reads quoted and unquoted labels": {
+			Filename: "main.tf",
+			Config: `
+				resource onelogin_apps unquoted {}
+				resource "onelogin_apps" "quoted" {
+					name = "quoted"
+				}
+				resource "onelogin_roles" "multi_line" {
+					name = "multi"
+					apps = [
+						1,
+						2,
+					]
+				}
+				provider "onelogin" {
+					alias = "onelogin"
+				}
+			`,
+			ExpectedProviders: map[string]int{"onelogin": 1},
+			ExpectedResources: map[string]int{"onelogin_apps.unquoted": 1, "onelogin_apps.quoted": 1, "onelogin_roles.multi_line": 1},
+		},
+		"it ignores commented out resources": {
+			Filename: "main.tf",
+			Config: `
+				# resource "onelogin_apps" "hash" {}
+				// resource "onelogin_apps" "slashes" {}
+				/*
+				resource "onelogin_apps" "block" {}
+				*/
+				resource "onelogin_apps" "live" {}
+			`,
+			ExpectedProviders: map[string]int{},
+			ExpectedResources: map[string]int{"onelogin_apps.live": 1},
+		},
+		"it reads json configuration": {
+			Filename:          "main.tf.json",
+			Config:            `{"provider": {"onelogin": {"alias": "onelogin"}}, "resource": {"onelogin_apps": {"from_json": {"name": "json"}}}}`,
+			ExpectedProviders: map[string]int{"onelogin": 1},
+			ExpectedResources: map[string]int{"onelogin_apps.from_json": 1},
+		},
+		"it reads provider aliases": {
+			Filename: "main.tf",
+			Config: `
+				provider "onelogin" {
+					alias = "emea"
+				}
+				provider "onelogin" {}
+			`,
+			ExpectedProviders: map[string]int{"onelogin": 2},
+			ExpectedResources: map[string]int{},
+			ExpectedAliases:   map[string]int{"onelogin.emea": 1},
+		},
+		"it errors on invalid configuration": {
+			Filename:    "main.tf",
+			Config:      `resource "onelogin_apps" {`,
+			ExpectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			headers, err := ParseDefinitionHeaders(test.Filename, []byte(test.Config))
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedProviders, headers.Providers)
+			assert.Equal(t, test.ExpectedResources, headers.Resources)
+			if test.ExpectedAliases != nil {
+				assert.Equal(t, test.ExpectedAliases, headers.ProviderAliases)
+			}
+		})
+	}
+}
+
+func TestReadDefinitionHeaders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfimport")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "onelogin_apps" "in_main" {}`), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "roles.tf"), []byte(`resource "onelogin_roles" "in_roles" {}`), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "users.tf.json"), []byte(`{"resource": {"onelogin_users": {"in_json": {}}}}`), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`resource "onelogin_apps" "not_config" {}`), 0600)
+
+	headers, err := ReadDefinitionHeaders(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"onelogin_apps.in_main": 1, "onelogin_roles.in_roles": 1, "onelogin_users.in_json": 1}, headers.Resources)
+}
+
 func TestFilterExistingDefinitions(t *testing.T) {
 	tests := map[string]struct {
-		InputReadWriter             io.Reader
+		Config                      string
 		IncomingResourceDefinitions []tfimportables.ResourceDefinition
 		ExpectedResourceDefinitions []tfimportables.ResourceDefinition
 		ExpectedProviders           []string
 	}{
 		"it yields lists of resource definitions and providers not already defined in main.tf": {
-			InputReadWriter: strings.NewReader(`
+			Config: `
 				resource onelogin_apps defined_in_main_already {
-					name = defined_in_main_already
+					name = "defined_in_main_already"
 				}
-				resource okra_saml_apps test_defined_already {
-					name = test_defined_already
+				resource "okra_saml_apps" "test_defined_already" {
+					name = "test_defined_already"
 				}
 				provider onelogin {
-					alias "onelogin"
+					alias = "onelogin"
 				}
-				provider okra {
-					alias "okra"
+				provider "okra" {
+					alias = "okra"
 				}
-			`),
+			`,
 			IncomingResourceDefinitions: []tfimportables.ResourceDefinition{
 				tfimportables.ResourceDefinition{Provider: "onelogin", Name: "defined_in_main_already", Type: "onelogin_apps"},
 				tfimportables.ResourceDefinition{Provider: "okra", Name: "test_defined_already", Type: "okra_saml_apps"},
@@ -65,13 +166,32 @@ func TestFilterExistingDefinitions(t *testing.T) {
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			actualResourceDefinitions, actualProviderDefinitions := FilterExistingDefinitions(test.InputReadWriter, test.IncomingResourceDefinitions)
+			headers, err := ParseDefinitionHeaders("main.tf", []byte(test.Config))
+			assert.Nil(t, err)
+			actualResourceDefinitions, actualProviderDefinitions := FilterExistingDefinitions(headers, test.IncomingResourceDefinitions)
 			assert.Equal(t, test.ExpectedResourceDefinitions, actualResourceDefinitions)
 			assert.Equal(t, test.ExpectedProviders, actualProviderDefinitions)
 		})
 	}
 }
 
+func TestSplitDataSources(t *testing.T) {
+	headers, err := ParseDefinitionHeaders("main.tf", []byte(`
+		data "onelogin_roles" "declared" {
+			id = "2"
+		}
+	`))
+	assert.Nil(t, err)
+	incoming := []tfimportables.ResourceDefinition{
+		tfimportables.ResourceDefinition{Provider: "onelogin", Name: "wiki", Type: "onelogin_apps", ImportID: "1"},
+		tfimportables.ResourceDefinition{Provider: "onelogin", Name: "declared", Type: "onelogin_roles", ImportID: "2"},
+		tfimportables.ResourceDefinition{Provider: "onelogin", Name: "engineering", Type: "onelogin_roles", ImportID: "3"},
+	}
+	toImport, dataSources := SplitDataSources(headers, incoming, []string{"onelogin_roles"})
+	assert.Equal(t, []tfimportables.ResourceDefinition{incoming[0]}, toImport)
+	assert.Equal(t, []tfimportables.ResourceDefinition{incoming[2]}, dataSources)
+}
+
 func TestAppendDefinitionsToMainTF(t *testing.T) {
 	tests := map[string]struct {
 		TestFile                 MockFile
@@ -86,7 +206,14 @@ func TestAppendDefinitionsToMainTF(t *testing.T) {
 			},
 			TestFile:                 MockFile{},
 			InputProviderDefinitions: []string{"test", "test2"},
-			ExpectedOut:              []byte("provider test {\n\talias = \"test\"\n}\n\nprovider test2 {\n\talias = \"test2\"\n}\n\nresource test test {}\nresource test test {}\n"),
+			ExpectedOut:              []byte("terraform {\n  required_providers {\n    test = {\n      source = \"test/test\"\n    }\n  }\n}\n\nprovider \"test\" {\n  alias = \"test\"\n}\n\nterraform {\n  required_providers {\n    test2 = {\n      source = \"test2/test2\"\n    }\n  }\n}\n\nprovider \"test2\" {\n  alias = \"test2\"\n}\n\nresource \"test\" \"_test_1\" {\n}\nresource \"test\" \"_test_2\" {\n}\n"),
+		},
+		"it assigns resources to their provider alias": {
+			InputResourceDefinitions: []tfimportables.ResourceDefinition{
+				tfimportables.ResourceDefinition{Name: "emea_test", Type: "onelogin_apps", ImportID: "1", Provider: "onelogin", ProviderAlias: "emea"},
+			},
+			TestFile:    MockFile{},
+			ExpectedOut: []byte("resource \"onelogin_apps\" \"_emea_test_1\" {\n  provider = onelogin.emea\n}\n"),
 		},
 	}
 	for name, test := range tests {
@@ -98,3 +225,44 @@ func TestAppendDefinitionsToMainTF(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteImportScript(t *testing.T) {
+	resourceDefinitions := []tfimportables.ResourceDefinition{
+		tfimportables.ResourceDefinition{Name: "my_app", Type: "onelogin_apps", ImportID: "12", Provider: "onelogin"},
+		tfimportables.ResourceDefinition{Name: "bob", Type: "aws_iam_user", ImportID: "bob's", Provider: "aws"},
+	}
+	tests := map[string]struct {
+		Format      string
+		ExpectedOut string
+		ExpectError bool
+	}{
+		"it writes a shell script": {
+			Format:      "sh",
+			ExpectedOut: "#!/usr/bin/env sh\nset -e\n\nterraform import 'onelogin_apps._my_app_1' '12'\nterraform import 'aws_iam_user._bob_2' 'bob'\"'\"'s'\n",
+		},
+		"it writes a powershell script": {
+			Format:      "ps1",
+			ExpectedOut: "$ErrorActionPreference = 'Stop'\r\n\r\n& 'terraform' import 'onelogin_apps._my_app_1' '12'\r\nif ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }\r\n& 'terraform' import 'aws_iam_user._bob_2' 'bob''s'\r\nif ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }\r\n",
+		},
+		"it writes a json plan": {
+			Format:      "json",
+			ExpectedOut: "[\n  {\n    \"address\": \"onelogin_apps._my_app_1\",\n    \"id\": \"12\",\n    \"type\": \"onelogin_apps\",\n    \"provider\": \"onelogin\"\n  },\n  {\n    \"address\": \"aws_iam_user._bob_2\",\n    \"id\": \"bob's\",\n    \"type\": \"aws_iam_user\",\n    \"provider\": \"aws\"\n  }\n]\n",
+		},
+		"it rejects unknown formats": {
+			Format:      "yaml",
+			ExpectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := WriteImportScript(resourceDefinitions, "terraform", test.Format, &out)
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOut, out.String())
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package tfimport
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"io"
+	"strings"
+)
+
+// resource types whose pulumi provider doesn't follow the bridged naming
+var pulumiTypes = map[string]string{
+	"aws_iam_user": "aws:iam/user:User",
+}
+
+// PulumiManifest is the bulk import file read by `pulumi import --file`
+type PulumiManifest struct {
+	Resources []PulumiResource `json:"resources"`
+}
+
+// PulumiResource is one resource to import with pulumi
+type PulumiResource struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+	ID   string `json:"id"`
+}
+
+// PulumiType is the pulumi type token of a terraform resource type as a bridged provider names it,
+// e.g. onelogin:index/apps:Apps for onelogin_apps
+func PulumiType(resourceType string) string {
+	if pulumiType, ok := pulumiTypes[resourceType]; ok {
+		return pulumiType
+	}
+	parts := strings.SplitN(resourceType, "_", 2)
+	if len(parts) < 2 || parts[0] == "" {
+		return resourceType
+	}
+	words := strings.Split(parts[1], "_")
+	for i, word := range words {
+		if word != "" {
+			words[i] = strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	name := strings.Join(words, "")
+	if name == "" {
+		return resourceType // nothing after the provider to name the resource by e.g. onelogin_
+	}
+	return fmt.Sprintf("%s:index/%s:%s", parts[0], strings.ToLower(name[:1])+name[1:], name)
+}
+
+// WritePulumiManifest writes the resources as a `pulumi import --file` bulk import file. Resources are
+// named the same way terraform import names them
+func WritePulumiManifest(resourceDefinitions []tfimportables.ResourceDefinition, w io.Writer) error {
+	manifest := PulumiManifest{Resources: make([]PulumiResource, len(resourceDefinitions))}
+	for i, resourceDefinition := range resourceDefinitions {
+		manifest.Resources[i] = PulumiResource{
+			Type: PulumiType(resourceDefinition.Type),
+			Name: ImportName(resourceDefinition, i),
+			ID:   resourceDefinition.ImportID,
+		}
+	}
+	out, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
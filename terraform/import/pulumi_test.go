@@ -0,0 +1,52 @@
+package tfimport
+
+import (
+	"bytes"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPulumiType(t *testing.T) {
+	tests := map[string]struct {
+		ResourceType string
+		Expected     string
+	}{
+		"it names bridged resources":             {ResourceType: "onelogin_apps", Expected: "onelogin:index/apps:Apps"},
+		"it joins words of the resource name":    {ResourceType: "onelogin_user_mappings", Expected: "onelogin:index/userMappings:UserMappings"},
+		"it uses the names of the aws provider":  {ResourceType: "aws_iam_user", Expected: "aws:iam/user:User"},
+		"it keeps types without a resource name": {ResourceType: "onelogin_", Expected: "onelogin_"},
+		"it keeps types of only underscores":     {ResourceType: "onelogin__", Expected: "onelogin__"},
+		"it keeps types without a provider":      {ResourceType: "_apps", Expected: "_apps"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, PulumiType(test.ResourceType))
+		})
+	}
+}
+
+func TestWritePulumiManifest(t *testing.T) {
+	resourceDefinitions := []tfimportables.ResourceDefinition{
+		tfimportables.ResourceDefinition{Name: "my_app", Type: "onelogin_apps", ImportID: "12", Provider: "onelogin"},
+		tfimportables.ResourceDefinition{Name: "bob", Type: "aws_iam_user", ImportID: "bob", Provider: "aws"},
+	}
+	var out bytes.Buffer
+	err := WritePulumiManifest(resourceDefinitions, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, `{
+  "resources": [
+    {
+      "type": "onelogin:index/apps:Apps",
+      "name": "_my_app_1",
+      "id": "12"
+    },
+    {
+      "type": "aws:iam/user:User",
+      "name": "_bob_2",
+      "id": "bob"
+    }
+  ]
+}
+`, out.String())
+}
@@ -0,0 +1,133 @@
+package tfimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"io"
+	"sort"
+	"time"
+)
+
+// results of an import
+const (
+	Succeeded = "succeeded"
+	Partial   = "partial"
+	Failed    = "failed"
+)
+
+// Summary is the outcome of an import, for a report to attach to the change that adds the imported configuration
+type Summary struct {
+	Resource    string        `json:"resource"` // the resource type asked for e.g. onelogin_apps
+	Result      string        `json:"result"`
+	Elapsed     time.Duration `json:"-"`
+	APIRequests int64         `json:"api_requests"`
+	Types       []TypeSummary `json:"types"`
+	Skipped     []string      `json:"skipped"` // addresses of resources already in configuration
+	Failures    []Failure     `json:"failures"`
+}
+
+// TypeSummary counts the resources of a type
+type TypeSummary struct {
+	Type        string `json:"type"`
+	Fetched     int    `json:"fetched"`
+	Imported    int    `json:"imported"`
+	Skipped     int    `json:"skipped"`
+	DataSources int    `json:"data_sources"`
+}
+
+// Failure is why a resource, or the whole import when there's no address, failed
+type Failure struct {
+	Address string `json:"address,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// NewSummary counts the resources fetched from the remote by type, and those skipped because configuration already
+// has them. toImport are the fetched resources that weren't skipped, and dataSources those declared as data sources
+func NewSummary(resource string, fetched []tfimportables.ResourceDefinition, toImport []tfimportables.ResourceDefinition, dataSources []tfimportables.ResourceDefinition) Summary {
+	summary := Summary{Resource: resource, Types: []TypeSummary{}, Skipped: []string{}, Failures: []Failure{}}
+	importing := map[string]bool{}
+	for _, resourceDefinition := range toImport {
+		importing[resourceDefinition.Type+"."+resourceDefinition.Name] = true
+	}
+	for _, resourceDefinition := range fetched {
+		counts := summary.typeSummary(resourceDefinition.Type)
+		counts.Fetched++
+		if address := resourceDefinition.Type + "." + resourceDefinition.Name; !importing[address] {
+			counts.Skipped++
+			summary.Skipped = append(summary.Skipped, address)
+		}
+	}
+	for _, resourceDefinition := range dataSources {
+		counts := summary.typeSummary(resourceDefinition.Type)
+		counts.Fetched++
+		counts.DataSources++
+	}
+	sort.Slice(summary.Types, func(i, j int) bool { return summary.Types[i].Type < summary.Types[j].Type })
+	return summary
+}
+
+// Imported counts a resource of the type as imported
+func (s *Summary) Imported(resourceType string) {
+	s.typeSummary(resourceType).Imported++
+}
+
+// Fail records why the resource at the address failed, or the import when the address is empty
+func (s *Summary) Fail(address string, err error) {
+	s.Failures = append(s.Failures, Failure{Address: address, Reason: err.Error()})
+}
+
+func (s *Summary) typeSummary(resourceType string) *TypeSummary {
+	for i := range s.Types {
+		if s.Types[i].Type == resourceType {
+			return &s.Types[i]
+		}
+	}
+	s.Types = append(s.Types, TypeSummary{Type: resourceType})
+	return &s.Types[len(s.Types)-1]
+}
+
+// WriteJSON writes the summary as an indented JSON object, with the elapsed time in seconds
+func (s Summary) WriteJSON(w io.Writer) error {
+	out, err := json.MarshalIndent(struct {
+		Summary
+		ElapsedSeconds float64 `json:"elapsed_seconds"`
+	}{s, s.Elapsed.Seconds()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
+
+// WriteMarkdown writes the summary as Markdown, with a table of the counts by type then the resources skipped and
+// the failures
+func (s Summary) WriteMarkdown(w io.Writer) error {
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "# Import of %s\n\n", s.Resource)
+	fmt.Fprintf(&out, "**Result:** %s in %s, **API requests:** %d\n\n", s.Result, s.Elapsed.Round(100*time.Millisecond), s.APIRequests)
+	fmt.Fprintln(&out, "| Type | Fetched | Imported | Skipped | Data sources |")
+	fmt.Fprintln(&out, "|------|--------:|---------:|--------:|-------------:|")
+	for _, counts := range s.Types {
+		fmt.Fprintf(&out, "| %s | %d | %d | %d | %d |\n", counts.Type, counts.Fetched, counts.Imported, counts.Skipped, counts.DataSources)
+	}
+	if len(s.Skipped) > 0 {
+		fmt.Fprintf(&out, "\n## Skipped\n\nAlready in configuration:\n\n")
+		for _, address := range s.Skipped {
+			fmt.Fprintf(&out, "- `%s`\n", address)
+		}
+	}
+	if len(s.Failures) > 0 {
+		fmt.Fprintf(&out, "\n## Failures\n\n")
+		for _, failure := range s.Failures {
+			if failure.Address == "" {
+				fmt.Fprintf(&out, "- %s\n", failure.Reason)
+				continue
+			}
+			fmt.Fprintf(&out, "- `%s`: %s\n", failure.Address, failure.Reason)
+		}
+	}
+	_, err := w.Write(out.Bytes())
+	return err
+}
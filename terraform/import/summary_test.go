@@ -0,0 +1,64 @@
+package tfimport
+
+import (
+	"bytes"
+	"errors"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewSummary(t *testing.T) {
+	fetched := []tfimportables.ResourceDefinition{
+		{Type: "onelogin_saml_apps", Name: "slack"},
+		{Type: "onelogin_saml_apps", Name: "zoom"},
+		{Type: "onelogin_oidc_apps", Name: "portal"},
+	}
+	toImport := []tfimportables.ResourceDefinition{fetched[1], fetched[2]}
+	dataSources := []tfimportables.ResourceDefinition{{Type: "onelogin_roles", Name: "admins"}}
+
+	summary := NewSummary("onelogin_apps", fetched, toImport, dataSources)
+	summary.Imported("onelogin_saml_apps")
+	summary.Fail("onelogin_oidc_apps._portal_2", errors.New("terraform import: exit status 1"))
+
+	assert.Equal(t, "onelogin_apps", summary.Resource)
+	assert.Equal(t, []TypeSummary{
+		{Type: "onelogin_oidc_apps", Fetched: 1},
+		{Type: "onelogin_roles", Fetched: 1, DataSources: 1},
+		{Type: "onelogin_saml_apps", Fetched: 2, Imported: 1, Skipped: 1},
+	}, summary.Types)
+	assert.Equal(t, []string{"onelogin_saml_apps.slack"}, summary.Skipped)
+	assert.Equal(t, []Failure{{Address: "onelogin_oidc_apps._portal_2", Reason: "terraform import: exit status 1"}}, summary.Failures)
+}
+
+func TestWriteSummary(t *testing.T) {
+	summary := Summary{
+		Resource:    "onelogin_roles",
+		Result:      Partial,
+		Elapsed:     1500 * time.Millisecond,
+		APIRequests: 4,
+		Types:       []TypeSummary{{Type: "onelogin_roles", Fetched: 3, Imported: 1, Skipped: 1}},
+		Skipped:     []string{"onelogin_roles.admins"},
+		Failures:    []Failure{{Address: "onelogin_roles._eng_2", Reason: "rate limited"}, {Reason: "stopped"}},
+	}
+
+	var out bytes.Buffer
+	assert.Nil(t, summary.WriteMarkdown(&out))
+	assert.Equal(t, "# Import of onelogin_roles\n\n"+
+		"**Result:** partial in 1.5s, **API requests:** 4\n\n"+
+		"| Type | Fetched | Imported | Skipped | Data sources |\n"+
+		"|------|--------:|---------:|--------:|-------------:|\n"+
+		"| onelogin_roles | 3 | 1 | 1 | 0 |\n\n"+
+		"## Skipped\n\nAlready in configuration:\n\n"+
+		"- `onelogin_roles.admins`\n\n"+
+		"## Failures\n\n"+
+		"- `onelogin_roles._eng_2`: rate limited\n"+
+		"- stopped\n", out.String())
+
+	out.Reset()
+	assert.Nil(t, summary.WriteJSON(&out))
+	assert.Contains(t, out.String(), `"result": "partial"`)
+	assert.Contains(t, out.String(), `"elapsed_seconds": 1.5`)
+	assert.Contains(t, out.String(), `"api_requests": 4`)
+}
@@ -0,0 +1,90 @@
+package tfimport
+
+import (
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/zclconf/go-cty/cty"
+	"io"
+)
+
+// Tenant is one of several OneLogin accounts imported into the same workspace. Its resources are managed through a
+// onelogin provider configuration aliased by the tenant's name, which reads the credentials from variables so they
+// never end up in configuration
+type Tenant struct {
+	Alias        string // alias of the provider configuration and prefix of resource names e.g. emea
+	URL          string
+	ClientID     string
+	ClientSecret string
+}
+
+// NewTenant checks the alias can be used in configuration
+func NewTenant(alias string, url string, clientID string, clientSecret string) (Tenant, error) {
+	if !hclsyntax.ValidIdentifier(alias) {
+		return Tenant{}, fmt.Errorf("%s can't be used as a provider alias, use letters, digits, underscores and dashes", alias)
+	}
+	return Tenant{Alias: alias, URL: url, ClientID: clientID, ClientSecret: clientSecret}, nil
+}
+
+// ClientIDVariable is the variable the tenant's provider configuration reads the client id from
+func (t Tenant) ClientIDVariable() string {
+	return fmt.Sprintf("onelogin_%s_client_id", t.Alias)
+}
+
+// ClientSecretVariable is the variable the tenant's provider configuration reads the client secret from
+func (t Tenant) ClientSecretVariable() string {
+	return fmt.Sprintf("onelogin_%s_client_secret", t.Alias)
+}
+
+// Env sets the tenant's credential variables for terraform
+func (t Tenant) Env() []string {
+	return []string{
+		fmt.Sprintf("TF_VAR_%s=%s", t.ClientIDVariable(), t.ClientID),
+		fmt.Sprintf("TF_VAR_%s=%s", t.ClientSecretVariable(), t.ClientSecret),
+	}
+}
+
+// Tag assigns the resources to the tenant's provider configuration, prefixing their names with the alias so the
+// same resource name in two tenants doesn't collide
+func (t Tenant) Tag(resources []tfimportables.ResourceDefinition) []tfimportables.ResourceDefinition {
+	out := make([]tfimportables.ResourceDefinition, len(resources))
+	for i, resource := range resources {
+		resource.Name = t.Alias + "_" + resource.Name
+		resource.ProviderAlias = t.Alias
+		out[i] = resource
+	}
+	return out
+}
+
+// WriteTenantProviders writes the aliased provider configuration and credential variables of the tenants that
+// aren't configured yet
+func WriteTenantProviders(headers DefinitionHeaders, tenants []Tenant, w io.Writer) error {
+	file := hclwrite.NewEmptyFile()
+	for _, tenant := range tenants {
+		if headers.ProviderAliases["onelogin."+tenant.Alias] > 0 {
+			continue
+		}
+		provider := file.Body().AppendNewBlock("provider", []string{"onelogin"}).Body()
+		provider.SetAttributeValue("alias", cty.StringVal(tenant.Alias))
+		provider.SetAttributeValue("url", cty.StringVal(tenant.URL))
+		provider.SetAttributeTraversal("client_id", variableReference(tenant.ClientIDVariable()))
+		provider.SetAttributeTraversal("client_secret", variableReference(tenant.ClientSecretVariable()))
+		file.Body().AppendNewline()
+		for _, name := range []string{tenant.ClientIDVariable(), tenant.ClientSecretVariable()} {
+			variable := file.Body().AppendNewBlock("variable", []string{name}).Body()
+			variable.SetAttributeTraversal("type", hcl.Traversal{hcl.TraverseRoot{Name: "string"}})
+			if name == tenant.ClientSecretVariable() {
+				variable.SetAttributeValue("sensitive", cty.True)
+			}
+			file.Body().AppendNewline()
+		}
+	}
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+func variableReference(name string) hcl.Traversal {
+	return hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: name}}
+}
@@ -0,0 +1,72 @@
+package tfimport
+
+import (
+	"bytes"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNewTenant(t *testing.T) {
+	tests := map[string]struct {
+		Alias         string
+		ExpectedError string
+	}{
+		"It accepts identifiers":         {Alias: "emea-prod_2"},
+		"It rejects names with spaces":   {Alias: "emea prod", ExpectedError: "emea prod can't be used as a provider alias, use letters, digits, underscores and dashes"},
+		"It rejects names with a number": {Alias: "2emea", ExpectedError: "2emea can't be used as a provider alias, use letters, digits, underscores and dashes"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewTenant(test.Alias, "https://api.eu.onelogin.com", "id", "secret")
+			if test.ExpectedError == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.EqualError(t, err, test.ExpectedError)
+			}
+		})
+	}
+}
+
+func TestTenantTag(t *testing.T) {
+	tenant := Tenant{Alias: "emea"}
+	resources := []tfimportables.ResourceDefinition{{Provider: "onelogin", Type: "onelogin_apps", Name: "wiki", ImportID: "1"}}
+	assert.Equal(t, []tfimportables.ResourceDefinition{
+		{Provider: "onelogin", Type: "onelogin_apps", Name: "emea_wiki", ImportID: "1", ProviderAlias: "emea"},
+	}, tenant.Tag(resources))
+	assert.Equal(t, "wiki", resources[0].Name) // the input is left as it was
+}
+
+func TestTenantEnv(t *testing.T) {
+	tenant := Tenant{Alias: "emea", ClientID: "id", ClientSecret: "secret"}
+	assert.Equal(t, []string{"TF_VAR_onelogin_emea_client_id=id", "TF_VAR_onelogin_emea_client_secret=secret"}, tenant.Env())
+}
+
+func TestWriteTenantProviders(t *testing.T) {
+	tenants := []Tenant{
+		{Alias: "prod", URL: "https://api.us.onelogin.com"},
+		{Alias: "emea", URL: "https://api.eu.onelogin.com"},
+	}
+	headers := newDefinitionHeaders()
+	headers.ProviderAliases["onelogin.prod"] = 1
+
+	var out bytes.Buffer
+	assert.Nil(t, WriteTenantProviders(headers, tenants, &out))
+	assert.Equal(t, `provider "onelogin" {
+  alias         = "emea"
+  url           = "https://api.eu.onelogin.com"
+  client_id     = var.onelogin_emea_client_id
+  client_secret = var.onelogin_emea_client_secret
+}
+
+variable "onelogin_emea_client_id" {
+  type = string
+}
+
+variable "onelogin_emea_client_secret" {
+  type      = string
+  sensitive = true
+}
+
+`, out.String())
+}
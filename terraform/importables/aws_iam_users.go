@@ -1,8 +1,8 @@
 package tfimportables
 
 import (
+	"fmt"
 	"github.com/aws/aws-sdk-go/service/iam"
-	"log"
 )
 
 type AWSUserQuerier interface {
@@ -15,10 +15,10 @@ type AWSUsersImportable struct {
 
 // Interface requirement to be an Importable. Calls out to remote (aws api) and
 // creates their Terraform ResourceDefinitions
-func (i AWSUsersImportable) ImportFromRemote(searchId *string) []ResourceDefinition {
+func (i AWSUsersImportable) ImportFromRemote(searchId *string) ([]ResourceDefinition, error) {
 	usrs, err := i.Service.ListUsers(&iam.ListUsersInput{})
 	if err != nil {
-		log.Fatalln("There was a problem getting users", err)
+		return nil, fmt.Errorf("there was a problem getting users: %s", err)
 	}
 	out := make([]ResourceDefinition, len(usrs.Users))
 	for i, u := range usrs.Users {
@@ -27,9 +27,10 @@ func (i AWSUsersImportable) ImportFromRemote(searchId *string) []ResourceDefinit
 			Type:     "aws_iam_user",
 			Name:     *u.UserName,
 			ImportID: *u.UserName,
+			Remote:   *u,
 		}
 	}
-	return out
+	return out, nil
 }
 
 func (i AWSUsersImportable) HCLShape() interface{} {
@@ -26,14 +26,15 @@ func TestImportAWSUserFromRemote(t *testing.T) {
 		"It pulls all users": {
 			Importable: AWSUsersImportable{Service: MockAWSUsersService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "aws", Name: "test_1", ImportID: "test_1", Type: "aws_iam_user"},
-				ResourceDefinition{Provider: "aws", Name: "test_2", ImportID: "test_2", Type: "aws_iam_user"},
+				ResourceDefinition{Provider: "aws", Name: "test_1", ImportID: "test_1", Type: "aws_iam_user", Remote: iam.User{UserName: oltypes.String("test_1"), Path: oltypes.String("/"), UserId: oltypes.String("1")}},
+				ResourceDefinition{Provider: "aws", Name: "test_2", ImportID: "test_2", Type: "aws_iam_user", Remote: iam.User{UserName: oltypes.String("test_2"), Path: oltypes.String("/"), UserId: oltypes.String("2")}},
 			},
 		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			actual := test.Importable.ImportFromRemote(nil)
+			actual, err := test.Importable.ImportFromRemote(nil)
+			assert.Nil(t, err)
 			assert.Equal(t, test.Expected, actual)
 		})
 	}
@@ -0,0 +1,35 @@
+package tfimportables
+
+import (
+	"context"
+	"fmt"
+)
+
+// ImportFromRemoteContext runs the importable's ImportFromRemote, returning as soon as ctx is done. The clients abort
+// their requests in flight with the same context, this makes sure a fetch that hasn't noticed yet doesn't hold up the caller
+func ImportFromRemoteContext(ctx context.Context, importable Importable, searchId *string) ([]ResourceDefinition, error) {
+	type result struct {
+		definitions []ResourceDefinition
+		err         error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			// the OneLogin SDK panics when a page after the first fails, which cancelling makes likely
+			if r := recover(); r != nil {
+				done <- result{err: fmt.Errorf("request to the remote failed: %v", r)}
+			}
+		}()
+		definitions, err := importable.ImportFromRemote(searchId)
+		done <- result{definitions: definitions, err: err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("stopped collecting resources: %s", ctx.Err())
+	case r := <-done:
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("stopped collecting resources: %s", ctx.Err()) // the error was caused by cancelling
+		}
+		return r.definitions, r.err
+	}
+}
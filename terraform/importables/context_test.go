@@ -0,0 +1,68 @@
+package tfimportables
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// MockImportable calls fetch for its remote resources
+type MockImportable struct {
+	fetch func() ([]ResourceDefinition, error)
+}
+
+func (i MockImportable) ImportFromRemote(searchId *string) ([]ResourceDefinition, error) {
+	return i.fetch()
+}
+
+func (i MockImportable) HCLShape() interface{} {
+	return nil
+}
+
+func TestImportFromRemoteContext(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	tests := map[string]struct {
+		Cancelled     bool
+		Fetch         func() ([]ResourceDefinition, error)
+		Expected      []ResourceDefinition
+		ExpectedError string
+	}{
+		"It returns what the importable fetched": {
+			Fetch:    func() ([]ResourceDefinition, error) { return []ResourceDefinition{{Name: "test"}}, nil },
+			Expected: []ResourceDefinition{{Name: "test"}},
+		},
+		"It returns the importable's errors": {
+			Fetch:         func() ([]ResourceDefinition, error) { return nil, errors.New("unable to get users") },
+			ExpectedError: "unable to get users",
+		},
+		"It recovers from panics while paging": {
+			Fetch: func() ([]ResourceDefinition, error) {
+				panic("runtime error: invalid memory address or nil pointer dereference")
+			},
+			ExpectedError: "request to the remote failed: runtime error: invalid memory address or nil pointer dereference",
+		},
+		"It stops waiting once the context is done": {
+			Cancelled:     true,
+			Fetch:         func() ([]ResourceDefinition, error) { <-block; return nil, nil },
+			ExpectedError: "stopped collecting resources: context canceled",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if test.Cancelled {
+				cancel()
+			}
+			defer cancel()
+			definitions, err := ImportFromRemoteContext(ctx, MockImportable{fetch: test.Fetch}, nil)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, definitions)
+		})
+	}
+}
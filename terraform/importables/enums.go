@@ -0,0 +1,26 @@
+package tfimportables
+
+// enums is the registry of attributes the API only accepts some values for, keyed by resource type then attribute
+// path. The provider schema doesn't list them, so resources are checked against these before importing
+var enums = map[string]map[string][]string{
+	"onelogin_apps":      appEnums,
+	"onelogin_saml_apps": appEnums,
+	"onelogin_oidc_apps": appEnums,
+	"onelogin_users": {
+		"state":  {"0", "1", "2", "3"},
+		"status": {"0", "1", "2", "3", "4", "5", "7", "8"},
+	},
+}
+
+// appEnums are shared by the resource types apps are imported as
+var appEnums = map[string][]string{
+	"configuration.oidc_application_type":      {"0", "1"},
+	"configuration.token_endpoint_auth_method": {"0", "1", "2"},
+	"configuration.signature_algorithm":        {"SHA-1", "SHA-256", "SHA-348", "SHA-512"},
+}
+
+// Enums returns the values allowed for attributes of the resource type by attribute path e.g.
+// configuration.token_endpoint_auth_method
+func Enums(resourceType string) map[string][]string {
+	return enums[resourceType]
+}
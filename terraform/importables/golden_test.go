@@ -0,0 +1,40 @@
+package tfimportables_test
+
+import (
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/importables/importabletest"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+var goldenCases = map[string]importabletest.Case{
+	"roles":         {Type: "onelogin_roles"},
+	"one_role":      {Type: "onelogin_roles", SearchID: "2"},
+	"users":         {Type: "onelogin_users"},
+	"apps":          {Type: "onelogin_apps"},
+	"saml_apps":     {Type: "onelogin_saml_apps"},
+	"oidc_apps":     {Type: "onelogin_oidc_apps"},
+	"user_mappings": {Type: "onelogin_user_mappings"},
+	"aws_iam_users": {Type: "aws_iam_user"},
+}
+
+func TestGolden(t *testing.T) {
+	for name, test := range goldenCases {
+		t.Run(name, func(t *testing.T) {
+			test.Fixtures = filepath.Join("testdata", test.Type)
+			test.Golden = filepath.Join("testdata", name+".tf")
+			importabletest.Run(t, test)
+		})
+	}
+}
+
+func TestGoldenCoversEveryImportable(t *testing.T) {
+	covered := map[string]bool{}
+	for _, test := range goldenCases {
+		covered[test.Type] = true
+	}
+	for _, name := range tfimportables.Names() {
+		assert.True(t, covered[name], "%s has no golden test case", name)
+	}
+}
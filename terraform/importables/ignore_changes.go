@@ -0,0 +1,18 @@
+package tfimportables
+
+// ignoreChanges is the registry of attributes that drift on their own e.g. timestamps the remote updates, keyed by resource type.
+// Generated resources ignore changes to them so plans stay quiet
+var ignoreChanges = map[string][]string{
+	"onelogin_apps":      appIgnoreChanges,
+	"onelogin_saml_apps": appIgnoreChanges,
+	"onelogin_oidc_apps": appIgnoreChanges,
+	"onelogin_users":     {"last_login", "invalid_login_attempts", "password_changed_at", "locked_until"},
+}
+
+// appIgnoreChanges are shared by the resource types apps are imported as
+var appIgnoreChanges = []string{"updated_at"}
+
+// IgnoreChanges returns the attributes of the resource type that always drift
+func IgnoreChanges(resourceType string) []string {
+	return ignoreChanges[resourceType]
+}
@@ -2,15 +2,15 @@
 // This module creates a list of importable instances so each can be instantiated once and shared among other callers.
 //
 // Adding importables
-// Add a case to the switch statement where the key is the name of the resource as it should be represented in terraform
-// then call the requisite client method if needed. Be sure the client exists in the clients package to avoid comiler errors.
-// Finally, add the importable to the map of importables so it can be fetched by referencing the terraform name via the terraform naming convention
+// Add an entry to the registry where the key is the name of the resource as it should be represented in terraform
+// with the zero value of the importable and a function creating it, calling the requisite client method if needed. Be sure the client exists in the clients package to avoid comiler errors.
+// The importable is created once and can then be fetched by referencing the terraform name via the terraform naming convention
 package tfimportables
 
 import (
+	"fmt"
 	"github.com/onelogin/onelogin/clients"
-
-	"log"
+	"sort"
 )
 
 // ImportableList is the list of created importables referenced by a map where the key is the name used to identify it in terraform
@@ -26,27 +26,116 @@ func New(clients *clients.Clients) *ImportableList {
 	return &imf
 }
 
-func (imf *ImportableList) GetImportable(importableType string) Importable {
+// registration is how an importable is created for a resource type
+type registration struct {
+	empty  Importable                                                                // zero value of the importable, enough to read its HCLShape
+	create func(clients *clients.Clients, importableType string) (Importable, error) // creates the importable with its service
+}
+
+// registry holds the registration for each resource type
+var registry = map[string]registration{
+	"aws_iam_user": {
+		empty: &AWSUsersImportable{},
+		create: func(clients *clients.Clients, importableType string) (Importable, error) {
+			client, err := clients.AwsIamClient()
+			if err != nil {
+				return nil, err
+			}
+			return &AWSUsersImportable{Service: client}, nil
+		},
+	},
+	"onelogin_users": {
+		empty: &OneloginUsersImportable{},
+		create: func(clients *clients.Clients, importableType string) (Importable, error) {
+			client, err := clients.OneLoginClient()
+			if err != nil {
+				return nil, err
+			}
+			pages, err := clients.OneLoginPages()
+			if err != nil {
+				return nil, err
+			}
+			return &OneloginUsersImportable{Service: client.Services.UsersV2, Pages: pages}, nil
+		},
+	},
+	"onelogin_apps":      appsRegistration,
+	"onelogin_saml_apps": appsRegistration,
+	"onelogin_oidc_apps": appsRegistration,
+	"onelogin_user_mappings": {
+		empty: &OneloginUserMappingsImportable{},
+		create: func(clients *clients.Clients, importableType string) (Importable, error) {
+			client, err := clients.OneLoginClient()
+			if err != nil {
+				return nil, err
+			}
+			return &OneloginUserMappingsImportable{Service: client.Services.UserMappingsV2}, nil
+		},
+	},
+	"onelogin_roles": {
+		empty: &OneloginRolesImportable{},
+		create: func(clients *clients.Clients, importableType string) (Importable, error) {
+			client, err := clients.OneLoginClient()
+			if err != nil {
+				return nil, err
+			}
+			return &OneloginRolesImportable{Service: client.Services.RolesV1}, nil
+		},
+	},
+}
+
+// apps are registered under each resource type they are imported as
+var appsRegistration = registration{
+	empty: &OneloginAppsImportable{},
+	create: func(clients *clients.Clients, importableType string) (Importable, error) {
+		client, err := clients.OneLoginClient()
+		if err != nil {
+			return nil, err
+		}
+		pages, err := clients.OneLoginPages()
+		if err != nil {
+			return nil, err
+		}
+		return &OneloginAppsImportable{Service: client.Services.AppsV2, Pages: pages, AppType: importableType}, nil
+	},
+}
+
+// HCLShape returns the HCLShape of the resource type's importable without creating clients for it
+func HCLShape(importableType string) (interface{}, bool) {
+	registration, ok := registry[importableType]
+	if !ok {
+		return nil, false
+	}
+	return registration.empty.HCLShape(), true
+}
+
+// Registered reports whether there is an importable for the resource type
+func Registered(importableType string) bool {
+	_, ok := registry[importableType]
+	return ok
+}
+
+// Names lists the resource types there are importables for, sorted
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetImportable creates the importable for the resource type once, and returns it on every later call
+func (imf *ImportableList) GetImportable(importableType string) (Importable, error) {
 	if imf.importables[importableType] == nil {
-		switch importableType {
-		case "aws_iam_user":
-			remoteClient := imf.Clients.AwsIamClient()
-			imf.importables[importableType] = &AWSUsersImportable{Service: remoteClient}
-		case "onelogin_users":
-			remoteClient := imf.Clients.OneLoginClient()
-			imf.importables[importableType] = &OneloginUsersImportable{Service: remoteClient.Services.UsersV2}
-		case "onelogin_apps", "onelogin_saml_apps", "onelogin_oidc_apps":
-			remoteClient := imf.Clients.OneLoginClient()
-			imf.importables[importableType] = &OneloginAppsImportable{Service: remoteClient.Services.AppsV2, AppType: importableType}
-		case "onelogin_user_mappings":
-			remoteClient := imf.Clients.OneLoginClient()
-			imf.importables[importableType] = &OneloginUserMappingsImportable{Service: remoteClient.Services.UserMappingsV2}
-		case "onelogin_roles":
-			remoteClient := imf.Clients.OneLoginClient()
-			imf.importables[importableType] = &OneloginRolesImportable{Service: remoteClient.Services.RolesV1}
-		default:
-			log.Fatalf("The importable %s is not configured", importableType)
+		registration, ok := registry[importableType]
+		if !ok {
+			return nil, fmt.Errorf("the importable %s is not configured", importableType)
+		}
+		importable, err := registration.create(imf.Clients, importableType)
+		if err != nil {
+			return nil, err
 		}
+		imf.importables[importableType] = importable
 	}
-	return imf.importables[importableType]
+	return imf.importables[importableType], nil
 }
@@ -3,6 +3,7 @@ package tfimportables
 import (
 	"github.com/onelogin/onelogin/clients"
 	"github.com/stretchr/testify/assert"
+	"sort"
 	"testing"
 )
 
@@ -31,11 +32,41 @@ func TestGetImportable(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			for _, name := range importableNames {
-				importable := test.Importables.GetImportable(name)
-				memoizedImportable := test.Importables.GetImportable(name)
+				importable, err := test.Importables.GetImportable(name)
+				assert.Nil(t, err)
+				memoizedImportable, _ := test.Importables.GetImportable(name)
 				assert.Equal(t, test.Importables.importables[name], importable)
 				assert.Equal(t, test.Importables.importables[name], memoizedImportable)
 			}
 		})
 	}
 }
+
+func TestGetImportableNotConfigured(t *testing.T) {
+	importables := New(clients.New(clients.ClientConfigs{}))
+	importable, err := importables.GetImportable("random_pet")
+	assert.Nil(t, importable)
+	assert.EqualError(t, err, "the importable random_pet is not configured")
+}
+
+func TestRegistered(t *testing.T) {
+	tests := map[string]struct {
+		ImportableType string
+		Expected       bool
+	}{
+		"it knows registered importables": {ImportableType: "onelogin_saml_apps", Expected: true},
+		"it doesn't know other resources": {ImportableType: "random_pet", Expected: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Registered(test.ImportableType))
+		})
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names()
+	assert.Contains(t, names, "onelogin_saml_apps")
+	assert.Contains(t, names, "aws_iam_user")
+	assert.True(t, sort.StringsAreSorted(names))
+}
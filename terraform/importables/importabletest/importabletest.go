@@ -0,0 +1,104 @@
+// Package importabletest runs importables against recorded API responses and compares the configuration they
+// generate with golden files, so a new importable can be tested the way every other one is.
+//
+// Testing an importable
+// Record the responses it needs with `onelogin terraform-export <type> --record-dir testdata/<type>` against a sandbox
+// account, or write them by hand, then add a Case for the type to the golden test of the importables package and run
+// `go test ./terraform/importables/ -update` to write its golden file. Review the golden file like any other change:
+// it is the configuration users get for those responses.
+package importabletest
+
+import (
+	"flag"
+	"github.com/onelogin/onelogin/clients"
+	"github.com/onelogin/onelogin/terraform/import"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/state_parser"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update rewrites the golden files with the configuration generated instead of comparing them
+var update = flag.Bool("update", false, "rewrite the golden files with the configuration the importables generate")
+
+// Case is an importable run against a directory of recorded responses
+type Case struct {
+	Type     string // resource type of the importable e.g. onelogin_roles
+	SearchID string // imports only the resource with this id, all of them when empty
+	Fixtures string // directory of recorded API responses, laid out as --record-dir writes them
+	Golden   string // file holding the configuration expected from the responses
+}
+
+// Clients replays the responses recorded in dir rather than sending requests, with any credentials
+func Clients(dir string) *clients.Clients {
+	return clients.New(clients.ClientConfigs{MockDir: dir, Retry: clients.RetryPolicy{MaxAttempts: 1}})
+}
+
+// Import creates the importable for the case's type from the registry and imports from its fixtures, naming the
+// resources the way terraform-import does
+func Import(c Case) ([]tfimportables.ResourceDefinition, error) {
+	importable, err := tfimportables.New(Clients(c.Fixtures)).GetImportable(c.Type)
+	if err != nil {
+		return nil, err
+	}
+	var searchID *string
+	if c.SearchID != "" {
+		searchID = &c.SearchID
+	}
+	resourceDefinitions, err := importable.ImportFromRemote(searchID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resourceDefinitions {
+		resourceDefinitions[i].Name = tfimport.ImportName(resourceDefinitions[i], i)
+	}
+	return resourceDefinitions, nil
+}
+
+// Render writes the resources' configuration from the data fetched from the remote, as terraform-export does
+func Render(resourceDefinitions []tfimportables.ResourceDefinition) ([]byte, error) {
+	state, err := stateparser.FromRemote(resourceDefinitions)
+	if err != nil {
+		return nil, err
+	}
+	return stateparser.Render(state, stateparser.Options{})
+}
+
+// Run imports the case's resources and compares their configuration with its golden file
+func Run(t *testing.T, c Case) {
+	t.Helper()
+	resourceDefinitions, err := Import(c)
+	if !assert.Nil(t, err) {
+		return
+	}
+	actual, err := Render(resourceDefinitions)
+	if !assert.Nil(t, err) {
+		return
+	}
+	AssertGolden(t, c.Golden, actual)
+}
+
+// AssertGolden compares actual with the contents of the golden file at path, or writes actual to it with -update
+func AssertGolden(t *testing.T, path string, actual []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, actual, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := ioutil.ReadFile(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		t.Fatalf("no golden file at %s, run the test with -update to write it", path)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, string(expected), string(actual), "generated configuration differs from %s, run the test with -update if the change is intended", path)
+}
@@ -1,12 +1,14 @@
 package tfimportables
 
 import (
+	"encoding/json"
 	"fmt"
-	"log"
+	"net/url"
 	"strconv"
 
 	"github.com/onelogin/onelogin-go-sdk/pkg/services/apps"
 	"github.com/onelogin/onelogin-go-sdk/pkg/utils"
+	"github.com/onelogin/onelogin/logger"
 )
 
 type AppQuerier interface {
@@ -17,29 +19,71 @@ type AppQuerier interface {
 type OneloginAppsImportable struct {
 	AppType string
 	Service AppQuerier
+	Pages   PageReader // when given, apps are streamed a page at a time instead of listed through the Service
 }
 
 // Interface requirement to be an Importable. Calls out to remote (onelogin api) and
 // creates their Terraform ResourceDefinitions
-func (i OneloginAppsImportable) ImportFromRemote(searchId *string) []ResourceDefinition {
+func (i OneloginAppsImportable) ImportFromRemote(searchId *string) ([]ResourceDefinition, error) {
+	if i.Pages != nil && (searchId == nil || *searchId == "") {
+		return collect(i, searchId)
+	}
 	var remoteApps []apps.App
 	if searchId == nil || *searchId == "" {
-		fmt.Println("Collecting Apps from OneLogin...")
-		remoteApps = i.getOneLoginAppsApps()
+		logger.Info("Collecting Apps from OneLogin...")
+		var err error
+		if remoteApps, err = i.getOneLoginAppsApps(); err != nil {
+			return nil, err
+		}
 	} else {
-		fmt.Printf("Collecting App %s from OneLogin...\n", *searchId)
+		logger.Info("Collecting App from OneLogin...", "id", *searchId)
 		id, err := strconv.Atoi(*searchId)
 		if err != nil {
-			log.Fatalln("invalid input given for id", *searchId)
+			return nil, fmt.Errorf("invalid input given for id %s", *searchId)
 		}
 		app, err := i.Service.GetOne(int32(id))
 		if err != nil {
-			log.Fatalln("Unable to locate resource with id", id)
+			return nil, fmt.Errorf("unable to locate resource with id %d: %s", id, err)
 		}
 		remoteApps = []apps.App{*app}
 	}
 	resourceDefinitions := assembleResourceDefinitions(remoteApps)
-	return resourceDefinitions
+	return resourceDefinitions, nil
+}
+
+// StreamFromRemote hands over the apps of each page as it is fetched. Without a PageReader, or for one app, they
+// are collected first
+func (i OneloginAppsImportable) StreamFromRemote(searchId *string, each func(ResourceDefinition) error) error {
+	if i.Pages == nil || (searchId != nil && *searchId != "") {
+		definitions, err := i.ImportFromRemote(searchId)
+		if err != nil {
+			return err
+		}
+		return emit(definitions, each)
+	}
+	logger.Info("Collecting Apps from OneLogin...")
+	query := url.Values{}
+	if authMethod := appTypeQueryMap[i.AppType]; authMethod != "" {
+		query.Set("auth_method", authMethod)
+	}
+	number := 0
+	err := i.Pages.StreamPages("/api/2/apps", query, func(page []byte) error {
+		number++
+		var remoteApps []apps.App
+		if err := json.Unmarshal(page, &remoteApps); err != nil {
+			return fmt.Errorf("unable to read page %d: %s", number, err)
+		}
+		for _, definition := range assembleResourceDefinitions(remoteApps) {
+			if err := each(definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error retrieving apps: %s", err)
+	}
+	return nil
 }
 
 // helper for packing apps into ResourceDefinitions
@@ -50,6 +94,7 @@ func assembleResourceDefinitions(allApps []apps.App) []ResourceDefinition {
 			Provider: "onelogin",
 			ImportID: fmt.Sprintf("%d", *app.ID),
 			Name:     utils.ToSnakeCase(utils.ReplaceSpecialChar(*app.Name, "")),
+			Remote:   app,
 		}
 		switch *app.AuthMethod {
 		case 8:
@@ -64,24 +109,25 @@ func assembleResourceDefinitions(allApps []apps.App) []ResourceDefinition {
 	return resourceDefinitions
 }
 
-// Makes the HTTP call to the remote to get the apps using the given query parameters
-func (i OneloginAppsImportable) getOneLoginAppsApps() []apps.App {
+// auth methods the apps of each resource type are queried by
+var appTypeQueryMap = map[string]string{
+	"onelogin_apps":      "",
+	"onelogin_saml_apps": "2",
+	"onelogin_oidc_apps": "8",
+}
 
-	appTypeQueryMap := map[string]string{
-		"onelogin_apps":      "",
-		"onelogin_saml_apps": "2",
-		"onelogin_oidc_apps": "8",
-	}
+// Makes the HTTP call to the remote to get the apps using the given query parameters
+func (i OneloginAppsImportable) getOneLoginAppsApps() ([]apps.App, error) {
 	requestedAppType := appTypeQueryMap[i.AppType]
 
 	appApps, err := i.Service.Query(&apps.AppsQuery{
 		AuthMethod: requestedAppType,
 	})
 	if err != nil {
-		log.Fatal("error retrieving apps ", err)
+		return nil, fmt.Errorf("error retrieving apps: %s", err)
 	}
 
-	return appApps
+	return appApps, nil
 }
 
 func (i OneloginAppsImportable) HCLShape() interface{} {
@@ -19,9 +19,9 @@ func TestAssembleResourceDefinitions(t *testing.T) {
 				apps.App{Name: oltypes.String("test3"), AuthMethod: oltypes.Int32(1), ID: oltypes.Int32(3)},
 			},
 			ExpectedOut: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Type: "onelogin_oidc_apps", ImportID: "1", Name: "test1"},
-				ResourceDefinition{Provider: "onelogin", Type: "onelogin_saml_apps", ImportID: "2", Name: "test2"},
-				ResourceDefinition{Provider: "onelogin", Type: "onelogin_apps", ImportID: "3", Name: "test3"},
+				ResourceDefinition{Provider: "onelogin", Type: "onelogin_oidc_apps", ImportID: "1", Name: "test1", Remote: apps.App{Name: oltypes.String("test1"), AuthMethod: oltypes.Int32(8), ID: oltypes.Int32(1)}},
+				ResourceDefinition{Provider: "onelogin", Type: "onelogin_saml_apps", ImportID: "2", Name: "test2", Remote: apps.App{Name: oltypes.String("test2"), AuthMethod: oltypes.Int32(2), ID: oltypes.Int32(2)}},
+				ResourceDefinition{Provider: "onelogin", Type: "onelogin_apps", ImportID: "3", Name: "test3", Remote: apps.App{Name: oltypes.String("test3"), AuthMethod: oltypes.Int32(1), ID: oltypes.Int32(3)}},
 			},
 		},
 	}
@@ -47,27 +47,44 @@ func (svc MockAppsService) GetOne(id int32) (*apps.App, error) {
 
 func TestImportAppFromRemote(t *testing.T) {
 	tests := map[string]struct {
-		SearchID   *string
-		Importable OneloginAppsImportable
-		Expected   []ResourceDefinition
+		SearchID      *string
+		Importable    OneloginAppsImportable
+		Expected      []ResourceDefinition
+		ExpectedError string
 	}{
 		"It pulls all apps of a certain type": {
 			Importable: OneloginAppsImportable{AppType: "onelogin_saml_apps", Service: MockAppsService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_saml_apps"},
+				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_saml_apps", Remote: apps.App{Name: oltypes.String("test2"), AuthMethod: oltypes.Int32(2), ID: oltypes.Int32(2)}},
+			},
+		},
+		"It pulls apps of a certain type a page at a time": {
+			Importable: OneloginAppsImportable{AppType: "onelogin_saml_apps", Service: MockAppsService{}, Pages: MockPageReader{Pages: []string{`[{"id":2,"name":"test2","auth_method":2}]`}}},
+			Expected: []ResourceDefinition{
+				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_saml_apps", Remote: apps.App{Name: oltypes.String("test2"), AuthMethod: oltypes.Int32(2), ID: oltypes.Int32(2)}},
 			},
 		},
 		"It gets one app": {
 			SearchID:   oltypes.String("2"),
 			Importable: OneloginAppsImportable{AppType: "onelogin_saml_apps", Service: MockAppsService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_saml_apps"},
+				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_saml_apps", Remote: apps.App{Name: oltypes.String("test2"), AuthMethod: oltypes.Int32(2), ID: oltypes.Int32(2)}},
 			},
 		},
+		"It reports ids that aren't numbers": {
+			SearchID:      oltypes.String("abc"),
+			Importable:    OneloginAppsImportable{Service: MockAppsService{}},
+			ExpectedError: "invalid input given for id abc",
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			actual := test.Importable.ImportFromRemote(test.SearchID)
+			actual, err := test.Importable.ImportFromRemote(test.SearchID)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
 			assert.Equal(t, test.Expected, actual)
 		})
 	}
@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"github.com/onelogin/onelogin-go-sdk/pkg/services/roles"
 	"github.com/onelogin/onelogin-go-sdk/pkg/utils"
-	"log"
+	"github.com/onelogin/onelogin/logger"
 	"strconv"
 )
 
@@ -19,24 +19,24 @@ type OneloginRolesImportable struct {
 
 // Interface requirement to be an Importable. Calls out to remote (onelogin api) and
 // creates their Terraform ResourceDefinitions
-func (i OneloginRolesImportable) ImportFromRemote(searchId *string) []ResourceDefinition {
+func (i OneloginRolesImportable) ImportFromRemote(searchId *string) ([]ResourceDefinition, error) {
 	out := []roles.Role{}
 	var err error
 	if searchId == nil || *searchId == "" {
-		fmt.Println("Collecting Roles from OneLogin...")
+		logger.Info("Collecting Roles from OneLogin...")
 		out, err = i.Service.Query(nil) // Todo, interface to pass these queries down
 		if err != nil {
-			log.Fatalln("Unable to get roles", err)
+			return nil, fmt.Errorf("unable to get roles: %s", err)
 		}
 	} else {
-		fmt.Printf("Collecting Role %s from OneLogin...\n", *searchId)
+		logger.Info("Collecting Role from OneLogin...", "id", *searchId)
 		id, err := strconv.Atoi(*searchId)
 		if err != nil {
-			log.Fatalln("invalid input given for id", *searchId)
+			return nil, fmt.Errorf("invalid input given for id %s", *searchId)
 		}
 		role, err := i.Service.GetOne(int32(id))
 		if err != nil {
-			log.Fatalln("Unable to locate resource with id", id)
+			return nil, fmt.Errorf("unable to locate resource with id %d: %s", id, err)
 		}
 		out = append(out, *role)
 	}
@@ -47,9 +47,10 @@ func (i OneloginRolesImportable) ImportFromRemote(searchId *string) []ResourceDe
 			Type:     "onelogin_roles",
 			Name:     utils.ToSnakeCase(utils.ReplaceSpecialChar(*rd.Name, "")),
 			ImportID: fmt.Sprintf("%d", *rd.ID),
+			Remote:   rd,
 		}
 	}
-	return resourceDefinitions
+	return resourceDefinitions, nil
 }
 
 func (i OneloginRolesImportable) HCLShape() interface{} {
@@ -22,28 +22,39 @@ func (svc MockRolesService) GetOne(id int32) (*roles.Role, error) {
 
 func TestImportRoleFromRemote(t *testing.T) {
 	tests := map[string]struct {
-		SearchID   *string
-		Importable OneloginRolesImportable
-		Expected   []ResourceDefinition
+		SearchID      *string
+		Importable    OneloginRolesImportable
+		Expected      []ResourceDefinition
+		ExpectedError string
 	}{
 		"It pulls all roles": {
 			Importable: OneloginRolesImportable{Service: MockRolesService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Name: "test1", ImportID: "1", Type: "onelogin_roles"},
-				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_roles"},
+				ResourceDefinition{Provider: "onelogin", Name: "test1", ImportID: "1", Type: "onelogin_roles", Remote: roles.Role{Name: oltypes.String("test_1"), Apps: []int32{1, 2, 3}, ID: oltypes.Int32(1)}},
+				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_roles", Remote: roles.Role{Name: oltypes.String("test_2"), Apps: []int32{1, 2, 3}, ID: oltypes.Int32(2)}},
 			},
 		},
 		"It gets one role": {
 			SearchID:   oltypes.String("1"),
 			Importable: OneloginRolesImportable{Service: MockRolesService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Name: "test", ImportID: "1", Type: "onelogin_roles"},
+				ResourceDefinition{Provider: "onelogin", Name: "test", ImportID: "1", Type: "onelogin_roles", Remote: roles.Role{Name: oltypes.String("test"), Apps: []int32{1, 2, 3}, ID: oltypes.Int32(1)}},
 			},
 		},
+		"It reports ids that aren't numbers": {
+			SearchID:      oltypes.String("abc"),
+			Importable:    OneloginRolesImportable{Service: MockRolesService{}},
+			ExpectedError: "invalid input given for id abc",
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			actual := test.Importable.ImportFromRemote(test.SearchID)
+			actual, err := test.Importable.ImportFromRemote(test.SearchID)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
 			assert.Equal(t, test.Expected, actual)
 		})
 	}
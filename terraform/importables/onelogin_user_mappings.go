@@ -4,7 +4,7 @@ import (
 	"fmt"
 	"github.com/onelogin/onelogin-go-sdk/pkg/services/user_mappings"
 	"github.com/onelogin/onelogin-go-sdk/pkg/utils"
-	"log"
+	"github.com/onelogin/onelogin/logger"
 	"strconv"
 )
 
@@ -19,25 +19,28 @@ type OneloginUserMappingsImportable struct {
 
 // Interface requirement to be an Importable. Calls out to remote (onelogin api) and
 // creates their Terraform ResourceDefinitions
-func (i OneloginUserMappingsImportable) ImportFromRemote(searchId *string) []ResourceDefinition {
+func (i OneloginUserMappingsImportable) ImportFromRemote(searchId *string) ([]ResourceDefinition, error) {
 	var remoteUserMappings []usermappings.UserMapping
 	if searchId == nil || *searchId == "" {
-		fmt.Println("Collecting User Mappings from OneLogin...")
-		remoteUserMappings = i.getOneLoginUserMappings()
+		logger.Info("Collecting User Mappings from OneLogin...")
+		var err error
+		if remoteUserMappings, err = i.getOneLoginUserMappings(); err != nil {
+			return nil, err
+		}
 	} else {
-		fmt.Printf("Collecting User Mapping %s from OneLogin...\n", *searchId)
+		logger.Info("Collecting User Mapping from OneLogin...", "id", *searchId)
 		id, err := strconv.Atoi(*searchId)
 		if err != nil {
-			log.Fatalln("invalid input given for id", *searchId)
+			return nil, fmt.Errorf("invalid input given for id %s", *searchId)
 		}
 		userMapping, err := i.Service.GetOne(int32(id))
 		if err != nil {
-			log.Fatalln("Unable to locate resource with id", id)
+			return nil, fmt.Errorf("unable to locate resource with id %d: %s", id, err)
 		}
 		remoteUserMappings = []usermappings.UserMapping{*userMapping}
 	}
 	resourceDefinitions := assembleUserMappingResourceDefinitions(remoteUserMappings)
-	return resourceDefinitions
+	return resourceDefinitions, nil
 }
 
 // helper for packing apps into ResourceDefinitions
@@ -49,18 +52,19 @@ func assembleUserMappingResourceDefinitions(allUserMappings []usermappings.UserM
 			Type:     "onelogin_user_mappings",
 			ImportID: fmt.Sprintf("%d", *userMapping.ID),
 			Name:     utils.ReplaceSpecialChar(*userMapping.Name, ""),
+			Remote:   userMapping,
 		}
 	}
 	return resourceDefinitions
 }
 
 // Makes the HTTP call to the remote to get the apps using the given query parameters
-func (i OneloginUserMappingsImportable) getOneLoginUserMappings() []usermappings.UserMapping {
+func (i OneloginUserMappingsImportable) getOneLoginUserMappings() ([]usermappings.UserMapping, error) {
 	um, err := i.Service.Query(&usermappings.UserMappingsQuery{})
 	if err != nil {
-		log.Fatal("error retrieving apps ", err)
+		return nil, fmt.Errorf("error retrieving user mappings: %s", err)
 	}
-	return um
+	return um, nil
 }
 
 func (i OneloginUserMappingsImportable) HCLShape() interface{} {
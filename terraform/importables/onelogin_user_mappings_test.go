@@ -19,9 +19,9 @@ func TestAssembleUserMappingResourceDefinitions(t *testing.T) {
 				usermappings.UserMapping{Name: oltypes.String("test3"), ID: oltypes.Int32(3)},
 			},
 			ExpectedOut: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Type: "onelogin_user_mappings", ImportID: "1", Name: "test1"},
-				ResourceDefinition{Provider: "onelogin", Type: "onelogin_user_mappings", ImportID: "2", Name: "test2"},
-				ResourceDefinition{Provider: "onelogin", Type: "onelogin_user_mappings", ImportID: "3", Name: "test3"},
+				ResourceDefinition{Provider: "onelogin", Type: "onelogin_user_mappings", ImportID: "1", Name: "test1", Remote: usermappings.UserMapping{Name: oltypes.String("test1"), ID: oltypes.Int32(1)}},
+				ResourceDefinition{Provider: "onelogin", Type: "onelogin_user_mappings", ImportID: "2", Name: "test2", Remote: usermappings.UserMapping{Name: oltypes.String("test2"), ID: oltypes.Int32(2)}},
+				ResourceDefinition{Provider: "onelogin", Type: "onelogin_user_mappings", ImportID: "3", Name: "test3", Remote: usermappings.UserMapping{Name: oltypes.String("test3"), ID: oltypes.Int32(3)}},
 			},
 		},
 	}
@@ -47,27 +47,38 @@ func (svc MockUserMappingService) GetOne(id int32) (*usermappings.UserMapping, e
 
 func TestImportUserMappingFromRemote(t *testing.T) {
 	tests := map[string]struct {
-		SearchID   *string
-		Importable OneloginUserMappingsImportable
-		Expected   []ResourceDefinition
+		SearchID      *string
+		Importable    OneloginUserMappingsImportable
+		Expected      []ResourceDefinition
+		ExpectedError string
 	}{
 		"It pulls all apps of a certain type": {
 			Importable: OneloginUserMappingsImportable{Service: MockUserMappingService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_user_mappings"},
+				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_user_mappings", Remote: usermappings.UserMapping{Name: oltypes.String("test2"), ID: oltypes.Int32(2)}},
 			},
 		},
 		"It gets one app": {
 			SearchID:   oltypes.String("1"),
 			Importable: OneloginUserMappingsImportable{Service: MockUserMappingService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_user_mappings"},
+				ResourceDefinition{Provider: "onelogin", Name: "test2", ImportID: "2", Type: "onelogin_user_mappings", Remote: usermappings.UserMapping{Name: oltypes.String("test2"), ID: oltypes.Int32(2)}},
 			},
 		},
+		"It reports ids that aren't numbers": {
+			SearchID:      oltypes.String("abc"),
+			Importable:    OneloginUserMappingsImportable{Service: MockUserMappingService{}},
+			ExpectedError: "invalid input given for id abc",
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			actual := test.Importable.ImportFromRemote(test.SearchID)
+			actual, err := test.Importable.ImportFromRemote(test.SearchID)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
 			assert.Equal(t, test.Expected, actual)
 		})
 	}
@@ -1,10 +1,11 @@
 package tfimportables
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/onelogin/onelogin-go-sdk/pkg/services/users"
 	"github.com/onelogin/onelogin-go-sdk/pkg/utils"
-	"log"
+	"github.com/onelogin/onelogin/logger"
 	"strconv"
 )
 
@@ -15,42 +16,83 @@ type UserQuerier interface {
 
 type OneloginUsersImportable struct {
 	Service UserQuerier
+	Pages   PageReader // when given, users are streamed a page at a time instead of listed through the Service
 }
 
 // Interface requirement to be an Importable. Calls out to remote (onelogin api) and
 // creates their Terraform ResourceDefinitions
-func (i OneloginUsersImportable) ImportFromRemote(searchId *string) []ResourceDefinition {
+func (i OneloginUsersImportable) ImportFromRemote(searchId *string) ([]ResourceDefinition, error) {
+	if i.Pages != nil && (searchId == nil || *searchId == "") {
+		return collect(i, searchId)
+	}
 	out := []users.User{}
 	var err error
 	if searchId == nil || *searchId == "" {
-		fmt.Println("Collecting Users from OneLogin...")
+		logger.Info("Collecting Users from OneLogin...")
 		out, err = i.Service.Query(nil) // Todo, interface to pass these queries down
 		if err != nil {
-			log.Fatalln("Unable to get users", err)
+			return nil, fmt.Errorf("unable to get users: %s", err)
 		}
 	} else {
-		fmt.Printf("Collecting User %s from OneLogin...\n", *searchId)
+		logger.Info("Collecting User from OneLogin...", "id", *searchId)
 		id, err := strconv.Atoi(*searchId)
 		if err != nil {
-			log.Fatalln("invalid input given for id", *searchId)
+			return nil, fmt.Errorf("invalid input given for id %s", *searchId)
 		}
 		user, err := i.Service.GetOne(int32(id))
 		if err != nil {
-			log.Fatalln("Unable to locate resource with id", id)
+			return nil, fmt.Errorf("unable to locate resource with id %d: %s", id, err)
 		}
 		out = append(out, *user)
 	}
 	resourceDefinitions := make([]ResourceDefinition, len(out))
 	for i, rd := range out {
-		name := utils.ReplaceSpecialChar(*rd.Email, "_") // use email as unique identifier
-		resourceDefinitions[i] = ResourceDefinition{
-			Provider: "onelogin",
-			Type:     "onelogin_users",
-			Name:     name[:len(name)-4], // trims the .com part of the email
-			ImportID: fmt.Sprintf("%d", *rd.ID),
+		resourceDefinitions[i] = userDefinition(rd)
+	}
+	return resourceDefinitions, nil
+}
+
+// StreamFromRemote hands over the users of each page as it is fetched. Without a PageReader, or for one user, they
+// are collected first
+func (i OneloginUsersImportable) StreamFromRemote(searchId *string, each func(ResourceDefinition) error) error {
+	if i.Pages == nil || (searchId != nil && *searchId != "") {
+		definitions, err := i.ImportFromRemote(searchId)
+		if err != nil {
+			return err
 		}
+		return emit(definitions, each)
+	}
+	logger.Info("Collecting Users from OneLogin...")
+	number := 0
+	err := i.Pages.StreamPages("/api/2/users", nil, func(page []byte) error {
+		number++
+		var remoteUsers []users.User
+		if err := json.Unmarshal(page, &remoteUsers); err != nil {
+			return fmt.Errorf("unable to read page %d: %s", number, err)
+		}
+		for _, user := range remoteUsers {
+			if err := each(userDefinition(user)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to get users: %s", err)
+	}
+	return nil
+}
+
+// helper for packing a user into a ResourceDefinition
+func userDefinition(user users.User) ResourceDefinition {
+	name := utils.ReplaceSpecialChar(*user.Email, "_") // use email as unique identifier
+	return ResourceDefinition{
+		Provider: "onelogin",
+		Type:     "onelogin_users",
+		Name:     name[:len(name)-4], // trims the .com part of the email
+		ImportID: fmt.Sprintf("%d", *user.ID),
+		Remote:   user,
 	}
-	return resourceDefinitions
 }
 
 func (i OneloginUsersImportable) HCLShape() interface{} {
@@ -4,6 +4,7 @@ import (
 	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
 	"github.com/onelogin/onelogin-go-sdk/pkg/services/users"
 	"github.com/stretchr/testify/assert"
+	"net/url"
 	"testing"
 )
 
@@ -20,30 +21,69 @@ func (svc MockUsersService) GetOne(id int32) (*users.User, error) {
 	return &users.User{Username: oltypes.String("test"), Email: oltypes.String("test@test.com"), ID: oltypes.Int32(1)}, nil
 }
 
+type MockPageReader struct {
+	Pages []string
+	Err   error
+}
+
+func (r MockPageReader) StreamPages(path string, query url.Values, each func(page []byte) error) error {
+	for _, page := range r.Pages {
+		if err := each([]byte(page)); err != nil {
+			return err
+		}
+	}
+	return r.Err
+}
+
 func TestImportUserFromRemote(t *testing.T) {
 	tests := map[string]struct {
-		SearchID   *string
-		Importable OneloginUsersImportable
-		Expected   []ResourceDefinition
+		SearchID      *string
+		Importable    OneloginUsersImportable
+		Expected      []ResourceDefinition
+		ExpectedError string
 	}{
 		"It pulls all apps of a certain type": {
 			Importable: OneloginUsersImportable{Service: MockUsersService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Name: "test_1_test", ImportID: "1", Type: "onelogin_users"},
-				ResourceDefinition{Provider: "onelogin", Name: "test_2_test", ImportID: "2", Type: "onelogin_users"},
+				ResourceDefinition{Provider: "onelogin", Name: "test_1_test", ImportID: "1", Type: "onelogin_users", Remote: users.User{Username: oltypes.String("test_1"), Email: oltypes.String("test_1@test.com"), ID: oltypes.Int32(1)}},
+				ResourceDefinition{Provider: "onelogin", Name: "test_2_test", ImportID: "2", Type: "onelogin_users", Remote: users.User{Username: oltypes.String("test_2"), Email: oltypes.String("test_2@test.com"), ID: oltypes.Int32(2)}},
 			},
 		},
+		"It pulls users a page at a time": {
+			Importable: OneloginUsersImportable{Service: MockUsersService{}, Pages: MockPageReader{Pages: []string{
+				`[{"id":1,"email":"test_1@test.com"}]`,
+				`[{"id":2,"email":"test_2@test.com"}]`,
+			}}},
+			Expected: []ResourceDefinition{
+				ResourceDefinition{Provider: "onelogin", Name: "test_1_test", ImportID: "1", Type: "onelogin_users", Remote: users.User{Email: oltypes.String("test_1@test.com"), ID: oltypes.Int32(1)}},
+				ResourceDefinition{Provider: "onelogin", Name: "test_2_test", ImportID: "2", Type: "onelogin_users", Remote: users.User{Email: oltypes.String("test_2@test.com"), ID: oltypes.Int32(2)}},
+			},
+		},
+		"It reports pages it can't read": {
+			Importable:    OneloginUsersImportable{Service: MockUsersService{}, Pages: MockPageReader{Pages: []string{`{"message":"oops"}`}}},
+			ExpectedError: "unable to get users: unable to read page 1: json: cannot unmarshal object into Go value of type []users.User",
+		},
 		"It gets one app": {
 			SearchID:   oltypes.String("1"),
 			Importable: OneloginUsersImportable{Service: MockUsersService{}},
 			Expected: []ResourceDefinition{
-				ResourceDefinition{Provider: "onelogin", Name: "test_test", ImportID: "1", Type: "onelogin_users"},
+				ResourceDefinition{Provider: "onelogin", Name: "test_test", ImportID: "1", Type: "onelogin_users", Remote: users.User{Username: oltypes.String("test"), Email: oltypes.String("test@test.com"), ID: oltypes.Int32(1)}},
 			},
 		},
+		"It reports ids that aren't numbers": {
+			SearchID:      oltypes.String("abc"),
+			Importable:    OneloginUsersImportable{Service: MockUsersService{}},
+			ExpectedError: "invalid input given for id abc",
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			actual := test.Importable.ImportFromRemote(test.SearchID)
+			actual, err := test.Importable.ImportFromRemote(test.SearchID)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
 			assert.Equal(t, test.Expected, actual)
 		})
 	}
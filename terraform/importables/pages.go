@@ -0,0 +1,11 @@
+package tfimportables
+
+import (
+	"net/url"
+)
+
+// PageReader hands every page of a OneLogin collection over in order as it is fetched. clients.PageReader requests
+// several at once
+type PageReader interface {
+	StreamPages(path string, query url.Values, each func(page []byte) error) error
+}
@@ -0,0 +1,47 @@
+package tfimportables
+
+// Reference marks an attribute that holds ids of another resource type so generated configuration
+// can point at that resource e.g. onelogin_roles.engineering.id rather than embedding its id
+type Reference struct {
+	Path          string                                     // attribute path within the resource e.g. rules.actions.value
+	ResourceTypes []string                                   // types of resource the ids may belong to
+	When          func(siblings map[string]interface{}) bool // optional, limits the reference to blocks whose other attributes match
+}
+
+// apps are imported as one of several resource types depending on their auth method
+var appTypes = []string{"onelogin_apps", "onelogin_saml_apps", "onelogin_oidc_apps"}
+
+// references is the registry of attributes holding ids of other resources, keyed by the resource type that holds them
+var references = map[string][]Reference{
+	"onelogin_apps": {
+		{Path: "rules.actions.value", ResourceTypes: []string{"onelogin_roles"}, When: roleAction},
+	},
+	"onelogin_saml_apps": {
+		{Path: "rules.actions.value", ResourceTypes: []string{"onelogin_roles"}, When: roleAction},
+	},
+	"onelogin_oidc_apps": {
+		{Path: "rules.actions.value", ResourceTypes: []string{"onelogin_roles"}, When: roleAction},
+	},
+	"onelogin_roles": {
+		{Path: "apps", ResourceTypes: appTypes},
+		{Path: "users", ResourceTypes: []string{"onelogin_users"}},
+		{Path: "admins", ResourceTypes: []string{"onelogin_users"}},
+	},
+	"onelogin_users": {
+		{Path: "manager_user_id", ResourceTypes: []string{"onelogin_users"}},
+	},
+	"onelogin_user_mappings": {
+		{Path: "actions.value", ResourceTypes: []string{"onelogin_roles"}, When: roleAction},
+	},
+}
+
+// References returns the attributes of the resource type that hold ids of other resources
+func References(resourceType string) []Reference {
+	return references[resourceType]
+}
+
+// rule and mapping actions share a value list whose meaning depends on the action
+func roleAction(siblings map[string]interface{}) bool {
+	action, _ := siblings["action"].(string)
+	return action == "set_role" || action == "add_role"
+}
@@ -0,0 +1,17 @@
+package tfimportables
+
+// sensitive is the registry of attributes holding secrets, keyed by resource type. Their values are
+// redacted from generated configuration unless asked for, in addition to attributes the provider schema marks sensitive
+var sensitive = map[string][]string{
+	"onelogin_apps":      appSensitive,
+	"onelogin_saml_apps": appSensitive,
+	"onelogin_oidc_apps": appSensitive,
+}
+
+// appSensitive are shared by the resource types apps are imported as
+var appSensitive = []string{"sso.client_secret", "certificate.value", "configuration.certificate", "configuration.client_secret"}
+
+// Sensitive returns the attribute paths of the resource type that hold secrets e.g. sso.client_secret
+func Sensitive(resourceType string) []string {
+	return sensitive[resourceType]
+}
@@ -0,0 +1,30 @@
+package tfimportables
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"strings"
+)
+
+// sourcePaths are where the remote objects of each OneLogin resource type live in the API, by type
+var sourcePaths = map[string]string{
+	"onelogin_apps":          "/api/2/apps/%s",
+	"onelogin_saml_apps":     "/api/2/apps/%s",
+	"onelogin_oidc_apps":     "/api/2/apps/%s",
+	"onelogin_roles":         "/api/2/roles/%s",
+	"onelogin_users":         "/api/2/users/%s",
+	"onelogin_user_mappings": "/api/2/mappings/%s",
+}
+
+// Source is where the remote object of the resource lives, to trace generated configuration back to it: its URL in
+// the API of the OneLogin account at baseURL e.g. https://api.us.onelogin.com/api/2/apps/12, or its ARN for AWS
+// resources. Empty when it isn't known
+func Source(resource ResourceDefinition, baseURL string) string {
+	if path, ok := sourcePaths[resource.Type]; ok && baseURL != "" {
+		return strings.TrimRight(baseURL, "/") + fmt.Sprintf(path, resource.ImportID)
+	}
+	if user, ok := resource.Remote.(iam.User); ok && user.Arn != nil {
+		return *user.Arn
+	}
+	return ""
+}
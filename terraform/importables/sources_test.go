@@ -0,0 +1,34 @@
+package tfimportables
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := map[string]struct {
+		Resource ResourceDefinition
+		BaseURL  string
+		Expected string
+	}{
+		"It links OneLogin resources to the API": {
+			Resource: ResourceDefinition{Type: "onelogin_saml_apps", ImportID: "12"},
+			BaseURL:  "https://api.us.onelogin.com/",
+			Expected: "https://api.us.onelogin.com/api/2/apps/12",
+		},
+		"It links AWS users by ARN": {
+			Resource: ResourceDefinition{Type: "aws_iam_user", ImportID: "bob", Remote: iam.User{Arn: aws.String("arn:aws:iam::123456789012:user/bob")}},
+			Expected: "arn:aws:iam::123456789012:user/bob",
+		},
+		"It doesn't know OneLogin resources without the URL": {
+			Resource: ResourceDefinition{Type: "onelogin_roles", ImportID: "3"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, Source(test.Resource, test.BaseURL))
+		})
+	}
+}
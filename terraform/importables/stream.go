@@ -0,0 +1,59 @@
+package tfimportables
+
+import (
+	"context"
+	"fmt"
+)
+
+// StreamingImportable is an Importable that hands resources over as it collects them, so callers can start on the
+// first before the last is fetched without holding every resource of a huge tenant at once
+type StreamingImportable interface {
+	Importable
+	StreamFromRemote(searchId *string, each func(ResourceDefinition) error) error // calls each per resource, stopping at its first error
+}
+
+// StreamFromRemoteContext calls each with the importable's resources as they are collected, returning as soon as ctx
+// is done. Importables that can't stream are collected whole first
+func StreamFromRemoteContext(ctx context.Context, importable Importable, searchId *string, each func(ResourceDefinition) error) error {
+	streaming, ok := importable.(StreamingImportable)
+	if !ok {
+		definitions, err := ImportFromRemoteContext(ctx, importable, searchId)
+		if err != nil {
+			return err
+		}
+		return emit(definitions, each)
+	}
+	err := streaming.StreamFromRemote(searchId, func(definition ResourceDefinition) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return each(definition)
+	})
+	if ctx.Err() != nil {
+		return fmt.Errorf("stopped collecting resources: %s", ctx.Err()) // the error was caused by cancelling
+	}
+	return err
+}
+
+// collect gathers what the importable streams, for its ImportFromRemote
+func collect(importable StreamingImportable, searchId *string) ([]ResourceDefinition, error) {
+	definitions := []ResourceDefinition{}
+	err := importable.StreamFromRemote(searchId, func(definition ResourceDefinition) error {
+		definitions = append(definitions, definition)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return definitions, nil
+}
+
+// emit hands resources collected whole over one at a time, for importables streaming only some of their queries
+func emit(definitions []ResourceDefinition, each func(ResourceDefinition) error) error {
+	for _, definition := range definitions {
+		if err := each(definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package tfimportables
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestStreamFromRemoteContext(t *testing.T) {
+	twoPages := MockPageReader{Pages: []string{
+		`[{"id":1,"email":"test_1@test.com"},{"id":2,"email":"test_2@test.com"}]`,
+		`[{"id":3,"email":"test_3@test.com"}]`,
+	}}
+	tests := map[string]struct {
+		Importable    Importable
+		Cancelled     bool
+		StopAt        string
+		Expected      []string
+		ExpectedError string
+	}{
+		"It hands over the resources of each page in order": {
+			Importable: OneloginUsersImportable{Service: MockUsersService{}, Pages: twoPages},
+			Expected:   []string{"1", "2", "3"},
+		},
+		"It stops at the first error of the callback": {
+			Importable:    OneloginUsersImportable{Service: MockUsersService{}, Pages: twoPages},
+			StopAt:        "2",
+			Expected:      []string{"1", "2"},
+			ExpectedError: "unable to get users: enough",
+		},
+		"It stops once the context is done": {
+			Importable:    OneloginUsersImportable{Service: MockUsersService{}, Pages: twoPages},
+			Cancelled:     true,
+			Expected:      []string{},
+			ExpectedError: "stopped collecting resources: context canceled",
+		},
+		"It collects importables that can't stream first": {
+			Importable: MockImportable{fetch: func() ([]ResourceDefinition, error) {
+				return []ResourceDefinition{{ImportID: "1"}, {ImportID: "2"}}, nil
+			}},
+			Expected: []string{"1", "2"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			if test.Cancelled {
+				cancel()
+			}
+			defer cancel()
+			actual := []string{}
+			err := StreamFromRemoteContext(ctx, test.Importable, nil, func(definition ResourceDefinition) error {
+				actual = append(actual, definition.ImportID)
+				if definition.ImportID == test.StopAt {
+					return errors.New("enough")
+				}
+				return nil
+			})
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
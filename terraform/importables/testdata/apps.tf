@@ -0,0 +1,31 @@
+terraform {
+  required_providers {
+    onelogin = {
+      source = "onelogin/onelogin"
+    }
+  }
+}
+
+provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_saml_apps" "_wiki_1" {
+  configuration = {
+    signature_algorithm = "SHA-256"
+  }
+  connector_id = 110016
+  description  = "Team wiki"
+  name         = "Wiki"
+  visible      = true
+}
+
+resource "onelogin_oidc_apps" "_chat_2" {
+  configuration = {
+    redirect_uri = "https://chat.example.com/callback"
+  }
+  connector_id = 108419
+  name         = "Chat"
+  visible      = true
+}
+
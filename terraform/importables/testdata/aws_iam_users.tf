@@ -0,0 +1,16 @@
+terraform {
+  required_providers {
+    onelogin = {
+      source = "onelogin/onelogin"
+    }
+  }
+}
+
+provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "aws_iam_user" "_ann_1" {
+  path = "/engineering/"
+}
+
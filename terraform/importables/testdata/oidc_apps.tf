@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    onelogin = {
+      source = "onelogin/onelogin"
+    }
+  }
+}
+
+provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_oidc_apps" "_chat_1" {
+  configuration = {
+    redirect_uri = "https://chat.example.com/callback"
+  }
+  connector_id = 108419
+  name         = "Chat"
+  visible      = true
+}
+
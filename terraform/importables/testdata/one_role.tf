@@ -0,0 +1,16 @@
+terraform {
+  required_providers {
+    onelogin = {
+      source = "onelogin/onelogin"
+    }
+  }
+}
+
+provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_roles" "_sales_team_1" {
+  name = "Sales Team"
+}
+
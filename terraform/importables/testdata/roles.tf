@@ -0,0 +1,23 @@
+terraform {
+  required_providers {
+    onelogin = {
+      source = "onelogin/onelogin"
+    }
+  }
+}
+
+provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_roles" "_engineering_1" {
+  admins = [3]
+  apps   = [12]
+  name   = "Engineering"
+  users  = [3]
+}
+
+resource "onelogin_roles" "_sales_team_2" {
+  name = "Sales Team"
+}
+
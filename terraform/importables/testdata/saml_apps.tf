@@ -0,0 +1,22 @@
+terraform {
+  required_providers {
+    onelogin = {
+      source = "onelogin/onelogin"
+    }
+  }
+}
+
+provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_saml_apps" "_wiki_1" {
+  configuration = {
+    signature_algorithm = "SHA-256"
+  }
+  connector_id = 110016
+  description  = "Team wiki"
+  name         = "Wiki"
+  visible      = true
+}
+
@@ -0,0 +1,28 @@
+terraform {
+  required_providers {
+    onelogin = {
+      source = "onelogin/onelogin"
+    }
+  }
+}
+
+provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_user_mappings" "_Engineersgettherole_1" {
+  actions {
+    action = "add_role"
+    value  = ["1"]
+  }
+  conditions {
+    operator = "contains"
+    source   = "member_of"
+    value    = "Engineering"
+  }
+  enabled  = true
+  match    = "all"
+  name     = "Engineers get the role"
+  position = 1
+}
+
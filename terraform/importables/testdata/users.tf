@@ -0,0 +1,21 @@
+terraform {
+  required_providers {
+    onelogin = {
+      source = "onelogin/onelogin"
+    }
+  }
+}
+
+provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_users" "_ann_example_1" {
+  email     = "ann@example.com"
+  firstname = "Ann"
+  lastname  = "Lee"
+  state     = 1
+  status    = 1
+  username  = "ann"
+}
+
@@ -0,0 +1,17 @@
+package tfimportables
+
+// variables is the registry of attributes holding values specific to one tenant or environment, keyed by resource type.
+// They are extracted from generated configuration into input variables along with sensitive attributes
+var variables = map[string][]string{
+	"onelogin_apps":      appVariables,
+	"onelogin_saml_apps": appVariables,
+	"onelogin_oidc_apps": appVariables,
+}
+
+// appVariables are shared by the resource types apps are imported as
+var appVariables = []string{"configuration.redirect_uri", "configuration.login_url", "configuration.provider_arn"}
+
+// Variables returns the attribute paths of the resource type that are extracted into variables e.g. configuration.redirect_uri
+func Variables(resourceType string) []string {
+	return variables[resourceType]
+}
@@ -0,0 +1,135 @@
+// Package tfschema schema.go
+// This module reads the machine readable provider schemas emitted by `terraform providers schema -json`
+// so callers can tell which attributes a resource has and how they are shaped.
+package tfschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+	"strings"
+)
+
+// ProviderSchemas is the in memory representation of `terraform providers schema -json`
+type ProviderSchemas struct {
+	FormatVersion string                    `json:"format_version"`
+	Schemas       map[string]ProviderSchema `json:"provider_schemas"`
+}
+
+// ProviderSchema holds the schemas for every resource a provider offers, keyed by resource type
+type ProviderSchema struct {
+	ResourceSchemas   map[string]Schema `json:"resource_schemas"`
+	DataSourceSchemas map[string]Schema `json:"data_source_schemas"`
+}
+
+// Schema is the schema of a single resource type
+type Schema struct {
+	Version int   `json:"version"`
+	Block   Block `json:"block"`
+}
+
+// Block is a collection of attributes and nested blocks
+type Block struct {
+	Attributes map[string]Attribute   `json:"attributes"`
+	BlockTypes map[string]NestedBlock `json:"block_types"`
+}
+
+// Attribute describes a single attribute on a block
+type Attribute struct {
+	Type      json.RawMessage `json:"type"`
+	Required  bool            `json:"required"`
+	Optional  bool            `json:"optional"`
+	Computed  bool            `json:"computed"`
+	Sensitive bool            `json:"sensitive"`
+}
+
+// NestedBlock describes a block nested in another block and how many of it may appear
+type NestedBlock struct {
+	NestingMode string `json:"nesting_mode"`
+	Block       Block  `json:"block"`
+	MinItems    int    `json:"min_items"`
+	MaxItems    int    `json:"max_items"`
+}
+
+// Settable reports whether the attribute can be written in configuration. Purely computed attributes are read only
+func (a Attribute) Settable() bool {
+	return a.Required || a.Optional
+}
+
+// CtyType is the attribute's type as understood by HCL
+func (a Attribute) CtyType() (cty.Type, error) {
+	return ctyjson.UnmarshalType(a.Type)
+}
+
+// Value converts arbitrary JSON-like data to a value of the attribute's type
+func (a Attribute) Value(data interface{}) (cty.Value, error) {
+	ty, err := a.CtyType()
+	if err != nil {
+		return cty.NilVal, err
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return cty.NilVal, err
+	}
+	return ctyjson.Unmarshal(b, ty)
+}
+
+// Parse reads the output of `terraform providers schema -json`
+func Parse(data []byte) (*ProviderSchemas, error) {
+	schemas := ProviderSchemas{}
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		return nil, fmt.Errorf("unable to parse provider schemas: %s", err)
+	}
+	return &schemas, nil
+}
+
+// Resource finds the schema for the given resource type and the address of the provider that declares it
+func (p *ProviderSchemas) Resource(resourceType string) (*Schema, string, bool) {
+	for address, provider := range p.Schemas {
+		if schema, ok := provider.ResourceSchemas[resourceType]; ok {
+			return &schema, address, true
+		}
+	}
+	return nil, "", false
+}
+
+// ProviderName returns the local name of a provider address e.g. registry.terraform.io/onelogin/onelogin => onelogin
+func ProviderName(address string) string {
+	parts := strings.Split(address, "/")
+	return parts[len(parts)-1]
+}
+
+// Conform shapes arbitrary JSON-like data to the block. Keys that aren't in the schema are dropped
+// and anything the schema declares but the data lacks is set to its empty value
+func (b Block) Conform(data map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	for name := range b.Attributes {
+		out[name] = data[name]
+	}
+	for name, nested := range b.BlockTypes {
+		value := data[name]
+		switch nested.NestingMode {
+		case "single", "group":
+			if m, ok := value.(map[string]interface{}); ok {
+				out[name] = nested.Block.Conform(m)
+			} else {
+				out[name] = nil
+			}
+		default: // list, set
+			items := []interface{}{}
+			switch v := value.(type) {
+			case []interface{}:
+				for _, item := range v {
+					if m, ok := item.(map[string]interface{}); ok {
+						items = append(items, nested.Block.Conform(m))
+					}
+				}
+			case map[string]interface{}:
+				items = append(items, nested.Block.Conform(v))
+			}
+			out[name] = items
+		}
+	}
+	return out
+}
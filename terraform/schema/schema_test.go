@@ -0,0 +1,103 @@
+package tfschema
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+	"testing"
+)
+
+const testSchemas = `{
+	"format_version": "0.1",
+	"provider_schemas": {
+		"registry.terraform.io/onelogin/onelogin": {
+			"resource_schemas": {
+				"onelogin_apps": {
+					"version": 0,
+					"block": {
+						"attributes": {
+							"id": {"type": "string", "optional": true, "computed": true},
+							"name": {"type": "string", "required": true},
+							"connector_id": {"type": "number", "required": true}
+						},
+						"block_types": {
+							"provisioning": {
+								"nesting_mode": "list",
+								"block": {"attributes": {"enabled": {"type": "bool", "optional": true}}},
+								"max_items": 1
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestResource(t *testing.T) {
+	schemas, err := Parse([]byte(testSchemas))
+	assert.Nil(t, err)
+	schema, address, ok := schemas.Resource("onelogin_apps")
+	assert.True(t, ok)
+	assert.Equal(t, "registry.terraform.io/onelogin/onelogin", address)
+	assert.Equal(t, "onelogin", ProviderName(address))
+	assert.True(t, schema.Block.Attributes["name"].Required)
+	_, _, ok = schemas.Resource("aws_iam_user")
+	assert.False(t, ok)
+}
+
+func TestConform(t *testing.T) {
+	schemas, _ := Parse([]byte(testSchemas))
+	schema, _, _ := schemas.Resource("onelogin_apps")
+	tests := map[string]struct {
+		Input    map[string]interface{}
+		Expected map[string]interface{}
+	}{
+		"It drops unknown keys and fills missing ones": {
+			Input: map[string]interface{}{
+				"name":         "test",
+				"created_at":   "2020-01-01",
+				"provisioning": map[string]interface{}{"enabled": true, "status": "ok"},
+			},
+			Expected: map[string]interface{}{
+				"id":           nil,
+				"name":         "test",
+				"connector_id": nil,
+				"provisioning": []interface{}{map[string]interface{}{"enabled": true}},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, schema.Block.Conform(test.Input))
+		})
+	}
+}
+
+func TestAttributeValue(t *testing.T) {
+	tests := map[string]struct {
+		Type        string
+		Data        interface{}
+		Expected    cty.Value
+		ExpectError bool
+	}{
+		"It converts strings":         {Type: `"string"`, Data: "test", Expected: cty.StringVal("test")},
+		"It converts numbers":         {Type: `"number"`, Data: 22.0, Expected: cty.NumberIntVal(22)},
+		"It converts lists":           {Type: `["list", "number"]`, Data: []interface{}{1.0, 2.0}, Expected: cty.ListVal([]cty.Value{cty.NumberIntVal(1), cty.NumberIntVal(2)})},
+		"It converts maps":            {Type: `["map", "string"]`, Data: map[string]interface{}{"Key": "v"}, Expected: cty.MapVal(map[string]cty.Value{"Key": cty.StringVal("v")})},
+		"It converts null":            {Type: `"string"`, Data: nil, Expected: cty.NullVal(cty.String)},
+		"It errors on mismatch types": {Type: `"bool"`, Data: map[string]interface{}{}, ExpectError: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			attribute := Attribute{Type: json.RawMessage(test.Type)}
+			actual, err := attribute.Value(test.Data)
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.True(t, test.Expected.RawEquals(actual), actual.GoString())
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package tfschema
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Validate lists why the data, a resource's attributes, doesn't fit the block: required attributes that are missing
+// or blank, settable attributes whose value isn't of their type, and nested blocks repeated fewer or more times than
+// allowed. Problems are prefixed with the path of the attribute under path e.g. configuration.redirect_uri
+func (b Block) Validate(data map[string]interface{}, path string) []string {
+	problems := []string{}
+	names := make([]string, 0, len(b.Attributes))
+	for name := range b.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attribute := b.Attributes[name]
+		value, present := data[name]
+		if attribute.Required && (!present || value == nil || value == "") {
+			problems = append(problems, fmt.Sprintf("%s is required", join(path, name)))
+			continue
+		}
+		if !attribute.Settable() || value == nil {
+			continue
+		}
+		if _, err := attribute.Value(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", join(path, name), err))
+		}
+	}
+
+	names = make([]string, 0, len(b.BlockTypes))
+	for name := range b.BlockTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		nested := b.BlockTypes[name]
+		var items []interface{}
+		switch v := data[name].(type) {
+		case []interface{}:
+			items = v
+		case map[string]interface{}:
+			items = []interface{}{v}
+		}
+		if len(items) < nested.MinItems {
+			problems = append(problems, fmt.Sprintf("%s needs at least %d blocks, has %d", join(path, name), nested.MinItems, len(items)))
+		}
+		if nested.MaxItems > 0 && len(items) > nested.MaxItems {
+			problems = append(problems, fmt.Sprintf("%s allows at most %d blocks, has %d", join(path, name), nested.MaxItems, len(items)))
+		}
+		for _, item := range items {
+			if itemData, ok := item.(map[string]interface{}); ok {
+				problems = append(problems, nested.Block.Validate(itemData, join(path, name))...)
+			}
+		}
+	}
+	return problems
+}
+
+func join(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
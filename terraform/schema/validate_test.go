@@ -0,0 +1,41 @@
+package tfschema
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schemas, _ := Parse([]byte(testSchemas))
+	schema, _, _ := schemas.Resource("onelogin_apps")
+	tests := map[string]struct {
+		Data     map[string]interface{}
+		Expected []string
+	}{
+		"It finds nothing wrong with valid data": {
+			Data:     map[string]interface{}{"name": "Wiki", "connector_id": json.Number("110016"), "provisioning": []interface{}{map[string]interface{}{"enabled": true}}},
+			Expected: []string{},
+		},
+		"It finds required attributes missing or blank": {
+			Data:     map[string]interface{}{"name": ""},
+			Expected: []string{"connector_id is required", "name is required"},
+		},
+		"It finds values not of their type, in nested blocks too": {
+			Data: map[string]interface{}{"name": "Wiki", "connector_id": "abc", "provisioning": map[string]interface{}{"enabled": []interface{}{}}},
+			Expected: []string{
+				"connector_id: a number is required",
+				"provisioning.enabled: bool is required",
+			},
+		},
+		"It finds blocks repeated too often": {
+			Data:     map[string]interface{}{"name": "Wiki", "connector_id": 1, "provisioning": []interface{}{map[string]interface{}{}, map[string]interface{}{}}},
+			Expected: []string{"provisioning allows at most 1 blocks, has 2"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, schema.Block.Validate(test.Data, ""))
+		})
+	}
+}
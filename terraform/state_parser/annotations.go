@@ -0,0 +1,28 @@
+package stateparser
+
+import (
+	"strings"
+	"time"
+)
+
+// Annotation is the comment written above a generated resource, tracing it back to the remote object it came from
+// and when and how it was generated
+type Annotation struct {
+	Action string // what was done e.g. imported
+	Time   time.Time
+	Source string // where the remote object lives, see tfimportables.Source
+	Tool   string // what generated the configuration e.g. onelogin-cli v1.4.0
+}
+
+// Comment formats the annotation as a line comment e.g.
+// # imported 2024-06-01 from https://api.us.onelogin.com/api/2/apps/12 by onelogin-cli v1.4.0
+func (a Annotation) Comment() string {
+	parts := []string{"#", a.Action, a.Time.Format("2006-01-02")}
+	if a.Source != "" {
+		parts = append(parts, "from", a.Source)
+	}
+	if a.Tool != "" {
+		parts = append(parts, "by", a.Tool)
+	}
+	return strings.Join(parts, " ") + "\n"
+}
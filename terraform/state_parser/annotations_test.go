@@ -0,0 +1,49 @@
+package stateparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAnnotationComment(t *testing.T) {
+	imported := time.Date(2024, 6, 1, 9, 30, 0, 0, time.UTC)
+	tests := map[string]struct {
+		Annotation Annotation
+		Expected   string
+	}{
+		"It traces the resource to its source": {
+			Annotation: Annotation{Action: "imported", Time: imported, Source: "https://api.us.onelogin.com/api/2/apps/12", Tool: "onelogin-cli v1.4.0"},
+			Expected:   "# imported 2024-06-01 from https://api.us.onelogin.com/api/2/apps/12 by onelogin-cli v1.4.0\n",
+		},
+		"It leaves out what isn't known": {
+			Annotation: Annotation{Action: "exported", Time: imported},
+			Expected:   "# exported 2024-06-01\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, test.Annotation.Comment())
+		})
+	}
+}
+
+func TestAnnotatedResources(t *testing.T) {
+	state := State{Resources: []StateResource{{
+		Name:      "_wiki_1",
+		Type:      "onelogin_apps",
+		Instances: []ResourceInstance{{Data: map[string]interface{}{"id": "12", "name": "Wiki", "connector_id": 22}}},
+	}}}
+	options := Options{Annotations: map[string]Annotation{
+		"onelogin_apps._wiki_1": {Action: "imported", Time: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), Source: "https://api.us.onelogin.com/api/2/apps/12"},
+	}}
+	expected := "# imported 2024-06-01 from https://api.us.onelogin.com/api/2/apps/12\nresource \"onelogin_apps\" \"_wiki_1\" {\n  connector_id = 22\n  name         = \"Wiki\"\n}\n"
+
+	rendered, err := Render(state, options)
+	assert.Nil(t, err)
+	assert.Contains(t, string(rendered), expected)
+
+	updated, err := UpdateHCL([]byte("variable \"x\" {}\n\nresource \"onelogin_apps\" \"_wiki_1\" {\n}\n"), "main.tf", state, options, []string{"onelogin_apps._wiki_1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "variable \"x\" {}\n\n"+expected, string(updated))
+}
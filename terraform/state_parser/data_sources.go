@@ -0,0 +1,44 @@
+package stateparser
+
+import (
+	"fmt"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// dataSourceNames names the data blocks for the resources, suffixing repeated names so each is unique
+func dataSourceNames(dataSources []tfimportables.ResourceDefinition) []string {
+	names := make([]string, len(dataSources))
+	seen := map[string]int{}
+	for i, dataSource := range dataSources {
+		key := dataSource.Type + "." + dataSource.Name
+		seen[key]++
+		names[i] = dataSource.Name
+		if seen[key] > 1 {
+			names[i] = fmt.Sprintf("%s_%d", dataSource.Name, seen[key])
+		}
+	}
+	return names
+}
+
+// appendDataSources writes a data block looking up each resource by its id
+func appendDataSources(body *hclwrite.Body, dataSources []tfimportables.ResourceDefinition) {
+	for i, name := range dataSourceNames(dataSources) {
+		block := body.AppendNewBlock("data", []string{dataSources[i].Type, name})
+		block.Body().SetAttributeValue("id", cty.StringVal(dataSources[i].ImportID))
+		body.AppendNewline()
+	}
+}
+
+// indexDataSources adds the data sources to the index so ids of resources owned elsewhere reference them
+func (index addressIndex) indexDataSources(dataSources []tfimportables.ResourceDefinition) {
+	for i, name := range dataSourceNames(dataSources) {
+		if index[dataSources[i].Type] == nil {
+			index[dataSources[i].Type] = map[string]string{}
+		}
+		if _, managed := index[dataSources[i].Type][dataSources[i].ImportID]; !managed {
+			index[dataSources[i].Type][dataSources[i].ImportID] = fmt.Sprintf("data.%s.%s", dataSources[i].Type, name)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package stateparser
+
+import (
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderDataSources(t *testing.T) {
+	state := State{
+		Resources: []StateResource{
+			StateResource{
+				Name: "wiki",
+				Type: "onelogin_saml_apps",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"id": "12", "name": "wiki", "rules": []interface{}{
+						map[string]interface{}{"name": "roles", "actions": []interface{}{
+							map[string]interface{}{"action": "set_role", "value": []interface{}{"7", "8"}},
+						}},
+					}}},
+				},
+			},
+		},
+	}
+	dataSources := []tfimportables.ResourceDefinition{
+		{Provider: "onelogin", Type: "onelogin_roles", Name: "engineering", ImportID: "7"},
+		{Provider: "onelogin", Type: "onelogin_roles", Name: "engineering", ImportID: "8"},
+	}
+	tests := map[string]struct {
+		Options        Options
+		ExpectedOutput string
+	}{
+		"it writes data blocks and references them": {
+			Options:        Options{DataSources: dataSources},
+			ExpectedOutput: "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\ndata \"onelogin_roles\" \"engineering\" {\n  id = \"7\"\n}\n\ndata \"onelogin_roles\" \"engineering_2\" {\n  id = \"8\"\n}\n\nresource \"onelogin_saml_apps\" \"wiki\" {\n  name = \"wiki\"\n  rules {\n    actions {\n      action = \"set_role\"\n      value  = [data.onelogin_roles.engineering.id, data.onelogin_roles.engineering_2.id]\n    }\n    name = \"roles\"\n  }\n}\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(state, test.Options)
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package stateparser
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
+)
+
+// BuildState adds the given resources to an existing tfstate (or a fresh one if existing is empty)
+// using the data already fetched from the remote, so no provider refresh is needed per resource.
+// The provider schemas decide which attributes make it into state. Resource names are used as given.
+func BuildState(existing []byte, resources []tfimportables.ResourceDefinition, schemas *tfschema.ProviderSchemas) ([]byte, error) {
+	state, err := decodeRawState(existing)
+	if err != nil {
+		return nil, err
+	}
+	stateResources, _ := state["resources"].([]interface{})
+	for _, resource := range resources {
+		schema, providerAddress, ok := schemas.Resource(resource.Type)
+		if !ok {
+			return nil, fmt.Errorf("no provider schema found for %s", resource.Type)
+		}
+		attributes, err := remoteAttributes(resource.Remote)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read remote data for %s.%s: %s", resource.Type, resource.Name, err)
+		}
+		attributes = schema.Block.Conform(attributes)
+		attributes["id"] = resource.ImportID
+		provider := fmt.Sprintf("provider[%q]", providerAddress)
+		if resource.ProviderAlias != "" {
+			provider += "." + resource.ProviderAlias
+		}
+		stateResources = append(stateResources, map[string]interface{}{
+			"mode":     "managed",
+			"type":     resource.Type,
+			"name":     resource.Name,
+			"provider": provider,
+			"instances": []interface{}{
+				map[string]interface{}{
+					"schema_version": schema.Version,
+					"attributes":     attributes,
+				},
+			},
+		})
+	}
+	state["resources"] = stateResources
+
+	serial, _ := state["serial"].(json.Number).Int64()
+	state["serial"] = serial + 1
+
+	return json.MarshalIndent(state, "", "  ")
+}
+
+// decodes tfstate into a generic map so fields this package doesn't model survive the round trip
+func decodeRawState(data []byte) (map[string]interface{}, error) {
+	state := map[string]interface{}{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		lineage, err := newLineage()
+		if err != nil {
+			return nil, err
+		}
+		state["version"] = 4
+		state["serial"] = json.Number("0")
+		state["lineage"] = lineage
+		state["outputs"] = map[string]interface{}{}
+		state["resources"] = []interface{}{}
+		return state, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&state); err != nil {
+		return nil, fmt.Errorf("unable to parse existing state: %s", err)
+	}
+	if _, ok := state["serial"].(json.Number); !ok {
+		state["serial"] = json.Number("0")
+	}
+	return state, nil
+}
+
+// marshals the remote's representation of a resource into a generic map keyed the way the api names them
+func remoteAttributes(remote interface{}) (map[string]interface{}, error) {
+	attributes := map[string]interface{}{}
+	if remote == nil {
+		return attributes, nil
+	}
+	b, err := json.Marshal(remote)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&attributes); err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// lineage is a uuid shaped random identifier terraform uses to tell states apart
+func newLineage() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,97 @@
+package stateparser
+
+import (
+	"encoding/json"
+	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/apps"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testProviderSchemas = `{
+	"format_version": "0.1",
+	"provider_schemas": {
+		"registry.terraform.io/onelogin/onelogin": {
+			"resource_schemas": {
+				"onelogin_apps": {
+					"version": 0,
+					"block": {
+						"attributes": {
+							"id": {"type": "string", "optional": true, "computed": true},
+							"name": {"type": "string", "required": true},
+							"connector_id": {"type": "number", "required": true}
+						}
+					}
+				}
+			}
+		}
+	}
+}`
+
+func TestBuildState(t *testing.T) {
+	schemas, _ := tfschema.Parse([]byte(testProviderSchemas))
+	tests := map[string]struct {
+		ExistingState     string
+		Resources         []tfimportables.ResourceDefinition
+		ExpectedSerial    float64
+		ExpectedResources int
+		ExpectedProvider  string
+		ExpectError       bool
+	}{
+		"It creates a new state": {
+			Resources: []tfimportables.ResourceDefinition{
+				tfimportables.ResourceDefinition{Type: "onelogin_apps", Name: "_test_1", ImportID: "1", Remote: apps.App{ID: oltypes.Int32(1), Name: oltypes.String("test"), ConnectorID: oltypes.Int32(22)}},
+			},
+			ExpectedSerial:    1,
+			ExpectedResources: 1,
+		},
+		"It appends to an existing state": {
+			ExistingState: `{"version": 4, "serial": 7, "lineage": "abc", "resources": [{"mode": "managed", "type": "onelogin_apps", "name": "existing", "instances": []}]}`,
+			Resources: []tfimportables.ResourceDefinition{
+				tfimportables.ResourceDefinition{Type: "onelogin_apps", Name: "_test_1", ImportID: "1", Remote: apps.App{ID: oltypes.Int32(1), Name: oltypes.String("test")}},
+			},
+			ExpectedSerial:    8,
+			ExpectedResources: 2,
+		},
+		"It keeps the provider alias": {
+			Resources: []tfimportables.ResourceDefinition{
+				tfimportables.ResourceDefinition{Type: "onelogin_apps", Name: "_emea_test_1", ImportID: "1", ProviderAlias: "emea", Remote: apps.App{ID: oltypes.Int32(1), Name: oltypes.String("test")}},
+			},
+			ExpectedSerial:    1,
+			ExpectedResources: 1,
+			ExpectedProvider:  `provider["registry.terraform.io/onelogin/onelogin"].emea`,
+		},
+		"It errors when the provider has no schema for the resource": {
+			Resources: []tfimportables.ResourceDefinition{
+				tfimportables.ResourceDefinition{Type: "aws_iam_user", Name: "test", ImportID: "test"},
+			},
+			ExpectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := BuildState([]byte(test.ExistingState), test.Resources, schemas)
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			var state map[string]interface{}
+			json.Unmarshal(out, &state)
+			assert.Equal(t, test.ExpectedSerial, state["serial"])
+			resources := state["resources"].([]interface{})
+			assert.Equal(t, test.ExpectedResources, len(resources))
+			added := resources[len(resources)-1].(map[string]interface{})
+			expectedProvider := `provider["registry.terraform.io/onelogin/onelogin"]`
+			if test.ExpectedProvider != "" {
+				expectedProvider = test.ExpectedProvider
+			}
+			assert.Equal(t, expectedProvider, added["provider"])
+			attributes := added["instances"].([]interface{})[0].(map[string]interface{})["attributes"].(map[string]interface{})
+			assert.Equal(t, "1", attributes["id"])
+			assert.Equal(t, "test", attributes["name"])
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package stateparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
+	"strings"
+)
+
+// ResourceError is a resource that couldn't be rendered
+type ResourceError struct {
+	Address string
+	Err     error
+}
+
+// RenderError lists the resources that couldn't be rendered. The rest of the configuration is still produced, with
+// each of these written as a commented out block holding its state as JSON to fix by hand
+type RenderError struct {
+	Resources []ResourceError
+}
+
+func (e *RenderError) Error() string {
+	messages := make([]string, len(e.Resources))
+	for i, resource := range e.Resources {
+		messages[i] = resource.Err.Error()
+	}
+	return fmt.Sprintf("%d resources could not be rendered and were written as comments: %s", len(e.Resources), strings.Join(messages, "; "))
+}
+
+// add records the resource that couldn't be rendered, creating the error on the first one
+func (e *RenderError) add(resource StateResource, err error) *RenderError {
+	if e == nil {
+		e = &RenderError{}
+	}
+	e.Resources = append(e.Resources, ResourceError{Address: resource.Type + "." + resource.Name, Err: err})
+	return e
+}
+
+// commentedResource writes the resource that couldn't be rendered as a commented out block holding its state as
+// JSON, with secrets redacted unless Options.IncludeSecrets is set
+func commentedResource(resource StateResource, instance ResourceInstance, options Options, err error) []byte {
+	data := instance.Data
+	if !options.IncludeSecrets {
+		var block *tfschema.Block
+		if options.Schemas != nil {
+			if schema, _, ok := options.Schemas.Resource(resource.Type); ok {
+				block = &schema.Block
+			}
+		}
+		data = redactState(data, block, tfimportables.Sensitive(resource.Type), "")
+	}
+	state, _ := json.MarshalIndent(data, "", "  ")
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "# %s could not be rendered: %s\n", resource.Type+"."+resource.Name, err)
+	fmt.Fprintf(&out, "# Its state follows. Write its configuration from it, or fix the cause and import it again.\n")
+	fmt.Fprintf(&out, "# resource %q %q {\n", resource.Type, resource.Name)
+	for _, line := range strings.Split(string(state), "\n") {
+		fmt.Fprintf(&out, "#   %s\n", line)
+	}
+	fmt.Fprintf(&out, "# }\n")
+	return out.Bytes()
+}
+
+// redactState copies the state replacing the values of sensitive attributes, those the schema block marks
+// sensitive or at the given paths, with RedactedPlaceholder
+func redactState(data interface{}, block *tfschema.Block, sensitive []string, path string) interface{} {
+	switch value := data.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(value))
+		for name, item := range value {
+			itemPath := attributePath(path, name)
+			var attribute tfschema.Attribute
+			var nested *tfschema.Block
+			if block != nil {
+				attribute = block.Attributes[name]
+				if nestedBlock, ok := block.BlockTypes[name]; ok {
+					nested = &nestedBlock.Block
+				}
+			}
+			if (attribute.Sensitive || contains(sensitive, itemPath)) && item != nil && item != "" {
+				out[name] = RedactedPlaceholder
+				continue
+			}
+			out[name] = redactState(item, nested, sensitive, itemPath)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(value))
+		for i, item := range value {
+			out[i] = redactState(item, block, sensitive, path) // paths leave out indexes, like sso.client_secret
+		}
+		return out
+	}
+	return data
+}
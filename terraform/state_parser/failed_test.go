@@ -0,0 +1,91 @@
+package stateparser
+
+import (
+	"github.com/onelogin/onelogin/terraform/schema"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderFailedResources(t *testing.T) {
+	schemas := failingSchemas()
+	state := State{Resources: []StateResource{{
+		Name: "broken",
+		Type: "onelogin_apps",
+		Instances: []ResourceInstance{{Data: map[string]interface{}{
+			"name":         "Broken",
+			"connector_id": map[string]interface{}{"unexpected": true},
+			"sso":          []interface{}{map[string]interface{}{"client_secret": "hunter2"}},
+		}}},
+	}, {
+		Name:      "fine",
+		Type:      "onelogin_apps",
+		Instances: []ResourceInstance{{Data: map[string]interface{}{"name": "Fine", "connector_id": 1}}},
+	}}}
+
+	actual, err := Render(state, Options{Schemas: schemas})
+
+	renderError, ok := err.(*RenderError)
+	assert.True(t, ok, "failures are returned as a RenderError")
+	assert.Equal(t, "onelogin_apps.broken", renderError.Resources[0].Address)
+	assert.Len(t, renderError.Resources, 1)
+	assert.Contains(t, string(actual), "# onelogin_apps.broken could not be rendered: unable to render onelogin_apps.broken: connector_id")
+	assert.Contains(t, string(actual), "# resource \"onelogin_apps\" \"broken\" {\n#   {\n")
+	assert.Contains(t, string(actual), "\"client_secret\": \"REDACTED\"", "secrets are redacted from the state in comments")
+	assert.NotContains(t, string(actual), "hunter2")
+	assert.Contains(t, string(actual), "resource \"onelogin_apps\" \"fine\" {\n  connector_id = 1\n  name         = \"Fine\"\n}\n", "the other resources are rendered")
+}
+
+func TestUpdateHCLFailedResources(t *testing.T) {
+	src := "resource \"onelogin_apps\" \"broken\" {\n}\n"
+	state := State{Resources: []StateResource{{
+		Name:      "broken",
+		Type:      "onelogin_apps",
+		Instances: []ResourceInstance{{Data: map[string]interface{}{"name": "Broken", "connector_id": []interface{}{1}}}},
+	}}}
+
+	actual, err := UpdateHCL([]byte(src), "main.tf", state, Options{Schemas: failingSchemas()}, []string{"onelogin_apps.broken"})
+
+	assert.IsType(t, &RenderError{}, err)
+	assert.Contains(t, string(actual), "# onelogin_apps.broken could not be rendered")
+	assert.Contains(t, string(actual), "# }\nresource \"onelogin_apps\" \"broken\" {\n}\n", "the imported resource keeps its block")
+}
+
+func TestRedactState(t *testing.T) {
+	data := map[string]interface{}{
+		"name":          "Wiki",
+		"configuration": []interface{}{map[string]interface{}{"client_secret": "s3cret", "redirect_uri": "https://wiki"}},
+		"certificate":   map[string]interface{}{"value": ""},
+	}
+	assert.Equal(t, map[string]interface{}{
+		"name":          "Wiki",
+		"configuration": []interface{}{map[string]interface{}{"client_secret": RedactedPlaceholder, "redirect_uri": "https://wiki"}},
+		"certificate":   map[string]interface{}{"value": ""},
+	}, redactState(data, nil, []string{"configuration.client_secret", "certificate.value"}, ""))
+}
+
+// failingSchemas has a number connector_id, which state holding something else fails to render
+func failingSchemas() *tfschema.ProviderSchemas {
+	schemas, _ := tfschema.Parse([]byte(`{
+		"provider_schemas": {
+			"registry.terraform.io/onelogin/onelogin": {
+				"resource_schemas": {
+					"onelogin_apps": {
+						"block": {
+							"attributes": {
+								"name": {"type": "string", "required": true},
+								"connector_id": {"type": "number", "required": true}
+							},
+							"block_types": {
+								"sso": {
+									"nesting_mode": "list",
+									"block": {"attributes": {"client_secret": {"type": "string", "computed": true}}}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	return schemas
+}
@@ -0,0 +1,244 @@
+package stateparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"sort"
+	"strings"
+)
+
+// HCLToJSON rewrites HCL configuration, e.g. from Render, in terraform's JSON configuration syntax for a .tf.json file.
+// Literal strings are escaped so terraform doesn't read them as templates and references become "${...}" interpolations
+func HCLToJSON(src []byte, filename string) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	c := jsonConverter{src: src}
+	root := &orderedObject{}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		body, err := c.body(block.Body, block.Type)
+		if err != nil {
+			return nil, err
+		}
+		path := append([]string{block.Type}, block.Labels...) // resource "type" "name" is at resource.type.name
+		parent := root
+		for _, key := range path[:len(path)-1] {
+			parent = parent.object(key)
+		}
+		parent.set(path[len(path)-1], body)
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// jsonConverter holds the source so expressions without a JSON equivalent can be written as interpolations
+type jsonConverter struct {
+	src []byte
+}
+
+// body converts attributes and nested blocks to an object, repeated nested blocks to a list of objects
+func (c jsonConverter) body(body *hclsyntax.Body, blockType string) (*orderedObject, error) {
+	items := []hclsyntax.Node{}
+	for _, attribute := range body.Attributes {
+		items = append(items, attribute)
+	}
+	blocks := map[string][]*hclsyntax.Block{}
+	for _, block := range body.Blocks {
+		if blocks[block.Type] == nil {
+			items = append(items, block)
+		}
+		blocks[block.Type] = append(blocks[block.Type], block)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Range().Start.Byte < items[j].Range().Start.Byte })
+
+	out := &orderedObject{}
+	for _, item := range items {
+		switch node := item.(type) {
+		case *hclsyntax.Attribute:
+			var value interface{}
+			var err error
+			switch {
+			case blockType == "lifecycle" && node.Name == "ignore_changes":
+				value, err = c.traversalNames(node.Expr)
+			case (blockType == "resource" || blockType == "data") && node.Name == "provider":
+				value = c.source(node.Expr) // the provider meta-argument is a bare reference like onelogin.emea
+			default:
+				value, err = c.expression(node.Expr)
+			}
+			if err != nil {
+				return nil, err
+			}
+			out.set(node.Name, value)
+		case *hclsyntax.Block:
+			nested := make([]interface{}, len(blocks[node.Type]))
+			for i, block := range blocks[node.Type] {
+				value, err := c.body(block.Body, block.Type)
+				if err != nil {
+					return nil, err
+				}
+				nested[i] = value
+			}
+			if len(nested) == 1 {
+				out.set(node.Type, nested[0])
+			} else {
+				out.set(node.Type, nested)
+			}
+		}
+	}
+	return out, nil
+}
+
+// traversalNames writes the attributes given to ignore_changes as the bare names terraform expects in JSON
+func (c jsonConverter) traversalNames(expr hclsyntax.Expression) (interface{}, error) {
+	tuple, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return c.source(expr), nil // ignore_changes = all
+	}
+	names := make([]interface{}, len(tuple.Exprs))
+	for i, item := range tuple.Exprs {
+		names[i] = c.source(item)
+	}
+	return names, nil
+}
+
+// expression converts an attribute value. Lists and objects are converted item by item so literals stay literal
+func (c jsonConverter) expression(expr hclsyntax.Expression) (interface{}, error) {
+	switch e := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		items := make([]interface{}, len(e.Exprs))
+		for i, item := range e.Exprs {
+			value, err := c.expression(item)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = value
+		}
+		return items, nil
+	case *hclsyntax.ObjectConsExpr:
+		out := &orderedObject{}
+		for _, item := range e.Items {
+			key, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() || key.Type() != cty.String || key.IsNull() {
+				return nil, fmt.Errorf("unsupported object key at %s", item.KeyExpr.Range())
+			}
+			value, err := c.expression(item.ValueExpr)
+			if err != nil {
+				return nil, err
+			}
+			out.set(key.AsString(), value)
+		}
+		return out, nil
+	}
+	if len(expr.Variables()) > 0 {
+		return "${" + c.source(expr) + "}", nil
+	}
+	value, diags := Literal(expr)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return jsonLiteral(value), nil
+}
+
+// source is the expression as written
+func (c jsonConverter) source(expr hclsyntax.Expression) string {
+	r := expr.Range()
+	return string(c.src[r.Start.Byte:r.End.Byte])
+}
+
+// jsonLiteral converts a value without references, escaping template sequences in strings
+func jsonLiteral(value cty.Value) interface{} {
+	if value.IsNull() {
+		return nil
+	}
+	ty := value.Type()
+	switch {
+	case ty == cty.String:
+		return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(value.AsString())
+	case ty == cty.Number:
+		return json.Number(value.AsBigFloat().Text('f', -1))
+	case ty == cty.Bool:
+		return value.True()
+	case ty.IsListType() || ty.IsTupleType() || ty.IsSetType():
+		items := []interface{}{}
+		for it := value.ElementIterator(); it.Next(); {
+			_, element := it.Element()
+			items = append(items, jsonLiteral(element))
+		}
+		return items
+	case ty.IsMapType() || ty.IsObjectType():
+		out := &orderedObject{}
+		for it := value.ElementIterator(); it.Next(); {
+			key, element := it.Element()
+			out.set(key.AsString(), jsonLiteral(element))
+		}
+		return out
+	}
+	return nil
+}
+
+// orderedObject is a JSON object that keeps its keys in the order they were set
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *orderedObject) set(key string, value interface{}) {
+	if o.values == nil {
+		o.values = map[string]interface{}{}
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// object returns the object at key, adding it when missing
+func (o *orderedObject) object(key string) *orderedObject {
+	if existing, ok := o.values[key].(*orderedObject); ok {
+		return existing
+	}
+	child := &orderedObject{}
+	o.set(key, child)
+	return child
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+		if err := encodeJSON(&buffer, key); err != nil {
+			return nil, err
+		}
+		buffer.WriteByte(':')
+		if err := encodeJSON(&buffer, o.values[key]); err != nil {
+			return nil, err
+		}
+	}
+	buffer.WriteByte('}')
+	return buffer.Bytes(), nil
+}
+
+// encodeJSON writes v without escaping HTML characters or a trailing newline
+func encodeJSON(buffer *bytes.Buffer, v interface{}) error {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	buffer.Truncate(buffer.Len() - 1)
+	return nil
+}
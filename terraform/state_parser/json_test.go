@@ -0,0 +1,143 @@
+package stateparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHCLToJSON(t *testing.T) {
+	tests := map[string]struct {
+		HCL      string
+		Expected string
+	}{
+		"It writes blocks keyed by their labels in the order they were written": {
+			HCL: `provider "onelogin" {
+  alias = "onelogin"
+}
+
+resource "onelogin_apps" "wiki" {
+  name         = "Wiki"
+  connector_id = 22
+  visible      = true
+  configuration = {
+    redirect_uri = "https://wiki.example.com"
+  }
+  parameters {
+    param_key_name = "email"
+  }
+  parameters {
+    param_key_name = "name"
+  }
+  lifecycle {
+    ignore_changes = [updated_at]
+  }
+}
+
+resource "onelogin_roles" "engineering" {
+  apps = [onelogin_apps.wiki.id, 40]
+}
+`,
+			Expected: `{
+  "provider": {
+    "onelogin": {
+      "alias": "onelogin"
+    }
+  },
+  "resource": {
+    "onelogin_apps": {
+      "wiki": {
+        "name": "Wiki",
+        "connector_id": 22,
+        "visible": true,
+        "configuration": {
+          "redirect_uri": "https://wiki.example.com"
+        },
+        "parameters": [
+          {
+            "param_key_name": "email"
+          },
+          {
+            "param_key_name": "name"
+          }
+        ],
+        "lifecycle": {
+          "ignore_changes": [
+            "updated_at"
+          ]
+        }
+      }
+    },
+    "onelogin_roles": {
+      "engineering": {
+        "apps": [
+          "${onelogin_apps.wiki.id}",
+          40
+        ]
+      }
+    }
+  }
+}
+`,
+		},
+		"It escapes literal template sequences and keeps variables": {
+			HCL: `resource "onelogin_apps" "wiki" {
+  notes         = "costs $${price} <each>"
+  client_secret = var.onelogin_apps_wiki_client_secret
+}
+`,
+			Expected: `{
+  "resource": {
+    "onelogin_apps": {
+      "wiki": {
+        "notes": "costs $${price} <each>",
+        "client_secret": "${var.onelogin_apps_wiki_client_secret}"
+      }
+    }
+  }
+}
+`,
+		},
+		"It writes the provider meta-argument as a bare reference": {
+			HCL: `resource "onelogin_apps" "emea_wiki" {
+  provider = onelogin.emea
+  name     = "Wiki"
+}
+`,
+			Expected: `{
+  "resource": {
+    "onelogin_apps": {
+      "emea_wiki": {
+        "provider": "onelogin.emea",
+        "name": "Wiki"
+      }
+    }
+  }
+}
+`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := HCLToJSON([]byte(test.HCL), "main.tf")
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, string(actual))
+		})
+	}
+}
+
+func TestHCLToJSONRendered(t *testing.T) {
+	state := State{
+		Resources: []StateResource{
+			StateResource{
+				Name:      "wiki",
+				Type:      "onelogin_apps",
+				Instances: []ResourceInstance{ResourceInstance{Data: map[string]interface{}{"description": "line1\nline2 ${x}"}}},
+			},
+		},
+	}
+	rendered, err := Render(state, Options{})
+	assert.Nil(t, err)
+	actual, err := HCLToJSON(rendered, "main.tf")
+	assert.Nil(t, err)
+	assert.Contains(t, string(actual), `"description": "line1\nline2 $${x}"`, "a heredoc without a trailing newline is written as its string")
+}
@@ -0,0 +1,263 @@
+package stateparser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// legacyResource is a resource of a version 3 state, keyed by its address in the module e.g. onelogin_roles.admins,
+// onelogin_roles.admins.1 for the second instance of a count, or data.onelogin_roles.admins
+type legacyResource struct {
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+	Primary  struct {
+		ID         string            `json:"id"`
+		Attributes map[string]string `json:"attributes"` // flattened e.g. apps.# = 2, apps.0 = 12, apps.1 = 5
+	} `json:"primary"`
+}
+
+// decodeModules decodes the modules of a version 3 state a module at a time, handing each resource to each in the
+// layout of version 4
+func decodeModules(decoder *json.Decoder, each func(StateResource) error) error {
+	if err := expectDelim(decoder, '['); err != nil {
+		return err
+	}
+	for decoder.More() {
+		if err := decodeModule(decoder, each); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+// decodeModule decodes a module, handing each its resources once all of them are read, as the instances of a counted
+// resource can be anywhere in the module and its path anywhere around them. The rest of the module is skipped
+func decodeModule(decoder *json.Decoder, each func(StateResource) error) error {
+	if err := expectDelim(decoder, '{'); err != nil {
+		return err
+	}
+	var path []string
+	keys := []string{}
+	resources := []legacyResource{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case "path":
+			if err := decoder.Decode(&path); err != nil {
+				return err
+			}
+		case "resources":
+			if err := expectDelim(decoder, '{'); err != nil {
+				return err
+			}
+			for decoder.More() {
+				key, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				resource := legacyResource{}
+				if err := decoder.Decode(&resource); err != nil {
+					return err
+				}
+				keys = append(keys, fmt.Sprint(key))
+				resources = append(resources, resource)
+			}
+			if err := expectDelim(decoder, '}'); err != nil {
+				return err
+			}
+		default:
+			var skipped json.RawMessage
+			if err := decoder.Decode(&skipped); err != nil {
+				return err
+			}
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return err
+	}
+	grouped, err := groupInstances(keys, resources, path)
+	if err != nil {
+		return err
+	}
+	for _, resource := range grouped {
+		if err := each(resource); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countInstance is an instance of a resource and its index in the resource's count
+type countInstance struct {
+	index    int
+	instance ResourceInstance
+}
+
+// groupInstances converts the resources of a module, the instances of a counted resource grouped under one resource
+// in order of their index as in a version 4 state. Resources keep the order of their first instance
+func groupInstances(keys []string, resources []legacyResource, path []string) ([]StateResource, error) {
+	grouped := []StateResource{}
+	instances := [][]countInstance{}
+	positions := map[string]int{}
+	for i, resource := range resources {
+		converted, index, err := resource.convert(keys[i], path)
+		if err != nil {
+			return nil, err
+		}
+		address := strings.Join([]string{converted.Mode, converted.Type, converted.Name}, ".")
+		position, ok := positions[address]
+		if !ok {
+			position = len(grouped)
+			positions[address] = position
+			grouped = append(grouped, converted)
+			instances = append(instances, nil)
+		}
+		instances[position] = append(instances[position], countInstance{index: index, instance: converted.Instances[0]})
+	}
+	for i := range grouped {
+		sort.SliceStable(instances[i], func(a, b int) bool { return instances[i][a].index < instances[i][b].index })
+		grouped[i].Instances = make([]ResourceInstance, len(instances[i]))
+		for j, counted := range instances[i] {
+			grouped[i].Instances[j] = counted.instance
+		}
+	}
+	return grouped, nil
+}
+
+// convert lays the resource out as in a version 4 state, with its attributes unflattened, returning its index in
+// the resource's count. Resources of child modules are named after the module path too e.g. network_admins for
+// onelogin_roles.admins in module network, so they don't collide with the resources of other modules
+func (r legacyResource) convert(key string, path []string) (StateResource, int, error) {
+	mode := "managed"
+	if strings.HasPrefix(key, "data.") {
+		mode, key = "data", strings.TrimPrefix(key, "data.")
+	}
+	parts := strings.Split(key, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return StateResource{}, 0, fmt.Errorf("unable to read resource %s of the version 3 tfstate: expected type.name or type.name.index", key)
+	}
+	index := 0
+	if len(parts) == 3 {
+		var err error
+		if index, err = strconv.Atoi(parts[2]); err != nil {
+			return StateResource{}, 0, fmt.Errorf("unable to read resource %s of the version 3 tfstate: %s isn't a count index", key, parts[2])
+		}
+	}
+	resourceType := r.Type
+	if resourceType == "" {
+		resourceType = parts[0]
+	}
+	name := parts[1]
+	if len(path) > 1 {
+		name = strings.Join(append(append([]string{}, path[1:]...), name), "_") // the first element is always root
+	}
+	attributes := unflatten(r.Primary.Attributes, "")
+	if _, ok := attributes["id"]; !ok && r.Primary.ID != "" {
+		attributes["id"] = legacyValue(r.Primary.ID)
+	}
+	return StateResource{
+		Mode:      mode,
+		Type:      resourceType,
+		Name:      name,
+		Provider:  r.Provider,
+		Instances: []ResourceInstance{{Data: attributes}},
+	}, index, nil
+}
+
+// unflatten rebuilds the attributes under prefix from their flattened form: lists are counted by name.#, maps by
+// name.%, and their items follow as name.0 or name.key
+func unflatten(flat map[string]string, prefix string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, name := range childNames(flat, prefix) {
+		path := prefix + name
+		if _, ok := flat[path+".#"]; ok {
+			out[name] = unflattenList(flat, path+".")
+		} else if _, ok := flat[path+".%"]; ok {
+			out[name] = unflattenMap(flat, path+".")
+		} else if value, ok := flat[path]; ok {
+			out[name] = legacyValue(value)
+		} else {
+			out[name] = unflatten(flat, path+".")
+		}
+	}
+	return out
+}
+
+// unflattenList rebuilds a list from the items under prefix. Sets are keyed by hashes rather than indexes, so items
+// are ordered by their numeric keys
+func unflattenList(flat map[string]string, prefix string) []interface{} {
+	keys := childNames(flat, prefix)
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, errA := strconv.ParseInt(keys[i], 10, 64)
+		b, errB := strconv.ParseInt(keys[j], 10, 64)
+		return errA == nil && errB == nil && a < b
+	})
+	out := []interface{}{}
+	for _, key := range keys {
+		if key == "#" {
+			continue
+		}
+		if value, ok := flat[prefix+key]; ok {
+			out = append(out, legacyValue(value))
+			continue
+		}
+		out = append(out, unflatten(flat, prefix+key+"."))
+	}
+	return out
+}
+
+// unflattenMap rebuilds a map of strings from the items under prefix. Keys may hold dots, so they run to the end
+func unflattenMap(flat map[string]string, prefix string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for path, value := range flat {
+		if key := strings.TrimPrefix(path, prefix); key != path && key != "%" {
+			out[key] = value
+		}
+	}
+	return out
+}
+
+// childNames are the distinct names directly under prefix, sorted
+func childNames(flat map[string]string, prefix string) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for path := range flat {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		name := strings.SplitN(strings.TrimPrefix(path, prefix), ".", 2)[0]
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// legacyValue types a flattened value: version 3 states write every value as a string, so booleans and numbers are
+// read back as such for the HCL to match version 4 states
+func legacyValue(value string) interface{} {
+	switch value {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(value)))
+	decoder.UseNumber()
+	var number interface{}
+	if err := decoder.Decode(&number); err == nil && !decoder.More() {
+		if n, ok := number.(json.Number); ok && n.String() == value {
+			return n
+		}
+	}
+	return value
+}
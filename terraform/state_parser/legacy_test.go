@@ -0,0 +1,120 @@
+package stateparser
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestUnflatten(t *testing.T) {
+	tests := map[string]struct {
+		Flat     map[string]string
+		Expected map[string]interface{}
+	}{
+		"It types booleans and numbers, leaving other strings alone": {
+			Flat:     map[string]string{"name": "engineering", "enabled": "true", "connector_id": "108419", "code": "0123", "ratio": "1.5", "notes": ""},
+			Expected: map[string]interface{}{"name": "engineering", "enabled": true, "connector_id": json.Number("108419"), "code": "0123", "ratio": json.Number("1.5"), "notes": ""},
+		},
+		"It rebuilds lists in order": {
+			Flat:     map[string]string{"apps.#": "3", "apps.0": "12", "apps.1": "5", "apps.2": "40", "empty.#": "0"},
+			Expected: map[string]interface{}{"apps": []interface{}{json.Number("12"), json.Number("5"), json.Number("40")}, "empty": []interface{}{}},
+		},
+		"It rebuilds sets keyed by hashes": {
+			Flat:     map[string]string{"tags.#": "2", "tags.3512": "b", "tags.98": "a"},
+			Expected: map[string]interface{}{"tags": []interface{}{"a", "b"}},
+		},
+		"It rebuilds maps with dots in their keys": {
+			Flat:     map[string]string{"configuration.%": "2", "configuration.redirect_uri": "https://example.com", "configuration.login.url": "https://example.com/login"},
+			Expected: map[string]interface{}{"configuration": map[string]interface{}{"redirect_uri": "https://example.com", "login.url": "https://example.com/login"}},
+		},
+		"It rebuilds nested blocks": {
+			Flat: map[string]string{
+				"rules.#": "1", "rules.0.name": "admins",
+				"rules.0.actions.#": "1", "rules.0.actions.0.action": "set_role", "rules.0.actions.0.value.#": "1", "rules.0.actions.0.value.0": "7",
+			},
+			Expected: map[string]interface{}{"rules": []interface{}{
+				map[string]interface{}{"name": "admins", "actions": []interface{}{
+					map[string]interface{}{"action": "set_role", "value": []interface{}{json.Number("7")}},
+				}},
+			}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, unflatten(test.Flat, ""))
+		})
+	}
+}
+
+func TestParseEachVersion3(t *testing.T) {
+	input := `{
+		"version": 3,
+		"terraform_version": "0.11.14",
+		"serial": 9,
+		"modules": [
+			{"path": ["root"], "outputs": {}, "resources": {
+				"onelogin_roles.engineering": {"type": "onelogin_roles", "depends_on": [], "primary": {"id": "7", "attributes": {"id": "7", "name": "engineering", "apps.#": "1", "apps.0": "12"}}, "provider": "provider.onelogin"},
+				"onelogin_users.people.1": {"type": "onelogin_users", "primary": {"id": "41", "attributes": {"username": "ann"}}, "provider": "provider.onelogin"},
+				"data.onelogin_saml_apps.wiki": {"type": "onelogin_saml_apps", "primary": {"id": "12", "attributes": {"id": "12", "name": "wiki"}}, "provider": "provider.onelogin"}
+			}},
+			{"path": ["root", "network"], "resources": {}}
+		]
+	}`
+	resources := []StateResource{}
+	state, err := ParseEach(strings.NewReader(input), func(resource StateResource) error {
+		resources = append(resources, resource)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, State{Version: 3, Serial: 9}, state)
+	assert.Equal(t, []StateResource{
+		{Mode: "managed", Type: "onelogin_roles", Name: "engineering", Provider: "provider.onelogin", Instances: []ResourceInstance{{Data: map[string]interface{}{"id": json.Number("7"), "name": "engineering", "apps": []interface{}{json.Number("12")}}}}},
+		{Mode: "managed", Type: "onelogin_users", Name: "people", Provider: "provider.onelogin", Instances: []ResourceInstance{{Data: map[string]interface{}{"id": json.Number("41"), "username": "ann"}}}},
+		{Mode: "data", Type: "onelogin_saml_apps", Name: "wiki", Provider: "provider.onelogin", Instances: []ResourceInstance{{Data: map[string]interface{}{"id": json.Number("12"), "name": "wiki"}}}},
+	}, resources, "in the order of the state, the id filled in from primary when missing from the attributes")
+
+	version4, err := Parse(strings.NewReader(`{"version": 4, "serial": 9, "resources": [
+		{"mode": "managed", "type": "onelogin_roles", "name": "engineering", "provider": "provider.onelogin", "instances": [{"attributes": {"id": 7, "name": "engineering", "apps": [12]}}]},
+		{"mode": "managed", "type": "onelogin_users", "name": "people", "provider": "provider.onelogin", "instances": [{"attributes": {"id": "41", "username": "ann"}}]},
+		{"mode": "data", "type": "onelogin_saml_apps", "name": "wiki", "provider": "provider.onelogin", "instances": [{"attributes": {"id": 12, "name": "wiki"}}]}
+	]}`))
+	assert.Nil(t, err)
+	version3, err := Parse(strings.NewReader(input))
+	assert.Nil(t, err)
+	expected, err := Render(version4, Options{})
+	assert.Nil(t, err)
+	actual, err := Render(version3, Options{})
+	assert.Nil(t, err)
+	assert.Equal(t, string(expected), string(actual), "it renders the same configuration as the version 4 state")
+}
+
+func TestParseEachVersion3CountAndModules(t *testing.T) {
+	input := `{
+		"version": 3,
+		"serial": 4,
+		"modules": [
+			{"path": ["root"], "resources": {
+				"onelogin_roles.admins.1": {"type": "onelogin_roles", "primary": {"id": "8", "attributes": {"id": "8", "name": "emea admins"}}, "provider": "provider.onelogin"},
+				"onelogin_roles.admins.0": {"type": "onelogin_roles", "primary": {"id": "7", "attributes": {"id": "7", "name": "admins"}}, "provider": "provider.onelogin"},
+				"onelogin_users.ann": {"type": "onelogin_users", "primary": {"id": "41", "attributes": {"id": "41", "username": "ann"}}, "provider": "provider.onelogin"}
+			}},
+			{"resources": {
+				"onelogin_roles.admins": {"type": "onelogin_roles", "primary": {"id": "9", "attributes": {"id": "9", "name": "network admins"}}, "provider": "provider.onelogin"}
+			}, "path": ["root", "network"]}
+		]
+	}`
+	state, err := Parse(strings.NewReader(input))
+	assert.Nil(t, err)
+	assert.Equal(t, []StateResource{
+		{Mode: "managed", Type: "onelogin_roles", Name: "admins", Provider: "provider.onelogin", Instances: []ResourceInstance{
+			{Data: map[string]interface{}{"id": json.Number("7"), "name": "admins"}},
+			{Data: map[string]interface{}{"id": json.Number("8"), "name": "emea admins"}},
+		}},
+		{Mode: "managed", Type: "onelogin_users", Name: "ann", Provider: "provider.onelogin", Instances: []ResourceInstance{{Data: map[string]interface{}{"id": json.Number("41"), "username": "ann"}}}},
+		{Mode: "managed", Type: "onelogin_roles", Name: "network_admins", Provider: "provider.onelogin", Instances: []ResourceInstance{{Data: map[string]interface{}{"id": json.Number("9"), "name": "network admins"}}}},
+	}, state.Resources, "count instances are one resource in index order, and resources of child modules are named after the module")
+
+	_, err = Parse(strings.NewReader(`{"version": 3, "serial": 1, "modules": [{"path": ["root"], "resources": {"onelogin_roles.admins.first": {"type": "onelogin_roles", "primary": {"id": "7", "attributes": {}}}}}]}`))
+	assert.EqualError(t, err, "unable to read resource onelogin_roles.admins.first of the version 3 tfstate: first isn't a count index")
+}
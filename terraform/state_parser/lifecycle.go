@@ -0,0 +1,53 @@
+package stateparser
+
+import (
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"strings"
+)
+
+// ignoredAttributes lists the attributes of the resource type to ignore changes to: the registered noisy attributes
+// written to the body, as ignoring attributes configuration doesn't set is redundant, unless the defaults are turned off,
+// followed by those given in Options.IgnoreChanges
+func (o Options) ignoredAttributes(resourceType string, body *hclwrite.Body) []string {
+	attributes := []string{}
+	if !o.NoDefaultIgnoreChanges {
+		for _, attribute := range tfimportables.IgnoreChanges(resourceType) {
+			name := strings.SplitN(attribute, ".", 2)[0]
+			if body.GetAttribute(name) != nil || body.FirstMatchingBlock(name, nil) != nil {
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	prefix := resourceType + "."
+	for _, attribute := range o.IgnoreChanges {
+		if strings.HasPrefix(attribute, prefix) && !contains(attributes, strings.TrimPrefix(attribute, prefix)) {
+			attributes = append(attributes, strings.TrimPrefix(attribute, prefix))
+		}
+	}
+	return attributes
+}
+
+// appendLifecycle adds a lifecycle block ignoring changes to the attributes
+func appendLifecycle(body *hclwrite.Body, attributes []string) error {
+	if len(attributes) == 0 {
+		return nil
+	}
+	tokens := hclwrite.Tokens{{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")}}
+	for i, attribute := range attributes {
+		traversal, diags := hclsyntax.ParseTraversalAbs([]byte(attribute), "", hcl.InitialPos)
+		if diags.HasErrors() {
+			return fmt.Errorf("invalid attribute %s to ignore changes to: %s", attribute, diags)
+		}
+		if i > 0 {
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+		}
+		tokens = append(tokens, hclwrite.TokensForTraversal(traversal)...)
+	}
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+	body.AppendNewBlock("lifecycle", nil).Body().SetAttributeRaw("ignore_changes", tokens)
+	return nil
+}
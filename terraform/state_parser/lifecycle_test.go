@@ -0,0 +1,46 @@
+package stateparser
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderIgnoreChanges(t *testing.T) {
+	state := State{
+		Resources: []StateResource{
+			StateResource{
+				Name: "jane",
+				Type: "onelogin_users",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"username": "jane", "comment": "contractor", "invalid_login_attempts": json.Number("2")}},
+				},
+			},
+		},
+	}
+	header := "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\n"
+	tests := map[string]struct {
+		Options        Options
+		ExpectedOutput string
+	}{
+		"it ignores changes to registered noisy attributes that are written": {
+			Options:        Options{},
+			ExpectedOutput: header + "resource \"onelogin_users\" \"jane\" {\n  comment                = \"contractor\"\n  invalid_login_attempts = 2\n  username               = \"jane\"\n  lifecycle {\n    ignore_changes = [invalid_login_attempts]\n  }\n}\n\n",
+		},
+		"it adds attributes given for the resource type": {
+			Options:        Options{IgnoreChanges: []string{"onelogin_users.comment", "onelogin_apps.notes"}},
+			ExpectedOutput: header + "resource \"onelogin_users\" \"jane\" {\n  comment                = \"contractor\"\n  invalid_login_attempts = 2\n  username               = \"jane\"\n  lifecycle {\n    ignore_changes = [invalid_login_attempts, comment]\n  }\n}\n\n",
+		},
+		"it leaves out the defaults when asked": {
+			Options:        Options{NoDefaultIgnoreChanges: true},
+			ExpectedOutput: header + "resource \"onelogin_users\" \"jane\" {\n  comment                = \"contractor\"\n  invalid_login_attempts = 2\n  username               = \"jane\"\n}\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(state, test.Options)
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+		})
+	}
+}
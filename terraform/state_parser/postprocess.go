@@ -0,0 +1,119 @@
+package stateparser
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
+	"os"
+	"os/exec"
+	"text/template"
+)
+
+// PostProcessor transforms the configuration rendered for a resource before it's written, e.g. to add standard tags,
+// an owner comment, or wrap it in a module
+type PostProcessor interface {
+	Process(resource RenderedResource) ([]byte, error)
+}
+
+// RenderedResource is what a post-processor is given: the resource's configuration as rendered, and its attributes
+// from state with secrets redacted unless Options.IncludeSecrets is set
+type RenderedResource struct {
+	Type       string
+	Name       string
+	Address    string
+	HCL        string
+	Attributes map[string]interface{}
+}
+
+// Template is a post-processor writing the resource through a Go template, e.g. "# owner: {{.Attributes.name}}\n{{.HCL}}"
+type Template struct {
+	template *template.Template
+}
+
+// NewTemplate parses the text of a Template
+func NewTemplate(name string, text string) (Template, error) {
+	parsed, err := template.New(name).Parse(text)
+	if err != nil {
+		return Template{}, err
+	}
+	return Template{template: parsed}, nil
+}
+
+// Process executes the template with the resource
+func (t Template) Process(resource RenderedResource) ([]byte, error) {
+	var out bytes.Buffer
+	if err := t.template.Execute(&out, resource); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Command is a post-processor running a program with the resource's configuration on stdin, what it prints replacing
+// the configuration. The resource's type, name, address and id are in its environment as ONELOGIN_RESOURCE_TYPE,
+// ONELOGIN_RESOURCE_NAME, ONELOGIN_RESOURCE_ADDRESS and ONELOGIN_RESOURCE_ID
+type Command struct {
+	Args []string
+	Dir  string // where the program runs, the current directory when empty
+}
+
+// Process runs the program for the resource
+func (c Command) Process(resource RenderedResource) ([]byte, error) {
+	if len(c.Args) == 0 {
+		return nil, fmt.Errorf("no command to run")
+	}
+	var stdout, stderr bytes.Buffer
+	// #nosec G204
+	cmd := exec.Command(c.Args[0], c.Args[1:]...)
+	cmd.Dir = c.Dir
+	cmd.Stdin = bytes.NewReader([]byte(resource.HCL))
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.Env = append(os.Environ(),
+		"ONELOGIN_RESOURCE_TYPE="+resource.Type,
+		"ONELOGIN_RESOURCE_NAME="+resource.Name,
+		"ONELOGIN_RESOURCE_ADDRESS="+resource.Address,
+		fmt.Sprintf("ONELOGIN_RESOURCE_ID=%v", resource.Attributes["id"]),
+	)
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %s %s", c.Args[0], err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return stdout.Bytes(), nil
+}
+
+// renderBlock renders the instance of the resource as a formatted resource block, passed through the post-processor
+// of its type when options have one and preceded by its annotation. What the post-processor returns must be valid HCL
+func renderBlock(resource StateResource, instance ResourceInstance, options Options, addresses addressIndex) ([]byte, error) {
+	file := hclwrite.NewEmptyFile()
+	if err := renderResource(file.Body(), resource, instance, options, addresses); err != nil {
+		return nil, err
+	}
+	content := hclwrite.Format(file.Bytes())
+	address := resource.Type + "." + resource.Name
+	if processor, ok := options.PostProcessors[resource.Type]; ok {
+		attributes, _ := instance.Data.(map[string]interface{})
+		if !options.IncludeSecrets {
+			var block *tfschema.Block
+			if options.Schemas != nil {
+				if schema, _, ok := options.Schemas.Resource(resource.Type); ok {
+					block = &schema.Block
+				}
+			}
+			attributes, _ = redactState(attributes, block, tfimportables.Sensitive(resource.Type), "").(map[string]interface{})
+		}
+		processed, err := processor.Process(RenderedResource{Type: resource.Type, Name: resource.Name, Address: address, HCL: string(content), Attributes: attributes})
+		if err != nil {
+			return nil, fmt.Errorf("unable to post-process %s: %s", address, err)
+		}
+		if _, diags := hclsyntax.ParseConfig(processed, address, hcl.InitialPos); diags.HasErrors() {
+			return nil, fmt.Errorf("post-processing %s returned invalid HCL: %s", address, diags)
+		}
+		content = append(bytes.TrimRight(hclwrite.Format(processed), "\n"), '\n')
+	}
+	if annotation, ok := options.Annotations[address]; ok {
+		content = append([]byte(annotation.Comment()), content...)
+	}
+	return content, nil
+}
@@ -0,0 +1,69 @@
+package stateparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPostProcessors(t *testing.T) {
+	state := State{Resources: []StateResource{{
+		Name:      "wiki",
+		Type:      "onelogin_apps",
+		Instances: []ResourceInstance{{Data: map[string]interface{}{"id": "12", "name": "Wiki", "connector_id": 22}}},
+	}}}
+	owner, _ := NewTemplate("owner", "# owner: platform, id {{.Attributes.id}}\n{{.HCL}}")
+	wrapped, _ := NewTemplate("module", "module \"{{.Name}}\" {\n  source = \"./apps\"\n}\n")
+	broken, _ := NewTemplate("broken", "{{.HCL}}\n}")
+	tests := map[string]struct {
+		PostProcessor PostProcessor
+		Expected      string
+		ExpectedError string
+	}{
+		"It writes the resource through a template": {
+			PostProcessor: owner,
+			Expected:      "# owner: platform, id 12\nresource \"onelogin_apps\" \"wiki\" {\n  connector_id = 22\n  name         = \"Wiki\"\n}\n",
+		},
+		"It replaces the resource with what the template writes": {
+			PostProcessor: wrapped,
+			Expected:      "module \"wiki\" {\n  source = \"./apps\"\n}\n",
+		},
+		"It writes the resource through a command": {
+			PostProcessor: Command{Args: []string{"sh", "-c", "echo \"# $ONELOGIN_RESOURCE_ADDRESS ($ONELOGIN_RESOURCE_ID)\"; cat"}},
+			Expected:      "# onelogin_apps.wiki (12)\nresource \"onelogin_apps\" \"wiki\" {\n",
+		},
+		"It fails the resource when the command fails": {
+			PostProcessor: Command{Args: []string{"sh", "-c", "echo nope >&2; exit 3"}},
+			ExpectedError: "unable to post-process onelogin_apps.wiki: sh: exit status 3 nope",
+		},
+		"It fails the resource when the result isn't HCL": {
+			PostProcessor: broken,
+			ExpectedError: "post-processing onelogin_apps.wiki returned invalid HCL",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(state, Options{RawIDs: true, PostProcessors: map[string]PostProcessor{"onelogin_apps": test.PostProcessor}})
+			if test.ExpectedError != "" {
+				assert.IsType(t, &RenderError{}, err)
+				assert.Contains(t, err.Error(), test.ExpectedError)
+				assert.Contains(t, string(actual), "# resource \"onelogin_apps\" \"wiki\" {")
+				return
+			}
+			assert.Nil(t, err)
+			assert.Contains(t, string(actual), test.Expected)
+		})
+	}
+}
+
+func TestPostProcessorsRedactSecrets(t *testing.T) {
+	state := State{Resources: []StateResource{{
+		Name:      "wiki",
+		Type:      "onelogin_oidc_apps",
+		Instances: []ResourceInstance{{Data: map[string]interface{}{"name": "Wiki", "connector_id": 22, "sso": []interface{}{map[string]interface{}{"client_secret": "hunter2"}}}}},
+	}}}
+	secret, _ := NewTemplate("secret", "# {{range .Attributes.sso}}{{.client_secret}}{{end}}\n{{.HCL}}")
+	actual, err := Render(state, Options{PostProcessors: map[string]PostProcessor{"onelogin_oidc_apps": secret}})
+	assert.Nil(t, err)
+	assert.Contains(t, string(actual), "# "+RedactedPlaceholder+"\n")
+	assert.NotContains(t, string(actual), "hunter2")
+}
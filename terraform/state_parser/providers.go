@@ -0,0 +1,24 @@
+package stateparser
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"regexp"
+)
+
+// the provider of a resource in state configured with an alias e.g. provider["registry.terraform.io/onelogin/onelogin"].emea,
+// or provider.onelogin.emea in state written before terraform 0.13
+var aliasedProvider = regexp.MustCompile(`^provider(?:\["(?:[^"]*/)?([^"/]+)"\]|\.([A-Za-z_][A-Za-z0-9_-]*))\.([A-Za-z_][A-Za-z0-9_-]*)$`)
+
+// ProviderReference is the provider meta-argument of a resource managed by an aliased provider configuration,
+// e.g. onelogin.emea. ok is false for resources using the default configuration
+func ProviderReference(stateProvider string) (hcl.Traversal, bool) {
+	match := aliasedProvider.FindStringSubmatch(stateProvider)
+	if match == nil {
+		return nil, false
+	}
+	name := match[1]
+	if name == "" {
+		name = match[2]
+	}
+	return hcl.Traversal{hcl.TraverseRoot{Name: name}, hcl.TraverseAttr{Name: match[3]}}, true
+}
@@ -0,0 +1,36 @@
+package stateparser
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestProviderReference(t *testing.T) {
+	tests := map[string]struct {
+		Provider   string
+		Expected   hcl.Traversal
+		ExpectedOK bool
+	}{
+		"It references aliased providers by local name and alias": {
+			Provider:   `provider["registry.terraform.io/onelogin/onelogin"].emea`,
+			Expected:   hcl.Traversal{hcl.TraverseRoot{Name: "onelogin"}, hcl.TraverseAttr{Name: "emea"}},
+			ExpectedOK: true,
+		},
+		"It reads provider addresses from before terraform 0.13": {
+			Provider:   `provider.onelogin.emea`,
+			Expected:   hcl.Traversal{hcl.TraverseRoot{Name: "onelogin"}, hcl.TraverseAttr{Name: "emea"}},
+			ExpectedOK: true,
+		},
+		"It skips the default configuration": {
+			Provider: `provider["registry.terraform.io/onelogin/onelogin"]`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			reference, ok := ProviderReference(test.Provider)
+			assert.Equal(t, test.ExpectedOK, ok)
+			assert.Equal(t, test.Expected, reference)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package stateparser
+
+import (
+	"encoding/json"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"strconv"
+)
+
+// addressIndex finds the address of a resource in state by its type and id
+type addressIndex map[string]map[string]string
+
+// indexAddresses indexes every resource instance in state by its type and id
+func indexAddresses(state State) addressIndex {
+	index := addressIndex{}
+	for _, resource := range state.Resources {
+		index.add(resource)
+	}
+	return index
+}
+
+// add indexes the instances of the resource by their ids
+func (index addressIndex) add(resource StateResource) {
+	for _, instance := range resource.Instances {
+		data, ok := instance.Data.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, ok := idString(data["id"])
+		if !ok {
+			continue
+		}
+		if index[resource.Type] == nil {
+			index[resource.Type] = map[string]string{}
+		}
+		address := resource.Type + "." + resource.Name
+		if resource.Mode == "data" {
+			address = "data." + address
+		}
+		if _, managed := index[resource.Type][id]; !managed || resource.Mode != "data" {
+			index[resource.Type][id] = address // prefer the managed resource when both read the same id
+		}
+	}
+}
+
+// lookup finds the address of the resource with the id among the resource types
+func (index addressIndex) lookup(resourceTypes []string, value interface{}) (string, bool) {
+	id, ok := idString(value)
+	if !ok {
+		return "", false
+	}
+	for _, resourceType := range resourceTypes {
+		if address, ok := index[resourceType][id]; ok {
+			return address, true
+		}
+	}
+	return "", false
+}
+
+// referenceTokens renders ids held in the attribute at path as references to the resources they belong to
+// e.g. onelogin_roles.engineering.id. Lists keep ids of resources that aren't managed as literals.
+// Returns false when the attribute isn't a reference or none of its ids belong to a managed resource
+func (r renderer) referenceTokens(path string, value interface{}, siblings map[string]interface{}) (hclwrite.Tokens, bool) {
+	if r.addresses == nil {
+		return nil, false
+	}
+	for _, reference := range tfimportables.References(r.resourceType) {
+		if reference.Path != path || (reference.When != nil && !reference.When(siblings)) {
+			continue
+		}
+		items, isList := value.([]interface{})
+		if !isList {
+			address, ok := r.addresses.lookup(reference.ResourceTypes, value)
+			if !ok {
+				return nil, false
+			}
+			return addressTokens(address), true
+		}
+		found := false
+		tokens := hclwrite.Tokens{{Type: hclsyntax.TokenOBrack, Bytes: []byte("[")}}
+		for i, item := range items {
+			if i > 0 {
+				tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenComma, Bytes: []byte(",")})
+			}
+			if address, ok := r.addresses.lookup(reference.ResourceTypes, item); ok {
+				tokens = append(tokens, addressTokens(address)...)
+				found = true
+			} else {
+				tokens = append(tokens, hclwrite.TokensForValue(toCtyValue(item))...)
+			}
+		}
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrack, Bytes: []byte("]")})
+		return tokens, found
+	}
+	return nil, false
+}
+
+// addressTokens is the traversal to the id of the resource at the address
+func addressTokens(address string) hclwrite.Tokens {
+	traversal, _ := hclsyntax.ParseTraversalAbs([]byte(address+".id"), "", hcl.InitialPos)
+	return hclwrite.TokensForTraversal(traversal)
+}
+
+// idString normalizes ids from state, which may be strings or numbers, for comparison
+func idString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case int:
+		return strconv.Itoa(v), true
+	}
+	return "", false
+}
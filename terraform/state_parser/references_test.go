@@ -0,0 +1,54 @@
+package stateparser
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderReferences(t *testing.T) {
+	state := State{
+		Resources: []StateResource{
+			StateResource{
+				Name: "engineering",
+				Type: "onelogin_roles",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"id": "7", "name": "engineering", "apps": []interface{}{json.Number("12"), json.Number("5")}}},
+				},
+			},
+			StateResource{
+				Name: "wiki",
+				Type: "onelogin_saml_apps",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"id": "12", "name": "wiki", "rules": []interface{}{
+						map[string]interface{}{"name": "roles", "actions": []interface{}{
+							map[string]interface{}{"action": "set_role", "value": []interface{}{"7"}},
+							map[string]interface{}{"action": "set_status", "value": []interface{}{"7"}},
+						}},
+					}}},
+				},
+			},
+		},
+	}
+	header := "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\n"
+	tests := map[string]struct {
+		Options        Options
+		ExpectedOutput string
+	}{
+		"it references resources managed in the same state": {
+			Options:        Options{},
+			ExpectedOutput: header + "resource \"onelogin_roles\" \"engineering\" {\n  apps = [onelogin_saml_apps.wiki.id, 5]\n  name = \"engineering\"\n}\n\nresource \"onelogin_saml_apps\" \"wiki\" {\n  name = \"wiki\"\n  rules {\n    actions {\n      action = \"set_role\"\n      value  = [onelogin_roles.engineering.id]\n    }\n    actions {\n      action = \"set_status\"\n      value  = [\"7\"]\n    }\n    name = \"roles\"\n  }\n}\n\n",
+		},
+		"it writes raw ids when asked": {
+			Options:        Options{RawIDs: true},
+			ExpectedOutput: header + "resource \"onelogin_roles\" \"engineering\" {\n  apps = [12, 5]\n  name = \"engineering\"\n}\n\nresource \"onelogin_saml_apps\" \"wiki\" {\n  name = \"wiki\"\n  rules {\n    actions {\n      action = \"set_role\"\n      value  = [\"7\"]\n    }\n    actions {\n      action = \"set_status\"\n      value  = [\"7\"]\n    }\n    name = \"roles\"\n  }\n}\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(state, test.Options)
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package stateparser
+
+import (
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/onelogin/onelogin/terraform/importables"
+)
+
+// FromRemote builds state in memory from the data fetched from the remote so it can be rendered without
+// terraform having imported anything. Resource names are used as given and ids are the ones terraform would import by
+func FromRemote(resources []tfimportables.ResourceDefinition) (State, error) {
+	state := State{Resources: make([]StateResource, len(resources))}
+	for i, resource := range resources {
+		attributes, err := remoteAttributes(resource.Remote)
+		if err != nil {
+			return State{}, fmt.Errorf("unable to read remote data for %s.%s: %s", resource.Type, resource.Name, err)
+		}
+		attributes["id"] = resource.ImportID
+		state.Resources[i] = StateResource{
+			Mode:      "managed",
+			Type:      resource.Type,
+			Name:      resource.Name,
+			Instances: []ResourceInstance{{Data: attributes}},
+		}
+	}
+	return state, nil
+}
+
+// Refreshed pairs the resources managed in state with those fetched from the remote by type, provider alias and id,
+// and builds state holding the remote data under the managed resources' names and providers, so their configuration
+// can be rendered again with UpdateHCL without touching the workspace's state. Managed resources of the types fetched
+// that the remote no longer has are listed in missing by address
+func Refreshed(managed State, resources []tfimportables.ResourceDefinition) (refreshed State, missing []string, err error) {
+	remote := map[string]tfimportables.ResourceDefinition{}
+	types := map[string]bool{}
+	for _, resource := range resources {
+		remote[resource.Type+"/"+resource.ProviderAlias+"/"+resource.ImportID] = resource
+		types[resource.Type] = true
+	}
+	refreshed.Resources, missing = []StateResource{}, []string{}
+	for _, resource := range managed.Resources {
+		if resource.Mode == "data" || !types[resource.Type] || len(resource.Instances) == 0 {
+			continue
+		}
+		alias := ""
+		if reference, ok := ProviderReference(resource.Provider); ok {
+			alias = reference[1].(hcl.TraverseAttr).Name
+		}
+		data, _ := resource.Instances[0].Data.(map[string]interface{})
+		definition, ok := remote[resource.Type+"/"+alias+"/"+fmt.Sprint(data["id"])]
+		if !ok {
+			missing = append(missing, resource.Type+"."+resource.Name)
+			continue
+		}
+		definition.Name = resource.Name
+		fresh, err := FromRemote([]tfimportables.ResourceDefinition{definition})
+		if err != nil {
+			return State{}, nil, err
+		}
+		fresh.Resources[0].Provider = resource.Provider
+		refreshed.Resources = append(refreshed.Resources, fresh.Resources[0])
+	}
+	return refreshed, missing, nil
+}
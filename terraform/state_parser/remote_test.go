@@ -0,0 +1,74 @@
+package stateparser
+
+import (
+	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/apps"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/roles"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFromRemote(t *testing.T) {
+	tests := map[string]struct {
+		Resources []tfimportables.ResourceDefinition
+		Expected  string
+	}{
+		"It renders remote data without state": {
+			Resources: []tfimportables.ResourceDefinition{
+				tfimportables.ResourceDefinition{Type: "onelogin_apps", Name: "wiki", ImportID: "12", Remote: apps.App{ID: oltypes.Int32(12), Name: oltypes.String("Wiki"), ConnectorID: oltypes.Int32(22)}},
+				tfimportables.ResourceDefinition{Type: "onelogin_roles", Name: "engineering", ImportID: "3", Remote: roles.Role{ID: oltypes.Int32(3), Name: oltypes.String("Engineering"), Apps: []int32{12, 40}}},
+			},
+			Expected: `resource "onelogin_apps" "wiki" {
+  connector_id = 22
+  name         = "Wiki"
+}
+
+resource "onelogin_roles" "engineering" {
+  apps = [onelogin_apps.wiki.id, 40]
+  name = "Engineering"
+}
+`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state, err := FromRemote(test.Resources)
+			assert.Nil(t, err)
+			actual, err := Render(state, Options{})
+			assert.Nil(t, err)
+			assert.Contains(t, string(actual), test.Expected)
+		})
+	}
+}
+
+func TestRefreshed(t *testing.T) {
+	managed := State{Resources: []StateResource{
+		{Mode: "managed", Type: "onelogin_apps", Name: "_wiki_1", Provider: `provider["registry.terraform.io/onelogin/onelogin"]`, Instances: []ResourceInstance{{Data: map[string]interface{}{"id": "12", "name": "Old Wiki"}}}},
+		{Mode: "managed", Type: "onelogin_apps", Name: "emea_wiki", Provider: `provider["registry.terraform.io/onelogin/onelogin"].emea`, Instances: []ResourceInstance{{Data: map[string]interface{}{"id": "12"}}}},
+		{Mode: "managed", Type: "onelogin_apps", Name: "gone", Instances: []ResourceInstance{{Data: map[string]interface{}{"id": "99"}}}},
+		{Mode: "managed", Type: "onelogin_roles", Name: "admins", Instances: []ResourceInstance{{Data: map[string]interface{}{"id": "3"}}}},
+		{Mode: "data", Type: "onelogin_apps", Name: "shared", Instances: []ResourceInstance{{Data: map[string]interface{}{"id": "40"}}}},
+	}}
+	resources := []tfimportables.ResourceDefinition{
+		{Type: "onelogin_apps", Name: "wiki", ImportID: "12", Remote: apps.App{ID: oltypes.Int32(12), Name: oltypes.String("Wiki"), ConnectorID: oltypes.Int32(22)}},
+		{Type: "onelogin_apps", Name: "wiki", ImportID: "12", ProviderAlias: "emea", Remote: apps.App{ID: oltypes.Int32(12), Name: oltypes.String("EMEA Wiki"), ConnectorID: oltypes.Int32(22)}},
+		{Type: "onelogin_apps", Name: "new", ImportID: "13", Remote: apps.App{ID: oltypes.Int32(13), Name: oltypes.String("New")}},
+	}
+
+	refreshed, missing, err := Refreshed(managed, resources)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"onelogin_apps.gone"}, missing, "only managed resources of the types fetched are looked for")
+	assert.Len(t, refreshed.Resources, 2, "resources not managed yet are left out")
+	assert.Equal(t, "_wiki_1", refreshed.Resources[0].Name)
+	assert.Equal(t, "Wiki", refreshed.Resources[0].Instances[0].Data.(map[string]interface{})["name"])
+	assert.Equal(t, "emea_wiki", refreshed.Resources[1].Name)
+	assert.Equal(t, `provider["registry.terraform.io/onelogin/onelogin"].emea`, refreshed.Resources[1].Provider)
+	assert.Equal(t, "EMEA Wiki", refreshed.Resources[1].Instances[0].Data.(map[string]interface{})["name"])
+
+	src := "resource \"onelogin_apps\" \"_wiki_1\" {\n  name = \"Old Wiki\"\n}\n"
+	actual, err := UpdateHCL([]byte(src), "main.tf", refreshed, Options{}, []string{"onelogin_apps._wiki_1"})
+	assert.Nil(t, err)
+	assert.Equal(t, "resource \"onelogin_apps\" \"_wiki_1\" {\n  connector_id = 22\n  name         = \"Wiki\"\n}\n", string(actual))
+}
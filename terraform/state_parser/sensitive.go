@@ -0,0 +1,41 @@
+package stateparser
+
+import (
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// RedactedPlaceholder replaces secrets in generated configuration unless Options.IncludeSecrets is set
+const RedactedPlaceholder = "REDACTED"
+
+// sensitive reports whether the attribute at path holds a secret, per the provider schema or the resource type's registry
+func (r renderer) sensitive(path string, schemaSensitive bool) bool {
+	return schemaSensitive || contains(tfimportables.Sensitive(r.resourceType), path)
+}
+
+// substituteTokens renders attributes whose value isn't written as is. Secrets and environment specific values are
+// referenced as variables when extracting them, otherwise secrets are replaced with RedactedPlaceholder.
+// Returns true with nil tokens when a secret has no placeholder of its type and is left out
+func (r renderer) substituteTokens(path string, value cty.Value, schemaSensitive bool) (hclwrite.Tokens, bool) {
+	sensitive := r.sensitive(path, schemaSensitive)
+	if tokens, ok := r.variableTokens(path, value, sensitive); ok {
+		return tokens, true
+	}
+	if !sensitive || r.options.IncludeSecrets || isEmpty(value) {
+		return nil, false
+	}
+	if value.Type() == cty.String {
+		return hclwrite.TokensForValue(cty.StringVal(RedactedPlaceholder)), true
+	}
+	return nil, true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
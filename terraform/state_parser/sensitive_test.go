@@ -0,0 +1,35 @@
+package stateparser
+
+import (
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestConvertToHCLBodyRedactsSecrets(t *testing.T) {
+	input := map[string]interface{}{
+		"name":          "portal",
+		"configuration": map[string]interface{}{"client_secret": "s3cr3t", "login_url": "https://example.com"},
+		"sso":           map[string]interface{}{"client_secret": "s3cr3t"},
+	}
+	tests := map[string]struct {
+		Options        Options
+		ExpectedOutput string
+	}{
+		"it redacts registered secrets by default": {
+			Options:        Options{},
+			ExpectedOutput: "configuration = {\n  client_secret = \"REDACTED\"\n  login_url     = \"https://example.com\"\n}\nname = \"portal\"\nsso = {\n  client_secret = \"REDACTED\"\n}\n",
+		},
+		"it writes secrets when they are included": {
+			Options:        Options{IncludeSecrets: true},
+			ExpectedOutput: "configuration = {\n  client_secret = \"s3cr3t\"\n  login_url     = \"https://example.com\"\n}\nname = \"portal\"\nsso = {\n  client_secret = \"s3cr3t\"\n}\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := hclwrite.NewEmptyFile()
+			renderer{options: test.Options, resourceType: "onelogin_oidc_apps"}.convertToHCLBody(input, file.Body(), "")
+			assert.Equal(t, test.ExpectedOutput, string(hclwrite.Format(file.Bytes())))
+		})
+	}
+}
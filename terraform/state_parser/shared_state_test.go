@@ -0,0 +1,36 @@
+package stateparser
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderSharedState(t *testing.T) {
+	tests := map[string]struct {
+		InputState     string
+		ExpectedOutput string
+	}{
+		"it skips resources of other providers and data sources": {
+			InputState: `{
+				"version": 4,
+				"resources": [
+					{"mode": "managed", "type": "random_pet", "name": "server", "provider": "provider[\"registry.terraform.io/hashicorp/random\"]", "instances": [{"attributes": {"id": "wise-owl", "length": 2}}]},
+					{"mode": "managed", "type": "google_storage_bucket", "name": "assets", "provider": "provider[\"registry.terraform.io/hashicorp/google\"]", "instances": [{"attributes": {"id": "assets", "location": "US"}}]},
+					{"mode": "data", "type": "onelogin_roles", "name": "engineering", "provider": "provider[\"registry.terraform.io/onelogin/onelogin\"]", "instances": [{"attributes": {"id": "7", "name": "engineering"}}]},
+					{"mode": "managed", "type": "onelogin_user_mappings", "name": "engineers", "provider": "provider[\"registry.terraform.io/onelogin/onelogin\"]", "instances": [{"attributes": {"id": "5", "name": "engineers", "actions": [{"action": "add_role", "value": ["7"]}]}}]}
+				]
+			}`,
+			ExpectedOutput: "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\nresource \"onelogin_user_mappings\" \"engineers\" {\n  actions {\n    action = \"add_role\"\n    value  = [data.onelogin_roles.engineering.id]\n  }\n  name = \"engineers\"\n}\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var state State
+			assert.Nil(t, json.Unmarshal([]byte(test.InputState), &state))
+			actual, err := Render(state, Options{})
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+		})
+	}
+}
@@ -1,23 +1,37 @@
+// Package stateparser state_parser.go
+// This module converts terraform state into HCL configuration. Parse reads state as written by `terraform state pull`,
+// Render writes its resources as HCL and UpdateHCL fills them in to existing configuration. Nothing here exits the
+// process so other tools can embed the conversion.
 package stateparser
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/onelogin/onelogin-go-sdk/pkg/utils"
 	"github.com/onelogin/onelogin/terraform/importables"
-	"log"
+	"github.com/onelogin/onelogin/terraform/schema"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"io"
 	"reflect"
+	"sort"
 	"strings"
 )
 
 // State is the in memory representation of tfstate.
 type State struct {
+	Version   int             `json:"version"` // format of the state, 3 up to Terraform 0.11 and 4 since 0.12
+	Serial    uint64          `json:"serial"`  // incremented by terraform every time state is written
 	Resources []StateResource `json:"resources"`
 }
 
 // Terraform resource representation
 type StateResource struct {
-	Content   []byte
+	Mode      string             `json:"mode"` // managed for resources, data for data sources
 	Name      string             `json:"name"`
 	Type      string             `json:"type"`
 	Provider  string             `json:"provider"`
@@ -29,95 +43,454 @@ type ResourceInstance struct {
 	Data interface{} `json:"attributes"`
 }
 
-// takes the tfstate representations formats them as HCL and writes them to a bytes buffer
-// so it can be flushed into main.tf
-func ConvertTFStateToHCL(state State, importables *tfimportables.ImportableList) []byte {
-	var builder strings.Builder
+// Options tune how state is rendered as HCL
+type Options struct {
+	Schemas                *tfschema.ProviderSchemas          // when given, resources with a schema are rendered from it rather than from their HCLShape
+	KeepEmpties            bool                               // write empty values instead of dropping them, nulls as their schema type's "", [], or {}, or as null
+	KeepEmptyAttributes    []string                           // attributes written even when empty, as resource_type.attribute e.g. onelogin_apps.notes
+	RawIDs                 bool                               // write ids of other managed resources as literals instead of references to those resources
+	Variables              *Variables                         // when given, secrets and environment specific values are extracted into it and referenced as var.name
+	IncludeSecrets         bool                               // write sensitive values as they are instead of redacting them
+	IgnoreChanges          []string                           // attributes to ignore changes to in addition to the registered noisy ones, as resource_type.attribute
+	NoDefaultIgnoreChanges bool                               // don't ignore changes to the registered noisy attributes
+	DataSources            []tfimportables.ResourceDefinition // resources owned elsewhere, written as data blocks looking them up by id
+	PostProcessors         map[string]PostProcessor           // transform the configuration rendered for resources of a type, by type
+	Annotations            map[string]Annotation              // comments written above resources, by address
+}
 
-	log.Println("Assembling main.tf...")
+// keepEmpty decides whether an empty value at the attribute path of the resource type is written
+func (o Options) keepEmpty(resourceType string, path string) bool {
+	if o.KeepEmpties {
+		return true
+	}
+	for _, attribute := range o.KeepEmptyAttributes {
+		if attribute == fmt.Sprintf("%s.%s", resourceType, path) {
+			return true
+		}
+	}
+	return false
+}
 
-	newProvider := "onelogin" // FIXME
-	builder.WriteString(fmt.Sprintf("terraform {\n\trequired_providers {\n\t\t%s = {\n\t\t\tsource = \"%s/%s\"\n\t\t\t}\n\t\t}\n\t}\n\n", newProvider, newProvider, newProvider))
-	builder.WriteString(fmt.Sprintf("provider %s {\n\talias = \"%s\"\n}\n\n", newProvider, newProvider))
+// renderer carries the options and the resource being rendered through the recursive conversion
+type renderer struct {
+	options      Options
+	resourceType string
+	resourceName string
+	addresses    addressIndex // managed resources that ids can be written as references to, nil when references are off
+}
 
+// Parse reads tfstate. Numbers are kept as json.Number so ids and decimals render exactly
+func Parse(r io.Reader) (State, error) {
+	resources := []StateResource{}
+	state, err := ParseEach(r, func(resource StateResource) error {
+		resources = append(resources, resource)
+		return nil
+	})
+	state.Resources = resources
+	return state, err
+}
+
+// flushEvery is how many blocks RenderTo writes between flushes of its output
+const flushEvery = 100
+
+// Render formats the resources in state as HCL, preceded by the provider blocks and any data sources in options.
+// Data sources in state and resources without an importable, e.g. from other providers sharing the state, are left out.
+// The output is canonically formatted the same way terraform fmt would. Resources that can't be rendered are written
+// as comments holding their state, and returned in a *RenderError along with the rest of the configuration
+func Render(state State, options Options) ([]byte, error) {
+	var buffer bytes.Buffer
+	err := RenderTo(&buffer, state, options)
+	if _, partlyRendered := err.(*RenderError); err != nil && !partlyRendered {
+		return nil, err
+	}
+	return buffer.Bytes(), err
+}
+
+// RenderTo writes the configuration Render returns to w a block at a time, flushing every flushEvery blocks, so a
+// large state is never held in memory as HCL all at once. Errors writing to w stop it, while resources that can't be
+// rendered are written as comments and returned in a *RenderError once the rest is written
+func RenderTo(w io.Writer, state State, options Options) error {
+	out := newBlockWriter(w)
+	if err := out.header(options); err != nil {
+		return err
+	}
+	addresses := options.addressIndex(state)
 	for _, resource := range state.Resources {
-		for _, instance := range resource.Instances {
-			builder.WriteString(fmt.Sprintf("resource %s %s {\n", resource.Type, resource.Name))
-			b, _ := json.Marshal(instance.Data)
-			hclShape := importables.GetImportable(resource.Type).HCLShape()
-			json.Unmarshal(b, hclShape)
-			convertToHCLLine(hclShape, 1, &builder)
-			builder.WriteString("}\n\n")
+		if err := out.resource(resource, options, addresses); err != nil {
+			return err
+		}
+	}
+	return out.close()
+}
+
+// addressIndex indexes the resources ids can be written as references to, nil when references are off
+func (o Options) addressIndex(state State) addressIndex {
+	if o.RawIDs {
+		return nil
+	}
+	addresses := indexAddresses(state)
+	addresses.indexDataSources(o.DataSources)
+	return addresses
+}
+
+// renderResource writes the instance of the resource as a resource block to body, from the provider schema when
+// there is one for the resource type and from the importable's HCLShape otherwise
+func renderResource(body *hclwrite.Body, resource StateResource, instance ResourceInstance, options Options, addresses addressIndex) error {
+	var schema *tfschema.Schema
+	if options.Schemas != nil {
+		schema, _, _ = options.Schemas.Resource(resource.Type)
+	}
+	r := renderer{options: options, resourceType: resource.Type, resourceName: resource.Name, addresses: addresses}
+	block := body.AppendNewBlock("resource", []string{resource.Type, resource.Name})
+	if reference, ok := ProviderReference(resource.Provider); ok {
+		block.Body().SetAttributeTraversal("provider", reference)
+	}
+	if schema != nil {
+		if data, ok := instance.Data.(map[string]interface{}); ok {
+			if err := r.convertToHCLBodyFromSchema(data, schema.Block, block.Body(), ""); err != nil {
+				return fmt.Errorf("unable to render %s.%s: %s", resource.Type, resource.Name, err)
+			}
+		}
+	} else {
+		hclShape, ok := tfimportables.HCLShape(resource.Type)
+		if !ok {
+			return fmt.Errorf("unable to render %s.%s: no importable or schema for %s", resource.Type, resource.Name, resource.Type)
+		}
+		data := instance.Data
+		if m, ok := data.(map[string]interface{}); ok {
+			// the resource's own id is computed and left out, as in the schema path. It's a string in state the shape can't hold
+			data = withoutID(m)
 		}
-		builder.WriteString(string(resource.Content))
+		b, _ := json.Marshal(data)
+		json.Unmarshal(b, hclShape)
+		if err := r.convertToHCLBody(hclShape, block.Body(), ""); err != nil {
+			return fmt.Errorf("unable to render %s.%s: %s", resource.Type, resource.Name, err)
+		}
+	}
+	if err := appendLifecycle(block.Body(), options.ignoredAttributes(resource.Type, block.Body())); err != nil {
+		return fmt.Errorf("unable to render %s.%s: %s", resource.Type, resource.Name, err)
 	}
-	return []byte(builder.String())
+	return nil
 }
 
-func indent(level int) []byte {
-	out := make([]byte, level)
-	for i := 0; i < level; i++ {
-		out[i] = byte('\t')
+// copies the attributes of an instance without its top-level id
+func withoutID(data map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != "id" {
+			copied[k] = v
+		}
 	}
-	return out
+	return copied
+}
+
+// AppendProviderBlocks adds the required_providers entry and aliased provider block for a provider
+func AppendProviderBlocks(body *hclwrite.Body, provider string) {
+	requiredProviders := body.AppendNewBlock("terraform", nil).Body().AppendNewBlock("required_providers", nil)
+	requiredProviders.Body().SetAttributeValue(provider, cty.ObjectVal(map[string]cty.Value{
+		"source": cty.StringVal(fmt.Sprintf("%s/%s", provider, provider)),
+	}))
+	body.AppendNewline()
+	body.AppendNewBlock("provider", []string{provider}).Body().SetAttributeValue("alias", cty.StringVal(provider))
+	body.AppendNewline()
+}
+
+// sorted keys so re-running the import doesn't reorder attributes
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joins attribute names into the dotted path used to match KeepEmptyAttributes
+func attributePath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
 }
 
 // recursively converts a chunk of data from it's struct representation to its HCL representation
-// and appends the "line" to a bytes buffer.
-func convertToHCLLine(input interface{}, indentLevel int, builder *strings.Builder) {
+// and writes the attributes and nested blocks to the body. Lists of objects become repeated nested blocks.
+func (r renderer) convertToHCLBody(input interface{}, body *hclwrite.Body, path string) error {
 	b, err := json.Marshal(input)
 	if err != nil {
-		log.Fatalln("unable to parse state to hcl")
+		return err
 	}
 	var m map[string]interface{}
-	json.Unmarshal(b, &m)
-	for k, v := range m {
-		if v != nil {
-			log.Println(v)
-			switch reflect.TypeOf(v).Kind() {
-			case reflect.String:
-				builder.WriteString(fmt.Sprintf("%s%s = %q\n", indent(indentLevel), utils.ToSnakeCase(k), v))
-			case reflect.Int, reflect.Int32, reflect.Float32, reflect.Float64, reflect.Bool:
-				builder.WriteString(fmt.Sprintf("%s%s = %v\n", indent(indentLevel), utils.ToSnakeCase(k), v))
-			case reflect.Array, reflect.Slice:
-				sl := v.([]interface{})
-				if len(sl) > 0 {
-					switch reflect.TypeOf(sl[0]).Kind() { // array of complex stuff
-					case reflect.Array, reflect.Slice, reflect.Map:
-						for j := 0; j < len(sl); j++ {
-							builder.WriteString(strings.ToLower(fmt.Sprintf("\n%s%s {\n", indent(indentLevel), utils.ToSnakeCase(k))))
-							convertToHCLLine(sl[j], indentLevel+1, builder)
-							builder.WriteString(fmt.Sprintf("%s}\n", indent(indentLevel)))
-						}
-					case reflect.Int, reflect.Int32, reflect.Float32, reflect.Float64, reflect.Bool:
-						builder.WriteString(fmt.Sprintf("%s%s = [", indent(indentLevel), utils.ToSnakeCase(k)))
-						for j := 0; j < len(sl); j++ {
-							builder.WriteString(fmt.Sprintf("%0.f", sl[j])) // not really expecting decimal values in terraform but may require a fix later
-							if j < len(sl)-1 {
-								builder.WriteString(", ")
-							}
-						}
-						builder.WriteString("]\n")
-					default: // array of strings
-						builder.WriteString(fmt.Sprintf("%s%s = [", indent(indentLevel), utils.ToSnakeCase(k)))
-						for j := 0; j < len(sl); j++ {
-							builder.WriteString(fmt.Sprintf("%q", sl[j]))
-							if j < len(sl)-1 {
-								builder.WriteString(", ")
-							}
-						}
-						builder.WriteString("]\n")
-					}
+	decodeJSON(b, &m)
+	for _, k := range sortedKeys(m) {
+		v := m[k]
+		name := utils.ToSnakeCase(k) // attribute names follow the schema's naming. Values, including map keys, are user data and left alone
+		keep := r.options.keepEmpty(r.resourceType, attributePath(path, name))
+		if v == nil {
+			if keep {
+				setAttribute(body, name, cty.NullVal(cty.DynamicPseudoType)) // the shape doesn't say what type it would have
+			}
+			continue
+		}
+		if tokens, ok := r.referenceTokens(attributePath(path, name), v, m); ok {
+			body.SetAttributeRaw(name, tokens)
+			continue
+		}
+		if tokens, ok := r.substituteTokens(attributePath(path, name), toCtyValue(v), false); ok {
+			if tokens != nil {
+				body.SetAttributeRaw(name, tokens)
+			}
+			continue
+		}
+		if number, ok := v.(json.Number); ok {
+			setAttribute(body, name, toCtyValue(number))
+			continue
+		}
+		switch reflect.TypeOf(v).Kind() {
+		case reflect.Array, reflect.Slice:
+			sl := v.([]interface{})
+			if len(sl) == 0 {
+				if keep {
+					setAttribute(body, name, cty.EmptyTupleVal)
 				}
-			case reflect.Map:
-				if len(v.(map[string]interface{})) > 0 {
-					builder.WriteString(strings.ToLower(fmt.Sprintf("\n%s%s = {\n", indent(indentLevel), utils.ToSnakeCase(k))))
-					convertToHCLLine(v, indentLevel+1, builder)
-					builder.WriteString(fmt.Sprintf("%s}\n", indent(indentLevel)))
+				continue
+			}
+			switch reflect.TypeOf(sl[0]).Kind() {
+			case reflect.Array, reflect.Slice, reflect.Map: // array of complex stuff
+				for _, item := range sl {
+					if err := r.convertToHCLBody(item, body.AppendNewBlock(name, nil).Body(), attributePath(path, name)); err != nil {
+						return err
+					}
 				}
 			default:
-				fmt.Println("Unable to Determine Type", k, v)
+				setAttribute(body, name, toCtyValue(v))
+			}
+		case reflect.Map:
+			if tokens, ok := r.objectTokens(attributePath(path, name), v.(map[string]interface{})); ok {
+				body.SetAttributeRaw(name, tokens)
+				continue
+			}
+			if len(v.(map[string]interface{})) > 0 || keep {
+				setAttribute(body, name, toCtyValue(v))
+			}
+		case reflect.String:
+			if v.(string) != "" || keep {
+				setAttribute(body, name, toCtyValue(v))
+			}
+		case reflect.Float64, reflect.Bool:
+			setAttribute(body, name, toCtyValue(v))
+		default:
+			return fmt.Errorf("unable to determine the type of %s", attributePath(path, name))
+		}
+	}
+	return nil
+}
+
+// writes the settable attributes and nested blocks the schema declares, typed per the schema.
+// Computed-only attributes are skipped as they can't be set in configuration. Required attributes are
+// always written, as their type's empty value if they have none
+func (r renderer) convertToHCLBodyFromSchema(data map[string]interface{}, block tfschema.Block, body *hclwrite.Body, path string) error {
+	attributeNames := make([]string, 0, len(block.Attributes))
+	for name, attribute := range block.Attributes {
+		if name != "id" && attribute.Settable() {
+			attributeNames = append(attributeNames, name)
+		}
+	}
+	sort.Strings(attributeNames)
+	for _, name := range attributeNames {
+		attribute := block.Attributes[name]
+		if tokens, ok := r.referenceTokens(attributePath(path, name), data[name], data); ok {
+			body.SetAttributeRaw(name, tokens)
+			continue
+		}
+		value, err := attribute.Value(data[name])
+		if err != nil {
+			return fmt.Errorf("%s: %s", name, err)
+		}
+		if tokens, ok := r.substituteTokens(attributePath(path, name), value, attribute.Sensitive); ok {
+			if tokens != nil {
+				body.SetAttributeRaw(name, tokens)
 			}
+			continue
+		}
+		if isEmpty(value) {
+			if !attribute.Required && !r.options.keepEmpty(r.resourceType, attributePath(path, name)) {
+				continue
+			}
+			if empty, err := emptyValue(value.Type()); err == nil {
+				value = empty
+			} // numbers and bools have no empty value and stay null
+		}
+		setAttribute(body, name, value)
+	}
+
+	blockNames := make([]string, 0, len(block.BlockTypes))
+	for name := range block.BlockTypes {
+		blockNames = append(blockNames, name)
+	}
+	sort.Strings(blockNames)
+	for _, name := range blockNames {
+		nested := block.BlockTypes[name]
+		var items []interface{}
+		switch v := data[name].(type) {
+		case []interface{}:
+			items = v
+		case map[string]interface{}:
+			items = []interface{}{v}
+		}
+		for _, item := range items {
+			itemData, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := r.convertToHCLBodyFromSchema(itemData, nested.Block, body.AppendNewBlock(name, nil).Body(), attributePath(path, name)); err != nil {
+				return fmt.Errorf("%s.%s", name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// isEmpty reports whether the value is null or an empty string or collection
+func isEmpty(value cty.Value) bool {
+	if value.IsNull() {
+		return true
+	}
+	ty := value.Type()
+	switch {
+	case ty == cty.String:
+		return value.AsString() == ""
+	case ty.IsListType(), ty.IsSetType(), ty.IsMapType(), ty.IsTupleType():
+		return value.LengthInt() == 0
+	}
+	return false
+}
+
+// emptyValue is the "", [], or {} of the type
+func emptyValue(ty cty.Type) (cty.Value, error) {
+	switch {
+	case ty == cty.String:
+		return cty.StringVal(""), nil
+	case ty.IsListType():
+		return cty.ListValEmpty(ty.ElementType()), nil
+	case ty.IsSetType():
+		return cty.SetValEmpty(ty.ElementType()), nil
+	case ty.IsMapType():
+		return cty.MapValEmpty(ty.ElementType()), nil
+	case ty.IsObjectType():
+		return cty.EmptyObjectVal, nil
+	}
+	return cty.NilVal, fmt.Errorf("no empty value for %s", ty.FriendlyName())
+}
+
+// setAttribute writes the attribute, using a heredoc for multi-line strings like certificates and
+// policy documents so they stay readable. Everything else is written as an escaped literal
+func setAttribute(body *hclwrite.Body, name string, value cty.Value) {
+	if value.IsKnown() && !value.IsNull() && value.Type() == cty.String {
+		if s := value.AsString(); strings.Contains(s, "\n") && !strings.Contains(s, "\r") {
+			body.SetAttributeRaw(name, heredocTokens(s))
+			return
+		}
+	}
+	body.SetAttributeValue(name, value)
+}
+
+// heredocTokens renders s as a heredoc. Heredocs always end in a newline, so values
+// without a trailing newline are wrapped in chomp() to round trip exactly
+func heredocTokens(s string) hclwrite.Tokens {
+	delimiter := "EOT"
+	for i := 1; closesHeredoc(s, delimiter); i++ {
+		delimiter = fmt.Sprintf("EOT%d", i)
+	}
+	content := strings.NewReplacer("${", "$${", "%{", "%%{").Replace(s)
+	chomp := !strings.HasSuffix(content, "\n")
+	if chomp {
+		content += "\n"
+	}
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOHeredoc, Bytes: []byte("<<" + delimiter + "\n")},
+		{Type: hclsyntax.TokenStringLit, Bytes: []byte(content)},
+		{Type: hclsyntax.TokenCHeredoc, Bytes: []byte(delimiter)},
+	}
+	if chomp {
+		tokens = append(hclwrite.Tokens{
+			{Type: hclsyntax.TokenIdent, Bytes: []byte("chomp")},
+			{Type: hclsyntax.TokenOParen, Bytes: []byte("(")},
+		}, tokens...)
+		tokens = append(tokens,
+			&hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")}, // the closing marker must end its line
+			&hclwrite.Token{Type: hclsyntax.TokenCParen, Bytes: []byte(")")},
+		)
+	}
+	return tokens
+}
+
+// chompFunc is terraform's chomp, which heredocTokens wraps values without a trailing newline in
+var chompFunc = function.New(&function.Spec{
+	Params: []function.Parameter{{Name: "str", Type: cty.String}},
+	Type:   function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		return cty.StringVal(strings.TrimRight(args[0].AsString(), "\r\n")), nil
+	},
+})
+
+// Literal evaluates an expression without references the way terraform would, including the chomp() Render wraps
+// heredocs without a trailing newline in, so configuration it wrote can be converted to other languages
+func Literal(expr hcl.Expression) (cty.Value, hcl.Diagnostics) {
+	return expr.Value(&hcl.EvalContext{Functions: map[string]function.Function{"chomp": chompFunc}})
+}
+
+// closesHeredoc reports whether a line of s would end a heredoc with the delimiter, which HCL allows to be indented
+func closesHeredoc(s string, delimiter string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == delimiter {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeJSON unmarshals keeping numbers as json.Number so large ids and decimals aren't
+// pushed through float64 and mangled on the way to HCL
+func decodeJSON(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// converts decoded json data to the equivalent cty value so hclwrite can render it
+func toCtyValue(v interface{}) cty.Value {
+	switch t := v.(type) {
+	case string:
+		return cty.StringVal(t)
+	case json.Number:
+		if number, err := cty.ParseNumberVal(t.String()); err == nil {
+			return number
+		}
+		return cty.StringVal(t.String())
+	case float64:
+		return cty.NumberFloatVal(t)
+	case bool:
+		return cty.BoolVal(t)
+	case []interface{}:
+		if len(t) == 0 {
+			return cty.EmptyTupleVal
+		}
+		values := make([]cty.Value, len(t))
+		for i, item := range t {
+			values[i] = toCtyValue(item)
+		}
+		return cty.TupleVal(values)
+	case map[string]interface{}:
+		if len(t) == 0 {
+			return cty.EmptyObjectVal
+		}
+		values := map[string]cty.Value{}
+		for k, item := range t {
+			values[k] = toCtyValue(item) // map keys are data e.g. SAML attribute names, so their case is kept
 		}
+		return cty.ObjectVal(values)
+	default:
+		return cty.NullVal(cty.DynamicPseudoType)
 	}
 }
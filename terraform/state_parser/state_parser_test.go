@@ -1,15 +1,17 @@
 package stateparser
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-
-	"github.com/onelogin/onelogin/clients"
-	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
 )
 
-func TestConvertTFStateToHCL(t *testing.T) {
+func TestRender(t *testing.T) {
 	tests := map[string]struct {
 		InputState     State
 		ExpectedOutput string
@@ -74,20 +76,307 @@ func TestConvertTFStateToHCL(t *testing.T) {
 					},
 				},
 			},
-			ExpectedOutput: fmt.Sprintf("provider onelogin {\n\talias = \"onelogin\"\n}\n\nresource onelogin_apps test_resource {\n\tprovider = onelogin\n\n\tprovisioning = {\n\t\tenabled = true\n\t}\n\n\trules {\n\n\t\tactions {\n\t\t\tvalue = [\"member_of\", \"asdf\"]\n\t\t}\n\t}\n\tconnector_id = 22\n\tname = \"test\"\n\n\tconfiguration = {\n\t\tprovider_arn = \"arn\"\n\t\tsignature_algorithm = \"sha-256\"\n\t}\n}\n\nresource onelogin_roles test_resource {\n\tprovider = onelogin\n\tname = \"test\"\n\tapps = [1, 2, 3]\n}\n\nresource onelogin_users test_resource {\n\tprovider = onelogin\n\tusername = \"test\"\n\temail = \"test@test.test\"\n}\n\nprovider aws {\n\talias = \"aws\"\n}\n\nresource aws_iam_user test_resource {\n\tprovider = aws\n\tpath = \"/\"\n}\n\n"),
+			ExpectedOutput: "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\nresource \"onelogin_apps\" \"test_resource\" {\n  configuration = {\n    provider_arn        = \"arn\"\n    signature_algorithm = \"sha-256\"\n  }\n  connector_id = 22\n  name         = \"test\"\n  provisioning = {\n    enabled = true\n  }\n  rules {\n    actions {\n      value = [\"member_of\", \"asdf\"]\n    }\n  }\n}\n\nresource \"onelogin_roles\" \"test_resource\" {\n  apps = [1, 2, 3]\n  name = \"test\"\n}\n\nresource \"onelogin_users\" \"test_resource\" {\n  email    = \"test@test.test\"\n  username = \"test\"\n}\n\nresource \"aws_iam_user\" \"test_resource\" {\n  path = \"/\"\n}\n\n",
+		},
+		"it leaves out the resource's own id but keeps nested ones": {
+			InputState: State{
+				Resources: []StateResource{
+					StateResource{
+						Name:     "test_resource",
+						Type:     "onelogin_apps",
+						Provider: "provider.onelogin",
+						Instances: []ResourceInstance{
+							ResourceInstance{
+								Data: map[string]interface{}{
+									"id":         "7",
+									"name":       "test",
+									"parameters": []map[string]interface{}{{"id": 3, "label": "email"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			ExpectedOutput: "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\nresource \"onelogin_apps\" \"test_resource\" {\n  name = \"test\"\n  parameters {\n    id    = 3\n    label = \"email\"\n  }\n}\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(test.InputState, Options{})
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+		})
+	}
+}
+
+// recordingWriter records the size of each write, failing those after FailAfter writes when it is set
+type recordingWriter struct {
+	Writes    []int
+	FailAfter int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.FailAfter > 0 && len(w.Writes) >= w.FailAfter {
+		return 0, errors.New("disk full")
+	}
+	w.Writes = append(w.Writes, len(p))
+	return len(p), nil
+}
+
+func TestRenderTo(t *testing.T) {
+	state := State{}
+	for i := 0; i < 2*flushEvery+10; i++ {
+		state.Resources = append(state.Resources, StateResource{
+			Name:      fmt.Sprintf("role_%d", i),
+			Type:      "onelogin_roles",
+			Instances: []ResourceInstance{{Data: map[string]interface{}{"name": strings.Repeat("r", 100)}}},
+		})
+	}
+	rendered, err := Render(state, Options{})
+	assert.Nil(t, err)
+
+	var buffer bytes.Buffer
+	assert.Nil(t, RenderTo(&buffer, state, Options{}))
+	assert.Equal(t, string(rendered), buffer.String(), "it writes what Render returns")
+
+	writer := &recordingWriter{}
+	assert.Nil(t, RenderTo(writer, state, Options{}))
+	assert.True(t, len(writer.Writes) >= 3, "it flushes as it goes rather than once at the end")
+	for _, size := range writer.Writes {
+		assert.True(t, size < len(rendered)/2, "it never holds most of the configuration at once")
+	}
+
+	assert.EqualError(t, RenderTo(&recordingWriter{FailAfter: 1}, state, Options{}), "disk full")
+}
+
+func TestRenderFromSchema(t *testing.T) {
+	schemas, _ := tfschema.Parse([]byte(`{
+		"provider_schemas": {
+			"registry.terraform.io/onelogin/onelogin": {
+				"resource_schemas": {
+					"onelogin_apps": {
+						"block": {
+							"attributes": {
+								"id": {"type": "string", "optional": true, "computed": true},
+								"name": {"type": "string", "required": true},
+								"connector_id": {"type": "number", "required": true},
+								"created_at": {"type": "string", "computed": true},
+								"visible": {"type": "bool", "optional": true, "computed": true},
+								"notes": {"type": "string", "optional": true}
+							},
+							"block_types": {
+								"configuration": {
+									"nesting_mode": "list",
+									"max_items": 1,
+									"block": {"attributes": {"signature_algorithm": {"type": "string", "optional": true}}}
+								},
+								"parameters": {
+									"nesting_mode": "set",
+									"block": {"attributes": {"param_key_name": {"type": "string", "required": true}}}
+								}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	tests := map[string]struct {
+		InputState     State
+		ExpectedOutput string
+	}{
+		"it writes settable attributes and nested blocks per the schema": {
+			InputState: State{
+				Resources: []StateResource{
+					StateResource{
+						Name: "test_resource",
+						Type: "onelogin_apps",
+						Instances: []ResourceInstance{
+							ResourceInstance{
+								Data: map[string]interface{}{
+									"id":            "12",
+									"name":          "test",
+									"connector_id":  22.0,
+									"created_at":    "2020-01-01",
+									"visible":       true,
+									"notes":         nil,
+									"configuration": []interface{}{map[string]interface{}{"signature_algorithm": "sha-256"}},
+									"parameters":    []interface{}{map[string]interface{}{"param_key_name": "email"}, map[string]interface{}{"param_key_name": "name"}},
+								},
+							},
+						},
+					},
+				},
+			},
+			ExpectedOutput: "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\nresource \"onelogin_apps\" \"test_resource\" {\n  connector_id = 22\n  name         = \"test\"\n  visible      = true\n  configuration {\n    signature_algorithm = \"sha-256\"\n  }\n  parameters {\n    param_key_name = \"email\"\n  }\n  parameters {\n    param_key_name = \"name\"\n  }\n}\n\n",
+		},
+		"it keeps large numbers exact": {
+			InputState: State{
+				Resources: []StateResource{
+					StateResource{
+						Name: "test_resource",
+						Type: "onelogin_apps",
+						Instances: []ResourceInstance{
+							ResourceInstance{
+								Data: map[string]interface{}{
+									"name":         "test",
+									"connector_id": json.Number("9007199254740993"),
+								},
+							},
+						},
+					},
+				},
+			},
+			ExpectedOutput: "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\nresource \"onelogin_apps\" \"test_resource\" {\n  connector_id = 9007199254740993\n  name         = \"test\"\n}\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(test.InputState, Options{Schemas: schemas})
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+		})
+	}
+}
+
+func TestRenderEmptyValues(t *testing.T) {
+	schemas, _ := tfschema.Parse([]byte(`{
+		"provider_schemas": {
+			"registry.terraform.io/onelogin/onelogin": {
+				"resource_schemas": {
+					"onelogin_roles": {
+						"block": {
+							"attributes": {
+								"name": {"type": "string", "required": true},
+								"notes": {"type": "string", "optional": true},
+								"priority": {"type": "number", "optional": true},
+								"apps": {"type": ["list", "number"], "optional": true},
+								"tags": {"type": ["map", "string"], "optional": true}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	state := State{
+		Resources: []StateResource{
+			StateResource{
+				Name: "test_resource",
+				Type: "onelogin_roles",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"name": nil, "notes": "", "priority": nil, "apps": []interface{}{}, "tags": nil}},
+				},
+			},
+		},
+	}
+	header := "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\n"
+	tests := map[string]struct {
+		Options        Options
+		ExpectedOutput string
+	}{
+		"it omits empties but always writes required attributes": {
+			Options:        Options{Schemas: schemas},
+			ExpectedOutput: header + "resource \"onelogin_roles\" \"test_resource\" {\n  name = \"\"\n}\n\n",
+		},
+		"it keeps every empty value": {
+			Options:        Options{Schemas: schemas, KeepEmpties: true},
+			ExpectedOutput: header + "resource \"onelogin_roles\" \"test_resource\" {\n  apps     = []\n  name     = \"\"\n  notes    = \"\"\n  priority = null\n  tags     = {}\n}\n\n",
+		},
+		"it keeps selected empty attributes": {
+			Options:        Options{Schemas: schemas, KeepEmptyAttributes: []string{"onelogin_roles.apps"}},
+			ExpectedOutput: header + "resource \"onelogin_roles\" \"test_resource\" {\n  apps = []\n  name = \"\"\n}\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(state, test.Options)
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+		})
+	}
+}
+
+func TestRenderNullFromShape(t *testing.T) {
+	state := State{
+		Resources: []StateResource{
+			StateResource{
+				Name:      "test_resource",
+				Type:      "onelogin_users",
+				Instances: []ResourceInstance{ResourceInstance{Data: map[string]interface{}{"username": "test", "phone": nil}}},
+			},
+		},
+	}
+	actual, err := Render(state, Options{KeepEmptyAttributes: []string{"onelogin_users.phone"}})
+	assert.Nil(t, err)
+	assert.Contains(t, string(actual), "resource \"onelogin_users\" \"test_resource\" {\n  phone    = null\n  username = \"test\"\n}\n", "it writes a kept null as null rather than a string")
+}
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		Input         string
+		ExpectedState State
+		ExpectError   bool
+		ExpectedError string
+	}{
+		"it reads resources keeping numbers exact": {
+			Input: `{"version": 4, "serial": 3, "resources": [{"mode": "managed", "type": "onelogin_roles", "name": "engineering", "provider": "provider.onelogin", "instances": [{"attributes": {"id": "7", "apps": [9007199254740993]}}]}]}`,
+			ExpectedState: State{Version: 4, Serial: 3, Resources: []StateResource{
+				StateResource{Mode: "managed", Type: "onelogin_roles", Name: "engineering", Provider: "provider.onelogin", Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"id": "7", "apps": []interface{}{json.Number("9007199254740993")}}},
+				}},
+			}},
+		},
+		"it errors on invalid state": {
+			Input:       `{"resources": [`,
+			ExpectError: true,
+		},
+		"it reads version 3 states": {
+			Input: `{"version": 3, "serial": 2, "modules": [{"path": ["root"], "resources": {"onelogin_roles.engineering": {"type": "onelogin_roles", "provider": "provider.onelogin", "primary": {"id": "7", "attributes": {"id": "7", "name": "engineering"}}}}}]}`,
+			ExpectedState: State{Version: 3, Serial: 2, Resources: []StateResource{
+				StateResource{Mode: "managed", Type: "onelogin_roles", Name: "engineering", Provider: "provider.onelogin", Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"id": json.Number("7"), "name": "engineering"}},
+				}},
+			}},
+		},
+		"it refuses state without a version": {
+			Input:         `{"serial": 1, "resources": []}`,
+			ExpectedError: "tfstate has no version. Read the output of terraform state pull or a terraform.tfstate file, which Terraform writes with one",
+		},
+		"it refuses versions before 3": {
+			Input:         `{"version": 1, "serial": 1, "modules": []}`,
+			ExpectedError: "tfstate version 1 is too old to read, only versions 3 and 4 are. Run terraform refresh with Terraform 0.11 or later to upgrade the state, then try again",
+		},
+		"it refuses versions after 4": {
+			Input:         `{"version": 5, "serial": 1, "resources": []}`,
+			ExpectedError: "tfstate version 5 is newer than this CLI reads, only versions 3 and 4 are. Upgrade the onelogin CLI, or pull the state with a Terraform version writing version 4",
+		},
+		"it refuses a version that isn't a whole number": {
+			Input:         `{"version": 4.5, "resources": []}`,
+			ExpectedError: "tfstate version must be a whole number, found 4.5",
+		},
+		"it refuses a serial that isn't a whole number": {
+			Input:         `{"version": 4, "serial": -1, "resources": []}`,
+			ExpectedError: "tfstate serial must be a whole number, found -1",
+		},
+		"it refuses the layout of another version": {
+			Input:         `{"version": 4, "serial": 1, "modules": []}`,
+			ExpectedError: "tfstate version 4 has modules, which only version 3 states have. Make sure the state wasn't edited by hand",
 		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			clients := clients.New(clients.ClientConfigs{
-				OneLoginClientID:     "ONELOGIN_CLIENT_ID",
-				OneLoginClientSecret: "ONELOGIN_CLIENT_SECRET",
-				OneLoginURL:          "ONELOGIN_OAPI_URL",
-				AwsRegion:            "us-west-2",
-			})
-			importables := tfimportables.New(clients)
-			actual := ConvertTFStateToHCL(test.InputState, importables)
-			assert.Equal(t, len(test.ExpectedOutput), len(string(actual)))
+			state, err := Parse(strings.NewReader(test.Input))
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedState, state)
 		})
 	}
 }
@@ -0,0 +1,229 @@
+package stateparser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"io"
+	"strconv"
+)
+
+// Versions of the tfstate format ParseEach reads. Version 3 is written up to Terraform 0.11, with resources under
+// modules and their attributes flattened to strings, and version 4 since Terraform 0.12
+const (
+	MinStateVersion = 3
+	MaxStateVersion = 4
+)
+
+// ParseEach reads tfstate a resource at a time, handing each to each as soon as it is decoded rather than holding
+// them all, so states of hundreds of megabytes can be read in little memory. The state returned has every field but
+// its resources. An error from each stops the reading. Resources of version 3 states are handed over a module at a
+// time and converted to the layout of version 4, and states of other versions, or without one, are refused with
+// what to do about them
+func ParseEach(r io.Reader, each func(StateResource) error) (State, error) {
+	state := State{}
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	if err := expectDelim(decoder, '{'); err != nil {
+		return state, err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return state, err
+		}
+		switch token {
+		case "version":
+			if state.Version, err = decodeVersion(decoder); err == nil {
+				err = checkVersion(state.Version)
+			}
+		case "serial":
+			state.Serial, err = decodeSerial(decoder)
+		case "resources":
+			if err = checkLayout(state.Version, MaxStateVersion, "resources"); err == nil {
+				err = decodeResources(decoder, each)
+			}
+		case "modules":
+			if err = checkLayout(state.Version, MinStateVersion, "modules"); err == nil {
+				err = decodeModules(decoder, each)
+			}
+		default:
+			var skipped json.RawMessage
+			err = decoder.Decode(&skipped)
+		}
+		if err != nil {
+			return state, err
+		}
+	}
+	if err := expectDelim(decoder, '}'); err != nil {
+		return state, err
+	}
+	if state.Version == 0 {
+		return state, fmt.Errorf("tfstate has no version. Read the output of terraform state pull or a terraform.tfstate file, which Terraform writes with one")
+	}
+	return state, nil
+}
+
+// decodeVersion reads the version of the state format
+func decodeVersion(decoder *json.Decoder) (int, error) {
+	var version json.Number
+	if err := decoder.Decode(&version); err != nil {
+		return 0, fmt.Errorf("tfstate version must be a number: %s", err)
+	}
+	parsed, err := strconv.Atoi(version.String())
+	if err != nil || parsed < 1 {
+		return 0, fmt.Errorf("tfstate version must be a whole number, found %s", version)
+	}
+	return parsed, nil
+}
+
+// checkVersion refuses versions of the state format that aren't read, saying how to get one that is
+func checkVersion(version int) error {
+	switch {
+	case version < MinStateVersion:
+		return fmt.Errorf("tfstate version %d is too old to read, only versions %d and %d are. Run terraform refresh with Terraform 0.11 or later to upgrade the state, then try again", version, MinStateVersion, MaxStateVersion)
+	case version > MaxStateVersion:
+		return fmt.Errorf("tfstate version %d is newer than this CLI reads, only versions %d and %d are. Upgrade the onelogin CLI, or pull the state with a Terraform version writing version %d", version, MinStateVersion, MaxStateVersion, MaxStateVersion)
+	}
+	return nil
+}
+
+// checkLayout refuses a field of one version's layout in a state of another, as when a state was edited by hand.
+// Fields before the version are taken at their word
+func checkLayout(version int, expected int, field string) error {
+	if version != 0 && version != expected {
+		return fmt.Errorf("tfstate version %d has %s, which only version %d states have. Make sure the state wasn't edited by hand", version, field, expected)
+	}
+	return nil
+}
+
+// decodeSerial reads the serial of the state
+func decodeSerial(decoder *json.Decoder) (uint64, error) {
+	var serial json.Number
+	if err := decoder.Decode(&serial); err != nil {
+		return 0, fmt.Errorf("tfstate serial must be a number: %s", err)
+	}
+	parsed, err := strconv.ParseUint(serial.String(), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("tfstate serial must be a whole number, found %s", serial)
+	}
+	return parsed, nil
+}
+
+// decodeResources decodes the resources array an element at a time
+func decodeResources(decoder *json.Decoder, each func(StateResource) error) error {
+	if err := expectDelim(decoder, '['); err != nil {
+		return err
+	}
+	for decoder.More() {
+		resource := StateResource{}
+		if err := decoder.Decode(&resource); err != nil {
+			return err
+		}
+		if err := each(resource); err != nil {
+			return err
+		}
+	}
+	return expectDelim(decoder, ']')
+}
+
+// expectDelim reads the next token, failing unless it is the delimiter
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %s in tfstate, found %v", delim, token)
+	}
+	return nil
+}
+
+// RenderStateTo writes the configuration Render would for the tfstate read from r, decoding, rendering and dropping
+// a resource at a time so neither the state nor its configuration is ever held in memory whole. Unless
+// Options.RawIDs is set, r is read twice, first to find the resources ids can be written as references to
+func RenderStateTo(w io.Writer, r io.ReadSeeker, options Options) error {
+	var addresses addressIndex
+	if !options.RawIDs {
+		addresses = addressIndex{}
+		if _, err := ParseEach(r, func(resource StateResource) error {
+			addresses.add(resource)
+			return nil
+		}); err != nil {
+			return err
+		}
+		addresses.indexDataSources(options.DataSources)
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	out := newBlockWriter(w)
+	if err := out.header(options); err != nil {
+		return err
+	}
+	if _, err := ParseEach(r, func(resource StateResource) error {
+		return out.resource(resource, options, addresses)
+	}); err != nil {
+		return err
+	}
+	return out.close()
+}
+
+// blockWriter writes rendered blocks through a buffer flushed every flushEvery blocks, collecting the resources
+// that couldn't be rendered
+type blockWriter struct {
+	out    *bufio.Writer
+	blocks int
+	failed *RenderError
+}
+
+func newBlockWriter(w io.Writer) *blockWriter {
+	return &blockWriter{out: bufio.NewWriter(w)}
+}
+
+// header writes the provider blocks and the data sources in options
+func (b *blockWriter) header(options Options) error {
+	header := hclwrite.NewEmptyFile()
+	AppendProviderBlocks(header.Body(), "onelogin") // FIXME
+	appendDataSources(header.Body(), options.DataSources)
+	_, err := b.out.Write(hclwrite.Format(header.Bytes()))
+	return err
+}
+
+// resource writes a block for each instance of the resource, or a comment holding its state when it can't be
+// rendered. Data sources and resources without an importable, e.g. from other providers sharing the state, are left
+// out as they are configured elsewhere
+func (b *blockWriter) resource(resource StateResource, options Options, addresses addressIndex) error {
+	if resource.Mode == "data" || !tfimportables.Registered(resource.Type) {
+		return nil
+	}
+	for _, instance := range resource.Instances {
+		content, err := renderBlock(resource, instance, options, addresses)
+		if err != nil {
+			b.failed = b.failed.add(resource, err)
+			content = commentedResource(resource, instance, options, err)
+		}
+		if _, err := b.out.Write(hclwrite.Format(append(content, '\n'))); err != nil {
+			return err
+		}
+		if b.blocks++; b.blocks%flushEvery == 0 {
+			if err := b.out.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// close flushes what's left, returning a *RenderError when resources couldn't be rendered
+func (b *blockWriter) close() error {
+	if err := b.out.Flush(); err != nil {
+		return err
+	}
+	if b.failed != nil {
+		return b.failed
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package stateparser
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// referencesState has a role and an app referring to each other by id, the app after the role
+const referencesState = `{
+	"version": 4,
+	"serial": 12,
+	"outputs": {"role": {"value": "7", "type": "string"}},
+	"resources": [
+		{"mode": "managed", "type": "onelogin_roles", "name": "engineering", "provider": "provider.onelogin", "instances": [{"attributes": {"id": "7", "name": "engineering", "apps": [12, 5]}}]},
+		{"mode": "managed", "type": "random_pet", "name": "server", "provider": "provider.random", "instances": [{"attributes": {"id": "wise-owl"}}]},
+		{"mode": "managed", "type": "onelogin_saml_apps", "name": "wiki", "provider": "provider.onelogin", "instances": [{"attributes": {"id": "12", "name": "wiki"}}]}
+	]
+}`
+
+func TestParseEach(t *testing.T) {
+	tests := map[string]struct {
+		Input          string
+		StopAt         string
+		ExpectedSerial uint64
+		ExpectedNames  []string
+		ExpectedError  string
+	}{
+		"It hands over each resource in order, with the rest of the state": {
+			Input:          referencesState,
+			ExpectedSerial: 12,
+			ExpectedNames:  []string{"engineering", "server", "wiki"},
+		},
+		"It stops at the first error of each": {
+			Input:          referencesState,
+			StopAt:         "server",
+			ExpectedSerial: 12,
+			ExpectedNames:  []string{"engineering", "server"},
+			ExpectedError:  "stopped at server",
+		},
+		"It reads state without resources": {
+			Input:          `{"version": 4, "serial": 1}`,
+			ExpectedSerial: 1,
+			ExpectedNames:  []string{},
+		},
+		"It errors on truncated state, after the resources read": {
+			Input:         `{"resources": [{"name": "engineering"}, {"name": "wi`,
+			ExpectedNames: []string{"engineering"},
+			ExpectedError: "unexpected EOF",
+		},
+		"It errors on state that isn't an object": {
+			Input:         `[]`,
+			ExpectedNames: []string{},
+			ExpectedError: "expected { in tfstate, found [",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			names := []string{}
+			state, err := ParseEach(strings.NewReader(test.Input), func(resource StateResource) error {
+				names = append(names, resource.Name)
+				if resource.Name == test.StopAt {
+					return errors.New("stopped at " + resource.Name)
+				}
+				return nil
+			})
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+			} else {
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.ExpectedSerial, state.Serial)
+			assert.Nil(t, state.Resources)
+			assert.Equal(t, test.ExpectedNames, names)
+		})
+	}
+}
+
+func TestRenderStateTo(t *testing.T) {
+	tests := map[string]Options{
+		"It writes what Render does, references included": {},
+		"It writes what Render does with raw ids":         {RawIDs: true},
+	}
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			state, err := Parse(strings.NewReader(referencesState))
+			assert.Nil(t, err)
+			expected, err := Render(state, options)
+			assert.Nil(t, err)
+
+			var actual bytes.Buffer
+			assert.Nil(t, RenderStateTo(&actual, strings.NewReader(referencesState), options))
+			assert.Equal(t, string(expected), actual.String())
+		})
+	}
+
+	var actual bytes.Buffer
+	assert.Nil(t, RenderStateTo(&actual, strings.NewReader(referencesState), Options{}))
+	assert.Contains(t, actual.String(), "apps = [onelogin_saml_apps.wiki.id, 5]", "it refers to resources later in the state")
+	assert.Error(t, RenderStateTo(&bytes.Buffer{}, strings.NewReader(`{"resources": [`), Options{}))
+}
@@ -0,0 +1,131 @@
+package stateparser
+
+import (
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderStrings(t *testing.T) {
+	tests := map[string]struct {
+		Value            string
+		ExpectedFragment string
+	}{
+		"it escapes quotes": {
+			Value:            `the "best" app`,
+			ExpectedFragment: `description = "the \"best\" app"`,
+		},
+		"it escapes template sequences": {
+			Value:            "costs ${5} and %{if}",
+			ExpectedFragment: `description = "costs $${5} and %%{if}"`,
+		},
+		"it writes certificates as heredocs": {
+			Value:            "-----BEGIN CERTIFICATE-----\nMIIBkTCB+wIJAKHHIG...\n-----END CERTIFICATE-----\n",
+			ExpectedFragment: "description = <<EOT\n-----BEGIN CERTIFICATE-----\nMIIBkTCB+wIJAKHHIG...\n-----END CERTIFICATE-----\nEOT\n",
+		},
+		"it chomps heredocs without a trailing newline": {
+			Value:            "{\n  \"Statement\": [{\"Resource\": \"${aws:username}\"}]\n}",
+			ExpectedFragment: "description = chomp(<<EOT\n{\n  \"Statement\": [{\"Resource\": \"$${aws:username}\"}]\n}\nEOT\n  )",
+		},
+		"it picks a delimiter not in the content": {
+			Value:            "line\nEOT\nline\n",
+			ExpectedFragment: "description = <<EOT1\nline\nEOT\nline\nEOT1\n",
+		},
+		"it picks a delimiter not in the content when indented": {
+			Value:            "line1\n  EOT\nline3",
+			ExpectedFragment: "description = chomp(<<EOT1\nline1\n  EOT\nline3\nEOT1\n  )",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := State{
+				Resources: []StateResource{
+					StateResource{
+						Name:      "test_resource",
+						Type:      "onelogin_apps",
+						Instances: []ResourceInstance{ResourceInstance{Data: map[string]interface{}{"description": test.Value}}},
+					},
+				},
+			}
+			actual, err := Render(state, Options{})
+			assert.Nil(t, err)
+			assert.Contains(t, string(actual), test.ExpectedFragment)
+
+			// and terraform reads back exactly what was in state
+			file, diags := hclparse.NewParser().ParseHCL(actual, "main.tf")
+			assert.False(t, diags.HasErrors(), diags.Error())
+			content, _, _ := file.Body.PartialContent(&hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: "resource", LabelNames: []string{"type", "name"}}}})
+			attributes, _ := content.Blocks[0].Body.JustAttributes()
+			value, diags := Literal(attributes["description"].Expr)
+			assert.False(t, diags.HasErrors(), diags.Error())
+			assert.Equal(t, test.Value, value.AsString())
+		})
+	}
+}
+
+func TestConvertToHCLBodyKeepsMapKeyCase(t *testing.T) {
+	tests := map[string]struct {
+		Input            interface{}
+		ExpectedFragment []string
+	}{
+		"it keeps the case of map keys and values": {
+			Input: map[string]interface{}{
+				"configuration": map[string]interface{}{
+					"FirstName": "User.FirstName",
+					"http://schemas.xmlsoap.org/claims/Group": "MemberOf",
+				},
+			},
+			ExpectedFragment: []string{`FirstName`, `"http://schemas.xmlsoap.org/claims/Group" = "MemberOf"`},
+		},
+		"it keeps the case of values in nested blocks": {
+			Input: map[string]interface{}{
+				"parameters": []interface{}{
+					map[string]interface{}{"param_key_name": "SAML_NameID", "values": map[string]interface{}{"CostCenter": "Dept"}},
+				},
+			},
+			ExpectedFragment: []string{`param_key_name = "SAML_NameID"`, `CostCenter = "Dept"`},
+		},
+		"it normalizes attribute names": {
+			Input:            map[string]interface{}{"ParamKeyName": "Email"},
+			ExpectedFragment: []string{`param_key_name = "Email"`},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			file := hclwrite.NewEmptyFile()
+			renderer{}.convertToHCLBody(test.Input, file.Body(), "")
+			for _, fragment := range test.ExpectedFragment {
+				assert.Contains(t, string(file.Bytes()), fragment)
+			}
+		})
+	}
+}
+
+func TestConvertToHCLBodyNumbers(t *testing.T) {
+	tests := map[string]struct {
+		Input            string
+		ExpectedFragment []string
+	}{
+		"it keeps large ids exact": {
+			Input:            `{"external_id": 9007199254740993, "apps": [12345678901234567, 2]}`,
+			ExpectedFragment: []string{`external_id = 9007199254740993`, `apps        = [12345678901234567, 2]`},
+		},
+		"it keeps decimals": {
+			Input:            `{"ratio": 0.1, "weight": 1.50, "rules": [{"position": 3}]}`,
+			ExpectedFragment: []string{`ratio = 0.1`, `weight = 1.5`, `position = 3`},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var input map[string]interface{}
+			decodeJSON([]byte(test.Input), &input)
+			file := hclwrite.NewEmptyFile()
+			renderer{}.convertToHCLBody(input, file.Body(), "")
+			for _, fragment := range test.ExpectedFragment {
+				assert.Contains(t, string(hclwrite.Format(file.Bytes())), fragment)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package stateparser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// attributes naming a resource in the order they are looked for, e.g. users have no name but a username
+var nameAttributes = []string{"name", "username", "email"}
+
+// ResourceSummary identifies a resource instance in state
+type ResourceSummary struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+}
+
+// Summarize lists the instances of managed resources of the provider in state with their remote ids and names.
+// Data sources and resources of other providers are left out
+func Summarize(state State, provider string) []ResourceSummary {
+	summaries := []ResourceSummary{}
+	for _, resource := range state.Resources {
+		if resource.Mode == "data" || !strings.HasPrefix(resource.Type, provider+"_") {
+			continue
+		}
+		for _, instance := range resource.Instances {
+			attributes, _ := instance.Data.(map[string]interface{})
+			summary := ResourceSummary{Address: resource.Type + "." + resource.Name, Type: resource.Type}
+			if id, ok := idString(attributes["id"]); ok {
+				summary.ID = id
+			}
+			for _, attribute := range nameAttributes {
+				if name, ok := attributes[attribute]; ok && name != nil && name != "" {
+					summary.Name = fmt.Sprint(name)
+					break
+				}
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries
+}
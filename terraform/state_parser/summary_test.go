@@ -0,0 +1,22 @@
+package stateparser
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	state, err := Parse(strings.NewReader(`{"version": 4, "serial": 7, "resources": [
+		{"mode": "managed", "type": "onelogin_apps", "name": "wiki", "instances": [{"attributes": {"id": "12", "name": "Wiki"}}]},
+		{"mode": "managed", "type": "onelogin_users", "name": "jane", "instances": [{"attributes": {"id": 40, "username": "jane", "email": "jane@example.com"}}]},
+		{"mode": "data", "type": "onelogin_roles", "name": "admins", "instances": [{"attributes": {"id": "5", "name": "Admins"}}]},
+		{"mode": "managed", "type": "aws_iam_user", "name": "bob", "instances": [{"attributes": {"id": "bob", "name": "bob"}}]}
+	]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), state.Serial)
+	assert.Equal(t, []ResourceSummary{
+		{Address: "onelogin_apps.wiki", Type: "onelogin_apps", ID: "12", Name: "Wiki"},
+		{Address: "onelogin_users.jane", Type: "onelogin_users", ID: "40", Name: "jane"},
+	}, Summarize(state, "onelogin"))
+}
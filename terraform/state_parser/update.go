@@ -0,0 +1,96 @@
+package stateparser
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"sort"
+)
+
+// a replacement of the bytes between start and end of the source
+type splice struct {
+	start, end int
+	content    []byte
+}
+
+// UpdateHCL rewrites the resource blocks at the given addresses in src, e.g. the placeholders written for an import,
+// with their configuration from state. Data sources in options are appended. Everything else in src, like comments,
+// variables, and backend blocks, is left byte for byte intact. A resource that can't be rendered keeps its block, so
+// terraform doesn't plan to destroy what was imported, preceded by a comment holding its state. Those are returned in
+// a *RenderError along with the rest of the configuration
+func UpdateHCL(src []byte, filename string, state State, options Options, addresses []string) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	resources := map[string]StateResource{}
+	for _, resource := range state.Resources {
+		resources[resource.Type+"."+resource.Name] = resource
+	}
+	index := options.addressIndex(state)
+
+	splices := []splice{}
+	var failed *RenderError
+	for _, address := range addresses {
+		resource, ok := resources[address]
+		if !ok || len(resource.Instances) == 0 {
+			return nil, fmt.Errorf("%s is not in state", address)
+		}
+		block := findResourceBlock(file.Body.(*hclsyntax.Body), resource.Type, resource.Name)
+		if block == nil {
+			return nil, fmt.Errorf("%s is not in %s", address, filename)
+		}
+		rendered, err := renderBlock(resource, resource.Instances[0], options, index)
+		if err != nil {
+			failed = failed.add(resource, err)
+			start := block.Range().Start.Byte
+			splices = append(splices, splice{start: start, end: start, content: commentedResource(resource, resource.Instances[0], options, err)})
+			continue
+		}
+		splices = append(splices, splice{
+			start:   block.Range().Start.Byte,
+			end:     block.Range().End.Byte,
+			content: bytes.TrimRight(rendered, "\n"),
+		})
+	}
+	sort.Slice(splices, func(i, j int) bool { return splices[i].start > splices[j].start }) // back to front so offsets stay valid
+
+	out := append([]byte{}, src...)
+	for _, s := range splices {
+		out = append(out[:s.start], append(s.content, out[s.end:]...)...)
+	}
+	if len(options.DataSources) > 0 {
+		dataSources := hclwrite.NewEmptyFile()
+		appendDataSources(dataSources.Body(), options.DataSources)
+		if len(out) > 0 && !bytes.HasSuffix(out, []byte("\n\n")) {
+			out = append(bytes.TrimRight(out, "\n"), '\n', '\n')
+		}
+		out = append(out, bytes.TrimRight(hclwrite.Format(dataSources.Bytes()), "\n")...)
+		out = append(out, '\n')
+	}
+	if crlf(src) {
+		out = bytes.Replace(bytes.Replace(out, []byte("\r\n"), []byte("\n"), -1), []byte("\n"), []byte("\r\n"), -1)
+	}
+	if failed != nil {
+		return out, failed
+	}
+	return out, nil
+}
+
+// crlf tells whether the file ends its lines with \r\n, as editors and git's autocrlf do on Windows, so rendered
+// blocks can end theirs the same way rather than leave the file with mixed line endings
+func crlf(src []byte) bool {
+	i := bytes.IndexByte(src, '\n')
+	return i > 0 && src[i-1] == '\r'
+}
+
+func findResourceBlock(body *hclsyntax.Body, resourceType string, name string) *hclsyntax.Block {
+	for _, block := range body.Blocks {
+		if block.Type == "resource" && len(block.Labels) == 2 && block.Labels[0] == resourceType && block.Labels[1] == name {
+			return block
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package stateparser
+
+import (
+	"encoding/json"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUpdateHCL(t *testing.T) {
+	src := `# managed by the platform team
+terraform {
+  backend "s3" {}
+}
+
+variable "env" {}
+
+resource "onelogin_users" "hand_written" {
+  username    = "kept"   # odd spacing is kept too
+}
+
+resource "onelogin_roles" "_engineering_1" {
+}
+# trailing comment
+`
+	state := State{
+		Resources: []StateResource{
+			StateResource{
+				Name: "hand_written",
+				Type: "onelogin_users",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"id": "3", "username": "changed remotely"}},
+				},
+			},
+			StateResource{
+				Name:     "_emea_sales_1",
+				Type:     "onelogin_roles",
+				Provider: `provider["registry.terraform.io/onelogin/onelogin"].emea`,
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"id": "8", "name": "sales"}},
+				},
+			},
+			StateResource{
+				Name: "_engineering_1",
+				Type: "onelogin_roles",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"id": "7", "name": "engineering", "users": []interface{}{json.Number("3")}}},
+				},
+			},
+		},
+	}
+	tests := map[string]struct {
+		Src            string // defaults to src
+		Addresses      []string
+		Options        Options
+		ExpectedOutput string
+		ExpectError    bool
+	}{
+		"it rewrites only the given resources": {
+			Addresses:      []string{"onelogin_roles._engineering_1"},
+			ExpectedOutput: "# managed by the platform team\nterraform {\n  backend \"s3\" {}\n}\n\nvariable \"env\" {}\n\nresource \"onelogin_users\" \"hand_written\" {\n  username    = \"kept\"   # odd spacing is kept too\n}\n\nresource \"onelogin_roles\" \"_engineering_1\" {\n  name  = \"engineering\"\n  users = [onelogin_users.hand_written.id]\n}\n# trailing comment\n",
+		},
+		"it appends data sources": {
+			Addresses:      []string{},
+			Options:        Options{DataSources: []tfimportables.ResourceDefinition{{Type: "onelogin_apps", Name: "wiki", ImportID: "12"}}},
+			ExpectedOutput: src + "\ndata \"onelogin_apps\" \"wiki\" {\n  id = \"12\"\n}\n",
+		},
+		"it keeps resources on their aliased provider": {
+			Src:            "resource \"onelogin_roles\" \"_emea_sales_1\" {\n  provider = onelogin.emea\n}\n",
+			Addresses:      []string{"onelogin_roles._emea_sales_1"},
+			ExpectedOutput: "resource \"onelogin_roles\" \"_emea_sales_1\" {\n  provider = onelogin.emea\n  name     = \"sales\"\n}\n",
+		},
+		"it keeps windows line endings": {
+			Src:            "# imported\r\nresource \"onelogin_roles\" \"_emea_sales_1\" {\r\n  provider = onelogin.emea\r\n}\r\n",
+			Addresses:      []string{"onelogin_roles._emea_sales_1"},
+			ExpectedOutput: "# imported\r\nresource \"onelogin_roles\" \"_emea_sales_1\" {\r\n  provider = onelogin.emea\r\n  name     = \"sales\"\r\n}\r\n",
+		},
+		"it errors when a resource is not in state": {
+			Addresses:   []string{"onelogin_roles.missing"},
+			ExpectError: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			input := src
+			if test.Src != "" {
+				input = test.Src
+			}
+			actual, err := UpdateHCL([]byte(input), "main.tf", state, test.Options, test.Addresses)
+			if test.ExpectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+		})
+	}
+}
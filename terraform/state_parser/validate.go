@@ -0,0 +1,84 @@
+package stateparser
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/onelogin/onelogin/terraform/schema"
+	"sort"
+	"strings"
+)
+
+// Invalid is a resource fetched from the remote that will fail to import or plan, with why
+type Invalid struct {
+	Resource tfimportables.ResourceDefinition
+	Problems []string
+}
+
+// Validate checks the resources fetched from the remote against the provider schema, for required attributes that
+// are missing and values that aren't of their attribute's type, and against the values the API accepts for
+// attributes like configuration.token_endpoint_auth_method. Resource types without a schema are only checked
+// against those values
+func Validate(resources []tfimportables.ResourceDefinition, schemas *tfschema.ProviderSchemas) ([]Invalid, error) {
+	invalid := []Invalid{}
+	for _, resource := range resources {
+		attributes, err := remoteAttributes(resource.Remote)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read remote data for %s.%s: %s", resource.Type, resource.Name, err)
+		}
+		problems := []string{}
+		if schemas != nil {
+			if schema, _, ok := schemas.Resource(resource.Type); ok {
+				problems = append(problems, schema.Block.Validate(attributes, "")...)
+			}
+		}
+		problems = append(problems, enumProblems(attributes, tfimportables.Enums(resource.Type))...)
+		if len(problems) > 0 {
+			invalid = append(invalid, Invalid{Resource: resource, Problems: problems})
+		}
+	}
+	return invalid, nil
+}
+
+// enumProblems lists the attributes whose value isn't one of those allowed at their path
+func enumProblems(attributes map[string]interface{}, enums map[string][]string) []string {
+	paths := make([]string, 0, len(enums))
+	for path := range enums {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	problems := []string{}
+	for _, path := range paths {
+		for _, value := range valuesAt(attributes, strings.Split(path, ".")) {
+			if value == nil || contains(enums[path], fmt.Sprint(value)) {
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s is %v, expected one of %s", path, value, strings.Join(enums[path], ", ")))
+		}
+	}
+	return problems
+}
+
+// valuesAt finds the values at the path in the data, in each item of the lists along it
+func valuesAt(data interface{}, path []string) []interface{} {
+	switch value := data.(type) {
+	case []interface{}:
+		out := []interface{}{}
+		for _, item := range value {
+			out = append(out, valuesAt(item, path)...)
+		}
+		return out
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return []interface{}{value}
+		}
+		next, ok := value[path[0]]
+		if !ok {
+			return nil
+		}
+		return valuesAt(next, path[1:])
+	}
+	if len(path) > 0 {
+		return nil
+	}
+	return []interface{}{data}
+}
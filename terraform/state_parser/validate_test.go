@@ -0,0 +1,38 @@
+package stateparser
+
+import (
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	valid := tfimportables.ResourceDefinition{Type: "onelogin_apps", Name: "wiki", Remote: map[string]interface{}{
+		"name": "Wiki", "connector_id": 110016, "configuration": map[string]interface{}{"token_endpoint_auth_method": 1},
+	}}
+	missingName := tfimportables.ResourceDefinition{Type: "onelogin_apps", Name: "nameless", Remote: map[string]interface{}{"connector_id": 110016}}
+	badEnum := tfimportables.ResourceDefinition{Type: "onelogin_oidc_apps", Name: "portal", Remote: map[string]interface{}{
+		"name": "Portal", "configuration": map[string]interface{}{"token_endpoint_auth_method": 9, "signature_algorithm": "SHA-256"},
+	}}
+	badStatus := tfimportables.ResourceDefinition{Type: "onelogin_users", Name: "jo", Remote: map[string]interface{}{"status": 6, "state": 1}}
+
+	invalid, err := Validate([]tfimportables.ResourceDefinition{valid, missingName, badEnum, badStatus}, failingSchemas())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Invalid{
+		{Resource: missingName, Problems: []string{"name is required"}},
+		{Resource: badEnum, Problems: []string{"configuration.token_endpoint_auth_method is 9, expected one of 0, 1, 2"}},
+		{Resource: badStatus, Problems: []string{"status is 6, expected one of 0, 1, 2, 3, 4, 5, 7, 8"}},
+	}, invalid)
+}
+
+func TestValuesAt(t *testing.T) {
+	data := map[string]interface{}{
+		"name":  "Wiki",
+		"rules": []interface{}{map[string]interface{}{"match": "all"}, map[string]interface{}{"match": "any"}, map[string]interface{}{}},
+	}
+	assert.Equal(t, []interface{}{"Wiki"}, valuesAt(data, []string{"name"}))
+	assert.Equal(t, []interface{}{"all", "any"}, valuesAt(data, []string{"rules", "match"}))
+	assert.Empty(t, valuesAt(data, []string{"name", "first"}))
+	assert.Empty(t, valuesAt(data, []string{"missing"}))
+}
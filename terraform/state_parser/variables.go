@@ -0,0 +1,126 @@
+package stateparser
+
+import (
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/onelogin/onelogin/terraform/importables"
+	"github.com/zclconf/go-cty/cty"
+	"strings"
+)
+
+// Variable is an input variable a value was extracted into
+type Variable struct {
+	Name      string
+	Value     cty.Value
+	Sensitive bool
+	Redacted  bool // the value is a secret left out of the example tfvars
+}
+
+// Variables collects the values extracted from main.tf while rendering so they can be declared in variables.tf
+type Variables struct {
+	list  []Variable
+	names map[string]int
+}
+
+// List is the extracted variables in the order they were found
+func (v *Variables) List() []Variable {
+	return v.list
+}
+
+// add records the value under a variable name derived from the resource and attribute path
+// and returns the name given to it, which is suffixed when repeated blocks hold the same attribute
+func (v *Variables) add(resourceType string, resourceName string, path string, value cty.Value, sensitive bool, redacted bool) string {
+	if v.names == nil {
+		v.names = map[string]int{}
+	}
+	name := strings.Replace(fmt.Sprintf("%s_%s_%s", resourceType, resourceName, path), ".", "_", -1)
+	v.names[name]++
+	if v.names[name] > 1 {
+		name = fmt.Sprintf("%s_%d", name, v.names[name])
+	}
+	v.list = append(v.list, Variable{Name: name, Value: value, Sensitive: sensitive, Redacted: redacted})
+	return name
+}
+
+// HCL declares the variables for variables.tf. Sensitive variables are marked so terraform keeps them out of its output
+func (v *Variables) HCL() []byte {
+	file := hclwrite.NewEmptyFile()
+	for i, variable := range v.list {
+		if i > 0 {
+			file.Body().AppendNewline()
+		}
+		body := file.Body().AppendNewBlock("variable", []string{variable.Name}).Body()
+		body.SetAttributeRaw("type", hclwrite.TokensForTraversal(hcl.Traversal{hcl.TraverseRoot{Name: typeexpr.TypeString(variable.Value.Type())}}))
+		if variable.Sensitive {
+			body.SetAttributeValue("sensitive", cty.True)
+		}
+	}
+	return hclwrite.Format(file.Bytes())
+}
+
+// TFVarsExample is an example terraform.tfvars with the values found in state. Secrets are left blank
+// unless Options.IncludeSecrets was set so the example is safe to commit
+func (v *Variables) TFVarsExample() []byte {
+	file := hclwrite.NewEmptyFile()
+	for _, variable := range v.list {
+		value := variable.Value
+		if variable.Redacted {
+			if empty, err := emptyValue(value.Type()); err == nil {
+				value = empty
+			}
+		}
+		setAttribute(file.Body(), variable.Name, value)
+	}
+	return hclwrite.Format(file.Bytes())
+}
+
+// variableTokens extracts the value of the attribute at path into a variable when Options.Variables is set and
+// the attribute is registered for the resource type or is sensitive, returning the var. reference
+func (r renderer) variableTokens(path string, value cty.Value, sensitive bool) (hclwrite.Tokens, bool) {
+	if r.options.Variables == nil || isEmpty(value) {
+		return nil, false
+	}
+	if !sensitive && !contains(tfimportables.Variables(r.resourceType), path) {
+		return nil, false
+	}
+	name := r.options.Variables.add(r.resourceType, r.resourceName, path, value, sensitive, sensitive && !r.options.IncludeSecrets)
+	return hclwrite.TokensForTraversal(hcl.Traversal{hcl.TraverseRoot{Name: "var"}, hcl.TraverseAttr{Name: name}}), true
+}
+
+// objectTokens renders a map attribute entry by entry when any of its entries are extracted into variables or redacted
+func (r renderer) objectTokens(path string, m map[string]interface{}) (hclwrite.Tokens, bool) {
+	if r.options.Variables == nil && r.options.IncludeSecrets {
+		return nil, false
+	}
+	found := false
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOBrace, Bytes: []byte("{")},
+		{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")},
+	}
+	for _, k := range sortedKeys(m) {
+		value := toCtyValue(m[k])
+		substitute, substituted := r.substituteTokens(attributePath(path, k), value, false)
+		if substituted && substitute == nil {
+			found = true
+			continue
+		}
+		if hclsyntax.ValidIdentifier(k) {
+			tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(k)})
+		} else {
+			tokens = append(tokens, hclwrite.TokensForValue(cty.StringVal(k))...)
+		}
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenEqual, Bytes: []byte("=")})
+		if substituted {
+			tokens = append(tokens, substitute...)
+			found = true
+		} else {
+			tokens = append(tokens, hclwrite.TokensForValue(value)...)
+		}
+		tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	}
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenCBrace, Bytes: []byte("}")})
+	return tokens, found
+}
@@ -0,0 +1,84 @@
+package stateparser
+
+import (
+	"github.com/onelogin/onelogin/terraform/schema"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRenderVariables(t *testing.T) {
+	schemas, _ := tfschema.Parse([]byte(`{
+		"provider_schemas": {
+			"registry.terraform.io/onelogin/onelogin": {
+				"resource_schemas": {
+					"onelogin_oidc_apps": {
+						"block": {
+							"attributes": {
+								"name": {"type": "string", "required": true},
+								"connector_password": {"type": "string", "optional": true, "sensitive": true}
+							}
+						}
+					}
+				}
+			}
+		}
+	}`))
+	state := State{
+		Resources: []StateResource{
+			StateResource{
+				Name: "wiki",
+				Type: "onelogin_saml_apps",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"name": "wiki", "configuration": map[string]interface{}{"provider_arn": "arn:aws:iam::1", "signature_algorithm": "sha-256"}}},
+				},
+			},
+			StateResource{
+				Name: "portal",
+				Type: "onelogin_oidc_apps",
+				Instances: []ResourceInstance{
+					ResourceInstance{Data: map[string]interface{}{"name": "portal", "connector_password": "hunter2"}},
+				},
+			},
+		},
+	}
+	header := "terraform {\n  required_providers {\n    onelogin = {\n      source = \"onelogin/onelogin\"\n    }\n  }\n}\n\nprovider \"onelogin\" {\n  alias = \"onelogin\"\n}\n\n"
+	tests := map[string]struct {
+		IncludeSecrets    bool
+		Variables         *Variables
+		ExpectedOutput    string
+		ExpectedVariables string
+		ExpectedTFVars    string
+	}{
+		"it extracts registered and sensitive attributes into variables": {
+			Variables:         &Variables{},
+			ExpectedOutput:    header + "resource \"onelogin_saml_apps\" \"wiki\" {\n  configuration = {\n    provider_arn        = var.onelogin_saml_apps_wiki_configuration_provider_arn\n    signature_algorithm = \"sha-256\"\n  }\n  name = \"wiki\"\n}\n\nresource \"onelogin_oidc_apps\" \"portal\" {\n  connector_password = var.onelogin_oidc_apps_portal_connector_password\n  name               = \"portal\"\n}\n\n",
+			ExpectedVariables: "variable \"onelogin_saml_apps_wiki_configuration_provider_arn\" {\n  type = string\n}\n\nvariable \"onelogin_oidc_apps_portal_connector_password\" {\n  type      = string\n  sensitive = true\n}\n",
+			ExpectedTFVars:    "onelogin_saml_apps_wiki_configuration_provider_arn = \"arn:aws:iam::1\"\nonelogin_oidc_apps_portal_connector_password       = \"\"\n",
+		},
+		"it fills in secrets in the example tfvars when secrets are included": {
+			IncludeSecrets:    true,
+			Variables:         &Variables{},
+			ExpectedOutput:    header + "resource \"onelogin_saml_apps\" \"wiki\" {\n  configuration = {\n    provider_arn        = var.onelogin_saml_apps_wiki_configuration_provider_arn\n    signature_algorithm = \"sha-256\"\n  }\n  name = \"wiki\"\n}\n\nresource \"onelogin_oidc_apps\" \"portal\" {\n  connector_password = var.onelogin_oidc_apps_portal_connector_password\n  name               = \"portal\"\n}\n\n",
+			ExpectedVariables: "variable \"onelogin_saml_apps_wiki_configuration_provider_arn\" {\n  type = string\n}\n\nvariable \"onelogin_oidc_apps_portal_connector_password\" {\n  type      = string\n  sensitive = true\n}\n",
+			ExpectedTFVars:    "onelogin_saml_apps_wiki_configuration_provider_arn = \"arn:aws:iam::1\"\nonelogin_oidc_apps_portal_connector_password       = \"hunter2\"\n",
+		},
+		"it redacts secrets without variables": {
+			ExpectedOutput: header + "resource \"onelogin_saml_apps\" \"wiki\" {\n  configuration = {\n    provider_arn        = \"arn:aws:iam::1\"\n    signature_algorithm = \"sha-256\"\n  }\n  name = \"wiki\"\n}\n\nresource \"onelogin_oidc_apps\" \"portal\" {\n  connector_password = \"REDACTED\"\n  name               = \"portal\"\n}\n\n",
+		},
+		"it writes secrets when they are included": {
+			IncludeSecrets: true,
+			ExpectedOutput: header + "resource \"onelogin_saml_apps\" \"wiki\" {\n  configuration = {\n    provider_arn        = \"arn:aws:iam::1\"\n    signature_algorithm = \"sha-256\"\n  }\n  name = \"wiki\"\n}\n\nresource \"onelogin_oidc_apps\" \"portal\" {\n  connector_password = \"hunter2\"\n  name               = \"portal\"\n}\n\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Render(state, Options{Schemas: schemas, Variables: test.Variables, IncludeSecrets: test.IncludeSecrets})
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedOutput, string(actual))
+			if test.Variables != nil {
+				assert.Equal(t, test.ExpectedVariables, string(test.Variables.HCL()))
+				assert.Equal(t, test.ExpectedTFVars, string(test.Variables.TFVarsExample()))
+			}
+		})
+	}
+}
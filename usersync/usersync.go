@@ -0,0 +1,143 @@
+// Package usersync usersync.go
+// This module reconciles the account's users with a source of truth like an HR extract or an LDAP export, read as
+// rows of user fields. Rows are matched to users by a key field, e.g. email, ignoring case: rows with no user are
+// created, users whose fields differ from their row are updated, and optionally active users with no row are
+// deactivated.
+//
+// Fields compared
+// Only the fields to update are compared, or every field of the rows when none are given. Empty cells are left out of
+// rows, so they never clear a field.
+package usersync
+
+import (
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"io"
+	"sort"
+	"strings"
+)
+
+// actions of a Change
+const (
+	Create     = "create"
+	Update     = "update"
+	Deactivate = "deactivate"
+)
+
+// user statuses, as the API numbers them
+const (
+	ActiveStatus    = "1"
+	SuspendedStatus = "2"
+)
+
+// FieldChange is a field a row changes, with the user's value and the row's
+type FieldChange struct {
+	Field string
+	From  string
+	To    string
+}
+
+// Change creates, updates or deactivates a user
+type Change struct {
+	Action string
+	Key    string         // the key field's value, lowercased
+	ID     string         // of the user updated or deactivated
+	Line   int            // of the row creating or updating the user, counting the header as 1
+	Body   records.Record // the fields sent
+	Fields []FieldChange  // fields updated, sorted
+}
+
+// Options say how rows are reconciled with users
+type Options struct {
+	Key               string   // field rows and users are matched by
+	UpdateFields      []string // fields compared and updated, every field of the rows when empty
+	DeactivateMissing bool     // suspend active users with no row
+}
+
+// Plan lists the changes reconciling the users with the rows, in the order of the rows then of the users deactivated.
+// Rows without the key, or with the key of an earlier row, are reported
+func Plan(rows []records.Record, users []records.Record, options Options) ([]Change, error) {
+	byKey := map[string]records.Record{}
+	for _, user := range users {
+		if key := strings.ToLower(user.Text(options.Key)); key != "" {
+			byKey[key] = user
+		}
+	}
+	seen := map[string]bool{}
+	problems := []string{}
+	changes := []Change{}
+	for i, row := range rows {
+		line := i + 2
+		key := strings.ToLower(row.Text(options.Key))
+		if key == "" {
+			problems = append(problems, fmt.Sprintf("line %d has no %s", line, options.Key))
+			continue
+		}
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("line %d repeats %s %s", line, options.Key, key))
+			continue
+		}
+		seen[key] = true
+		user, exists := byKey[key]
+		if !exists {
+			changes = append(changes, Change{Action: Create, Key: key, Line: line, Body: records.Merge(row)})
+			continue
+		}
+		fields := options.UpdateFields
+		if len(fields) == 0 {
+			fields = records.Fields([]records.Record{row})
+		}
+		change := Change{Action: Update, Key: key, ID: user.Text("id"), Line: line, Body: records.Record{}, Fields: []FieldChange{}}
+		for _, field := range fields {
+			if _, given := row[field]; !given || field == options.Key || row.Text(field) == user.Text(field) {
+				continue
+			}
+			change.Body[field] = row[field]
+			change.Fields = append(change.Fields, FieldChange{Field: field, From: user.Text(field), To: row.Text(field)})
+		}
+		if len(change.Fields) > 0 {
+			sort.Slice(change.Fields, func(i, j int) bool { return change.Fields[i].Field < change.Fields[j].Field })
+			changes = append(changes, change)
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid source: %s", strings.Join(problems, ", "))
+	}
+	if options.DeactivateMissing {
+		deactivated := []Change{}
+		for key, user := range byKey {
+			if !seen[key] && user.Text("status") == ActiveStatus {
+				deactivated = append(deactivated, Change{Action: Deactivate, Key: key, ID: user.Text("id"), Body: records.Record{"status": SuspendedStatus},
+					Fields: []FieldChange{{Field: "status", From: ActiveStatus, To: SuspendedStatus}}})
+			}
+		}
+		sort.Slice(deactivated, func(i, j int) bool { return deactivated[i].Key < deactivated[j].Key })
+		changes = append(changes, deactivated...)
+	}
+	return changes, nil
+}
+
+// WritePreview prints the changes like a diff, + for users created, ~ for those updated with a line per field and
+// - for those deactivated, then their count
+func WritePreview(w io.Writer, changes []Change) {
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "The users are in sync.")
+		return
+	}
+	counts := map[string]int{}
+	for _, change := range changes {
+		counts[change.Action]++
+		switch change.Action {
+		case Create:
+			fmt.Fprintf(w, "+ %s\n", change.Key)
+		case Update:
+			fmt.Fprintf(w, "~ %s\n", change.Key)
+			for _, field := range change.Fields {
+				fmt.Fprintf(w, "    %s: %q => %q\n", field.Field, field.From, field.To)
+			}
+		case Deactivate:
+			fmt.Fprintf(w, "- %s\n", change.Key)
+		}
+	}
+	fmt.Fprintf(w, "%d to create, %d to update and %d to deactivate.\n", counts[Create], counts[Update], counts[Deactivate])
+}
@@ -0,0 +1,83 @@
+package usersync
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	users := []records.Record{
+		{"id": json.Number("1"), "email": "Ann@acme.com", "department": "Sales", "title": "Rep", "status": json.Number("1")},
+		{"id": json.Number("2"), "email": "bob@acme.com", "department": "Eng", "status": json.Number("1")},
+		{"id": json.Number("3"), "email": "cy@acme.com", "status": json.Number("1")},
+		{"id": json.Number("4"), "email": "dee@acme.com", "status": json.Number("2")},
+		{"id": json.Number("5"), "username": "svc", "status": json.Number("1")},
+	}
+	tests := map[string]struct {
+		Rows          []records.Record
+		Options       Options
+		Expected      []Change
+		ExpectedError string
+	}{
+		"It creates, updates and deactivates users": {
+			Rows: []records.Record{
+				{"email": "ann@acme.com", "department": "Eng", "title": "Rep", "phone": "555"},
+				{"email": "bob@acme.com", "department": "Eng"},
+				{"email": "eve@acme.com", "department": "Ops"},
+			},
+			Options: Options{Key: "email", UpdateFields: []string{"department", "title"}, DeactivateMissing: true},
+			Expected: []Change{
+				{Action: Update, Key: "ann@acme.com", ID: "1", Line: 2, Body: records.Record{"department": "Eng"},
+					Fields: []FieldChange{{Field: "department", From: "Sales", To: "Eng"}}},
+				{Action: Create, Key: "eve@acme.com", Line: 4, Body: records.Record{"email": "eve@acme.com", "department": "Ops"}},
+				{Action: Deactivate, Key: "cy@acme.com", ID: "3", Body: records.Record{"status": SuspendedStatus},
+					Fields: []FieldChange{{Field: "status", From: ActiveStatus, To: SuspendedStatus}}},
+			},
+		},
+		"It compares every field of the rows when none are given, and leaves missing users alone": {
+			Rows:    []records.Record{{"email": "bob@acme.com", "department": "Eng", "title": "Dev"}},
+			Options: Options{Key: "email"},
+			Expected: []Change{
+				{Action: Update, Key: "bob@acme.com", ID: "2", Line: 2, Body: records.Record{"title": "Dev"},
+					Fields: []FieldChange{{Field: "title", From: "", To: "Dev"}}},
+			},
+		},
+		"It reports rows without the key or repeating one": {
+			Rows:          []records.Record{{"email": "bob@acme.com"}, {"department": "Eng"}, {"email": "BOB@acme.com"}},
+			Options:       Options{Key: "email"},
+			ExpectedError: "invalid source: line 3 has no email, line 4 repeats email bob@acme.com",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := Plan(test.Rows, users, test.Options)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestWritePreview(t *testing.T) {
+	var out bytes.Buffer
+	WritePreview(&out, []Change{
+		{Action: Create, Key: "eve@acme.com"},
+		{Action: Update, Key: "ann@acme.com", Fields: []FieldChange{{Field: "department", From: "Sales", To: "Eng"}}},
+		{Action: Deactivate, Key: "cy@acme.com"},
+	})
+	assert.Equal(t, "+ eve@acme.com\n"+
+		"~ ann@acme.com\n"+
+		"    department: \"Sales\" => \"Eng\"\n"+
+		"- cy@acme.com\n"+
+		"1 to create, 1 to update and 1 to deactivate.\n", out.String())
+
+	out.Reset()
+	WritePreview(&out, nil)
+	assert.Equal(t, "The users are in sync.\n", out.String())
+}
@@ -0,0 +1,71 @@
+package webhooks
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"github.com/onelogin/onelogin/logger"
+	"github.com/onelogin/onelogin/records"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// MaxDelivery is the most of a delivery read, in bytes
+const MaxDelivery = 10 << 20
+
+// Receiver is the http.Handler deliveries are posted to. It queues the commands of the rules matching each event,
+// leaving running them to whoever reads the queue so slow commands don't hold up deliveries
+type Receiver struct {
+	Rules  []Rule
+	Secret string                     // bearer token deliveries must give in their Authorization header, when set
+	Name   func(event records.Record) // sets the event's event_type, when given
+	Queue  chan<- []string
+}
+
+// ServeHTTP queues the commands for a delivery, answering 202 with how many were queued
+func (r Receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "deliveries must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Secret != "" {
+		token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(r.Secret)) != 1 {
+			logger.Warn("Rejected a delivery without the secret", "remote", req.RemoteAddr)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, req.Body, MaxDelivery))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	events, err := ParseEvents(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	queued := 0
+	for _, event := range events {
+		if r.Name != nil {
+			r.Name(event)
+		}
+		for _, rule := range r.Rules {
+			if !rule.Matches(event) {
+				continue
+			}
+			command, err := rule.Render(event)
+			if err != nil {
+				logger.Warn("Skipping a command the event can't fill in", "event", rule.Event, "id", event.Text("id"), "error", err)
+				continue
+			}
+			r.Queue <- command
+			queued++
+		}
+	}
+	logger.Info("Received events", "count", len(events), "queued", queued)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]int{"events": len(events), "queued": queued})
+}
@@ -0,0 +1,72 @@
+package webhooks
+
+import (
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestReceiver(t *testing.T) {
+	rule, err := ParseRule("app.updated=terraform-import onelogin_apps --id {{.app_id}}")
+	assert.Nil(t, err)
+	tests := map[string]struct {
+		Method         string
+		Authorization  string
+		Body           string
+		ExpectedStatus int
+		ExpectedQueue  [][]string
+	}{
+		"It queues the commands of matching events": {
+			Method:         http.MethodPost,
+			Authorization:  "Bearer s3cret",
+			Body:           `[{"event_type_id":13,"app_id":5},{"event_type_id":5,"app_id":6},{"event_type_id":13,"app_id":7}]`,
+			ExpectedStatus: http.StatusAccepted,
+			ExpectedQueue:  [][]string{{"terraform-import", "onelogin_apps", "--id", "5"}, {"terraform-import", "onelogin_apps", "--id", "7"}},
+		},
+		"It rejects deliveries without the secret": {
+			Method:         http.MethodPost,
+			Authorization:  "Bearer wrong",
+			Body:           `[]`,
+			ExpectedStatus: http.StatusUnauthorized,
+		},
+		"It rejects other methods": {
+			Method:         http.MethodGet,
+			ExpectedStatus: http.StatusMethodNotAllowed,
+		},
+		"It rejects bodies that aren't events": {
+			Method:         http.MethodPost,
+			Authorization:  "Bearer s3cret",
+			Body:           `{"id":`,
+			ExpectedStatus: http.StatusBadRequest,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			queue := make(chan []string, 10)
+			receiver := Receiver{
+				Rules:  []Rule{rule},
+				Secret: "s3cret",
+				Name: func(event records.Record) {
+					if event.Text("event_type_id") == "13" {
+						event["event_type"] = "APP_UPDATED"
+					}
+				},
+				Queue: queue,
+			}
+			req := httptest.NewRequest(test.Method, "/", strings.NewReader(test.Body))
+			req.Header.Set("Authorization", test.Authorization)
+			recorder := httptest.NewRecorder()
+			receiver.ServeHTTP(recorder, req)
+			assert.Equal(t, test.ExpectedStatus, recorder.Code)
+			close(queue)
+			var queued [][]string
+			for command := range queue {
+				queued = append(queued, command)
+			}
+			assert.Equal(t, test.ExpectedQueue, queued)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package webhooks webhooks.go
+// This module turns the events OneLogin's event webhooks deliver into the commands configured for them, e.g. a
+// targeted re-import of an app whenever it changes:
+//
+//	app.updated=terraform-import onelogin_apps --id {{.app_id}} --auto_approve
+//
+// Events
+// Deliveries hold a JSON array of events, a single event, or an event per line. Rules name the events they run for by
+// event type name, ignoring case and with dots standing for underscores so app.updated matches APP_UPDATED, by event
+// type id, or * for every event.
+//
+// Commands
+// A command is split into words like a shell would, then each word is rendered as a Go template with the event's
+// fields, as text. Words are never split again after rendering, so values from the event can't add arguments.
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/onelogin/onelogin/records"
+	"strings"
+	"text/template"
+)
+
+// Rule is a command to run for events of a type
+type Rule struct {
+	Event    string
+	Command  []string
+	template []*template.Template
+}
+
+// ParseRule parses a rule written as <event>=<command>
+func ParseRule(s string) (Rule, error) {
+	index := strings.Index(s, "=")
+	if index < 1 {
+		return Rule{}, fmt.Errorf("invalid rule %s, expected <event>=<command>", s)
+	}
+	rule := Rule{Event: strings.TrimSpace(s[:index])}
+	words, err := SplitWords(s[index+1:])
+	if err != nil {
+		return Rule{}, fmt.Errorf("invalid command for %s: %s", rule.Event, err)
+	}
+	if len(words) == 0 {
+		return Rule{}, fmt.Errorf("invalid rule %s, the command is empty", s)
+	}
+	for _, word := range words {
+		parsed, err := template.New(rule.Event).Option("missingkey=error").Parse(word)
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid command for %s: %s", rule.Event, err)
+		}
+		rule.template = append(rule.template, parsed)
+	}
+	rule.Command = words
+	return rule, nil
+}
+
+// Matches reports whether the rule runs for the event, whose type is named by event_type when known
+func (r Rule) Matches(event records.Record) bool {
+	if r.Event == "*" || r.Event == event.Text("event_type_id") {
+		return true
+	}
+	name := strings.ReplaceAll(r.Event, ".", "_")
+	return event.Text("event_type") != "" && strings.EqualFold(name, event.Text("event_type"))
+}
+
+// Render is the rule's command for the event, reporting fields the command uses that the event doesn't have
+func (r Rule) Render(event records.Record) ([]string, error) {
+	fields := map[string]string{}
+	for field := range event {
+		fields[field] = event.Text(field)
+	}
+	out := make([]string, len(r.template))
+	for i, word := range r.template {
+		var rendered bytes.Buffer
+		if err := word.Execute(&rendered, fields); err != nil {
+			return nil, err
+		}
+		out[i] = rendered.String()
+	}
+	return out, nil
+}
+
+// ParseEvents reads the events of a delivery: a JSON array, a single event, or an event per line
+func ParseEvents(body []byte) ([]records.Record, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return []records.Record{}, nil
+	}
+	if body[0] == '[' {
+		return records.FromJSON(body)
+	}
+	out := []records.Record{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	for decoder.More() {
+		var event records.Record
+		if err := decoder.Decode(&event); err != nil {
+			return nil, fmt.Errorf("unable to read events: %s", err)
+		}
+		out = append(out, event)
+	}
+	return out, nil
+}
+
+// SplitWords splits the command line into words at spaces, keeping spaces quoted with ' or " or escaped with \
+func SplitWords(s string) ([]string, error) {
+	words := []string{}
+	var word strings.Builder
+	inWord, escaped := false, false
+	var quote rune
+	for _, c := range s {
+		switch {
+		case escaped:
+			word.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote, inWord = c, true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %s", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
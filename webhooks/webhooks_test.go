@@ -0,0 +1,117 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"github.com/onelogin/onelogin/records"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := map[string]struct {
+		Line          string
+		Expected      []string
+		ExpectedError string
+	}{
+		"It splits at spaces": {
+			Line:     "  terraform-import onelogin_apps\t--id {{.app_id}} ",
+			Expected: []string{"terraform-import", "onelogin_apps", "--id", "{{.app_id}}"},
+		},
+		"It keeps quoted and escaped spaces": {
+			Line:     `curl -d 'app {{.app_name}}' "a \"b\"" c\ d ''`,
+			Expected: []string{"curl", "-d", "app {{.app_name}}", `a "b"`, "c d", ""},
+		},
+		"It reports unterminated quotes": {
+			Line:          `echo 'oops`,
+			ExpectedError: "unterminated quote or escape in echo 'oops",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := SplitWords(test.Line)
+			if test.ExpectedError != "" {
+				assert.EqualError(t, err, test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}
+
+func TestRule(t *testing.T) {
+	event := records.Record{"id": json.Number("9"), "event_type_id": json.Number("13"), "event_type": "APP_UPDATED", "app_id": json.Number("123456789"), "app_name": "a b; rm -rf /"}
+	tests := map[string]struct {
+		Rule            string
+		ExpectedMatch   bool
+		ExpectedCommand []string
+		ExpectedError   string
+	}{
+		"It matches event type names with dots for underscores": {
+			Rule:            "app.updated=terraform-import onelogin_apps --id {{.app_id}} --auto_approve",
+			ExpectedMatch:   true,
+			ExpectedCommand: []string{"terraform-import", "onelogin_apps", "--id", "123456789", "--auto_approve"},
+		},
+		"It matches event type ids and keeps rendered values in one word": {
+			Rule:            "13=notify {{.app_name}}",
+			ExpectedMatch:   true,
+			ExpectedCommand: []string{"notify", "a b; rm -rf /"},
+		},
+		"It matches every event with *": {
+			Rule:            "*=log {{.id}}",
+			ExpectedMatch:   true,
+			ExpectedCommand: []string{"log", "9"},
+		},
+		"It doesn't match other events": {
+			Rule: "user.created=log",
+		},
+		"It reports fields the event doesn't have": {
+			Rule:          "*=log {{.user_id}}",
+			ExpectedMatch: true,
+			ExpectedError: `map has no entry for key "user_id"`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rule, err := ParseRule(test.Rule)
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedMatch, rule.Matches(event))
+			if !test.ExpectedMatch {
+				return
+			}
+			command, err := rule.Render(event)
+			if test.ExpectedError != "" {
+				assert.Contains(t, err.Error(), test.ExpectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.ExpectedCommand, command)
+		})
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	for _, invalid := range []string{"app.updated", "=cmd", "app.updated= ", "x=cmd {{.a"} {
+		_, err := ParseRule(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestParseEvents(t *testing.T) {
+	tests := map[string]struct {
+		Body     string
+		Expected []records.Record
+	}{
+		"It reads arrays":          {Body: `[{"id":1},{"id":2}]`, Expected: []records.Record{{"id": json.Number("1")}, {"id": json.Number("2")}}},
+		"It reads an event a line": {Body: "{\"id\":1}\n{\"id\":2}\n", Expected: []records.Record{{"id": json.Number("1")}, {"id": json.Number("2")}}},
+		"It reads single events":   {Body: `{"id":1}`, Expected: []records.Record{{"id": json.Number("1")}}},
+		"It reads empty bodies":    {Body: " ", Expected: []records.Record{}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseEvents([]byte(test.Body))
+			assert.Nil(t, err)
+			assert.Equal(t, test.Expected, actual)
+		})
+	}
+}